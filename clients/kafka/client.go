@@ -9,6 +9,7 @@ import (
 
 	"github.com/IBM/sarama"
 
+	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 )
@@ -31,6 +32,7 @@ func InitClient(ctx context.Context) error {
 		log.Info("Backing off for %d seconds", int64(setting.Kafka.ConnectBackoff/time.Second))
 		time.Sleep(setting.Kafka.ConnectBackoff)
 	}
+	graceful.GetManager().RunAtTerminate(CloseProducers)
 	return nil
 }
 
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// consumeRetryBackoff задержка перед повторным подключением группы потребителей после ошибки
+// и перед повторной обработкой сообщения
+const consumeRetryBackoff = 5 * time.Second
+
+// MessageHandler обрабатывает прочитанное сообщение. Если обработчик возвращает ошибку, смещение не фиксируется,
+// и сообщение обрабатывается повторно, пока обработчик не завершится успешно или партиция не будет передана
+// другому потребителю группы. Поэтому обработчик должен быть идемпотентным
+type MessageHandler func(ctx context.Context, msg *sarama.ConsumerMessage) error
+
+// consumerGroupHandler обработчик сессии группы потребителей
+type consumerGroupHandler struct {
+	handler MessageHandler
+}
+
+func (h consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim обрабатывает сообщения партиции по порядку и фиксирует смещение после успешной обработки
+func (h consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if !h.handleWithRetry(session.Context(), msg) {
+				return nil
+			}
+			session.MarkMessage(msg, "")
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// handleWithRetry обрабатывает сообщение, повторяя обработку после ошибки. Следующие сообщения партиции
+// не обрабатываются, пока не обработано текущее. Возвращает false, если сессия завершена до успешной обработки
+func (h consumerGroupHandler) handleWithRetry(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	for {
+		err := h.handler(ctx, msg)
+		if err == nil {
+			return true
+		}
+		log.Error("Error has occurred while handling message %s/%d/%d. Error: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(consumeRetryBackoff):
+		}
+	}
+}
+
+// RunConsumerGroup читает топики в группе потребителей groupID до завершения ctx
+func RunConsumerGroup(ctx context.Context, groupID string, topics []string, handler MessageHandler) error {
+	client := GetClient(ctx)
+	if client == nil {
+		return errors.New("kafka client is not initialized")
+	}
+	group, err := sarama.NewConsumerGroupFromClient(groupID, client)
+	if err != nil {
+		return fmt.Errorf("create consumer group %s: %w", groupID, err)
+	}
+	defer func() {
+		if err := group.Close(); err != nil {
+			log.Error("Error has occurred while closing Kafka consumer group %s. Error: %v", groupID, err)
+		}
+	}()
+
+	log.Info("Kafka consumer group %s started for topics %v", groupID, topics)
+	for {
+		// Consume возвращается при перебалансировке группы, поэтому вызывается в цикле
+		if err = group.Consume(ctx, topics, consumerGroupHandler{handler: handler}); err != nil {
+			log.Error("Error has occurred while consuming topics %v in group %s. Error: %v", topics, groupID, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(consumeRetryBackoff):
+			}
+		}
+		if ctx.Err() != nil {
+			log.Info("Kafka consumer group %s stopped", groupID)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// producers общие писатели в топики. Писатель создается при первой отправке в топик
+// и переиспользуется всеми отправителями до остановки приложения
+var producers = struct {
+	sync.Mutex
+	topics map[string]*Topic
+}{topics: make(map[string]*Topic)}
+
+// ProduceMessage отправляет сообщение в топик topicKey из настроек [kafka.*] общим писателем топика
+func ProduceMessage(ctx context.Context, topicKey, key, idempotencyKey string, value []byte) error {
+	topicInfo := setting.Kafka.Topics[topicKey]
+	topic := getProducerTopic(ctx, topicKey, topicInfo)
+	if err := topic.Produce(NewMessage(key, idempotencyKey, value)); err != nil {
+		return fmt.Errorf("produce to topic %s: %w", topicInfo.Name, err)
+	}
+	return nil
+}
+
+// getProducerTopic возвращает общий писатель топика topicKey, создавая его при первом обращении
+func getProducerTopic(ctx context.Context, topicKey string, topicInfo setting.TopicConfig) *Topic {
+	producers.Lock()
+	defer producers.Unlock()
+
+	topic, ok := producers.topics[topicKey]
+	if !ok {
+		topic = NewTopic(ctx, topicInfo.Enabled, topicInfo.Name, string(Produce))
+		producers.topics[topicKey] = topic
+	}
+	return topic
+}
+
+// CloseProducers закрывает общие писатели топиков
+func CloseProducers() {
+	producers.Lock()
+	defer producers.Unlock()
+
+	for topicKey, topic := range producers.topics {
+		topic.Close()
+		delete(producers.topics, topicKey)
+	}
+}
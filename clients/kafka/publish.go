@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+
+	"code.gitea.io/gitea/models/kafka_outbox"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// IdempotencyKeyHeader заголовок сообщения с ключом идемпотентности, по которому потребитель отбрасывает повторы
+const IdempotencyKeyHeader = "idempotency_key"
+
+// Publish отправляет событие в топик topicKey из настроек [kafka.*]. Если включен outbox, событие сохраняется в БД
+// и отправляется задачей cron, иначе отправляется сразу
+func Publish(ctx context.Context, topicKey, key, idempotencyKey string, value []byte) error {
+	topicInfo, ok := setting.Kafka.Topics[topicKey]
+	if !ok || !topicInfo.Enabled {
+		log.Debug(`topic %s is disabled`, topicKey)
+		return nil
+	}
+
+	if topicInfo.Type != string(Produce) {
+		log.Debug(`Produce events to topic %s is disabled`, topicKey)
+		return nil
+	}
+
+	if setting.Kafka.Outbox.Enabled {
+		return kafka_outbox.Enqueue(ctx, &kafka_outbox.Message{
+			TopicKey:       topicKey,
+			MessageKey:     key,
+			IdempotencyKey: idempotencyKey,
+			Payload:        string(value),
+		})
+	}
+
+	return ProduceMessage(ctx, topicKey, key, idempotencyKey, value)
+}
+
+// NewMessage создает сообщение с ключом и заголовком идемпотентности
+func NewMessage(key, idempotencyKey string, value []byte) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(IdempotencyKeyHeader), Value: []byte(idempotencyKey)},
+		},
+	}
+	if key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+	return msg
+}
@@ -3,8 +3,8 @@ package v1
 import (
 	"context"
 	"fmt"
+	"strconv"
 
-	"github.com/IBM/sarama"
 	"github.com/google/uuid"
 
 	"code.gitea.io/gitea/clients/kafka"
@@ -47,18 +47,6 @@ func NewCreateRepositorySenderOptions(repo *repo.Repository, tenant *tenant.ScTe
 // Send отправляет сообщение о создании репозитория
 func (r CreateRepositorySender) Send(ctx context.Context, options CreateRepositorySenderOptions) error {
 	log.Debug(`trying to send create repository event with options: %v`, options)
-	topicInfo := setting.Kafka.Topics[setting.KafkaRepositoryTopic]
-	if !topicInfo.Enabled {
-		log.Debug(`repository topic is disabled`)
-		return nil
-	}
-
-	if topicInfo.Type != string(kafka.Produce) {
-		log.Debug(`Produce create repository event is disabled`)
-		return nil
-	}
-
-	repositoryTopic := kafka.NewTopic(ctx, topicInfo.Enabled, topicInfo.Name, topicInfo.Type)
 
 	projectName := events.Property{
 		Name:  "project-name",
@@ -97,11 +85,5 @@ func (r CreateRepositorySender) Send(ctx context.Context, options CreateReposito
 		return fmt.Errorf("error marshaling event: %v", err)
 	}
 
-	msg := &sarama.ProducerMessage{
-		Value: sarama.ByteEncoder(marshal),
-	}
-
-	defer repositoryTopic.Close()
-
-	return repositoryTopic.Produce(msg)
+	return kafka.Publish(ctx, setting.KafkaRepositoryTopic, strconv.FormatInt(options.repo.ID, 10), event.Id, marshal)
 }
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"code.gitea.io/gitea/clients/kafka"
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	"code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// produceEvent - отправляет событие в топик topicKey. Ключ сообщения - идентификатор репозитория,
+// поэтому события одного репозитория попадают в одну партицию и читаются в порядке отправки.
+// Идентификатор сообщения используется как ключ идемпотентности
+func produceEvent(ctx context.Context, topicKey string, repoID int64, metadata generic.Metadata, event interface{}) error {
+	marshal, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %v", err)
+	}
+
+	return kafka.Publish(ctx, topicKey, strconv.FormatInt(repoID, 10), metadata.MessageId, marshal)
+}
+
+// newMetadata - создает метаданные сообщения
+func newMetadata() generic.Metadata {
+	return generic.Metadata{
+		CorrelationMessageId: nil,
+		MessageCreateTs:      strconv.FormatInt(int64(timeutil.TimeStampNow()), 10),
+		MessageId:            uuid.NewString(),
+		Producer: generic.Producer{
+			Id: setting.Kafka.Issuer,
+		},
+		Version: version,
+	}
+}
+
+// newContext - создает контекст события, тенант может отсутствовать, если ролевая модель выключена
+func newContext(entityType generic.ContextEntityType, eventCode generic.ContextEventCode, createdUnix timeutil.TimeStamp, tenant *tenant.ScTenant) generic.Context {
+	contextEvent := generic.Context{
+		EntityType:    entityType,
+		EventCode:     eventCode,
+		EventCreateTs: strconv.FormatInt(int64(createdUnix), 10),
+		EventId:       uuid.NewString(),
+	}
+	if tenant != nil {
+		contextEvent.TenantId = tenant.ID
+	}
+	return contextEvent
+}
+
+// newRepositoryInfo - создает информацию о репозитории
+func newRepositoryInfo(repo *repo.Repository, tenant *tenant.ScTenant) events.RepositoryInfo {
+	repositoryInfo := events.RepositoryInfo{
+		ProjectName:    repo.OwnerName,
+		RepositoryId:   strconv.FormatInt(repo.ID, 10),
+		RepositoryName: repo.Name,
+	}
+	if tenant != nil {
+		repositoryInfo.TenantName = tenant.Name
+	}
+	return repositoryInfo
+}
+
+// optionalString - возвращает указатель на строку или nil для пустой строки
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
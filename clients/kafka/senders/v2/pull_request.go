@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PullRequestSender - отправляет события об открытии, слиянии и закрытии запроса на слияние
+type PullRequestSender struct{}
+
+// PullRequestSenderOptions - опции для сообщения о запросе на слияние.
+// У запроса на слияние должны быть загружены задача и базовый репозиторий
+type PullRequestSenderOptions struct {
+	pr        *issues_model.PullRequest
+	tenant    *tenant.ScTenant
+	doerId    string
+	authorId  string
+	eventCode generic.ContextEventCode
+}
+
+// NewPullRequestSender - создает отправителя сообщений о запросе на слияние
+func NewPullRequestSender() PullRequestSender {
+	return PullRequestSender{}
+}
+
+// NewPullRequestSenderOptions - создает опции для сообщения о запросе на слияние, eventCode - CREATE, MERGE или CLOSE
+func NewPullRequestSenderOptions(pr *issues_model.PullRequest, tenant *tenant.ScTenant, doerId, authorId string, eventCode generic.ContextEventCode) PullRequestSenderOptions {
+	return PullRequestSenderOptions{
+		pr:        pr,
+		tenant:    tenant,
+		doerId:    doerId,
+		authorId:  authorId,
+		eventCode: eventCode,
+	}
+}
+
+// Send отправляет сообщение о запросе на слияние
+func (r PullRequestSender) Send(ctx context.Context, options PullRequestSenderOptions) error {
+	log.Debug(`trying to send pull request event with options: %v`, options)
+
+	createdUnix := timeutil.TimeStampNow()
+	if options.eventCode == generic.CREATE {
+		createdUnix = options.pr.Issue.CreatedUnix
+	}
+
+	metadata := newMetadata()
+	pullRequestEvent := events.PullRequestEvent{
+		Context:  newContext(generic.PULLREQUEST, options.eventCode, createdUnix, options.tenant),
+		Metadata: metadata,
+		Payload: events.PullRequestPayload{
+			InitiatorUser:  events.InitiatorUser{Id: options.doerId},
+			PullRequest:    newPullRequestInfo(options.pr, options.authorId),
+			RepositoryInfo: newRepositoryInfo(options.pr.BaseRepo, options.tenant),
+		},
+	}
+
+	return produceEvent(ctx, setting.KafkaPullRequestTopic, options.pr.BaseRepoID, metadata, pullRequestEvent)
+}
+
+// newPullRequestInfo - создает информацию о запросе на слияние
+func newPullRequestInfo(pr *issues_model.PullRequest, authorId string) events.PullRequestInfo {
+	pullRequestInfo := events.PullRequestInfo{
+		AuthorId:   authorId,
+		BaseBranch: pr.BaseBranch,
+		HeadBranch: pr.HeadBranch,
+		HeadSha:    pr.HeadCommitID,
+		Id:         strconv.FormatInt(pr.ID, 10),
+		Index:      int(pr.Index),
+		State:      events.Open,
+		Title:      pr.Issue.Title,
+		Url:        pr.Issue.HTMLURL(),
+	}
+	switch {
+	case pr.HasMerged:
+		pullRequestInfo.State = events.Merged
+		pullRequestInfo.MergeCommitSha = optionalString(pr.MergedCommitID)
+	case pr.Issue.IsClosed:
+		pullRequestInfo.State = events.Closed
+	}
+	return pullRequestInfo
+}
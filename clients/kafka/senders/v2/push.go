@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	"code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PushSender - отправляет события о push в репозиторий
+type PushSender struct{}
+
+// PushSenderOptions - опции для сообщения о push в репозиторий
+type PushSenderOptions struct {
+	repo    *repo.Repository
+	tenant  *tenant.ScTenant
+	doerId  string
+	opts    *repository.PushUpdateOptions
+	commits *repository.PushCommits
+}
+
+// NewPushSender - создает отправителя сообщений о push в репозиторий
+func NewPushSender() PushSender {
+	return PushSender{}
+}
+
+// NewPushSenderOptions - создает опции для сообщения о push в репозиторий
+func NewPushSenderOptions(repo *repo.Repository, tenant *tenant.ScTenant, doerId string, opts *repository.PushUpdateOptions, commits *repository.PushCommits) PushSenderOptions {
+	return PushSenderOptions{
+		repo:    repo,
+		tenant:  tenant,
+		doerId:  doerId,
+		opts:    opts,
+		commits: commits,
+	}
+}
+
+// Send отправляет сообщение о push в репозиторий
+func (r PushSender) Send(ctx context.Context, options PushSenderOptions) error {
+	log.Debug(`trying to send push event with options: %v`, options)
+
+	commits := make([]events.CommitInfo, 0, len(options.commits.Commits))
+	for _, commit := range options.commits.Commits {
+		commits = append(commits, events.CommitInfo{
+			AuthorEmail:    commit.AuthorEmail,
+			AuthorName:     commit.AuthorName,
+			CommitterEmail: optionalString(commit.CommitterEmail),
+			CommitterName:  optionalString(commit.CommitterName),
+			Message:        commit.Message,
+			Sha:            commit.Sha1,
+			Timestamp:      commit.Timestamp.UTC().Format(time.RFC3339),
+		})
+	}
+
+	metadata := newMetadata()
+	pushEvent := events.PushEvent{
+		Context:  newContext(generic.BRANCH, generic.PUSH, timeutil.TimeStampNow(), options.tenant),
+		Metadata: metadata,
+		Payload: events.PushPayload{
+			After:          options.opts.NewCommitID,
+			Before:         options.opts.OldCommitID,
+			Commits:        commits,
+			CompareUrl:     optionalString(options.commits.CompareURL),
+			InitiatorUser:  events.InitiatorUser{Id: options.doerId},
+			Ref:            options.opts.RefFullName,
+			RepositoryInfo: newRepositoryInfo(options.repo, options.tenant),
+			TotalCommits:   options.commits.Len,
+		},
+	}
+
+	return produceEvent(ctx, setting.KafkaPushTopic, options.repo.ID, metadata, pushEvent)
+}
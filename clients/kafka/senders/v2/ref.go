@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	"code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RefSender - отправляет события о создании и удалении ветки или тега
+type RefSender struct{}
+
+// RefSenderOptions - опции для сообщения о создании и удалении ветки или тега
+type RefSenderOptions struct {
+	repo      *repo.Repository
+	tenant    *tenant.ScTenant
+	doerId    string
+	eventCode generic.ContextEventCode
+	refType   events.RefPayloadRefType
+	refName   string
+	sha       string
+}
+
+// NewRefSender - создает отправителя сообщений о создании и удалении ветки или тега
+func NewRefSender() RefSender {
+	return RefSender{}
+}
+
+// NewCreateRefSenderOptions - создает опции для сообщения о создании ветки или тега
+func NewCreateRefSenderOptions(repo *repo.Repository, tenant *tenant.ScTenant, doerId string, refType events.RefPayloadRefType, refName, sha string) RefSenderOptions {
+	return RefSenderOptions{
+		repo:      repo,
+		tenant:    tenant,
+		doerId:    doerId,
+		eventCode: generic.CREATE,
+		refType:   refType,
+		refName:   refName,
+		sha:       sha,
+	}
+}
+
+// NewDeleteRefSenderOptions - создает опции для сообщения об удалении ветки или тега
+func NewDeleteRefSenderOptions(repo *repo.Repository, tenant *tenant.ScTenant, doerId string, refType events.RefPayloadRefType, refName string) RefSenderOptions {
+	return RefSenderOptions{
+		repo:      repo,
+		tenant:    tenant,
+		doerId:    doerId,
+		eventCode: generic.DELETE,
+		refType:   refType,
+		refName:   refName,
+	}
+}
+
+// Send отправляет сообщение о создании или удалении ветки или тега. События веток и тегов отправляются в разные топики
+func (r RefSender) Send(ctx context.Context, options RefSenderOptions) error {
+	log.Debug(`trying to send ref event with options: %v`, options)
+
+	topicKey, entityType := setting.KafkaBranchTopic, generic.BRANCH
+	if options.refType == events.Tag {
+		topicKey, entityType = setting.KafkaTagTopic, generic.TAG
+	}
+
+	metadata := newMetadata()
+	refEvent := events.RefEvent{
+		Context:  newContext(entityType, options.eventCode, timeutil.TimeStampNow(), options.tenant),
+		Metadata: metadata,
+		Payload: events.RefPayload{
+			InitiatorUser:  events.InitiatorUser{Id: options.doerId},
+			RefName:        options.refName,
+			RefType:        options.refType,
+			RepositoryInfo: newRepositoryInfo(options.repo, options.tenant),
+			Sha:            optionalString(options.sha),
+		},
+	}
+
+	return produceEvent(ctx, topicKey, options.repo.ID, metadata, refEvent)
+}
@@ -2,19 +2,15 @@ package v1
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 
 	"code.gitea.io/gitea/modules/log"
-	"github.com/IBM/sarama"
 	"github.com/google/uuid"
 
-	"code.gitea.io/gitea/clients/kafka"
 	"code.gitea.io/gitea/models/events/v2"
 	"code.gitea.io/gitea/models/events/v2/generic"
 	"code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/tenant"
-	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 )
@@ -50,19 +46,6 @@ func NewCreateRepositorySenderOptions(repo *repo.Repository, tenant *tenant.ScTe
 // Send отправляет сообщение о создании репозитория
 func (r CreateRepositorySender) Send(ctx context.Context, options CreateRepositorySenderOptions) error {
 	log.Debug(`trying to send create repository event with options: %v`, options)
-	topicInfo := setting.Kafka.Topics[setting.KafkaRepositoryTopic]
-
-	if !topicInfo.Enabled {
-		log.Debug(`repository topic is disabled`)
-		return nil
-	}
-
-	if topicInfo.Type != string(kafka.Produce) {
-		log.Debug(`Produce create repository event is disabled`)
-		return nil
-	}
-
-	repositoryTopic := kafka.NewTopic(ctx, topicInfo.Enabled, topicInfo.Name, topicInfo.Type)
 
 	metadata := generic.Metadata{
 		CorrelationMessageId: nil,
@@ -109,16 +92,90 @@ func (r CreateRepositorySender) Send(ctx context.Context, options CreateReposito
 		Payload:  payload,
 	}
 
-	marshal, err := json.Marshal(createRepositoryEvent)
-	if err != nil {
-		return fmt.Errorf("error marshaling event: %v", err)
+	return produceEvent(ctx, setting.KafkaRepositoryTopic, options.repo.ID, metadata, createRepositoryEvent)
+}
+
+// DeleteRepositorySender - отправляет события об удалении репозитория
+type DeleteRepositorySender struct{}
+
+// DeleteRepositorySenderOptions - опции для сообщения об удалении репозитория
+type DeleteRepositorySenderOptions struct {
+	repo   *repo.Repository
+	tenant *tenant.ScTenant
+	doerId string
+}
+
+// NewDeleteRepositorySender - создает отправителя сообщений об удалении репозитория
+func NewDeleteRepositorySender() DeleteRepositorySender {
+	return DeleteRepositorySender{}
+}
+
+// NewDeleteRepositorySenderOptions - создает опции для сообщения об удалении репозитория
+func NewDeleteRepositorySenderOptions(repo *repo.Repository, tenant *tenant.ScTenant, doerId string) DeleteRepositorySenderOptions {
+	return DeleteRepositorySenderOptions{
+		repo:   repo,
+		tenant: tenant,
+		doerId: doerId,
+	}
+}
+
+// Send отправляет сообщение об удалении репозитория
+func (r DeleteRepositorySender) Send(ctx context.Context, options DeleteRepositorySenderOptions) error {
+	log.Debug(`trying to send delete repository event with options: %v`, options)
+
+	metadata := newMetadata()
+	deleteRepositoryEvent := events.DeleteRepositoryEvent{
+		Context:  newContext(generic.REPOSITORY, generic.DELETE, timeutil.TimeStampNow(), options.tenant),
+		Metadata: metadata,
+		Payload: events.Payload{
+			RepositoryInfo: newRepositoryInfo(options.repo, options.tenant),
+			InitiatorUser:  events.InitiatorUser{Id: options.doerId},
+		},
 	}
 
-	msg := &sarama.ProducerMessage{
-		Value: sarama.ByteEncoder(marshal),
+	return produceEvent(ctx, setting.KafkaRepositoryDeleteTopic, options.repo.ID, metadata, deleteRepositoryEvent)
+}
+
+// TransferRepositorySender - отправляет события о передаче репозитория другому владельцу
+type TransferRepositorySender struct{}
+
+// TransferRepositorySenderOptions - опции для сообщения о передаче репозитория
+type TransferRepositorySenderOptions struct {
+	repo         *repo.Repository
+	tenant       *tenant.ScTenant
+	doerId       string
+	oldOwnerName string
+}
+
+// NewTransferRepositorySender - создает отправителя сообщений о передаче репозитория
+func NewTransferRepositorySender() TransferRepositorySender {
+	return TransferRepositorySender{}
+}
+
+// NewTransferRepositorySenderOptions - создает опции для сообщения о передаче репозитория
+func NewTransferRepositorySenderOptions(repo *repo.Repository, tenant *tenant.ScTenant, doerId, oldOwnerName string) TransferRepositorySenderOptions {
+	return TransferRepositorySenderOptions{
+		repo:         repo,
+		tenant:       tenant,
+		doerId:       doerId,
+		oldOwnerName: oldOwnerName,
 	}
+}
 
-	defer repositoryTopic.Close()
+// Send отправляет сообщение о передаче репозитория
+func (r TransferRepositorySender) Send(ctx context.Context, options TransferRepositorySenderOptions) error {
+	log.Debug(`trying to send transfer repository event with options: %v`, options)
+
+	metadata := newMetadata()
+	transferRepositoryEvent := events.TransferRepositoryEvent{
+		Context:  newContext(generic.REPOSITORY, generic.TRANSFER, timeutil.TimeStampNow(), options.tenant),
+		Metadata: metadata,
+		Payload: events.TransferPayload{
+			InitiatorUser:       events.InitiatorUser{Id: options.doerId},
+			PreviousProjectName: options.oldOwnerName,
+			RepositoryInfo:      newRepositoryInfo(options.repo, options.tenant),
+		},
+	}
 
-	return repositoryTopic.Produce(msg)
+	return produceEvent(ctx, setting.KafkaRepositoryTransferTopic, options.repo.ID, metadata, transferRepositoryEvent)
 }
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ReviewSender - отправляет события о завершении ревью запроса на слияние
+type ReviewSender struct{}
+
+// ReviewSenderOptions - опции для сообщения о ревью запроса на слияние
+type ReviewSenderOptions struct {
+	pr       *issues_model.PullRequest
+	review   *issues_model.Review
+	tenant   *tenant.ScTenant
+	doerId   string
+	authorId string
+}
+
+// NewReviewSender - создает отправителя сообщений о ревью запроса на слияние
+func NewReviewSender() ReviewSender {
+	return ReviewSender{}
+}
+
+// NewReviewSenderOptions - создает опции для сообщения о ревью запроса на слияние
+func NewReviewSenderOptions(pr *issues_model.PullRequest, review *issues_model.Review, tenant *tenant.ScTenant, doerId, authorId string) ReviewSenderOptions {
+	return ReviewSenderOptions{
+		pr:       pr,
+		review:   review,
+		tenant:   tenant,
+		doerId:   doerId,
+		authorId: authorId,
+	}
+}
+
+// Send отправляет сообщение о ревью запроса на слияние. Ревью других типов, например запрос ревью, не отправляются
+func (r ReviewSender) Send(ctx context.Context, options ReviewSenderOptions) error {
+	log.Debug(`trying to send review event with options: %v`, options)
+
+	var reviewType events.ReviewInfoType
+	switch options.review.Type {
+	case issues_model.ReviewTypeApprove:
+		reviewType = events.Approve
+	case issues_model.ReviewTypeReject:
+		reviewType = events.Reject
+	case issues_model.ReviewTypeComment:
+		reviewType = events.Comment
+	default:
+		log.Debug(`review type %d is not sent`, options.review.Type)
+		return nil
+	}
+
+	metadata := newMetadata()
+	reviewEvent := events.ReviewEvent{
+		Context:  newContext(generic.REVIEW, generic.SUBMIT, options.review.UpdatedUnix, options.tenant),
+		Metadata: metadata,
+		Payload: events.ReviewPayload{
+			InitiatorUser:  events.InitiatorUser{Id: options.doerId},
+			PullRequest:    newPullRequestInfo(options.pr, options.authorId),
+			RepositoryInfo: newRepositoryInfo(options.pr.BaseRepo, options.tenant),
+			Review: events.ReviewInfo{
+				Content: optionalString(options.review.Content),
+				Id:      strconv.FormatInt(options.review.ID, 10),
+				Type:    reviewType,
+			},
+		},
+	}
+
+	return produceEvent(ctx, setting.KafkaReviewTopic, options.pr.BaseRepoID, metadata, reviewEvent)
+}
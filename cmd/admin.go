@@ -40,6 +40,9 @@ var (
 			subcmdRegenerate,
 			subcmdAuth,
 			subcmdSendMail,
+			subcmdPrivileges,
+			subcmdAudit,
+			subcmdKafkaOutbox,
 		},
 	}
 
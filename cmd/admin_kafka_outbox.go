@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	kafka_outbox_model "code.gitea.io/gitea/models/kafka_outbox"
+	"code.gitea.io/gitea/modules/sbt/audit"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	subcmdKafkaOutbox = cli.Command{
+		Name:  "kafka-outbox",
+		Usage: "Manage Kafka outbox messages that failed after all attempts and stopped messages with the same key",
+		Subcommands: []cli.Command{
+			microcmdKafkaOutboxFailed,
+			microcmdKafkaOutboxRetry,
+			microcmdKafkaOutboxSkip,
+		},
+	}
+
+	kafkaOutboxMessageIDFlag = cli.Int64Flag{
+		Name:  "id",
+		Usage: "ID of the failed outbox message",
+	}
+
+	microcmdKafkaOutboxFailed = cli.Command{
+		Name:   "failed",
+		Usage:  "List failed outbox messages",
+		Action: runKafkaOutboxFailed,
+	}
+
+	microcmdKafkaOutboxRetry = cli.Command{
+		Name:   "retry",
+		Usage:  "Return the failed outbox message to the queue with attempts reset",
+		Action: runKafkaOutboxRetry,
+		Flags:  []cli.Flag{kafkaOutboxMessageIDFlag},
+	}
+
+	microcmdKafkaOutboxSkip = cli.Command{
+		Name:   "skip",
+		Usage:  "Skip the failed outbox message and send the next messages with the same key",
+		Action: runKafkaOutboxSkip,
+		Flags:  []cli.Flag{kafkaOutboxMessageIDFlag},
+	}
+)
+
+func runKafkaOutboxFailed(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+	messages, err := kafka_outbox_model.GetFailedMessages(ctx)
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		fmt.Printf("%d\t%s\t%s\t%d\t%s\n", msg.ID, msg.TopicKey, msg.MessageKey, msg.Attempts, msg.LastError)
+	}
+	return nil
+}
+
+func runKafkaOutboxRetry(c *cli.Context) error {
+	return changeFailedKafkaOutboxMessage(c, kafka_outbox_model.RetryFailedMessage, audit.KafkaOutboxMessageRetryEvent)
+}
+
+func runKafkaOutboxSkip(c *cli.Context) error {
+	return changeFailedKafkaOutboxMessage(c, kafka_outbox_model.SkipFailedMessage, audit.KafkaOutboxMessageSkipEvent)
+}
+
+// changeFailedKafkaOutboxMessage повторяет или пропускает ошибочное событие и отправляет событие аудита
+func changeFailedKafkaOutboxMessage(c *cli.Context, change func(ctx context.Context, id int64) error, event audit.Event) error {
+	if !c.IsSet("id") {
+		return errors.New("--id is required")
+	}
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+	id := c.Int64("id")
+	auditParams := map[string]string{
+		"message_id": strconv.FormatInt(id, 10),
+	}
+	if err := change(ctx, id); err != nil {
+		auditParams["error"] = "Error has occurred while changing failed Kafka outbox message"
+		audit.CreateAndSendEvent(event, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+		return err
+	}
+	audit.CreateAndSendEvent(event, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusSuccess, audit.EmptyRequiredField, auditParams)
+	return nil
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/role_model"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	audit_utils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/privileges"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	subcmdPrivileges = cli.Command{
+		Name:  "privileges",
+		Usage: "Manage privileges of the role model as declarative state",
+		Subcommands: []cli.Command{
+			microcmdPrivilegesExport,
+			microcmdPrivilegesPlan,
+			microcmdPrivilegesApply,
+		},
+	}
+
+	privilegesFileFlag = cli.StringFlag{
+		Name:  "file, f",
+		Usage: "Path to the privileges state file, standard input/output if empty",
+	}
+	privilegesFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Value: "yaml",
+		Usage: "Format of the privileges state: yaml or json",
+	}
+
+	microcmdPrivilegesExport = cli.Command{
+		Name:   "export",
+		Usage:  "Export privileges of tenants to the state file",
+		Action: runPrivilegesExport,
+		Flags: []cli.Flag{
+			privilegesFileFlag,
+			privilegesFormatFlag,
+			cli.StringSliceFlag{
+				Name:  "tenant",
+				Usage: "Key of the tenant to be exported, can be repeated. Either --tenant or --all-tenants is required",
+			},
+			cli.BoolFlag{
+				Name:  "all-tenants",
+				Usage: "Export privileges of all tenants",
+			},
+		},
+	}
+
+	microcmdPrivilegesPlan = cli.Command{
+		Name:   "plan",
+		Usage:  "Show privileges to be added and removed to apply the state file",
+		Action: runPrivilegesPlan,
+		Flags: []cli.Flag{
+			privilegesFileFlag,
+			privilegesFormatFlag,
+		},
+	}
+
+	microcmdPrivilegesApply = cli.Command{
+		Name:   "apply",
+		Usage:  "Apply the state file to the role model",
+		Action: runPrivilegesApply,
+		Flags: []cli.Flag{
+			privilegesFileFlag,
+			privilegesFormatFlag,
+			cli.BoolFlag{
+				Name:  "force",
+				Usage: "Apply the state even if it removes more privileges than PRIVILEGES_STATE_MAX_REMOVALS",
+			},
+		},
+	}
+)
+
+func runPrivilegesExport(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	service, err := initPrivilegesService(ctx)
+	if err != nil {
+		return err
+	}
+	state, warnings, err := service.ExportPrivilegesState(ctx, c.StringSlice("tenant"), c.Bool("all-tenants"))
+	if err != nil {
+		return fmt.Errorf("export privileges state: %w", err)
+	}
+	printPrivilegesWarnings(warnings)
+	return writePrivilegesFile(c, state)
+}
+
+func runPrivilegesPlan(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	service, err := initPrivilegesService(ctx)
+	if err != nil {
+		return err
+	}
+	state, err := readPrivilegesFile(c)
+	if err != nil {
+		return err
+	}
+	plan, err := service.PlanPrivilegesState(ctx, state)
+	if err != nil {
+		return fmt.Errorf("plan privileges state: %w", err)
+	}
+	printPrivilegesWarnings(plan.Warnings)
+	plan.Warnings = nil
+	return writePrivilegesOutput(os.Stdout, c.String("format"), plan)
+}
+
+func runPrivilegesApply(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	service, err := initPrivilegesService(ctx)
+	if err != nil {
+		return err
+	}
+	state, err := readPrivilegesFile(c)
+	if err != nil {
+		return err
+	}
+	auditInfo := audit_utils.AuditRequiredParams{
+		DoerName:      audit.EmptyRequiredField,
+		DoerID:        audit.EmptyRequiredField,
+		RemoteAddress: audit.EmptyRequiredField,
+	}
+	result, err := service.ApplyPrivilegesState(ctx, state, c.Bool("force"), auditInfo)
+	if err != nil {
+		return fmt.Errorf("apply privileges state: %w", err)
+	}
+	printPrivilegesWarnings(result.Plan.Warnings)
+	if !result.Applied {
+		fmt.Fprintln(os.Stderr, "Privileges are up to date")
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "Privileges state was successfully applied")
+	result.Plan.Warnings = nil
+	return writePrivilegesOutput(os.Stdout, c.String("format"), result.Plan)
+}
+
+// initPrivilegesService инициализирует БД и ролевую модель для работы с привилегиями
+func initPrivilegesService(ctx context.Context) (privileges.PrivilegesProcessor, error) {
+	if err := initDB(ctx); err != nil {
+		return nil, err
+	}
+	if !setting.SourceControl.TenantWithRoleModeEnabled {
+		return nil, errors.New("role model is disabled")
+	}
+	if err := role_model.InitRoleModel(); err != nil {
+		return nil, fmt.Errorf("init role model: %w", err)
+	}
+	return privileges.NewPrivilege(db.GetEngine(ctx), role_model.GetSecurityEnforcer())
+}
+
+func readPrivilegesFile(c *cli.Context) (forms.PrivilegesState, error) {
+	var (
+		state forms.PrivilegesState
+		data  []byte
+		err   error
+	)
+	if path := c.String("file"); path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return state, fmt.Errorf("read privileges state: %w", err)
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		err = json.Unmarshal(data, &state)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &state)
+	default:
+		return state, fmt.Errorf("unknown format: %s", c.String("format"))
+	}
+	if err != nil {
+		return state, fmt.Errorf("parse privileges state: %w", err)
+	}
+	return state, nil
+}
+
+func writePrivilegesFile(c *cli.Context, value interface{}) error {
+	path := c.String("file")
+	if path == "" {
+		return writePrivilegesOutput(os.Stdout, c.String("format"), value)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create privileges state file: %w", err)
+	}
+	defer f.Close()
+	return writePrivilegesOutput(f, c.String("format"), value)
+}
+
+func writePrivilegesOutput(w io.Writer, format string, value interface{}) error {
+	switch strings.ToLower(format) {
+	case "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "yaml", "yml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func printPrivilegesWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// Поля события аудита, которые хранятся в отдельных колонках, остальные поля сохраняются в Params
+const (
+	fieldID            = "id"
+	fieldEvent         = "event"
+	fieldDate          = "eventdate"
+	fieldUsername      = "username"
+	fieldInternalID    = "internal_id"
+	fieldStatus        = "status"
+	fieldUserIP        = "user_ip"
+	fieldHostName      = "host.name"
+	fieldHostIP        = "host.ip"
+	fieldFormatVersion = "format_version"
+	fieldSeq           = "seq"
+	fieldPrevHash      = "prev_hash"
+	fieldHash          = "hash"
+)
+
+// dateTimeFormat формат времени события аудита
+const dateTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Event структура таблицы audit_event, событие аудита
+type Event struct {
+	ID         int64              `xorm:"pk autoincr"`
+	EventID    string             `xorm:"VARCHAR(36) UNIQUE NOT NULL"`
+	Type       string             `xorm:"VARCHAR(255) INDEX NOT NULL"`
+	Status     string             `xorm:"VARCHAR(20) INDEX"`
+	UserName   string             `xorm:"VARCHAR(255) INDEX"`
+	UserID     string             `xorm:"VARCHAR(50)"`
+	UserIP     string             `xorm:"VARCHAR(64) INDEX"`
+	Tenant     string             `xorm:"VARCHAR(255) INDEX"`
+	Repository string             `xorm:"VARCHAR(512) INDEX"`
+	HostName   string             `xorm:"VARCHAR(255)"`
+	HostIP     string             `xorm:"VARCHAR(64)"`
+	Params     map[string]string  `xorm:"JSON TEXT"`
+	EventUnix  timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	Seq        int64              `xorm:"INDEX NOT NULL DEFAULT 0"` // номер сообщения в цепочке хешей узла, 0 - сообщение вне цепочки
+	Raw        string             `xorm:"TEXT"`                     // исходное сообщение для проверки цепочки хешей
+}
+
+func init() {
+	db.RegisterModel(new(Event))
+}
+
+// TableName возвращает имя таблицы событий аудита
+func (Event) TableName() string {
+	return "audit_event"
+}
+
+// ParseEvent разбирает событие аудита из "плоского" json, в котором оно пишется в файл
+func ParseEvent(data []byte) (*Event, error) {
+	fields := make(map[string]string)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal audit event: %w", err)
+	}
+	if fields[fieldID] == "" || fields[fieldEvent] == "" {
+		return nil, fmt.Errorf("audit event has no id or event")
+	}
+
+	event := &Event{
+		EventID:  fields[fieldID],
+		Type:     fields[fieldEvent],
+		Status:   fields[fieldStatus],
+		UserName: fields[fieldUsername],
+		UserID:   fields[fieldInternalID],
+		UserIP:   fields[fieldUserIP],
+		HostName: fields[fieldHostName],
+		HostIP:   fields[fieldHostIP],
+		Params:   make(map[string]string),
+		Raw:      string(data),
+	}
+	date, err := time.Parse(dateTimeFormat, fields[fieldDate])
+	if err != nil {
+		return nil, fmt.Errorf("parse audit event date: %w", err)
+	}
+	event.EventUnix = timeutil.TimeStamp(date.Unix())
+	if seq := fields[fieldSeq]; seq != "" {
+		if event.Seq, err = strconv.ParseInt(seq, 10, 64); err != nil {
+			return nil, fmt.Errorf("parse audit event seq: %w", err)
+		}
+	}
+
+	for key, value := range fields {
+		switch key {
+		case fieldID, fieldEvent, fieldDate, fieldUsername, fieldInternalID, fieldStatus, fieldUserIP, fieldHostName, fieldHostIP, fieldFormatVersion,
+			fieldSeq, fieldPrevHash, fieldHash:
+		default:
+			event.Params[key] = value
+		}
+	}
+
+	// тенант и репозиторий события передаются в параметрах под разными именами
+	event.Tenant = firstNotEmpty(event.Params["tenant_key"], event.Params["tenant_id"])
+	event.Repository = firstNotEmpty(event.Params["repository"], event.Params["repository_key"])
+	if owner := event.Params["owner"]; owner != "" && event.Params["repository"] != "" {
+		event.Repository = owner + "/" + event.Params["repository"]
+	}
+	return event, nil
+}
+
+// InsertEvents сохраняет события аудита. Уже сохраненные события пропускаются, поэтому повторная доставка безопасна
+func InsertEvents(ctx context.Context, events []*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.EventID)
+		}
+		existing := make([]string, 0)
+		if err := db.GetEngine(ctx).Table("audit_event").In("event_id", ids).Cols("event_id").Find(&existing); err != nil {
+			return fmt.Errorf("error has occurred while getting existing audit events: %w", err)
+		}
+		saved := make(map[string]struct{}, len(existing))
+		for _, id := range existing {
+			saved[id] = struct{}{}
+		}
+
+		for _, event := range events {
+			if _, ok := saved[event.EventID]; ok {
+				continue
+			}
+			saved[event.EventID] = struct{}{}
+			if _, err := db.GetEngine(ctx).Insert(event); err != nil {
+				return fmt.Errorf("error has occurred while inserting audit event: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SearchEventsOptions параметры поиска событий аудита
+type SearchEventsOptions struct {
+	db.ListOptions
+	Type       string
+	UserName   string
+	UserIP     string
+	Tenant     string
+	Repository string
+	Status     string
+	From       timeutil.TimeStamp
+	To         timeutil.TimeStamp
+}
+
+// ToConds возвращает условия поиска событий аудита
+func (opts *SearchEventsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Type != "" {
+		cond = cond.And(builder.Eq{"type": opts.Type})
+	}
+	if opts.UserName != "" {
+		cond = cond.And(builder.Eq{"user_name": opts.UserName})
+	}
+	if opts.UserIP != "" {
+		cond = cond.And(builder.Eq{"user_ip": opts.UserIP})
+	}
+	if opts.Tenant != "" {
+		cond = cond.And(builder.Eq{"tenant": opts.Tenant})
+	}
+	if opts.Repository != "" {
+		cond = cond.And(builder.Eq{"repository": opts.Repository})
+	}
+	if opts.Status != "" {
+		cond = cond.And(builder.Eq{"status": strings.ToUpper(opts.Status)})
+	}
+	if opts.From > 0 {
+		cond = cond.And(builder.Gte{"event_unix": opts.From})
+	}
+	if opts.To > 0 {
+		cond = cond.And(builder.Lte{"event_unix": opts.To})
+	}
+	return cond
+}
+
+// SearchEvents возвращает страницу событий аудита от новых к старым и общее количество найденных событий
+func SearchEvents(ctx context.Context, opts *SearchEventsOptions) ([]*Event, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.ToConds()).Desc("event_unix", "id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	events := make([]*Event, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&events)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error has occurred while searching audit events: %w", err)
+	}
+	return events, count, nil
+}
+
+// IterateChainedEvents обходит события аудита, связанные цепочкой хешей, в порядке сохранения
+func IterateChainedEvents(ctx context.Context, batchSize int, f func(event *Event) error) error {
+	var lastID int64
+	for {
+		events := make([]*Event, 0, batchSize)
+		if err := db.GetEngine(ctx).Where(builder.Gt{"seq": 0}.And(builder.Gt{"id": lastID})).Asc("id").Limit(batchSize).Find(&events); err != nil {
+			return fmt.Errorf("error has occurred while getting chained audit events: %w", err)
+		}
+		for _, event := range events {
+			if err := f(event); err != nil {
+				return err
+			}
+			lastID = event.ID
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+	}
+}
+
+// DeleteEventsBefore удаляет события аудита старше before
+func DeleteEventsBefore(ctx context.Context, before timeutil.TimeStamp) (int64, error) {
+	deleted, err := db.GetEngine(ctx).Where(builder.Lt{"event_unix": before}).Delete(new(Event))
+	if err != nil {
+		return 0, fmt.Errorf("error has occurred while deleting audit events: %w", err)
+	}
+	return deleted, nil
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
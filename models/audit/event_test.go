@@ -0,0 +1,72 @@
+//go:build !correct
+
+package audit
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+// TestParseEvent проверяет разбор события аудита из файла
+func TestParseEvent(t *testing.T) {
+	data := []byte(`{"id":"0f8fad5b-d9cb-469f-a165-70867728950e","event":"Create user","eventdate":"2024-03-01T10:15:30.000+03:00",` +
+		`"username":"admin","internal_id":"1","status":"SUCCESS","user_ip":"127.0.0.1","host.name":"sc","host.ip":"10.0.0.1",` +
+		`"format_version":"1.0","owner":"org","repository":"repo","tenant_key":"tenant","affected_user":"user2"}`)
+
+	event, err := ParseEvent(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "0f8fad5b-d9cb-469f-a165-70867728950e", event.EventID)
+	assert.Equal(t, "Create user", event.Type)
+	assert.Equal(t, "SUCCESS", event.Status)
+	assert.Equal(t, "admin", event.UserName)
+	assert.Equal(t, "1", event.UserID)
+	assert.Equal(t, "127.0.0.1", event.UserIP)
+	assert.Equal(t, "sc", event.HostName)
+	assert.Equal(t, "10.0.0.1", event.HostIP)
+	assert.Equal(t, timeutil.TimeStamp(1709277330), event.EventUnix)
+	assert.Equal(t, "tenant", event.Tenant)
+	assert.Equal(t, "org/repo", event.Repository)
+	assert.Equal(t, map[string]string{"owner": "org", "repository": "repo", "tenant_key": "tenant", "affected_user": "user2"}, event.Params)
+
+	chained := []byte(`{"id":"1","event":"Create user","eventdate":"2024-03-01T10:15:30.000Z","seq":"3","prev_hash":"a","hash":"b"}`)
+	event, err = ParseEvent(chained)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), event.Seq)
+	assert.Equal(t, string(chained), event.Raw)
+	assert.Empty(t, event.Params)
+
+	for _, data := range []string{
+		`not json`,
+		`{"event":"Create user","eventdate":"2024-03-01T10:15:30.000+03:00"}`,
+		`{"id":"1","event":"Create user","eventdate":"yesterday"}`,
+		`{"id":"1","event":"Create user","eventdate":"2024-03-01T10:15:30.000Z","seq":"first"}`,
+	} {
+		_, err = ParseEvent([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+// TestSearchEventsOptionsToConds проверяет условия поиска событий аудита
+func TestSearchEventsOptionsToConds(t *testing.T) {
+	sql, args, err := builder.ToSQL((&SearchEventsOptions{}).ToConds())
+	assert.NoError(t, err)
+	assert.Empty(t, sql)
+	assert.Empty(t, args)
+
+	opts := &SearchEventsOptions{
+		Type:       "Create user",
+		UserName:   "admin",
+		Repository: "org/repo",
+		Status:     "fail",
+		From:       100,
+		To:         200,
+	}
+	sql, args, err = builder.ToSQL(opts.ToConds())
+	assert.NoError(t, err)
+	assert.Equal(t, "type=? AND user_name=? AND repository=? AND status=? AND event_unix>=? AND event_unix<=?", sql)
+	assert.Equal(t, []interface{}{"Create user", "admin", "org/repo", "FAIL", timeutil.TimeStamp(100), timeutil.TimeStamp(200)}, args)
+}
@@ -0,0 +1,62 @@
+package background_task
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+var (
+	queueNamesMu sync.RWMutex
+	queueNames   = map[string]struct{}{}
+)
+
+// Queue очередь фоновых задач с нагрузкой типа T. Нагрузка хранится в задаче в формате JSON
+type Queue[T any] struct {
+	Name string
+}
+
+// NewQueue создает очередь с именем name. Имя сохраняется в задачах и не должно меняться.
+// Очередь регистрируется и становится доступной для администрирования
+func NewQueue[T any](name string) Queue[T] {
+	queueNamesMu.Lock()
+	defer queueNamesMu.Unlock()
+	queueNames[name] = struct{}{}
+	return Queue[T]{Name: name}
+}
+
+// QueueNames возвращает имена зарегистрированных очередей по алфавиту
+func QueueNames() []string {
+	queueNamesMu.RLock()
+	defer queueNamesMu.RUnlock()
+	names := make([]string, 0, len(queueNames))
+	for name := range queueNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Enqueue ставит задачу с нагрузкой payload в очередь. Если ctx содержит транзакцию, задача сохраняется в ней
+func (q Queue[T]) Enqueue(ctx context.Context, payload T, opts EnqueueOptions) (*Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload of queue %s: %w", q.Name, err)
+	}
+	return Insert(ctx, q.Name, string(data), opts)
+}
+
+// Decode возвращает нагрузку задачи очереди
+func (q Queue[T]) Decode(task *Task) (T, error) {
+	var payload T
+	if task.Queue != q.Name {
+		return payload, fmt.Errorf("task %d belongs to queue %s, not %s", task.ID, task.Queue, q.Name)
+	}
+	if err := json.Unmarshal([]byte(task.Payload), &payload); err != nil {
+		return payload, fmt.Errorf("unmarshal payload of task %d: %w", task.ID, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,52 @@
+//go:build !correct
+
+package background_task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/builder"
+)
+
+type testPayload struct {
+	RepoID int64  `json:"repo_id"`
+	Name   string `json:"name"`
+}
+
+func TestQueue_Decode(t *testing.T) {
+	queue := NewQueue[testPayload]("test")
+
+	t.Run("payload", func(t *testing.T) {
+		payload, err := queue.Decode(&Task{ID: 1, Queue: "test", Payload: `{"repo_id":2,"name":"repo"}`})
+		require.NoError(t, err)
+		assert.Equal(t, testPayload{RepoID: 2, Name: "repo"}, payload)
+	})
+
+	t.Run("other queue", func(t *testing.T) {
+		_, err := queue.Decode(&Task{ID: 1, Queue: "other", Payload: `{}`})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := queue.Decode(&Task{ID: 1, Queue: "test", Payload: `{"repo_id":"two"}`})
+		assert.Error(t, err)
+	})
+}
+
+func TestLeasedTasksCond(t *testing.T) {
+	sql, args, err := builder.ToSQL(leasedTasksCond("test", "host:1", []int64{1, 2}))
+	require.NoError(t, err)
+	assert.Equal(t, "lease_owner=? AND queue=? AND status=? AND id IN (?,?)", sql)
+	assert.Equal(t, []any{"host:1", "test", StatusRunning, int64(1), int64(2)}, args)
+}
+
+func TestQueueNames(t *testing.T) {
+	NewQueue[testPayload]("test_b")
+	NewQueue[testPayload]("test_a")
+
+	names := QueueNames()
+	assert.Subset(t, names, []string{"test_a", "test_b"})
+	assert.IsNonDecreasing(t, names)
+}
@@ -0,0 +1,319 @@
+package background_task
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Task))
+}
+
+type Status string
+
+const (
+	StatusPending Status = "pending" // задача ожидает обработки
+	StatusRunning Status = "running" // задача обрабатывается, пока не истекла аренда
+	StatusDone    Status = "done"    // задача обработана, сохраняется только для очередей с KeepDone
+	StatusDead    Status = "dead"    // задача не обработана после всех попыток и ждет повтора администратором
+)
+
+// Statuses состояния задач в порядке жизненного цикла
+var Statuses = []Status{StatusPending, StatusRunning, StatusDead, StatusDone}
+
+// Task структура таблицы background_task, задача очереди фоновых задач в БД
+type Task struct {
+	ID         int64              `xorm:"pk autoincr"`
+	Queue      string             `xorm:"VARCHAR(64) NOT NULL INDEX(queue_claim)"`
+	Status     Status             `xorm:"VARCHAR(20) NOT NULL INDEX(queue_claim)"`
+	Priority   int                `xorm:"NOT NULL DEFAULT 0"` // задачи с большим приоритетом обрабатываются раньше
+	Payload    string             `xorm:"LONGTEXT NOT NULL"`  // JSON типизированной нагрузки очереди
+	Attempts   int                `xorm:"NOT NULL DEFAULT 0"` // количество начатых попыток, включая прерванные
+	LastError  string             `xorm:"TEXT"`
+	RunAt      timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0 INDEX(queue_claim)"` // задача не обрабатывается раньше этого времени
+	LeaseOwner string             `xorm:"VARCHAR(255)"`                          // экземпляр приложения, обрабатывающий задачу
+	LeaseUntil timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`                    // после истечения аренды задача возвращается в очередь
+	CreatedAt  timeutil.TimeStamp `xorm:"created"`
+	UpdatedAt  timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName возвращает имя таблицы фоновых задач
+func (Task) TableName() string {
+	return "background_task"
+}
+
+// EnqueueOptions параметры постановки задачи в очередь
+type EnqueueOptions struct {
+	Priority int
+	// Delay задержка перед первой обработкой задачи
+	Delay time.Duration
+}
+
+// Insert сохраняет задачу очереди queue с нагрузкой payload в формате JSON. Если ctx содержит транзакцию,
+// задача сохраняется в ней и обрабатывается только вместе с изменением, которое ее породило
+func Insert(ctx context.Context, queue, payload string, opts EnqueueOptions) (*Task, error) {
+	task := &Task{
+		Queue:    queue,
+		Status:   StatusPending,
+		Priority: opts.Priority,
+		Payload:  payload,
+		RunAt:    timeutil.TimeStamp(time.Now().Add(opts.Delay).Unix()),
+	}
+	if _, err := db.GetEngine(ctx).Insert(task); err != nil {
+		return nil, fmt.Errorf("insert task of queue %s: %w", queue, err)
+	}
+	return task, nil
+}
+
+// Claim арендует до limit готовых к обработке задач очереди на время lease и увеличивает количество их попыток.
+// Задачи выбираются по убыванию приоритета, затем в порядке постановки. В PostgreSQL задачи выбираются
+// с FOR UPDATE SKIP LOCKED, поэтому экземпляры приложения не ждут друг друга и не получают одни и те же задачи.
+// В остальных БД каждая задача арендуется условным обновлением, задачи, арендованные другим экземпляром, пропускаются
+func Claim(ctx context.Context, queue, owner string, limit int, lease time.Duration) ([]*Task, error) {
+	now := timeutil.TimeStampNow()
+	leaseUntil := timeutil.TimeStamp(time.Now().Add(lease).Unix())
+
+	if setting.Database.Type.IsPostgreSQL() {
+		return claimSkipLocked(ctx, queue, owner, limit, now, leaseUntil)
+	}
+
+	candidates := make([]*Task, 0, limit)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"queue": queue, "status": StatusPending}.And(builder.Lte{"run_at": now})).
+		OrderBy("priority DESC, id ASC").
+		Limit(limit).
+		Find(&candidates); err != nil {
+		return nil, fmt.Errorf("find pending tasks of queue %s: %w", queue, err)
+	}
+
+	tasks := make([]*Task, 0, len(candidates))
+	for _, task := range candidates {
+		affected, err := db.GetEngine(ctx).
+			Where(builder.Eq{"id": task.ID, "status": StatusPending}).
+			Incr("attempts").
+			Cols("status", "lease_owner", "lease_until").
+			Update(&Task{Status: StatusRunning, LeaseOwner: owner, LeaseUntil: leaseUntil})
+		if err != nil {
+			return tasks, fmt.Errorf("claim task %d: %w", task.ID, err)
+		}
+		if affected == 0 {
+			continue
+		}
+		task.Status = StatusRunning
+		task.Attempts++
+		task.LeaseOwner = owner
+		task.LeaseUntil = leaseUntil
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func claimSkipLocked(ctx context.Context, queue, owner string, limit int, now, leaseUntil timeutil.TimeStamp) ([]*Task, error) {
+	const claimQuery = `
+UPDATE background_task
+SET status = ?, attempts = attempts + 1, lease_owner = ?, lease_until = ?, updated_at = ?
+WHERE id IN (
+	SELECT id FROM background_task
+	WHERE queue = ? AND status = ? AND run_at <= ?
+	ORDER BY priority DESC, id ASC
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+	tasks := make([]*Task, 0, limit)
+	if err := db.GetEngine(ctx).
+		SQL(claimQuery, StatusRunning, owner, leaseUntil, now, queue, StatusPending, now, limit).
+		Find(&tasks); err != nil {
+		return nil, fmt.Errorf("claim tasks of queue %s: %w", queue, err)
+	}
+	// RETURNING не сохраняет порядок подзапроса
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority > tasks[j].Priority
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks, nil
+}
+
+// leasedTasksCond условие выбора задач ids очереди queue, арендованных экземпляром owner
+func leasedTasksCond(queue, owner string, ids []int64) builder.Cond {
+	return builder.Eq{"queue": queue, "status": StatusRunning, "lease_owner": owner}.And(builder.In("id", ids))
+}
+
+// ExtendLeases продлевает на время lease аренду задач ids, которые все еще арендованы экземпляром owner.
+// Возвращает количество задач с продленной арендой
+func ExtendLeases(ctx context.Context, queue, owner string, ids []int64, lease time.Duration) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	affected, err := db.GetEngine(ctx).
+		Where(leasedTasksCond(queue, owner, ids)).
+		Cols("lease_until").
+		Update(&Task{LeaseUntil: timeutil.TimeStamp(time.Now().Add(lease).Unix())})
+	if err != nil {
+		return 0, fmt.Errorf("extend leases of queue %s: %w", queue, err)
+	}
+	return affected, nil
+}
+
+// Complete завершает арендованную задачу: удаляет ее или, если keep, переводит в состояние done.
+// Возвращает false, если аренда истекла и задача уже возвращена в очередь
+func Complete(ctx context.Context, task *Task, keep bool) (bool, error) {
+	cond := builder.Eq{"id": task.ID, "status": StatusRunning, "lease_owner": task.LeaseOwner}
+	if !keep {
+		deleted, err := db.GetEngine(ctx).Where(cond).Delete(new(Task))
+		if err != nil {
+			return false, fmt.Errorf("delete task %d: %w", task.ID, err)
+		}
+		return deleted > 0, nil
+	}
+
+	task.Status = StatusDone
+	task.LeaseOwner = ""
+	task.LeaseUntil = 0
+	affected, err := db.GetEngine(ctx).
+		Where(cond).
+		Cols("status", "lease_owner", "lease_until").
+		Update(task)
+	if err != nil {
+		return false, fmt.Errorf("complete task %d: %w", task.ID, err)
+	}
+	return affected > 0, nil
+}
+
+// Fail снимает аренду с задачи после неудачной попытки и сохраняет ошибку. Если dead, задача переводится
+// в состояние dead, иначе обрабатывается повторно не раньше runAt. Возвращает false, если аренда истекла
+func Fail(ctx context.Context, task *Task, taskErr error, runAt timeutil.TimeStamp, dead bool) (bool, error) {
+	owner := task.LeaseOwner
+	task.Status = StatusPending
+	if dead {
+		task.Status = StatusDead
+	}
+	task.LastError = taskErr.Error()
+	task.RunAt = runAt
+	task.LeaseOwner = ""
+	task.LeaseUntil = 0
+
+	affected, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": task.ID, "status": StatusRunning, "lease_owner": owner}).
+		Cols("status", "last_error", "run_at", "lease_owner", "lease_until").
+		Update(task)
+	if err != nil {
+		return false, fmt.Errorf("fail task %d: %w", task.ID, err)
+	}
+	return affected > 0, nil
+}
+
+// RecoverExpired возвращает в очередь задачи с истекшей арендой, например если экземпляр приложения был остановлен
+// во время обработки. Задачи, исчерпавшие maxAttempts попыток, переводятся в состояние dead. Если maxAttempts
+// не задано, количество попыток не ограничено. Возвращает количество возвращенных и переведенных в dead задач
+func RecoverExpired(ctx context.Context, queue string, maxAttempts int) (recovered, dead int64, err error) {
+	now := timeutil.TimeStampNow()
+	expired := builder.Eq{"queue": queue, "status": StatusRunning}.And(builder.Lt{"lease_until": now})
+
+	if maxAttempts > 0 {
+		dead, err = db.GetEngine(ctx).
+			Where(expired.And(builder.Gte{"attempts": maxAttempts})).
+			Cols("status", "last_error", "lease_owner", "lease_until").
+			Update(&Task{Status: StatusDead, LastError: "lease expired"})
+		if err != nil {
+			return 0, 0, fmt.Errorf("move expired tasks of queue %s to dead: %w", queue, err)
+		}
+	}
+
+	recovered, err = db.GetEngine(ctx).
+		Where(expired).
+		Cols("status", "last_error", "run_at", "lease_owner", "lease_until").
+		Update(&Task{Status: StatusPending, LastError: "lease expired", RunAt: now})
+	if err != nil {
+		return 0, dead, fmt.Errorf("recover expired tasks of queue %s: %w", queue, err)
+	}
+	return recovered, dead, nil
+}
+
+// FindOptions параметры поиска задач для администрирования, пустой Status означает задачи в любом состоянии
+type FindOptions struct {
+	db.ListOptions
+	Queue  string
+	Status Status
+}
+
+// Find возвращает страницу задач очереди, начиная с последних, и общее количество найденных задач
+func Find(ctx context.Context, opts FindOptions) ([]*Task, int64, error) {
+	cond := builder.NewCond().And(builder.Eq{"queue": opts.Queue})
+	if opts.Status != "" {
+		cond = cond.And(builder.Eq{"status": opts.Status})
+	}
+
+	tasks := make([]*Task, 0, opts.PageSize)
+	sess := db.GetEngine(ctx).Where(cond).OrderBy("id DESC")
+	if !opts.IsListAll() {
+		skip, take := opts.GetSkipTake()
+		sess = sess.Limit(take, skip)
+	}
+	count, err := sess.FindAndCount(&tasks)
+	if err != nil {
+		return nil, 0, fmt.Errorf("find tasks of queue %s: %w", opts.Queue, err)
+	}
+	return tasks, count, nil
+}
+
+// Retry возвращает ожидающую или dead задачу в очередь для немедленной обработки со сброшенным количеством попыток.
+// Возвращает false, если такой задачи нет
+func Retry(ctx context.Context, queue string, id int64) (bool, error) {
+	affected, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": id, "queue": queue}.And(builder.In("status", StatusPending, StatusDead))).
+		Cols("status", "attempts", "run_at").
+		Update(&Task{Status: StatusPending, RunAt: timeutil.TimeStampNow()})
+	if err != nil {
+		return false, fmt.Errorf("retry task %d: %w", id, err)
+	}
+	return affected > 0, nil
+}
+
+// Discard удаляет ожидающую, dead или обработанную задачу. Возвращает false, если такой задачи нет
+func Discard(ctx context.Context, queue string, id int64) (bool, error) {
+	deleted, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": id, "queue": queue}.And(builder.In("status", StatusPending, StatusDead, StatusDone))).
+		Delete(new(Task))
+	if err != nil {
+		return false, fmt.Errorf("discard task %d: %w", id, err)
+	}
+	return deleted > 0, nil
+}
+
+// CountByStatus возвращает количество задач очереди в каждом состоянии
+func CountByStatus(ctx context.Context, queue string) (map[Status]int64, error) {
+	type statusCount struct {
+		Status Status
+		Count  int64
+	}
+	rows := make([]statusCount, 0, len(Statuses))
+	if err := db.GetEngine(ctx).
+		Table("background_task").
+		Select("status, COUNT(*) AS count").
+		Where(builder.Eq{"queue": queue}).
+		GroupBy("status").
+		Find(&rows); err != nil {
+		return nil, fmt.Errorf("count tasks of queue %s: %w", queue, err)
+	}
+
+	counts := make(map[Status]int64, len(Statuses))
+	for _, status := range Statuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
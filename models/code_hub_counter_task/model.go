@@ -1,38 +1,28 @@
 package code_hub_counter_task
 
 import (
-	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/background_task"
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
-func init() {
-	db.RegisterModel(new(CodeHubCounterTasks))
-}
-
 type CodeHubAction string
 
 const (
 	CloneRepositoryAction CodeHubAction = "clone_repository"
+	ForkRepositoryAction  CodeHubAction = "fork_repository"
+	StarRepositoryAction  CodeHubAction = "star_repository"
+	DownloadArchiveAction CodeHubAction = "download_archive"
+	DownloadPackageAction CodeHubAction = "download_package"
 )
 
-type Status string
-
-const (
-	StatusDone     Status = "done"
-	StatusUnlocked Status = "unlocked"
-	StatusLocked   Status = "locked"
-)
-
-// CodeHubCounterTasks модель для хранения тасок для подсчета статистики по уникальным использованиям репозитория
-type CodeHubCounterTasks struct {
-	ID int64 `xorm:"PK AUTOINCR" json:"-"`
-
-	UserID int64 `xorm:"NOT NULL" json:"user_id"`
-	RepoID int64 `xorm:"NOT NULL" json:"repo_id"`
-
-	Action CodeHubAction `xorm:"NOT NULL" json:"action"`
-	Status Status        `xorm:"NOT NULL" json:"status"`
+// Queue очередь подсчета статистики по использованиям репозитория
+var Queue = background_task.NewQueue[Payload]("code_hub_counter")
 
-	CreatedAt timeutil.TimeStamp `xorm:"CREATED" json:"-"`
-	UpdatedAt timeutil.TimeStamp `xorm:"UPDATED" json:"-"`
+// Payload нагрузка задачи подсчета статистики по использованиям репозитория
+type Payload struct {
+	UserID int64         `json:"user_id"`
+	RepoID int64         `json:"repo_id"`
+	Action CodeHubAction `json:"action"`
+	// At время действия, по нему действие попадает в дневную статистику. В задачах, созданных до его появления, не заполнено
+	At timeutil.TimeStamp `json:"at,omitempty"`
 }
@@ -0,0 +1,108 @@
+package code_hub_usage_stat_db
+
+import (
+	"context"
+	"fmt"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/code_hub_usage_stat"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+type codeHubUsageStatDB struct{}
+
+func New() codeHubUsageStatDB {
+	return codeHubUsageStatDB{}
+}
+
+// AddUsage увеличить значение метрики репозитория за день, в который попадает at
+func (c codeHubUsageStatDB) AddUsage(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, at timeutil.TimeStamp) error {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		return incrementStat(ctx, repoID, metric, code_hub_usage_stat.DayStart(at))
+	}); err != nil {
+		return fmt.Errorf("add code hub usage: %w", err)
+	}
+	return nil
+}
+
+// AddUniqueUsage учесть пользователя в уникальной метрике репозитория за день, в который попадает at.
+// Значение метрики увеличивается только при первом использовании пользователем за день
+func (c codeHubUsageStatDB) AddUniqueUsage(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, at timeutil.TimeStamp, userID int64) error {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		usageUser := &code_hub_usage_stat.CodeHubUsageUser{
+			RepoID: repoID,
+			Metric: metric,
+			Day:    code_hub_usage_stat.DayStart(at),
+			UserID: userID,
+		}
+
+		has, err := db.GetEngine(ctx).Exist(usageUser)
+		if err != nil {
+			return fmt.Errorf("check code hub usage user: %w", err)
+		}
+		if has {
+			return nil
+		}
+
+		if _, err = db.GetEngine(ctx).Insert(usageUser); err != nil {
+			return fmt.Errorf("insert code hub usage user: %w", err)
+		}
+		return incrementStat(ctx, repoID, metric, usageUser.Day)
+	}); err != nil {
+		return fmt.Errorf("add code hub unique usage: %w", err)
+	}
+	return nil
+}
+
+func incrementStat(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, day timeutil.TimeStamp) error {
+	updated, err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "metric": metric, "day": day}).
+		Incr("value").
+		Update(new(code_hub_usage_stat.CodeHubUsageStat))
+	if err != nil {
+		return fmt.Errorf("increment code hub usage stat: %w", err)
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	if _, err = db.GetEngine(ctx).Insert(&code_hub_usage_stat.CodeHubUsageStat{
+		RepoID: repoID,
+		Metric: metric,
+		Day:    day,
+		Value:  1,
+	}); err != nil {
+		return fmt.Errorf("insert code hub usage stat: %w", err)
+	}
+	return nil
+}
+
+// GetDailyStats получить значения метрики репозитория по дням в интервале [from, to)
+func (c codeHubUsageStatDB) GetDailyStats(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, from, to timeutil.TimeStamp) ([]*code_hub_usage_stat.CodeHubUsageStat, error) {
+	stats := make([]*code_hub_usage_stat.CodeHubUsageStat, 0)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "metric": metric}).
+		And(builder.Gte{"day": from}).
+		And(builder.Lt{"day": to}).
+		OrderBy("day").
+		Find(&stats); err != nil {
+		return nil, fmt.Errorf("find code hub usage stats: %w", err)
+	}
+	return stats, nil
+}
+
+// CountUniqueUsers получить количество уникальных пользователей метрики репозитория в интервале [from, to)
+func (c codeHubUsageStatDB) CountUniqueUsers(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, from, to timeutil.TimeStamp) (int64, error) {
+	count, err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "metric": metric}).
+		And(builder.Gte{"day": from}).
+		And(builder.Lt{"day": to}).
+		Distinct("user_id").
+		Count(new(code_hub_usage_stat.CodeHubUsageUser))
+	if err != nil {
+		return 0, fmt.Errorf("count code hub usage users: %w", err)
+	}
+	return count, nil
+}
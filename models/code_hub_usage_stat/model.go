@@ -0,0 +1,90 @@
+package code_hub_usage_stat
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(CodeHubUsageStat))
+	db.RegisterModel(new(CodeHubUsageUser))
+}
+
+// Metric метрика использования репозитория, собираемая по дням
+type Metric string
+
+const (
+	UniqueClonersMetric    Metric = "unique_cloners"    // уникальные пользователи, клонировавшие или скачавшие репозиторий
+	ForksMetric            Metric = "forks"             // созданные форки
+	StarsMetric            Metric = "stars"             // поставленные звезды
+	ArchiveDownloadsMetric Metric = "archive_downloads" // скачивания архивов
+	PackageDownloadsMetric Metric = "package_downloads" // скачивания пакетов, привязанных к репозиторию
+)
+
+// Metrics метрики использования репозитория, доступные по периодам
+var Metrics = []Metric{UniqueClonersMetric, ForksMetric, StarsMetric, ArchiveDownloadsMetric, PackageDownloadsMetric}
+
+// IsUnique проверяет, что метрика считает уникальных пользователей, а не события
+func (m Metric) IsUnique() bool {
+	return m == UniqueClonersMetric
+}
+
+// Interval размер периода, по которому группируется статистика
+type Interval string
+
+const (
+	IntervalDay  Interval = "day"
+	IntervalWeek Interval = "week"
+)
+
+const day = int64(24 * time.Hour / time.Second)
+
+// CodeHubUsageStat структура таблицы code_hub_usage_stat, значение метрики репозитория за день
+type CodeHubUsageStat struct {
+	ID        int64              `xorm:"PK AUTOINCR"`
+	RepoID    int64              `xorm:"NOT NULL UNIQUE(repo_metric_day)"`
+	Metric    Metric             `xorm:"VARCHAR(50) NOT NULL UNIQUE(repo_metric_day)"`
+	Day       timeutil.TimeStamp `xorm:"NOT NULL UNIQUE(repo_metric_day)"` // начало дня по UTC
+	Value     int64              `xorm:"NOT NULL DEFAULT 0"`
+	UpdatedAt timeutil.TimeStamp `xorm:"UPDATED"`
+}
+
+// CodeHubUsageUser структура таблицы code_hub_usage_user, пользователь, учтенный в уникальной метрике репозитория за день
+type CodeHubUsageUser struct {
+	ID     int64              `xorm:"PK AUTOINCR"`
+	RepoID int64              `xorm:"NOT NULL UNIQUE(repo_metric_day_user)"`
+	Metric Metric             `xorm:"VARCHAR(50) NOT NULL UNIQUE(repo_metric_day_user)"`
+	Day    timeutil.TimeStamp `xorm:"NOT NULL UNIQUE(repo_metric_day_user)"`
+	UserID int64              `xorm:"NOT NULL UNIQUE(repo_metric_day_user)"`
+}
+
+// DayStart возвращает начало дня по UTC, в который попадает ts
+func DayStart(ts timeutil.TimeStamp) timeutil.TimeStamp {
+	return ts - ts%timeutil.TimeStamp(day)
+}
+
+// WeekStart возвращает начало недели (понедельник) по UTC, в которую попадает ts
+func WeekStart(ts timeutil.TimeStamp) timeutil.TimeStamp {
+	start := DayStart(ts)
+	// 1 января 1970 года был четвергом
+	weekday := (int64(start)/day + 3) % 7
+	return start - timeutil.TimeStamp(weekday*day)
+}
+
+// BucketStart возвращает начало периода interval, в который попадает ts
+func BucketStart(ts timeutil.TimeStamp, interval Interval) timeutil.TimeStamp {
+	if interval == IntervalWeek {
+		return WeekStart(ts)
+	}
+	return DayStart(ts)
+}
+
+// NextBucket возвращает начало периода interval, следующего за периодом, начинающимся в start
+func NextBucket(start timeutil.TimeStamp, interval Interval) timeutil.TimeStamp {
+	if interval == IntervalWeek {
+		return start + timeutil.TimeStamp(7*day)
+	}
+	return start + timeutil.TimeStamp(day)
+}
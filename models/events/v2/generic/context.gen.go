@@ -5,14 +5,23 @@ package generic
 
 // Defines values for ContextEntityType.
 const (
-	REPOSITORY ContextEntityType = "REPOSITORY"
+	BRANCH      ContextEntityType = "BRANCH"
+	PULLREQUEST ContextEntityType = "PULL_REQUEST"
+	REPOSITORY  ContextEntityType = "REPOSITORY"
+	REVIEW      ContextEntityType = "REVIEW"
+	TAG         ContextEntityType = "TAG"
 )
 
 // Defines values for ContextEventCode.
 const (
-	CREATE ContextEventCode = "CREATE"
-	DELETE ContextEventCode = "DELETE"
-	UPDATE ContextEventCode = "UPDATE"
+	CLOSE    ContextEventCode = "CLOSE"
+	CREATE   ContextEventCode = "CREATE"
+	DELETE   ContextEventCode = "DELETE"
+	MERGE    ContextEventCode = "MERGE"
+	PUSH     ContextEventCode = "PUSH"
+	SUBMIT   ContextEventCode = "SUBMIT"
+	TRANSFER ContextEventCode = "TRANSFER"
+	UPDATE   ContextEventCode = "UPDATE"
 )
 
 // Context Context
@@ -7,9 +7,53 @@ import (
 	externalRef0 "code.gitea.io/gitea/models/events/v2/generic"
 )
 
+// Defines values for PullRequestInfoState.
+const (
+	Closed PullRequestInfoState = "closed"
+	Merged PullRequestInfoState = "merged"
+	Open   PullRequestInfoState = "open"
+)
+
+// Defines values for RefPayloadRefType.
+const (
+	Branch RefPayloadRefType = "branch"
+	Tag    RefPayloadRefType = "tag"
+)
+
+// Defines values for ReviewInfoType.
+const (
+	Approve ReviewInfoType = "approve"
+	Comment ReviewInfoType = "comment"
+	Reject  ReviewInfoType = "reject"
+)
+
 // AdditionalProperties Additional properties
 type AdditionalProperties map[string]string
 
+// CommitInfo Commit info
+type CommitInfo struct {
+	// AuthorEmail Author email
+	AuthorEmail string `json:"author_email"`
+
+	// AuthorName Author name
+	AuthorName string `json:"author_name"`
+
+	// CommitterEmail Committer email
+	CommitterEmail *string `json:"committer_email,omitempty"`
+
+	// CommitterName Committer name
+	CommitterName *string `json:"committer_name,omitempty"`
+
+	// Message Commit message
+	Message string `json:"message"`
+
+	// Sha Commit SHA
+	Sha string `json:"sha"`
+
+	// Timestamp Commit timestamp in UTC
+	Timestamp string `json:"timestamp"`
+}
+
 // CreateRepositoryEvent Create repository event
 type CreateRepositoryEvent struct {
 	// Context Context
@@ -20,6 +64,16 @@ type CreateRepositoryEvent struct {
 	Payload  Payload               `json:"payload"`
 }
 
+// DeleteRepositoryEvent Delete repository event
+type DeleteRepositoryEvent struct {
+	// Context Context
+	Context externalRef0.Context `json:"context"`
+
+	// Metadata Metadata
+	Metadata externalRef0.Metadata `json:"metadata"`
+	Payload  Payload               `json:"payload"`
+}
+
 // InitiatorUser Initiator user
 type InitiatorUser struct {
 	// Id User id
@@ -38,6 +92,132 @@ type Payload struct {
 	RepositoryInfo RepositoryInfo `json:"repository_info"`
 }
 
+// PullRequestEvent Pull request open, merge and close event
+type PullRequestEvent struct {
+	// Context Context
+	Context externalRef0.Context `json:"context"`
+
+	// Metadata Metadata
+	Metadata externalRef0.Metadata `json:"metadata"`
+	Payload  PullRequestPayload    `json:"payload"`
+}
+
+// PullRequestInfo Pull request info
+type PullRequestInfo struct {
+	// AuthorId Pull request author id
+	AuthorId string `json:"author_id"`
+
+	// BaseBranch Target branch
+	BaseBranch string `json:"base_branch"`
+
+	// HeadBranch Source branch
+	HeadBranch string `json:"head_branch"`
+
+	// HeadSha Head commit SHA of source branch
+	HeadSha string `json:"head_sha"`
+
+	// Id Pull request id
+	Id string `json:"id"`
+
+	// Index Pull request number in repository
+	Index int `json:"index"`
+
+	// MergeCommitSha Merge commit SHA, only for merged pull request
+	MergeCommitSha *string `json:"merge_commit_sha,omitempty"`
+
+	// State Pull request state
+	State PullRequestInfoState `json:"state"`
+
+	// Title Pull request title
+	Title string `json:"title"`
+
+	// Url Pull request URL
+	Url string `json:"url"`
+}
+
+// PullRequestInfoState Pull request state
+type PullRequestInfoState string
+
+// PullRequestPayload defines model for PullRequestPayload.
+type PullRequestPayload struct {
+	// InitiatorUser Initiator user
+	InitiatorUser InitiatorUser `json:"initiator_user"`
+
+	// PullRequest Pull request info
+	PullRequest PullRequestInfo `json:"pull_request"`
+
+	// RepositoryInfo Repository info
+	RepositoryInfo RepositoryInfo `json:"repository_info"`
+}
+
+// PushEvent Push to repository event
+type PushEvent struct {
+	// Context Context
+	Context externalRef0.Context `json:"context"`
+
+	// Metadata Metadata
+	Metadata externalRef0.Metadata `json:"metadata"`
+	Payload  PushPayload           `json:"payload"`
+}
+
+// PushPayload defines model for PushPayload.
+type PushPayload struct {
+	// After Commit SHA after push
+	After string `json:"after"`
+
+	// Before Commit SHA before push
+	Before string `json:"before"`
+
+	// Commits Pushed commits, the most recent first
+	Commits []CommitInfo `json:"commits"`
+
+	// CompareUrl URL to compare commits before and after push
+	CompareUrl *string `json:"compare_url,omitempty"`
+
+	// InitiatorUser Initiator user
+	InitiatorUser InitiatorUser `json:"initiator_user"`
+
+	// Ref Full name of the pushed reference
+	Ref string `json:"ref"`
+
+	// RepositoryInfo Repository info
+	RepositoryInfo RepositoryInfo `json:"repository_info"`
+
+	// TotalCommits Total number of pushed commits
+	TotalCommits int `json:"total_commits"`
+}
+
+// RefEvent Branch or tag create and delete event
+type RefEvent struct {
+	// Context Context
+	Context externalRef0.Context `json:"context"`
+
+	// Metadata Metadata
+	Metadata externalRef0.Metadata `json:"metadata"`
+	Payload  RefPayload            `json:"payload"`
+}
+
+// RefPayload defines model for RefPayload.
+type RefPayload struct {
+	// InitiatorUser Initiator user
+	InitiatorUser InitiatorUser `json:"initiator_user"`
+
+	// RefName Short name of the branch or tag
+	RefName string `json:"ref_name"`
+
+	// RefType Reference type
+	RefType RefPayloadRefType `json:"ref_type"`
+
+	// RepositoryInfo Repository info
+	RepositoryInfo RepositoryInfo `json:"repository_info"`
+
+	// Sha Commit SHA the reference points to, empty for deleted reference
+	Sha *string `json:"sha,omitempty"`
+}
+
+// RefPayloadRefType Reference type
+type RefPayloadRefType string
+
 // RepositoryInfo Repository info
 type RepositoryInfo struct {
 	// ProjectName Project name
@@ -52,3 +232,65 @@ type RepositoryInfo struct {
 	// TenantName Tenant name
 	TenantName string `json:"tenant_name"`
 }
+
+// ReviewEvent Pull request review submitted event
+type ReviewEvent struct {
+	// Context Context
+	Context externalRef0.Context `json:"context"`
+
+	// Metadata Metadata
+	Metadata externalRef0.Metadata `json:"metadata"`
+	Payload  ReviewPayload         `json:"payload"`
+}
+
+// ReviewInfo Review info
+type ReviewInfo struct {
+	// Content Review comment
+	Content *string `json:"content,omitempty"`
+
+	// Id Review id
+	Id string `json:"id"`
+
+	// Type Review type
+	Type ReviewInfoType `json:"type"`
+}
+
+// ReviewInfoType Review type
+type ReviewInfoType string
+
+// ReviewPayload defines model for ReviewPayload.
+type ReviewPayload struct {
+	// InitiatorUser Initiator user
+	InitiatorUser InitiatorUser `json:"initiator_user"`
+
+	// PullRequest Pull request info
+	PullRequest PullRequestInfo `json:"pull_request"`
+
+	// RepositoryInfo Repository info
+	RepositoryInfo RepositoryInfo `json:"repository_info"`
+
+	// Review Review info
+	Review ReviewInfo `json:"review"`
+}
+
+// TransferPayload defines model for TransferPayload.
+type TransferPayload struct {
+	// InitiatorUser Initiator user
+	InitiatorUser InitiatorUser `json:"initiator_user"`
+
+	// PreviousProjectName Project name before transfer
+	PreviousProjectName string `json:"previous_project_name"`
+
+	// RepositoryInfo Repository info
+	RepositoryInfo RepositoryInfo `json:"repository_info"`
+}
+
+// TransferRepositoryEvent Transfer repository event
+type TransferRepositoryEvent struct {
+	// Context Context
+	Context externalRef0.Context `json:"context"`
+
+	// Metadata Metadata
+	Metadata externalRef0.Metadata `json:"metadata"`
+	Payload  TransferPayload       `json:"payload"`
+}
@@ -0,0 +1,103 @@
+package kafka_command
+
+import (
+	"context"
+	"fmt"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Command))
+}
+
+type Status string
+
+const (
+	StatusPending Status = "pending" // команда получена, но еще не выполнена
+	StatusDone    Status = "done"    // команда выполнена, ответ сохранен
+	StatusDead    Status = "dead"    // команда отправлена в топик недоставленных команд
+)
+
+// Command структура таблицы kafka_command, команда, полученная из топика [kafka.commands].
+// Идентификатор команды уникален, поэтому повторно доставленная команда не выполняется второй раз
+type Command struct {
+	ID          int64              `xorm:"pk autoincr"`
+	CommandID   string             `xorm:"VARCHAR(36) UNIQUE NOT NULL"` // идентификатор сообщения команды metadata.message_id
+	Type        string             `xorm:"VARCHAR(50) NOT NULL"`
+	Status      Status             `xorm:"VARCHAR(20) INDEX NOT NULL"`
+	Attempts    int                `xorm:"NOT NULL DEFAULT 0"`
+	LastError   string             `xorm:"TEXT"`
+	Reply       string             `xorm:"LONGTEXT"` // сообщение с результатом выполнения команды
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName возвращает имя таблицы команд
+func (Command) TableName() string {
+	return "kafka_command"
+}
+
+// GetOrCreateCommand возвращает команду с идентификатором commandID, сохраняя ее при первом получении
+func GetOrCreateCommand(ctx context.Context, commandID, commandType string) (*Command, error) {
+	command := &Command{CommandID: commandID}
+	has, err := db.GetEngine(ctx).Get(command)
+	if err != nil {
+		return nil, fmt.Errorf("get command %s: %w", commandID, err)
+	}
+	if has {
+		return command, nil
+	}
+
+	command = &Command{CommandID: commandID, Type: commandType, Status: StatusPending}
+	if _, err = db.GetEngine(ctx).Insert(command); err != nil {
+		// Команду с тем же идентификатором мог сохранить другой экземпляр приложения
+		existing := &Command{CommandID: commandID}
+		if has, getErr := db.GetEngine(ctx).Get(existing); getErr == nil && has {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("insert command %s: %w", commandID, err)
+	}
+	return command, nil
+}
+
+// FinishCommand сохраняет ответ выполненной команды
+func FinishCommand(ctx context.Context, command *Command, reply string) error {
+	command.Status = StatusDone
+	command.Reply = reply
+	if _, err := db.GetEngine(ctx).ID(command.ID).Cols("status", "reply").Update(command); err != nil {
+		return fmt.Errorf("finish command %s: %w", command.CommandID, err)
+	}
+	return nil
+}
+
+// FailCommandAttempt сохраняет ошибку неудачной попытки выполнения команды.
+// Если dead, команда больше не выполняется
+func FailCommandAttempt(ctx context.Context, command *Command, lastError string, dead bool) error {
+	command.Attempts++
+	command.LastError = lastError
+	if dead {
+		command.Status = StatusDead
+	}
+	if _, err := db.GetEngine(ctx).ID(command.ID).Cols("status", "attempts", "last_error").Update(command); err != nil {
+		return fmt.Errorf("fail command %s attempt: %w", command.CommandID, err)
+	}
+	return nil
+}
+
+// finishedCommandsCond условие выбора выполненных и недоставленных команд, измененных раньше before
+func finishedCommandsCond(before timeutil.TimeStamp) builder.Cond {
+	return builder.In("status", StatusDone, StatusDead).And(builder.Lt{"updated_unix": before})
+}
+
+// DeleteFinishedCommandsBefore удаляет выполненные и недоставленные команды, измененные раньше before
+func DeleteFinishedCommandsBefore(ctx context.Context, before timeutil.TimeStamp) (int64, error) {
+	deleted, err := db.GetEngine(ctx).Where(finishedCommandsCond(before)).Delete(new(Command))
+	if err != nil {
+		return 0, fmt.Errorf("delete finished commands: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,20 @@
+//go:build !correct
+
+package kafka_command
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+// TestFinishedCommandsCond проверяет, что удаляются только выполненные и недоставленные команды старше срока хранения
+func TestFinishedCommandsCond(t *testing.T) {
+	sql, args, err := builder.ToSQL(finishedCommandsCond(timeutil.TimeStamp(100)))
+	assert.NoError(t, err)
+	assert.Equal(t, "status IN (?,?) AND updated_unix<?", sql)
+	assert.Equal(t, []interface{}{StatusDone, StatusDead, timeutil.TimeStamp(100)}, args)
+}
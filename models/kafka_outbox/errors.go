@@ -0,0 +1,27 @@
+package kafka_outbox
+
+import "fmt"
+
+type MessageAlreadyLockedError struct {
+	MessageID int64
+}
+
+func NewMessageAlreadyLockedError(messageID int64) *MessageAlreadyLockedError {
+	return &MessageAlreadyLockedError{MessageID: messageID}
+}
+
+func (e *MessageAlreadyLockedError) Error() string {
+	return fmt.Sprintf("outbox message '%d' already locked", e.MessageID)
+}
+
+type FailedMessageNotFoundError struct {
+	MessageID int64
+}
+
+func NewFailedMessageNotFoundError(messageID int64) *FailedMessageNotFoundError {
+	return &FailedMessageNotFoundError{MessageID: messageID}
+}
+
+func (e *FailedMessageNotFoundError) Error() string {
+	return fmt.Sprintf("failed outbox message '%d' not found", e.MessageID)
+}
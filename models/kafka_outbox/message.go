@@ -0,0 +1,211 @@
+package kafka_outbox
+
+import (
+	"context"
+	"fmt"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Message))
+}
+
+type Status string
+
+const (
+	StatusUnlocked Status = "unlocked" // событие ожидает отправки
+	StatusLocked   Status = "locked"   // событие отправляется
+	StatusSent     Status = "sent"     // событие отправлено
+	StatusFailed   Status = "failed"   // событие не отправлено после всех попыток, очередь ключа остановлена
+	StatusSkipped  Status = "skipped"  // ошибочное событие пропущено администратором
+)
+
+// blockingStatuses статусы событий, которые задерживают следующие события своего ключа
+var blockingStatuses = []Status{StatusUnlocked, StatusLocked, StatusFailed}
+
+// Message структура таблицы kafka_outbox_message, событие Kafka, ожидающее отправки
+type Message struct {
+	ID              int64              `xorm:"pk autoincr"`
+	TopicKey        string             `xorm:"VARCHAR(100) NOT NULL"` // ключ топика в настройках [kafka.*]
+	MessageKey      string             `xorm:"VARCHAR(255) INDEX"`    // ключ сообщения, события с одним ключом отправляются по порядку
+	IdempotencyKey  string             `xorm:"VARCHAR(36) UNIQUE NOT NULL"`
+	Payload         string             `xorm:"LONGTEXT NOT NULL"`
+	Status          Status             `xorm:"VARCHAR(20) INDEX NOT NULL"`
+	Attempts        int                `xorm:"NOT NULL DEFAULT 0"`
+	LastError       string             `xorm:"TEXT"`
+	NextAttemptUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName возвращает имя таблицы outbox
+func (Message) TableName() string {
+	return "kafka_outbox_message"
+}
+
+// Enqueue сохраняет событие для отправки. Если ctx содержит транзакцию, событие сохраняется в ней,
+// поэтому оно отправляется только вместе с изменением, которое его породило. Повторное сохранение события
+// с тем же ключом идемпотентности игнорируется
+func Enqueue(ctx context.Context, msg *Message) error {
+	exists, err := db.GetEngine(ctx).Exist(&Message{IdempotencyKey: msg.IdempotencyKey})
+	if err != nil {
+		return fmt.Errorf("check outbox message %s: %w", msg.IdempotencyKey, err)
+	}
+	if exists {
+		return nil
+	}
+	msg.Status = StatusUnlocked
+	msg.Attempts = 0
+	if _, err = db.GetEngine(ctx).Insert(msg); err != nil {
+		return fmt.Errorf("insert outbox message %s: %w", msg.IdempotencyKey, err)
+	}
+	return nil
+}
+
+// deliverableMessagesCond условие выбора событий, готовых к отправке. Выбирается только первое неотправленное событие
+// каждого ключа, и только если оно не заблокировано, не ошибочное и время повторной попытки наступило.
+// Поэтому следующее событие ключа не отправляется, пока не отправлено предыдущее
+func deliverableMessagesCond(now timeutil.TimeStamp) builder.Cond {
+	heads := builder.Select("MIN(id)").
+		From("kafka_outbox_message").
+		Where(builder.In("status", blockingStatuses)).
+		GroupBy("message_key")
+	return builder.In("id", heads).
+		And(builder.Eq{"status": StatusUnlocked}).
+		And(builder.Lte{"next_attempt_unix": now})
+}
+
+// GetDeliverableMessages возвращает события, готовые к отправке, не более одного события на ключ, в порядке сохранения
+func GetDeliverableMessages(ctx context.Context, limit int, now timeutil.TimeStamp) ([]*Message, error) {
+	messages := make([]*Message, 0, limit)
+	if err := db.GetEngine(ctx).
+		Where(deliverableMessagesCond(now)).
+		OrderBy("id").
+		Limit(limit).
+		Find(&messages); err != nil {
+		return nil, fmt.Errorf("find deliverable outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// lockMessageCond условие блокировки события: событие не заблокировано и время повторной попытки наступило
+func lockMessageCond(id int64, now timeutil.TimeStamp) builder.Cond {
+	return builder.Eq{"id": id, "status": StatusUnlocked}.And(builder.Lte{"next_attempt_unix": now})
+}
+
+// LockMessage блокирует событие для отправки. Блокировка выполняется одним условным обновлением,
+// поэтому событие отправляет только один экземпляр приложения
+func LockMessage(ctx context.Context, id int64, now timeutil.TimeStamp) error {
+	affected, err := db.GetEngine(ctx).
+		Where(lockMessageCond(id, now)).
+		Cols("status").
+		Update(&Message{Status: StatusLocked})
+	if err != nil {
+		return fmt.Errorf("lock outbox message: %w", err)
+	}
+	if affected == 0 {
+		return NewMessageAlreadyLockedError(id)
+	}
+	return nil
+}
+
+// MarkMessageSent помечает заблокированное событие отправленным
+func MarkMessageSent(ctx context.Context, id int64) error {
+	if _, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": id, "status": StatusLocked}).
+		Cols("status", "last_error").
+		Update(&Message{Status: StatusSent}); err != nil {
+		return fmt.Errorf("mark outbox message sent: %w", err)
+	}
+	return nil
+}
+
+// MarkMessageAttemptFailed снимает блокировку с события после неудачной отправки. Если final, событие помечается
+// ошибочным и больше не отправляется, иначе отправка повторяется не раньше nextAttempt
+func MarkMessageAttemptFailed(ctx context.Context, msg *Message, sendErr error, nextAttempt timeutil.TimeStamp, final bool) error {
+	msg.Attempts++
+	msg.LastError = sendErr.Error()
+	msg.NextAttemptUnix = nextAttempt
+	msg.Status = StatusUnlocked
+	if final {
+		msg.Status = StatusFailed
+	}
+	if _, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": msg.ID, "status": StatusLocked}).
+		Cols("status", "attempts", "last_error", "next_attempt_unix").
+		Update(msg); err != nil {
+		return fmt.Errorf("mark outbox message attempt failed: %w", err)
+	}
+	return nil
+}
+
+// GetFailedMessages возвращает ошибочные события, которые останавливают очереди своих ключей
+func GetFailedMessages(ctx context.Context) ([]*Message, error) {
+	messages := make([]*Message, 0, 10)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"status": StatusFailed}).
+		OrderBy("id").
+		Find(&messages); err != nil {
+		return nil, fmt.Errorf("find failed outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// RetryFailedMessage возвращает ошибочное событие в очередь, счетчик попыток сбрасывается
+func RetryFailedMessage(ctx context.Context, id int64) error {
+	affected, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": id, "status": StatusFailed}).
+		Cols("status", "attempts", "next_attempt_unix").
+		Update(&Message{Status: StatusUnlocked})
+	if err != nil {
+		return fmt.Errorf("retry failed outbox message: %w", err)
+	}
+	if affected == 0 {
+		return NewFailedMessageNotFoundError(id)
+	}
+	return nil
+}
+
+// SkipFailedMessage пропускает ошибочное событие, после чего отправляются следующие события его ключа
+func SkipFailedMessage(ctx context.Context, id int64) error {
+	affected, err := db.GetEngine(ctx).
+		Where(builder.Eq{"id": id, "status": StatusFailed}).
+		Cols("status").
+		Update(&Message{Status: StatusSkipped})
+	if err != nil {
+		return fmt.Errorf("skip failed outbox message: %w", err)
+	}
+	if affected == 0 {
+		return NewFailedMessageNotFoundError(id)
+	}
+	return nil
+}
+
+// UnlockStaleMessages снимает блокировку с событий, заблокированных раньше before, например если приложение
+// было остановлено во время отправки
+func UnlockStaleMessages(ctx context.Context, before timeutil.TimeStamp) (int64, error) {
+	affected, err := db.GetEngine(ctx).
+		Where(builder.Eq{"status": StatusLocked}.And(builder.Lt{"updated_unix": before})).
+		Cols("status").
+		NoAutoTime().
+		Update(&Message{Status: StatusUnlocked})
+	if err != nil {
+		return 0, fmt.Errorf("unlock stale outbox messages: %w", err)
+	}
+	return affected, nil
+}
+
+// DeleteSentMessagesBefore удаляет отправленные и пропущенные события, измененные раньше before
+func DeleteSentMessagesBefore(ctx context.Context, before timeutil.TimeStamp) (int64, error) {
+	deleted, err := db.GetEngine(ctx).
+		Where(builder.In("status", StatusSent, StatusSkipped).And(builder.Lt{"updated_unix": before})).
+		Delete(new(Message))
+	if err != nil {
+		return 0, fmt.Errorf("delete sent outbox messages: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,30 @@
+//go:build !correct
+
+package kafka_outbox
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+// TestDeliverableMessagesCond проверяет, что выбирается только первое неотправленное событие ключа,
+// а заблокированное, ошибочное или ожидающее повторной попытки событие останавливает очередь ключа
+func TestDeliverableMessagesCond(t *testing.T) {
+	sql, args, err := builder.ToSQL(deliverableMessagesCond(timeutil.TimeStamp(100)))
+	assert.NoError(t, err)
+	assert.Equal(t, "id IN (SELECT MIN(id) FROM kafka_outbox_message WHERE status IN (?,?,?) GROUP BY message_key) "+
+		"AND status=? AND next_attempt_unix<=?", sql)
+	assert.Equal(t, []interface{}{StatusUnlocked, StatusLocked, StatusFailed, StatusUnlocked, timeutil.TimeStamp(100)}, args)
+}
+
+// TestLockMessageCond проверяет, что блокируется только незаблокированное событие с наступившим временем попытки
+func TestLockMessageCond(t *testing.T) {
+	sql, args, err := builder.ToSQL(lockMessageCond(5, timeutil.TimeStamp(100)))
+	assert.NoError(t, err)
+	assert.Equal(t, "id=? AND status=? AND next_attempt_unix<=?", sql)
+	assert.Equal(t, []interface{}{int64(5), StatusUnlocked, timeutil.TimeStamp(100)}, args)
+}
@@ -37,6 +37,7 @@ import (
 	"code.gitea.io/gitea/models/migrations/v1_30"
 	"code.gitea.io/gitea/models/migrations/v1_31"
 	"code.gitea.io/gitea/models/migrations/v1_33"
+	"code.gitea.io/gitea/models/migrations/v1_34"
 	"code.gitea.io/gitea/models/migrations/v1_6"
 	"code.gitea.io/gitea/models/migrations/v1_7"
 	"code.gitea.io/gitea/models/migrations/v1_8"
@@ -604,6 +605,52 @@ var migrations = []Migration{
 	NewMigration("Create table sc_custom_privileges_group", v_32.CreateScCustomPrivileges),
 	// 286 -> 287
 	NewMigration("Create review_settings and default_reviewers tables", v1_33.CreateReviewSettingsAndDefaultReviewersTable),
+	// 287 -> 288
+	NewMigration("Update table sc_tenant(add is_archived field)", v1_34.AddIsArchivedToScTenant),
+	// 288 -> 289
+	NewMigration("Update table sc_tenant(add quota fields)", v1_34.AddQuotasToScTenant),
+	// 289 -> 290
+	NewMigration("Create table sc_privilege_expiration", v1_34.CreateScPrivilegeExpirationTable),
+	// 290 -> 291
+	NewMigration("Create table sc_role", v1_34.CreateScRoleTable),
+	// 291 -> 292
+	NewMigration("Create table audit_event", v1_34.CreateAuditEventTable),
+	// 292 -> 293
+	NewMigration("Add chain columns to audit_event", v1_34.AddAuditEventChainColumns),
+	// 293 -> 294
+	NewMigration("Create table kafka_outbox_message", v1_34.CreateKafkaOutboxMessageTable),
+	// 294 -> 295
+	NewMigration("Create table task_tracker_provider_binding", v1_34.CreateTaskTrackerProviderBindingTable),
+	// 295 -> 296
+	NewMigration("Create table unit_code_pattern", v1_34.CreateUnitCodePatternTable),
+	// 296 -> 297
+	NewMigration("Create table unit_links_ref", v1_34.CreateUnitLinksRefTable),
+	// 297 -> 298
+	NewMigration("Add unit status merge restriction to review_settings", v1_34.AddUnitStatusToReviewSettings),
+	// 298 -> 299
+	NewMigration("Add retry columns to unit_links_sender_tasks and code_hub_counter_tasks", v1_34.AddRetryColumnsToBackgroundTasks),
+	// 299 -> 300
+	NewMigration("Move unit_links_sender_tasks and code_hub_counter_tasks to background_task", v1_34.MoveTasksToBackgroundTask),
+	// 300 -> 301
+	NewMigration("Create tables code_hub_usage_stat and code_hub_usage_user", v1_34.CreateCodeHubUsageStatTables),
+	// 301 -> 302
+	NewMigration("Create quality gate tables and add required quality gates to review_settings", v1_34.CreateQualityGateTables),
+	// 302 -> 303
+	NewMigration("Encrypt sonar tokens and add vault token path to sc_sonar_settings", v1_34.EncryptSonarTokens),
+	// 303 -> 304
+	NewMigration("Add webhook secret to sc_sonar_settings", v1_34.AddSonarWebhookSecret),
+	// 304 -> 305
+	NewMigration("Create table sc_sonar_metrics_snapshot and keep sonar metrics history", v1_34.CreateSonarMetricsSnapshots),
+	// 305 -> 306
+	NewMigration("Add is_deleting to sc_tenant", v1_34.AddTenantIsDeleting),
+	// 306 -> 307
+	NewMigration("Create table sc_repo_privilege_collaboration", v1_34.CreateRepoPrivilegeCollaboration),
+	// 307 -> 308
+	NewMigration("Create table kafka_command", v1_34.CreateKafkaCommandTable),
+	// 308 -> 309
+	NewMigration("Move unit code patterns of task tracker bindings to unit_code_pattern", v1_34.MoveBindingUnitCodePatterns),
+	// 309 -> 310
+	NewMigration("Create table sc_role_revision", v1_34.CreateScRoleRevisionTable),
 }
 
 // GetCurrentDBVersion returns the current db version
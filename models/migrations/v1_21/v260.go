@@ -1,11 +1,19 @@
 package v1_21
 
 import (
-	"code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/timeutil"
 	"xorm.io/xorm"
 )
 
 // CreateScSonarSettingsTable создание таблицы ScSonarSettings в зависимости от параметра SourceControl.Enabled
 func CreateScSonarSettingsTable(x *xorm.Engine) error {
-	return x.Sync(new(repo.ScSonarSettings))
+	type ScSonarSettings struct {
+		ID         int64              `xorm:"pk autoincr"`
+		RepoID     int64              `xorm:"INDEX"`
+		URL        string             `xorm:"VARCHAR(2048) not null"`
+		Token      string             `xorm:"VARCHAR(255) not null"`
+		ProjectKey string             `xorm:"VARCHAR(50) not null"`
+		Updated    timeutil.TimeStamp `xorm:"updated not null"`
+	}
+	return x.Sync(new(ScSonarSettings))
 }
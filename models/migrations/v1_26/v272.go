@@ -4,7 +4,7 @@ import (
 	"xorm.io/xorm"
 
 	"code.gitea.io/gitea/models/unit_links"
-	"code.gitea.io/gitea/models/unit_links_sender"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // CreateUnitLinks создаем таблицу для хранения информации o связях юнитов в SourceControl и TaskTracker
@@ -14,5 +14,21 @@ func CreateUnitLinks(x *xorm.Engine) error {
 
 // CreateUnitLinksSenderTasks создаем таблицу для хранения информации об отправке связей юнитов в TaskTracker
 func CreateUnitLinksSenderTasks(x *xorm.Engine) error {
-	return x.Sync(new(unit_links_sender.UnitLinksSenderTasks))
+	type UnitLinksSenderTasks struct {
+		ID int64 `xorm:"PK AUTOINCR"`
+
+		Payload  string `xorm:"NOT NULL TEXT JSON"`
+		Action   string `xorm:"NOT NULL"`
+		UserName string `xorm:"NOT NULL"`
+
+		PullRequestID  int64  `xorm:"NOT NULL"`
+		PullRequestURL string `xorm:"NOT NULL"`
+
+		Status string `xorm:"NOT NULL"`
+
+		CreatedAt timeutil.TimeStamp `xorm:"CREATED"`
+		UpdatedAt timeutil.TimeStamp `xorm:"UPDATED"`
+	}
+
+	return x.Sync(new(UnitLinksSenderTasks))
 }
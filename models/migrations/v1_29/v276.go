@@ -5,7 +5,6 @@ import (
 
 	"xorm.io/xorm"
 
-	"code.gitea.io/gitea/models/code_hub_counter_task"
 	"code.gitea.io/gitea/models/code_hub_unique_usages"
 	"code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -52,5 +51,18 @@ func CreateCodeHubUniqueUsagesTable(x *xorm.Engine) error {
 }
 
 func CreateCodeHubCounterTasksTable(x *xorm.Engine) error {
-	return x.Sync(new(code_hub_counter_task.CodeHubCounterTasks))
+	type CodeHubCounterTasks struct {
+		ID int64 `xorm:"PK AUTOINCR"`
+
+		UserID int64 `xorm:"NOT NULL"`
+		RepoID int64 `xorm:"NOT NULL"`
+
+		Action string `xorm:"NOT NULL"`
+		Status string `xorm:"NOT NULL"`
+
+		CreatedAt timeutil.TimeStamp `xorm:"CREATED"`
+		UpdatedAt timeutil.TimeStamp `xorm:"UPDATED"`
+	}
+
+	return x.Sync(new(CodeHubCounterTasks))
 }
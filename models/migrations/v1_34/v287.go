@@ -0,0 +1,19 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// AddIsArchivedToScTenant добавление колонки is_archived в таблицу sc_tenant
+func AddIsArchivedToScTenant(x *xorm.Engine) error {
+	type ScTenant struct {
+		IsArchived bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync(new(ScTenant)); err != nil {
+		return fmt.Errorf("failed to sync ScTenant model: %w", err)
+	}
+	return nil
+}
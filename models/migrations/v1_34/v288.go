@@ -0,0 +1,22 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// AddQuotasToScTenant добавление колонок квот ресурсов в таблицу sc_tenant
+func AddQuotasToScTenant(x *xorm.Engine) error {
+	type ScTenant struct {
+		MaxRepoCount   int64 `xorm:"NOT NULL DEFAULT 0"`
+		MaxGitSize     int64 `xorm:"NOT NULL DEFAULT 0"`
+		MaxLFSSize     int64 `xorm:"NOT NULL DEFAULT 0"`
+		MaxPackageSize int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync(new(ScTenant)); err != nil {
+		return fmt.Errorf("failed to sync ScTenant model: %w", err)
+	}
+	return nil
+}
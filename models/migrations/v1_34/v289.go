@@ -0,0 +1,27 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateScPrivilegeExpirationTable создание таблицы sc_privilege_expiration со сроками действия привилегий
+func CreateScPrivilegeExpirationTable(x *xorm.Engine) error {
+	type ScPrivilegeExpiration struct {
+		ID        int64              `xorm:"pk autoincr"`
+		UserID    int64              `xorm:"UNIQUE(s) NOT NULL"`
+		TenantID  string             `xorm:"VARCHAR(50) NOT NULL"`
+		OrgID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Role      string             `xorm:"VARCHAR(100) NOT NULL"`
+		ExpiresAt timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+		Created   timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync(new(ScPrivilegeExpiration)); err != nil {
+		return fmt.Errorf("failed to sync ScPrivilegeExpiration model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateScRoleTable создание таблицы sc_role с описанием ролей ролевой модели.
+// Таблица заполняется встроенными ролями в порядке их прежних числовых значений, поэтому идентификаторы встроенных ролей совпадают с ними.
+// Назначенные привилегии хранят имя роли, поэтому остаются действительными без изменений
+func CreateScRoleTable(x *xorm.Engine) error {
+	type ScRole struct {
+		ID           int64              `xorm:"pk autoincr"`
+		Name         string             `xorm:"VARCHAR(100) UNIQUE NOT NULL"`
+		DisplayName  string             `xorm:"VARCHAR(255)"`
+		Actions      string             `xorm:"TEXT"`
+		Parent       string             `xorm:"VARCHAR(100)"`
+		IsSystem     bool               `xorm:"NOT NULL DEFAULT false"`
+		IsAssignable bool               `xorm:"NOT NULL DEFAULT true"`
+		Created      timeutil.TimeStamp `xorm:"created"`
+		Updated      timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync(new(ScRole)); err != nil {
+		return fmt.Errorf("failed to sync ScRole model: %w", err)
+	}
+
+	count, err := x.Count(new(ScRole))
+	if err != nil {
+		return fmt.Errorf("failed to count roles: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	roles := []*ScRole{
+		{
+			Name:         "owner",
+			DisplayName:  "Владелец проекта",
+			Actions:      "own,create,edit,edit_project,read,read_private,write,delete,merge_without_check,manage_comments",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			Name:         "manager",
+			DisplayName:  "Менеджер",
+			Actions:      "read_private,create,edit,delete,write,read",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			Name:         "writer",
+			DisplayName:  "Пользователь с правами на запись",
+			Actions:      "read,write",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			Name:         "reader",
+			DisplayName:  "Пользователь с правами на чтение",
+			Actions:      "read",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			Name:         "tuz",
+			DisplayName:  "Технологическая учетная запись",
+			Parent:       "owner",
+			IsSystem:     true,
+			IsAssignable: false,
+		},
+	}
+	// вставка по одной записи гарантирует порядок идентификаторов
+	for _, role := range roles {
+		if _, err := x.Insert(role); err != nil {
+			return fmt.Errorf("failed to insert role %s: %w", role.Name, err)
+		}
+	}
+	return nil
+}
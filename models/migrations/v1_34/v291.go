@@ -0,0 +1,33 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateAuditEventTable создание таблицы audit_event для поиска по событиям аудита
+func CreateAuditEventTable(x *xorm.Engine) error {
+	type AuditEvent struct {
+		ID         int64              `xorm:"pk autoincr"`
+		EventID    string             `xorm:"VARCHAR(36) UNIQUE NOT NULL"`
+		Type       string             `xorm:"VARCHAR(255) INDEX NOT NULL"`
+		Status     string             `xorm:"VARCHAR(20) INDEX"`
+		UserName   string             `xorm:"VARCHAR(255) INDEX"`
+		UserID     string             `xorm:"VARCHAR(50)"`
+		UserIP     string             `xorm:"VARCHAR(64) INDEX"`
+		Tenant     string             `xorm:"VARCHAR(255) INDEX"`
+		Repository string             `xorm:"VARCHAR(512) INDEX"`
+		HostName   string             `xorm:"VARCHAR(255)"`
+		HostIP     string             `xorm:"VARCHAR(64)"`
+		Params     map[string]string  `xorm:"JSON TEXT"`
+		EventUnix  timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	}
+
+	if err := x.Sync(new(AuditEvent)); err != nil {
+		return fmt.Errorf("failed to sync AuditEvent model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// AddAuditEventChainColumns добавление в таблицу audit_event номера сообщения в цепочке хешей и исходного сообщения,
+// по которому проверяется цепочка
+func AddAuditEventChainColumns(x *xorm.Engine) error {
+	type AuditEvent struct {
+		Seq int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Raw string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync(new(AuditEvent)); err != nil {
+		return fmt.Errorf("failed to sync AuditEvent model: %w", err)
+	}
+	return nil
+}
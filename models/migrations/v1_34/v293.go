@@ -0,0 +1,31 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateKafkaOutboxMessageTable создание таблицы kafka_outbox_message для отправки событий Kafka через outbox
+func CreateKafkaOutboxMessageTable(x *xorm.Engine) error {
+	type KafkaOutboxMessage struct {
+		ID              int64              `xorm:"pk autoincr"`
+		TopicKey        string             `xorm:"VARCHAR(100) NOT NULL"`
+		MessageKey      string             `xorm:"VARCHAR(255) INDEX"`
+		IdempotencyKey  string             `xorm:"VARCHAR(36) UNIQUE NOT NULL"`
+		Payload         string             `xorm:"LONGTEXT NOT NULL"`
+		Status          string             `xorm:"VARCHAR(20) INDEX NOT NULL"`
+		Attempts        int                `xorm:"NOT NULL DEFAULT 0"`
+		LastError       string             `xorm:"TEXT"`
+		NextAttemptUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync(new(KafkaOutboxMessage)); err != nil {
+		return fmt.Errorf("failed to sync KafkaOutboxMessage model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateTaskTrackerProviderBindingTable создание таблицы task_tracker_provider_binding для выбора трекера задач тенантом или репозиторием
+func CreateTaskTrackerProviderBindingTable(x *xorm.Engine) error {
+	type TaskTrackerProviderBinding struct {
+		ID              int64              `xorm:"pk autoincr"`
+		TenantID        string             `xorm:"VARCHAR(36) UNIQUE(s) NOT NULL"`
+		RepoID          int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Provider        string             `xorm:"VARCHAR(100) NOT NULL"`
+		UnitCodePattern string             `xorm:"TEXT"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync(new(TaskTrackerProviderBinding)); err != nil {
+		return fmt.Errorf("failed to sync TaskTrackerProviderBinding model: %w", err)
+	}
+	return nil
+}
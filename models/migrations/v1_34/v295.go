@@ -0,0 +1,26 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateUnitCodePatternTable создание таблицы unit_code_pattern для шаблонов кодов юнитов тенанта или репозитория
+func CreateUnitCodePatternTable(x *xorm.Engine) error {
+	type UnitCodePattern struct {
+		ID          int64              `xorm:"pk autoincr"`
+		TenantID    string             `xorm:"VARCHAR(36) UNIQUE(s) NOT NULL"`
+		RepoID      int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Patterns    []string           `xorm:"JSON TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync(new(UnitCodePattern)); err != nil {
+		return fmt.Errorf("failed to sync UnitCodePattern model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateUnitLinksRefTable создание таблицы unit_links_ref для коммитов и веток, связанных с юнитами
+func CreateUnitLinksRefTable(x *xorm.Engine) error {
+	type UnitLinksRef struct {
+		ID        int64              `xorm:"PK AUTOINCR"`
+		RepoID    int64              `xorm:"NOT NULL UNIQUE(s)"`
+		RefType   int                `xorm:"NOT NULL UNIQUE(s)"`
+		Ref       string             `xorm:"VARCHAR(255) NOT NULL UNIQUE(s)"`
+		Title     string             `xorm:"TEXT"`
+		URL       string             `xorm:"TEXT"`
+		CreatedAt timeutil.TimeStamp `xorm:"CREATED"`
+	}
+
+	if err := x.Sync(new(UnitLinksRef)); err != nil {
+		return fmt.Errorf("failed to sync UnitLinksRef model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// AddUnitStatusToReviewSettings добавление в review_settings настроек запрета слияния по статусу юнитов трекера задач
+func AddUnitStatusToReviewSettings(x *xorm.Engine) error {
+	type ReviewSettings struct {
+		RequireUnitStatus   bool     `xorm:"NOT NULL DEFAULT false"`
+		AllowedUnitStatuses []string `xorm:"JSON TEXT"`
+	}
+
+	if err := x.Sync(new(ReviewSettings)); err != nil {
+		return fmt.Errorf("failed to sync ReviewSettings model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// AddRetryColumnsToBackgroundTasks добавление в unit_links_sender_tasks и code_hub_counter_tasks количества попыток,
+// последней ошибки и времени следующей попытки
+func AddRetryColumnsToBackgroundTasks(x *xorm.Engine) error {
+	type UnitLinksSenderTasks struct {
+		Attempts      int                `xorm:"NOT NULL DEFAULT 0"`
+		LastError     string             `xorm:"TEXT"`
+		NextAttemptAt timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type CodeHubCounterTasks struct {
+		Attempts      int                `xorm:"NOT NULL DEFAULT 0"`
+		LastError     string             `xorm:"TEXT"`
+		NextAttemptAt timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync(new(UnitLinksSenderTasks)); err != nil {
+		return fmt.Errorf("failed to sync UnitLinksSenderTasks model: %w", err)
+	}
+	if err := x.Sync(new(CodeHubCounterTasks)); err != nil {
+		return fmt.Errorf("failed to sync CodeHubCounterTasks model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// MoveTasksToBackgroundTask создание общей таблицы фоновых задач background_task и перенос в нее необработанных
+// задач из unit_links_sender_tasks и code_hub_counter_tasks. Заблокированные задачи возвращаются в очередь,
+// обработанные не переносятся. Задачи с некорректной нагрузкой переносятся в состоянии dead, чтобы администратор
+// мог их разобрать, а не прерывают миграцию. Старые таблицы удаляются
+func MoveTasksToBackgroundTask(x *xorm.Engine) error {
+	type BackgroundTask struct {
+		ID         int64              `xorm:"pk autoincr"`
+		Queue      string             `xorm:"VARCHAR(64) NOT NULL INDEX(queue_claim)"`
+		Status     string             `xorm:"VARCHAR(20) NOT NULL INDEX(queue_claim)"`
+		Priority   int                `xorm:"NOT NULL DEFAULT 0"`
+		Payload    string             `xorm:"LONGTEXT NOT NULL"`
+		Attempts   int                `xorm:"NOT NULL DEFAULT 0"`
+		LastError  string             `xorm:"TEXT"`
+		RunAt      timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0 INDEX(queue_claim)"`
+		LeaseOwner string             `xorm:"VARCHAR(255)"`
+		LeaseUntil timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CreatedAt  timeutil.TimeStamp `xorm:"created"`
+		UpdatedAt  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type UnitLinksSenderTasks struct {
+		ID             int64
+		Payload        string
+		Action         string
+		UserName       string
+		PullRequestID  int64
+		PullRequestURL string
+		Status         string
+		Attempts       int
+		LastError      string
+		NextAttemptAt  timeutil.TimeStamp
+		CreatedAt      timeutil.TimeStamp
+	}
+
+	type CodeHubCounterTasks struct {
+		ID            int64
+		UserID        int64
+		RepoID        int64
+		Action        string
+		Status        string
+		Attempts      int
+		LastError     string
+		NextAttemptAt timeutil.TimeStamp
+		CreatedAt     timeutil.TimeStamp
+	}
+
+	if err := x.Sync(new(BackgroundTask)); err != nil {
+		return fmt.Errorf("failed to sync BackgroundTask model: %w", err)
+	}
+
+	// status возвращает состояние перенесенной задачи, заблокированные задачи возвращаются в очередь
+	status := func(old string) string {
+		if old == "dead" {
+			return "dead"
+		}
+		return "pending"
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	exists, err := sess.IsTableExist("unit_links_sender_tasks")
+	if err != nil {
+		return fmt.Errorf("check unit_links_sender_tasks table: %w", err)
+	}
+	if exists {
+		senderTasks := make([]UnitLinksSenderTasks, 0)
+		if err = sess.Table("unit_links_sender_tasks").Where("status <> ?", "done").Asc("id").Find(&senderTasks); err != nil {
+			return fmt.Errorf("find unit links sender tasks: %w", err)
+		}
+		for _, task := range senderTasks {
+			taskStatus, lastError := status(task.Status), task.LastError
+			fields := map[string]any{
+				"action":           task.Action,
+				"user_name":        task.UserName,
+				"pull_request_id":  task.PullRequestID,
+				"pull_request_url": task.PullRequestURL,
+				"links":            json.RawMessage(task.Payload),
+			}
+			if !json.Valid([]byte(task.Payload)) {
+				// Нагрузка сохраняется строкой, поэтому повтор задачи администратором снова переведет ее в dead
+				fields["links"] = task.Payload
+				taskStatus, lastError = "dead", "invalid payload of unit_links_sender_tasks"
+			}
+			// В задачах коммитов и веток pull_request_id хранил идентификатор unit_links_ref
+			if task.Action == "add_ref" || task.Action == "delete_ref" {
+				fields["ref_id"] = task.PullRequestID
+				fields["pull_request_id"] = 0
+				fields["pull_request_url"] = ""
+			}
+			payload, err := json.Marshal(fields)
+			if err != nil {
+				return fmt.Errorf("marshal payload of unit links sender task %d: %w", task.ID, err)
+			}
+			if _, err = sess.NoAutoTime().Insert(&BackgroundTask{
+				Queue:     "unit_links_sender",
+				Status:    taskStatus,
+				Payload:   string(payload),
+				Attempts:  task.Attempts,
+				LastError: lastError,
+				RunAt:     task.NextAttemptAt,
+				CreatedAt: task.CreatedAt,
+				UpdatedAt: timeutil.TimeStampNow(),
+			}); err != nil {
+				return fmt.Errorf("move unit links sender task %d: %w", task.ID, err)
+			}
+		}
+		if err = sess.DropTable("unit_links_sender_tasks"); err != nil {
+			return fmt.Errorf("drop unit_links_sender_tasks table: %w", err)
+		}
+	}
+
+	exists, err = sess.IsTableExist("code_hub_counter_tasks")
+	if err != nil {
+		return fmt.Errorf("check code_hub_counter_tasks table: %w", err)
+	}
+	if exists {
+		counterTasks := make([]CodeHubCounterTasks, 0)
+		if err = sess.Table("code_hub_counter_tasks").Where("status <> ?", "done").Asc("id").Find(&counterTasks); err != nil {
+			return fmt.Errorf("find code hub counter tasks: %w", err)
+		}
+		for _, task := range counterTasks {
+			payload, err := json.Marshal(map[string]any{
+				"user_id": task.UserID,
+				"repo_id": task.RepoID,
+				"action":  task.Action,
+			})
+			if err != nil {
+				return fmt.Errorf("marshal payload of code hub counter task %d: %w", task.ID, err)
+			}
+			if _, err = sess.NoAutoTime().Insert(&BackgroundTask{
+				Queue:     "code_hub_counter",
+				Status:    status(task.Status),
+				Payload:   string(payload),
+				Attempts:  task.Attempts,
+				LastError: task.LastError,
+				RunAt:     task.NextAttemptAt,
+				CreatedAt: task.CreatedAt,
+				UpdatedAt: timeutil.TimeStampNow(),
+			}); err != nil {
+				return fmt.Errorf("move code hub counter task %d: %w", task.ID, err)
+			}
+		}
+		if err = sess.DropTable("code_hub_counter_tasks"); err != nil {
+			return fmt.Errorf("drop code_hub_counter_tasks table: %w", err)
+		}
+	}
+
+	return sess.Commit()
+}
@@ -0,0 +1,35 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateCodeHubUsageStatTables создание таблиц code_hub_usage_stat для дневной статистики использования репозиториев
+// и code_hub_usage_user для учета уникальных пользователей за день
+func CreateCodeHubUsageStatTables(x *xorm.Engine) error {
+	type CodeHubUsageStat struct {
+		ID        int64              `xorm:"PK AUTOINCR"`
+		RepoID    int64              `xorm:"NOT NULL UNIQUE(repo_metric_day)"`
+		Metric    string             `xorm:"VARCHAR(50) NOT NULL UNIQUE(repo_metric_day)"`
+		Day       timeutil.TimeStamp `xorm:"NOT NULL UNIQUE(repo_metric_day)"`
+		Value     int64              `xorm:"NOT NULL DEFAULT 0"`
+		UpdatedAt timeutil.TimeStamp `xorm:"UPDATED"`
+	}
+
+	type CodeHubUsageUser struct {
+		ID     int64              `xorm:"PK AUTOINCR"`
+		RepoID int64              `xorm:"NOT NULL UNIQUE(repo_metric_day_user)"`
+		Metric string             `xorm:"VARCHAR(50) NOT NULL UNIQUE(repo_metric_day_user)"`
+		Day    timeutil.TimeStamp `xorm:"NOT NULL UNIQUE(repo_metric_day_user)"`
+		UserID int64              `xorm:"NOT NULL UNIQUE(repo_metric_day_user)"`
+	}
+
+	if err := x.Sync(new(CodeHubUsageStat), new(CodeHubUsageUser)); err != nil {
+		return fmt.Errorf("failed to sync code hub usage stat models: %w", err)
+	}
+	return nil
+}
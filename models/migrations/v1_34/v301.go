@@ -0,0 +1,64 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateQualityGateTables создание таблиц настроек, отчетов и метрик провайдеров quality gate
+// и добавление в review_settings списка обязательных quality gate
+func CreateQualityGateTables(x *xorm.Engine) error {
+	type Condition struct {
+		Metric    string  `json:"metric"`
+		Operator  string  `json:"operator"`
+		Threshold float64 `json:"threshold"`
+	}
+
+	type QualityGateSettings struct {
+		ID             int64              `xorm:"pk autoincr"`
+		RepoID         int64              `xorm:"NOT NULL UNIQUE(repo_provider)"`
+		Provider       string             `xorm:"VARCHAR(50) NOT NULL UNIQUE(repo_provider)"`
+		TokenHash      string             `xorm:"VARCHAR(64) NOT NULL UNIQUE"`
+		TokenLastEight string             `xorm:"VARCHAR(8)"`
+		Conditions     []Condition        `xorm:"JSON TEXT"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type QualityGateReport struct {
+		ID               int64              `xorm:"pk autoincr"`
+		RepoID           int64              `xorm:"NOT NULL UNIQUE(s)"`
+		Provider         string             `xorm:"VARCHAR(50) NOT NULL UNIQUE(s)"`
+		Branch           string             `xorm:"VARCHAR(255) NOT NULL UNIQUE(s)"`
+		PullRequestIndex int64              `xorm:"NOT NULL DEFAULT 0 UNIQUE(s)"`
+		CommitSHA        string             `xorm:"VARCHAR(64)"`
+		Status           string             `xorm:"VARCHAR(20) NOT NULL"`
+		AnalysedUnix     timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		UpdatedUnix      timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type QualityGateMetric struct {
+		ID        int64  `xorm:"pk autoincr"`
+		ReportID  int64  `xorm:"NOT NULL INDEX"`
+		Key       string `xorm:"VARCHAR(255) NOT NULL"`
+		Value     string `xorm:"VARCHAR(255)"`
+		Operator  string `xorm:"VARCHAR(10)"`
+		Threshold string `xorm:"VARCHAR(255)"`
+		Status    string `xorm:"VARCHAR(20)"`
+	}
+
+	type ReviewSettings struct {
+		RequiredQualityGates []string `xorm:"JSON TEXT"`
+	}
+
+	if err := x.Sync(new(QualityGateSettings), new(QualityGateReport), new(QualityGateMetric)); err != nil {
+		return fmt.Errorf("failed to sync quality gate models: %w", err)
+	}
+	if err := x.Sync(new(ReviewSettings)); err != nil {
+		return fmt.Errorf("failed to sync ReviewSettings model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models/migrations/base"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/xorm"
+)
+
+// EncryptSonarTokens шифрование токенов Sonar секретным ключом экземпляра, добавление пути к токену в Vault
+// и удаление колонки с открытым токеном из sc_sonar_settings
+func EncryptSonarTokens(x *xorm.Engine) error {
+	type ScSonarSettings struct {
+		ID               int64  `xorm:"pk autoincr"`
+		Token            string `xorm:"VARCHAR(255)"`
+		TokenEncrypted   string `xorm:"TEXT"`
+		VaultStoragePath string `xorm:"VARCHAR(255)"`
+		VaultSecretPath  string `xorm:"VARCHAR(255)"`
+		VaultTokenKey    string `xorm:"VARCHAR(255)"`
+		VaultVersionKey  int    `xorm:"NOT NULL DEFAULT 1"`
+	}
+
+	if err := x.Sync(new(ScSonarSettings)); err != nil {
+		return fmt.Errorf("failed to sync ScSonarSettings model: %w", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	settings := make([]*ScSonarSettings, 0)
+	if err := sess.Where("token <> ''").Find(&settings); err != nil {
+		return fmt.Errorf("failed to find sonar settings: %w", err)
+	}
+	for _, s := range settings {
+		encrypted, err := secret.EncryptSecret(setting.SecretKey, s.Token)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt sonar token of settings %d: %w", s.ID, err)
+		}
+		if _, err = sess.Exec("UPDATE sc_sonar_settings SET token_encrypted = ? WHERE id = ?", encrypted, s.ID); err != nil {
+			return fmt.Errorf("failed to update sonar settings %d: %w", s.ID, err)
+		}
+	}
+
+	if err := base.DropTableColumns(sess, "sc_sonar_settings", "token"); err != nil {
+		return fmt.Errorf("failed to drop token column: %w", err)
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,19 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// AddSonarWebhookSecret добавление в sc_sonar_settings зашифрованного секрета для проверки подписи webhook SonarQube
+func AddSonarWebhookSecret(x *xorm.Engine) error {
+	type ScSonarSettings struct {
+		WebhookSecretEncrypted string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync(new(ScSonarSettings)); err != nil {
+		return fmt.Errorf("failed to sync ScSonarSettings model: %w", err)
+	}
+	return nil
+}
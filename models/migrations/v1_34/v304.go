@@ -0,0 +1,71 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/google/uuid"
+	"xorm.io/xorm"
+)
+
+// CreateSonarMetricsSnapshots создание таблицы снимков метрик анализов Sonar и привязка к ним сохраненных метрик.
+// Для каждой ветки с метриками создается снимок с временем последнего анализа
+func CreateSonarMetricsSnapshots(x *xorm.Engine) error {
+	type ScSonarMetricsSnapshot struct {
+		ID                   string             `xorm:"pk uuid"`
+		SonarProjectStatusID string             `xorm:"INDEX NOT NULL"`
+		Branch               string             `xorm:"NOT NULL"`
+		Revision             string             `xorm:"VARCHAR(64)"`
+		QualityGateStatus    string             `xorm:"VARCHAR(20)"`
+		AnalysedAt           timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+		Created              timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type ScSonarProjectMetrics struct {
+		SnapshotID string `xorm:"INDEX"`
+	}
+
+	type ScSonarProjectStatus struct {
+		ID         string `xorm:"pk uuid"`
+		Branch     string
+		AnalysedAt timeutil.TimeStamp
+		Status     string
+	}
+
+	if err := x.Sync(new(ScSonarMetricsSnapshot)); err != nil {
+		return fmt.Errorf("failed to sync ScSonarMetricsSnapshot model: %w", err)
+	}
+	if err := x.Sync(new(ScSonarProjectMetrics)); err != nil {
+		return fmt.Errorf("failed to sync ScSonarProjectMetrics model: %w", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	statuses := make([]*ScSonarProjectStatus, 0)
+	if err := sess.Table("sc_sonar_project_status").
+		Where("id IN (SELECT sonar_project_status_id FROM sc_sonar_project_metrics WHERE snapshot_id IS NULL OR snapshot_id = '')").
+		Find(&statuses); err != nil {
+		return fmt.Errorf("failed to find sonar project statuses: %w", err)
+	}
+	for _, status := range statuses {
+		snapshot := &ScSonarMetricsSnapshot{
+			ID:                   uuid.NewString(),
+			SonarProjectStatusID: status.ID,
+			Branch:               status.Branch,
+			QualityGateStatus:    status.Status,
+			AnalysedAt:           status.AnalysedAt,
+		}
+		if _, err := sess.Insert(snapshot); err != nil {
+			return fmt.Errorf("failed to insert sonar metrics snapshot of status %s: %w", status.ID, err)
+		}
+		if _, err := sess.Exec("UPDATE sc_sonar_project_metrics SET snapshot_id = ? WHERE sonar_project_status_id = ? AND (snapshot_id IS NULL OR snapshot_id = '')", snapshot.ID, status.ID); err != nil {
+			return fmt.Errorf("failed to link sonar metrics of status %s: %w", status.ID, err)
+		}
+	}
+	return sess.Commit()
+}
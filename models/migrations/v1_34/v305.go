@@ -0,0 +1,19 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// AddTenantIsDeleting добавление в sc_tenant признака начатого удаления tenant
+func AddTenantIsDeleting(x *xorm.Engine) error {
+	type ScTenant struct {
+		IsDeleting bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync(new(ScTenant)); err != nil {
+		return fmt.Errorf("failed to sync ScTenant model: %w", err)
+	}
+	return nil
+}
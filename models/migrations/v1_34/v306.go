@@ -0,0 +1,24 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateRepoPrivilegeCollaboration создание таблицы участий в репозиториях, созданных при назначении ролей на репозиторий
+func CreateRepoPrivilegeCollaboration(x *xorm.Engine) error {
+	type ScRepoPrivilegeCollaboration struct {
+		ID      int64              `xorm:"pk autoincr"`
+		RepoID  int64              `xorm:"UNIQUE(s) NOT NULL"`
+		UserID  int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Created timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync(new(ScRepoPrivilegeCollaboration)); err != nil {
+		return fmt.Errorf("failed to sync ScRepoPrivilegeCollaboration model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// CreateKafkaCommandTable создание таблицы kafka_command для однократного выполнения команд Kafka
+func CreateKafkaCommandTable(x *xorm.Engine) error {
+	type KafkaCommand struct {
+		ID          int64              `xorm:"pk autoincr"`
+		CommandID   string             `xorm:"VARCHAR(36) UNIQUE NOT NULL"`
+		Type        string             `xorm:"VARCHAR(50) NOT NULL"`
+		Status      string             `xorm:"VARCHAR(20) INDEX NOT NULL"`
+		Attempts    int                `xorm:"NOT NULL DEFAULT 0"`
+		LastError   string             `xorm:"TEXT"`
+		Reply       string             `xorm:"LONGTEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync(new(KafkaCommand)); err != nil {
+		return fmt.Errorf("failed to sync KafkaCommand model: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models/migrations/base"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// MoveBindingUnitCodePatterns перенос шаблонов кодов юнитов из task_tracker_provider_binding в unit_code_pattern
+// и удаление колонки unit_code_pattern из task_tracker_provider_binding. Если для тенанта или репозитория уже
+// сохранены шаблоны в unit_code_pattern, шаблон выбора трекера задач не переносится, так как он не применялся
+func MoveBindingUnitCodePatterns(x *xorm.Engine) error {
+	type TaskTrackerProviderBinding struct {
+		ID              int64  `xorm:"pk autoincr"`
+		TenantID        string `xorm:"VARCHAR(36) UNIQUE(s) NOT NULL"`
+		RepoID          int64  `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		UnitCodePattern string `xorm:"TEXT"`
+	}
+	type UnitCodePattern struct {
+		ID          int64              `xorm:"pk autoincr"`
+		TenantID    string             `xorm:"VARCHAR(36) UNIQUE(s) NOT NULL"`
+		RepoID      int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Patterns    []string           `xorm:"JSON TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	bindings := make([]*TaskTrackerProviderBinding, 0)
+	if err := sess.Where("unit_code_pattern IS NOT NULL AND unit_code_pattern <> ''").Find(&bindings); err != nil {
+		return fmt.Errorf("failed to find task tracker bindings: %w", err)
+	}
+	for _, binding := range bindings {
+		has, err := sess.Where("tenant_id = ? AND repo_id = ?", binding.TenantID, binding.RepoID).Exist(new(UnitCodePattern))
+		if err != nil {
+			return fmt.Errorf("failed to check unit code patterns of tenant %s repo %d: %w", binding.TenantID, binding.RepoID, err)
+		}
+		if has {
+			continue
+		}
+		if _, err = sess.Insert(&UnitCodePattern{
+			TenantID: binding.TenantID,
+			RepoID:   binding.RepoID,
+			Patterns: []string{binding.UnitCodePattern},
+		}); err != nil {
+			return fmt.Errorf("failed to insert unit code patterns of tenant %s repo %d: %w", binding.TenantID, binding.RepoID, err)
+		}
+	}
+
+	if err := base.DropTableColumns(sess, "task_tracker_provider_binding", "unit_code_pattern"); err != nil {
+		return fmt.Errorf("failed to drop unit_code_pattern column: %w", err)
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,32 @@
+package v1_34
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// CreateScRoleRevisionTable создание таблицы sc_role_revision со счетчиком изменений описаний ролей.
+// Счетчик заменяет отпечаток таблицы sc_role, который не менялся при нескольких изменениях роли за одну секунду
+func CreateScRoleRevisionTable(x *xorm.Engine) error {
+	type ScRoleRevision struct {
+		ID       int64 `xorm:"pk"`
+		Revision int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync(new(ScRoleRevision)); err != nil {
+		return fmt.Errorf("failed to sync ScRoleRevision model: %w", err)
+	}
+
+	has, err := x.ID(1).Exist(new(ScRoleRevision))
+	if err != nil {
+		return fmt.Errorf("failed to check role definitions revision: %w", err)
+	}
+	if has {
+		return nil
+	}
+	if _, err = x.Insert(&ScRoleRevision{ID: 1, Revision: 1}); err != nil {
+		return fmt.Errorf("failed to add role definitions revision: %w", err)
+	}
+	return nil
+}
@@ -30,7 +30,7 @@ func (t taskTrackerDB) IsActiveOfPullRequestStatus(ctx context.Context, pullRequ
 	var unitLinks []unit_links.UnitLinks
 
 	if err = t.engine.
-		Where(builder.Eq{"is_active": 1}, builder.Eq{"from_unit_id": pullRequestID}).
+		Where(builder.Eq{"is_active": 1}, builder.Eq{"from_unit_id": pullRequestID, "from_unit_type": unit_links.PullRequestFromUnitType}).
 		Table("unit_links").
 		Find(&unitLinks); err != nil {
 		return false, fmt.Errorf("find unit links: %w", err)
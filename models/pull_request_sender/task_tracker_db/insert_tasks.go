@@ -5,14 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"code.gitea.io/gitea/models/background_task"
 	"code.gitea.io/gitea/models/pull_request_sender"
 	"code.gitea.io/gitea/models/unit_links"
 	"code.gitea.io/gitea/models/unit_links_sender"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/timeutil"
 )
 
-func (t taskTrackerDB) insertNewTasks(_ context.Context, opts pull_request_sender.UpdatePullRequestStatusOptions) error {
+func (t taskTrackerDB) insertNewTasks(ctx context.Context, opts pull_request_sender.UpdatePullRequestStatusOptions) error {
 	payloadUpdate := []payloadStatusForUpdating{{
 		FromUnitID:     opts.FromUnitID,
 		FromUnitType:   unit_links.PullRequestFromUnitType,
@@ -23,18 +23,13 @@ func (t taskTrackerDB) insertNewTasks(_ context.Context, opts pull_request_sende
 		log.Error("Error has occurred while marshaling payload: %v", err)
 		return fmt.Errorf("marshal payload: %w", err)
 	}
-	timeNow := timeutil.TimeStampNow()
-
-	if _, err = t.engine.Insert(unit_links_sender.UnitLinksSenderTasks{
-		Payload:        string(payload),
+	if _, err = unit_links_sender.Queue.Enqueue(ctx, unit_links_sender.Payload{
 		Action:         unit_links_sender.SendUpdatePullRequestStatusAction,
+		UserName:       opts.UserName,
 		PullRequestID:  opts.FromUnitID,
 		PullRequestURL: opts.PullRequestURL,
-		UserName:       opts.UserName,
-		Status:         unit_links_sender.StatusUnlocked,
-		CreatedAt:      timeNow,
-		UpdatedAt:      timeNow,
-	}); err != nil {
+		Links:          payload,
+	}, background_task.EnqueueOptions{}); err != nil {
 		log.Error("Error has occurred while inserting task: %v", err)
 		return fmt.Errorf("insert from update issue statues: %w", err)
 	}
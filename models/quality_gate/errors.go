@@ -0,0 +1,19 @@
+package quality_gate
+
+import "fmt"
+
+// ErrSettingsNotExist настройки провайдера quality gate репозитория не найдены
+type ErrSettingsNotExist struct {
+	RepoID   int64
+	Provider Provider
+}
+
+func (e ErrSettingsNotExist) Error() string {
+	return fmt.Sprintf("quality gate settings of provider %s for repo %d do not exist", e.Provider, e.RepoID)
+}
+
+// IsErrSettingsNotExist проверяет, что ошибка ErrSettingsNotExist
+func IsErrSettingsNotExist(err error) bool {
+	_, ok := err.(ErrSettingsNotExist)
+	return ok
+}
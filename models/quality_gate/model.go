@@ -0,0 +1,116 @@
+package quality_gate
+
+import (
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Settings))
+	db.RegisterModel(new(Report))
+	db.RegisterModel(new(Metric))
+}
+
+// Provider внешний инструмент, результаты которого проверяются перед слиянием
+type Provider string
+
+const (
+	ProviderSonarQube         Provider = "sonarqube"
+	ProviderSemgrep           Provider = "semgrep"
+	ProviderTrivy             Provider = "trivy"
+	ProviderDependencyScanner Provider = "dependency_scanner"
+)
+
+// Providers все провайдеры quality gate, которые можно требовать в настройках ревью
+var Providers = []Provider{ProviderSonarQube, ProviderSemgrep, ProviderTrivy, ProviderDependencyScanner}
+
+// IsValid проверяет, что провайдер quality gate существует
+func (p Provider) IsValid() bool {
+	for _, provider := range Providers {
+		if provider == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Status результат проверки quality gate
+type Status string
+
+const (
+	StatusOK    Status = "OK"
+	StatusError Status = "ERROR"
+)
+
+// Operator оператор условия quality gate. Условие не выполнено, если значение метрики больше (GT) или меньше (LT) порога
+type Operator string
+
+const (
+	OperatorGreaterThan Operator = "GT"
+	OperatorLessThan    Operator = "LT"
+)
+
+// Condition условие quality gate на значение метрики
+type Condition struct {
+	Metric    string   `json:"metric"`
+	Operator  Operator `json:"operator"`
+	Threshold float64  `json:"threshold"`
+}
+
+// Settings структура таблицы quality_gate_settings, настройки провайдера quality gate репозитория.
+// Отчеты провайдера принимаются по токену, в базе хранится только его хеш
+type Settings struct {
+	ID             int64              `xorm:"pk autoincr"`
+	RepoID         int64              `xorm:"NOT NULL UNIQUE(repo_provider)"`
+	Provider       Provider           `xorm:"VARCHAR(50) NOT NULL UNIQUE(repo_provider)"`
+	Token          string             `xorm:"-"`
+	TokenHash      string             `xorm:"VARCHAR(64) NOT NULL UNIQUE"`
+	TokenLastEight string             `xorm:"VARCHAR(8)"`
+	Conditions     []Condition        `xorm:"JSON TEXT"` // пустой список означает условия провайдера по умолчанию
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName возвращает имя таблицы настроек провайдеров quality gate
+func (Settings) TableName() string {
+	return "quality_gate_settings"
+}
+
+// Report структура таблицы quality_gate_report, последний отчет провайдера по ветке или пулл реквесту репозитория
+type Report struct {
+	ID               int64              `xorm:"pk autoincr"`
+	RepoID           int64              `xorm:"NOT NULL UNIQUE(s)"`
+	Provider         Provider           `xorm:"VARCHAR(50) NOT NULL UNIQUE(s)"`
+	Branch           string             `xorm:"VARCHAR(255) NOT NULL UNIQUE(s)"`
+	PullRequestIndex int64              `xorm:"NOT NULL DEFAULT 0 UNIQUE(s)"` // 0, если отчет построен по ветке
+	CommitSHA        string             `xorm:"VARCHAR(64)"`
+	Status           Status             `xorm:"VARCHAR(20) NOT NULL"`
+	AnalysedUnix     timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	UpdatedUnix      timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName возвращает имя таблицы отчетов quality gate
+func (Report) TableName() string {
+	return "quality_gate_report"
+}
+
+// Metric структура таблицы quality_gate_metric, метрика отчета quality gate и результат проверки условия на нее
+type Metric struct {
+	ID        int64    `xorm:"pk autoincr"`
+	ReportID  int64    `xorm:"NOT NULL INDEX"`
+	Key       string   `xorm:"VARCHAR(255) NOT NULL"`
+	Value     string   `xorm:"VARCHAR(255)"`
+	Operator  Operator `xorm:"VARCHAR(10)"` // пустой, если на метрику нет условия
+	Threshold string   `xorm:"VARCHAR(255)"`
+	Status    Status   `xorm:"VARCHAR(20)"`
+}
+
+// TableName возвращает имя таблицы метрик отчетов quality gate
+func (Metric) TableName() string {
+	return "quality_gate_metric"
+}
+
+// IsCondition проверяет, что на метрику есть условие quality gate
+func (m *Metric) IsCondition() bool {
+	return m.Operator != ""
+}
@@ -0,0 +1,108 @@
+package quality_gate
+
+import (
+	"context"
+	"fmt"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+)
+
+// SaveReport сохранить отчет провайдера вместо предыдущего отчета по той же ветке или пулл реквесту вместе с метриками
+func SaveReport(ctx context.Context, report *Report, metrics []*Metric) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+
+		existing, err := GetReport(ctx, report.RepoID, report.Provider, report.Branch, report.PullRequestIndex)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			report.ID = existing.ID
+			if _, err = e.ID(report.ID).Cols("commit_sha", "status", "analysed_unix").Update(report); err != nil {
+				return fmt.Errorf("update quality gate report: %w", err)
+			}
+			if _, err = e.Where(builder.Eq{"report_id": report.ID}).Delete(new(Metric)); err != nil {
+				return fmt.Errorf("delete quality gate metrics: %w", err)
+			}
+		} else if _, err = e.Insert(report); err != nil {
+			return fmt.Errorf("insert quality gate report: %w", err)
+		}
+
+		if len(metrics) == 0 {
+			return nil
+		}
+		for _, metric := range metrics {
+			metric.ID = 0
+			metric.ReportID = report.ID
+		}
+		if _, err = e.Insert(metrics); err != nil {
+			return fmt.Errorf("insert quality gate metrics: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetReport получить отчет провайдера по ветке или пулл реквесту репозитория. Возвращает nil, если отчета нет
+func GetReport(ctx context.Context, repoID int64, provider Provider, branch string, pullRequestIndex int64) (*Report, error) {
+	report := new(Report)
+	has, err := db.GetEngine(ctx).Where(builder.Eq{
+		"repo_id":            repoID,
+		"provider":           provider,
+		"branch":             branch,
+		"pull_request_index": pullRequestIndex,
+	}).Get(report)
+	if err != nil {
+		return nil, fmt.Errorf("get quality gate report: %w", err)
+	}
+	if !has {
+		return nil, nil
+	}
+	return report, nil
+}
+
+// pullRequestReportCond условие выбора отчетов провайдера по коммиту commitSHA пулл реквеста pullRequestIndex
+// репозитория baseRepoID или по его исходной ветке branch в репозитории headRepoID. Для пулл реквеста из форка отчет
+// по одноименной ветке целевого репозитория не подходит
+func pullRequestReportCond(provider Provider, baseRepoID, pullRequestIndex, headRepoID int64, branch, commitSHA string) builder.Cond {
+	return builder.Eq{"provider": provider, "commit_sha": commitSHA}.And(builder.Or(
+		builder.Eq{"repo_id": baseRepoID, "pull_request_index": pullRequestIndex},
+		builder.Eq{"repo_id": headRepoID, "branch": branch, "pull_request_index": 0},
+	))
+}
+
+// GetPullRequestReport получить последний отчет провайдера для коммита commitSHA пулл реквеста. Если отчета по пулл
+// реквесту нет, возвращается отчет по его исходной ветке в репозитории headRepoID. Возвращает nil, если отчета
+// по этому коммиту нет
+func GetPullRequestReport(ctx context.Context, provider Provider, baseRepoID, pullRequestIndex, headRepoID int64, branch, commitSHA string) (*Report, error) {
+	if commitSHA == "" {
+		return nil, nil
+	}
+
+	reports := make([]*Report, 0, 2)
+	if err := db.GetEngine(ctx).
+		Where(pullRequestReportCond(provider, baseRepoID, pullRequestIndex, headRepoID, branch, commitSHA)).
+		Find(&reports); err != nil {
+		return nil, fmt.Errorf("find quality gate reports: %w", err)
+	}
+
+	var found *Report
+	for _, report := range reports {
+		if report.RepoID == baseRepoID && report.PullRequestIndex == pullRequestIndex {
+			return report, nil
+		}
+		found = report
+	}
+	return found, nil
+}
+
+// GetReportMetrics получить метрики отчета quality gate
+func GetReportMetrics(ctx context.Context, reportID int64) ([]*Metric, error) {
+	metrics := make([]*Metric, 0)
+	if err := db.GetEngine(ctx).Where(builder.Eq{"report_id": reportID}).OrderBy("id").Find(&metrics); err != nil {
+		return nil, fmt.Errorf("find quality gate metrics: %w", err)
+	}
+	return metrics, nil
+}
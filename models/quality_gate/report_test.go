@@ -0,0 +1,18 @@
+//go:build !correct
+
+package quality_gate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/builder"
+)
+
+func TestPullRequestReportCond(t *testing.T) {
+	sql, args, err := builder.ToSQL(pullRequestReportCond(ProviderSonarQube, 1, 3, 2, "feature", "sha"))
+	require.NoError(t, err)
+	assert.Equal(t, "commit_sha=? AND provider=? AND ((pull_request_index=? AND repo_id=?) OR (branch=? AND pull_request_index=? AND repo_id=?))", sql)
+	assert.Equal(t, []any{"sha", ProviderSonarQube, int64(3), int64(1), "feature", 0, int64(2)}, args)
+}
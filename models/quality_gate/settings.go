@@ -0,0 +1,94 @@
+package quality_gate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// HashToken возвращает хеш токена приема отчетов
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateToken создает новый токен приема отчетов и сохраняет в настройки его хеш
+func (s *Settings) GenerateToken() error {
+	token, err := util.CryptoRandomBytes(20)
+	if err != nil {
+		return fmt.Errorf("generate token: %w", err)
+	}
+	s.Token = hex.EncodeToString(token)
+	s.TokenHash = HashToken(s.Token)
+	s.TokenLastEight = s.Token[len(s.Token)-8:]
+	return nil
+}
+
+// GetSettings получить настройки провайдера quality gate репозитория
+func GetSettings(ctx context.Context, repoID int64, provider Provider) (*Settings, error) {
+	settings := new(Settings)
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "provider": provider}).Get(settings)
+	if err != nil {
+		return nil, fmt.Errorf("get quality gate settings: %w", err)
+	}
+	if !has {
+		return nil, ErrSettingsNotExist{RepoID: repoID, Provider: provider}
+	}
+	return settings, nil
+}
+
+// GetSettingsByToken получить настройки провайдера quality gate по токену приема отчетов
+func GetSettingsByToken(ctx context.Context, provider Provider, token string) (*Settings, error) {
+	settings := new(Settings)
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"provider": provider, "token_hash": HashToken(token)}).Get(settings)
+	if err != nil {
+		return nil, fmt.Errorf("get quality gate settings by token: %w", err)
+	}
+	if !has {
+		return nil, ErrSettingsNotExist{Provider: provider}
+	}
+	return settings, nil
+}
+
+// FindSettings получить настройки всех провайдеров quality gate репозитория
+func FindSettings(ctx context.Context, repoID int64) ([]*Settings, error) {
+	settings := make([]*Settings, 0)
+	if err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID}).OrderBy("provider").Find(&settings); err != nil {
+		return nil, fmt.Errorf("find quality gate settings: %w", err)
+	}
+	return settings, nil
+}
+
+// InsertSettings сохранить новые настройки провайдера quality gate
+func InsertSettings(ctx context.Context, settings *Settings) error {
+	if _, err := db.GetEngine(ctx).Insert(settings); err != nil {
+		return fmt.Errorf("insert quality gate settings: %w", err)
+	}
+	return nil
+}
+
+// UpdateSettings обновить условия и хеш токена настроек провайдера quality gate
+func UpdateSettings(ctx context.Context, settings *Settings) error {
+	if _, err := db.GetEngine(ctx).ID(settings.ID).Cols("conditions", "token_hash", "token_last_eight").Update(settings); err != nil {
+		return fmt.Errorf("update quality gate settings: %w", err)
+	}
+	return nil
+}
+
+// DeleteSettings удалить настройки провайдера quality gate репозитория
+func DeleteSettings(ctx context.Context, repoID int64, provider Provider) error {
+	deleted, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "provider": provider}).Delete(new(Settings))
+	if err != nil {
+		return fmt.Errorf("delete quality gate settings: %w", err)
+	}
+	if deleted == 0 {
+		return ErrSettingsNotExist{RepoID: repoID, Provider: provider}
+	}
+	return nil
+}
@@ -144,7 +144,7 @@ func FindRepoArchives(opts FindRepoArchiversOption) ([]*RepoArchiver, error) {
 }
 
 // SetArchiveRepoState sets if a repo is archived
-func SetArchiveRepoState(repo *Repository, isArchived bool) (err error) {
+func SetArchiveRepoState(ctx context.Context, repo *Repository, isArchived bool) (err error) {
 	repo.IsArchived = isArchived
 
 	if isArchived {
@@ -153,6 +153,6 @@ func SetArchiveRepoState(repo *Repository, isArchived bool) (err error) {
 		repo.ArchivedUnix = timeutil.TimeStamp(0)
 	}
 
-	_, err = db.GetEngine(db.DefaultContext).ID(repo.ID).Cols("is_archived", "archived_unix").NoAutoTime().Update(repo)
+	_, err = db.GetEngine(ctx).ID(repo.ID).Cols("is_archived", "archived_unix").NoAutoTime().Update(repo)
 	return err
 }
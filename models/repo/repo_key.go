@@ -56,6 +56,15 @@ func (r RepoKeyDB) GetRepoByRepoID(ctx context.Context, repoId string) (*ScRepoK
 	return repoKey, nil
 }
 
+// GetAllRepoKeys извлечение связей внутренних и внешних ключей всех репозиториев
+func (r RepoKeyDB) GetAllRepoKeys(ctx context.Context) ([]*ScRepoKey, error) {
+	var repoKeys []*ScRepoKey
+	if err := r.engine.Find(&repoKeys); err != nil {
+		return nil, fmt.Errorf("failed to get repokeys: %w", err)
+	}
+	return repoKeys, nil
+}
+
 // InsertRepoKey добавление связи между внутренним и внешним ключами репозитория
 func (r RepoKeyDB) InsertRepoKey(ctx context.Context, repoKey *ScRepoKey) error {
 	_, err := r.engine.Insert(repoKey)
@@ -32,3 +32,16 @@ func (e ErrorOrgDoestExist) Error() string {
 func IsErrorOrgDoestExist(err error) bool {
 	return errors.As(err, &ErrorRepoKeyDoesntExists{})
 }
+
+// ErrorRepoKeyAlreadyUsed кастомная ошибка типа
+type ErrorRepoKeyAlreadyUsed struct {
+	RepoKey string
+}
+
+func (e ErrorRepoKeyAlreadyUsed) Error() string {
+	return fmt.Sprintf("Err: repository key %s already used", e.RepoKey)
+}
+
+func IsErrorRepoKeyAlreadyUsed(err error) bool {
+	return errors.As(err, &ErrorRepoKeyAlreadyUsed{})
+}
@@ -758,3 +758,14 @@ func GetUserRepositories(opts *SearchRepoOptions) (RepositoryList, int64, error)
 	repos := make(RepositoryList, 0, opts.PageSize)
 	return repos, count, db.SetSessionPagination(sess, opts).Find(&repos)
 }
+
+// GetRepositoriesByOwnerIDs returns all repositories owned by given owners.
+func GetRepositoriesByOwnerIDs(ctx context.Context, ownerIDs []int64) (RepositoryList, error) {
+	repos := make(RepositoryList, 0, len(ownerIDs))
+	if len(ownerIDs) == 0 {
+		return repos, nil
+	}
+	return repos, db.GetEngine(ctx).
+		Where(builder.In("owner_id", ownerIDs)).
+		Find(&repos)
+}
@@ -1,7 +1,17 @@
 package repo
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
 	"code.gitea.io/gitea/models/db"
+	vault_model "code.gitea.io/gitea/models/vault_client"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"xorm.io/builder"
 )
@@ -16,34 +26,201 @@ type ScSonarSettings struct {
 	RepoID int64 `xorm:"INDEX"`
 	// Url для доступа к Api Sonar
 	URL string `xorm:"VARCHAR(2048) not null"`
-	// Токен для доступа к Api Sonar
-	Token string `xorm:"VARCHAR(255) not null"`
+	// Токен для доступа к Api Sonar, зашифрованный секретным ключом экземпляра. Пустой, если токен хранится в Vault
+	TokenEncrypted string `xorm:"TEXT"`
+	// Путь к токену в Vault (sec man). Если задан, токен получается из Vault при каждом обращении к Api Sonar
+	VaultStoragePath string `xorm:"VARCHAR(255)"`
+	VaultSecretPath  string `xorm:"VARCHAR(255)"`
+	VaultTokenKey    string `xorm:"VARCHAR(255)"`
+	VaultVersionKey  int    `xorm:"NOT NULL DEFAULT 1"`
+	// Секрет для проверки подписи webhook SonarQube (X-Sonar-Webhook-HMAC-SHA256), зашифрованный секретным ключом экземпляра
+	WebhookSecretEncrypted string `xorm:"TEXT"`
 	// Ключ проекта в Sonar
 	ProjectKey string             `xorm:"VARCHAR(50) not null"`
 	Updated    timeutil.TimeStamp `xorm:"updated not null"`
 }
 
-// InsertOrUpdateSonarSettings добавление или изменение настроек Sonar для репозитория (если настроек не было то они добавляются, если были то обновляются новыми значениями)
-func InsertOrUpdateSonarSettings(repoId int64, url string, token string, projectKey string) error {
+// SonarTokenVault путь к токену Sonar в Vault
+type SonarTokenVault struct {
+	StoragePath string
+	SecretPath  string
+	TokenKey    string
+	VersionKey  int
+}
+
+// ErrSonarTokenVaultNotAllowed путь к токену Sonar в Vault не входит в разрешенный для репозитория префикс
+type ErrSonarTokenVaultNotAllowed struct {
+	RepoID      int64
+	StoragePath string
+	SecretPath  string
+}
+
+// Реализация интерфейса error
+func (err ErrSonarTokenVaultNotAllowed) Error() string {
+	return fmt.Sprintf("sonar token vault path %s/%s is not allowed for repository %d", err.StoragePath, err.SecretPath, err.RepoID)
+}
+
+// IsErrSonarTokenVaultNotAllowed проверяет, является ли ошибка ErrSonarTokenVaultNotAllowed
+func IsErrSonarTokenVaultNotAllowed(err error) bool {
+	return errors.As(err, &ErrSonarTokenVaultNotAllowed{})
+}
+
+// Validate проверяет, что путь к токену входит в хранилище и префикс секретов Vault, разрешенные настройками
+// экземпляра для репозитория repoID. Иначе пользователь мог бы указать чужой секрет и получить его через Api Sonar
+func (v SonarTokenVault) Validate(repoID int64) error {
+	notAllowed := ErrSonarTokenVaultNotAllowed{RepoID: repoID, StoragePath: v.StoragePath, SecretPath: v.SecretPath}
+	if setting.Sonar.VaultSecretPathPrefix == "" {
+		return notAllowed
+	}
+	if setting.Sonar.VaultStoragePath != "" && strings.Trim(v.StoragePath, "/") != strings.Trim(setting.Sonar.VaultStoragePath, "/") {
+		return notAllowed
+	}
+
+	prefix := strings.ReplaceAll(setting.Sonar.VaultSecretPathPrefix, "{repo_id}", strconv.FormatInt(repoID, 10))
+	prefix = strings.Trim(path.Clean("/"+prefix), "/")
+	secretPath := strings.Trim(path.Clean("/"+v.SecretPath), "/")
+	if secretPath != prefix && !strings.HasPrefix(secretPath, prefix+"/") {
+		return notAllowed
+	}
+	return nil
+}
+
+// SonarSecManGetter получение секретов из Vault, подменяется в тестах
+var SonarSecManGetter setting.GetCredSecMan = setting.NewGetterForSecMan()
+
+// IsTokenInVault проверяет, что токен Sonar хранится в Vault
+func (s *ScSonarSettings) IsTokenInVault() bool {
+	return s.VaultSecretPath != ""
+}
+
+// SetToken шифрует токен Sonar секретным ключом экземпляра и сбрасывает путь к токену в Vault
+func (s *ScSonarSettings) SetToken(token string) error {
+	encrypted, err := secret.EncryptSecret(setting.SecretKey, token)
+	if err != nil {
+		return fmt.Errorf("encrypt sonar token: %w", err)
+	}
+	s.TokenEncrypted = encrypted
+	s.SetTokenVault(SonarTokenVault{})
+	return nil
+}
+
+// SetTokenVault сохраняет путь к токену Sonar в Vault. Зашифрованный токен сбрасывается, если путь задан
+func (s *ScSonarSettings) SetTokenVault(vault SonarTokenVault) {
+	s.VaultStoragePath = vault.StoragePath
+	s.VaultSecretPath = vault.SecretPath
+	s.VaultTokenKey = vault.TokenKey
+	s.VaultVersionKey = vault.VersionKey
+	if s.VaultVersionKey == 0 {
+		s.VaultVersionKey = 1
+	}
+	if s.IsTokenInVault() {
+		s.TokenEncrypted = ""
+	}
+}
+
+// TokenVault возвращает путь к токену Sonar в Vault
+func (s *ScSonarSettings) TokenVault() SonarTokenVault {
+	return SonarTokenVault{
+		StoragePath: s.VaultStoragePath,
+		SecretPath:  s.VaultSecretPath,
+		TokenKey:    s.VaultTokenKey,
+		VersionKey:  s.VaultVersionKey,
+	}
+}
+
+// GetToken возвращает токен для доступа к Api Sonar: расшифровывает сохраненный токен или получает его из Vault
+func (s *ScSonarSettings) GetToken() (string, error) {
+	if !s.IsTokenInVault() {
+		if s.TokenEncrypted == "" {
+			return "", nil
+		}
+		token, err := secret.DecryptSecret(setting.SecretKey, s.TokenEncrypted)
+		if err != nil {
+			return "", fmt.Errorf("decrypt sonar token: %w", err)
+		}
+		return token, nil
+	}
+
+	if !setting.CheckSettingsForIntegrationWithSecMan() {
+		return "", fmt.Errorf("sonar token is stored in Vault, but integration with sec man is disabled")
+	}
+	// разрешенный префикс мог измениться после сохранения настроек
+	if err := s.TokenVault().Validate(s.RepoID); err != nil {
+		return "", err
+	}
+	resp, err := SonarSecManGetter.GetCredFromSecManByVersionKey(&vault_model.KeyValueConfigForGetSecrets{
+		StoragePath: s.VaultStoragePath,
+		SecretPath:  s.VaultSecretPath,
+		VersionKey:  s.VaultVersionKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("get sonar token from sec man: %w", err)
+	}
+	if !setting.GetResponseNotNil(resp) || resp.Data[s.VaultTokenKey] == "" {
+		return "", fmt.Errorf("sonar token %s not found in sec man", s.VaultTokenKey)
+	}
+	return strings.TrimSpace(resp.Data[s.VaultTokenKey]), nil
+}
+
+// HasWebhookSecret проверяет, что задан секрет для проверки подписи webhook SonarQube
+func (s *ScSonarSettings) HasWebhookSecret() bool {
+	return s.WebhookSecretEncrypted != ""
+}
+
+// SetWebhookSecret шифрует секрет для проверки подписи webhook SonarQube секретным ключом экземпляра
+func (s *ScSonarSettings) SetWebhookSecret(webhookSecret string) error {
+	if webhookSecret == "" {
+		s.WebhookSecretEncrypted = ""
+		return nil
+	}
+	encrypted, err := secret.EncryptSecret(setting.SecretKey, webhookSecret)
+	if err != nil {
+		return fmt.Errorf("encrypt sonar webhook secret: %w", err)
+	}
+	s.WebhookSecretEncrypted = encrypted
+	return nil
+}
+
+// GetWebhookSecret возвращает расшифрованный секрет для проверки подписи webhook SonarQube
+func (s *ScSonarSettings) GetWebhookSecret() (string, error) {
+	if s.WebhookSecretEncrypted == "" {
+		return "", nil
+	}
+	webhookSecret, err := secret.DecryptSecret(setting.SecretKey, s.WebhookSecretEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("decrypt sonar webhook secret: %w", err)
+	}
+	return webhookSecret, nil
+}
+
+// InsertOrUpdateSonarSettings добавление или изменение настроек Sonar для репозитория (если настроек не было то они добавляются, если были то обновляются новыми значениями).
+// Пустой webhookSecret не изменяет сохраненный секрет для проверки подписи webhook
+func InsertOrUpdateSonarSettings(repoId int64, url string, token string, projectKey string, webhookSecret string) error {
 	var res ScSonarSettings
 	has, err := db.GetEngine(db.DefaultContext).Where("repo_id = ?", repoId).Get(&res)
 	if err != nil {
 		return err
-	} else if !has {
-		return db.Insert(db.DefaultContext, ScSonarSettings{
-			URL:        url,
-			RepoID:     repoId,
-			Token:      token,
-			ProjectKey: projectKey,
-		})
-	} else {
-		_, err = db.GetEngine(db.DefaultContext).ID(res.ID).Update(ScSonarSettings{
-			URL:        url,
-			Token:      token,
-			ProjectKey: projectKey,
-		})
 	}
 
+	settings := ScSonarSettings{
+		URL:        url,
+		RepoID:     repoId,
+		ProjectKey: projectKey,
+	}
+	if err = settings.SetToken(token); err != nil {
+		return err
+	}
+	if err = settings.SetWebhookSecret(webhookSecret); err != nil {
+		return err
+	}
+	if !has {
+		return db.Insert(db.DefaultContext, settings)
+	}
+	cols := []string{"url", "token_encrypted", "vault_storage_path", "vault_secret_path", "vault_token_key", "vault_version_key", "project_key"}
+	if webhookSecret != "" {
+		cols = append(cols, "webhook_secret_encrypted")
+	}
+	_, err = db.GetEngine(db.DefaultContext).ID(res.ID).Cols(cols...).Update(settings)
 	return err
 }
 
@@ -81,3 +258,9 @@ func GetSonarSettingsByProjectKeyAndServerUrl(projectKey, serverUrl string) (*Sc
 	}
 	return &res, nil
 }
+
+// GetSonarSettingsByProjectKey получение всех настроек Sonar с ключом проекта projectKey
+func GetSonarSettingsByProjectKey(ctx context.Context, projectKey string) ([]*ScSonarSettings, error) {
+	settings := make([]*ScSonarSettings, 0)
+	return settings, db.GetEngine(ctx).Where("project_key = ?", projectKey).Find(&settings)
+}
@@ -0,0 +1,89 @@
+//go:build !correct
+
+package repo_test
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScSonarSettings_Token(t *testing.T) {
+	t.Run("encrypted token", func(t *testing.T) {
+		settings := &repo_model.ScSonarSettings{}
+		require.NoError(t, settings.SetToken("squ_0123456789abcdef0123456789abcdef01234567"))
+
+		assert.NotEmpty(t, settings.TokenEncrypted)
+		assert.NotContains(t, settings.TokenEncrypted, "squ_0123456789abcdef")
+		assert.False(t, settings.IsTokenInVault())
+
+		token, err := settings.GetToken()
+		require.NoError(t, err)
+		assert.Equal(t, "squ_0123456789abcdef0123456789abcdef01234567", token)
+	})
+
+	t.Run("vault replaces encrypted token", func(t *testing.T) {
+		settings := &repo_model.ScSonarSettings{}
+		require.NoError(t, settings.SetToken("squ_0123456789abcdef0123456789abcdef01234567"))
+		settings.SetTokenVault(repo_model.SonarTokenVault{StoragePath: "kv", SecretPath: "sonar", TokenKey: "token"})
+
+		assert.True(t, settings.IsTokenInVault())
+		assert.Empty(t, settings.TokenEncrypted)
+		assert.Equal(t, 1, settings.VaultVersionKey)
+
+		// интеграция с sec man в тестах выключена
+		_, err := settings.GetToken()
+		assert.Error(t, err)
+	})
+
+	t.Run("encrypted token replaces vault", func(t *testing.T) {
+		settings := &repo_model.ScSonarSettings{}
+		settings.SetTokenVault(repo_model.SonarTokenVault{StoragePath: "kv", SecretPath: "sonar", TokenKey: "token", VersionKey: 2})
+		require.NoError(t, settings.SetToken("squ_0123456789abcdef0123456789abcdef01234567"))
+
+		assert.False(t, settings.IsTokenInVault())
+		assert.Empty(t, settings.VaultStoragePath)
+	})
+}
+
+func TestSonarTokenVault_Validate(t *testing.T) {
+	oldSonar := setting.Sonar
+	defer func() { setting.Sonar = oldSonar }()
+
+	setting.Sonar.VaultStoragePath = ""
+	setting.Sonar.VaultSecretPathPrefix = ""
+	err := repo_model.SonarTokenVault{StoragePath: "kv", SecretPath: "sonar/1", TokenKey: "token"}.Validate(1)
+	assert.True(t, repo_model.IsErrSonarTokenVaultNotAllowed(err))
+
+	setting.Sonar.VaultStoragePath = "kv"
+	setting.Sonar.VaultSecretPathPrefix = "sonar/{repo_id}"
+	assert.NoError(t, repo_model.SonarTokenVault{StoragePath: "kv", SecretPath: "sonar/1", TokenKey: "token"}.Validate(1))
+	assert.NoError(t, repo_model.SonarTokenVault{StoragePath: "/kv/", SecretPath: "sonar/1/token", TokenKey: "token"}.Validate(1))
+
+	for _, vault := range []repo_model.SonarTokenVault{
+		{StoragePath: "other", SecretPath: "sonar/1", TokenKey: "token"},
+		{StoragePath: "kv", SecretPath: "sonar/2", TokenKey: "token"},
+		{StoragePath: "kv", SecretPath: "sonar/12", TokenKey: "token"},
+		{StoragePath: "kv", SecretPath: "sonar/1/../2", TokenKey: "token"},
+		{StoragePath: "kv", SecretPath: "database", TokenKey: "password"},
+	} {
+		err = vault.Validate(1)
+		assert.True(t, repo_model.IsErrSonarTokenVaultNotAllowed(err), vault.SecretPath)
+	}
+}
+
+func TestScSonarSettings_WebhookSecret(t *testing.T) {
+	settings := &repo_model.ScSonarSettings{}
+	assert.False(t, settings.HasWebhookSecret())
+
+	require.NoError(t, settings.SetWebhookSecret("0123456789abcdef-secret"))
+	assert.True(t, settings.HasWebhookSecret())
+	assert.NotContains(t, settings.WebhookSecretEncrypted, "0123456789abcdef")
+
+	webhookSecret, err := settings.GetWebhookSecret()
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef-secret", webhookSecret)
+}
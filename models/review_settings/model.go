@@ -29,6 +29,9 @@ type ReviewSettings struct {
 	BlockOnOutdatedBranch         bool                   `xorm:"NOT NULL DEFAULT false"`
 	DismissStaleApprovals         bool                   `xorm:"NOT NULL DEFAULT false"`
 	EnableSonarQube               bool                   `xorm:"NOT NULL DEFAULT false"`
+	RequireUnitStatus             bool                   `xorm:"NOT NULL DEFAULT false"` // merge requires a linked unit in one of AllowedUnitStatuses
+	AllowedUnitStatuses           []string               `xorm:"JSON TEXT"`
+	RequiredQualityGates          []string               `xorm:"JSON TEXT"` // providers of quality gates that must pass before merge
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
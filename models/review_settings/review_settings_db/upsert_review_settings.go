@@ -19,6 +19,14 @@ func (r reviewSettingsDB) UpsertReviewSettings(_ context.Context, rs *review_set
 	if err != nil {
 		return fmt.Errorf("marshal contexts: %w", err)
 	}
+	jsonUnitStatuses, err := json.Marshal(rs.AllowedUnitStatuses)
+	if err != nil {
+		return fmt.Errorf("marshal unit statuses: %w", err)
+	}
+	jsonQualityGates, err := json.Marshal(rs.RequiredQualityGates)
+	if err != nil {
+		return fmt.Errorf("marshal quality gates: %w", err)
+	}
 
 	now := timeutil.TimeStampNow()
 
@@ -34,8 +42,10 @@ func (r reviewSettingsDB) UpsertReviewSettings(_ context.Context, rs *review_set
 			enable_default_reviewers, block_on_rejected_reviews,
 			block_on_official_review_requests, block_on_outdated_branch,
 			dismiss_stale_approvals, enable_sonar_qube,
+			require_unit_status, allowed_unit_statuses,
+			required_quality_gates,
 			created_unix, updated_unix
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(repo_id, branch_name) DO UPDATE SET
 			enable_merge_whitelist = excluded.enable_merge_whitelist,
 			merge_whitelist_user_i_ds = excluded.merge_whitelist_user_i_ds,
@@ -47,6 +57,9 @@ func (r reviewSettingsDB) UpsertReviewSettings(_ context.Context, rs *review_set
 			block_on_outdated_branch = excluded.block_on_outdated_branch,
 			dismiss_stale_approvals = excluded.dismiss_stale_approvals,
 			enable_sonar_qube = excluded.enable_sonar_qube,
+			require_unit_status = excluded.require_unit_status,
+			allowed_unit_statuses = excluded.allowed_unit_statuses,
+			required_quality_gates = excluded.required_quality_gates,
 			updated_unix = excluded.updated_unix
 	`, rs.RepoID, rs.RuleName,
 		rs.EnableMergeWhitelist, string(jsonUserIDs),
@@ -54,6 +67,8 @@ func (r reviewSettingsDB) UpsertReviewSettings(_ context.Context, rs *review_set
 		rs.EnableDefaultReviewers, rs.BlockOnRejectedReviews,
 		rs.BlockOnOfficialReviewRequests, rs.BlockOnOutdatedBranch,
 		rs.DismissStaleApprovals, rs.EnableSonarQube,
+		rs.RequireUnitStatus, string(jsonUnitStatuses),
+		string(jsonQualityGates),
 		rs.CreatedUnix, now)
 
 	if err != nil {
@@ -54,3 +54,12 @@ func GetActionByString(str string) (Action, bool) {
 	}
 	return 0, false
 }
+
+// GetAllActions возвращает все действия в порядке их значений
+func GetAllActions() []Action {
+	all := make([]Action, 0, len(actions))
+	for action := OWN; action <= MANAGE_COMMENTS; action++ {
+		all = append(all, action)
+	}
+	return all
+}
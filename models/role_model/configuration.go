@@ -54,17 +54,23 @@ import (
 																																																- тенант в политике и в запросе совпадают
 																																																- проект в политике и в запросе совпадают
 																																																- содержит ли политика запрашиваемое действие в списке разрешенных действий
+		r/r4, p/p6 - sub, tenant, project, repository, act - аналогичны r/r и p/p, дополнительно содержат repository -
+репозиторий проекта, на который назначена роль.
+		m/m6 - аналогичен m/m, дополнительно проверяет, что репозиторий в политике и в запросе совпадают.
+Роль на репозиторий имеет приоритет над ролью в проекте
 */
 func configureRoleModel() model.Model {
 	m := model.NewModel()
 	m.AddDef("r", "r", "sub, tenant, project, act")
 	m.AddDef("r", "r2", "sub, tenant, project")
 	m.AddDef("r", "r3", "team, project, repository, act")
+	m.AddDef("r", "r4", "sub, tenant, project, repository, act")
 	m.AddDef("p", "p", "sub, tenant, project, act")
 	m.AddDef("p", "p2", "project, act")
 	m.AddDef("p", "p3", "sub, act")
 	m.AddDef("p", "p4", "sub, tenant, project, team")
 	m.AddDef("p", "p5", "team, project, repository, name") // cB_vB_vPR, create_branch, view_branch, view_Pr
+	m.AddDef("p", "p6", "sub, tenant, project, repository, act")
 	m.AddDef("g", "g", "_, _")
 	m.AddDef("g", "g2", "_, _")
 	m.AddDef("g", "g3", "_, _")
@@ -75,6 +81,8 @@ func configureRoleModel() model.Model {
 	m.AddDef("m", "m3", "r.sub == p3.sub && g(p3.act, r.act)")
 	m.AddDef("m", "m4", "r2.sub == p4.sub && r2.tenant == p4.tenant && r2.project == p4.project")
 	m.AddDef("m", "m5", "r3.team == p5.team && r3.project == p5.project && r3.repository == p5.repository && g3(p5.name, r3.act)")
+	// роль пользователя на отдельный репозиторий проекта, проверяется раньше роли в проекте
+	m.AddDef("m", "m6", "r4.sub == p6.sub && r4.tenant == p6.tenant && r4.project == p6.project && r4.repository == p6.repository && g(p6.act, r4.act)")
 	return m
 }
 
@@ -180,42 +188,7 @@ func CheckUserPermissionToOrganization(ctx context.Context, sub *user_model.User
 		}
 	}()
 
-	permitted, err := securityEnforcer.Enforce(strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10), action.String())
-	if err != nil {
-		log.Error("Error has occurred while checking %v permission to projectId: %d for userId: %d under tenantId: %v. Error: %v", action.String(), org.ID, sub.ID, tenantId, err)
-		return false, err
-	}
-	permittedFromInnerSource, errFromInnerSource := securityEnforcer.Enforce(casbin.EnforceContext{
-		RType: "r",
-		PType: "p2",
-		EType: "e",
-		MType: "m2",
-	}, strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10), action.String())
-	if errFromInnerSource != nil {
-		log.Error("Error has occurred while checking %v permission to projectId: %d for userId: %d under tenantId: %v. Error: %v", action.String(), org.ID, sub.ID, tenantId, errFromInnerSource)
-		return false, errFromInnerSource
-	}
-
-	permittedTuz, errTuz := securityEnforcer.Enforce(casbin.EnforceContext{
-		RType: "r",
-		PType: "p3",
-		EType: "e",
-		MType: "m3",
-	}, strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10), action.String())
-	if errTuz != nil {
-		log.Error("Error has occurred while checking %v permission to projectId: %d for userId: %d under tenantId: %v. Error: %v", action.String(), org.ID, sub.ID, tenantId, errTuz)
-		return false, errTuz
-	}
-	if !permittedTuz && !permitted && permittedFromInnerSource {
-		tenantOrg, err := tenant.GetTenantOrganizationsByOrgId(db.DefaultContext, org.ID)
-		if err != nil {
-			log.Error("Error has occurred while checking %s permission to projectId: %d for userId: %d. Error: %v", action.String(), org.ID, sub.ID, err)
-			return false, err
-		}
-		permittedFromInnerSource = tenantOrg.TenantID == tenantId
-	}
-
-	return permitted || permittedFromInnerSource || permittedTuz, nil
+	return decideUserPermissionToOrganization(ctx, &permissionDecision{}, sub, tenantId, org, action)
 }
 
 // CheckUserPermissionToTeam проверяет доступ пользователя к репозиторию по кастомным привилегиям +++
@@ -245,41 +218,7 @@ func CheckUserPermissionToTeam(ctx context.Context, sub *user_model.User, tenant
 	if org == nil || sub == nil || repo == nil {
 		return false, fmt.Errorf("invalid input: organization, user, or repository is nil")
 	}
-	permitted, err := securityEnforcer.Enforce(casbin.EnforceContext{
-		RType: "r2",
-		PType: "p4",
-		EType: "e",
-		MType: "m4",
-	}, strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10))
-	if err != nil {
-		log.Error("Error has occurred while checking action: %v permission for userID: %v, projectID: %v, repoID: %v, branch: %v. Error: %v", action, sub.ID, org.ID, repo.ID, err)
-		return false, fmt.Errorf("matching policies: %w", err)
-	}
-
-	teams, err := organization.GetUserOrgTeams(db.DefaultContext, org.ID, sub.ID)
-	if err != nil {
-		log.Error("Error has occurred while getting user organization teams for userID: %v, projectID: %v. Error: %v", sub.ID, org.ID, err)
-		return false, fmt.Errorf("getting teams by org: %w", err)
-	}
-
-	for _, t := range teams {
-		permittedForTeam, err := securityEnforcer.Enforce(casbin.EnforceContext{
-			RType: "r3",
-			PType: "p5",
-			EType: "e",
-			MType: "m5",
-		}, t.Name, strconv.FormatInt(org.ID, 10), strconv.FormatInt(repo.ID, 10), action)
-		if err != nil {
-			log.Error("Error has occurred while checking action: %v permission for userID: %v, projectID: %v, repoID: %v, branch: %v. Error: %v", action, sub.ID, org.ID, repo.ID, err)
-			return false, fmt.Errorf("matching policies: %w", err)
-		}
-
-		if permitted && permittedForTeam {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return decideUserPermissionToTeam(ctx, &permissionDecision{}, sub, tenantId, org, repo, action)
 }
 
 // RevokeUserPermissionToOrganization снимает с пользователя роль в проекте под тенантом
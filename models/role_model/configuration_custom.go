@@ -11,6 +11,9 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 )
 
+// customGroupsStartIndex - начальный индекс кастомных групп привилегий из конфигурации
+const customGroupsStartIndex = 1 << 20
+
 // syncPrivilegesFromConfig - синхронизация привилегий при инициализации ролевой модели
 func syncPrivilegesFromConfig(ctx context.Context) error {
 	return db.WithTx(ctx, func(ctx context.Context) error {
@@ -22,7 +25,8 @@ func syncPrivilegesFromConfig(ctx context.Context) error {
 		mapCustomGroups := convertCustomGroupsToMap(customGroups)
 		existingGroup := make(map[string]interface{}, len(mapCustomGroups))
 
-		startIndex := 4 // первые 4 индекса занимает дефолтная ролевка, поэтому начинаем с 5-ого
+		// индексы кастомных групп не должны пересекаться с идентификаторами ролей из таблицы sc_role
+		startIndex := customGroupsStartIndex
 
 		for code, group := range setting.SourceControlCustomGroups.CustomGroups {
 			if !validateCustomGroup(code, group) {
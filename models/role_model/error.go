@@ -52,3 +52,64 @@ func IsErrCustomGroupNotFound(err error) bool {
 func (err ErrCustomGroupNotFound) Error() string {
 	return fmt.Sprintf("Custom Group %s not found", err.Group)
 }
+
+// ErrRoleDefinitionNotExist представляет собой ошибку типа "ErrRoleDefinitionNotExist"
+type ErrRoleDefinitionNotExist struct {
+	Name string
+}
+
+// IsErrRoleDefinitionNotExist проверяет, является ли ошибка ErrRoleDefinitionNotExist.
+func IsErrRoleDefinitionNotExist(err error) bool {
+	errRoleDefinitionNotExist := new(ErrRoleDefinitionNotExist)
+	return errors.As(err, &errRoleDefinitionNotExist)
+}
+
+func (err ErrRoleDefinitionNotExist) Error() string {
+	return fmt.Sprintf("Role definition %s does not exist", err.Name)
+}
+
+// ErrRoleDefinitionAlreadyExist представляет собой ошибку типа "ErrRoleDefinitionAlreadyExist"
+type ErrRoleDefinitionAlreadyExist struct {
+	Name string
+}
+
+// IsErrRoleDefinitionAlreadyExist проверяет, является ли ошибка ErrRoleDefinitionAlreadyExist.
+func IsErrRoleDefinitionAlreadyExist(err error) bool {
+	errRoleDefinitionAlreadyExist := new(ErrRoleDefinitionAlreadyExist)
+	return errors.As(err, &errRoleDefinitionAlreadyExist)
+}
+
+func (err ErrRoleDefinitionAlreadyExist) Error() string {
+	return fmt.Sprintf("Role definition %s already exists", err.Name)
+}
+
+// ErrRoleDefinitionInvalid представляет собой ошибку типа "ErrRoleDefinitionInvalid"
+type ErrRoleDefinitionInvalid struct {
+	Name   string
+	Reason string
+}
+
+// IsErrRoleDefinitionInvalid проверяет, является ли ошибка ErrRoleDefinitionInvalid.
+func IsErrRoleDefinitionInvalid(err error) bool {
+	errRoleDefinitionInvalid := new(ErrRoleDefinitionInvalid)
+	return errors.As(err, &errRoleDefinitionInvalid)
+}
+
+func (err ErrRoleDefinitionInvalid) Error() string {
+	return fmt.Sprintf("Role definition %s is invalid: %s", err.Name, err.Reason)
+}
+
+// ErrRoleDefinitionInUse представляет собой ошибку типа "ErrRoleDefinitionInUse"
+type ErrRoleDefinitionInUse struct {
+	Name string
+}
+
+// IsErrRoleDefinitionInUse проверяет, является ли ошибка ErrRoleDefinitionInUse.
+func IsErrRoleDefinitionInUse(err error) bool {
+	errRoleDefinitionInUse := new(ErrRoleDefinitionInUse)
+	return errors.As(err, &errRoleDefinitionInUse)
+}
+
+func (err ErrRoleDefinitionInUse) Error() string {
+	return fmt.Sprintf("Role definition %s is assigned to users", err.Name)
+}
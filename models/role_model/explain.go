@@ -0,0 +1,171 @@
+package role_model
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/casbin/casbin/v2"
+
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Проверки ролевой модели, участвующие в принятии решения о доступе
+const (
+	ExplainCheckProjectRole    = "project_role"
+	ExplainCheckInnerSource    = "inner_source"
+	ExplainCheckTuz            = "tuz"
+	ExplainCheckRepositoryRole = "repository_role"
+	ExplainCheckTeamMembership = "team_membership"
+	ExplainCheckTeamPrivilege  = "team_custom_privilege"
+)
+
+// ExplainedGrouping связь наследования (g, g2, g3), через которую политика дает запрошенное действие
+// swagger:model ExplainedGrouping
+type ExplainedGrouping struct {
+	Type   string `json:"type"`
+	Role   string `json:"role"`
+	Action string `json:"action"`
+}
+
+// ExplainedRule результат одной проверки ролевой модели
+// swagger:model ExplainedRule
+type ExplainedRule struct {
+	Check      string              `json:"check"`
+	PolicyType string              `json:"policy_type"`
+	Request    []string            `json:"request"`
+	Allowed    bool                `json:"allowed"`
+	Policy     []string            `json:"policy,omitempty"`
+	Groupings  []ExplainedGrouping `json:"groupings,omitempty"`
+	Message    string              `json:"message,omitempty"`
+}
+
+// PermissionExplanation решение о доступе и проверки, которые к нему привели
+type PermissionExplanation struct {
+	Allowed bool            `json:"allowed"`
+	Rules   []ExplainedRule `json:"rules"`
+}
+
+// ExplainUserPermissionToOrganization объясняет решение CheckUserPermissionToOrganization
+func ExplainUserPermissionToOrganization(ctx context.Context, sub *user_model.User, tenantId string, org *organization.Organization, action Action) (*PermissionExplanation, error) {
+	if sub == nil || org == nil {
+		return nil, fmt.Errorf("invalid input: user or organization is nil")
+	}
+	decision := &permissionDecision{explain: true}
+	allowed, err := decideUserPermissionToOrganization(ctx, decision, sub, tenantId, org, action)
+	if err != nil {
+		return nil, err
+	}
+	return decision.explanation(allowed), nil
+}
+
+// ExplainUserPermissionToRepository объясняет решение CheckUserPermissionToRepository
+func ExplainUserPermissionToRepository(ctx context.Context, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, action Action) (*PermissionExplanation, error) {
+	if sub == nil || org == nil || repo == nil {
+		return nil, fmt.Errorf("invalid input: user, organization or repository is nil")
+	}
+	decision := &permissionDecision{explain: true}
+	allowed, err := decideUserPermissionToRepository(ctx, decision, sub, tenantId, org, repo, action)
+	if err != nil {
+		return nil, err
+	}
+	return decision.explanation(allowed), nil
+}
+
+// ExplainUserPermissionToRepositoryOrTeam объясняет решение CheckUserPermissionToRepositoryOrTeam
+func ExplainUserPermissionToRepositoryOrTeam(ctx context.Context, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, action Action, privilege CustomPrivilege) (*PermissionExplanation, error) {
+	if sub == nil || org == nil || repo == nil {
+		return nil, fmt.Errorf("invalid input: user, organization or repository is nil")
+	}
+	decision := &permissionDecision{explain: true}
+	allowed, err := decideUserPermissionToRepositoryOrTeam(ctx, decision, sub, tenantId, org, repo, action, privilege)
+	if err != nil {
+		return nil, err
+	}
+	return decision.explanation(allowed), nil
+}
+
+// explainEnforce выполняет проверку casbin с объяснением и дополняет сработавшую политику цепочкой наследования.
+// actionIndex - индекс поля политики, от которого наследуется запрошенное действие (последнее поле запроса)
+func explainEnforce(check string, enforceContext casbin.EnforceContext, groupingType string, actionIndex int, request []string) (ExplainedRule, error) {
+	rule := ExplainedRule{
+		Check:      check,
+		PolicyType: enforceContext.PType,
+		Request:    request,
+	}
+
+	rvals := make([]interface{}, 0, len(request)+1)
+	rvals = append(rvals, enforceContext)
+	for _, value := range request {
+		rvals = append(rvals, value)
+	}
+	allowed, policy, err := securityEnforcer.EnforceEx(rvals...)
+	if err != nil {
+		log.Error("Error has occurred while explaining %s check for request: %v. Error: %v", check, request, err)
+		return rule, fmt.Errorf("enforce %s: %w", check, err)
+	}
+	rule.Allowed = allowed
+	if !allowed || len(policy) == 0 {
+		return rule, nil
+	}
+
+	rule.Policy = policy
+	if groupingType != "" && actionIndex < len(policy) {
+		groupings, err := findGroupingPath(groupingType, policy[actionIndex], request[len(request)-1])
+		if err != nil {
+			return rule, err
+		}
+		rule.Groupings = groupings
+	}
+	return rule, nil
+}
+
+// findGroupingPath ищет цепочку политик наследования от роли политики до запрошенного действия
+func findGroupingPath(groupingType, from, to string) ([]ExplainedGrouping, error) {
+	policies, err := securityEnforcer.GetNamedGroupingPolicy(groupingType)
+	if err != nil {
+		log.Error("Error has occurred while getting %s grouping policies. Error: %v", groupingType, err)
+		return nil, fmt.Errorf("get named grouping policy: %w", err)
+	}
+	return groupingPath(groupingType, policies, from, to), nil
+}
+
+// groupingPath находит кратчайшую цепочку наследования from -> to среди политик наследования (поиск в ширину)
+func groupingPath(groupingType string, policies [][]string, from, to string) []ExplainedGrouping {
+	if from == to {
+		return nil
+	}
+	edges := make(map[string][]string, len(policies))
+	for _, policy := range policies {
+		if len(policy) >= 2 {
+			edges[policy[0]] = append(edges[policy[0]], policy[1])
+		}
+	}
+
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			break
+		}
+		for _, next := range edges[current] {
+			if _, visited := previous[next]; !visited {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	if _, found := previous[to]; !found {
+		return nil
+	}
+
+	path := make([]ExplainedGrouping, 0, 4)
+	for current := to; current != from; current = previous[current] {
+		path = append([]ExplainedGrouping{{Type: groupingType, Role: previous[current], Action: current}}, path...)
+	}
+	return path
+}
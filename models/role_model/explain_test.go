@@ -0,0 +1,27 @@
+//go:build !correct
+
+package role_model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGroupingPath проверяет поиск цепочки наследования от роли до действия
+func TestGroupingPath(t *testing.T) {
+	policies := [][]string{
+		{"owner", "own"},
+		{"owner", "write"},
+		{"tuz", "owner"},
+		{"reader", "read"},
+	}
+
+	assert.Equal(t, []ExplainedGrouping{
+		{Type: "g", Role: "tuz", Action: "owner"},
+		{Type: "g", Role: "owner", Action: "write"},
+	}, groupingPath("g", policies, "tuz", "write"))
+	assert.Equal(t, []ExplainedGrouping{{Type: "g", Role: "reader", Action: "read"}}, groupingPath("g", policies, "reader", "read"))
+	assert.Nil(t, groupingPath("g", policies, "reader", "write"))
+	assert.Nil(t, groupingPath("g", policies, "read", "read"))
+}
@@ -2,7 +2,6 @@ package role_model
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/casbin/casbin/v2"
 
@@ -95,98 +94,19 @@ func InitRoleModel() error {
 		return err
 	}
 
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), OWN.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", OWN.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), CREATE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", CREATE.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), EDIT.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", EDIT.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), EDIT_PROJECT.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", EDIT_PROJECT.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), READ.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", READ.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), READ_PRIVATE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", READ_PRIVATE.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), WRITE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", WRITE.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), DELETE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", DELETE.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), MERGE_WITHOUT_CHECK.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", MERGE_WITHOUT_CHECK.String(), OWNER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(OWNER.String(), MANAGE_COMMENTS.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", DELETE.String(), MANAGE_COMMENTS.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(MANAGER.String(), READ_PRIVATE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", READ_PRIVATE.String(), MANAGER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(MANAGER.String(), CREATE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", CREATE.String(), MANAGER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(MANAGER.String(), EDIT.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", EDIT.String(), MANAGER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(MANAGER.String(), DELETE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", DELETE.String(), MANAGER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(MANAGER.String(), WRITE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", WRITE.String(), MANAGER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(MANAGER.String(), READ.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", READ.String(), MANAGER.String(), err)
-		return err
-	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(WRITER.String(), READ.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", READ.String(), WRITER.String(), err)
+	// политики наследования ролей (g) пересоздаются по описаниям ролей из БД
+	roles, err := loadRoleDefinitions(ctx)
+	if err != nil {
+		log.Error("Error has occurred while loading role definitions. Error: %v", err)
 		return err
 	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(WRITER.String(), WRITE.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", WRITE.String(), WRITER.String(), err)
-		return err
+	for _, role := range roles {
+		if err = addRoleDefinitionPolicies(securityEnforcer, role); err != nil {
+			return err
+		}
 	}
-
-	if _, err = securityEnforcer.AddGroupingPolicy(READER.String(), READ.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", READ.String(), READER.String(), err)
+	if loadedRevision, err = getRoleDefinitionsRevision(ctx); err != nil {
+		log.Error("Error has occurred while getting role definitions revision. Error: %v", err)
 		return err
 	}
 
@@ -195,11 +115,6 @@ func InitRoleModel() error {
 		return err
 	}
 
-	if _, err = securityEnforcer.AddGroupingPolicy(TUZ.String(), OWNER.String()); err != nil {
-		log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", OWNER.String(), TUZ.String(), err)
-		return fmt.Errorf("add grouping policy: %w", err)
-	}
-
 	if setting.SourceControlCustomGroups.Enabled {
 		err = syncPrivilegesFromConfig(ctx)
 		if err != nil {
@@ -0,0 +1,159 @@
+package role_model
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/casbin/casbin/v2"
+
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// permissionDecision принимает решение о доступе по проверкам ролевой модели.
+// Используется как при проверке доступа, так и при его объяснении: при explain каждая проверка
+// выполняется с поиском сработавшей политики и сохраняется в rules
+type permissionDecision struct {
+	explain bool
+	rules   []ExplainedRule
+}
+
+// enforce выполняет одну проверку ролевой модели
+func (d *permissionDecision) enforce(check string, enforceContext casbin.EnforceContext, groupingType string, actionIndex int, request []string) (bool, error) {
+	if d.explain {
+		rule, err := explainEnforce(check, enforceContext, groupingType, actionIndex, request)
+		if err != nil {
+			return false, err
+		}
+		d.rules = append(d.rules, rule)
+		return rule.Allowed, nil
+	}
+
+	rvals := make([]interface{}, 0, len(request)+1)
+	rvals = append(rvals, enforceContext)
+	for _, value := range request {
+		rvals = append(rvals, value)
+	}
+	allowed, err := securityEnforcer.Enforce(rvals...)
+	if err != nil {
+		log.Error("Error has occurred while checking %s for request: %v. Error: %v", check, request, err)
+		return false, fmt.Errorf("enforce %s: %w", check, err)
+	}
+	return allowed, nil
+}
+
+// deny отмечает проверку check как не давшую доступ по причине message
+func (d *permissionDecision) deny(check, message string) {
+	for idx := len(d.rules) - 1; idx >= 0; idx-- {
+		if d.rules[idx].Check == check {
+			d.rules[idx].Allowed = false
+			d.rules[idx].Message = message
+			return
+		}
+	}
+}
+
+// explanation возвращает решение о доступе вместе с выполненными проверками
+func (d *permissionDecision) explanation(allowed bool) *PermissionExplanation {
+	rules := d.rules
+	if rules == nil {
+		rules = []ExplainedRule{}
+	}
+	return &PermissionExplanation{Allowed: allowed, Rules: rules}
+}
+
+// decideUserPermissionToOrganization доступ к проекту дает роль в проекте, роль ТУЗ или inner source проекта для пользователей его тенанта
+func decideUserPermissionToOrganization(ctx context.Context, d *permissionDecision, sub *user_model.User, tenantId string, org *organization.Organization, action Action) (bool, error) {
+	request := []string{strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10), action.String()}
+
+	permitted, err := d.enforce(ExplainCheckProjectRole, casbin.EnforceContext{RType: "r", PType: "p", EType: "e", MType: "m"}, "g", 3, request)
+	if err != nil {
+		return false, err
+	}
+	permittedFromInnerSource, err := d.enforce(ExplainCheckInnerSource, casbin.EnforceContext{RType: "r", PType: "p2", EType: "e", MType: "m2"}, "g2", 1, request)
+	if err != nil {
+		return false, err
+	}
+	permittedTuz, err := d.enforce(ExplainCheckTuz, casbin.EnforceContext{RType: "r", PType: "p3", EType: "e", MType: "m3"}, "g", 1, request)
+	if err != nil {
+		return false, err
+	}
+
+	// доступ inner source дается только пользователям тенанта проекта
+	if !permittedTuz && !permitted && permittedFromInnerSource {
+		tenantOrg, err := tenant.GetTenantOrganizationsByOrgId(ctx, org.ID)
+		if err != nil {
+			log.Error("Error has occurred while checking %s permission to projectId: %d for userId: %d. Error: %v", action.String(), org.ID, sub.ID, err)
+			return false, fmt.Errorf("get tenant organization: %w", err)
+		}
+		if tenantOrg.TenantID != tenantId {
+			permittedFromInnerSource = false
+			d.deny(ExplainCheckInnerSource, "project is not under requested tenant")
+		}
+	}
+
+	return permitted || permittedFromInnerSource || permittedTuz, nil
+}
+
+// decideUserPermissionToRepository роль, назначенная на репозиторий, имеет приоритет над ролью в проекте; при ее отсутствии проверяется доступ к проекту
+func decideUserPermissionToRepository(ctx context.Context, d *permissionDecision, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, action Action) (bool, error) {
+	_, hasRepoRole, err := GetRepoRoleForUser(sub.ID, tenantId, org.ID, repo.ID)
+	if err != nil {
+		return false, err
+	}
+	if !hasRepoRole {
+		return decideUserPermissionToOrganization(ctx, d, sub, tenantId, org, action)
+	}
+
+	request := []string{strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10), strconv.FormatInt(repo.ID, 10), action.String()}
+	return d.enforce(ExplainCheckRepositoryRole, casbin.EnforceContext{RType: "r4", PType: repoPolicyType, EType: "e", MType: "m6"}, "g", 4, request)
+}
+
+// decideUserPermissionToTeam доступ по кастомной привилегии дает участие пользователя в проекте и привилегия одной из его команд на репозиторий
+func decideUserPermissionToTeam(ctx context.Context, d *permissionDecision, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, privilege string) (bool, error) {
+	orgID := strconv.FormatInt(org.ID, 10)
+	permitted, err := d.enforce(ExplainCheckTeamMembership, casbin.EnforceContext{RType: "r2", PType: "p4", EType: "e", MType: "m4"}, "", 0,
+		[]string{strconv.FormatInt(sub.ID, 10), tenantId, orgID})
+	if err != nil {
+		return false, fmt.Errorf("matching policies: %w", err)
+	}
+
+	teams, err := organization.GetUserOrgTeams(ctx, org.ID, sub.ID)
+	if err != nil {
+		log.Error("Error has occurred while getting user organization teams for userID: %v, projectID: %v. Error: %v", sub.ID, org.ID, err)
+		return false, fmt.Errorf("getting teams by org: %w", err)
+	}
+
+	for _, t := range teams {
+		permittedForTeam, err := d.enforce(ExplainCheckTeamPrivilege, casbin.EnforceContext{RType: "r3", PType: "p5", EType: "e", MType: "m5"}, "g3", 3,
+			[]string{t.Name, orgID, strconv.FormatInt(repo.ID, 10), privilege})
+		if err != nil {
+			return false, fmt.Errorf("matching policies: %w", err)
+		}
+		if permitted && permittedForTeam {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// decideUserPermissionToRepositoryOrTeam доступ к репозиторию дает действие роли пользователя, а при его отсутствии - кастомная привилегия команды
+func decideUserPermissionToRepositoryOrTeam(ctx context.Context, d *permissionDecision, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, action Action, privilege CustomPrivilege) (bool, error) {
+	allowed, err := decideUserPermissionToRepository(ctx, d, sub, tenantId, org, repo, action)
+	if err != nil || allowed {
+		return allowed, err
+	}
+	return decideUserPermissionToTeam(ctx, d, sub, tenantId, org, repo, privilege.String())
+}
+
+// CheckUserPermissionToRepositoryOrTeam проверяет доступ пользователя к репозиторию по роли, а при его отсутствии - по кастомной привилегии команды
+func CheckUserPermissionToRepositoryOrTeam(ctx context.Context, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, action Action, privilege CustomPrivilege) (bool, error) {
+	if sub == nil || org == nil || repo == nil {
+		return false, fmt.Errorf("invalid input: user, organization or repository is nil")
+	}
+	return decideUserPermissionToRepositoryOrTeam(ctx, &permissionDecision{}, sub, tenantId, org, repo, action, privilege)
+}
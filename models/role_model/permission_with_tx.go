@@ -45,7 +45,7 @@ func RemoveExistingPrivilegesByTenantAndOrgID(tenantID string, orgID int64) erro
 			}
 		}
 	}
-	return nil
+	return removeRepoPrivilegesByOrgID(orgID)
 }
 
 // RevokeUserPermissionToOrganizationTx снимает с пользователя роль в проекте под тенантом
@@ -0,0 +1,71 @@
+package role_model
+
+import (
+	"context"
+	"fmt"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ScPrivilegeExpiration - структура таблицы sc_privilege_expiration, срок действия привилегии пользователя в проекте.
+// Привилегии без записи в таблице действуют бессрочно
+type ScPrivilegeExpiration struct {
+	ID        int64              `xorm:"pk autoincr"`
+	UserID    int64              `xorm:"UNIQUE(s) NOT NULL"`
+	TenantID  string             `xorm:"VARCHAR(50) NOT NULL"`
+	OrgID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Role      string             `xorm:"VARCHAR(100) NOT NULL"`
+	ExpiresAt timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	Created   timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ScPrivilegeExpiration))
+}
+
+// SetPrivilegeExpiration - устанавливает срок действия привилегии пользователя в проекте, заменяя предыдущий
+func SetPrivilegeExpiration(ctx context.Context, expiration *ScPrivilegeExpiration) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := RemovePrivilegeExpiration(ctx, expiration.UserID, expiration.OrgID); err != nil {
+			return err
+		}
+		if _, err := db.GetEngine(ctx).Insert(expiration); err != nil {
+			return fmt.Errorf("error has occurred while adding privilege expiration: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemovePrivilegeExpiration - удаляет срок действия привилегии пользователя в проекте, делая привилегию бессрочной
+func RemovePrivilegeExpiration(ctx context.Context, userID, orgID int64) error {
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"user_id": userID, "org_id": orgID}).Delete(new(ScPrivilegeExpiration))
+	if err != nil {
+		return fmt.Errorf("error has occurred while deleting privilege expiration: %w", err)
+	}
+	return nil
+}
+
+// GetPrivilegeExpiration - возвращает срок действия привилегии пользователя в проекте, nil если привилегия бессрочная
+func GetPrivilegeExpiration(ctx context.Context, userID, orgID int64) (*ScPrivilegeExpiration, error) {
+	expiration := new(ScPrivilegeExpiration)
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"user_id": userID, "org_id": orgID}).Get(expiration)
+	if err != nil {
+		return nil, fmt.Errorf("error has occurred while getting privilege expiration: %w", err)
+	}
+	if !has {
+		return nil, nil
+	}
+	return expiration, nil
+}
+
+// GetExpiredPrivileges - возвращает привилегии, срок действия которых истек к моменту now
+func GetExpiredPrivileges(ctx context.Context, now timeutil.TimeStamp) ([]*ScPrivilegeExpiration, error) {
+	expired := make([]*ScPrivilegeExpiration, 0)
+	if err := db.GetEngine(ctx).Where(builder.Lte{"expires_at": now}).Asc("expires_at").Find(&expired); err != nil {
+		return nil, fmt.Errorf("error has occurred while getting expired privileges: %w", err)
+	}
+	return expired, nil
+}
@@ -0,0 +1,306 @@
+package role_model
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/casbin/casbin/v2"
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// repoPolicyType тип политики casbin для ролей, назначенных на отдельный репозиторий проекта
+const repoPolicyType = "p6"
+
+// ScRepoPrivilegeCollaboration - структура таблицы sc_repo_privilege_collaboration, участие пользователя в репозитории,
+// созданное при назначении роли на репозиторий. Участие, добавленное в репозиторий другими способами, при снятии роли не удаляется
+type ScRepoPrivilegeCollaboration struct {
+	ID      int64              `xorm:"pk autoincr"`
+	RepoID  int64              `xorm:"UNIQUE(s) NOT NULL"`
+	UserID  int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Created timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ScRepoPrivilegeCollaboration))
+}
+
+// RepoPrivilege привилегия пользователя на отдельный репозиторий проекта
+type RepoPrivilege struct {
+	UserID   int64
+	TenantID string
+	OrgID    int64
+	RepoID   int64
+	Role     Role
+}
+
+// convertStringToRepoPrivilegeArray конвертирует массив привилегий на репозитории из casbin в массив RepoPrivilege
+func convertStringToRepoPrivilegeArray(policies [][]string) ([]RepoPrivilege, error) {
+	privileges := make([]RepoPrivilege, 0, len(policies))
+	for _, policy := range policies {
+		if len(policy) != 5 {
+			continue
+		}
+		userID, err := strconv.ParseInt(policy[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse userId: %s. Error: %w", policy[0], err)
+		}
+		orgID, err := strconv.ParseInt(policy[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse orgId: %s. Error: %w", policy[2], err)
+		}
+		repoID, err := strconv.ParseInt(policy[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse repoId: %s. Error: %w", policy[3], err)
+		}
+		role, ok := GetRoleByString(policy[4])
+		if !ok {
+			return nil, &ErrNonExistentRole{Role: policy[4]}
+		}
+		privileges = append(privileges, RepoPrivilege{
+			UserID:   userID,
+			TenantID: policy[1],
+			OrgID:    orgID,
+			RepoID:   repoID,
+			Role:     role,
+		})
+	}
+	return privileges, nil
+}
+
+// GrantUserPermissionToRepositoryTx назначает пользователю роль на репозиторий проекта под тенантом, заменяя ранее назначенную роль на этот репозиторий
+func GrantUserPermissionToRepositoryTx(enforcer casbin.IEnforcer, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, role Role) error {
+	if err := AddRepoPrivilegeCollaborator(db.DefaultContext, enforcer, sub, org, repo, role); err != nil {
+		return err
+	}
+	return GrantUserRepositoryPolicyTx(enforcer, sub, tenantId, org, repo, role)
+}
+
+// RevokeUserPermissionToRepositoryTx снимает с пользователя роль на репозиторий проекта под тенантом
+func RevokeUserPermissionToRepositoryTx(enforcer casbin.IEnforcer, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, role Role) error {
+	if err := RevokeUserRepositoryPolicyTx(enforcer, sub, tenantId, org, repo, role); err != nil {
+		return err
+	}
+	return RemoveRepoPrivilegeCollaborator(db.DefaultContext, sub, repo)
+}
+
+// AddRepoPrivilegeCollaborator добавляет пользователя в участники репозитория с уровнем доступа роли.
+// Изменяет только записи БД в транзакции ctx, политика роли назначается GrantUserRepositoryPolicyTx
+func AddRepoPrivilegeCollaborator(ctx context.Context, enforcer casbin.IEnforcer, sub *user_model.User, org *organization.Organization, repo *repo_model.Repository, role Role) error {
+	if sub == nil || org == nil || repo == nil {
+		return fmt.Errorf("sub, org or repo is required")
+	}
+	if repo.OwnerID != org.ID {
+		return fmt.Errorf("repoId: %d does not belong to orgId: %d", repo.ID, org.ID)
+	}
+
+	mode, err := repoCollaborationMode(enforcer, role)
+	if err != nil {
+		log.Error("Error has occurred while resolving access mode of role: %v. Error: %v", role.String(), err)
+		return fmt.Errorf("resolve access mode: %w", err)
+	}
+	if err := addRepoCollaborator(ctx, repo, sub.ID, mode); err != nil {
+		log.Error("Error has occurred while adding userId: %d as collaborator to repoId: %d. Error: %v", sub.ID, repo.ID, err)
+		return fmt.Errorf("add collaborator: %w", err)
+	}
+	return nil
+}
+
+// RemoveRepoPrivilegeCollaborator удаляет участие пользователя в репозитории, созданное при назначении роли на репозиторий.
+// Изменяет только записи БД в транзакции ctx, политика роли снимается RevokeUserRepositoryPolicyTx
+func RemoveRepoPrivilegeCollaborator(ctx context.Context, sub *user_model.User, repo *repo_model.Repository) error {
+	if sub == nil || repo == nil {
+		return fmt.Errorf("sub or repo is required")
+	}
+	if err := removeRepoCollaborator(ctx, repo, sub.ID); err != nil {
+		log.Error("Error has occurred while removing userId: %d collaboration from repoId: %d. Error: %v", sub.ID, repo.ID, err)
+		return fmt.Errorf("delete collaboration: %w", err)
+	}
+	return nil
+}
+
+// GrantUserRepositoryPolicyTx назначает политику роли пользователя на репозиторий, заменяя ранее назначенную роль на этот репозиторий.
+// Участники репозитория не изменяются
+func GrantUserRepositoryPolicyTx(enforcer casbin.IEnforcer, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, role Role) error {
+	if sub == nil || org == nil || repo == nil {
+		return fmt.Errorf("sub, org or repo is required")
+	}
+	if repo.OwnerID != org.ID {
+		return fmt.Errorf("repoId: %d does not belong to orgId: %d", repo.ID, org.ID)
+	}
+
+	userID := strconv.FormatInt(sub.ID, 10)
+	repoID := strconv.FormatInt(repo.ID, 10)
+	if _, err := enforcer.RemoveFilteredNamedPolicy(repoPolicyType, 0, userID, "", "", repoID); err != nil {
+		log.Error("Error has occurred while removing policies to repoId: %d for userId: %d. Error: %v", repo.ID, sub.ID, err)
+		return fmt.Errorf("remove existing repository policies: %w", err)
+	}
+	if _, err := enforcer.AddNamedPolicy(repoPolicyType, userID, tenantId, strconv.FormatInt(org.ID, 10), repoID, role.String()); err != nil {
+		log.Error("Error has occurred while adding %v policy to repoId: %d for userId: %d under tenantId: %v. Error: %v", role.String(), repo.ID, sub.ID, tenantId, err)
+		return fmt.Errorf("add repository policy: %w", err)
+	}
+
+	log.Debug("%v policy to repoId: %d for userId: %d under tenantId: %v successful granted", role.String(), repo.ID, sub.ID, tenantId)
+	return nil
+}
+
+// RevokeUserRepositoryPolicyTx снимает политику роли пользователя на репозиторий. Участники репозитория не изменяются
+func RevokeUserRepositoryPolicyTx(enforcer casbin.IEnforcer, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, role Role) error {
+	if sub == nil || org == nil || repo == nil {
+		return fmt.Errorf("sub, org or repo is required")
+	}
+	if _, err := enforcer.RemoveNamedPolicy(repoPolicyType, strconv.FormatInt(sub.ID, 10), tenantId, strconv.FormatInt(org.ID, 10), strconv.FormatInt(repo.ID, 10), role.String()); err != nil {
+		log.Error("Error has occurred while removing %v policy to repoId: %d for userId: %d under tenantId: %v. Error: %v", role.String(), repo.ID, sub.ID, tenantId, err)
+		return fmt.Errorf("remove repository policy: %w", err)
+	}
+
+	log.Debug("%v policy to repoId: %d for userId: %d under tenantId: %v successful revoked", role.String(), repo.ID, sub.ID, tenantId)
+	return nil
+}
+
+// GetRepoPrivilegesByRepoID возвращает все роли, назначенные на репозиторий
+func GetRepoPrivilegesByRepoID(repoID int64) ([]RepoPrivilege, error) {
+	policies, err := securityEnforcer.GetFilteredNamedPolicy(repoPolicyType, 3, strconv.FormatInt(repoID, 10))
+	if err != nil {
+		log.Error("Error has occurred while loading repository policies for repoId: %d. Error: %v", repoID, err)
+		return nil, fmt.Errorf("get filtered named policy: %w", err)
+	}
+	return convertStringToRepoPrivilegeArray(policies)
+}
+
+// GetRepoRoleForUser возвращает роль пользователя, назначенную непосредственно на репозиторий. Второе значение false, если такой роли нет
+func GetRepoRoleForUser(userID int64, tenantID string, orgID, repoID int64) (Role, bool, error) {
+	policies, err := securityEnforcer.GetFilteredNamedPolicy(repoPolicyType, 0,
+		strconv.FormatInt(userID, 10), tenantID, strconv.FormatInt(orgID, 10), strconv.FormatInt(repoID, 10))
+	if err != nil {
+		log.Error("Error has occurred while loading repository policies for userId: %d, repoId: %d. Error: %v", userID, repoID, err)
+		return 0, false, fmt.Errorf("get filtered named policy: %w", err)
+	}
+	privileges, err := convertStringToRepoPrivilegeArray(policies)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(privileges) == 0 {
+		return 0, false, nil
+	}
+	return privileges[0].Role, true, nil
+}
+
+// CheckUserPermissionToRepository проверяет доступ пользователя к репозиторию проекта под тенантом.
+// Роль, назначенная на репозиторий, имеет приоритет над ролью в проекте; при ее отсутствии проверяется роль в проекте
+func CheckUserPermissionToRepository(ctx context.Context, sub *user_model.User, tenantId string, org *organization.Organization, repo *repo_model.Repository, action Action) (bool, error) {
+	if sub == nil || org == nil || repo == nil {
+		return false, fmt.Errorf("invalid input: user, organization or repository is nil")
+	}
+
+	return decideUserPermissionToRepository(ctx, &permissionDecision{}, sub, tenantId, org, repo, action)
+}
+
+// RemoveRepoPrivilegesByRepoID удаляет все роли, назначенные на репозиторий. Записи БД удаляются в транзакции ctx
+func RemoveRepoPrivilegesByRepoID(ctx context.Context, repoID int64) error {
+	if _, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID}).Delete(new(ScRepoPrivilegeCollaboration)); err != nil {
+		log.Error("Error has occurred while removing repository privilege collaborations for repoId: %d. Error: %v", repoID, err)
+		return fmt.Errorf("delete repository privilege collaborations: %w", err)
+	}
+	if _, err := securityEnforcer.RemoveFilteredNamedPolicy(repoPolicyType, 3, strconv.FormatInt(repoID, 10)); err != nil {
+		log.Error("Error has occurred while removing repository policies for repoId: %d. Error: %v", repoID, err)
+		return fmt.Errorf("remove filtered named policy: %w", err)
+	}
+	if err := securityEnforcer.SavePolicy(); err != nil {
+		log.Error("Error has occurred while saving policies. Error: %v", err)
+		return fmt.Errorf("save policy: %w", err)
+	}
+	return nil
+}
+
+// removeRepoPrivilegesByOrgID удаляет все роли, назначенные на репозитории проекта
+func removeRepoPrivilegesByOrgID(orgID int64) error {
+	if _, err := securityEnforcer.RemoveFilteredNamedPolicy(repoPolicyType, 2, strconv.FormatInt(orgID, 10)); err != nil {
+		log.Error("Error has occurred while removing repository policies for orgId: %d. Error: %v", orgID, err)
+		return fmt.Errorf("remove filtered named policy: %w", err)
+	}
+	if err := securityEnforcer.SavePolicy(); err != nil {
+		log.Error("Error has occurred while saving policies. Error: %v", err)
+		return fmt.Errorf("save policy: %w", err)
+	}
+	return nil
+}
+
+// repoCollaborationMode возвращает уровень доступа участника репозитория для роли:
+// роль с действием own получает права администратора, с действием write - права на запись, остальные роли - права на чтение
+func repoCollaborationMode(enforcer casbin.IEnforcer, role Role) (perm.AccessMode, error) {
+	actions, err := enforcer.GetImplicitRolesForUser(role.String())
+	if err != nil {
+		return perm.AccessModeNone, err
+	}
+	mode := perm.AccessModeRead
+	for _, action := range actions {
+		switch action {
+		case OWN.String():
+			return perm.AccessModeAdmin, nil
+		case WRITE.String():
+			mode = perm.AccessModeWrite
+		}
+	}
+	return mode, nil
+}
+
+// addRepoCollaborator добавляет пользователя в участники репозитория, чтобы репозиторий был доступен ему без членства в проекте.
+// Уровень доступа ранее добавленного участника изменяется, только если участие было создано при назначении роли на репозиторий
+func addRepoCollaborator(ctx context.Context, repo *repo_model.Repository, userID int64, mode perm.AccessMode) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		collaboration := &repo_model.Collaboration{
+			RepoID: repo.ID,
+			UserID: userID,
+		}
+		has, err := db.GetByBean(ctx, collaboration)
+		if err != nil {
+			return err
+		}
+		if has {
+			owned, err := db.GetEngine(ctx).Exist(&ScRepoPrivilegeCollaboration{RepoID: repo.ID, UserID: userID})
+			if err != nil || !owned || collaboration.Mode == mode {
+				return err
+			}
+			collaboration.Mode = mode
+			if _, err = db.GetEngine(ctx).ID(collaboration.ID).Cols("mode").Update(collaboration); err != nil {
+				return err
+			}
+			return access_model.RecalculateUserAccess(ctx, repo, userID)
+		}
+
+		collaboration.Mode = mode
+		if err = db.Insert(ctx, collaboration); err != nil {
+			return err
+		}
+		if err = db.Insert(ctx, &ScRepoPrivilegeCollaboration{RepoID: repo.ID, UserID: userID}); err != nil {
+			return err
+		}
+		return access_model.RecalculateUserAccess(ctx, repo, userID)
+	})
+}
+
+// removeRepoCollaborator удаляет участие пользователя в репозитории, если оно было создано при назначении роли на репозиторий
+func removeRepoCollaborator(ctx context.Context, repo *repo_model.Repository, userID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		deleted, err := db.GetEngine(ctx).Delete(&ScRepoPrivilegeCollaboration{RepoID: repo.ID, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			log.Debug("Collaboration of userId: %d in repoId: %d was not created by repository privilege, keeping it", userID, repo.ID)
+			return nil
+		}
+		return models.DeleteCollaboration(ctx, repo, userID)
+	})
+}
@@ -0,0 +1,71 @@
+//go:build !correct
+
+package role_model
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/perm"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConvertStringToRepoPrivilegeArray проверяет разбор политик casbin с ролями на репозитории
+func TestConvertStringToRepoPrivilegeArray(t *testing.T) {
+	privileges, err := convertStringToRepoPrivilegeArray([][]string{
+		{"1", "tenant", "2", "3", "writer"},
+		{"1", "tenant", "2", "writer"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []RepoPrivilege{{UserID: 1, TenantID: "tenant", OrgID: 2, RepoID: 3, Role: WRITER}}, privileges)
+
+	_, err = convertStringToRepoPrivilegeArray([][]string{{"1", "tenant", "2", "repo", "writer"}})
+	assert.Error(t, err)
+
+	_, err = convertStringToRepoPrivilegeArray([][]string{{"1", "tenant", "2", "3", "unknown"}})
+	assert.Error(t, err)
+}
+
+// TestRepoCollaborationMode проверяет уровень доступа участника репозитория в зависимости от действий роли
+func TestRepoCollaborationMode(t *testing.T) {
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, act
+[policy_definition]
+p = sub, act
+[role_definition]
+g = _, _
+[policy_effect]
+e = some(where (p.eft == allow))
+[matchers]
+m = g(r.sub, p.sub) && r.act == p.act
+`)
+	require.NoError(t, err)
+	enforcer, err := casbin.NewEnforcer(m)
+	require.NoError(t, err)
+	for _, policy := range [][]string{
+		{"owner", "own"},
+		{"owner", "write"},
+		{"writer", "write"},
+		{"writer", "read"},
+		{"reader", "read"},
+		{"tuz", "owner"},
+	} {
+		_, err = enforcer.AddGroupingPolicy(policy[0], policy[1])
+		require.NoError(t, err)
+	}
+
+	for role, expected := range map[Role]perm.AccessMode{
+		OWNER:  perm.AccessModeAdmin,
+		TUZ:    perm.AccessModeAdmin,
+		WRITER: perm.AccessModeWrite,
+		READER: perm.AccessModeRead,
+	} {
+		mode, err := repoCollaborationMode(enforcer, role)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode, role.String())
+	}
+}
@@ -0,0 +1,468 @@
+package role_model
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v2"
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// roleNameRegex регулярное выражение для проверки имени роли
+var roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+// ScRole - структура таблицы sc_role, описание роли ролевой модели.
+// Числовое значение роли Role совпадает с идентификатором записи
+type ScRole struct {
+	ID           int64              `xorm:"pk autoincr"`
+	Name         string             `xorm:"VARCHAR(100) UNIQUE NOT NULL"`
+	DisplayName  string             `xorm:"VARCHAR(255)"`
+	Actions      string             `xorm:"TEXT"`                   // действия роли через запятую
+	Parent       string             `xorm:"VARCHAR(100)"`           // роль, действия которой наследуются
+	IsSystem     bool               `xorm:"NOT NULL DEFAULT false"` // встроенная роль, не может быть удалена
+	IsAssignable bool               `xorm:"NOT NULL DEFAULT true"`  // роль доступна для назначения пользователям в проекте
+	Created      timeutil.TimeStamp `xorm:"created"`
+	Updated      timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ScRoleRevision структура таблицы sc_role_revision, счетчик изменений описаний ролей. Увеличивается в транзакции
+// каждого изменения sc_role, по нему другие экземпляры приложения определяют, что роли нужно перезагрузить
+type ScRoleRevision struct {
+	ID       int64 `xorm:"pk"`
+	Revision int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// roleRevisionID идентификатор единственной записи таблицы sc_role_revision
+const roleRevisionID = 1
+
+func init() {
+	db.RegisterModel(new(ScRole))
+	db.RegisterModel(new(ScRoleRevision))
+}
+
+// Role возвращает значение роли, соответствующее описанию
+func (r *ScRole) Role() Role {
+	return Role(r.ID)
+}
+
+// GetActions возвращает список действий роли
+func (r *ScRole) GetActions() []string {
+	actions := make([]string, 0, 8)
+	for _, action := range strings.Split(r.Actions, ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// SetActions устанавливает список действий роли
+func (r *ScRole) SetActions(actions []string) {
+	r.Actions = strings.Join(actions, ",")
+}
+
+// defaultRoleDefinitions возвращает описания встроенных ролей, используются при отсутствии записей в таблице sc_role
+func defaultRoleDefinitions() []*ScRole {
+	return []*ScRole{
+		{
+			ID:           int64(OWNER),
+			Name:         "owner",
+			DisplayName:  "Владелец проекта",
+			Actions:      "own,create,edit,edit_project,read,read_private,write,delete,merge_without_check,manage_comments",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			ID:           int64(MANAGER),
+			Name:         "manager",
+			DisplayName:  "Менеджер",
+			Actions:      "read_private,create,edit,delete,write,read",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			ID:           int64(WRITER),
+			Name:         "writer",
+			DisplayName:  "Пользователь с правами на запись",
+			Actions:      "read,write",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			ID:           int64(READER),
+			Name:         "reader",
+			DisplayName:  "Пользователь с правами на чтение",
+			Actions:      "read",
+			IsSystem:     true,
+			IsAssignable: true,
+		},
+		{
+			ID:           int64(TUZ),
+			Name:         "tuz",
+			DisplayName:  "Технологическая учетная запись",
+			Parent:       "owner",
+			IsSystem:     true,
+			IsAssignable: false,
+		},
+	}
+}
+
+// GetAllRoleDefinitions возвращает все описания ролей
+func GetAllRoleDefinitions(ctx context.Context) ([]*ScRole, error) {
+	roles := make([]*ScRole, 0, 8)
+	if err := db.GetEngine(ctx).Asc("id").Find(&roles); err != nil {
+		return nil, fmt.Errorf("error has occurred while getting role definitions: %w", err)
+	}
+	return roles, nil
+}
+
+// GetRoleDefinitionByName возвращает описание роли по имени
+func GetRoleDefinitionByName(ctx context.Context, name string) (*ScRole, error) {
+	role := new(ScRole)
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"name": name}).Get(role)
+	if err != nil {
+		return nil, fmt.Errorf("error has occurred while getting role definition: %w", err)
+	}
+	if !has {
+		return nil, &ErrRoleDefinitionNotExist{Name: name}
+	}
+	return role, nil
+}
+
+// CreateRoleDefinition создает роль и добавляет ее действия в ролевую модель.
+// Описание роли сохраняется в транзакции, политики casbin синхронизируются после ее фиксации.
+// При сбое синхронизации политики будут восстановлены по описанию роли при следующей перезагрузке ролевой модели
+func CreateRoleDefinition(ctx context.Context, role *ScRole) error {
+	if err := validateRoleDefinition(role); err != nil {
+		return err
+	}
+	if _, ok := GetRoleByString(role.Name); ok {
+		return &ErrRoleDefinitionAlreadyExist{Name: role.Name}
+	}
+
+	role.IsSystem = false
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := GetRoleDefinitionByName(ctx, role.Name); err == nil {
+			return &ErrRoleDefinitionAlreadyExist{Name: role.Name}
+		} else if !IsErrRoleDefinitionNotExist(err) {
+			return err
+		}
+		if _, err := db.GetEngine(ctx).Insert(role); err != nil {
+			return fmt.Errorf("error has occurred while adding role definition: %w", err)
+		}
+		return incrementRoleDefinitionsRevision(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	applyRoleDefinition(role)
+	return syncRoleDefinitionPolicies(securityEnforcer, role)
+}
+
+// UpdateRoleDefinition изменяет отображаемое имя, действия и доступность роли для назначения. Встроенные роли не изменяются
+func UpdateRoleDefinition(ctx context.Context, role *ScRole) error {
+	if err := validateRoleDefinition(role); err != nil {
+		return err
+	}
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		existing, err := getRoleDefinitionByID(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+		if existing.IsSystem {
+			return &ErrRoleDefinitionInvalid{Name: existing.Name, Reason: "system role can not be changed"}
+		}
+		role.Name = existing.Name
+		role.IsSystem = false
+		if _, err = db.GetEngine(ctx).ID(role.ID).Cols("display_name", "actions", "is_assignable").Update(role); err != nil {
+			return fmt.Errorf("error has occurred while updating role definition: %w", err)
+		}
+		return incrementRoleDefinitionsRevision(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	applyRoleDefinition(role)
+	return syncRoleDefinitionPolicies(securityEnforcer, role)
+}
+
+// DeleteRoleDefinition удаляет роль. Встроенные и назначенные пользователям роли не удаляются.
+// Назначение роли проверяется в транзакции удаления, чтобы роль не была назначена между проверкой и удалением
+func DeleteRoleDefinition(ctx context.Context, role *ScRole) error {
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		existing, err := getRoleDefinitionByID(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+		if existing.IsSystem {
+			return &ErrRoleDefinitionInvalid{Name: existing.Name, Reason: "system role can not be deleted"}
+		}
+		assigned, err := securityEnforcer.GetFilteredPolicy(3, role.Name)
+		if err != nil {
+			return fmt.Errorf("get filtered policy: %w", err)
+		}
+		assignedToRepos, err := securityEnforcer.GetFilteredNamedPolicy(repoPolicyType, 4, role.Name)
+		if err != nil {
+			return fmt.Errorf("get filtered named policy: %w", err)
+		}
+		if len(assigned) > 0 || len(assignedToRepos) > 0 {
+			return &ErrRoleDefinitionInUse{Name: role.Name}
+		}
+		if _, err = db.GetEngine(ctx).ID(role.ID).Delete(new(ScRole)); err != nil {
+			return fmt.Errorf("error has occurred while deleting role definition: %w", err)
+		}
+		return incrementRoleDefinitionsRevision(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	DeleteRole(role.Role())
+	deleteUserRole(role.Role())
+
+	if _, err = securityEnforcer.RemoveFilteredGroupingPolicy(0, role.Name); err != nil {
+		log.Error("Error has occurred while removing grouping policies for role: %v. Error: %v", role.Name, err)
+		return fmt.Errorf("remove grouping policy: %w", err)
+	}
+	if err = securityEnforcer.SavePolicy(); err != nil {
+		log.Error("Error has occurred while saving policy. Error: %v", err)
+		return fmt.Errorf("save policy: %w", err)
+	}
+	return nil
+}
+
+var (
+	loadedRevisionMu sync.Mutex
+	loadedRevision   int64
+)
+
+// incrementRoleDefinitionsRevision увеличивает счетчик изменений описаний ролей в транзакции ctx
+func incrementRoleDefinitionsRevision(ctx context.Context) error {
+	updated, err := db.GetEngine(ctx).ID(roleRevisionID).Incr("revision").Update(new(ScRoleRevision))
+	if err != nil {
+		return fmt.Errorf("error has occurred while incrementing role definitions revision: %w", err)
+	}
+	if updated > 0 {
+		return nil
+	}
+	if _, err = db.GetEngine(ctx).Insert(&ScRoleRevision{ID: roleRevisionID, Revision: 1}); err != nil {
+		return fmt.Errorf("error has occurred while adding role definitions revision: %w", err)
+	}
+	return nil
+}
+
+// getRoleDefinitionsRevision возвращает текущее значение счетчика изменений описаний ролей, 0 если роли не изменялись
+func getRoleDefinitionsRevision(ctx context.Context) (int64, error) {
+	revision := new(ScRoleRevision)
+	has, err := db.GetEngine(ctx).ID(roleRevisionID).Get(revision)
+	if err != nil {
+		return 0, fmt.Errorf("error has occurred while getting role definitions revision: %w", err)
+	}
+	if !has {
+		return 0, nil
+	}
+	return revision.Revision, nil
+}
+
+// ReloadRoleDefinitions перезагружает политики casbin и описания ролей из БД, если роли были изменены, в том числе на другом экземпляре приложения.
+// Политики наследования ролей, не совпадающие с описанием роли, пересоздаются
+func ReloadRoleDefinitions(ctx context.Context) error {
+	revision, err := getRoleDefinitionsRevision(ctx)
+	if err != nil {
+		return err
+	}
+	loadedRevisionMu.Lock()
+	defer loadedRevisionMu.Unlock()
+	if revision == loadedRevision {
+		return nil
+	}
+
+	log.Info("Role definitions have changed, reloading role model")
+	if err = securityEnforcer.LoadPolicy(); err != nil {
+		log.Error("Error has occurred while loading policy. Error: %v", err)
+		return fmt.Errorf("load policy: %w", err)
+	}
+	roles, err := loadRoleDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		inSync, err := roleDefinitionPoliciesInSync(securityEnforcer, role)
+		if err != nil {
+			return err
+		}
+		if inSync {
+			continue
+		}
+		log.Warn("Grouping policies of role: %v do not match its definition, recreating them", role.Name)
+		if err = syncRoleDefinitionPolicies(securityEnforcer, role); err != nil {
+			return err
+		}
+	}
+	loadedRevision = revision
+	return nil
+}
+
+// roleDefinitionPoliciesInSync проверяет, что политики наследования роли (g) совпадают с действиями и родительской ролью из описания
+func roleDefinitionPoliciesInSync(enforcer casbin.IEnforcer, role *ScRole) (bool, error) {
+	policies, err := enforcer.GetFilteredGroupingPolicy(0, role.Name)
+	if err != nil {
+		return false, fmt.Errorf("get filtered grouping policy: %w", err)
+	}
+	expected := make(map[string]struct{}, len(policies))
+	for _, action := range role.GetActions() {
+		expected[action] = struct{}{}
+	}
+	if role.Parent != "" {
+		expected[role.Parent] = struct{}{}
+	}
+	if len(policies) != len(expected) {
+		return false, nil
+	}
+	for _, policy := range policies {
+		if _, ok := expected[policy[1]]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getRoleDefinitionByID возвращает описание роли по идентификатору
+func getRoleDefinitionByID(ctx context.Context, id int64) (*ScRole, error) {
+	role := new(ScRole)
+	has, err := db.GetEngine(ctx).ID(id).Get(role)
+	if err != nil {
+		return nil, fmt.Errorf("error has occurred while getting role definition: %w", err)
+	}
+	if !has {
+		return nil, &ErrRoleDefinitionNotExist{Name: strconv.FormatInt(id, 10)}
+	}
+	return role, nil
+}
+
+// validateRoleDefinition проверяет имя и действия роли
+func validateRoleDefinition(role *ScRole) error {
+	if !roleNameRegex.MatchString(role.Name) {
+		return &ErrRoleDefinitionInvalid{Name: role.Name, Reason: "name must match " + roleNameRegex.String()}
+	}
+	if _, ok := GetActionByString(role.Name); ok || role.Name == InnerSource {
+		return &ErrRoleDefinitionInvalid{Name: role.Name, Reason: "name is reserved"}
+	}
+	actions := role.GetActions()
+	if len(actions) == 0 && role.Parent == "" {
+		return &ErrRoleDefinitionInvalid{Name: role.Name, Reason: "at least one action is required"}
+	}
+	seen := make(map[string]struct{}, len(actions))
+	for _, action := range actions {
+		if _, ok := GetActionByString(action); !ok {
+			return &ErrRoleDefinitionInvalid{Name: role.Name, Reason: fmt.Sprintf("action %s does not exist", action)}
+		}
+		if _, ok := seen[action]; ok {
+			return &ErrRoleDefinitionInvalid{Name: role.Name, Reason: fmt.Sprintf("action %s is duplicated", action)}
+		}
+		seen[action] = struct{}{}
+	}
+	return nil
+}
+
+// loadRoleDefinitions загружает описания ролей из БД, при отсутствии записей используются встроенные роли
+func loadRoleDefinitions(ctx context.Context) ([]*ScRole, error) {
+	roles, err := GetAllRoleDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		log.Warn("Role definitions are not found in database, default roles are used")
+		roles = defaultRoleDefinitions()
+	}
+
+	// справочники собираются заново и подменяются целиком, чтобы во время перезагрузки роли не пропадали
+	loadedRoles := make(map[Role]string, len(roles))
+	loadedUserRoles := make(map[Role]string, len(roles))
+	loadedUserRoleNames := make(map[Role]string, len(roles))
+	for _, role := range roles {
+		loadedRoles[role.Role()] = role.Name
+		if role.IsAssignable {
+			loadedUserRoles[role.Role()] = role.Name
+			loadedUserRoleNames[role.Role()] = role.DisplayName
+		}
+	}
+
+	allRolesMu.Lock()
+	userRolesMu.Lock()
+	userRoleNamesMu.Lock()
+	allRoles, userRoles, userRoleNames = loadedRoles, loadedUserRoles, loadedUserRoleNames
+	userRoleNamesMu.Unlock()
+	userRolesMu.Unlock()
+	allRolesMu.Unlock()
+	return roles, nil
+}
+
+// applyRoleDefinition добавляет роль в справочники ролей
+func applyRoleDefinition(role *ScRole) {
+	allRolesMu.Lock()
+	allRoles[role.Role()] = role.Name
+	allRolesMu.Unlock()
+
+	if !role.IsAssignable {
+		deleteUserRole(role.Role())
+		return
+	}
+	userRolesMu.Lock()
+	userRoles[role.Role()] = role.Name
+	userRolesMu.Unlock()
+
+	userRoleNamesMu.Lock()
+	userRoleNames[role.Role()] = role.DisplayName
+	userRoleNamesMu.Unlock()
+}
+
+// deleteUserRole удаляет роль из справочников ролей, доступных для назначения
+func deleteUserRole(r Role) {
+	userRolesMu.Lock()
+	delete(userRoles, r)
+	userRolesMu.Unlock()
+
+	userRoleNamesMu.Lock()
+	delete(userRoleNames, r)
+	userRoleNamesMu.Unlock()
+}
+
+// syncRoleDefinitionPolicies пересоздает политики наследования роли (g) по ее описанию и сохраняет политики
+func syncRoleDefinitionPolicies(enforcer casbin.IEnforcer, role *ScRole) error {
+	if err := addRoleDefinitionPolicies(enforcer, role); err != nil {
+		return err
+	}
+	if err := enforcer.SavePolicy(); err != nil {
+		log.Error("Error has occurred while saving policy. Error: %v", err)
+		return fmt.Errorf("save policy: %w", err)
+	}
+	return nil
+}
+
+// addRoleDefinitionPolicies заменяет политики наследования роли (g) на действия и родительскую роль из описания
+func addRoleDefinitionPolicies(enforcer casbin.IEnforcer, role *ScRole) error {
+	if _, err := enforcer.RemoveFilteredGroupingPolicy(0, role.Name); err != nil {
+		log.Error("Error has occurred while removing grouping policies for role: %v. Error: %v", role.Name, err)
+		return fmt.Errorf("remove grouping policy: %w", err)
+	}
+
+	inherited := role.GetActions()
+	if role.Parent != "" {
+		inherited = append(inherited, role.Parent)
+	}
+	for _, name := range inherited {
+		if _, err := enforcer.AddGroupingPolicy(role.Name, name); err != nil {
+			log.Error("Error has occurred while adding grouping policy with action: %v for role: %v. Error: %v", name, role.Name, err)
+			return fmt.Errorf("add grouping policy: %w", err)
+		}
+	}
+	return nil
+}
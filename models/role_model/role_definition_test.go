@@ -0,0 +1,77 @@
+//go:build !correct
+
+package role_model
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateRoleDefinition проверяет валидацию имени и действий роли
+func TestValidateRoleDefinition(t *testing.T) {
+	assert.NoError(t, validateRoleDefinition(&ScRole{Name: "auditor", Actions: "read,read_private"}))
+	assert.NoError(t, validateRoleDefinition(&ScRole{Name: "tuz", Parent: "owner"}))
+
+	for _, role := range []*ScRole{
+		{Name: "Auditor", Actions: "read"},
+		{Name: "1auditor", Actions: "read"},
+		{Name: "read", Actions: "read"},
+		{Name: "auditor"},
+		{Name: "auditor", Actions: "read,unknown"},
+		{Name: "auditor", Actions: "read,read"},
+	} {
+		err := validateRoleDefinition(role)
+		assert.True(t, IsErrRoleDefinitionInvalid(err), role.Name+": "+role.Actions)
+	}
+}
+
+// TestScRoleActions проверяет преобразование списка действий роли
+func TestScRoleActions(t *testing.T) {
+	role := &ScRole{}
+	role.SetActions([]string{"read", "write"})
+	assert.Equal(t, "read,write", role.Actions)
+
+	role.Actions = " read, ,write "
+	assert.Equal(t, []string{"read", "write"}, role.GetActions())
+}
+
+// TestRoleDefinitionPoliciesInSync проверяет сравнение политик наследования роли с ее описанием
+func TestRoleDefinitionPoliciesInSync(t *testing.T) {
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, act
+[policy_definition]
+p = sub, act
+[role_definition]
+g = _, _
+[policy_effect]
+e = some(where (p.eft == allow))
+[matchers]
+m = g(r.sub, p.sub) && r.act == p.act
+`)
+	require.NoError(t, err)
+	enforcer, err := casbin.NewEnforcer(m)
+	require.NoError(t, err)
+	_, err = enforcer.AddGroupingPolicies([][]string{{"auditor", "read"}, {"auditor", "read_private"}})
+	require.NoError(t, err)
+
+	inSync, err := roleDefinitionPoliciesInSync(enforcer, &ScRole{Name: "auditor", Actions: "read_private,read"})
+	assert.NoError(t, err)
+	assert.True(t, inSync)
+
+	inSync, err = roleDefinitionPoliciesInSync(enforcer, &ScRole{Name: "auditor", Actions: "read"})
+	assert.NoError(t, err)
+	assert.False(t, inSync)
+
+	inSync, err = roleDefinitionPoliciesInSync(enforcer, &ScRole{Name: "auditor", Actions: "read,write"})
+	assert.NoError(t, err)
+	assert.False(t, inSync)
+
+	inSync, err = roleDefinitionPoliciesInSync(enforcer, &ScRole{Name: "tuz", Parent: "owner"})
+	assert.NoError(t, err)
+	assert.False(t, inSync)
+}
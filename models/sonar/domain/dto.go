@@ -1,20 +1,82 @@
 package domain
 
 import (
+	"time"
+
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
+// SonarTokenVault путь к токену Sonar в Vault (sec man)
+type SonarTokenVault struct {
+	StoragePath string `json:"storage_path"`
+	SecretPath  string `json:"secret_path"`
+	TokenKey    string `json:"token_key"`
+	VersionKey  int    `json:"version_key"`
+}
+
+// CreateOrUpdateSonarProjectRequest настройки Sonar репозитория. Токен передается открытым текстом (SonarToken)
+// или путем в Vault (SonarTokenVault). Если не задано ни то, ни другое, при обновлении сохраняется текущий токен.
+// Секрет подписи webhook (SonarWebhookSecret) при обновлении также сохраняется, если не передан
 type CreateOrUpdateSonarProjectRequest struct {
-	SonarServerURL  string
-	SonarToken      string
-	SonarProjectKey string
-	RepoId          int64
-	TenantKey       string
-	Project         *user_model.User
+	SonarServerURL     string
+	SonarToken         string
+	SonarTokenVault    *SonarTokenVault
+	SonarWebhookSecret string
+	SonarProjectKey    string
+	RepoId             int64
+	TenantKey          string
+	Project            *user_model.User
 }
 
+// SonarSettingsResponse настройки Sonar репозитория без токена и секрета подписи webhook
 type SonarSettingsResponse struct {
-	SonarServerURL  string `json:"sonar_server_url" `
-	SonarProjectKey string `json:"sonar_project_key" `
-	SonarToken      string `json:"sonar_token" `
+	SonarServerURL   string           `json:"sonar_server_url" `
+	SonarProjectKey  string           `json:"sonar_project_key" `
+	SonarTokenVault  *SonarTokenVault `json:"sonar_token_vault,omitempty"`
+	HasSonarToken    bool             `json:"has_sonar_token"`
+	HasWebhookSecret bool             `json:"has_webhook_secret"`
+}
+
+// SonarTrendMetrics метрики истории анализов ветки и ключи метрик Sonar, из которых берется значение,
+// в порядке предпочтения: значение по всему коду, затем по новому коду
+var SonarTrendMetrics = struct {
+	Coverage        []string
+	Bugs            []string
+	Vulnerabilities []string
+	Duplications    []string
+}{
+	Coverage:        []string{"coverage", "new_coverage"},
+	Bugs:            []string{"bugs", "new_bugs"},
+	Vulnerabilities: []string{"vulnerabilities", "new_vulnerabilities"},
+	Duplications:    []string{"duplicated_lines_density", "new_duplicated_lines_density"},
+}
+
+// SonarMetricsTrendOptions параметры выборки истории метрик Sonar репозитория
+type SonarMetricsTrendOptions struct {
+	RepoID int64
+	// Branch ветка или номер пулл реквеста в Sonar. Если не задана, возвращается история всех веток
+	Branch string
+	// From и To границы времени анализа включительно, 0 - без ограничения
+	From timeutil.TimeStamp
+	To   timeutil.TimeStamp
+	// Limit количество последних анализов каждой ветки
+	Limit int
+}
+
+// SonarMetricsTrendPoint значения метрик по одному анализу ветки. Метрика не задана, если Sonar ее не передал
+type SonarMetricsTrendPoint struct {
+	AnalysedAt        time.Time `json:"analysed_at"`
+	Revision          string    `json:"revision,omitempty"`
+	QualityGateStatus string    `json:"quality_gate_status,omitempty"`
+	Coverage          *float64  `json:"coverage"`
+	Bugs              *float64  `json:"bugs"`
+	Vulnerabilities   *float64  `json:"vulnerabilities"`
+	Duplications      *float64  `json:"duplications"`
+}
+
+// SonarBranchMetricsTrend история метрик ветки в порядке времени анализа
+type SonarBranchMetricsTrend struct {
+	Branch string                   `json:"branch"`
+	Points []SonarMetricsTrendPoint `json:"points"`
 }
@@ -53,6 +53,36 @@ func (_m *SonarSettingsUsecaser) DeleteSonarSettings(ctx context.Context, repoID
 	return r0
 }
 
+// SonarMetricsTrend provides a mock function with given fields: ctx, opts
+func (_m *SonarSettingsUsecaser) SonarMetricsTrend(ctx context.Context, opts domain.SonarMetricsTrendOptions) ([]domain.SonarBranchMetricsTrend, error) {
+	ret := _m.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SonarMetricsTrend")
+	}
+
+	var r0 []domain.SonarBranchMetricsTrend
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SonarMetricsTrendOptions) ([]domain.SonarBranchMetricsTrend, error)); ok {
+		return rf(ctx, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SonarMetricsTrendOptions) []domain.SonarBranchMetricsTrend); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SonarBranchMetricsTrend)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SonarMetricsTrendOptions) error); ok {
+		r1 = rf(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SonarSettings provides a mock function with given fields: ctx, repoID
 func (_m *SonarSettingsUsecaser) SonarSettings(ctx context.Context, repoID int64) (*repo.ScSonarSettings, error) {
 	ret := _m.Called(ctx, repoID)
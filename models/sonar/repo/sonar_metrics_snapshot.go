@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// deleteSnapshotsBatchSize количество снимков метрик, удаляемых за один запрос
+const deleteSnapshotsBatchSize = 500
+
+// ScSonarMetricsSnapshot снимок метрик проекта Sonar по одному анализу ветки. Значения метрик хранятся
+// в sc_sonar_project_metrics со ссылкой на снимок
+type ScSonarMetricsSnapshot struct {
+	ID                   string             `xorm:"pk uuid"`
+	SonarProjectStatusID string             `xorm:"INDEX NOT NULL"`
+	Branch               string             `xorm:"NOT NULL"`
+	Revision             string             `xorm:"VARCHAR(64)"`
+	QualityGateStatus    string             `xorm:"VARCHAR(20)"`
+	AnalysedAt           timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	Created              timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ScSonarMetricsSnapshot))
+}
+
+// SaveSonarMetricsSnapshot сохраняем снимок анализа вместе со значениями метрик. Новый снимок (без ID) добавляется,
+// не изменяя предыдущие снимки ветки, у сохраненного ранее снимка заменяются значения метрик
+func SaveSonarMetricsSnapshot(ctx context.Context, snapshot *ScSonarMetricsSnapshot, metrics []ScSonarProjectMetrics) error {
+	isNew := snapshot.ID == ""
+	if isNew {
+		snapshot.ID = uuid.NewString()
+	}
+	for idx := range metrics {
+		metrics[idx].SnapshotID = snapshot.ID
+		metrics[idx].SonarProjectStatusID = snapshot.SonarProjectStatusID
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if isNew {
+			if err := db.Insert(ctx, snapshot); err != nil {
+				return fmt.Errorf("insert sonar metrics snapshot: %w", err)
+			}
+		} else if _, err := db.GetEngine(ctx).Delete(&ScSonarProjectMetrics{SnapshotID: snapshot.ID}); err != nil {
+			return fmt.Errorf("delete sonar metrics of snapshot %s: %w", snapshot.ID, err)
+		}
+		if len(metrics) == 0 {
+			return nil
+		}
+		if err := db.Insert(ctx, metrics); err != nil {
+			return fmt.Errorf("insert sonar metrics: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetLatestSonarMetricsSnapshot получаем последний снимок анализа ветки, nil если анализов не было
+func GetLatestSonarMetricsSnapshot(ctx context.Context, sonarProjectStatusID string) (*ScSonarMetricsSnapshot, error) {
+	var snapshot ScSonarMetricsSnapshot
+	has, err := db.GetEngine(ctx).
+		Where(builder.Eq{"sonar_project_status_id": sonarProjectStatusID}).
+		OrderBy("analysed_at DESC, created DESC").
+		Get(&snapshot)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
+
+// GetLatestSonarAnalysedAt получаем время последнего сохраненного анализа ветки проекта Sonar, 0 если анализов не было
+func GetLatestSonarAnalysedAt(ctx context.Context, sonarServer, projectKey, branch string) (timeutil.TimeStamp, error) {
+	var snapshot ScSonarMetricsSnapshot
+	has, err := db.GetEngine(ctx).
+		Join("INNER", "sc_sonar_project_status", "sc_sonar_project_status.id = sc_sonar_metrics_snapshot.sonar_project_status_id").
+		Where(builder.Eq{
+			"sc_sonar_project_status.sonar_server": sonarServer,
+			"sc_sonar_project_status.project_key":  projectKey,
+			"sc_sonar_project_status.branch":       branch,
+		}).
+		OrderBy("sc_sonar_metrics_snapshot.analysed_at DESC").
+		Get(&snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("get latest sonar metrics snapshot of branch %s: %w", branch, err)
+	} else if !has {
+		return 0, nil
+	}
+	return snapshot.AnalysedAt, nil
+}
+
+// DeleteSonarMetricsSnapshotsBefore удаляем снимки анализов старше before вместе со значениями метрик.
+// Последний снимок каждой ветки не удаляется, чтобы не потерять текущие значения метрик
+func DeleteSonarMetricsSnapshotsBefore(ctx context.Context, before timeutil.TimeStamp) (int64, error) {
+	cond := builder.Lt{"analysed_at": before}.And(builder.Expr(
+		"analysed_at < (SELECT MAX(latest.analysed_at) FROM sc_sonar_metrics_snapshot latest " +
+			"WHERE latest.sonar_project_status_id = sc_sonar_metrics_snapshot.sonar_project_status_id)"))
+
+	var deleted int64
+	for {
+		ids := make([]string, 0, deleteSnapshotsBatchSize)
+		if err := db.GetEngine(ctx).Table("sc_sonar_metrics_snapshot").Cols("id").
+			Where(cond).Limit(deleteSnapshotsBatchSize).Find(&ids); err != nil {
+			return deleted, fmt.Errorf("find expired sonar metrics snapshots: %w", err)
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+		err := db.WithTx(ctx, func(ctx context.Context) error {
+			if _, err := db.GetEngine(ctx).In("snapshot_id", ids).Delete(new(ScSonarProjectMetrics)); err != nil {
+				return fmt.Errorf("delete sonar metrics: %w", err)
+			}
+			if _, err := db.GetEngine(ctx).In("id", ids).Delete(new(ScSonarMetricsSnapshot)); err != nil {
+				return fmt.Errorf("delete sonar metrics snapshots: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += int64(len(ids))
+		if len(ids) < deleteSnapshotsBatchSize {
+			return deleted, nil
+		}
+	}
+}
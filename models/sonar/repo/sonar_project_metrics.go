@@ -1,8 +1,6 @@
 package repo
 
 import (
-	"context"
-
 	"xorm.io/builder"
 
 	"code.gitea.io/gitea/models/db"
@@ -13,6 +11,7 @@ import (
 type ScSonarProjectMetrics struct {
 	ID                   string `xorm:"pk uuid"`
 	SonarProjectStatusID string `xorm:"INDEX NOT NULL"`
+	SnapshotID           string `xorm:"INDEX"` // снимок анализа, к которому относится значение метрики
 	Key                  string `xorm:"NOT NULL"`
 	Name                 string
 	Type                 string
@@ -25,31 +24,19 @@ func init() {
 	db.RegisterModel(new(ScSonarProjectMetrics))
 }
 
-// UpsertSonarMetrics обновляем информации о метриках в базе
-func UpsertSonarMetrics(ctx context.Context, listScSonarMetrics []ScSonarProjectMetrics) error {
-	has, err := db.GetEngine(ctx).Get(&ScSonarProjectMetrics{SonarProjectStatusID: listScSonarMetrics[0].SonarProjectStatusID})
+// GetSonarProjectMetrics получаем спиоск метрик из сонара по последнему анализу ветки
+func GetSonarProjectMetrics(sonarProjectID string) ([]ScSonarProjectMetrics, error) {
+	var getSonarMetrics []ScSonarProjectMetrics
+	snapshot, err := GetLatestSonarMetricsSnapshot(db.DefaultContext, sonarProjectID)
 	if err != nil {
-		return err
-	}
-	if has {
-		_, errDelete := db.GetEngine(ctx).Delete(&ScSonarProjectMetrics{SonarProjectStatusID: listScSonarMetrics[0].SonarProjectStatusID})
-		if errDelete != nil {
-			log.Error("UpsertSonarMetrics failed while deleting notes in table sc_sonar_project_metrics: %v", errDelete)
-			return errDelete
-		}
+		log.Error("GetSonarProjectMetrics failed while getting latest snapshot of sonar_project_status_id %v: %v", sonarProjectID, err)
+		return nil, err
 	}
-	if err := db.Insert(ctx, listScSonarMetrics); err != nil {
-		log.Error("UpsertSonarMetrics failed while adding notes in table sc_sonar_project_metrics: %v", err)
-		return err
+	if snapshot == nil {
+		return getSonarMetrics, nil
 	}
-	return nil
-}
-
-// GetSonarProjectMetrics получаем спиоск метрик из сонара
-func GetSonarProjectMetrics(sonarProjectID string) ([]ScSonarProjectMetrics, error) {
-	var getSonarMetrics []ScSonarProjectMetrics
-	err := db.GetEngine(db.DefaultContext).
-		Where(builder.Eq{"sonar_project_status_id": sonarProjectID}).
+	err = db.GetEngine(db.DefaultContext).
+		Where(builder.Eq{"snapshot_id": snapshot.ID}).
 		Find(&getSonarMetrics)
 	if err != nil {
 		log.Error("GetSonarProjectMetrics failed while getting sonar_project_status_id %v: %v", sonarProjectID, err)
@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"xorm.io/builder"
@@ -19,6 +20,7 @@ type SonarSettingsProvider interface {
 	UpdateSonarSettings(ctx context.Context, settings domain.CreateOrUpdateSonarProjectRequest) error
 	DeleteSonarSettings(ctx context.Context, id int64) error
 	SonarSettings(ctx context.Context, id int64) (*domain.SonarSettingsResponse, error)
+	SonarMetricsTrend(ctx context.Context, opts domain.SonarMetricsTrendOptions) ([]domain.SonarBranchMetricsTrend, error)
 }
 
 type dbEngine interface {
@@ -45,19 +47,24 @@ func (s SonarSettings) InsertSonarSettings(ctx context.Context, settings domain.
 	if err != nil {
 		return fmt.Errorf("get sonar settings: %w", err)
 	}
-	if !has {
-		_, err = s.engine.Insert(repo.ScSonarSettings{
-			URL:        settings.SonarServerURL,
-			RepoID:     settings.RepoId,
-			Token:      settings.SonarToken,
-			ProjectKey: settings.SonarProjectKey,
-		})
-		if err != nil {
-			return fmt.Errorf("insert sonar settings: %w", err)
-		}
-	} else {
+	if has {
 		return sonar.ErrSonarSettingsAlreadyExists{SonarProjectKey: settings.SonarProjectKey}
 	}
+
+	newSettings := repo.ScSonarSettings{
+		URL:        settings.SonarServerURL,
+		RepoID:     settings.RepoId,
+		ProjectKey: settings.SonarProjectKey,
+	}
+	if err = applySonarToken(&newSettings, settings); err != nil {
+		return err
+	}
+	if err = newSettings.SetWebhookSecret(settings.SonarWebhookSecret); err != nil {
+		return err
+	}
+	if _, err = s.engine.Insert(newSettings); err != nil {
+		return fmt.Errorf("insert sonar settings: %w", err)
+	}
 	return nil
 }
 
@@ -67,26 +74,38 @@ func (s SonarSettings) UpdateSonarSettings(ctx context.Context, settings domain.
 	if err != nil {
 		return fmt.Errorf("get sonar settings: %w", err)
 	}
+	if !has {
+		return sonar.ErrSonarSettingsNotFound{SonarProjectKey: settings.SonarProjectKey}
+	}
 
 	newSettings := repo.ScSonarSettings{
 		URL:        settings.SonarServerURL,
 		RepoID:     settings.RepoId,
-		Token:      settings.SonarToken,
 		ProjectKey: settings.SonarProjectKey,
 		Updated:    timeutil.TimeStamp(time.Now().Unix()),
 	}
-
-	if !has {
-		return sonar.ErrSonarSettingsNotFound{SonarProjectKey: settings.SonarProjectKey}
-	} else {
-		_, err = s.engine.Where(builder.Eq{"repo_id": settings.RepoId}).Update(newSettings)
-		if err != nil {
-			return fmt.Errorf("update sonar settings: %w", err)
+	cols := []string{"url", "project_key", "updated"}
+	// токен не передан, сохраняем текущий
+	if settings.SonarToken != "" || settings.SonarTokenVault != nil {
+		if err = applySonarToken(&newSettings, settings); err != nil {
+			return err
+		}
+		cols = append(cols, "token_encrypted", "vault_storage_path", "vault_secret_path", "vault_token_key", "vault_version_key")
+	}
+	// секрет подписи webhook не передан, сохраняем текущий
+	if settings.SonarWebhookSecret != "" {
+		if err = newSettings.SetWebhookSecret(settings.SonarWebhookSecret); err != nil {
+			return err
 		}
+		cols = append(cols, "webhook_secret_encrypted")
 	}
 
+	if _, err = s.engine.Where(builder.Eq{"repo_id": settings.RepoId}).Cols(cols...).Update(newSettings); err != nil {
+		return fmt.Errorf("update sonar settings: %w", err)
+	}
 	return nil
 }
+
 func (s SonarSettings) SonarSettings(ctx context.Context, id int64) (*domain.SonarSettingsResponse, error) {
 	var res repo.ScSonarSettings
 	has, err := s.engine.Where(builder.Eq{"repo_id": id}).Get(&res)
@@ -96,12 +115,132 @@ func (s SonarSettings) SonarSettings(ctx context.Context, id int64) (*domain.Son
 	if !has {
 		return nil, sonar.ErrSonarSettingsNotFound{SonarProjectKey: ""}
 	}
-	return &domain.SonarSettingsResponse{
-		SonarServerURL:  res.URL,
-		SonarProjectKey: res.ProjectKey,
-		SonarToken:      res.Token,
-	}, nil
+
+	response := &domain.SonarSettingsResponse{
+		SonarServerURL:   res.URL,
+		SonarProjectKey:  res.ProjectKey,
+		HasSonarToken:    res.TokenEncrypted != "" || res.IsTokenInVault(),
+		HasWebhookSecret: res.HasWebhookSecret(),
+	}
+	if res.IsTokenInVault() {
+		vault := res.TokenVault()
+		response.SonarTokenVault = &domain.SonarTokenVault{
+			StoragePath: vault.StoragePath,
+			SecretPath:  vault.SecretPath,
+			TokenKey:    vault.TokenKey,
+			VersionKey:  vault.VersionKey,
+		}
+	}
+	return response, nil
+}
+
+// SonarMetricsTrend история метрик анализов веток проекта Sonar репозитория, ветки упорядочены по имени,
+// анализы по времени. Для каждой ветки возвращается не больше opts.Limit последних анализов
+func (s SonarSettings) SonarMetricsTrend(ctx context.Context, opts domain.SonarMetricsTrendOptions) ([]domain.SonarBranchMetricsTrend, error) {
+	var settings repo.ScSonarSettings
+	has, err := s.engine.Where(builder.Eq{"repo_id": opts.RepoID}).Get(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("get sonar settings: %w", err)
+	}
+	if !has {
+		return nil, sonar.ErrSonarSettingsNotFound{}
+	}
+
+	statusCond := builder.Eq{"sonar_server": settings.URL, "project_key": settings.ProjectKey}
+	if opts.Branch != "" {
+		statusCond["branch"] = opts.Branch
+	}
+	statuses := make([]ScSonarProjectStatus, 0)
+	if err = s.engine.Where(statusCond).OrderBy("branch").Find(&statuses); err != nil {
+		return nil, fmt.Errorf("get sonar project statuses: %w", err)
+	}
+
+	trends := make([]domain.SonarBranchMetricsTrend, 0, len(statuses))
+	for _, status := range statuses {
+		snapshotCond := builder.NewCond().And(builder.Eq{"sonar_project_status_id": status.ID})
+		if opts.From > 0 {
+			snapshotCond = snapshotCond.And(builder.Gte{"analysed_at": opts.From})
+		}
+		if opts.To > 0 {
+			snapshotCond = snapshotCond.And(builder.Lte{"analysed_at": opts.To})
+		}
+		snapshots := make([]ScSonarMetricsSnapshot, 0, opts.Limit)
+		if err = s.engine.Where(snapshotCond).OrderBy("analysed_at DESC, created DESC").Limit(opts.Limit).Find(&snapshots); err != nil {
+			return nil, fmt.Errorf("get sonar metrics snapshots of branch %s: %w", status.Branch, err)
+		}
+		if len(snapshots) == 0 {
+			continue
+		}
+
+		snapshotIDs := make([]string, len(snapshots))
+		for idx, snapshot := range snapshots {
+			snapshotIDs[idx] = snapshot.ID
+		}
+		metrics := make([]ScSonarProjectMetrics, 0)
+		if err = s.engine.Where(builder.In("snapshot_id", snapshotIDs)).Find(&metrics); err != nil {
+			return nil, fmt.Errorf("get sonar metrics of branch %s: %w", status.Branch, err)
+		}
+		values := make(map[string]map[string]string, len(snapshots))
+		for _, metric := range metrics {
+			if values[metric.SnapshotID] == nil {
+				values[metric.SnapshotID] = make(map[string]string)
+			}
+			values[metric.SnapshotID][metric.Key] = metric.Value
+		}
+
+		trend := domain.SonarBranchMetricsTrend{Branch: status.Branch, Points: make([]domain.SonarMetricsTrendPoint, len(snapshots))}
+		// снимки выбраны от новых к старым, история возвращается в порядке времени анализа
+		for idx, snapshot := range snapshots {
+			snapshotValues := values[snapshot.ID]
+			trend.Points[len(snapshots)-1-idx] = domain.SonarMetricsTrendPoint{
+				AnalysedAt:        snapshot.AnalysedAt.AsTime(),
+				Revision:          snapshot.Revision,
+				QualityGateStatus: snapshot.QualityGateStatus,
+				Coverage:          trendMetricValue(snapshotValues, domain.SonarTrendMetrics.Coverage),
+				Bugs:              trendMetricValue(snapshotValues, domain.SonarTrendMetrics.Bugs),
+				Vulnerabilities:   trendMetricValue(snapshotValues, domain.SonarTrendMetrics.Vulnerabilities),
+				Duplications:      trendMetricValue(snapshotValues, domain.SonarTrendMetrics.Duplications),
+			}
+		}
+		trends = append(trends, trend)
+	}
+	return trends, nil
 }
+
+// trendMetricValue значение первой из метрик keys, сохраненной в снимке, nil если ни одной нет
+func trendMetricValue(values map[string]string, keys []string) *float64 {
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		return &parsed
+	}
+	return nil
+}
+
+// applySonarToken сохраняет в настройки зашифрованный токен или путь к токену в Vault, разрешенный для репозитория
+func applySonarToken(settings *repo.ScSonarSettings, request domain.CreateOrUpdateSonarProjectRequest) error {
+	if request.SonarTokenVault != nil {
+		vault := repo.SonarTokenVault{
+			StoragePath: request.SonarTokenVault.StoragePath,
+			SecretPath:  request.SonarTokenVault.SecretPath,
+			TokenKey:    request.SonarTokenVault.TokenKey,
+			VersionKey:  request.SonarTokenVault.VersionKey,
+		}
+		if err := vault.Validate(request.RepoId); err != nil {
+			return err
+		}
+		settings.SetTokenVault(vault)
+		return nil
+	}
+	return settings.SetToken(request.SonarToken)
+}
+
 func (s SonarSettings) DeleteSonarSettings(ctx context.Context, id int64) error {
 	var existing repo.ScSonarSettings
 	has, err := s.engine.
@@ -0,0 +1,30 @@
+//go:build !correct
+
+package repo
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/sonar/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendMetricValue(t *testing.T) {
+	values := map[string]string{
+		"new_coverage":             "61.5",
+		"coverage":                 "80.2",
+		"new_bugs":                 "3",
+		"duplicated_lines_density": "not a number",
+	}
+
+	coverage := trendMetricValue(values, domain.SonarTrendMetrics.Coverage)
+	if assert.NotNil(t, coverage) {
+		assert.InDelta(t, 80.2, *coverage, 0.001)
+	}
+	bugs := trendMetricValue(values, domain.SonarTrendMetrics.Bugs)
+	if assert.NotNil(t, bugs) {
+		assert.InDelta(t, 3, *bugs, 0.001)
+	}
+	assert.Nil(t, trendMetricValue(values, domain.SonarTrendMetrics.Vulnerabilities))
+	assert.Nil(t, trendMetricValue(values, domain.SonarTrendMetrics.Duplications))
+}
@@ -12,6 +12,7 @@ type SonarSettingsUsecaser interface {
 	UpdateSonarSettings(ctx context.Context, settings domain.CreateOrUpdateSonarProjectRequest) error
 	DeleteSonarSettings(ctx context.Context, repoID int64) error
 	SonarSettings(ctx context.Context, repoID int64) (*domain.SonarSettingsResponse, error)
+	SonarMetricsTrend(ctx context.Context, opts domain.SonarMetricsTrendOptions) ([]domain.SonarBranchMetricsTrend, error)
 }
 
 type Usecase struct {
@@ -32,6 +33,10 @@ func (u *Usecase) SonarSettings(ctx context.Context, repoID int64) (*domain.Sona
 	return u.repo.SonarSettings(ctx, repoID)
 }
 
+func (u *Usecase) SonarMetricsTrend(ctx context.Context, opts domain.SonarMetricsTrendOptions) ([]domain.SonarBranchMetricsTrend, error) {
+	return u.repo.SonarMetricsTrend(ctx, opts)
+}
+
 func (u *Usecase) DeleteSonarSettings(ctx context.Context, repoID int64) error {
 	return u.repo.DeleteSonarSettings(ctx, repoID)
 }
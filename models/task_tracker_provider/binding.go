@@ -0,0 +1,94 @@
+package task_tracker_provider
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Binding))
+}
+
+// Binding структура таблицы task_tracker_provider_binding, выбор трекера задач для тенанта или репозитория.
+// Настройка тенанта хранится с RepoID равным 0. Шаблоны кодов юнитов хранятся в таблице unit_code_pattern
+type Binding struct {
+	ID          int64              `xorm:"pk autoincr"`
+	TenantID    string             `xorm:"VARCHAR(36) UNIQUE(s) NOT NULL"`
+	RepoID      int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Provider    string             `xorm:"VARCHAR(100) NOT NULL"` // имя трекера задач из настроек [sourcecontrol.tasktracker.provider.*]
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName возвращает имя таблицы настроек трекеров задач
+func (Binding) TableName() string {
+	return "task_tracker_provider_binding"
+}
+
+// GetBinding возвращает настройку трекера задач тенанта (repoID равен 0) или репозитория
+func GetBinding(ctx context.Context, tenantID string, repoID int64) (*Binding, bool, error) {
+	binding := new(Binding)
+	has, err := db.GetEngine(ctx).Where("tenant_id = ? AND repo_id = ?", tenantID, repoID).Get(binding)
+	if err != nil {
+		return nil, false, fmt.Errorf("get task tracker binding of tenant %s repo %d: %w", tenantID, repoID, err)
+	}
+	if !has {
+		return nil, false, nil
+	}
+	return binding, true, nil
+}
+
+// GetEffectiveBinding возвращает настройку трекера задач репозитория, а если ее нет, настройку тенанта
+func GetEffectiveBinding(ctx context.Context, tenantID string, repoID int64) (*Binding, bool, error) {
+	if repoID > 0 {
+		binding, has, err := GetBinding(ctx, tenantID, repoID)
+		if err != nil || has {
+			return binding, has, err
+		}
+	}
+	return GetBinding(ctx, tenantID, 0)
+}
+
+// FindBindings возвращает настройки трекеров задач тенанта и его репозиториев
+func FindBindings(ctx context.Context, tenantID string) ([]*Binding, error) {
+	bindings := make([]*Binding, 0, 10)
+	if err := db.GetEngine(ctx).Where("tenant_id = ?", tenantID).OrderBy("repo_id").Find(&bindings); err != nil {
+		return nil, fmt.Errorf("find task tracker bindings of tenant %s: %w", tenantID, err)
+	}
+	return bindings, nil
+}
+
+// SaveBinding создает или изменяет настройку трекера задач тенанта или репозитория
+func SaveBinding(ctx context.Context, binding *Binding) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		existing, has, err := GetBinding(ctx, binding.TenantID, binding.RepoID)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if _, err = db.GetEngine(ctx).Insert(binding); err != nil {
+				return fmt.Errorf("insert task tracker binding: %w", err)
+			}
+			return nil
+		}
+
+		binding.ID = existing.ID
+		binding.CreatedUnix = existing.CreatedUnix
+		if _, err = db.GetEngine(ctx).ID(binding.ID).Cols("provider").Update(binding); err != nil {
+			return fmt.Errorf("update task tracker binding %d: %w", binding.ID, err)
+		}
+		return nil
+	})
+}
+
+// DeleteBinding удаляет настройку трекера задач тенанта или репозитория
+func DeleteBinding(ctx context.Context, tenantID string, repoID int64) (bool, error) {
+	deleted, err := db.GetEngine(ctx).Where("tenant_id = ? AND repo_id = ?", tenantID, repoID).Delete(new(Binding))
+	if err != nil {
+		return false, fmt.Errorf("delete task tracker binding of tenant %s repo %d: %w", tenantID, repoID, err)
+	}
+	return deleted > 0, nil
+}
@@ -126,3 +126,82 @@ func (err ErrTenantKeyNotExists) Unwrap() error {
 func IsTenantKeyNotExists(err error) bool {
 	return errors.As(err, &ErrTenantKeyNotExists{})
 }
+
+// ErrTenantNameAlreadyUsed represents a "ErrTenantNameAlreadyUsed" kind of error
+type ErrTenantNameAlreadyUsed struct {
+	Name string
+}
+
+// Реализация интерфейса error
+func (err ErrTenantNameAlreadyUsed) Error() string {
+	return fmt.Sprintf("Err: tenant name already used [name: %s]", err.Name)
+}
+
+// IsErrTenantNameAlreadyUsed проверяет, является ли ошибка ErrTenantNameAlreadyUsed
+func IsErrTenantNameAlreadyUsed(err error) bool {
+	return errors.As(err, &ErrTenantNameAlreadyUsed{})
+}
+
+// ErrTenantIsDefault represents a "ErrTenantIsDefault" kind of error
+type ErrTenantIsDefault struct {
+	TenantID string
+}
+
+// Реализация интерфейса error
+func (err ErrTenantIsDefault) Error() string {
+	return fmt.Sprintf("Err: operation is not allowed for default tenant [tenant_id: %s]", err.TenantID)
+}
+
+// IsErrTenantIsDefault проверяет, является ли ошибка ErrTenantIsDefault
+func IsErrTenantIsDefault(err error) bool {
+	return errors.As(err, &ErrTenantIsDefault{})
+}
+
+// ErrTenantIsDeleting represents a "ErrTenantIsDeleting" kind of error
+type ErrTenantIsDeleting struct {
+	TenantID string
+}
+
+// Реализация интерфейса error
+func (err ErrTenantIsDeleting) Error() string {
+	return fmt.Sprintf("Err: operation is not allowed for tenant that is being deleted [tenant_id: %s]", err.TenantID)
+}
+
+// IsErrTenantIsDeleting проверяет, является ли ошибка ErrTenantIsDeleting
+func IsErrTenantIsDeleting(err error) bool {
+	return errors.As(err, &ErrTenantIsDeleting{})
+}
+
+// ErrTenantQuotaExceeded represents a "ErrTenantQuotaExceeded" kind of error
+type ErrTenantQuotaExceeded struct {
+	TenantKey string
+	Resource  QuotaResource
+	Limit     int64
+	Usage     int64
+}
+
+// Реализация интерфейса error
+func (err ErrTenantQuotaExceeded) Error() string {
+	return fmt.Sprintf("Err: tenant quota exceeded [tenant_key: %s, resource: %s, limit: %d, usage: %d]", err.TenantKey, err.Resource, err.Limit, err.Usage)
+}
+
+// IsErrTenantQuotaExceeded проверяет, является ли ошибка ErrTenantQuotaExceeded
+func IsErrTenantQuotaExceeded(err error) bool {
+	return errors.As(err, &ErrTenantQuotaExceeded{})
+}
+
+// ErrTenantAlreadyExists represents a "ErrTenantAlreadyExists" kind of error
+type ErrTenantAlreadyExists struct {
+	Name      string
+	TenantKey string
+}
+
+// Реализация интерфейса error
+func (err ErrTenantAlreadyExists) Error() string {
+	return fmt.Sprintf("Err: tenant already exists [name: %s, tenant_key: %s]", err.Name, err.TenantKey)
+}
+
+// IsErrTenantAlreadyExists проверяет, является ли ошибка ErrTenantAlreadyExists
+func IsErrTenantAlreadyExists(err error) bool {
+	return errors.As(err, &ErrTenantAlreadyExists{})
+}
@@ -0,0 +1,134 @@
+package tenant
+
+import (
+	"context"
+
+	"xorm.io/builder"
+
+	"code.gitea.io/gitea/models/db"
+)
+
+// QuotaResource тип ресурса, на который действует квота тенанта
+type QuotaResource string
+
+const (
+	QuotaRepoCount   QuotaResource = "repo_count"
+	QuotaGitSize     QuotaResource = "git_size"
+	QuotaLFSSize     QuotaResource = "lfs_size"
+	QuotaPackageSize QuotaResource = "package_size"
+)
+
+// TenantUsage текущее потребление ресурсов тенанта
+type TenantUsage struct {
+	RepoCount   int64
+	GitSize     int64
+	LFSSize     int64
+	PackageSize int64
+}
+
+// Limit возвращает квоту тенанта для ресурса, 0 означает отсутствие ограничения
+func (t *ScTenant) Limit(resource QuotaResource) int64 {
+	switch resource {
+	case QuotaRepoCount:
+		return t.MaxRepoCount
+	case QuotaGitSize:
+		return t.MaxGitSize
+	case QuotaLFSSize:
+		return t.MaxLFSSize
+	case QuotaPackageSize:
+		return t.MaxPackageSize
+	}
+	return 0
+}
+
+// Value возвращает потребление ресурса
+func (u *TenantUsage) Value(resource QuotaResource) int64 {
+	switch resource {
+	case QuotaRepoCount:
+		return u.RepoCount
+	case QuotaGitSize:
+		return u.GitSize
+	case QuotaLFSSize:
+		return u.LFSSize
+	case QuotaPackageSize:
+		return u.PackageSize
+	}
+	return 0
+}
+
+// tenantOrganizationIDsCond подзапрос идентификаторов проектов тенанта
+func tenantOrganizationIDsCond(tenantID string) *builder.Builder {
+	return builder.Select("organization_id").
+		From("sc_tenant_organizations").
+		Where(builder.Eq{"tenant_id": tenantID})
+}
+
+// GetTenantUsage подсчет потребления ресурсов всеми проектами тенанта
+func GetTenantUsage(ctx context.Context, tenantID string) (*TenantUsage, error) {
+	usage := new(TenantUsage)
+	e := db.GetEngine(ctx)
+	ownerCond := builder.In("owner_id", tenantOrganizationIDsCond(tenantID))
+
+	var err error
+	if usage.RepoCount, err = e.Table("repository").Where(ownerCond).Count(); err != nil {
+		return nil, err
+	}
+	if usage.GitSize, err = e.Table("repository").Where(ownerCond).SumInt(new(struct{ Size int64 }), "size"); err != nil {
+		return nil, err
+	}
+	if usage.LFSSize, err = e.Table("lfs_meta_object").
+		Where(builder.In("repository_id", builder.Select("id").From("repository").Where(ownerCond))).
+		SumInt(new(struct{ Size int64 }), "size"); err != nil {
+		return nil, err
+	}
+	if usage.PackageSize, err = e.Table("package_file").
+		Join("INNER", "package_blob", "package_blob.id = package_file.blob_id").
+		Where(builder.In("package_file.version_id", builder.
+			Select("package_version.id").
+			From("package_version").
+			InnerJoin("package", "package.id = package_version.package_id").
+			Where(builder.In("package.owner_id", tenantOrganizationIDsCond(tenantID))))).
+		SumInt(new(struct{ Size int64 }), "package_blob.size"); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// UpdateTenantQuotas обновление квот тенанта
+func UpdateTenantQuotas(ctx context.Context, tenant *ScTenant) error {
+	return UpdateTenantCols(ctx, tenant, "max_repo_count", "max_git_size", "max_lfs_size", "max_package_size")
+}
+
+// CheckTenantQuotaByOrgID проверяет, что после добавления delta единиц ресурса проект не превысит квоту своего тенанта.
+// Для проектов без тенанта и для ресурсов без квоты проверка всегда успешна
+func CheckTenantQuotaByOrgID(ctx context.Context, organizationID int64, resource QuotaResource, delta int64) error {
+	tenantOrganization, err := GetTenantOrganizationsByOrgId(ctx, organizationID)
+	if err != nil {
+		if IsErrTenantOrganizationNotExists(err) {
+			return nil
+		}
+		return err
+	}
+	tenant, err := GetTenantByID(ctx, tenantOrganization.TenantID)
+	if err != nil {
+		return err
+	}
+	limit := tenant.Limit(resource)
+	if limit <= 0 {
+		return nil
+	}
+
+	usage, err := GetTenantUsage(ctx, tenant.ID)
+	if err != nil {
+		return err
+	}
+	if usage.Value(resource)+delta > limit {
+		return ErrTenantQuotaExceeded{
+			TenantKey: tenant.OrgKey,
+			Resource:  resource,
+			Limit:     limit,
+			Usage:     usage.Value(resource),
+		}
+	}
+	return nil
+}
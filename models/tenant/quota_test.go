@@ -0,0 +1,83 @@
+//go:build !correct
+
+package tenant
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTenantQuotaLimit тестируем получение квот и потребления по типу ресурса
+func TestTenantQuotaLimit(t *testing.T) {
+	tenant := &ScTenant{MaxRepoCount: 1, MaxGitSize: 2, MaxLFSSize: 3, MaxPackageSize: 4}
+	usage := &TenantUsage{RepoCount: 5, GitSize: 6, LFSSize: 7, PackageSize: 8}
+
+	assert.EqualValues(t, 1, tenant.Limit(QuotaRepoCount))
+	assert.EqualValues(t, 2, tenant.Limit(QuotaGitSize))
+	assert.EqualValues(t, 3, tenant.Limit(QuotaLFSSize))
+	assert.EqualValues(t, 4, tenant.Limit(QuotaPackageSize))
+	assert.EqualValues(t, 5, usage.Value(QuotaRepoCount))
+	assert.EqualValues(t, 6, usage.Value(QuotaGitSize))
+	assert.EqualValues(t, 7, usage.Value(QuotaLFSSize))
+	assert.EqualValues(t, 8, usage.Value(QuotaPackageSize))
+}
+
+// TestCheckTenantQuotaByOrgID тестируем проверку квоты для проектов без тенанта, без ограничений, на границе квоты и при ее превышении
+func TestCheckTenantQuotaByOrgID(t *testing.T) {
+	assert.NoError(t, CheckTenantQuotaByOrgID(db.DefaultContext, 100500, QuotaRepoCount, 1))
+
+	tenant := &ScTenant{
+		ID:        uuid.NewString(),
+		Name:      "quota",
+		OrgKey:    "quota",
+		IsActive:  true,
+		CreatedAt: timeutil.TimeStampNow(),
+		UpdatedAt: timeutil.TimeStampNow(),
+	}
+	_, err := InsertTenant(db.DefaultContext, tenant)
+	assert.NoError(t, err)
+	assert.NoError(t, InsertTenantOrganization(db.DefaultContext, &ScTenantOrganizations{
+		ID:             uuid.NewString(),
+		TenantID:       tenant.ID,
+		OrganizationID: 100501,
+	}))
+
+	assert.NoError(t, CheckTenantQuotaByOrgID(db.DefaultContext, 100501, QuotaGitSize, 1<<40))
+
+	// проект 3 содержит репозитории из fixtures, квота выставляется равной текущему потреблению
+	assert.NoError(t, InsertTenantOrganization(db.DefaultContext, &ScTenantOrganizations{
+		ID:             uuid.NewString(),
+		TenantID:       tenant.ID,
+		OrganizationID: 3,
+	}))
+	usage, err := GetTenantUsage(db.DefaultContext, tenant.ID)
+	assert.NoError(t, err)
+	assert.Positive(t, usage.RepoCount)
+
+	tenant.MaxRepoCount = usage.RepoCount
+	assert.NoError(t, UpdateTenantQuotas(db.DefaultContext, tenant))
+
+	t.Run("AtLimit", func(t *testing.T) {
+		assert.NoError(t, CheckTenantQuotaByOrgID(db.DefaultContext, 3, QuotaRepoCount, 0))
+	})
+
+	t.Run("Exceeded", func(t *testing.T) {
+		err := CheckTenantQuotaByOrgID(db.DefaultContext, 3, QuotaRepoCount, 1)
+		assert.True(t, IsErrTenantQuotaExceeded(err))
+		quotaErr := ErrTenantQuotaExceeded{}
+		assert.ErrorAs(t, err, &quotaErr)
+		assert.Equal(t, QuotaRepoCount, quotaErr.Resource)
+		assert.Equal(t, usage.RepoCount, quotaErr.Limit)
+		assert.Equal(t, usage.RepoCount, quotaErr.Usage)
+	})
+
+	t.Run("BelowLimit", func(t *testing.T) {
+		tenant.MaxRepoCount = usage.RepoCount + 1
+		assert.NoError(t, UpdateTenantQuotas(db.DefaultContext, tenant))
+		assert.NoError(t, CheckTenantQuotaByOrgID(db.DefaultContext, 100501, QuotaRepoCount, 1))
+	})
+}
@@ -13,15 +13,23 @@ func init() {
 	db.RegisterModel(new(ScTenant))
 }
 
-// ScTenant структура полей для таблицы tenant
+// ScTenant структура полей для таблицы tenant.
+// Квоты MaxRepoCount, MaxGitSize, MaxLFSSize и MaxPackageSize со значением 0 не ограничивают ресурсы тенанта.
+// IsDeleting выставляется перед удалением ресурсов тенанта и позволяет продолжить прерванное удаление
 type ScTenant struct {
-	ID        string             `xorm:"pk uuid"`
-	Name      string             `xorm:"VARCHAR(50) UNIQUE"`
-	OrgKey    string             `xorm:"VARCHAR(50) UNIQUE"`
-	Default   bool               `xorm:"NOT NULL DEFAULT true"`
-	IsActive  bool               `xorm:"NOT NULL DEFAULT true"`
-	CreatedAt timeutil.TimeStamp `xorm:"created"`
-	UpdatedAt timeutil.TimeStamp `xorm:"updated"`
+	ID             string             `xorm:"pk uuid"`
+	Name           string             `xorm:"VARCHAR(50) UNIQUE"`
+	OrgKey         string             `xorm:"VARCHAR(50) UNIQUE"`
+	Default        bool               `xorm:"NOT NULL DEFAULT true"`
+	IsActive       bool               `xorm:"NOT NULL DEFAULT true"`
+	IsArchived     bool               `xorm:"NOT NULL DEFAULT false"`
+	IsDeleting     bool               `xorm:"NOT NULL DEFAULT false"`
+	MaxRepoCount   int64              `xorm:"NOT NULL DEFAULT 0"`
+	MaxGitSize     int64              `xorm:"NOT NULL DEFAULT 0"`
+	MaxLFSSize     int64              `xorm:"NOT NULL DEFAULT 0"`
+	MaxPackageSize int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedAt      timeutil.TimeStamp `xorm:"created"`
+	UpdatedAt      timeutil.TimeStamp `xorm:"updated"`
 }
 
 // GetTenants извлечение всех tenants
@@ -118,15 +126,38 @@ func UpdateTenant(ctx context.Context, tenant *ScTenant) error {
 	return nil
 }
 
-// DeleteTenant удаление tenant и связанных с ним organization из таблицы tenant_project
-func DeleteTenant(ctx context.Context, tenantID string, organizationIDs []int64) error {
-	_, err := db.GetEngine(ctx).ID(tenantID).Delete(&ScTenant{})
+// UpdateTenantCols обновление указанных колонок tenant
+func UpdateTenantCols(ctx context.Context, tenant *ScTenant, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(tenant.ID).Cols(cols...).Update(tenant)
+	return err
+}
+
+// CheckTenantIsActiveByOrgID проверяет, что тенант, в который входит организация, активен.
+// Организации без привязки к тенанту считаются активными
+func CheckTenantIsActiveByOrgID(ctx context.Context, organizationID int64) error {
+	tenantOrganization, err := GetTenantOrganizationsByOrgId(ctx, organizationID)
 	if err != nil {
+		if IsErrTenantOrganizationNotExists(err) {
+			return nil
+		}
 		return err
 	}
-	err = DeleteTenantOrganization(ctx, tenantID, organizationIDs)
+	tenant, err := GetTenantByID(ctx, tenantOrganization.TenantID)
 	if err != nil {
 		return err
 	}
+	if !tenant.IsActive {
+		return ErrTenantNotActive{TenantKey: tenant.OrgKey}
+	}
 	return nil
 }
+
+// DeleteTenant удаление tenant и связанных с ним organization из таблицы tenant_project
+func DeleteTenant(ctx context.Context, tenantID string, organizationIDs []int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).ID(tenantID).Delete(&ScTenant{}); err != nil {
+			return err
+		}
+		return DeleteTenantOrganization(ctx, tenantID, organizationIDs)
+	})
+}
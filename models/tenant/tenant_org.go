@@ -31,6 +31,12 @@ func GetTenantOrganizations(ctx context.Context, tenantID string) ([]*ScTenantOr
 		Find(&tenantOrganizations)
 }
 
+// GetAllTenantOrganizations извлекаем связи всех тенантов с организациями
+func GetAllTenantOrganizations(ctx context.Context) ([]*ScTenantOrganizations, error) {
+	var tenantOrganizations []*ScTenantOrganizations
+	return tenantOrganizations, db.GetEngine(ctx).Find(&tenantOrganizations)
+}
+
 // GetTenantOrganizationsByOrgId извлекаем тенант для организации
 func GetTenantOrganizationsByOrgId(ctx context.Context, organizationID int64) (*ScTenantOrganizations, error) {
 	tenantOrganization := &ScTenantOrganizations{OrganizationID: organizationID}
@@ -0,0 +1,95 @@
+package unit_code_pattern
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Setting))
+}
+
+// Setting структура таблицы unit_code_pattern, шаблоны кодов юнитов тенанта или репозитория,
+// по которым ищутся коды в названиях веток, пулл реквестов и коммитов. Настройка тенанта хранится с RepoID равным 0
+type Setting struct {
+	ID       int64    `xorm:"pk autoincr"`
+	TenantID string   `xorm:"VARCHAR(36) UNIQUE(s) NOT NULL"`
+	RepoID   int64    `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Patterns []string `xorm:"JSON TEXT"` // регулярные выражения, группа code выделяет код юнита из совпадения
+	// CreatedUnix и UpdatedUnix время создания и изменения настройки
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName возвращает имя таблицы шаблонов кодов юнитов
+func (Setting) TableName() string {
+	return "unit_code_pattern"
+}
+
+// GetSetting возвращает шаблоны кодов юнитов тенанта (repoID равен 0) или репозитория
+func GetSetting(ctx context.Context, tenantID string, repoID int64) (*Setting, bool, error) {
+	setting := new(Setting)
+	has, err := db.GetEngine(ctx).Where("tenant_id = ? AND repo_id = ?", tenantID, repoID).Get(setting)
+	if err != nil {
+		return nil, false, fmt.Errorf("get unit code patterns of tenant %s repo %d: %w", tenantID, repoID, err)
+	}
+	if !has {
+		return nil, false, nil
+	}
+	return setting, true, nil
+}
+
+// GetEffectiveSetting возвращает шаблоны кодов юнитов репозитория, а если их нет, шаблоны тенанта
+func GetEffectiveSetting(ctx context.Context, tenantID string, repoID int64) (*Setting, bool, error) {
+	if repoID > 0 {
+		setting, has, err := GetSetting(ctx, tenantID, repoID)
+		if err != nil || has {
+			return setting, has, err
+		}
+	}
+	return GetSetting(ctx, tenantID, 0)
+}
+
+// FindSettings возвращает шаблоны кодов юнитов тенанта и его репозиториев
+func FindSettings(ctx context.Context, tenantID string) ([]*Setting, error) {
+	settings := make([]*Setting, 0, 10)
+	if err := db.GetEngine(ctx).Where("tenant_id = ?", tenantID).OrderBy("repo_id").Find(&settings); err != nil {
+		return nil, fmt.Errorf("find unit code patterns of tenant %s: %w", tenantID, err)
+	}
+	return settings, nil
+}
+
+// SaveSetting создает или изменяет шаблоны кодов юнитов тенанта или репозитория
+func SaveSetting(ctx context.Context, setting *Setting) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		existing, has, err := GetSetting(ctx, setting.TenantID, setting.RepoID)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if _, err = db.GetEngine(ctx).Insert(setting); err != nil {
+				return fmt.Errorf("insert unit code patterns: %w", err)
+			}
+			return nil
+		}
+
+		setting.ID = existing.ID
+		setting.CreatedUnix = existing.CreatedUnix
+		if _, err = db.GetEngine(ctx).ID(setting.ID).Cols("patterns").Update(setting); err != nil {
+			return fmt.Errorf("update unit code patterns %d: %w", setting.ID, err)
+		}
+		return nil
+	})
+}
+
+// DeleteSetting удаляет шаблоны кодов юнитов тенанта или репозитория
+func DeleteSetting(ctx context.Context, tenantID string, repoID int64) (bool, error) {
+	deleted, err := db.GetEngine(ctx).Where("tenant_id = ? AND repo_id = ?", tenantID, repoID).Delete(new(Setting))
+	if err != nil {
+		return false, fmt.Errorf("delete unit code patterns of tenant %s repo %d: %w", tenantID, repoID, err)
+	}
+	return deleted > 0, nil
+}
@@ -16,8 +16,25 @@ type FromUnitType int
 
 const (
 	PullRequestFromUnitType FromUnitType = iota + 1
+	// CommitFromUnitType связь коммита с юнитом, FromUnitID является идентификатором UnitLinksRef
+	CommitFromUnitType
+	// BranchFromUnitType связь ветки с юнитом, FromUnitID является идентификатором UnitLinksRef
+	BranchFromUnitType
 )
 
+func (t FromUnitType) String() string {
+	switch t {
+	case PullRequestFromUnitType:
+		return "pull_request"
+	case CommitFromUnitType:
+		return "commit"
+	case BranchFromUnitType:
+		return "branch"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
 type AllUnitLinks []UnitLinks
 
 // UnitLinks структура полей для таблицы unit_links
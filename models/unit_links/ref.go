@@ -0,0 +1,69 @@
+package unit_links
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(UnitLinksRef))
+}
+
+// UnitLinksRef структура полей для таблицы unit_links_ref, коммит или ветка репозитория, связанные с юнитами.
+// Для связей коммитов и веток UnitLinks.FromUnitID является идентификатором UnitLinksRef
+type UnitLinksRef struct {
+	ID      int64        `xorm:"PK AUTOINCR" json:"id"`
+	RepoID  int64        `xorm:"NOT NULL UNIQUE(s)" json:"repo_id"`
+	RefType FromUnitType `xorm:"NOT NULL UNIQUE(s)" json:"ref_type"`
+	// Ref SHA коммита или имя ветки
+	Ref string `xorm:"VARCHAR(255) NOT NULL UNIQUE(s)" json:"ref"`
+	// Title заголовок сообщения коммита или имя ветки
+	Title     string             `xorm:"TEXT" json:"title"`
+	URL       string             `xorm:"TEXT" json:"url"`
+	CreatedAt timeutil.TimeStamp `xorm:"CREATED" json:"-"`
+}
+
+// GetOrCreateRef возвращает коммит или ветку репозитория, создавая запись при ее отсутствии
+func GetOrCreateRef(ctx context.Context, ref *UnitLinksRef) (*UnitLinksRef, error) {
+	existing, has, err := GetRef(ctx, ref.RepoID, ref.RefType, ref.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return existing, nil
+	}
+
+	if _, err = db.GetEngine(ctx).Insert(ref); err != nil {
+		return nil, fmt.Errorf("insert unit links ref: %w", err)
+	}
+	return ref, nil
+}
+
+// GetRef возвращает коммит или ветку репозитория
+func GetRef(ctx context.Context, repoID int64, refType FromUnitType, ref string) (*UnitLinksRef, bool, error) {
+	linksRef := new(UnitLinksRef)
+	has, err := db.GetEngine(ctx).Where("repo_id = ? AND ref_type = ? AND ref = ?", repoID, refType, ref).Get(linksRef)
+	if err != nil {
+		return nil, false, fmt.Errorf("get unit links ref %s of repo %d: %w", ref, repoID, err)
+	}
+	if !has {
+		return nil, false, nil
+	}
+	return linksRef, true, nil
+}
+
+// GetRefByID возвращает коммит или ветку по идентификатору
+func GetRefByID(ctx context.Context, id int64) (*UnitLinksRef, error) {
+	linksRef := new(UnitLinksRef)
+	has, err := db.GetEngine(ctx).ID(id).Get(linksRef)
+	if err != nil {
+		return nil, fmt.Errorf("get unit links ref %d: %w", id, err)
+	}
+	if !has {
+		return nil, fmt.Errorf("unit links ref %d does not exist", id)
+	}
+	return linksRef, nil
+}
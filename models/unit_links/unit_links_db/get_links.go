@@ -26,7 +26,7 @@ func (u unitLinkDB) GetUnitLinks(_ goCtx.Context, pullRequestID int64) (unit_lin
 	unitLinks := make([]unit_links.UnitLinks, 0)
 
 	if err = u.engine.
-		Where(builder.Eq{"is_active": 1}, builder.Eq{"from_unit_id": pullRequestID}).
+		Where(builder.Eq{"is_active": 1}, builder.Eq{"from_unit_id": pullRequestID, "from_unit_type": unit_links.PullRequestFromUnitType}).
 		Table("unit_links").
 		Find(&unitLinks); err != nil {
 		return nil, fmt.Errorf("find unit links: %w", err)
@@ -27,10 +27,15 @@ func NewUnitLinkDB(engine dbEngine) unitLinkDB {
 	return unitLinkDB{engine: engine}
 }
 
-func (u unitLinkDB) calculateLinksDiff(_ context.Context, links unit_links.AllUnitLinks, fromUnitID int64) (unit_links.Diff, error) {
+func (u unitLinkDB) calculateLinksDiff(
+	_ context.Context,
+	links unit_links.AllUnitLinks,
+	fromUnitID int64,
+	fromUnitType unit_links.FromUnitType,
+) (unit_links.Diff, error) {
 	oldLinks := make([]unit_links.UnitLinks, 0)
 
-	if err := u.engine.Where(builder.Eq{"is_active": 1}, builder.Eq{"from_unit_id": fromUnitID}).
+	if err := u.engine.Where(builder.Eq{"is_active": 1}, builder.Eq{"from_unit_id": fromUnitID, "from_unit_type": fromUnitType}).
 		Table("unit_links").
 		Find(&oldLinks); err != nil {
 		return unit_links.Diff{}, fmt.Errorf("delete unit links: %w", err)
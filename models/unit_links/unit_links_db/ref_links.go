@@ -0,0 +1,149 @@
+package unit_links_db
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/background_task"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unit_links"
+	"code.gitea.io/gitea/models/unit_links_sender"
+	"code.gitea.io/gitea/modules/json"
+)
+
+// UpdateRefLinks метод обновляет список линков коммита или ветки, создавая запись unit_links_ref при ее отсутствии
+func (u unitLinkDB) UpdateRefLinks(
+	ctx context.Context,
+	ref *unit_links.UnitLinksRef,
+	links unit_links.AllUnitLinks,
+	userName string,
+) error {
+	updateLinks := func(ctx context.Context) error {
+		linksRef, err := unit_links.GetOrCreateRef(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("get or create ref: %w", err)
+		}
+
+		for idx := range links {
+			links[idx].FromUnitID = linksRef.ID
+			links[idx].FromUnitType = linksRef.RefType
+		}
+
+		return u.updateRefLinks(ctx, linksRef, links, userName)
+	}
+
+	if err := db.WithTx(ctx, updateLinks); err != nil {
+		return fmt.Errorf("update ref unit links: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveRefLinks метод отвязывает коммит или ветку от всех юнитов
+func (u unitLinkDB) RemoveRefLinks(
+	ctx context.Context,
+	repoID int64,
+	refType unit_links.FromUnitType,
+	ref, userName string,
+) error {
+	removeLinks := func(ctx context.Context) error {
+		linksRef, has, err := unit_links.GetRef(ctx, repoID, refType, ref)
+		if err != nil {
+			return fmt.Errorf("get ref: %w", err)
+		}
+		if !has {
+			return nil
+		}
+
+		return u.updateRefLinks(ctx, linksRef, nil, userName)
+	}
+
+	if err := db.WithTx(ctx, removeLinks); err != nil {
+		return fmt.Errorf("remove ref unit links: %w", err)
+	}
+
+	return nil
+}
+
+func (u unitLinkDB) updateRefLinks(
+	ctx context.Context,
+	linksRef *unit_links.UnitLinksRef,
+	links unit_links.AllUnitLinks,
+	userName string,
+) error {
+	diff, err := u.calculateLinksDiff(ctx, links, linksRef.ID, linksRef.RefType)
+	if err != nil {
+		return fmt.Errorf("calculate links diff: %w", err)
+	}
+
+	if diff.IsEmpty() {
+		return nil
+	}
+
+	var payloadsAdd unit_links.AllPayloadToAddOrDeletePr
+	for idx := range diff.LinksToAdd {
+		diff.LinksToAdd[idx].IsActive = true
+		if err := u.upsertLinks(ctx, diff.LinksToAdd[idx]); err != nil {
+			return fmt.Errorf("upsert active unit link: %w", err)
+		}
+		payloadsAdd = append(payloadsAdd, unit_links.PayloadToAddOrDeletePr{
+			FromUnitID:   diff.LinksToAdd[idx].FromUnitID,
+			FromUnitType: diff.LinksToAdd[idx].FromUnitType,
+			ToUnitID:     diff.LinksToAdd[idx].ToUnitID,
+			IsActive:     diff.LinksToAdd[idx].IsActive,
+		})
+	}
+
+	if err := u.insertRefTasks(ctx, unit_links_sender.SendAddRefLinksAction, payloadsAdd, linksRef, userName); err != nil {
+		return fmt.Errorf("insert add tasks: %w", err)
+	}
+
+	var payloadsDel unit_links.AllPayloadToAddOrDeletePr
+	for idx := range diff.LinksToDelete {
+		diff.LinksToDelete[idx].IsActive = false
+		if err := u.upsertLinks(ctx, diff.LinksToDelete[idx]); err != nil {
+			return fmt.Errorf("upsert inactive unit link: %w", err)
+		}
+		payloadsDel = append(payloadsDel, unit_links.PayloadToAddOrDeletePr{
+			FromUnitID:   diff.LinksToDelete[idx].FromUnitID,
+			FromUnitType: diff.LinksToDelete[idx].FromUnitType,
+			ToUnitID:     diff.LinksToDelete[idx].ToUnitID,
+			IsActive:     diff.LinksToDelete[idx].IsActive,
+		})
+	}
+
+	if err := u.insertRefTasks(ctx, unit_links_sender.SendDeleteRefLinksAction, payloadsDel, linksRef, userName); err != nil {
+		return fmt.Errorf("insert delete tasks: %w", err)
+	}
+
+	return nil
+}
+
+// insertRefTasks ставит в очередь отправку привязки или отвязки юнитов коммита или ветки linksRef
+func (u unitLinkDB) insertRefTasks(
+	ctx context.Context,
+	action unit_links_sender.Action,
+	links unit_links.AllPayloadToAddOrDeletePr,
+	linksRef *unit_links.UnitLinksRef,
+	userName string,
+) error {
+	if links == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(links)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	if _, err = unit_links_sender.Queue.Enqueue(ctx, unit_links_sender.Payload{
+		Action:   action,
+		UserName: userName,
+		RefID:    linksRef.ID,
+		Links:    payload,
+	}, background_task.EnqueueOptions{}); err != nil {
+		return fmt.Errorf("insert ref tasks: %w", err)
+	}
+
+	return nil
+}
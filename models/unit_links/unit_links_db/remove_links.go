@@ -38,8 +38,8 @@ func (u unitLinkDB) RemoveLinks(
 			unit_links_sender.SendDeletePullRequestLinksAction,
 			taskTrackerPayloads,
 			fromUnitID,
-			pullRequestURL,
 			userName,
+			pullRequestURL,
 		); err != nil {
 			return fmt.Errorf("insert add tasks: %w", err)
 		}
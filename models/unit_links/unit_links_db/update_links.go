@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"time"
 
+	"code.gitea.io/gitea/models/background_task"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/pull_request_sender"
 	"code.gitea.io/gitea/models/unit_links"
@@ -22,7 +23,7 @@ func (u unitLinkDB) UpdateLinks(
 	userName, pullRequestURL string,
 ) error {
 	createLinks := func(ctx context.Context) error {
-		diff, diffErr := u.calculateLinksDiff(ctx, links, fromUnitID)
+		diff, diffErr := u.calculateLinksDiff(ctx, links, fromUnitID, unit_links.PullRequestFromUnitType)
 		if diffErr != nil {
 			return fmt.Errorf("calculate links diff: %w", diffErr)
 		}
@@ -67,8 +68,8 @@ func (u unitLinkDB) UpdateLinks(
 			unit_links_sender.SendAddPullRequestLinksAction,
 			taskTrackerPayloadsAdd,
 			fromUnitID,
-			pullRequestURL,
 			userName,
+			pullRequestURL,
 		); err != nil {
 			return fmt.Errorf("insert add tasks: %w", err)
 		}
@@ -94,8 +95,8 @@ func (u unitLinkDB) UpdateLinks(
 			unit_links_sender.SendDeletePullRequestLinksAction,
 			taskTrackerPayloadsDel,
 			fromUnitID,
-			pullRequestURL,
 			userName,
+			pullRequestURL,
 		); err != nil {
 			return fmt.Errorf("insert delete tasks: %w", err)
 		}
@@ -141,7 +142,7 @@ DO UPDATE SET
 }
 
 func (u unitLinkDB) insertTasks(
-	_ context.Context,
+	ctx context.Context,
 	action unit_links_sender.Action,
 	links unit_links.AllPayloadToAddOrDeletePr,
 	pullRequestID int64,
@@ -151,31 +152,18 @@ func (u unitLinkDB) insertTasks(
 		return nil
 	}
 
-	insertQuery := `
-INSERT INTO unit_links_sender_tasks
-	(payload, action, pull_request_id, pull_request_url, user_name, status, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-`
-	timeNow := time.Now().Unix()
-
 	payload, err := json.Marshal(links)
 	if err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
 
-	_, err = u.engine.Exec(
-		insertQuery,
-		string(payload),
-		action,
-		pullRequestID,
-		userName,
-		pullRequestURL,
-		unit_links_sender.StatusUnlocked,
-		timeNow,
-		timeNow,
-	)
-
-	if err != nil {
+	if _, err = unit_links_sender.Queue.Enqueue(ctx, unit_links_sender.Payload{
+		Action:         action,
+		UserName:       userName,
+		PullRequestID:  pullRequestID,
+		PullRequestURL: pullRequestURL,
+		Links:          payload,
+	}, background_task.EnqueueOptions{}); err != nil {
 		return fmt.Errorf("insert from unit links: %w", err)
 	}
 
@@ -0,0 +1,19 @@
+package unit_links_push_task
+
+import (
+	"code.gitea.io/gitea/models/background_task"
+)
+
+// Queue очередь привязки к юнитам веток и коммитов, отправленных в репозиторий
+var Queue = background_task.NewQueue[Payload]("unit_links_push")
+
+// Payload нагрузка задачи привязки веток и коммитов одного push. Элементы OldCommitIDs, NewCommitIDs
+// и RefFullNames с одинаковым индексом относятся к одной ссылке
+type Payload struct {
+	RepoID   int64  `json:"repo_id"`
+	UserName string `json:"user_name"`
+
+	OldCommitIDs []string `json:"old_commit_ids"`
+	NewCommitIDs []string `json:"new_commit_ids"`
+	RefFullNames []string `json:"ref_full_names"`
+}
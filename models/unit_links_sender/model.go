@@ -1,43 +1,36 @@
 package unit_links_sender
 
 import (
-	"code.gitea.io/gitea/models/db"
-	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/models/background_task"
+	"code.gitea.io/gitea/modules/json"
 )
 
-func init() {
-	db.RegisterModel(new(UnitLinksSenderTasks))
-}
-
 type Action string
 
 const (
 	SendAddPullRequestLinksAction     Action = "add_pull_request"
 	SendDeletePullRequestLinksAction  Action = "delete_pull_request"
 	SendUpdatePullRequestStatusAction Action = "update_pull_request_status"
+	// SendAddRefLinksAction и SendDeleteRefLinksAction привязка и отвязка коммита или ветки unit_links.UnitLinksRef
+	// с идентификатором RefID
+	SendAddRefLinksAction    Action = "add_ref"
+	SendDeleteRefLinksAction Action = "delete_ref"
 )
 
-type Status string
-
-const (
-	StatusDone     Status = "done"
-	StatusUnlocked Status = "unlocked"
-	StatusLocked   Status = "locked"
-)
-
-// UnitLinksSenderTasks структура полей для таблицы unit_links_sender_tasks
-type UnitLinksSenderTasks struct {
-	ID int64 `xorm:"PK AUTOINCR" json:"-"`
-
-	Payload  string `xorm:"NOT NULL TEXT JSON" json:"payload"`
-	Action   Action `xorm:"NOT NULL" json:"action"`
-	UserName string `xorm:"NOT NULL" json:"user_name"`
+// Queue очередь отправки связей юнитов в трекер задач
+var Queue = background_task.NewQueue[Payload]("unit_links_sender")
 
-	PullRequestID  int64  `xorm:"NOT NULL" json:"pull_request_id"`
-	PullRequestURL string `xorm:"NOT NULL" json:"pull_request_url"`
+// Payload нагрузка задачи отправки связей юнитов в трекер задач
+type Payload struct {
+	Action   Action `json:"action"`
+	UserName string `json:"user_name"`
 
-	Status Status `xorm:"NOT NULL" json:"status"`
+	PullRequestID  int64  `json:"pull_request_id"`
+	PullRequestURL string `json:"pull_request_url"`
+	// RefID идентификатор коммита или ветки unit_links.UnitLinksRef для действий SendAddRefLinksAction
+	// и SendDeleteRefLinksAction
+	RefID int64 `json:"ref_id,omitempty"`
 
-	CreatedAt timeutil.TimeStamp `xorm:"CREATED" json:"-"`
-	UpdatedAt timeutil.TimeStamp `xorm:"UPDATED" json:"-"`
+	// Links связи юнитов в формате unit_links.AllPayloadToAddOrDeletePr
+	Links json.RawMessage `json:"links"`
 }
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models/auth"
@@ -464,8 +465,42 @@ func (ctx *APIContext) IsUserRepoWriter(unitTypes []unit.Type) bool {
 	return false
 }
 
+// CheckRepoTenantIsActiveApi проверяет, что тенант репозитория активен, иначе отвечает 403.
+// Возвращает false, если запрос дальше обрабатывать не нужно
+func CheckRepoTenantIsActiveApi(ctx *APIContext, repo *repo_model.Repository) bool {
+	err := tenant.CheckTenantIsActiveByOrgID(ctx, repo.OwnerID)
+	if err == nil {
+		return true
+	}
+	if !tenant.IsTenantNotActive(err) {
+		log.Error("Error has occurred while checking tenant of repository %d: %v", repo.ID, err)
+		ctx.InternalServerError(err)
+		return false
+	}
+
+	doerName, doerID := audit.EmptyRequiredField, audit.EmptyRequiredField
+	if ctx.Doer != nil {
+		doerName = ctx.Doer.Name
+		doerID = strconv.FormatInt(ctx.Doer.ID, 10)
+	}
+	auditParams := map[string]string{
+		"repository":    repo.Name,
+		"repository_id": strconv.FormatInt(repo.ID, 10),
+		"owner":         repo.OwnerName,
+		"error":         "Tenant is not active",
+	}
+	audit.CreateAndSendEvent(audit.TenantAccessDeniedEvent, doerName, doerID, audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+	ctx.Error(http.StatusForbidden, "CheckRepoTenantIsActive", "Tenant is not active")
+	return false
+}
+
 func RequireRepoPermissionApi(action role_model.Action) func(ctx *APIContext) {
 	return func(ctx *APIContext) {
+		if ctx.Repo != nil && ctx.Repo.Repository != nil && setting.SourceControl.Enabled {
+			if !CheckRepoTenantIsActiveApi(ctx, ctx.Repo.Repository) {
+				return
+			}
+		}
 		if ctx.IsSigned && ctx.Repo != nil && ctx.Repo.Repository != nil && setting.SourceControl.TenantWithRoleModeEnabled {
 			tenantId, err := tenant.GetTenantByOrgIdOrDefault(ctx, ctx.Repo.Repository.OwnerID)
 			if err != nil {
@@ -473,7 +508,7 @@ func RequireRepoPermissionApi(action role_model.Action) func(ctx *APIContext) {
 				return
 			}
 
-			allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, action)
+			allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, ctx.Repo.Repository, action)
 			if err != nil {
 				ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
 			}
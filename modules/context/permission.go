@@ -215,7 +215,7 @@ func RequireRepoPermission(action role_model.Action) func(ctx *Context) {
 				return
 			}
 
-			allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, action)
+			allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, ctx.Repo.Repository, action)
 			if err != nil || !allowed {
 				ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
 			}
@@ -301,16 +301,13 @@ func allowPermissions(ctx *Context, customPrivilege role_model.CustomPrivilege)
 			return
 		}
 
-		allow, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, action)
-		if !allow || err != nil {
-			allowed, err := role_model.CheckUserPermissionToTeam(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID},
-				&repo_model.Repository{ID: ctx.Repo.Repository.ID}, customPrivilege.String(),
-			)
-			if err != nil || !allowed {
-				log.Error("Error has occurred while checking user permission to organization or custom privileges")
-				ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
-				return
-			}
+		allowed, err := role_model.CheckUserPermissionToRepositoryOrTeam(ctx, ctx.Doer, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID},
+			ctx.Repo.Repository, action, customPrivilege,
+		)
+		if err != nil || !allowed {
+			log.Error("Error has occurred while checking user permission to organization or custom privileges")
+			ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
+			return
 		}
 	}
 }
@@ -351,24 +348,17 @@ func RequireCustomPermission(action role_model.Action, customPrivilege role_mode
 				}
 			}
 
-			allow, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantID, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, action)
+			allowed, err := role_model.CheckUserPermissionToRepositoryOrTeam(ctx, ctx.Doer, tenantID, &organization.Organization{ID: ctx.Repo.Repository.OwnerID},
+				ctx.Repo.Repository, action, customPrivilege)
 			if err != nil {
+				log.Error("Error has occurred while checking user permission to organization or custom privileges")
 				ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
 				return
 			}
-			if !allow {
-				allowed, err := role_model.CheckUserPermissionToTeam(ctx, ctx.Doer, tenantID, &organization.Organization{ID: ctx.Repo.Repository.OwnerID},
-					&repo_model.Repository{ID: ctx.Repo.Repository.ID}, customPrivilege.String())
-				if err != nil {
-					log.Error("Error has occurred while checking user permission to organization or custom privileges")
-					ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
-					return
-				}
-				if !allowed {
-					log.Warn("Access denied: user does not have the required role or privilege")
-					ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
-					return
-				}
+			if !allowed {
+				log.Warn("Access denied: user does not have the required role or privilege")
+				ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
+				return
 			}
 		}
 	}
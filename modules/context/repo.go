@@ -484,6 +484,20 @@ func repoAssignment(ctx *Context, repo *repo_model.Repository) {
 		return
 	}
 
+	// репозитории деактивированного тенанта недоступны через web интерфейс
+	if setting.SourceControl.Enabled && repo.Owner.IsOrganization() {
+		if err = tenant.CheckTenantIsActiveByOrgID(ctx, repo.OwnerID); err != nil {
+			if tenant.IsTenantNotActive(err) {
+				auditParams["error"] = "Tenant is not active"
+				audit.CreateAndSendEvent(audit.TenantAccessDeniedEvent, doer, doerID, audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+				ctx.NotFound(ctx.Req.URL.RequestURI(), err)
+				return
+			}
+			ctx.ServerError("CheckTenantIsActiveByOrgID", err)
+			return
+		}
+	}
+
 	// если у нас включена ролевая модель SourceControl, то запускается проверка привилегий на чтение репозитория
 	if setting.SourceControl.TenantWithRoleModeEnabled {
 		if ctx.IsSigned {
@@ -500,7 +514,7 @@ func repoAssignment(ctx *Context, repo *repo_model.Repository) {
 			}
 			var countMatch int
 			for _, tenantId := range tenantIDs {
-				allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantId, &organization.Organization{ID: repo.OwnerID}, action)
+				allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.Doer, tenantId, &organization.Organization{ID: repo.OwnerID}, repo, action)
 				if err != nil {
 					log.Error("Error has occurred while checking user's permissions: %v", err)
 					ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
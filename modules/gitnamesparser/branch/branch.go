@@ -18,7 +18,13 @@ var branchCodeRE = regexp.MustCompile("[^/_a-zа-я-][A-Z_0-9]{1,30}-[0-9]{1,30}
 
 // Parse метод ищет коды юнитов TaskTracker в названии ветки
 func (b branchParser) Parse(branchName string) (gitnames.BranchLinks, error) {
-	codes, desc, err := gitnamesparser.ParseCodesAndDescription(branchName, branchCodeRE)
+	return b.ParseWithPatterns(branchName, nil)
+}
+
+// ParseWithPatterns метод ищет коды юнитов в названии ветки по шаблонам трекера задач,
+// если patterns пустые, используется шаблон по умолчанию
+func (b branchParser) ParseWithPatterns(branchName string, patterns gitnamesparser.Patterns) (gitnames.BranchLinks, error) {
+	codes, desc, err := gitnamesparser.ParseCodes(branchName, patterns, branchCodeRE)
 	if err != nil {
 		return gitnames.BranchLinks{}, fmt.Errorf("parse codes and description: %w", err)
 	}
@@ -0,0 +1,44 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/modules/gitnamesparser"
+)
+
+type commitParser struct{}
+
+func NewParser() commitParser {
+	return commitParser{}
+}
+
+var commitCodeRE = regexp.MustCompile("[A-Z_0-9]{1,30}-[0-9]{1,30}")
+
+// Parse метод ищет коды юнитов TaskTracker в сообщении коммита
+func (c commitParser) Parse(message string) (gitnames.CommitLinks, error) {
+	return c.ParseWithPatterns(message, nil)
+}
+
+// ParseWithPatterns метод ищет коды юнитов в сообщении коммита по шаблонам трекера задач,
+// если patterns пустые, используется шаблон по умолчанию
+func (c commitParser) ParseWithPatterns(message string, patterns gitnamesparser.Patterns) (gitnames.CommitLinks, error) {
+	codes, desc, err := gitnamesparser.ParseCodes(message, patterns, commitCodeRE)
+	if err != nil {
+		return gitnames.CommitLinks{}, fmt.Errorf("parse codes and description: %w", err)
+	}
+
+	if len(codes) == 0 {
+		return gitnames.CommitLinks{}, gitnames.NewEmptyUnitCodesListError()
+	}
+
+	commit := gitnames.CommitLinks{
+		Base: gitnames.Base{Description: desc},
+	}
+	for _, code := range codes {
+		commit.LinkedUnits = append(commit.LinkedUnits, gitnames.UnitCode{Code: code})
+	}
+
+	return commit, nil
+}
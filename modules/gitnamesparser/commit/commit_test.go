@@ -0,0 +1,87 @@
+//go:build !correct
+
+package commit
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/modules/gitnamesparser"
+)
+
+func Test_commitParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    gitnames.CommitLinks
+	}{
+		{
+			message: "GITRU-1 Init commit",
+			want: gitnames.CommitLinks{
+				Base: gitnames.Base{
+					Description: "GITRU-1 Init commit",
+					LinkedUnits: []gitnames.UnitCode{{Code: "GITRU-1"}},
+				},
+			},
+		},
+		{
+			message: "[GIT_RU-1] [GITRU-2] Fix tests",
+			want: gitnames.CommitLinks{
+				Base: gitnames.Base{
+					Description: "[GIT_RU-1] [GITRU-2] Fix tests",
+					LinkedUnits: []gitnames.UnitCode{{Code: "GIT_RU-1"}, {Code: "GITRU-2"}},
+				},
+			},
+		},
+	}
+
+	c := commitParser{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Parse(tt.message)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_commitParser_ParseWithPatterns(t *testing.T) {
+	patterns := gitnamesparser.Patterns{regexp.MustCompile(`#(?P<code>\d+)`)}
+
+	c := commitParser{}
+
+	got, err := c.ParseWithPatterns("Fix #12 and GITRU-1", patterns)
+	require.NoError(t, err)
+	require.Equal(t, gitnames.CommitLinks{
+		Base: gitnames.Base{
+			Description: "Fix #12 and GITRU-1",
+			LinkedUnits: []gitnames.UnitCode{{Code: "12"}},
+		},
+	}, got)
+}
+
+func Test_commitParser_Parse_negative(t *testing.T) {
+	targetErr := gitnamesparser.NewUnitCodeNotFoundError("")
+
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{value: "", want: targetErr},
+		{value: "Init commit", want: targetErr},
+	}
+
+	c := commitParser{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Parse(tt.value)
+			require.ErrorAs(t, err, &tt.want)
+		})
+	}
+}
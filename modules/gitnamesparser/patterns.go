@@ -0,0 +1,79 @@
+package gitnamesparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CodeGroupName имя группы шаблона, значение которой является кодом юнита. Например, шаблон `#(?P<code>\d+)`
+// находит в "#123" код "123". Если группы нет, кодом юнита является все совпадение
+const CodeGroupName = "code"
+
+// Patterns шаблоны кодов юнитов, коды ищутся по всем шаблонам в порядке их перечисления
+type Patterns []*regexp.Regexp
+
+// CompilePatterns компилирует шаблоны кодов юнитов, пустые строки пропускаются
+func CompilePatterns(patterns []string) (Patterns, error) {
+	compiled := make(Patterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile unit code pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// FindCodes возвращает коды юнитов в порядке их нахождения без повторов
+func (p Patterns) FindCodes(value string) []string {
+	var codes []string
+	seen := make(map[string]struct{})
+	for _, re := range p {
+		codeGroup := re.SubexpIndex(CodeGroupName)
+		for _, match := range re.FindAllStringSubmatch(value, -1) {
+			code := match[0]
+			if codeGroup > 0 {
+				code = match[codeGroup]
+			}
+			if code == "" {
+				continue
+			}
+			if _, ok := seen[code]; ok {
+				continue
+			}
+			seen[code] = struct{}{}
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// ParseCodesAndDescriptionWithPatterns функция ищет коды юнитов по шаблонам трекера задач
+func ParseCodesAndDescriptionWithPatterns(value string, patterns Patterns) ([]string, string, error) {
+	if value == "" || len(value) < 2 {
+		return nil, "", NewUnitCodeNotFoundError(value)
+	}
+
+	description := strings.TrimSpace(value)
+	codes := patterns.FindCodes(description)
+
+	if codes == nil {
+		return nil, "", NewUnitCodeNotFoundError(description)
+	}
+
+	return codes, description, nil
+}
+
+// ParseCodes функция ищет коды юнитов по шаблонам трекера задач, а если они не заданы, по шаблону по умолчанию defaultRE
+func ParseCodes(value string, patterns Patterns, defaultRE *regexp.Regexp) ([]string, string, error) {
+	if len(patterns) > 0 {
+		return ParseCodesAndDescriptionWithPatterns(value, patterns)
+	}
+	return ParseCodesAndDescription(value, defaultRE)
+}
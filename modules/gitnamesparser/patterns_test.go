@@ -0,0 +1,66 @@
+//go:build !correct
+
+package gitnamesparser
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatterns_FindCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		value    string
+		want     []string
+	}{
+		{name: "jira key", patterns: []string{`[A-Z][A-Z0-9]+-\d+`}, value: "PROJ-123 fix PROJ-7 and PROJ-123", want: []string{"PROJ-123", "PROJ-7"}},
+		{name: "code group", patterns: []string{`#(?P<code>\d+)`}, value: "Fix #123 and #45", want: []string{"123", "45"}},
+		{name: "several patterns", patterns: []string{`US\d+`, `#(?P<code>\d+)`}, value: "US12345: see #9", want: []string{"US12345", "9"}},
+		{name: "empty pattern skipped", patterns: []string{"", `US\d+`}, value: "US1", want: []string{"US1"}},
+		{name: "nothing found", patterns: []string{`US\d+`}, value: "feature/login", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns, err := CompilePatterns(tt.patterns)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, patterns.FindCodes(tt.value))
+		})
+	}
+}
+
+func TestCompilePatterns_invalid(t *testing.T) {
+	_, err := CompilePatterns([]string{`US\d+`, `(`})
+	require.Error(t, err)
+}
+
+func TestParseCodesAndDescriptionWithPatterns(t *testing.T) {
+	patterns, err := CompilePatterns([]string{`#(?P<code>\d+)`})
+	require.NoError(t, err)
+
+	codes, description, err := ParseCodesAndDescriptionWithPatterns(" Fix #12 ", patterns)
+	require.NoError(t, err)
+	require.Equal(t, []string{"12"}, codes)
+	require.Equal(t, "Fix #12", description)
+
+	_, _, err = ParseCodesAndDescriptionWithPatterns("Fix login", patterns)
+	targetErr := &UnitCodeNotFoundError{}
+	require.ErrorAs(t, err, &targetErr)
+}
+
+func TestParseCodes(t *testing.T) {
+	defaultRE := regexp.MustCompile(`[A-Z]+-\d+`)
+	patterns, err := CompilePatterns([]string{`#(?P<code>\d+)`})
+	require.NoError(t, err)
+
+	codes, _, err := ParseCodes("Fix #12 ABC-1", patterns, defaultRE)
+	require.NoError(t, err)
+	require.Equal(t, []string{"12"}, codes)
+
+	codes, _, err = ParseCodes("Fix #12 ABC-1", nil, defaultRE)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ABC-1"}, codes)
+}
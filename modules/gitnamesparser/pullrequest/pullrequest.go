@@ -12,12 +12,20 @@ import (
 )
 
 type pullRequestLinksParser struct {
+	// patterns шаблоны кодов юнитов трекера задач, если не заданы, используются шаблоны по умолчанию
+	patterns gitnamesparser.Patterns
 }
 
 func NewParser() pullRequestLinksParser {
 	return pullRequestLinksParser{}
 }
 
+// ParseWithPatterns метод ищет коды юнитов по шаблонам трекера задач, если patterns пустые, используются шаблоны по умолчанию
+func (p pullRequestLinksParser) ParseWithPatterns(header pull_request_reader.PullRequestHeader, patterns gitnamesparser.Patterns) (gitnames.PullRequestLinks, error) {
+	p.patterns = patterns
+	return p.Parse(header)
+}
+
 // Parse метод ищет коды юнитов TaskTracker в названии ветки, МРа, коммитов
 func (p pullRequestLinksParser) Parse(header pull_request_reader.PullRequestHeader) (gitnames.PullRequestLinks, error) {
 	gatherCommitsLinks := func(prl *gitnames.PullRequestLinks) error {
@@ -85,7 +93,7 @@ var branchCodeRE = regexp.MustCompile("[^/_a-zа-я-][A-Z_0-9]{1,30}-[0-9]{1,30}
 
 func (p pullRequestLinksParser) gatherPullRequestsLinks(header pull_request_reader.PullRequestHeader) (gitnames.PullRequestLinks, error) {
 	pullRequestCodes, prDescription, err :=
-		gitnamesparser.ParseCodesAndDescription(header.PullRequestName, pullRequestCodeRE)
+		gitnamesparser.ParseCodes(header.PullRequestName, p.patterns, pullRequestCodeRE)
 	if err != nil {
 		return gitnames.PullRequestLinks{}, fmt.Errorf("pull request name: %w", err)
 	}
@@ -105,7 +113,7 @@ func (p pullRequestLinksParser) gatherPullRequestsLinks(header pull_request_read
 
 func (p pullRequestLinksParser) gatherBranchLinks(branchName string) (gitnames.BranchLinks, error) {
 	branchCodes, branchDescription, err :=
-		gitnamesparser.ParseCodesAndDescription(branchName, branchCodeRE)
+		gitnamesparser.ParseCodes(branchName, p.patterns, branchCodeRE)
 	if err != nil {
 		return gitnames.BranchLinks{}, fmt.Errorf("branch name: %w", err)
 	}
@@ -128,7 +136,7 @@ func (p pullRequestLinksParser) gatherCommitsLinks(commitNames []string) ([]gitn
 
 	var commitsLinks []gitnames.CommitLinks
 	for _, commitName := range commitNames {
-		commitCodes, commitDescription, err := gitnamesparser.ParseCodesAndDescription(commitName, pullRequestCodeRE)
+		commitCodes, commitDescription, err := gitnamesparser.ParseCodes(commitName, p.patterns, pullRequestCodeRE)
 		if err != nil {
 			continue
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/modules/gitnamesparser"
 )
 
 func Test_pullRequestNameParser_Parse(t *testing.T) {
@@ -120,3 +121,27 @@ func Test_pullRequestNameParser_Parse(t *testing.T) {
 		})
 	}
 }
+
+func Test_pullRequestNameParser_ParseWithPatterns(t *testing.T) {
+	patterns, err := gitnamesparser.CompilePatterns([]string{`US\d+`, `#(?P<code>\d+)`})
+	require.NoError(t, err)
+
+	header := pull_request_reader.PullRequestHeader{
+		PullRequestName: "US12345 New login page",
+		BranchName:      "feature/US12345-login",
+		CommitNames:     []string{"Fix #7", "GITRU-1 is not a unit code here"},
+	}
+	want := gitnames.PullRequestLinks{
+		Base: gitnames.Base{Description: "US12345 New login page", LinkedUnits: gitnames.LinkedUnits{{Code: "US12345"}}},
+		BranchLinks: gitnames.BranchLinks{
+			Base: gitnames.Base{Description: "feature/US12345-login", LinkedUnits: gitnames.LinkedUnits{{Code: "US12345"}}},
+		},
+		CommitsLinks: gitnames.CommitsLinks{
+			{Base: gitnames.Base{Description: "Fix #7", LinkedUnits: gitnames.LinkedUnits{{Code: "7"}}}},
+		},
+	}
+
+	got, err := NewParser().ParseWithPatterns(header, patterns)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -13,6 +13,9 @@ import (
 	jsoniter "github.com/json-iterator/go"
 )
 
+// RawMessage is a raw encoded JSON value, an alias of encoding/json RawMessage
+type RawMessage = json.RawMessage
+
 // Encoder represents an encoder for json
 type Encoder interface {
 	Encode(v interface{}) error
@@ -0,0 +1,227 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	sendersV2 "code.gitea.io/gitea/clients/kafka/senders/v2"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func (a *kafkaNotifier) NotifyPushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !setting.Kafka.Enabled {
+		return
+	}
+	options := sendersV2.NewPushSenderOptions(repo, getRepositoryTenant(ctx, repo), getExternalUserId(pusher), opts, commits)
+	if err := sendersV2.NewPushSender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending push event for repository %s/%s. Error: %v", repo.OwnerName, repo.Name, err)
+	}
+}
+
+func (a *kafkaNotifier) NotifyCreateRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refType, refFullName, refID string) {
+	if !setting.Kafka.Enabled {
+		return
+	}
+	refName := git.RefName(refFullName).ShortName()
+	options := sendersV2.NewCreateRefSenderOptions(repo, getRepositoryTenant(ctx, repo), getExternalUserId(doer), getRefType(refType), refName, refID)
+	if err := sendersV2.NewRefSender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending create %s %s event for repository %s/%s. Error: %v", refType, refName, repo.OwnerName, repo.Name, err)
+	}
+}
+
+func (a *kafkaNotifier) NotifyDeleteRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refType, refFullName string) {
+	if !setting.Kafka.Enabled {
+		return
+	}
+	refName := git.RefName(refFullName).ShortName()
+	options := sendersV2.NewDeleteRefSenderOptions(repo, getRepositoryTenant(ctx, repo), getExternalUserId(doer), getRefType(refType), refName)
+	if err := sendersV2.NewRefSender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending delete %s %s event for repository %s/%s. Error: %v", refType, refName, repo.OwnerName, repo.Name, err)
+	}
+}
+
+func (a *kafkaNotifier) NotifyNewPullRequest(ctx context.Context, pr *issues_model.PullRequest, mentions []*user_model.User) {
+	if !setting.Kafka.Enabled {
+		return
+	}
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("Error has occurred while loading issue of pull request %d. Error: %v", pr.ID, err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(ctx); err != nil {
+		log.Error("Error has occurred while loading poster of pull request %d. Error: %v", pr.ID, err)
+		return
+	}
+	sendPullRequestEvent(ctx, pr.Issue.Poster, pr, generic.CREATE)
+}
+
+func (a *kafkaNotifier) NotifyMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	if setting.Kafka.Enabled {
+		sendPullRequestEvent(ctx, doer, pr, generic.MERGE)
+	}
+}
+
+func (a *kafkaNotifier) NotifyAutoMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	if setting.Kafka.Enabled {
+		sendPullRequestEvent(ctx, doer, pr, generic.MERGE)
+	}
+}
+
+func (a *kafkaNotifier) NotifyIssueChangeStatus(ctx context.Context, doer *user_model.User, commitID string, issue *issues_model.Issue, actionComment *issues_model.Comment, closeOrReopen bool) {
+	// закрытие при слиянии отправляется через NotifyMergePullRequest, повторное открытие не отправляется
+	if !setting.Kafka.Enabled || !issue.IsPull || !closeOrReopen {
+		return
+	}
+	if err := issue.LoadPullRequest(ctx); err != nil {
+		log.Error("Error has occurred while loading pull request of issue %d. Error: %v", issue.ID, err)
+		return
+	}
+	if issue.PullRequest.HasMerged {
+		return
+	}
+	issue.PullRequest.Issue = issue
+	sendPullRequestEvent(ctx, doer, issue.PullRequest, generic.CLOSE)
+}
+
+func (a *kafkaNotifier) NotifyPullRequestReview(ctx context.Context, pr *issues_model.PullRequest, review *issues_model.Review, comment *issues_model.Comment, mentions []*user_model.User) {
+	if !setting.Kafka.Enabled {
+		return
+	}
+	if err := loadPullRequest(ctx, pr); err != nil {
+		log.Error("Error has occurred while loading pull request %d. Error: %v", pr.ID, err)
+		return
+	}
+	if err := review.LoadReviewer(ctx); err != nil {
+		log.Error("Error has occurred while loading reviewer of review %d. Error: %v", review.ID, err)
+		return
+	}
+	options := sendersV2.NewReviewSenderOptions(pr, review, getRepositoryTenant(ctx, pr.BaseRepo), getExternalUserId(review.Reviewer), getExternalUserId(pr.Issue.Poster))
+	if err := sendersV2.NewReviewSender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending review event for pull request %d. Error: %v", pr.ID, err)
+	}
+}
+
+func (a *kafkaNotifier) NotifyDeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	if !setting.Kafka.Enabled {
+		return
+	}
+	options := sendersV2.NewDeleteRepositorySenderOptions(repo, getRepositoryTenant(ctx, repo), getExternalUserId(doer))
+	if err := sendersV2.NewDeleteRepositorySender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending delete repository event for repository %s/%s. Error: %v", repo.OwnerName, repo.Name, err)
+	}
+}
+
+// sendTransferRepositoryEvent отправляет событие о передаче репозитория другому владельцу
+func sendTransferRepositoryEvent(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldOwnerName string) {
+	options := sendersV2.NewTransferRepositorySenderOptions(repo, getRepositoryTenant(ctx, repo), getExternalUserId(doer), oldOwnerName)
+	if err := sendersV2.NewTransferRepositorySender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending transfer repository event for repository %s/%s. Error: %v", repo.OwnerName, repo.Name, err)
+	}
+}
+
+// EnqueuePullRequestEvent сохраняет в outbox событие об открытии, слиянии или закрытии запроса на слияние.
+// Вызывается в транзакции, изменяющей запрос на слияние, поэтому событие отправляется, только если изменение сохранено.
+// Без outbox событие отправляется уведомителем после сохранения изменения
+func EnqueuePullRequestEvent(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, eventCode generic.ContextEventCode) error {
+	if !setting.Kafka.Enabled || !setting.Kafka.Outbox.Enabled {
+		return nil
+	}
+	if !db.InTransaction(ctx) {
+		return fmt.Errorf("%s event for pull request %d must be enqueued in a transaction", eventCode, pr.ID)
+	}
+	if err := loadPullRequest(ctx, pr); err != nil {
+		return fmt.Errorf("load pull request %d: %w", pr.ID, err)
+	}
+	options := sendersV2.NewPullRequestSenderOptions(pr, getRepositoryTenant(ctx, pr.BaseRepo), getExternalUserId(doer), getExternalUserId(pr.Issue.Poster), eventCode)
+	return sendersV2.NewPullRequestSender().Send(ctx, options)
+}
+
+// sendPullRequestEvent отправляет событие об открытии, слиянии или закрытии запроса на слияние.
+// С outbox событие уже сохранено в транзакции изменения через EnqueuePullRequestEvent
+func sendPullRequestEvent(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, eventCode generic.ContextEventCode) {
+	if setting.Kafka.Outbox.Enabled {
+		return
+	}
+	if err := loadPullRequest(ctx, pr); err != nil {
+		log.Error("Error has occurred while loading pull request %d. Error: %v", pr.ID, err)
+		return
+	}
+	options := sendersV2.NewPullRequestSenderOptions(pr, getRepositoryTenant(ctx, pr.BaseRepo), getExternalUserId(doer), getExternalUserId(pr.Issue.Poster), eventCode)
+	if err := sendersV2.NewPullRequestSender().Send(ctx, options); err != nil {
+		log.Error("Error has occurred while sending %s event for pull request %d. Error: %v", eventCode, pr.ID, err)
+	}
+}
+
+// loadPullRequest загружает задачу, автора, базовый репозиторий и последний коммит запроса на слияние
+func loadPullRequest(ctx context.Context, pr *issues_model.PullRequest) error {
+	if err := pr.LoadIssue(ctx); err != nil {
+		return fmt.Errorf("load issue: %w", err)
+	}
+	if err := pr.Issue.LoadPoster(ctx); err != nil {
+		return fmt.Errorf("load poster: %w", err)
+	}
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return fmt.Errorf("load base repository: %w", err)
+	}
+	if pr.HeadCommitID != "" {
+		return nil
+	}
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("open base repository: %w", err)
+	}
+	defer closer.Close()
+	if pr.HeadCommitID, err = gitRepo.GetRefCommitID(pr.GetGitRefName()); err != nil {
+		return fmt.Errorf("get head commit: %w", err)
+	}
+	return nil
+}
+
+// getRepositoryTenant возвращает тенант репозитория, если включена ролевая модель.
+// Событие отправляется и без тенанта, поэтому ошибка только логируется
+func getRepositoryTenant(ctx context.Context, repo *repo_model.Repository) *tenant.ScTenant {
+	if !setting.SourceControl.TenantWithRoleModeEnabled {
+		return nil
+	}
+	tenantId, err := tenant.GetTenantByOrgIdOrDefault(ctx, repo.OwnerID)
+	if err != nil {
+		log.Error("Error has occurred while getting tenant of repository %s/%s. Error: %v", repo.OwnerName, repo.Name, err)
+		return nil
+	}
+	tenantById, err := tenant.GetTenantByID(ctx, tenantId)
+	if err != nil {
+		log.Error("Error has occurred while getting tenant %s. Error: %v", tenantId, err)
+		return nil
+	}
+	return tenantById
+}
+
+// getExternalUserId возвращает идентификатор пользователя во внешней системе
+func getExternalUserId(user *user_model.User) string {
+	if user == nil {
+		return ""
+	}
+	// Используем login как ID пользователя во внешней системе
+	if user.LoginName != "" {
+		return user.LoginName
+	}
+	return user.Name // на случай локальной авторизации
+}
+
+// getRefType возвращает тип ссылки для события
+func getRefType(refType string) events.RefPayloadRefType {
+	if refType == "tag" {
+		return events.Tag
+	}
+	return events.Branch
+}
@@ -113,6 +113,8 @@ func (a *kafkaNotifier) NotifyForkRepository(ctx context.Context, doer *user_mod
 
 func (a *kafkaNotifier) NotifyTransferRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldOwnerName string) {
 	if setting.Kafka.Enabled {
+		sendTransferRepositoryEvent(ctx, doer, repo, oldOwnerName)
+
 		createRepositorySenderV1 := sendersV1.NewCreateRepositorySender()
 		createRepositorySenderV2 := sendersV2.NewCreateRepositorySender()
 		auditParams := map[string]string{
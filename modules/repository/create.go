@@ -23,6 +23,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
+	tenant_model "code.gitea.io/gitea/models/tenant"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
@@ -55,6 +56,13 @@ func CreateRepositoryByExample(ctx context.Context, doer, u *user_model.User, re
 		}
 	}
 
+	// квота тенанта на количество репозиториев проверяется для всех способов создания: web, API, миграции и форка
+	if u.IsOrganization() {
+		if err = tenant_model.CheckTenantQuotaByOrgID(ctx, u.ID, tenant_model.QuotaRepoCount, 1); err != nil {
+			return err
+		}
+	}
+
 	if err = db.Insert(ctx, repo); err != nil {
 		return err
 	}
@@ -305,8 +313,8 @@ func CreateRepository(doer, u *user_model.User, opts CreateRepoOptions) (*repo_m
 
 const notRegularFileMode = os.ModeSymlink | os.ModeNamedPipe | os.ModeSocket | os.ModeDevice | os.ModeCharDevice | os.ModeIrregular
 
-// getDirectorySize returns the disk consumption for a given path
-func getDirectorySize(path string) (int64, error) {
+// GetDirectorySize returns the disk consumption for a given path
+func GetDirectorySize(path string) (int64, error) {
 	var size int64
 	err := filepath.WalkDir(path, func(_ string, info os.DirEntry, err error) error {
 		if err != nil {
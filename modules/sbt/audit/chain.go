@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Поля цепочки хешей в сообщении события аудита
+const (
+	ChainSeqField       = "seq"                  // порядковый номер сообщения в цепочке узла
+	ChainPrevHashField  = "prev_hash"            // хеш предыдущего сообщения цепочки
+	ChainHashField      = "hash"                 // хеш сообщения, вычисляется по всем остальным полям
+	ChainSignatureField = "checkpoint_signature" // подпись контрольной точки
+
+	chainHostField = "host.name" // цепочка ведется отдельно для каждого узла
+	eventField     = "event"
+)
+
+// hashChain цепочка хешей событий аудита. Каждое сообщение содержит номер и хеш предыдущего сообщения,
+// поэтому удаление или изменение сообщения обнаруживается при проверке цепочки
+type hashChain struct {
+	mu        sync.Mutex
+	enabled   bool
+	statePath string
+	secret    []byte
+	seq       uint64
+	prevHash  string
+}
+
+var chain = &hashChain{}
+
+// EnableChain включает цепочку хешей событий аудита. Номер и хеш последнего сообщения хранятся в файле statePath,
+// чтобы цепочка продолжалась после перезапуска. secret используется для подписи контрольных точек
+func EnableChain(statePath string, secret []byte) error {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	content, err := os.ReadFile(statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read audit chain state: %w", err)
+	}
+	if err == nil {
+		if _, err = fmt.Sscan(string(content), &chain.seq, &chain.prevHash); err != nil {
+			return fmt.Errorf("parse audit chain state: %w", err)
+		}
+	}
+	if err = os.MkdirAll(filepath.Dir(statePath), 0o750); err != nil {
+		return fmt.Errorf("create audit chain state directory: %w", err)
+	}
+	chain.statePath = statePath
+	chain.secret = secret
+	chain.enabled = true
+	return nil
+}
+
+// WriteChainCheckpoint отправляет подписанную контрольную точку цепочки хешей
+func WriteChainCheckpoint() error {
+	chain.mu.Lock()
+	enabled, signed := chain.enabled, len(chain.secret) > 0
+	chain.mu.Unlock()
+	if !enabled {
+		return errors.New("audit chain is disabled")
+	}
+	if !signed {
+		return errors.New("audit chain secret is not configured")
+	}
+
+	eventMessage := createEvent(AuditChainCheckpointEvent, EmptyRequiredField, EmptyRequiredField, StatusSuccess, EmptyRequiredField, nil)
+	if eventMessage == nil {
+		return errors.New("cannot create audit chain checkpoint")
+	}
+	eventMessage.checkpoint = true
+	eventMessage.Send(true)
+	return nil
+}
+
+// link добавляет в поля сообщения номер, хеш предыдущего сообщения и хеш самого сообщения.
+// Вызывается под блокировкой цепочки, порядок записи сообщений с номерами совпадает благодаря deliveryQueue
+func (c *hashChain) link(fields map[string]string, checkpoint bool) error {
+	seq := c.seq + 1
+	fields[ChainSeqField] = strconv.FormatUint(seq, 10)
+	fields[ChainPrevHashField] = c.prevHash
+	if checkpoint {
+		fields[ChainSignatureField] = SignChainCheckpoint(c.secret, fields[chainHostField], seq, c.prevHash)
+	}
+	hash, err := ChainHash(fields)
+	if err != nil {
+		return err
+	}
+	fields[ChainHashField] = hash
+	c.seq, c.prevHash = seq, hash
+
+	// сообщение отправляется даже если состояние не сохранено, при перезапуске проверка покажет разрыв цепочки
+	tmp := c.statePath + ".tmp"
+	if err = os.WriteFile(tmp, []byte(fields[ChainSeqField]+" "+hash), 0o640); err == nil {
+		err = os.Rename(tmp, c.statePath)
+	}
+	if err != nil {
+		log.Error("Error has occurred while saving audit chain state to %s. Error: %v", c.statePath, err)
+	}
+	return nil
+}
+
+// ChainHash вычисляет хеш сообщения события аудита по всем полям, кроме поля хеша
+func ChainHash(fields map[string]string) (string, error) {
+	content := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if key != ChainHashField {
+			content[key] = value
+		}
+	}
+	// ключи сериализуются в отсортированном порядке
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("marshal audit event for hash: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SignChainCheckpoint вычисляет подпись контрольной точки цепочки узла host
+func SignChainCheckpoint(secret []byte, host string, seq uint64, prevHash string) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = fmt.Fprintf(mac, "%s:%d:%s", host, seq, prevHash)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,86 @@
+//go:build !correct
+
+package audit
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// linkedRecords создает сообщения, связанные цепочкой хешей, последнее сообщение является контрольной точкой
+func linkedRecords(t *testing.T, c *hashChain, count int) [][]byte {
+	records := make([][]byte, 0, count)
+	for i := 1; i <= count; i++ {
+		fields := map[string]string{"id": strconv.Itoa(i), "event": "Create user", "host.name": "sc-1"}
+		checkpoint := i == count
+		if checkpoint {
+			fields["event"] = AuditChainCheckpointEvent.String()
+		}
+		assert.NoError(t, c.link(fields, checkpoint))
+		data, err := json.Marshal(fields)
+		assert.NoError(t, err)
+		records = append(records, data)
+	}
+	return records
+}
+
+func verifyRecords(secret string, records [][]byte) *ChainReport {
+	verifier := NewChainVerifier([]byte(secret))
+	for idx, record := range records {
+		verifier.Add(strconv.Itoa(idx+1), record)
+	}
+	return verifier.Report()
+}
+
+// TestHashChain проверяет связывание сообщений и обнаружение нарушений цепочки
+func TestHashChain(t *testing.T) {
+	c := &hashChain{statePath: filepath.Join(t.TempDir(), "audit.chain"), secret: []byte("secret")}
+	records := linkedRecords(t, c, 5)
+
+	report := verifyRecords("secret", append([][]byte{[]byte(`{"id":"0","event":"Create user"}`)}, records...))
+	assert.True(t, report.OK(), report.Issues)
+	assert.Equal(t, 6, report.Records)
+	assert.Equal(t, 5, report.Chained)
+	assert.Equal(t, 1, report.Unchained)
+	assert.Equal(t, 1, report.Checkpoints)
+
+	// состояние сохраняется для продолжения цепочки после перезапуска
+	assert.NoError(t, EnableChain(c.statePath, nil))
+	assert.Equal(t, uint64(5), chain.seq)
+	assert.Equal(t, c.prevHash, chain.prevHash)
+	chain = &hashChain{}
+
+	// без секрета контрольные точки не проверяются
+	report = verifyRecords("", records)
+	assert.True(t, report.OK())
+	assert.Equal(t, 1, report.Unverified)
+
+	report = verifyRecords("other", records)
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, ChainIssueBadSignature, report.Issues[0].Type)
+
+	altered := append([][]byte(nil), records...)
+	altered[1] = []byte(string(records[1][:len(records[1])-1]) + `,"user":"admin"}`)
+	report = verifyRecords("secret", altered)
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, ChainIssueAltered, report.Issues[0].Type)
+	assert.Equal(t, uint64(2), report.Issues[0].Seq)
+
+	report = verifyRecords("secret", append(append([][]byte(nil), records[:1]...), records[3:]...))
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, ChainIssueGap, report.Issues[0].Type)
+	assert.Equal(t, "events 2-3 are missing", report.Issues[0].Message)
+
+	report = verifyRecords("secret", append(append([][]byte(nil), records...), records[0]))
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, ChainIssueRestart, report.Issues[0].Type)
+
+	report = verifyRecords("secret", [][]byte{[]byte("not json")})
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, ChainIssueInvalid, report.Issues[0].Type)
+}
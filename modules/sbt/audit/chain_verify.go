@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// ChainIssueType тип нарушения цепочки хешей
+type ChainIssueType string
+
+const (
+	ChainIssueInvalid      ChainIssueType = "invalid"       // запись не является сообщением аудита
+	ChainIssueAltered      ChainIssueType = "altered"       // хеш сообщения не совпадает с содержимым
+	ChainIssueBrokenLink   ChainIssueType = "broken_link"   // хеш предыдущего сообщения не совпадает
+	ChainIssueGap          ChainIssueType = "gap"           // пропущены сообщения
+	ChainIssueOrder        ChainIssueType = "order"         // сообщение повторяется или нарушен порядок
+	ChainIssueRestart      ChainIssueType = "restart"       // цепочка начата заново, например после потери состояния
+	ChainIssueBadSignature ChainIssueType = "bad_signature" // подпись контрольной точки не совпадает
+)
+
+// ChainIssue нарушение цепочки хешей
+type ChainIssue struct {
+	Source  string         `json:"source"` // файл и строка или таблица и идентификатор записи
+	Host    string         `json:"host,omitempty"`
+	Seq     uint64         `json:"seq,omitempty"`
+	Type    ChainIssueType `json:"type"`
+	Message string         `json:"message"`
+}
+
+// ChainReport результат проверки цепочки хешей
+type ChainReport struct {
+	Records     int          `json:"records"`
+	Chained     int          `json:"chained"`
+	Unchained   int          `json:"unchained"`   // сообщения, записанные до включения цепочки
+	Checkpoints int          `json:"checkpoints"` // проверенные контрольные точки
+	Unverified  int          `json:"unverified"`  // контрольные точки, которые не проверены из-за отсутствия секрета
+	Issues      []ChainIssue `json:"issues"`
+}
+
+// OK проверяет, что нарушений цепочки не найдено
+func (r *ChainReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// chainPosition последнее проверенное сообщение цепочки узла
+type chainPosition struct {
+	seq  uint64
+	hash string
+}
+
+// ChainVerifier проверка цепочки хешей событий аудита. Сообщения передаются в порядке записи,
+// цепочка каждого узла проверяется отдельно
+type ChainVerifier struct {
+	secret []byte
+	last   map[string]*chainPosition
+	report ChainReport
+}
+
+// NewChainVerifier создает проверку цепочки хешей, без секрета подписи контрольных точек не проверяются
+func NewChainVerifier(secret []byte) *ChainVerifier {
+	return &ChainVerifier{
+		secret: secret,
+		last:   make(map[string]*chainPosition),
+		report: ChainReport{Issues: make([]ChainIssue, 0)},
+	}
+}
+
+// Add проверяет очередное сообщение. source описывает положение сообщения для отчета
+func (v *ChainVerifier) Add(source string, record []byte) {
+	v.report.Records++
+
+	fields := make(map[string]string)
+	if err := json.Unmarshal(bytes.TrimSpace(record), &fields); err != nil {
+		v.AddIssue(ChainIssue{Source: source, Type: ChainIssueInvalid, Message: fmt.Sprintf("cannot parse audit event: %v", err)})
+		return
+	}
+	if _, ok := fields[ChainSeqField]; !ok {
+		v.report.Unchained++
+		return
+	}
+	v.report.Chained++
+
+	host := fields[chainHostField]
+	seq, err := strconv.ParseUint(fields[ChainSeqField], 10, 64)
+	if err != nil || seq == 0 {
+		v.AddIssue(ChainIssue{Source: source, Host: host, Type: ChainIssueInvalid, Message: fmt.Sprintf("invalid sequence number %q", fields[ChainSeqField])})
+		return
+	}
+	issue := func(issueType ChainIssueType, format string, args ...interface{}) {
+		v.AddIssue(ChainIssue{Source: source, Host: host, Seq: seq, Type: issueType, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if hash, err := ChainHash(fields); err != nil || hash != fields[ChainHashField] {
+		issue(ChainIssueAltered, "event hash does not match its content")
+	}
+
+	prevHash := fields[ChainPrevHashField]
+	last := v.last[host]
+	switch {
+	case last == nil:
+		// первое сообщение узла может быть в середине цепочки, если начало лога удалено ротацией
+		if seq == 1 && prevHash != "" {
+			issue(ChainIssueBrokenLink, "first event of the chain refers to previous event")
+		}
+	case seq == last.seq+1:
+		if prevHash != last.hash {
+			issue(ChainIssueBrokenLink, "previous hash does not match event %d", last.seq)
+		}
+	case seq == 1:
+		issue(ChainIssueRestart, "chain restarted after event %d", last.seq)
+	case seq <= last.seq:
+		issue(ChainIssueOrder, "event follows event %d", last.seq)
+	default:
+		issue(ChainIssueGap, "events %d-%d are missing", last.seq+1, seq-1)
+	}
+
+	if fields[eventField] == AuditChainCheckpointEvent.String() {
+		if len(v.secret) == 0 {
+			v.report.Unverified++
+		} else {
+			v.report.Checkpoints++
+			expected := SignChainCheckpoint(v.secret, host, seq, prevHash)
+			if !hmac.Equal([]byte(expected), []byte(fields[ChainSignatureField])) {
+				issue(ChainIssueBadSignature, "checkpoint signature is invalid")
+			}
+		}
+	}
+
+	v.last[host] = &chainPosition{seq: seq, hash: fields[ChainHashField]}
+}
+
+// AddIssue добавляет в отчет нарушение, найденное вне цепочки, например расхождение записи в БД с исходным сообщением
+func (v *ChainVerifier) AddIssue(issue ChainIssue) {
+	v.report.Issues = append(v.report.Issues, issue)
+}
+
+// Report возвращает результат проверки
+func (v *ChainVerifier) Report() *ChainReport {
+	return &v.report
+}
@@ -214,6 +214,52 @@ const (
 	SonarSettingsCreateEvent
 	SonarSettingsUpdateEvent
 	SonarSettingsDeleteEvent
+
+	// События жизненного цикла тенанта
+	TenantArchiveEvent           // Тенант архивирован
+	TenantAccessDeniedEvent      // Доступ к ресурсу деактивированного тенанта запрещен
+	TenantRepositoryArchiveEvent // Репозиторий архивирован вместе с тенантом
+	TenantQuotaUpdateEvent       // Квоты тенанта изменены
+
+	// События ролей ролевой модели
+	RoleCreateEvent // Роль создана
+	RoleUpdateEvent // Роль изменена
+	RoleDeleteEvent // Роль удалена
+
+	// События декларативного управления привилегиями
+	PrivilegesStateApplyEvent // Описание привилегий применено
+
+	// События журнала аудита
+	AuditLogSearchEvent // Выполнен поиск по журналу аудита
+	AuditLogExportEvent // Журнал аудита выгружен в CSV
+
+	// События цепочки хешей аудита
+	AuditChainCheckpointEvent // Контрольная точка цепочки хешей
+
+	// События отправки в Kafka через outbox
+	KafkaOutboxMessageFailEvent  // Событие Kafka не отправлено после всех попыток
+	KafkaOutboxMessageRetryEvent // Ошибочное событие Kafka возвращено в очередь
+	KafkaOutboxMessageSkipEvent  // Ошибочное событие Kafka пропущено
+
+	// События настройки трекеров задач
+	TaskTrackerBindingUpdateEvent // Трекер задач тенанта или репозитория изменен
+	TaskTrackerBindingDeleteEvent // Настройка трекера задач тенанта или репозитория удалена
+	UnitCodePatternsUpdateEvent   // Шаблоны кодов юнитов тенанта или репозитория изменены
+	UnitCodePatternsDeleteEvent   // Шаблоны кодов юнитов тенанта или репозитория удалены
+	RefLinksAddEvent              // Отправлена привязка юнитов к коммиту или ветке
+	RefLinksDeleteEvent           // Отправлена отвязка юнитов от коммита или ветки
+
+	// События фоновых задач, сохраненных в БД
+	BackgroundTaskDeadEvent    // Задача не выполнена после всех попыток
+	BackgroundTaskRetryEvent   // Задача возвращена в очередь администратором
+	BackgroundTaskDiscardEvent // Задача удалена администратором
+
+	// События настройки внешних quality gate
+	QualityGateSettingsUpdateEvent // Настройки провайдера quality gate репозитория изменены
+	QualityGateSettingsDeleteEvent // Настройки провайдера quality gate репозитория удалены
+
+	// События приема webhook SonarQube
+	SonarWebhookRejectEvent // Webhook SonarQube отклонен: неверная подпись, устаревший анализ или неизвестный сервер
 )
 
 // Описание событий
@@ -378,6 +424,32 @@ var events = map[Event]string{
 	SonarSettingsCreateEvent:                  "Create sonar settings",
 	SonarSettingsUpdateEvent:                  "Update sonar settings",
 	SonarSettingsDeleteEvent:                  "Delete sonar settings",
+	TenantArchiveEvent:                        "Archive tenant",
+	TenantAccessDeniedEvent:                   "Access to deactivated tenant denied",
+	TenantRepositoryArchiveEvent:              "Archive tenant repository",
+	TenantQuotaUpdateEvent:                    "Update tenant quotas",
+	RoleCreateEvent:                           "Create role",
+	RoleUpdateEvent:                           "Update role",
+	RoleDeleteEvent:                           "Delete role",
+	PrivilegesStateApplyEvent:                 "Apply privileges state",
+	AuditLogSearchEvent:                       "Search audit log",
+	AuditLogExportEvent:                       "Export audit log",
+	AuditChainCheckpointEvent:                 "Audit chain checkpoint",
+	KafkaOutboxMessageFailEvent:               "Kafka outbox message failed",
+	KafkaOutboxMessageRetryEvent:              "Retry Kafka outbox message",
+	KafkaOutboxMessageSkipEvent:               "Skip Kafka outbox message",
+	TaskTrackerBindingUpdateEvent:             "Update task tracker binding",
+	TaskTrackerBindingDeleteEvent:             "Delete task tracker binding",
+	UnitCodePatternsUpdateEvent:               "Update unit code patterns",
+	UnitCodePatternsDeleteEvent:               "Delete unit code patterns",
+	RefLinksAddEvent:                          "Send commit or branch unit binding event to task tracker",
+	RefLinksDeleteEvent:                       "Send commit or branch unit binding removal event to task tracker",
+	BackgroundTaskDeadEvent:                   "Background task failed",
+	BackgroundTaskRetryEvent:                  "Retry background task",
+	BackgroundTaskDiscardEvent:                "Discard background task",
+	QualityGateSettingsUpdateEvent:            "Update quality gate settings",
+	QualityGateSettingsDeleteEvent:            "Delete quality gate settings",
+	SonarWebhookRejectEvent:                   "Reject sonar webhook",
 }
 
 // String возвращает описание событий
@@ -25,10 +25,11 @@ type message struct {
 	HostIp        string            `json:"host.ip"`
 	FormatVersion string            `json:"format_version"`
 	Params        map[string]string `json:"-"`
+	checkpoint    bool              // сообщение является контрольной точкой цепочки хешей
 }
 
-// MarshalJson функция для преобразования структуры message в "плоский" json
-func (e *message) MarshalJson() ([]byte, error) {
+// fields функция для преобразования структуры message в "плоский" набор полей
+func (e *message) fields() (map[string]string, error) {
 	messageFieldMap := make(map[string]string, 0)
 	messageBytes, _ := json.Marshal(e)
 	err := json.Unmarshal(messageBytes, &messageFieldMap)
@@ -41,19 +42,40 @@ func (e *message) MarshalJson() ([]byte, error) {
 			messageFieldMap[k] = v
 		}
 	}
-
-	return json.Marshal(messageFieldMap)
+	return messageFieldMap, nil
 }
 
-// Send функция вывода сообщения события аудита
+// Send функция вывода сообщения события аудита. Событие также передается внешним получателям аудита.
+// Если включена цепочка хешей, сообщение связывается с предыдущим. Сообщения записываются в порядке номеров цепочки
 func (e message) Send(onlyFile bool) {
-	bytes, err := e.MarshalJson()
+	messageFieldMap, err := e.fields()
 	if err != nil {
 		log.Error("Error marshaling audit params: %s, error: %v", e, err)
 		return
 	}
 
-	auditionBytesMessage(bytes)
+	chain.mu.Lock()
+	if chain.enabled {
+		if err = chain.link(messageFieldMap, e.checkpoint); err != nil {
+			chain.mu.Unlock()
+			log.Error("Error has occurred while linking audit event %s to chain, error: %v", e.Id, err)
+			return
+		}
+	}
+	bytes, err := json.Marshal(messageFieldMap)
+	if err != nil {
+		chain.mu.Unlock()
+		log.Error("Error marshaling audit params: %s, error: %v", e, err)
+		return
+	}
+	ticket := delivery.reserve()
+	chain.mu.Unlock()
+
+	delivery.deliver(ticket, func() {
+		auditionBytesMessage(bytes)
+		writeToSinks(bytes)
+	})
+
 	if !onlyFile {
 		fmt.Println(fmt.Sprintf(string(bytes)))
 	}
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Sink получатель событий аудита во внешней системе.
+// Write должен сохранить событие для последующей доставки и не блокировать вызывающего на время отправки
+type Sink interface {
+	Name() string
+	Write(event []byte) error
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// deliveryQueue упорядочивает запись сообщений в лог и получателям. Номер выдается под блокировкой цепочки хешей,
+// а запись выполняется уже без нее строго по номерам, поэтому ожидание получателя не блокирует связывание сообщений
+type deliveryQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	next uint64 // номер, который получит следующее сообщение
+	turn uint64 // номер сообщения, которое записывается сейчас
+}
+
+var delivery = newDeliveryQueue()
+
+func newDeliveryQueue() *deliveryQueue {
+	q := &deliveryQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// reserve выдает номер очередного сообщения. Каждый выданный номер должен быть передан в deliver
+func (q *deliveryQueue) reserve() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ticket := q.next
+	q.next++
+	return ticket
+}
+
+// deliver дожидается записи предыдущих сообщений и вызывает write для сообщения с номером ticket
+func (q *deliveryQueue) deliver(ticket uint64, write func()) {
+	q.mu.Lock()
+	for q.turn != ticket {
+		q.cond.Wait()
+	}
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.turn++
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}()
+	write()
+}
+
+// RegisterSink добавляет получателя событий аудита
+func RegisterSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, sink)
+}
+
+// writeToSinks передает событие всем зарегистрированным получателям
+func writeToSinks(event []byte) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, sink := range sinks {
+		if err := sink.Write(event); err != nil {
+			log.Error("Error has occurred while writing audit event to sink %s, event: %s, error: %v", sink.Name(), event, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build !correct
+
+package audit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeliveryQueue проверяет, что сообщения записываются по номерам, а ожидание записи не мешает выдаче номеров
+func TestDeliveryQueue(t *testing.T) {
+	q := newDeliveryQueue()
+	release := make(chan struct{})
+	var (
+		mu      sync.Mutex
+		written []uint64
+		wg      sync.WaitGroup
+	)
+	write := func(ticket uint64) func() {
+		return func() {
+			if ticket == 0 {
+				<-release
+			}
+			mu.Lock()
+			written = append(written, ticket)
+			mu.Unlock()
+		}
+	}
+
+	first := q.reserve()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.deliver(first, write(first))
+	}()
+
+	tickets := []uint64{q.reserve(), q.reserve(), q.reserve()}
+	for i := len(tickets) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(ticket uint64) {
+			defer wg.Done()
+			q.deliver(ticket, write(ticket))
+		}(tickets[i])
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []uint64{0, 1, 2, 3}, written)
+}
@@ -0,0 +1,61 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// httpTransport отправка событий аудита пачками в виде json массива
+type httpTransport struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// newHTTPTransport создает отправителя событий аудита по HTTP
+func newHTTPTransport(config setting.AuditHTTPSink) *httpTransport {
+	return &httpTransport{
+		client: &http.Client{Timeout: config.Timeout},
+		url:    config.URL,
+		token:  config.Token,
+	}
+}
+
+// Send отправляет пачку событий одним запросом, успешным считается ответ 2xx
+func (t *httpTransport) Send(ctx context.Context, events [][]byte) error {
+	body := make([]byte, 0, 2+len(events)*512)
+	body = append(body, '[')
+	body = append(body, bytes.Join(events, []byte{','})...)
+	body = append(body, ']')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close освобождает соединения клиента
+func (t *httpTransport) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
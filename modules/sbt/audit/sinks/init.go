@@ -0,0 +1,58 @@
+package sinks
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Register создает получателя событий аудита со спулом и запускает доставку событий в транспорт
+func Register(name string, batchSize int, t Transport) error {
+	worker, err := newSinkWorker(name, t, workerOptions{
+		SpoolPath:         setting.AuditSinks.SpoolPath,
+		MaxSpoolSize:      setting.AuditSinks.MaxSpoolSize,
+		SpoolSyncInterval: setting.AuditSinks.SpoolSyncInterval,
+		SpoolFullPolicy:   setting.AuditSinks.SpoolFullPolicy,
+		SpoolBlockTimeout: setting.AuditSinks.SpoolBlockTimeout,
+		BatchSize:         batchSize,
+		RetryBackoff:      setting.AuditSinks.RetryBackoff,
+		MaxRetryBackoff:   setting.AuditSinks.MaxRetryBackoff,
+	})
+	if err != nil {
+		log.Error("Error has occurred while creating audit sink %s. Error: %v", name, err)
+		return err
+	}
+	audit.RegisterSink(worker)
+	go graceful.GetManager().RunWithShutdownContext(worker.run)
+	graceful.GetManager().RunAtTerminate(worker.close)
+	log.Info("Audit sink %s initialized", name)
+	return nil
+}
+
+// Init создает включенных в конфигурации получателей событий аудита и запускает доставку событий
+func Init(ctx context.Context) error {
+	if setting.AuditSinks.Kafka.Enabled {
+		t, err := newKafkaTransport(ctx, setting.AuditSinks.Kafka)
+		if err != nil {
+			log.Error("Error has occurred while creating audit Kafka sink. Error: %v", err)
+			return err
+		}
+		if err = Register("kafka", setting.AuditSinks.Kafka.BatchSize, t); err != nil {
+			return err
+		}
+	}
+	if setting.AuditSinks.Syslog.Enabled {
+		if err := Register("syslog", setting.AuditSinks.Syslog.BatchSize, newSyslogTransport(setting.AuditSinks.Syslog)); err != nil {
+			return err
+		}
+	}
+	if setting.AuditSinks.HTTP.Enabled {
+		if err := Register("http", setting.AuditSinks.HTTP.BatchSize, newHTTPTransport(setting.AuditSinks.HTTP)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"code.gitea.io/gitea/clients/kafka"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// kafkaTransport отправка событий аудита в топик Kafka.
+// Ключом сообщения является идентификатор события, поэтому события распределяются по партициям,
+// а повторно доставленные события получатель может отбросить по ключу
+type kafkaTransport struct {
+	topic *kafka.Topic
+}
+
+// newKafkaTransport создает отправителя событий аудита в Kafka через общий клиент Kafka
+func newKafkaTransport(ctx context.Context, config setting.AuditKafkaSink) (*kafkaTransport, error) {
+	if !setting.Kafka.Enabled {
+		return nil, fmt.Errorf("audit Kafka sink requires enabled [kafka]")
+	}
+	return &kafkaTransport{
+		topic: kafka.NewTopic(ctx, true, config.Topic, string(kafka.Produce)),
+	}, nil
+}
+
+// Send отправляет события по одному в порядке спула
+func (t *kafkaTransport) Send(_ context.Context, events [][]byte) error {
+	for _, event := range events {
+		if err := t.topic.Produce(&sarama.ProducerMessage{Key: eventKey(event), Value: sarama.ByteEncoder(event)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventKey возвращает ключ сообщения Kafka по идентификатору события, событие без идентификатора отправляется без ключа
+func eventKey(event []byte) sarama.Encoder {
+	var header struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(event, &header); err != nil || header.ID == "" {
+		return nil
+	}
+	return sarama.StringEncoder(header.ID)
+}
+
+// Close закрывает писателя топика
+func (t *kafkaTransport) Close() error {
+	t.topic.Close()
+	return nil
+}
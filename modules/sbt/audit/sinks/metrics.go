@@ -0,0 +1,37 @@
+package sinks
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Статусы событий в метрике доставки
+const (
+	statusDelivered = "delivered"
+	statusFailed    = "failed"
+	statusDropped   = "dropped"
+)
+
+var (
+	eventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sourcecontrol_audit_sink_events_total",
+			Help: "Number of audit events by sink and delivery status: delivered, failed (delivery attempt failed and will be retried), dropped (event was not saved to spool)",
+		},
+		[]string{"sink", "status"},
+	)
+	spoolBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sourcecontrol_audit_sink_spool_bytes",
+			Help: "Size of audit events waiting for delivery in the sink spool",
+		},
+		[]string{"sink"},
+	)
+	lastDelivery = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sourcecontrol_audit_sink_last_delivery_timestamp_seconds",
+			Help: "Time of the last successful delivery of audit events to the sink",
+		},
+		[]string{"sink"},
+	)
+)
@@ -0,0 +1,394 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	spoolSegmentExt         = ".seg"
+	spoolOffsetFile         = "offset"
+	defaultSpoolSegmentSize = 16 << 20
+)
+
+var (
+	// errSpoolFull спул достиг максимального размера, событие не сохранено
+	errSpoolFull = errors.New("audit spool is full")
+	// errSpoolClosed спул закрыт, событие не сохранено
+	errSpoolClosed = errors.New("audit spool is closed")
+)
+
+// spool очередь событий аудита на диске.
+// События хранятся построчно в сегментах, позиция чтения сохраняется в файл offset после подтверждения доставки,
+// поэтому неотправленные события переживают перезапуск. Запись сбрасывается на диск после каждого события
+// или не реже syncInterval
+type spool struct {
+	mu           sync.Mutex
+	dir          string
+	maxSize      int64
+	segmentSize  int64
+	syncInterval time.Duration
+
+	dirty    bool
+	lastSync time.Time
+	closed   bool
+	freed    chan struct{} // закрывается, когда доставка освобождает место в спуле
+
+	writeSegment uint64
+	writeFile    *os.File
+	writeSize    int64
+	partialWrite bool // после неудачной записи в конце сегмента может остаться часть строки
+
+	readSegment uint64
+	readOffset  int64
+
+	size int64
+}
+
+// spoolBatch пачка событий, прочитанная из спула
+type spoolBatch struct {
+	records       [][]byte
+	startSegment  uint64
+	nextSegment   uint64
+	nextOffset    int64
+	consumedBytes int64
+}
+
+// moved проверяет, что позиция чтения изменилась
+func (b *spoolBatch) moved() bool {
+	return b.consumedBytes > 0 || b.nextSegment != b.startSegment
+}
+
+// openSpool открывает спул в директории dir, незавершенная запись в конце последнего сегмента отбрасывается
+func openSpool(dir string, maxSize int64, syncInterval time.Duration) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create spool directory: %w", err)
+	}
+	s := &spool{dir: dir, maxSize: maxSize, segmentSize: defaultSpoolSegmentSize, syncInterval: syncInterval, freed: make(chan struct{})}
+
+	segments, err := s.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.readOffsetFile(); err != nil {
+		return nil, err
+	}
+
+	for _, segment := range segments {
+		if segment < s.readSegment {
+			if err = os.Remove(s.segmentPath(segment)); err != nil {
+				return nil, fmt.Errorf("remove consumed segment: %w", err)
+			}
+		}
+	}
+	segments, err = s.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		if s.readSegment == 0 {
+			s.readSegment = 1
+		}
+		s.readOffset = 0
+		segments = []uint64{s.readSegment}
+	} else if segments[0] > s.readSegment {
+		s.readSegment, s.readOffset = segments[0], 0
+	}
+
+	s.writeSegment = segments[len(segments)-1]
+	if err = s.repairSegment(s.writeSegment); err != nil {
+		return nil, err
+	}
+	if s.writeFile, err = os.OpenFile(s.segmentPath(s.writeSegment), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640); err != nil {
+		return nil, fmt.Errorf("open spool segment: %w", err)
+	}
+
+	for _, segment := range segments {
+		info, err := os.Stat(s.segmentPath(segment))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("stat spool segment: %w", err)
+		}
+		if segment == s.writeSegment {
+			s.writeSize = info.Size()
+		}
+		s.size += info.Size()
+	}
+	s.size -= s.readOffset
+	return s, nil
+}
+
+// append добавляет событие в конец спула. При syncInterval = 0 событие сбрасывается на диск до возврата
+func (s *spool) append(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errSpoolClosed
+	}
+	if bytes.IndexByte(record, '\n') >= 0 {
+		return fmt.Errorf("audit event contains line break")
+	}
+	if s.size+int64(len(record))+1 > s.maxSize {
+		return errSpoolFull
+	}
+	if s.partialWrite {
+		if err := s.discardPartialWrite(); err != nil {
+			return err
+		}
+	}
+	if s.writeSize >= s.segmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	line := make([]byte, 0, len(record)+1)
+	line = append(line, record...)
+	line = append(line, '\n')
+	if _, err := s.writeFile.Write(line); err != nil {
+		// часть строки без перевода строки склеилась бы со следующим событием в одну запись
+		s.partialWrite = true
+		if discardErr := s.discardPartialWrite(); discardErr != nil {
+			return fmt.Errorf("write spool segment: %w, %v", err, discardErr)
+		}
+		return fmt.Errorf("write spool segment: %w", err)
+	}
+	s.writeSize += int64(len(line))
+	s.size += int64(len(line))
+	s.dirty = true
+	if s.syncInterval <= 0 || time.Since(s.lastSync) >= s.syncInterval {
+		return s.syncLocked()
+	}
+	return nil
+}
+
+// discardPartialWrite обрезает текущий сегмент до конца последнего полностью записанного события
+func (s *spool) discardPartialWrite() error {
+	if err := s.writeFile.Truncate(s.writeSize); err != nil {
+		return fmt.Errorf("truncate spool segment: %w", err)
+	}
+	if _, err := s.writeFile.Seek(s.writeSize, io.SeekStart); err != nil {
+		return fmt.Errorf("seek spool segment: %w", err)
+	}
+	s.partialWrite = false
+	return nil
+}
+
+// sync сбрасывает на диск события, записанные после последнего сброса
+func (s *spool) sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	return s.syncLocked()
+}
+
+func (s *spool) syncLocked() error {
+	if !s.dirty {
+		return nil
+	}
+	if err := s.writeFile.Sync(); err != nil {
+		return fmt.Errorf("sync spool segment: %w", err)
+	}
+	s.dirty, s.lastSync = false, time.Now()
+	return nil
+}
+
+// spaceFreed возвращает канал, который закроется при освобождении места в спуле.
+// Канал нужно получить до попытки записи, чтобы не пропустить освобождение
+func (s *spool) spaceFreed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.freed
+}
+
+// peek читает до max событий с позиции чтения без ее изменения
+func (s *spool) peek(max int) (*spoolBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := &spoolBatch{startSegment: s.readSegment, nextSegment: s.readSegment, nextOffset: s.readOffset}
+	for len(batch.records) < max {
+		if err := s.readSegmentRecords(batch, max); err != nil {
+			return nil, err
+		}
+		if len(batch.records) >= max || batch.nextSegment >= s.writeSegment {
+			break
+		}
+		batch.nextSegment, batch.nextOffset = batch.nextSegment+1, 0
+	}
+	return batch, nil
+}
+
+// commit подтверждает доставку пачки событий и удаляет прочитанные сегменты
+func (s *spool) commit(batch *spoolBatch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := filepath.Join(s.dir, spoolOffsetFile+".tmp")
+	content := strconv.FormatUint(batch.nextSegment, 10) + " " + strconv.FormatInt(batch.nextOffset, 10)
+	if err := os.WriteFile(tmp, []byte(content), 0o640); err != nil {
+		return fmt.Errorf("write spool offset: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, spoolOffsetFile)); err != nil {
+		return fmt.Errorf("replace spool offset: %w", err)
+	}
+
+	for segment := s.readSegment; segment < batch.nextSegment; segment++ {
+		if err := os.Remove(s.segmentPath(segment)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove consumed segment: %w", err)
+		}
+	}
+	s.readSegment, s.readOffset = batch.nextSegment, batch.nextOffset
+	s.size -= batch.consumedBytes
+	if batch.consumedBytes > 0 {
+		close(s.freed)
+		s.freed = make(chan struct{})
+	}
+	return nil
+}
+
+// length возвращает размер неотправленных событий в байтах
+func (s *spool) length() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// close сбрасывает текущий сегмент на диск и закрывает его
+func (s *spool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if err := s.writeFile.Sync(); err != nil {
+		return err
+	}
+	return s.writeFile.Close()
+}
+
+// readSegmentRecords дочитывает события сегмента batch.nextSegment с позиции batch.nextOffset
+func (s *spool) readSegmentRecords(batch *spoolBatch, max int) error {
+	f, err := os.Open(s.segmentPath(batch.nextSegment))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("open spool segment: %w", err)
+	}
+	defer f.Close()
+	if _, err = f.Seek(batch.nextOffset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek spool segment: %w", err)
+	}
+
+	reader := bufio.NewReader(f)
+	for len(batch.records) < max {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// запись без перевода строки еще не дописана
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read spool segment: %w", err)
+		}
+		batch.nextOffset += int64(len(line))
+		batch.consumedBytes += int64(len(line))
+		if len(line) > 1 {
+			batch.records = append(batch.records, line[:len(line)-1])
+		}
+	}
+	return nil
+}
+
+// rotate закрывает текущий сегмент и начинает новый
+func (s *spool) rotate() error {
+	if err := s.writeFile.Sync(); err != nil {
+		return fmt.Errorf("sync spool segment: %w", err)
+	}
+	if err := s.writeFile.Close(); err != nil {
+		return fmt.Errorf("close spool segment: %w", err)
+	}
+	f, err := os.OpenFile(s.segmentPath(s.writeSegment+1), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("create spool segment: %w", err)
+	}
+	s.writeSegment++
+	s.writeFile, s.writeSize = f, 0
+	s.dirty = false
+	return nil
+}
+
+// repairSegment отбрасывает незавершенную запись в конце сегмента после аварийной остановки
+func (s *spool) repairSegment(segment uint64) error {
+	content, err := os.ReadFile(s.segmentPath(segment))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read spool segment: %w", err)
+	}
+	if len(content) == 0 || content[len(content)-1] == '\n' {
+		return nil
+	}
+	if err = os.Truncate(s.segmentPath(segment), int64(bytes.LastIndexByte(content, '\n')+1)); err != nil {
+		return fmt.Errorf("truncate spool segment: %w", err)
+	}
+	return nil
+}
+
+// readOffsetFile загружает сохраненную позицию чтения
+func (s *spool) readOffsetFile() error {
+	content, err := os.ReadFile(filepath.Join(s.dir, spoolOffsetFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read spool offset: %w", err)
+	}
+	if _, err = fmt.Sscan(string(content), &s.readSegment, &s.readOffset); err != nil {
+		return fmt.Errorf("parse spool offset: %w", err)
+	}
+	return nil
+}
+
+// listSegments возвращает номера сегментов спула по возрастанию
+func (s *spool) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool directory: %w", err)
+	}
+	segments := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		segment, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+func (s *spool) segmentPath(segment uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", segment, spoolSegmentExt))
+}
@@ -0,0 +1,171 @@
+//go:build !correct
+
+package sinks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool_AppendPeekCommit(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 1<<20, 0)
+	require.NoError(t, err)
+
+	for _, event := range []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`} {
+		require.NoError(t, s.append([]byte(event)))
+	}
+	assert.Error(t, s.append([]byte("multi\nline")))
+
+	batch, err := s.peek(2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`{"id":"1"}`), []byte(`{"id":"2"}`)}, batch.records)
+
+	// без подтверждения события читаются повторно
+	again, err := s.peek(2)
+	require.NoError(t, err)
+	assert.Equal(t, batch.records, again.records)
+
+	require.NoError(t, s.commit(batch))
+	assert.EqualValues(t, len(`{"id":"3"}`)+1, s.length())
+	require.NoError(t, s.close())
+
+	// неподтвержденные события сохраняются после перезапуска
+	s, err = openSpool(dir, 1<<20, 0)
+	require.NoError(t, err)
+	batch, err = s.peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`{"id":"3"}`)}, batch.records)
+	require.NoError(t, s.close())
+}
+
+func TestSpool_RotateAndRepair(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 1<<20, 0)
+	require.NoError(t, err)
+	s.segmentSize = 10
+
+	for _, event := range []string{"first-event", "second-event", "third-event"} {
+		require.NoError(t, s.append([]byte(event)))
+	}
+	segments, err := s.listSegments()
+	require.NoError(t, err)
+	assert.Len(t, segments, 3)
+
+	batch, err := s.peek(2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("first-event"), []byte("second-event")}, batch.records)
+	require.NoError(t, s.commit(batch))
+	segments, err = s.listSegments()
+	require.NoError(t, err)
+	assert.Len(t, segments, 2)
+	require.NoError(t, s.close())
+
+	// незавершенная запись после аварийной остановки отбрасывается
+	f, err := os.OpenFile(s.segmentPath(segments[len(segments)-1]), os.O_WRONLY|os.O_APPEND, 0o640)
+	require.NoError(t, err)
+	_, err = f.WriteString("broken")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = openSpool(dir, 1<<20, 0)
+	require.NoError(t, err)
+	batch, err = s.peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("third-event")}, batch.records)
+	require.NoError(t, s.close())
+}
+
+func TestSpool_DiscardPartialWrite(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 1<<20, 0)
+	require.NoError(t, err)
+	require.NoError(t, s.append([]byte(`{"id":"1"}`)))
+
+	// незавершенная запись в конце сегмента и дескриптор, запись в который невозможна
+	segment := s.segmentPath(s.writeSegment)
+	f, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0o640)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	writeFile := s.writeFile
+	s.writeFile, err = os.Open(segment)
+	require.NoError(t, err)
+
+	assert.Error(t, s.append([]byte(`{"id":"2"}`)))
+	assert.True(t, s.partialWrite)
+	assert.EqualValues(t, len(`{"id":"1"}`)+1, s.length())
+
+	require.NoError(t, s.writeFile.Close())
+	s.writeFile = writeFile
+	require.NoError(t, s.append([]byte(`{"id":"3"}`)))
+	assert.False(t, s.partialWrite)
+
+	batch, err := s.peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`{"id":"1"}`), []byte(`{"id":"3"}`)}, batch.records)
+	require.NoError(t, s.close())
+}
+
+func TestSpool_Full(t *testing.T) {
+	s, err := openSpool(filepath.Join(t.TempDir(), "sink"), 10, 0)
+	require.NoError(t, err)
+	defer s.close()
+
+	require.NoError(t, s.append([]byte("12345")))
+	assert.ErrorIs(t, s.append([]byte("12345")), errSpoolFull)
+}
+
+func TestSinkWorker_BlockUntilSpaceFreed(t *testing.T) {
+	worker, err := newSinkWorker("sink", nil, workerOptions{
+		SpoolPath:         t.TempDir(),
+		MaxSpoolSize:      10,
+		SpoolFullPolicy:   setting.AuditSpoolFullBlock,
+		SpoolBlockTimeout: time.Minute,
+	})
+	require.NoError(t, err)
+	require.NoError(t, worker.Write([]byte("12345")))
+
+	written := make(chan error, 1)
+	go func() {
+		written <- worker.Write([]byte("67890"))
+	}()
+	select {
+	case err = <-written:
+		t.Fatalf("write returned before space was freed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	batch, err := worker.spool.peek(1)
+	require.NoError(t, err)
+	require.NoError(t, worker.spool.commit(batch))
+	require.NoError(t, <-written)
+	require.NoError(t, worker.spool.close())
+}
+
+func TestSinkWorker_DropWhenFull(t *testing.T) {
+	worker, err := newSinkWorker("sink", nil, workerOptions{
+		SpoolPath:       t.TempDir(),
+		MaxSpoolSize:    10,
+		SpoolFullPolicy: setting.AuditSpoolFullDrop,
+	})
+	require.NoError(t, err)
+	defer worker.spool.close()
+
+	require.NoError(t, worker.Write([]byte("12345")))
+	assert.ErrorIs(t, worker.Write([]byte("67890")), errSpoolFull)
+}
+
+func TestEventKey(t *testing.T) {
+	assert.Equal(t, sarama.StringEncoder("42"), eventKey([]byte(`{"id":"42","event":"login"}`)))
+	assert.Nil(t, eventKey([]byte(`{"event":"login"}`)))
+}
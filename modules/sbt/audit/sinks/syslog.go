@@ -0,0 +1,125 @@
+package sinks
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	syslogSeverityInfo = 6
+	syslogTimeFormat   = "2006-01-02T15:04:05.000000Z07:00"
+	syslogTimeout      = 10 * time.Second
+	syslogMsgID        = "audit"
+)
+
+// syslogTransport отправка событий аудита в syslog в формате RFC5424.
+// Для tcp и tls используется octet counting (RFC6587), для udp каждое событие отправляется отдельной датаграммой
+type syslogTransport struct {
+	network  string
+	address  string
+	appName  string
+	hostName string
+	procID   string
+	facility int
+	conn     net.Conn
+}
+
+// newSyslogTransport создает отправителя событий аудита в syslog
+func newSyslogTransport(config setting.AuditSyslogSink) *syslogTransport {
+	hostName, err := os.Hostname()
+	if err != nil || hostName == "" {
+		hostName = "-"
+	}
+	return &syslogTransport{
+		network:  config.Network,
+		address:  config.Address,
+		appName:  truncateSyslogField(config.AppName, 48),
+		hostName: truncateSyslogField(hostName, 255),
+		procID:   strconv.Itoa(os.Getpid()),
+		facility: config.Facility,
+	}
+}
+
+// Send отправляет события, при ошибке соединение закрывается и открывается заново при следующей отправке
+func (t *syslogTransport) Send(ctx context.Context, events [][]byte) error {
+	if t.conn == nil {
+		if err := t.dial(ctx); err != nil {
+			return err
+		}
+	}
+	for _, event := range events {
+		frame := t.format(event, time.Now())
+		if t.network != "udp" {
+			frame = append([]byte(strconv.Itoa(len(frame))+" "), frame...)
+		}
+		if err := t.conn.SetWriteDeadline(time.Now().Add(syslogTimeout)); err != nil {
+			t.reset()
+			return fmt.Errorf("set syslog write deadline: %w", err)
+		}
+		if _, err := t.conn.Write(frame); err != nil {
+			t.reset()
+			return fmt.Errorf("write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close закрывает соединение с syslog
+func (t *syslogTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// format формирует сообщение RFC5424: <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (t *syslogTransport) format(event []byte, now time.Time) []byte {
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s - ",
+		t.facility*8+syslogSeverityInfo, now.UTC().Format(syslogTimeFormat), t.hostName, t.appName, t.procID, syslogMsgID)
+	return append([]byte(header), event...)
+}
+
+func (t *syslogTransport) dial(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if t.network == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", t.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, t.network, t.address)
+	}
+	if err != nil {
+		return fmt.Errorf("connect to syslog %s: %w", t.address, err)
+	}
+	t.conn = conn
+	return nil
+}
+
+func (t *syslogTransport) reset() {
+	_ = t.Close()
+}
+
+// truncateSyslogField приводит поле заголовка к печатным символам ASCII без пробелов допустимой длины
+func truncateSyslogField(value string, maxLength int) string {
+	result := make([]byte, 0, len(value))
+	for i := 0; i < len(value) && len(result) < maxLength; i++ {
+		if value[i] > 32 && value[i] < 127 {
+			result = append(result, value[i])
+		}
+	}
+	if len(result) == 0 {
+		return "-"
+	}
+	return string(result)
+}
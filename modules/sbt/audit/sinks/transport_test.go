@@ -0,0 +1,45 @@
+//go:build !correct
+
+package sinks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogTransport_Format(t *testing.T) {
+	transport := &syslogTransport{appName: "sourcecontrol", hostName: "host", procID: "42", facility: 13}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	assert.Equal(t, `<110>1 2024-01-02T03:04:05.000006Z host sourcecontrol 42 audit - {"id":"1"}`,
+		string(transport.format([]byte(`{"id":"1"}`), now)))
+	assert.Equal(t, "appname", truncateSyslogField("app name", 48))
+	assert.Equal(t, "-", truncateSyslogField(" ", 48))
+}
+
+func TestHTTPTransport_Send(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	transport := newHTTPTransport(setting.AuditHTTPSink{URL: server.URL, Token: "token", Timeout: time.Second})
+	require.NoError(t, transport.Send(context.Background(), [][]byte{[]byte(`{"id":"1"}`), []byte(`{"id":"2"}`)}))
+	assert.Equal(t, `[{"id":"1"},{"id":"2"}]`, string(body))
+
+	transport.url = server.URL + "/404"
+	server.Config.Handler = http.NotFoundHandler()
+	assert.Error(t, transport.Send(context.Background(), [][]byte{[]byte(`{}`)}))
+}
@@ -0,0 +1,238 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Transport отправка пачки событий аудита во внешнюю систему
+type Transport interface {
+	Send(ctx context.Context, events [][]byte) error
+	Close() error
+}
+
+// sinkWorker получатель событий аудита: события сохраняются в спул и доставляются в транспорт одной горутиной,
+// поэтому порядок событий сохраняется. Доставка выполняется не менее одного раза
+type sinkWorker struct {
+	name            string
+	transport       Transport
+	spool           *spool
+	batchSize       int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	syncInterval    time.Duration
+	fullPolicy      string
+	blockTimeout    time.Duration
+	notify          chan struct{}
+
+	// mu защищает закрытие спула и транспорта от одновременной записи событий
+	mu       sync.RWMutex
+	closed   bool
+	done     chan struct{} // закрывается при остановке, прерывает ожидание места в спуле
+	stopOnce sync.Once
+}
+
+// workerOptions параметры получателя событий аудита
+type workerOptions struct {
+	SpoolPath         string
+	MaxSpoolSize      int64
+	SpoolSyncInterval time.Duration
+	SpoolFullPolicy   string
+	SpoolBlockTimeout time.Duration
+	BatchSize         int
+	RetryBackoff      time.Duration
+	MaxRetryBackoff   time.Duration
+}
+
+// newSinkWorker создает получателя событий аудита со спулом в поддиректории name
+func newSinkWorker(name string, transport Transport, options workerOptions) (*sinkWorker, error) {
+	spool, err := openSpool(filepath.Join(options.SpoolPath, name), options.MaxSpoolSize, options.SpoolSyncInterval)
+	if err != nil {
+		return nil, fmt.Errorf("open spool of sink %s: %w", name, err)
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	worker := &sinkWorker{
+		name:            name,
+		transport:       transport,
+		spool:           spool,
+		batchSize:       batchSize,
+		retryBackoff:    options.RetryBackoff,
+		maxRetryBackoff: options.MaxRetryBackoff,
+		syncInterval:    options.SpoolSyncInterval,
+		fullPolicy:      options.SpoolFullPolicy,
+		blockTimeout:    options.SpoolBlockTimeout,
+		notify:          make(chan struct{}, 1),
+		done:            make(chan struct{}),
+	}
+	spoolBytes.WithLabelValues(name).Set(float64(spool.length()))
+	return worker, nil
+}
+
+// Name возвращает имя получателя
+func (w *sinkWorker) Name() string {
+	return w.name
+}
+
+// Write сохраняет событие в спул и будит горутину доставки.
+// При переполнении спула с политикой block запись ожидает освобождения места не дольше blockTimeout
+func (w *sinkWorker) Write(event []byte) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		eventsTotal.WithLabelValues(w.name, statusDropped).Inc()
+		return errSpoolClosed
+	}
+
+	if err := w.append(event); err != nil {
+		eventsTotal.WithLabelValues(w.name, statusDropped).Inc()
+		return err
+	}
+	spoolBytes.WithLabelValues(w.name).Set(float64(w.spool.length()))
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// append сохраняет событие в спул с учетом политики переполнения
+func (w *sinkWorker) append(event []byte) error {
+	var timeout <-chan time.Time
+	if w.blockTimeout > 0 {
+		timer := time.NewTimer(w.blockTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		freed := w.spool.spaceFreed()
+		err := w.spool.append(event)
+		if !errors.Is(err, errSpoolFull) || w.fullPolicy != setting.AuditSpoolFullBlock {
+			return err
+		}
+		select {
+		case <-freed:
+		case <-timeout:
+			return err
+		case <-w.done:
+			return errSpoolClosed
+		}
+	}
+}
+
+// run доставляет события из спула до остановки контекста, при ошибке отправка повторяется с экспоненциальной задержкой
+func (w *sinkWorker) run(ctx context.Context) {
+	if w.syncInterval > 0 {
+		go w.syncLoop(ctx)
+	}
+	backoff := w.retryBackoff
+	for {
+		batch, err := w.spool.peek(w.batchSize)
+		if err != nil {
+			log.Error("Error has occurred while reading audit spool of sink %s. Error: %v", w.name, err)
+			if !w.wait(ctx, backoff) {
+				return
+			}
+			backoff = w.nextBackoff(backoff)
+			continue
+		}
+
+		if len(batch.records) == 0 {
+			if batch.moved() {
+				w.commit(batch)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.notify:
+			}
+			continue
+		}
+
+		if err = w.transport.Send(ctx, batch.records); err != nil {
+			eventsTotal.WithLabelValues(w.name, statusFailed).Add(float64(len(batch.records)))
+			log.Warn("Error has occurred while delivering %d audit events to sink %s, retry in %v. Error: %v", len(batch.records), w.name, backoff, err)
+			if !w.wait(ctx, backoff) {
+				return
+			}
+			backoff = w.nextBackoff(backoff)
+			continue
+		}
+		backoff = w.retryBackoff
+		eventsTotal.WithLabelValues(w.name, statusDelivered).Add(float64(len(batch.records)))
+		lastDelivery.WithLabelValues(w.name).SetToCurrentTime()
+		w.commit(batch)
+	}
+}
+
+// commit подтверждает доставку пачки, при ошибке события будут отправлены повторно
+func (w *sinkWorker) commit(batch *spoolBatch) {
+	if err := w.spool.commit(batch); err != nil {
+		log.Error("Error has occurred while committing audit spool of sink %s. Error: %v", w.name, err)
+	}
+	spoolBytes.WithLabelValues(w.name).Set(float64(w.spool.length()))
+}
+
+// syncLoop периодически сбрасывает на диск события, записанные в спул без сброса
+func (w *sinkWorker) syncLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.spool.sync(); err != nil {
+				log.Error("Error has occurred while syncing audit spool of sink %s. Error: %v", w.name, err)
+			}
+		}
+	}
+}
+
+// close закрывает спул и транспорт после завершения начатых записей событий
+func (w *sinkWorker) close() {
+	// ожидающие места в спуле записи прерываются до захвата блокировки, иначе закрытие ждало бы их таймаута
+	w.stopOnce.Do(func() { close(w.done) })
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+
+	if err := w.spool.close(); err != nil {
+		log.Error("Error has occurred while closing audit spool of sink %s. Error: %v", w.name, err)
+	}
+	if err := w.transport.Close(); err != nil {
+		log.Error("Error has occurred while closing audit sink %s. Error: %v", w.name, err)
+	}
+}
+
+func (w *sinkWorker) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (w *sinkWorker) nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > w.maxRetryBackoff {
+		return w.maxRetryBackoff
+	}
+	return backoff
+}
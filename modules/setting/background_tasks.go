@@ -0,0 +1,29 @@
+package setting
+
+import "time"
+
+// BackgroundTasks настройки обработки фоновых задач, сохраненных в таблице background_task
+var BackgroundTasks = struct {
+	// LeaseTimeout время аренды задачи. Если экземпляр приложения не завершил обработку за это время,
+	// задача возвращается в очередь и обрабатывается повторно
+	LeaseTimeout time.Duration
+	// BatchSize количество задач, арендуемых за один запрос
+	BatchSize int
+}{
+	LeaseTimeout: 5 * time.Minute,
+	BatchSize:    100,
+}
+
+// loadBackgroundTasks загрузить настройки обработки фоновых задач из секции sourcecontrol.background_tasks
+func loadBackgroundTasks(rootCfg ConfigProvider) {
+	sec := rootCfg.Section("sourcecontrol.background_tasks")
+
+	BackgroundTasks.LeaseTimeout = sec.Key("LEASE_TIMEOUT").MustDuration(5 * time.Minute)
+	if BackgroundTasks.LeaseTimeout <= 0 {
+		BackgroundTasks.LeaseTimeout = 5 * time.Minute
+	}
+	BackgroundTasks.BatchSize = sec.Key("BATCH_SIZE").MustInt(100)
+	if BackgroundTasks.BatchSize <= 0 {
+		BackgroundTasks.BatchSize = 100
+	}
+}
@@ -1,6 +1,9 @@
 package setting
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 var CodeHub struct {
 	CodeHubMetricEnabled bool
@@ -10,6 +13,8 @@ var CodeHub struct {
 	CodeHubMarkLabelName                   string
 	CodeHubMarkEnabled                     bool
 	InternalMetricsNamesList               []string
+	// TasksRetry настройки повторной обработки задач подсчета использований
+	TasksRetry TaskRetry
 }
 
 // loadCodeHub - метод загрузки CODEHUB_MARK_LABEL_NAME в app.ini
@@ -22,6 +27,11 @@ func loadCodeHub(rootCfg ConfigProvider) {
 	CodeHub.CodeHubMarkLabelName = sec.Key("CODEHUB_MARK_LABEL_NAME").MustString("InSourceHub")
 	CodeHub.CodeHubMarkEnabled = sec.Key("CODEHUB_MARK_ENABLED").MustBool(false)
 	CodeHub.InternalMetricsNamesList = parseInternalMetricsNamesList(sec)
+	CodeHub.TasksRetry = loadTaskRetry(sec, "CODEHUB_TASKS_", TaskRetry{
+		MaxAttempts:     5,
+		RetryBackoff:    30 * time.Second,
+		MaxRetryBackoff: time.Hour,
+	})
 }
 
 func parseInternalMetricsNamesList(codeHubSection ConfigSection) []string {
@@ -11,6 +11,30 @@ import (
 
 const KafkaRepositoryTopic = "kafka.repository"
 
+// Топики доменных событий. Топик включается наличием секции в конфигурации
+const (
+	KafkaPushTopic        = "kafka.push"
+	KafkaBranchTopic      = "kafka.branch"
+	KafkaTagTopic         = "kafka.tag"
+	KafkaPullRequestTopic = "kafka.pull_request"
+	KafkaReviewTopic      = "kafka.review"
+	// KafkaRepositoryDeleteTopic топик событий удаления репозитория
+	KafkaRepositoryDeleteTopic = "kafka.repository_delete"
+	// KafkaRepositoryTransferTopic топик событий передачи репозитория другому владельцу
+	KafkaRepositoryTransferTopic = "kafka.repository_transfer"
+)
+
+// Топики команд: входящие команды, ответы на команды и команды, которые не удалось обработать
+const (
+	KafkaCommandTopic           = "kafka.commands"
+	KafkaCommandReplyTopic      = "kafka.command_replies"
+	KafkaCommandDeadLetterTopic = "kafka.command_dead_letter"
+)
+
+// kafkaEventTopics необязательные топики доменных событий
+var kafkaEventTopics = []string{KafkaPushTopic, KafkaBranchTopic, KafkaTagTopic, KafkaPullRequestTopic, KafkaReviewTopic,
+	KafkaRepositoryDeleteTopic, KafkaRepositoryTransferTopic, KafkaCommandReplyTopic, KafkaCommandDeadLetterTopic}
+
 // Kafka настройки
 var Kafka struct {
 	// Enabled Активирована ли Kafka
@@ -36,10 +60,49 @@ var Kafka struct {
 	//ConnectBackoff время задержки перед повторной попыткой подключения к серверу
 	ConnectBackoff time.Duration
 	//Topics список топиков
-	Topics       map[string]TopicConfig
+	Topics map[string]TopicConfig
+	//Outbox настройки отправки событий через таблицу outbox
+	Outbox KafkaOutboxConfig
+	//Commands настройки чтения команд
+	Commands     KafkaCommandsConfig
 	secManGetter GetCredSecMan
 }
 
+// KafkaCommandsConfig настройки чтения команд из топика [kafka.commands]
+type KafkaCommandsConfig struct {
+	// GroupID группа потребителей, экземпляры приложения делят партиции топика команд
+	GroupID string
+	// ServiceUser пользователь, от имени которого выполняются команды
+	ServiceUser string
+	// MaxAttempts количество попыток выполнения команды при временных ошибках, после которого команда
+	// отправляется в топик недоставленных команд
+	MaxAttempts int
+	// Retention время хранения выполненных команд, в течение которого повторная команда с тем же идентификатором
+	// не выполняется, а получает сохраненный ответ
+	Retention time.Duration
+}
+
+// KafkaOutboxConfig настройки отправки событий Kafka через таблицу outbox.
+// События сохраняются в БД и отправляются задачей cron, поэтому не теряются при недоступности брокера
+type KafkaOutboxConfig struct {
+	// Enabled сохранять события в outbox вместо синхронной отправки
+	Enabled bool
+	// Schedule расписание задачи отправки событий
+	Schedule string
+	// BatchSize количество событий, выбираемых за один проход задачи, не более одного события на ключ
+	BatchSize int
+	// MaxAttempts количество попыток отправки, после которого событие помечается ошибочным и останавливает очередь ключа
+	MaxAttempts int
+	// RetryBackoff начальная задержка перед повторной отправкой, удваивается с каждой попыткой
+	RetryBackoff time.Duration
+	// MaxRetryBackoff максимальная задержка перед повторной отправкой
+	MaxRetryBackoff time.Duration
+	// LockTimeout время, после которого заблокированное событие считается брошенным и отправляется повторно
+	LockTimeout time.Duration
+	// Retention время хранения отправленных событий
+	Retention time.Duration
+}
+
 // SourceControlKafkaAuth настройки авторизации
 var SourceControlKafkaAuth struct {
 	// Certificate сертификат
@@ -87,6 +150,57 @@ func loadKafka(rootCfg ConfigProvider) {
 
 		Kafka.Topics = make(map[string]TopicConfig)
 		newRepositoryTopic(rootCfg.Section(KafkaRepositoryTopic))
+		for _, topic := range kafkaEventTopics {
+			newEventTopic(rootCfg, topic)
+		}
+		loadKafkaOutbox(rootCfg.Section("kafka.outbox"))
+		loadKafkaCommands(rootCfg)
+	}
+}
+
+// loadKafkaCommands загрузить настройки топика команд, если он описан в конфигурации
+func loadKafkaCommands(rootCfg ConfigProvider) {
+	sec, err := rootCfg.GetSection(KafkaCommandTopic)
+	if err != nil {
+		return
+	}
+	topic := TopicConfig{
+		Enabled: sec.Key("TOPIC_ENABLED").MustBool(false),
+		Name:    sec.Key("TOPIC").MustString(""),
+		Type:    sec.Key("TYPE").MustString("consume"),
+	}
+	Kafka.Commands = KafkaCommandsConfig{
+		GroupID:     sec.Key("GROUP_ID").MustString("sourcecontrol-commands"),
+		ServiceUser: sec.Key("SERVICE_USER").MustString(""),
+		MaxAttempts: sec.Key("MAX_ATTEMPTS").MustInt(10),
+		Retention:   sec.Key("RETENTION").MustDuration(7 * 24 * time.Hour),
+	}
+	if Kafka.Commands.MaxAttempts <= 0 {
+		Kafka.Commands.MaxAttempts = 1
+	}
+	if topic.Enabled && topic.Name == "" {
+		log.Fatal("Kafka topic [%s] is enabled, but TOPIC is empty", KafkaCommandTopic)
+	}
+	if topic.Enabled && Kafka.Commands.ServiceUser == "" {
+		log.Fatal("Kafka topic [%s] is enabled, but SERVICE_USER is empty", KafkaCommandTopic)
+	}
+	Kafka.Topics[KafkaCommandTopic] = topic
+}
+
+// loadKafkaOutbox загрузить настройки отправки событий через outbox
+func loadKafkaOutbox(sec ConfigSection) {
+	Kafka.Outbox = KafkaOutboxConfig{
+		Enabled:         sec.Key("ENABLED").MustBool(false),
+		Schedule:        sec.Key("SCHEDULE").MustString("@every 10s"),
+		BatchSize:       sec.Key("BATCH_SIZE").MustInt(100),
+		MaxAttempts:     sec.Key("MAX_ATTEMPTS").MustInt(20),
+		RetryBackoff:    sec.Key("RETRY_BACKOFF").MustDuration(10 * time.Second),
+		MaxRetryBackoff: sec.Key("MAX_RETRY_BACKOFF").MustDuration(time.Hour),
+		LockTimeout:     sec.Key("LOCK_TIMEOUT").MustDuration(5 * time.Minute),
+		Retention:       sec.Key("RETENTION").MustDuration(7 * 24 * time.Hour),
+	}
+	if Kafka.Outbox.BatchSize <= 0 {
+		Kafka.Outbox.BatchSize = 100
 	}
 }
 
@@ -118,6 +232,23 @@ func newRepositoryTopic(kafkaRepositorySec ConfigSection) {
 	}
 }
 
+// newEventTopic создать топик доменных событий, если он описан в конфигурации
+func newEventTopic(rootCfg ConfigProvider, name string) {
+	sec, err := rootCfg.GetSection(name)
+	if err != nil {
+		return
+	}
+	topic := TopicConfig{
+		Enabled: sec.Key("TOPIC_ENABLED").MustBool(false),
+		Name:    sec.Key("TOPIC").MustString(""),
+		Type:    sec.Key("TYPE").MustString("produce"),
+	}
+	if topic.Enabled && topic.Name == "" {
+		log.Fatal("Kafka topic [%s] is enabled, but TOPIC is empty", name)
+	}
+	Kafka.Topics[name] = topic
+}
+
 // loadKafkaAuth загрузить настройки Kafka авторизации
 func loadKafkaAuth() {
 	if CheckSettingsForIntegrationWithSecMan() {
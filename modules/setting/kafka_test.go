@@ -4,6 +4,7 @@ package setting
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -81,3 +82,88 @@ TYPE=produce
 	assert.Equal(t, 9092, Kafka.Port)
 	assert.Equal(t, topics, Kafka.Topics)
 }
+
+// TestKafkaSettingsWithEventTopics проверяет, что топики доменных событий добавляются только при наличии секции
+func TestKafkaSettingsWithEventTopics(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(`
+[kafka]
+ENABLED=true
+ADDRESS = "00.00.00.000"
+PORT = 9092
+[kafka.repository]
+TOPIC_ENABLED=false
+[kafka.push]
+TOPIC_ENABLED=true
+TOPIC=push_workflow
+[kafka.review]
+TOPIC_ENABLED=false
+[kafka.repository_delete]
+TOPIC_ENABLED=true
+TOPIC=repository_delete_workflow
+`)
+	assert.NoError(t, err)
+	loadKafka(cfg)
+
+	assert.Equal(t, TopicConfig{true, "push_workflow", "produce"}, Kafka.Topics[KafkaPushTopic])
+	assert.Equal(t, TopicConfig{false, "", "produce"}, Kafka.Topics[KafkaReviewTopic])
+	assert.Equal(t, TopicConfig{true, "repository_delete_workflow", "produce"}, Kafka.Topics[KafkaRepositoryDeleteTopic])
+	_, ok := Kafka.Topics[KafkaBranchTopic]
+	assert.False(t, ok)
+	_, ok = Kafka.Topics[KafkaRepositoryTransferTopic]
+	assert.False(t, ok)
+}
+
+// TestKafkaSettingsWithOutbox проверяет настройки отправки событий через outbox
+func TestKafkaSettingsWithOutbox(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(`
+[kafka]
+ENABLED=true
+ADDRESS = "00.00.00.000"
+PORT = 9092
+[kafka.repository]
+TOPIC_ENABLED=false
+[kafka.outbox]
+ENABLED=true
+MAX_ATTEMPTS=5
+RETRY_BACKOFF=1m
+`)
+	assert.NoError(t, err)
+	loadKafka(cfg)
+
+	assert.True(t, Kafka.Outbox.Enabled)
+	assert.Equal(t, "@every 10s", Kafka.Outbox.Schedule)
+	assert.Equal(t, 100, Kafka.Outbox.BatchSize)
+	assert.Equal(t, 5, Kafka.Outbox.MaxAttempts)
+	assert.Equal(t, time.Minute, Kafka.Outbox.RetryBackoff)
+	assert.Equal(t, time.Hour, Kafka.Outbox.MaxRetryBackoff)
+}
+
+// TestKafkaSettingsWithCommands проверяет настройки топиков команд, ответов и недоставленных команд
+func TestKafkaSettingsWithCommands(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(`
+[kafka]
+ENABLED=true
+ADDRESS = "00.00.00.000"
+PORT = 9092
+[kafka.repository]
+TOPIC_ENABLED=false
+[kafka.commands]
+TOPIC_ENABLED=true
+TOPIC=sourcecontrol_commands
+SERVICE_USER=provisioner
+[kafka.command_replies]
+TOPIC_ENABLED=true
+TOPIC=sourcecontrol_command_replies
+`)
+	assert.NoError(t, err)
+	loadKafka(cfg)
+
+	assert.Equal(t, TopicConfig{true, "sourcecontrol_commands", "consume"}, Kafka.Topics[KafkaCommandTopic])
+	assert.Equal(t, TopicConfig{true, "sourcecontrol_command_replies", "produce"}, Kafka.Topics[KafkaCommandReplyTopic])
+	assert.Equal(t, "sourcecontrol-commands", Kafka.Commands.GroupID)
+	assert.Equal(t, "provisioner", Kafka.Commands.ServiceUser)
+	assert.Equal(t, 10, Kafka.Commands.MaxAttempts)
+	assert.Equal(t, 7*24*time.Hour, Kafka.Commands.Retention)
+	_, ok := Kafka.Topics[KafkaCommandDeadLetterTopic]
+	assert.False(t, ok)
+}
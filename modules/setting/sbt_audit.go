@@ -3,6 +3,7 @@ package setting
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/sbt/audit/writers"
@@ -23,6 +24,67 @@ type auditSbtConfig struct {
 
 var auditConfig auditSbtConfig
 
+// AuditSinks настройки отправки событий аудита во внешние системы
+var AuditSinks = struct {
+	SpoolPath         string        // путь до директории спула, в котором хранятся неотправленные события
+	MaxSpoolSize      int64         // максимальный размер спула одного получателя в байтах
+	SpoolSyncInterval time.Duration // интервал сброса спула на диск, 0 - после каждого события
+	SpoolFullPolicy   string        // поведение при переполнении спула: block - ожидать освобождения места, drop - отбросить событие
+	SpoolBlockTimeout time.Duration // максимальное ожидание места в спуле при политике block, 0 - без ограничения
+	RetryBackoff      time.Duration // начальная задержка перед повторной отправкой
+	MaxRetryBackoff   time.Duration // максимальная задержка перед повторной отправкой
+	Kafka             AuditKafkaSink
+	Syslog            AuditSyslogSink
+	HTTP              AuditHTTPSink
+	Database          AuditDatabaseSink
+}{}
+
+// Поведение получателей событий аудита при переполнении спула
+const (
+	AuditSpoolFullBlock = "block"
+	AuditSpoolFullDrop  = "drop"
+)
+
+// AuditChain настройки цепочки хешей событий аудита
+var AuditChain = struct {
+	Enabled   bool
+	StatePath string // файл с номером и хешем последнего сообщения цепочки
+	Secret    string // секрет подписи контрольных точек
+}{}
+
+// AuditKafkaSink настройки отправки событий аудита в Kafka
+type AuditKafkaSink struct {
+	Enabled   bool
+	Topic     string
+	BatchSize int
+}
+
+// AuditSyslogSink настройки отправки событий аудита в syslog по RFC5424
+type AuditSyslogSink struct {
+	Enabled   bool
+	Network   string // udp, tcp или tls
+	Address   string
+	AppName   string
+	Facility  int
+	BatchSize int
+}
+
+// AuditDatabaseSink настройки сохранения событий аудита в БД для поиска
+type AuditDatabaseSink struct {
+	Enabled   bool
+	Retention time.Duration // срок хранения событий, 0 - события не удаляются
+	BatchSize int
+}
+
+// AuditHTTPSink настройки отправки событий аудита пачками по HTTP
+type AuditHTTPSink struct {
+	Enabled   bool
+	URL       string
+	Token     string
+	Timeout   time.Duration
+	BatchSize int
+}
+
 // loadAuditSbtGlobalFrom функция считывания параметров аудита из конфигурации
 func loadAuditSbtGlobalFrom(rootCfg ConfigProvider) {
 	sec := rootCfg.Section("sbt.audit")
@@ -49,4 +111,71 @@ func loadAuditSbtGlobalFrom(rootCfg ConfigProvider) {
 	auditConfig.WriterOption = writerOption
 
 	writers.NewAuditWriter(writerOption)
+
+	loadAuditChainFrom(sec)
+	loadAuditSinksFrom(rootCfg, sec)
+}
+
+// loadAuditChainFrom функция считывания параметров цепочки хешей событий аудита
+func loadAuditChainFrom(sec ConfigSection) {
+	AuditChain.Enabled = sec.Key("CHAIN_ENABLED").MustBool(false)
+	AuditChain.StatePath = sec.Key("CHAIN_STATE_PATH").MustString(filepath.Join(auditConfig.Path, auditConfig.FileName+".chain"))
+	if !filepath.IsAbs(AuditChain.StatePath) {
+		AuditChain.StatePath = filepath.Join(AppWorkPath, AuditChain.StatePath)
+	}
+	AuditChain.Secret = loadSecret(sec, "CHAIN_SECRET_URI", "CHAIN_SECRET")
+	if AuditChain.Enabled && AuditChain.Secret == "" {
+		log.Warn("Audit chain is enabled, but [sbt.audit] CHAIN_SECRET is empty, checkpoints will not be written")
+	}
+}
+
+// loadAuditSinksFrom функция считывания параметров отправки событий аудита во внешние системы
+func loadAuditSinksFrom(rootCfg ConfigProvider, sec ConfigSection) {
+	AuditSinks.SpoolPath = sec.Key("SPOOL_PATH").MustString(filepath.Join(auditConfig.Path, "spool"))
+	if !filepath.IsAbs(AuditSinks.SpoolPath) {
+		AuditSinks.SpoolPath = filepath.Join(AppWorkPath, AuditSinks.SpoolPath)
+	}
+	AuditSinks.MaxSpoolSize = sec.Key("MAX_SPOOL_SIZE").MustInt64(1 << 30)
+	AuditSinks.SpoolSyncInterval = sec.Key("SPOOL_SYNC_INTERVAL").MustDuration(0)
+	AuditSinks.SpoolFullPolicy = sec.Key("SPOOL_FULL_POLICY").In(AuditSpoolFullBlock, []string{AuditSpoolFullBlock, AuditSpoolFullDrop})
+	AuditSinks.SpoolBlockTimeout = sec.Key("SPOOL_BLOCK_TIMEOUT").MustDuration(10 * time.Second)
+	AuditSinks.RetryBackoff = sec.Key("RETRY_BACKOFF").MustDuration(time.Second)
+	AuditSinks.MaxRetryBackoff = sec.Key("MAX_RETRY_BACKOFF").MustDuration(5 * time.Minute)
+
+	kafkaSec := rootCfg.Section("sbt.audit.kafka")
+	AuditSinks.Kafka.Enabled = kafkaSec.Key("ENABLED").MustBool(false)
+	AuditSinks.Kafka.Topic = kafkaSec.Key("TOPIC").MustString("")
+	AuditSinks.Kafka.BatchSize = kafkaSec.Key("BATCH_SIZE").MustInt(100)
+	if AuditSinks.Kafka.Enabled && AuditSinks.Kafka.Topic == "" {
+		log.Fatal("Audit Kafka sink is enabled, but [sbt.audit.kafka] TOPIC is empty")
+	}
+
+	syslogSec := rootCfg.Section("sbt.audit.syslog")
+	AuditSinks.Syslog.Enabled = syslogSec.Key("ENABLED").MustBool(false)
+	AuditSinks.Syslog.Network = syslogSec.Key("NETWORK").In("udp", []string{"udp", "tcp", "tls"})
+	AuditSinks.Syslog.Address = syslogSec.Key("ADDRESS").MustString("")
+	AuditSinks.Syslog.AppName = syslogSec.Key("APP_NAME").MustString("sourcecontrol")
+	AuditSinks.Syslog.Facility = syslogSec.Key("FACILITY").MustInt(13)
+	AuditSinks.Syslog.BatchSize = syslogSec.Key("BATCH_SIZE").MustInt(100)
+	if AuditSinks.Syslog.Enabled && AuditSinks.Syslog.Address == "" {
+		log.Fatal("Audit syslog sink is enabled, but [sbt.audit.syslog] ADDRESS is empty")
+	}
+	if AuditSinks.Syslog.Facility < 0 || AuditSinks.Syslog.Facility > 23 {
+		log.Fatal("Incorrect [sbt.audit.syslog] FACILITY: %d, must be from 0 to 23", AuditSinks.Syslog.Facility)
+	}
+
+	httpSec := rootCfg.Section("sbt.audit.http")
+	AuditSinks.HTTP.Enabled = httpSec.Key("ENABLED").MustBool(false)
+	AuditSinks.HTTP.URL = httpSec.Key("URL").MustString("")
+	AuditSinks.HTTP.Token = httpSec.Key("TOKEN").MustString("")
+	AuditSinks.HTTP.Timeout = httpSec.Key("TIMEOUT").MustDuration(10 * time.Second)
+	AuditSinks.HTTP.BatchSize = httpSec.Key("BATCH_SIZE").MustInt(100)
+	if AuditSinks.HTTP.Enabled && AuditSinks.HTTP.URL == "" {
+		log.Fatal("Audit HTTP sink is enabled, but [sbt.audit.http] URL is empty")
+	}
+
+	dbSec := rootCfg.Section("sbt.audit.database")
+	AuditSinks.Database.Enabled = dbSec.Key("ENABLED").MustBool(false)
+	AuditSinks.Database.Retention = dbSec.Key("RETENTION").MustDuration(90 * 24 * time.Hour)
+	AuditSinks.Database.BatchSize = dbSec.Key("BATCH_SIZE").MustInt(100)
 }
@@ -265,6 +265,8 @@ func loadCommonSettingsFrom(cfg ConfigProvider, consoleConf bool) {
 	loadSbtOneWorkForm(cfg)
 	loadCron(cfg)
 	loadCodeHub(cfg)
+	loadBackgroundTasks(cfg)
+	loadSonar(cfg)
 }
 
 func loadRunModeFrom(rootCfg ConfigProvider) {
@@ -0,0 +1,38 @@
+package setting
+
+import "time"
+
+// Sonar настройки приема webhook SonarQube и хранения истории метрик
+var Sonar = struct {
+	// RequireWebhookSecret webhook SonarQube принимается только с подписью секретом из настроек Sonar репозитория.
+	// Если выключено, webhook репозиториев без секрета принимаются без подписи на время перехода на обязательные секреты
+	RequireWebhookSecret bool
+	// WebhookReplayWindow допустимое отклонение времени анализа (analysedAt) от текущего времени. Webhook с более
+	// старым или более поздним анализом отклоняется как повторная отправка
+	WebhookReplayWindow time.Duration
+	// MetricsRetention срок хранения снимков метрик анализов. Последний снимок ветки не удаляется. 0 - снимки не удаляются
+	MetricsRetention time.Duration
+	// VaultStoragePath хранилище Vault, из которого разрешено получать токены Sonar. Пусто - любое хранилище
+	VaultStoragePath string
+	// VaultSecretPathPrefix префикс пути секрета в Vault, из которого разрешено получать токен Sonar репозитория.
+	// Подстановка {repo_id} заменяется идентификатором репозитория. Пусто - получение токенов из Vault запрещено
+	VaultSecretPathPrefix string
+}{
+	RequireWebhookSecret: true,
+	WebhookReplayWindow:  time.Hour,
+	MetricsRetention:     90 * 24 * time.Hour,
+}
+
+// loadSonar загрузить настройки приема webhook SonarQube и хранения истории метрик из секции sourcecontrol.sonar
+func loadSonar(rootCfg ConfigProvider) {
+	sec := rootCfg.Section("sourcecontrol.sonar")
+
+	Sonar.RequireWebhookSecret = sec.Key("REQUIRE_WEBHOOK_SECRET").MustBool(true)
+	Sonar.WebhookReplayWindow = sec.Key("WEBHOOK_REPLAY_WINDOW").MustDuration(time.Hour)
+	if Sonar.WebhookReplayWindow <= 0 {
+		Sonar.WebhookReplayWindow = time.Hour
+	}
+	Sonar.MetricsRetention = sec.Key("METRICS_RETENTION").MustDuration(90 * 24 * time.Hour)
+	Sonar.VaultStoragePath = sec.Key("VAULT_STORAGE_PATH").MustString("")
+	Sonar.VaultSecretPathPrefix = sec.Key("VAULT_SECRET_PATH_PREFIX").MustString("")
+}
@@ -26,6 +26,8 @@ var SourceControl struct {
 	DefaultTenantID string
 	//DefaultTenantID Идентификатор организации	по умолчанию
 	DefaultOrgKey string
+	// PrivilegesStateMaxRemovals максимальное количество снимаемых привилегий при применении описания привилегий без принудительного режима
+	PrivilegesStateMaxRemovals int
 }
 
 var WidgetSW struct {
@@ -51,6 +53,7 @@ func loadSourceControl(rootCfg ConfigProvider) {
 		SourceControl.DefaultTenantName = sec.Key("DEFAULT_TENANT_NAME").MustString("tenant")
 		SourceControl.DefaultTenantID = sec.Key("DEFAULT_TENANT_ID").MustString(uuid.NewString())
 		SourceControl.DefaultOrgKey = sec.Key("DEFAULT_ORG_KEY").MustString("")
+		SourceControl.PrivilegesStateMaxRemovals = sec.Key("PRIVILEGES_STATE_MAX_REMOVALS").MustInt(50)
 
 		widgetSec := rootCfg.Section("sourcecontrol.widget-sw")
 		WidgetSW.Enabled = widgetSec.Key("ENABLED").MustBool(false)
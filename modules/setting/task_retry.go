@@ -0,0 +1,47 @@
+package setting
+
+import "time"
+
+// TaskRetry настройки повторной обработки фоновых задач, сохраненных в БД
+type TaskRetry struct {
+	// MaxAttempts количество попыток, после которого задача переводится в состояние dead и больше не обрабатывается
+	MaxAttempts int
+	// RetryBackoff начальная задержка перед повторной обработкой, удваивается с каждой попыткой
+	RetryBackoff time.Duration
+	// MaxRetryBackoff максимальная задержка перед повторной обработкой
+	MaxRetryBackoff time.Duration
+}
+
+// loadTaskRetry загрузить настройки повторной обработки задач из ключей секции с префиксом prefix
+func loadTaskRetry(sec ConfigSection, prefix string, defaults TaskRetry) TaskRetry {
+	retry := TaskRetry{
+		MaxAttempts:     sec.Key(prefix + "MAX_ATTEMPTS").MustInt(defaults.MaxAttempts),
+		RetryBackoff:    sec.Key(prefix + "RETRY_BACKOFF").MustDuration(defaults.RetryBackoff),
+		MaxRetryBackoff: sec.Key(prefix + "MAX_RETRY_BACKOFF").MustDuration(defaults.MaxRetryBackoff),
+	}
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaults.MaxAttempts
+	}
+	if retry.MaxRetryBackoff < retry.RetryBackoff {
+		retry.MaxRetryBackoff = retry.RetryBackoff
+	}
+	return retry
+}
+
+// IsLastAttempt проверяет, что после attempts неудачных попыток задача больше не обрабатывается.
+// Если MaxAttempts не задано, количество попыток не ограничено
+func (r TaskRetry) IsLastAttempt(attempts int) bool {
+	return r.MaxAttempts > 0 && attempts >= r.MaxAttempts
+}
+
+// NextAttemptDelay возвращает задержку перед следующей попыткой после attempts неудачных попыток
+func (r TaskRetry) NextAttemptDelay(attempts int) time.Duration {
+	backoff := r.RetryBackoff
+	for i := 1; i < attempts && backoff < r.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.MaxRetryBackoff {
+		backoff = r.MaxRetryBackoff
+	}
+	return backoff
+}
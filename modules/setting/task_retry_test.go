@@ -0,0 +1,29 @@
+//go:build !correct
+
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadTaskRetry(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(`
+[sourcecontrol.codehub]
+CODEHUB_TASKS_MAX_ATTEMPTS = 3
+CODEHUB_TASKS_RETRY_BACKOFF = 10s
+CODEHUB_TASKS_MAX_RETRY_BACKOFF = 30s
+`)
+	assert.NoError(t, err)
+	loadCodeHub(cfg)
+
+	retry := CodeHub.TasksRetry
+	assert.Equal(t, TaskRetry{MaxAttempts: 3, RetryBackoff: 10 * time.Second, MaxRetryBackoff: 30 * time.Second}, retry)
+	assert.Equal(t, 10*time.Second, retry.NextAttemptDelay(1))
+	assert.Equal(t, 20*time.Second, retry.NextAttemptDelay(2))
+	assert.Equal(t, 30*time.Second, retry.NextAttemptDelay(5))
+	assert.False(t, retry.IsLastAttempt(2))
+	assert.True(t, retry.IsLastAttempt(3))
+}
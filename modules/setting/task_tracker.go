@@ -1,7 +1,9 @@
 package setting
 
 import (
+	"regexp"
 	"strings"
+	"time"
 
 	vault_model "code.gitea.io/gitea/models/vault_client"
 	"code.gitea.io/gitea/modules/log"
@@ -19,6 +21,38 @@ var TaskTracker struct {
 	UnitsValidationEnabled         bool
 	UnitLinksSenderIntervalSeconds int64
 	GetCredFor                     GetCredSecMan
+	// SenderRetry настройки повторной отправки задач привязки юнитов в трекер задач
+	SenderRetry TaskRetry
+	// UnitStatusCacheTTL время хранения в кэше статусов юнитов пулл реквеста для страницы пулл реквеста, 0 отключает кэш
+	UnitStatusCacheTTL time.Duration
+
+	// DefaultProvider трекер задач для тенантов и репозиториев без собственной настройки
+	DefaultProvider string
+	Providers       map[string]TaskTrackerProvider
+}
+
+// Типы трекеров задач
+const (
+	TaskTrackerProviderTypeTaskTracker = "tasktracker" // внутренний TaskTracker
+	TaskTrackerProviderTypeJira        = "jira"
+	TaskTrackerProviderTypeREST        = "rest" // трекер с REST API по контракту SourceControl
+)
+
+// DefaultTaskTrackerProviderName имя трекера задач, настроенного в секции [sourcecontrol.tasktracker]
+const DefaultTaskTrackerProviderName = "default"
+
+const taskTrackerProviderSectionPrefix = "sourcecontrol.tasktracker.provider."
+
+// TaskTrackerProvider настройки подключения к трекеру задач
+type TaskTrackerProvider struct {
+	Name        string
+	Type        string
+	APIBaseURL  string
+	APIToken    string
+	User        string // пользователь для basic авторизации, если пустой, токен передается как Bearer
+	UnitBaseURL string
+	// UnitCodePattern регулярное выражение кода юнита, если пустое, используются выражения по умолчанию
+	UnitCodePattern string
 }
 
 // //go:generate mockery --name=GetCredSecMan --exported
@@ -31,6 +65,12 @@ func loadTaskTracker(rootCfg ConfigProvider) {
 	sec := rootCfg.Section("sourcecontrol.tasktracker")
 
 	TaskTracker.Enabled = sec.Key("TASK_TRACKER_ENABLED").MustBool(false)
+	TaskTracker.SenderRetry = loadTaskRetry(sec, "UNIT_LINKS_SENDER_", TaskRetry{
+		MaxAttempts:     10,
+		RetryBackoff:    time.Minute,
+		MaxRetryBackoff: 6 * time.Hour,
+	})
+	TaskTracker.UnitStatusCacheTTL = sec.Key("UNIT_STATUS_CACHE_TTL").MustDuration(time.Minute)
 
 	if TaskTracker.Enabled && SourceControl.Enabled {
 		TaskTracker.UnitsValidationEnabled = sec.Key("UNITS_VALIDATION_ENABLED").MustBool(true)
@@ -75,5 +115,61 @@ func loadTaskTracker(rootCfg ConfigProvider) {
 			log.Fatal("API_TOKEN can't be blank")
 		}
 		TaskTracker.APIToken = tokenString
+
+		loadTaskTrackerProviders(rootCfg, sec)
+	}
+}
+
+// loadTaskTrackerProviders подтягивает настройки трекеров задач из секций [sourcecontrol.tasktracker.provider.<name>]
+func loadTaskTrackerProviders(rootCfg ConfigProvider, sec ConfigSection) {
+	TaskTracker.Providers = map[string]TaskTrackerProvider{
+		DefaultTaskTrackerProviderName: {
+			Name:            DefaultTaskTrackerProviderName,
+			Type:            TaskTrackerProviderTypeTaskTracker,
+			APIBaseURL:      TaskTracker.APIBaseURL,
+			APIToken:        TaskTracker.APIToken,
+			UnitBaseURL:     TaskTracker.UnitBaseURL,
+			UnitCodePattern: sec.Key("UNIT_CODE_PATTERN").MustString(""),
+		},
+	}
+
+	for _, providerSec := range rootCfg.Section("sourcecontrol.tasktracker.provider").ChildSections() {
+		name := strings.TrimPrefix(providerSec.Name(), taskTrackerProviderSectionPrefix)
+		// ключи читаются только из секции трекера, чтобы не унаследовать токен и шаблон из [sourcecontrol.tasktracker]
+		provider := TaskTrackerProvider{
+			Name:            name,
+			Type:            ConfigSectionKeyString(providerSec, "TYPE", TaskTrackerProviderTypeTaskTracker),
+			APIBaseURL:      strings.TrimSuffix(ConfigSectionKeyString(providerSec, "API_BASE_URL"), "/"),
+			APIToken:        ConfigSectionKeyString(providerSec, "API_TOKEN"),
+			User:            ConfigSectionKeyString(providerSec, "USER"),
+			UnitBaseURL:     strings.TrimSuffix(ConfigSectionKeyString(providerSec, "UNIT_BASE_URL"), "/"),
+			UnitCodePattern: ConfigSectionKeyString(providerSec, "UNIT_CODE_PATTERN"),
+		}
+		switch provider.Type {
+		case TaskTrackerProviderTypeTaskTracker, TaskTrackerProviderTypeJira, TaskTrackerProviderTypeREST:
+		default:
+			log.Fatal("TYPE '%s' of task tracker provider '%s' is not supported", provider.Type, name)
+		}
+		if len(provider.APIBaseURL) == 0 {
+			log.Fatal("API_BASE_URL of task tracker provider '%s' can't be blank", name)
+		}
+		if len(provider.UnitBaseURL) == 0 && provider.Type == TaskTrackerProviderTypeJira {
+			provider.UnitBaseURL = provider.APIBaseURL + "/browse"
+		}
+		if len(provider.UnitBaseURL) == 0 {
+			log.Fatal("UNIT_BASE_URL of task tracker provider '%s' can't be blank", name)
+		}
+		TaskTracker.Providers[name] = provider
+	}
+
+	for name, provider := range TaskTracker.Providers {
+		if _, err := regexp.Compile(provider.UnitCodePattern); err != nil {
+			log.Fatal("UNIT_CODE_PATTERN of task tracker provider '%s' is invalid: %v", name, err)
+		}
+	}
+
+	TaskTracker.DefaultProvider = sec.Key("DEFAULT_PROVIDER").MustString(DefaultTaskTrackerProviderName)
+	if _, ok := TaskTracker.Providers[TaskTracker.DefaultProvider]; !ok {
+		log.Fatal("DEFAULT_PROVIDER '%s' of task tracker is not configured", TaskTracker.DefaultProvider)
 	}
 }
@@ -91,4 +91,40 @@ API_TOKEN = eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ2.eyJncm23cHMiOlsiUk9MRV7TT1VSQ0V
 		TaskTracker.GetCredFor = getSecretMan
 		loadTaskTracker(cfg)
 	})
+
+	t.Run("Providers", func(t *testing.T) {
+		cfg, _ := NewConfigProviderFromData(`
+[sourcecontrol.tasktracker]
+API_BASE_URL = https://tasktracker.local/api
+UNIT_CODE_PATTERN = US\d+
+DEFAULT_PROVIDER = jira
+[sourcecontrol.tasktracker.provider.jira]
+TYPE = jira
+API_BASE_URL = https://jira.local/
+USER = bot
+API_TOKEN = secret
+[sourcecontrol.tasktracker.provider.adapter]
+TYPE = rest
+API_BASE_URL = https://adapter.local/api
+UNIT_BASE_URL = https://adapter.local/units
+UNIT_CODE_PATTERN = TASK-\d+
+`)
+		TaskTracker.APIBaseURL = "https://tasktracker.local/api"
+		loadTaskTrackerProviders(cfg, cfg.Section("sourcecontrol.tasktracker"))
+
+		assert.Equal(t, "jira", TaskTracker.DefaultProvider)
+		assert.Len(t, TaskTracker.Providers, 3)
+		assert.Equal(t, TaskTrackerProviderTypeTaskTracker, TaskTracker.Providers[DefaultTaskTrackerProviderName].Type)
+		assert.Equal(t, `US\d+`, TaskTracker.Providers[DefaultTaskTrackerProviderName].UnitCodePattern)
+		assert.Equal(t, TaskTrackerProvider{
+			Name:        "jira",
+			Type:        TaskTrackerProviderTypeJira,
+			APIBaseURL:  "https://jira.local",
+			APIToken:    "secret",
+			User:        "bot",
+			UnitBaseURL: "https://jira.local/browse",
+		}, TaskTracker.Providers["jira"])
+		assert.Equal(t, TaskTrackerProviderTypeREST, TaskTracker.Providers["adapter"].Type)
+		assert.Equal(t, `TASK-\d+`, TaskTracker.Providers["adapter"].UnitCodePattern)
+	})
 }
@@ -158,6 +158,9 @@ func NewFuncMap() template.FuncMap {
 		"EnableRoleModel": func() bool {
 			return setting.SourceControl.TenantWithRoleModeEnabled
 		},
+		"EnableAuditDatabase": func() bool {
+			return setting.AuditSinks.Database.Enabled
+		},
 		"EnableMultiTenants": func() bool {
 			return setting.SourceControl.MultiTenantEnabled
 		},
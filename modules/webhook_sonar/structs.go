@@ -66,6 +66,14 @@ type MeasureSonar struct {
 	Measures []SonarMeasures `json:"measures"`
 }
 
+// ComponentMeasures значения метрик проекта по ветке или пулл реквесту
+type ComponentMeasures struct {
+	Component struct {
+		Key      string          `json:"key"`
+		Measures []SonarMeasures `json:"measures"`
+	} `json:"component"`
+}
+
 // Branch информация о ветке из webhook
 type Branch struct {
 	Name   string `json:"name"`
@@ -217,9 +217,7 @@ func servePackageFile(ctx *context.Context, params parameters, serveContent bool
 	defer s.Close()
 
 	if pf.IsLead {
-		if err := packages_model.IncrementDownloadCounter(ctx, pv.ID); err != nil {
-			log.Error("Error incrementing download counter: %v", err)
-		}
+		packages_service.IncrementDownloadCounter(ctx, pv.ID)
 	}
 
 	opts.Filename = pf.Name
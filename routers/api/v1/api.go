@@ -189,6 +189,12 @@ func repoAssignment() func(ctx *context.APIContext) {
 		repo.Owner = owner
 		ctx.Repo.Repository = repo
 
+		if setting.SourceControl.Enabled && owner.IsOrganization() {
+			if !context.CheckRepoTenantIsActiveApi(ctx, repo) {
+				return
+			}
+		}
+
 		if ctx.Doer != nil && ctx.Doer.ID == user_model.ActionsUserID {
 			taskID := ctx.Data["ActionsTaskID"].(int64)
 			task, err := actions_model.GetTaskByID(ctx, taskID)
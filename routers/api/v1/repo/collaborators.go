@@ -232,7 +232,7 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		return
 	}
 
-	if err := models.DeleteCollaboration(ctx.Repo.Repository, collaborator.ID); err != nil {
+	if err := models.DeleteCollaboration(ctx, ctx.Repo.Repository, collaborator.ID); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
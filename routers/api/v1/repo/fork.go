@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
+	tenant_model "code.gitea.io/gitea/models/tenant"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	api "code.gitea.io/gitea/modules/structs"
@@ -147,6 +148,8 @@ func CreateFork(ctx *context.APIContext) {
 	if err != nil {
 		if errors.Is(err, util.ErrAlreadyExist) || repo_model.IsErrReachLimitOfRepo(err) {
 			ctx.Error(http.StatusConflict, "ForkRepository", err)
+		} else if tenant_model.IsErrTenantQuotaExceeded(err) {
+			ctx.Error(http.StatusForbidden, "ForkRepository", "Tenant repository quota exceeded")
 		} else if repo_model.IsErrCreateUserRepo(err) {
 			ctx.Error(http.StatusBadRequest, "ForkRepository", "Creating a repository outside the project is prohibited")
 		} else {
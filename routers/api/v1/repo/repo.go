@@ -266,6 +266,8 @@ func CreateUserRepo(ctx *context.APIContext, owner *user_model.User, opt api.Cre
 			db.IsErrNamePatternNotAllowed(err) ||
 			label.IsErrTemplateLoad(err) {
 			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else if tenant_model.IsErrTenantQuotaExceeded(err) {
+			ctx.Error(http.StatusForbidden, "", "Tenant repository quota exceeded")
 		} else if repo_model.IsErrCreateUserRepo(err) {
 			ctx.Error(http.StatusBadRequest, "", "Creating a repository outside the project is prohibited")
 		} else {
@@ -304,6 +306,8 @@ func Create(ctx *context.APIContext) {
 	//     "$ref": "#/responses/Repository"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	//   "409":
 	//     description: The repository with the same name already exists.
 	//   "422":
@@ -460,6 +464,8 @@ func Generate(ctx *context.APIContext) {
 		} else if db.IsErrNameReserved(err) ||
 			db.IsErrNamePatternNotAllowed(err) {
 			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else if tenant_model.IsErrTenantQuotaExceeded(err) {
+			ctx.Error(http.StatusForbidden, "", "Tenant repository quota exceeded")
 		} else if repo_model.IsErrCreateUserRepo(err) {
 			ctx.Error(http.StatusBadRequest, "", "Creating a repository outside the project is prohibited")
 		} else {
@@ -1071,14 +1077,14 @@ func updateRepoArchivedState(ctx *context.APIContext, opts api.EditRepoOption) e
 		}
 
 		if *opts.Archived {
-			if err := repo_model.SetArchiveRepoState(repo, *opts.Archived); err != nil {
+			if err := repo_model.SetArchiveRepoState(ctx, repo, *opts.Archived); err != nil {
 				log.Error("Tried to archive a repo: %s", err)
 				ctx.Error(http.StatusInternalServerError, "ArchiveRepoState", err)
 				return err
 			}
 			log.Trace("Repository was archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 		} else {
-			if err := repo_model.SetArchiveRepoState(repo, *opts.Archived); err != nil {
+			if err := repo_model.SetArchiveRepoState(ctx, repo, *opts.Archived); err != nil {
 				log.Error("Tried to un-archive a repo: %s", err)
 				ctx.Error(http.StatusInternalServerError, "ArchiveRepoState", err)
 				return err
@@ -0,0 +1,179 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/routers/api/v2/models"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// auditSearchParams параметры поиска событий аудита для события о поиске
+func auditSearchParams(opts *audit_model.SearchEventsOptions) map[string]string {
+	return map[string]string{
+		"event_type": opts.Type,
+		"username":   opts.UserName,
+		"user_ip":    opts.UserIP,
+		"tenant":     opts.Tenant,
+		"repository": opts.Repository,
+		"status":     opts.Status,
+		"from":       opts.From.FormatLong(),
+		"to":         opts.To.FormatLong(),
+	}
+}
+
+// SearchAuditEvents возвращает страницу событий аудита, сохраненных в БД
+func SearchAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/audit admin searchAuditEvents
+	// ---
+	// summary: Search audit events stored in the database
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: event
+	//   in: query
+	//   description: event type description, e.g. "Create user"
+	//   type: string
+	// - name: username
+	//   in: query
+	//   description: name of the user who initiated the event
+	//   type: string
+	// - name: user_ip
+	//   in: query
+	//   description: IP address of the user
+	//   type: string
+	// - name: tenant
+	//   in: query
+	//   description: key or id of the tenant
+	//   type: string
+	// - name: repository
+	//   in: query
+	//   description: full repository name (owner/name) or repository key
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: event status, SUCCESS, FAIL or UNKNOWN
+	//   type: string
+	// - name: from
+	//   in: query
+	//   description: start of the time range in RFC3339 format
+	//   type: string
+	//   format: date-time
+	// - name: to
+	//   in: query
+	//   description: end of the time range in RFC3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/auditEventListResponse"
+	//   "400":
+	//     description: Invalid search parameters
+	//   "500":
+	//     description: Internal server error
+
+	opts, err := models.ParseAuditSearchOpts(ctx)
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "", err.Error())
+		return
+	}
+	auditParams := auditSearchParams(opts)
+	auditParams["request_url"] = ctx.Req.URL.RequestURI()
+
+	events, total, err := audit_model.SearchEvents(ctx, opts)
+	if err != nil {
+		log.Error("Error has occurred while searching audit events. Error: %v", err)
+		auditParams["error"] = "Error has occurred while searching audit events"
+		audit.CreateAndSendEvent(audit.AuditLogSearchEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to search audit events")
+		return
+	}
+
+	response := models.AuditEventListResponse{Total: total, Events: make([]models.AuditEventResponse, 0, len(events))}
+	for _, event := range events {
+		response.Events = append(response.Events, models.ToAuditEventResponse(event))
+	}
+	audit.CreateAndSendEvent(audit.AuditLogSearchEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusSuccess, ctx.Req.RemoteAddr, auditParams)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ExportAuditEvents выгружает найденные события аудита в CSV
+func ExportAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/audit/export admin exportAuditEvents
+	// ---
+	// summary: Export audit events stored in the database to CSV
+	// produces:
+	// - text/csv
+	// parameters:
+	// - name: event
+	//   in: query
+	//   description: event type description, e.g. "Create user"
+	//   type: string
+	// - name: username
+	//   in: query
+	//   description: name of the user who initiated the event
+	//   type: string
+	// - name: user_ip
+	//   in: query
+	//   description: IP address of the user
+	//   type: string
+	// - name: tenant
+	//   in: query
+	//   description: key or id of the tenant
+	//   type: string
+	// - name: repository
+	//   in: query
+	//   description: full repository name (owner/name) or repository key
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: event status, SUCCESS, FAIL or UNKNOWN
+	//   type: string
+	// - name: from
+	//   in: query
+	//   description: start of the time range in RFC3339 format
+	//   type: string
+	//   format: date-time
+	// - name: to
+	//   in: query
+	//   description: end of the time range in RFC3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     description: CSV file with audit events
+	//   "400":
+	//     description: Invalid search parameters
+
+	opts, err := models.ParseAuditSearchOpts(ctx)
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "", err.Error())
+		return
+	}
+	auditParams := auditSearchParams(opts)
+	auditParams["request_url"] = ctx.Req.URL.RequestURI()
+
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if err = audit_service.WriteEventsCSV(ctx, ctx.Resp, *opts); err != nil {
+		// заголовки уже отправлены, поэтому ошибку можно только залогировать
+		log.Error("Error has occurred while exporting audit events. Error: %v", err)
+		auditParams["error"] = "Error has occurred while exporting audit events"
+		audit.CreateAndSendEvent(audit.AuditLogExportEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+		return
+	}
+	audit.CreateAndSendEvent(audit.AuditLogExportEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusSuccess, ctx.Req.RemoteAddr, auditParams)
+}
@@ -0,0 +1,141 @@
+package admin
+
+import (
+	gocontext "context"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/routers/api/v2/models"
+	"code.gitea.io/gitea/services/background_task"
+)
+
+// ListBackgroundTasks возвращает страницу фоновых задач очереди
+func ListBackgroundTasks(ctx *context.APIContext) {
+	// swagger:operation GET /admin/background_tasks admin listBackgroundTasks
+	// ---
+	// summary: List background tasks of a queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: queue
+	//   in: query
+	//   description: queue of the tasks, for example unit_links_sender, unit_links_push or code_hub_counter
+	//   type: string
+	//   required: true
+	// - name: status
+	//   in: query
+	//   description: task status, pending, running, dead or done
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/backgroundTaskListResponse"
+	//   "400":
+	//     description: Invalid search parameters
+	//   "500":
+	//     description: Internal server error
+
+	opts, err := models.ParseBackgroundTaskFindOpts(ctx)
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	tasks, total, err := background_task.FindTasks(ctx, *opts)
+	if err != nil {
+		log.Error("Error has occurred while finding background tasks of queue %s. Error: %v", opts.Queue, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to find background tasks")
+		return
+	}
+
+	response := models.BackgroundTaskListResponse{Total: total, Tasks: make([]models.BackgroundTaskResponse, 0, len(tasks))}
+	for _, task := range tasks {
+		response.Tasks = append(response.Tasks, models.ToBackgroundTaskResponse(task))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RetryBackgroundTask возвращает фоновую задачу в очередь
+func RetryBackgroundTask(ctx *context.APIContext) {
+	// swagger:operation POST /admin/background_tasks/{queue}/{id}/retry admin retryBackgroundTask
+	// ---
+	// summary: Return a pending or dead background task to the queue for immediate processing and reset its attempts
+	// parameters:
+	// - name: queue
+	//   in: path
+	//   description: queue of the task, for example unit_links_sender, unit_links_push or code_hub_counter
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the task
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: Task is returned to the queue
+	//   "404":
+	//     description: Queue or task is not found or the task is being processed
+	//   "500":
+	//     description: Internal server error
+
+	changeBackgroundTask(ctx, background_task.RetryTask)
+}
+
+// DiscardBackgroundTask удаляет фоновую задачу из очереди
+func DiscardBackgroundTask(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/background_tasks/{queue}/{id} admin discardBackgroundTask
+	// ---
+	// summary: Delete a background task that is not being processed from the queue
+	// parameters:
+	// - name: queue
+	//   in: path
+	//   description: queue of the task, for example unit_links_sender, unit_links_push or code_hub_counter
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the task
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: Task is deleted
+	//   "404":
+	//     description: Queue or task is not found or the task is being processed
+	//   "500":
+	//     description: Internal server error
+
+	changeBackgroundTask(ctx, background_task.DiscardTask)
+}
+
+func changeBackgroundTask(ctx *context.APIContext, change func(ctx gocontext.Context, queue background_task.Queue, taskID int64, auditInfo auditutils.AuditRequiredParams) error) {
+	queue, err := background_task.ParseQueue(ctx.Params("queue"))
+	if err != nil {
+		ctx.NotFound()
+		return
+	}
+	taskID := ctx.ParamsInt64("id")
+
+	if err = change(ctx, queue, taskID, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		if background_task.IsErrTaskNotFound(err) {
+			ctx.NotFound()
+			return
+		}
+		log.Error("Error has occurred while changing background task %d of queue %s. Error: %v", taskID, queue, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to change background task")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -1,14 +1,17 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
 
 	"code.gitea.io/gitea/models/role_model"
 	"code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	audit "code.gitea.io/gitea/modules/sbt/audit/utils"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v2/cache"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/privileges"
 )
@@ -69,6 +72,13 @@ func (s *Server) ApplyPrivileges(ctx *context.APIContext) {
 	//                       privilege_group:
 	//                         type: string
 	//                         description: Name of the privilege group to be granted
+	//                       expires_at:
+	//                         type: string
+	//                         format: date-time
+	//                         description: Time when the granted privilege group is revoked automatically. Privilege is permanent if empty
+	//                       repository_key:
+	//                         type: string
+	//                         description: Key of the project repository. Privilege group is granted to the whole project if empty
 	//           revoke:
 	//             type: array
 	//             items:
@@ -91,6 +101,9 @@ func (s *Server) ApplyPrivileges(ctx *context.APIContext) {
 	//                       privilege_group:
 	//                         type: string
 	//                         description: Name of the privilege group to be revoked
+	//                       repository_key:
+	//                         type: string
+	//                         description: Key of the project repository. Privilege group is revoked from the whole project if empty
 	// responses:
 	//   "200":
 	//     description: Privileges successfully applied
@@ -325,6 +338,9 @@ func (s *Server) GetPrivileges(ctx *context.APIContext) {
 	//         user_key:
 	//           type: string
 	//           description: Key of the user
+	//         repository_key:
+	//           type: string
+	//           description: Key of the project repository. Privilege group granted to the repository is returned if present
 	// responses:
 	//   200:
 	//     description: Privileges successfully retrieved
@@ -355,6 +371,9 @@ func (s *Server) GetPrivileges(ctx *context.APIContext) {
 	//                     privilege_group:
 	//                       type: string
 	//                       description: Assigned privilege group
+	//                     repository_key:
+	//                       type: string
+	//                       description: Key of the project repository the privilege group is assigned to
 	//   "400":
 	//     description: Bad request
 	//     schema:
@@ -416,3 +435,186 @@ func (s *Server) GetPrivileges(ctx *context.APIContext) {
 	}
 	ctx.JSON(http.StatusOK, result)
 }
+
+// ExplainPrivilege метод для объяснения решения о доступе пользователя
+func (s *Server) ExplainPrivilege(ctx *context.APIContext) {
+	// swagger:operation POST /admin/privileges/explain admin explainPrivilege
+	// ---
+	// summary: Explain why user can or can not perform the action
+	// description: This endpoint evaluates the role model for the user without changing privileges and returns the decision with matched policies, groupings and the source of user privileges.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: User, project and action to be checked. At least one of action or custom_privilege is required
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/ExplainPrivilegeRequest"
+	// responses:
+	//   "200":
+	//     description: Decision with the checks of the role model
+	//     schema:
+	//       "$ref": "#/definitions/ExplainPrivilegeResponse"
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: User, project or repository not found
+	//   "500":
+	//     description: Internal server error
+
+	form := web.GetForm(ctx).(*forms.ExplainPrivilegeRequest)
+	if err := form.Validate(); err != nil {
+		log.Debug("Input params for explaining privilege are not valid: %v", err)
+		ctx.Error(http.StatusBadRequest, "validation error", err)
+		return
+	}
+	result, err := s.processor.ExplainPrivilegeRequest(ctx, *form)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) || tenant.IsTenantOrganizationsNotExists(err) ||
+			errors.Is(err, cache.ErrTenantNotFound) || errors.Is(err, cache.ErrProjectNotFound) || errors.Is(err, cache.ErrRepositoryNotFound) {
+			log.Debug("Subject of privilege explanation not found: %v", err)
+			ctx.Error(http.StatusNotFound, "Explain privilege", err)
+			return
+		}
+		log.Error("Error has occurred while explaining privilege: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Explain privilege", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ExportPrivilegesState метод для выгрузки декларативного описания привилегий
+func (s *Server) ExportPrivilegesState(ctx *context.APIContext) {
+	// swagger:operation GET /admin/privileges/state admin exportPrivilegesState
+	// ---
+	// summary: Export privileges of tenants as declarative state
+	// description: This endpoint exports project, repository and team privileges and custom groups of the role model. The result can be changed and passed to the plan and apply endpoints.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant
+	//   in: query
+	//   description: Keys of the tenants to be exported. Either tenant or all_tenants is required
+	//   type: array
+	//   items:
+	//     type: string
+	// - name: all_tenants
+	//   in: query
+	//   description: Export privileges of all tenants
+	//   type: boolean
+	// responses:
+	//   "200":
+	//     description: Declarative privileges state
+	//     schema:
+	//       "$ref": "#/definitions/PrivilegesStateExportResponse"
+	//   "400":
+	//     description: Bad request
+	//   "500":
+	//     description: Internal server error
+
+	state, warnings, err := s.processor.ExportPrivilegesState(ctx, ctx.FormStrings("tenant"), ctx.FormBool("all_tenants"))
+	if err != nil {
+		if privileges.IsErrInvalidPrivilegesState(err) {
+			log.Debug("Privileges state scope is not valid: %v", err)
+			ctx.Error(http.StatusBadRequest, "validation error", err)
+			return
+		}
+		log.Error("Error has occurred while exporting privileges state: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Export privileges state", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, forms.PrivilegesStateExportResponse{State: state, Warnings: warnings})
+}
+
+// PlanPrivilegesState метод для сравнения описания привилегий с текущим состоянием
+func (s *Server) PlanPrivilegesState(ctx *context.APIContext) {
+	// swagger:operation POST /admin/privileges/state/plan admin planPrivilegesState
+	// ---
+	// summary: Compare declarative privileges state with the role model
+	// description: This endpoint returns privileges to be added and removed to bring the role model of the listed tenants (or all tenants if all_tenants is set) to the passed state. Privileges are not changed.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Desired privileges state
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PrivilegesState"
+	// responses:
+	//   "200":
+	//     description: Privileges to be added and removed
+	//     schema:
+	//       "$ref": "#/definitions/PrivilegesPlan"
+	//   "400":
+	//     description: Bad request
+	//   "500":
+	//     description: Internal server error
+
+	form := web.GetForm(ctx).(*forms.PrivilegesState)
+	plan, err := s.processor.PlanPrivilegesState(ctx, *form)
+	if err != nil {
+		if privileges.IsErrInvalidPrivilegesState(err) {
+			log.Debug("Privileges state is not valid: %v", err)
+			ctx.Error(http.StatusBadRequest, "validation error", err)
+			return
+		}
+		log.Error("Error has occurred while planning privileges state: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Plan privileges state", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, plan)
+}
+
+// ApplyPrivilegesState метод для применения описания привилегий
+func (s *Server) ApplyPrivilegesState(ctx *context.APIContext) {
+	// swagger:operation POST /admin/privileges/state admin applyPrivilegesState
+	// ---
+	// summary: Apply declarative privileges state to the role model
+	// description: This endpoint adds and removes privileges of the listed tenants (or all tenants if all_tenants is set) to bring the role model to the passed state. Database changes are committed in one transaction before policies are changed, policies are restored if any policy change fails. A plan removing more privileges than PRIVILEGES_STATE_MAX_REMOVALS is refused unless force is set. Custom groups are managed by configuration and are not applied.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: force
+	//   in: query
+	//   description: Apply the state even if it removes more privileges than allowed
+	//   type: boolean
+	// - name: body
+	//   in: body
+	//   description: Desired privileges state
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PrivilegesState"
+	// responses:
+	//   "200":
+	//     description: Result of applying with the applied plan
+	//     schema:
+	//       "$ref": "#/definitions/PrivilegesStateApplyResponse"
+	//   "400":
+	//     description: Bad request
+	//   "409":
+	//     description: Plan removes more privileges than allowed without force
+	//   "500":
+	//     description: Internal server error
+
+	form := web.GetForm(ctx).(*forms.PrivilegesState)
+	auditValues := audit.NewRequiredAuditParamsFromApiContext(ctx)
+	result, err := s.processor.ApplyPrivilegesState(ctx, *form, ctx.FormBool("force"), auditValues)
+	if err != nil {
+		if privileges.IsErrInvalidPrivilegesState(err) {
+			log.Debug("Privileges state is not valid: %v", err)
+			ctx.Error(http.StatusBadRequest, "validation error", err)
+			return
+		}
+		if privileges.IsErrPrivilegesStateRemovalsExceeded(err) {
+			log.Debug("Privileges state is refused: %v", err)
+			ctx.Error(http.StatusConflict, "Apply privileges state", err)
+			return
+		}
+		log.Error("Error has occurred while applying privileges state: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Apply privileges state", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
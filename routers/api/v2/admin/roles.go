@@ -0,0 +1,252 @@
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/role_model"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v2/models"
+	role_service "code.gitea.io/gitea/services/role"
+)
+
+// toRoleResponse конвертирует описание роли в ответ API
+func toRoleResponse(role *role_model.ScRole) models.RoleResponse {
+	return models.RoleResponse{
+		Name:         role.Name,
+		DisplayName:  role.DisplayName,
+		Actions:      role.GetActions(),
+		Parent:       role.Parent,
+		IsSystem:     role.IsSystem,
+		IsAssignable: role.IsAssignable,
+	}
+}
+
+// findRoleByName ищет роль по имени из параметра запроса
+func findRoleByName(ctx *context.APIContext) (*role_model.ScRole, bool) {
+	name := ctx.FormString("name")
+	if name == "" {
+		ctx.Error(http.StatusBadRequest, "", "Role name is required")
+		return nil, false
+	}
+	role, err := role_model.GetRoleDefinitionByName(ctx, name)
+	if err != nil {
+		if role_model.IsErrRoleDefinitionNotExist(err) {
+			log.Debug("Role '%s' not found", name)
+			ctx.Error(http.StatusNotFound, "", "Role not found")
+		} else {
+			log.Error("Error has occurred while getting role '%s'. Error: %v", name, err)
+			ctx.Error(http.StatusInternalServerError, "", "Fail to get role")
+		}
+		return nil, false
+	}
+	return role, true
+}
+
+// writeRoleError отправляет ответ с ошибкой изменения роли
+func writeRoleError(ctx *context.APIContext, err error) {
+	switch {
+	case role_model.IsErrRoleDefinitionInvalid(err):
+		ctx.Error(http.StatusBadRequest, "", err.Error())
+	case role_model.IsErrRoleDefinitionAlreadyExist(err), role_model.IsErrRoleDefinitionInUse(err):
+		ctx.Error(http.StatusConflict, "", err.Error())
+	default:
+		ctx.Error(http.StatusInternalServerError, "", "Fail to change role")
+	}
+}
+
+// GetRoles возвращает все роли ролевой модели
+func GetRoles(ctx *context.APIContext) {
+	// swagger:operation GET /admin/roles admin getRoles
+	// ---
+	// summary: Returns all roles of the role model with their actions
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/roleListResponse"
+	//   "500":
+	//     description: Internal server error
+
+	roles, err := role_model.GetAllRoleDefinitions(ctx)
+	if err != nil {
+		log.Error("Error has occurred while getting roles. Error: %v", err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to get roles")
+		return
+	}
+	response := models.RoleListResponse{Roles: make([]models.RoleResponse, 0, len(roles))}
+	for _, role := range roles {
+		response.Roles = append(response.Roles, toRoleResponse(role))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateRole создает роль
+func CreateRole(ctx *context.APIContext) {
+	// swagger:operation POST /admin/roles admin createRole
+	// ---
+	// summary: Creates role with the set of actions
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Role to be created
+	//   required: true
+	//   schema:
+	//     type: object
+	//     required:
+	//       - name
+	//       - actions
+	//     properties:
+	//       name:
+	//         type: string
+	//         description: Unique name of the role used in privileges
+	//       display_name:
+	//         type: string
+	//         description: Name of the role shown to users
+	//       actions:
+	//         type: array
+	//         items:
+	//           type: string
+	//         description: Actions allowed to the role
+	//       is_assignable:
+	//         type: boolean
+	//         description: Role can be granted to users in projects, true if empty
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/roleResponse"
+	//   "400":
+	//     description: Bad request
+	//   "409":
+	//     description: Role already exists
+	//   "500":
+	//     description: Internal server error
+
+	form := web.GetForm(ctx).(*models.CreateRoleOptions)
+	if err := form.Validate(); err != nil {
+		log.Debug("Input params for creating role are not valid: %v", err)
+		ctx.Error(http.StatusBadRequest, "", "Incorrect params")
+		return
+	}
+
+	opts := role_service.CreateRoleOptions{
+		Name:         form.Name,
+		DisplayName:  form.DisplayName,
+		Actions:      form.Actions,
+		IsAssignable: form.IsAssignable == nil || *form.IsAssignable,
+	}
+	role, err := role_service.CreateRole(ctx, opts, auditutils.NewRequiredAuditParamsFromApiContext(ctx))
+	if err != nil {
+		log.Error("Error has occurred while creating role '%s'. Error: %v", form.Name, err)
+		writeRoleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, toRoleResponse(role))
+}
+
+// UpdateRole изменяет роль
+func UpdateRole(ctx *context.APIContext) {
+	// swagger:operation PUT /admin/roles admin updateRole
+	// ---
+	// summary: Changes display name, actions or availability of the role
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: name
+	//   in: query
+	//   description: name of role
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   description: Role fields to be changed
+	//   required: true
+	//   schema:
+	//     type: object
+	//     properties:
+	//       display_name:
+	//         type: string
+	//         description: Name of the role shown to users
+	//       actions:
+	//         type: array
+	//         items:
+	//           type: string
+	//         description: Actions allowed to the role
+	//       is_assignable:
+	//         type: boolean
+	//         description: Role can be granted to users in projects
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/roleResponse"
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	form := web.GetForm(ctx).(*models.UpdateRoleOptions)
+	if err := form.Validate(); err != nil {
+		log.Debug("Input params for updating role are not valid: %v", err)
+		ctx.Error(http.StatusBadRequest, "", "Incorrect params")
+		return
+	}
+
+	role, ok := findRoleByName(ctx)
+	if !ok {
+		return
+	}
+
+	opts := role_service.UpdateRoleOptions{
+		DisplayName:  form.DisplayName,
+		Actions:      form.Actions,
+		IsAssignable: form.IsAssignable,
+	}
+	if err := role_service.UpdateRole(ctx, role, opts, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while updating role '%s'. Error: %v", role.Name, err)
+		writeRoleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+// DeleteRole удаляет роль
+func DeleteRole(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/roles admin deleteRole
+	// ---
+	// summary: Deletes role. System roles and roles granted to users can not be deleted
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: name
+	//   in: query
+	//   description: name of role
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: Role deleted
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "409":
+	//     description: Role is granted to users
+	//   "500":
+	//     description: Internal server error
+
+	role, ok := findRoleByName(ctx)
+	if !ok {
+		return
+	}
+
+	if err := role_service.DeleteRole(ctx, role, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while deleting role '%s'. Error: %v", role.Name, err)
+		writeRoleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -71,6 +71,7 @@ import (
 	"github.com/go-chi/cors"
 
 	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/code_hub_usage_stat/code_hub_usage_stat_db"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/external_metric_counter/external_metric_counter_db"
 	"code.gitea.io/gitea/models/internal_metric_counter/internal_metric_counter_db"
@@ -131,7 +132,7 @@ func Routes(ctx gocontext.Context) *web.Route {
 	codeHubMark := marks.GetCodeHubMark(setting.CodeHub.CodeHubMarkLabelName)
 	repoServer := repo.NewRepoServer(role_model.CheckUserPermissionToOrganization, repoKeyDb, editorRepoMarks, codeHubMark)
 	tenantServer := tenant.NewTenantServer()
-	internalMetricServer := internal_counter.New(internalMetricDB, repoKeyDb, setting.CodeHub.InternalMetricsNamesList, setting.CodeHub.CodeHubMetricEnabled)
+	internalMetricServer := internal_counter.New(internalMetricDB, code_hub_usage_stat_db.New(), repoKeyDb, setting.CodeHub.InternalMetricsNamesList, setting.CodeHub.CodeHubMetricEnabled)
 	externalMetricServer := external_counter.New(externalMetricDB, repoKeyDb, setting.CodeHub.CodeHubMetricEnabled)
 	enforcer := role_model.GetSecurityEnforcer()
 	privilege, err := privileges2.NewPrivilege(engine, enforcer)
@@ -187,6 +188,32 @@ func Routes(ctx gocontext.Context) *web.Route {
 			m.Group("/privileges", func() {
 				m.Post("", reqToken(auth_model.AccessTokenScopeWritePrivileges), bind(forms.ApplyPrivilegeRequest{}), server.ApplyPrivileges)
 				m.Get("", reqToken(auth_model.AccessTokenScopeReadPrivileges), bind(forms.GetPrivilegesRequest{}), server.GetPrivileges)
+				m.Post("/explain", reqToken(auth_model.AccessTokenScopeReadPrivileges), bind(forms.ExplainPrivilegeRequest{}), server.ExplainPrivilege)
+				m.Group("/state", func() {
+					m.Get("", reqToken(auth_model.AccessTokenScopeReadPrivileges), server.ExportPrivilegesState)
+					m.Post("", reqToken(auth_model.AccessTokenScopeWritePrivileges), bind(forms.PrivilegesState{}), server.ApplyPrivilegesState)
+					m.Post("/plan", reqToken(auth_model.AccessTokenScopeReadPrivileges), bind(forms.PrivilegesState{}), server.PlanPrivilegesState)
+				})
+			})
+			m.Group("/roles", func() {
+				m.Get("", reqToken(auth_model.AccessTokenScopeReadPrivileges), admin.GetRoles)
+				m.Post("", reqToken(auth_model.AccessTokenScopeWritePrivileges), bind(models.CreateRoleOptions{}), admin.CreateRole)
+				m.Put("", reqToken(auth_model.AccessTokenScopeWritePrivileges), bind(models.UpdateRoleOptions{}), admin.UpdateRole)
+				m.Delete("", reqToken(auth_model.AccessTokenScopeWritePrivileges), admin.DeleteRole)
+			})
+			m.Group("/background_tasks", func() {
+				m.Get("", admin.ListBackgroundTasks)
+				m.Post("/{queue}/{id}/retry", admin.RetryBackgroundTask)
+				m.Delete("/{queue}/{id}", admin.DiscardBackgroundTask)
+			}, reqToken(auth_model.AccessTokenScopeSudo), reqSiteAdmin())
+			m.Group("/audit", func() {
+				m.Get("", admin.SearchAuditEvents)
+				m.Get("/export", admin.ExportAuditEvents)
+			}, reqToken(auth_model.AccessTokenScopeSudo), reqSiteAdmin(), func(ctx *context.APIContext) {
+				if !setting.AuditSinks.Database.Enabled {
+					ctx.NotFound()
+					return
+				}
 			})
 		})
 		m.Group("/projects", func() {
@@ -196,6 +223,27 @@ func Routes(ctx gocontext.Context) *web.Route {
 		m.Group("/tenants", func() {
 			m.Get("/", reqToken(auth_model.AccessTokenScopeReadTenant), tenantServer.GetTenantByKey)
 			m.Post("/", reqToken(auth_model.AccessTokenScopeWriteTenant), bind(models.CreateTenantOptions{}), tenantServer.CreateTenant)
+			m.Patch("/", reqToken(auth_model.AccessTokenScopeWriteTenant), bind(models.UpdateTenantOptions{}), tenantServer.UpdateTenant)
+			m.Delete("/", reqToken(auth_model.AccessTokenScopeWriteTenant), tenantServer.DeleteTenant)
+			m.Post("/archive", reqToken(auth_model.AccessTokenScopeWriteTenant), tenantServer.ArchiveTenant)
+			m.Get("/quotas", reqToken(auth_model.AccessTokenScopeReadTenant), tenantServer.GetTenantQuotas)
+			m.Put("/quotas", reqToken(auth_model.AccessTokenScopeWriteTenant), bind(models.UpdateTenantQuotasOptions{}), tenantServer.UpdateTenantQuotas)
+			m.Group("/task_tracker", func() {
+				m.Get("", reqToken(auth_model.AccessTokenScopeReadTenant), tenantServer.GetTaskTrackerBindings)
+				m.Put("", reqToken(auth_model.AccessTokenScopeWriteTenant), bind(models.SetTaskTrackerBindingOptions{}), tenantServer.SetTaskTrackerBinding)
+				m.Delete("", reqToken(auth_model.AccessTokenScopeWriteTenant), tenantServer.DeleteTaskTrackerBinding)
+				m.Group("/unit_code_patterns", func() {
+					m.Get("", reqToken(auth_model.AccessTokenScopeReadTenant), tenantServer.GetUnitCodePatterns)
+					m.Put("", reqToken(auth_model.AccessTokenScopeWriteTenant), bind(models.SetUnitCodePatternsOptions{}), tenantServer.SetUnitCodePatterns)
+					m.Delete("", reqToken(auth_model.AccessTokenScopeWriteTenant), tenantServer.DeleteUnitCodePatterns)
+					m.Post("/preview", reqToken(auth_model.AccessTokenScopeReadTenant), bind(models.PreviewUnitCodesOptions{}), tenantServer.PreviewUnitCodes)
+				})
+			}, func(ctx *context.APIContext) {
+				if !setting.TaskTracker.Enabled {
+					ctx.NotFound()
+					return
+				}
+			})
 		})
 
 		m.Group("/projects", func() {
@@ -272,6 +320,16 @@ func buildAuthGroup() *auth.Group {
 	return group
 }
 
+// reqSiteAdmin пользователь должен быть администратором
+func reqSiteAdmin() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if !ctx.IsUserSiteAdmin() {
+			ctx.Error(http.StatusForbidden, "reqSiteAdmin", "user should be the site admin")
+			return
+		}
+	}
+}
+
 // Contexter middleware already checks token for user sign in process.
 func reqToken(requiredScope auth_model.AccessTokenScope) func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/role_model"
 	"code.gitea.io/gitea/models/tenant"
 	user_model "code.gitea.io/gitea/models/user"
@@ -14,6 +16,7 @@ import (
 
 var ErrTenantNotFound = errors.New("Err: tenant not exists")
 var ErrProjectNotFound = errors.New("Err: project not exists")
+var ErrRepositoryNotFound = errors.New("Err: repository not exists in project")
 
 // RequestCache структура для кэша запросов
 type RequestCache struct {
@@ -21,6 +24,7 @@ type RequestCache struct {
 	users      map[string]*user_model.User
 	tenants    map[string]*tenant.ScTenantOrganizations
 	privileges map[int64][]role_model.EnrichedPrivilege
+	repos      map[string]*repo_model.Repository
 }
 
 // Инициализация нового кэша
@@ -30,6 +34,7 @@ func NewRequestCache(engine db.Engine) RequestCache {
 		users:      make(map[string]*user_model.User),
 		tenants:    make(map[string]*tenant.ScTenantOrganizations),
 		privileges: make(map[int64][]role_model.EnrichedPrivilege),
+		repos:      make(map[string]*repo_model.Repository),
 	}
 }
 
@@ -145,3 +150,39 @@ func (rc *RequestCache) GetPrivileges(ctx context.Context, orgID int64) ([]role_
 	rc.privileges[orgID] = privileges
 	return privileges, nil
 }
+
+// GetRepository получения репозитория проекта по внешнему ключу
+func (rc *RequestCache) GetRepository(ctx context.Context, repoKey string, orgID int64) (*repo_model.Repository, error) {
+	if repo, exists := rc.repos[repoKey]; exists {
+		if repo.OwnerID != orgID {
+			return nil, ErrRepositoryNotFound
+		}
+		return repo, nil
+	}
+	scRepoKey, err := repo_model.NewRepoKeyDB(rc.engine).GetRepoByKey(ctx, repoKey)
+	if err != nil {
+		if repo_model.IsErrorRepoKeyDoesntExists(err) {
+			log.Debug("Repository with repoKey %s is not exists", repoKey)
+			return nil, ErrRepositoryNotFound
+		}
+		log.Error("Error has occurred while getting repository key. Error: %v", err)
+		return nil, fmt.Errorf("get repository by key: %s. %w", repoKey, err)
+	}
+	repoID, err := strconv.ParseInt(scRepoKey.RepoID, 10, 64)
+	if err != nil {
+		log.Error("Error has occurred while parsing repository id: %s. Error: %v", scRepoKey.RepoID, err)
+		return nil, fmt.Errorf("parse repository id: %s. %w", scRepoKey.RepoID, err)
+	}
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		log.Error("Error has occurred while getting repository. Error: %v", err)
+		return nil, fmt.Errorf("get repository by ID: %d. %w", repoID, err)
+	}
+	rc.repos[repoKey] = repo
+	// проверяем, что репозиторий принадлежит проекту
+	if repo.OwnerID != orgID {
+		log.Debug("Repository %s does not belong to orgId: %d", repoKey, orgID)
+		return nil, ErrRepositoryNotFound
+	}
+	return repo, nil
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+
+	"code.gitea.io/gitea/clients/kafka"
+	"code.gitea.io/gitea/models/events/v2"
+	"code.gitea.io/gitea/models/events/v2/generic"
+	kafka_command_model "code.gitea.io/gitea/models/kafka_command"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const version = "2.0.0"
+
+// Init запускает чтение команд из топика [kafka.commands], если он активирован
+func Init(ctx gocontext.Context) error {
+	topicInfo, ok := setting.Kafka.Topics[setting.KafkaCommandTopic]
+	if !setting.Kafka.Enabled || !ok || !topicInfo.Enabled {
+		return nil
+	}
+	if topicInfo.Type != string(kafka.Consume) && topicInfo.Type != string(kafka.Multiple) {
+		return fmt.Errorf("kafka topic [%s] must have consume or multiple type", setting.KafkaCommandTopic)
+	}
+
+	d, err := newDispatcher(ctx)
+	if err != nil {
+		return err
+	}
+	c := &consumer{dispatcher: d}
+	go graceful.GetManager().RunWithShutdownContext(func(ctx gocontext.Context) {
+		if err := kafka.RunConsumerGroup(ctx, setting.Kafka.Commands.GroupID, []string{topicInfo.Name}, c.handle); err != nil {
+			log.Error("Error has occurred while reading Kafka commands. Error: %v", err)
+		}
+	})
+	return nil
+}
+
+// consumer обрабатывает сообщения топика команд
+type consumer struct {
+	dispatcher *dispatcher
+}
+
+// handle выполняет команду и отправляет ответ. Ответ сохраняется в таблице kafka_command по идентификатору
+// metadata.message_id, поэтому повторно доставленная выполненная команда не выполняется, а получает сохраненный ответ.
+// При временной ошибке возвращается ошибка, смещение не фиксируется, и команда выполняется повторно.
+// Команды, которые невозможно выполнить или которые не выполнены за [kafka.commands] MAX_ATTEMPTS попыток,
+// отправляются в топик недоставленных команд, чтобы не останавливать чтение партиции
+func (c *consumer) handle(ctx gocontext.Context, msg *sarama.ConsumerMessage) error {
+	command := new(events.Command)
+	if err := json.Unmarshal(msg.Value, command); err != nil {
+		return deadLetter(ctx, msg, fmt.Errorf("%w: cannot parse command: %v", errPoisonCommand, err))
+	}
+	if command.Metadata.MessageId == "" {
+		return deadLetter(ctx, msg, fmt.Errorf("%w: command has no message_id", errPoisonCommand))
+	}
+	log.Debug("Kafka command %s of type %s received", command.Metadata.MessageId, command.Type)
+
+	record, err := kafka_command_model.GetOrCreateCommand(ctx, command.Metadata.MessageId, string(command.Type))
+	if err != nil {
+		return err
+	}
+	switch record.Status {
+	case kafka_command_model.StatusDone:
+		log.Debug("Kafka command %s was already executed, sending saved reply", command.Metadata.MessageId)
+		return publishReply(ctx, command, []byte(record.Reply))
+	case kafka_command_model.StatusDead:
+		log.Debug("Kafka command %s was already sent to dead letter topic", command.Metadata.MessageId)
+		return nil
+	}
+
+	var result *commandResult
+	doer, err := user_model.GetUserByName(ctx, setting.Kafka.Commands.ServiceUser)
+	if err != nil || !doer.IsActive || doer.ProhibitLogin {
+		log.Error("Error has occurred while getting Kafka commands service user %s. Error: %v", setting.Kafka.Commands.ServiceUser, err)
+		result = &commandResult{code: http.StatusUnauthorized, message: "service user is not available"}
+	} else if result, err = c.dispatcher.dispatch(ctx, doer, command); err != nil {
+		return c.fail(ctx, msg, record, err)
+	}
+
+	data, err := newReply(command, result)
+	if err != nil {
+		return c.fail(ctx, msg, record, fmt.Errorf("%w: %v", errPoisonCommand, err))
+	}
+	if err = kafka_command_model.FinishCommand(ctx, record, string(data)); err != nil {
+		return err
+	}
+	return publishReply(ctx, command, data)
+}
+
+// fail сохраняет ошибку выполнения команды. Команда отправляется в топик недоставленных команд,
+// если ее невозможно выполнить или попытки исчерпаны, иначе ошибка возвращается для повторного выполнения
+func (c *consumer) fail(ctx gocontext.Context, msg *sarama.ConsumerMessage, record *kafka_command_model.Command, cause error) error {
+	dead := errors.Is(cause, errPoisonCommand) || record.Attempts+1 >= setting.Kafka.Commands.MaxAttempts
+	if dead {
+		if err := deadLetter(ctx, msg, cause); err != nil {
+			return err
+		}
+	}
+	if err := kafka_command_model.FailCommandAttempt(ctx, record, cause.Error(), dead); err != nil {
+		return err
+	}
+	if dead {
+		return nil
+	}
+	return fmt.Errorf("execute command %s, attempt %d: %w", record.CommandID, record.Attempts, cause)
+}
+
+// newReply создает ответ с результатом выполнения команды
+func newReply(command *events.Command, result *commandResult) ([]byte, error) {
+	commandReply := events.CommandReply{
+		Code:     result.code,
+		Metadata: newMetadata(&command.Metadata.MessageId),
+		Status:   events.SUCCESS,
+		Type:     string(command.Type),
+	}
+	if result.body != nil {
+		body, err := json.Marshal(result.body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal command result: %w", err)
+		}
+		rawBody := json.RawMessage(body)
+		commandReply.Result = &rawBody
+	}
+	if result.code < http.StatusOK || result.code >= http.StatusMultipleChoices {
+		commandReply.Status = events.FAILURE
+		commandReply.Error = &result.message
+	}
+	log.Debug("Kafka command %s of type %s finished with code %d", command.Metadata.MessageId, command.Type, result.code)
+
+	data, err := json.Marshal(commandReply)
+	if err != nil {
+		return nil, fmt.Errorf("marshal command reply: %w", err)
+	}
+	return data, nil
+}
+
+// publishReply отправляет ответ на команду в топик [kafka.command_replies]. Повторно отправленный ответ
+// содержит тот же metadata.message_id
+func publishReply(ctx gocontext.Context, command *events.Command, data []byte) error {
+	commandReply := events.CommandReply{}
+	if err := json.Unmarshal(data, &commandReply); err != nil {
+		return fmt.Errorf("unmarshal command reply: %w", err)
+	}
+	if err := kafka.Publish(ctx, setting.KafkaCommandReplyTopic, command.Metadata.MessageId, commandReply.Metadata.MessageId, data); err != nil {
+		return fmt.Errorf("publish command reply: %w", err)
+	}
+	return nil
+}
+
+// deadLetter отправляет сообщение, которое невозможно обработать, в топик [kafka.command_dead_letter]
+func deadLetter(ctx gocontext.Context, msg *sarama.ConsumerMessage, cause error) error {
+	log.Warn("Kafka command %s/%d/%d can not be processed: %v", msg.Topic, msg.Partition, msg.Offset, cause)
+
+	letter := events.DeadLetter{
+		Error:           cause.Error(),
+		Metadata:        newMetadata(nil),
+		OriginalMessage: string(msg.Value),
+		Source: events.DeadLetterSource{
+			Offset:    msg.Offset,
+			Partition: msg.Partition,
+			Topic:     msg.Topic,
+		},
+	}
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("marshal dead letter: %w", err)
+	}
+	key := msg.Topic + "/" + strconv.FormatInt(int64(msg.Partition), 10)
+	if err = kafka.Publish(ctx, setting.KafkaCommandDeadLetterTopic, key, letter.Metadata.MessageId, data); err != nil {
+		return fmt.Errorf("publish dead letter: %w", err)
+	}
+	return nil
+}
+
+// newMetadata создает метаданные сообщения, correlationID - идентификатор команды
+func newMetadata(correlationID *string) generic.Metadata {
+	return generic.Metadata{
+		CorrelationMessageId: correlationID,
+		MessageCreateTs:      strconv.FormatInt(int64(timeutil.TimeStampNow()), 10),
+		MessageId:            uuid.NewString(),
+		Producer: generic.Producer{
+			Id: setting.Kafka.Issuer,
+		},
+		Version: version,
+	}
+}
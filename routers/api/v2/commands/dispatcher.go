@@ -0,0 +1,293 @@
+package commands
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gitea.com/go-chi/binding"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/events/v2"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/repo_marks/marks"
+	"code.gitea.io/gitea/models/repo_marks/repo_marks_db"
+	"code.gitea.io/gitea/models/role_model"
+	tenant_model "code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v2/models"
+	apirepo "code.gitea.io/gitea/routers/api/v2/models/repo"
+	"code.gitea.io/gitea/routers/private/repo_mark"
+	"code.gitea.io/gitea/services/forms"
+	privileges_service "code.gitea.io/gitea/services/privileges"
+	project_service "code.gitea.io/gitea/services/project"
+	repo_service "code.gitea.io/gitea/services/repository"
+	tenant_service "code.gitea.io/gitea/services/tenant"
+)
+
+// errPoisonCommand команда не может быть выполнена ни при какой попытке и отправляется в топик недоставленных команд
+var errPoisonCommand = errors.New("poison command")
+
+// remoteAddress адрес, с которого выполняются команды, используется в аудите
+const remoteAddress = "kafka"
+
+// commandHandler выполняет команду сервисом от имени сервисного пользователя doer.
+// Ошибка errPoisonCommand означает, что команду невозможно выполнить, остальные ошибки временные
+type commandHandler func(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error)
+
+// commandResult результат выполнения команды
+type commandResult struct {
+	code    int    // код ответа, такой же, как у запроса API v2
+	body    any    // тело ответа, nil если команда не возвращает результат
+	message string // сообщение об ошибке, если команда не выполнена
+}
+
+// validatedForm форма команды с проверкой значений полей
+type validatedForm interface {
+	Validate() error
+}
+
+// dispatcher выполняет команды сервисами от имени сервисного пользователя
+type dispatcher struct {
+	handlers map[events.CommandType]commandHandler
+}
+
+// newDispatcher создает обработчики команд с теми же сервисами, что и маршруты API v2
+func newDispatcher(ctx gocontext.Context) (*dispatcher, error) {
+	engine := db.GetEngine(ctx)
+	repoKeyDb := repo_model.NewRepoKeyDB(engine)
+	editorRepoMarks := repo_mark.NewRepoMarksEditor(repo_marks_db.NewRepoMarksDB(engine), repoKeyDb)
+	codeHubMark := marks.GetCodeHubMark(setting.CodeHub.CodeHubMarkLabelName)
+	tenantRepos := repo_service.NewTenantRepoService(role_model.CheckUserPermissionToOrganization, repoKeyDb, editorRepoMarks, codeHubMark)
+	privilege, err := privileges_service.NewPrivilege(engine, role_model.GetSecurityEnforcer())
+	if err != nil {
+		return nil, fmt.Errorf("create privileges service: %w", err)
+	}
+
+	return &dispatcher{handlers: map[events.CommandType]commandHandler{
+		events.CREATETENANT:     createTenant,
+		events.ARCHIVETENANT:    archiveTenant,
+		events.CREATEPROJECT:    createProject,
+		events.CREATEREPOSITORY: createRepository(tenantRepos),
+		events.APPLYPRIVILEGES:  applyPrivileges(privilege),
+		events.SETCODEHUBMARK:   setCodeHubMark(tenantRepos),
+	}}, nil
+}
+
+// dispatch выполняет команду обработчиком ее типа. Ошибка errPoisonCommand означает, что команду невозможно выполнить
+func (d *dispatcher) dispatch(ctx gocontext.Context, doer *user_model.User, command *events.Command) (result *commandResult, err error) {
+	handler, ok := d.handlers[command.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown command type %q", errPoisonCommand, command.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("%w: handler panicked: %v", errPoisonCommand, r)
+		}
+	}()
+	return handler(ctx, doer, command.Payload)
+}
+
+// decodeForm разбирает данные команды в форму. Данные, которые не соответствуют схеме команды, означают,
+// что команду невозможно выполнить. Ошибка проверки значений полей возвращается в результате с кодом 400
+func decodeForm(payload []byte, form validatedForm) (*commandResult, error) {
+	if err := json.Unmarshal(payload, form); err != nil {
+		return nil, fmt.Errorf("%w: invalid payload: %v", errPoisonCommand, err)
+	}
+	if errs := binding.RawValidate(form); len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s: %s", errPoisonCommand, strings.Join(errs[0].FieldNames, ","), errs[0].Error())
+	}
+	if err := form.Validate(); err != nil {
+		return &commandResult{code: http.StatusBadRequest, message: err.Error()}, nil
+	}
+	return nil, nil
+}
+
+// failure возвращает результат с кодом ошибки сервиса. Ошибки, для которых повторное выполнение команды
+// не изменит результат, возвращаются в ответе, остальные ошибки временные
+func failure(err error) (*commandResult, error) {
+	code := failureCode(err)
+	if code == 0 {
+		return nil, err
+	}
+	return &commandResult{code: code, message: err.Error()}, nil
+}
+
+// failureCode возвращает код ответа для ошибки сервиса или 0 для временной ошибки
+func failureCode(err error) int {
+	switch {
+	case tenant_model.IsErrTenantAlreadyExists(err),
+		tenant_model.IsErrTenantNameAlreadyUsed(err),
+		tenant_model.IsErrTenantIsDeleting(err),
+		tenant_model.IsProjectKeyAlreadyUsed(err),
+		project_service.IsProjectNameAlreadyUsed(err),
+		repo_model.IsErrorRepoKeyAlreadyUsed(err),
+		repo_model.IsErrRepoAlreadyExist(err),
+		errors.As(err, &repo_marks_db.ErrMarkAlreadyExists{}):
+		return http.StatusConflict
+	case tenant_model.IsTenantOrganizationsNotExists(err),
+		tenant_model.IsTenantKeyNotExists(err),
+		user_model.IsErrUserNotExist(err),
+		repo_model.IsErrorRepoKeyDoesntExists(err),
+		repo_model.IsErrRepoNotExist(err):
+		return http.StatusNotFound
+	case repo_service.IsErrNoProjectPermission(err),
+		tenant_model.IsErrTenantQuotaExceeded(err):
+		return http.StatusForbidden
+	case tenant_model.IsErrTenantIsDefault(err),
+		tenant_model.IsTenantNotActive(err),
+		project_service.IsVisibilityIncorrect(err),
+		repo_model.IsErrCreateUserRepo(err),
+		repo_service.IsErrProjectNotPublic(err),
+		repo_service.IsErrRepoNotPublic(err):
+		return http.StatusBadRequest
+	}
+	return 0
+}
+
+// auditInfo обязательные параметры аудита команды
+func auditInfo(doer *user_model.User) auditutils.AuditRequiredParams {
+	return auditutils.AuditRequiredParams{
+		DoerName:      doer.Name,
+		DoerID:        strconv.FormatInt(doer.ID, 10),
+		RemoteAddress: remoteAddress,
+	}
+}
+
+// createTenant выполняет команду CREATE_TENANT
+func createTenant(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+	form := new(models.CreateTenantOptions)
+	if result, err := decodeForm(payload, form); result != nil || err != nil {
+		return result, err
+	}
+
+	tenant, err := tenant_service.CreateTenant(ctx, form.Name, form.TenantKey, auditInfo(doer))
+	if err != nil {
+		return failure(err)
+	}
+	return &commandResult{code: http.StatusCreated, body: models.TenantPostResponse{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		TenantKey: tenant.OrgKey,
+	}}, nil
+}
+
+// archiveTenantPayload данные команды ARCHIVE_TENANT
+type archiveTenantPayload struct {
+	TenantKey string `json:"tenant_key" binding:"Required"`
+}
+
+func (p *archiveTenantPayload) Validate() error {
+	return nil
+}
+
+// archiveTenant выполняет команду ARCHIVE_TENANT
+func archiveTenant(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+	form := new(archiveTenantPayload)
+	if result, err := decodeForm(payload, form); result != nil || err != nil {
+		return result, err
+	}
+
+	tenant, has, err := tenant_model.GetTenantByOrgKey(ctx, form.TenantKey)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant by tenant key %s: %w", form.TenantKey, err)
+	}
+	if !has {
+		return &commandResult{code: http.StatusNotFound, message: "Tenant does not exist"}, nil
+	}
+	if err = tenant_service.ArchiveTenant(ctx, tenant, auditInfo(doer)); err != nil {
+		return failure(err)
+	}
+	return &commandResult{code: http.StatusOK, body: models.TenantGetResponse{
+		ID:         tenant.ID,
+		Name:       tenant.Name,
+		IsActive:   tenant.IsActive,
+		IsArchived: tenant.IsArchived,
+		TenantKey:  tenant.OrgKey,
+	}}, nil
+}
+
+// createProject выполняет команду CREATE_PROJECT
+func createProject(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+	form := new(forms.CreateProjectRequest)
+	if result, err := decodeForm(payload, form); result != nil || err != nil {
+		return result, err
+	}
+
+	response, err := project_service.CreateProject(ctx, doer, *form)
+	if err != nil {
+		return failure(err)
+	}
+	return &commandResult{code: http.StatusCreated, body: response}, nil
+}
+
+// createRepository возвращает обработчик команды CREATE_REPOSITORY
+func createRepository(tenantRepos repo_service.TenantRepoService) commandHandler {
+	return func(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+		form := new(apirepo.CreateRepoOptions)
+		if result, err := decodeForm(payload, form); result != nil || err != nil {
+			return result, err
+		}
+
+		repo, org, err := tenantRepos.CreateTenantOrgRepo(ctx, doer, repo_service.CreateTenantOrgRepoOptions{
+			TenantKey:     form.TenantKey,
+			ProjectKey:    form.ProjectKey,
+			RepositoryKey: form.RepositoryKey,
+			Name:          form.Name,
+			Description:   form.Description,
+			DefaultBranch: form.DefaultBranch,
+			IsPrivate:     *form.Private,
+		}, auditInfo(doer))
+		if err != nil {
+			return failure(err)
+		}
+		return &commandResult{code: http.StatusCreated, body: apirepo.RepositoryPostResponse{
+			ID:            strconv.FormatInt(repo.ID, 10),
+			TenantKey:     form.TenantKey,
+			ProjectKey:    form.ProjectKey,
+			RepositoryKey: form.RepositoryKey,
+			DefaultBranch: repo.DefaultBranch,
+			Name:          repo.Name,
+			Private:       repo.IsPrivate,
+			URI:           fmt.Sprintf("/%s/%s", org.LowerName, repo.LowerName),
+		}}, nil
+	}
+}
+
+// applyPrivileges возвращает обработчик команды APPLY_PRIVILEGES
+func applyPrivileges(privilege privileges_service.PrivilegesProcessor) commandHandler {
+	return func(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+		form := new(forms.ApplyPrivilegeRequest)
+		if result, err := decodeForm(payload, form); result != nil || err != nil {
+			return result, err
+		}
+
+		response, err := privilege.ApplyPrivilegesRequest(ctx, *form, auditInfo(doer))
+		if err != nil {
+			return nil, fmt.Errorf("apply privileges: %w", err)
+		}
+		return &commandResult{code: http.StatusCreated, body: response}, nil
+	}
+}
+
+// setCodeHubMark возвращает обработчик команды SET_CODEHUB_MARK
+func setCodeHubMark(tenantRepos repo_service.TenantRepoService) commandHandler {
+	return func(ctx gocontext.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+		form := new(apirepo.SetMarkRequest)
+		if result, err := decodeForm(payload, form); result != nil || err != nil {
+			return result, err
+		}
+
+		if err := tenantRepos.SetCodeHubMark(ctx, doer, form.TenantKey, form.ProjectKey, form.RepoKey, auditInfo(doer)); err != nil {
+			return failure(err)
+		}
+		return &commandResult{code: http.StatusCreated}, nil
+	}
+}
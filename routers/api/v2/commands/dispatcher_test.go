@@ -0,0 +1,89 @@
+//go:build !correct
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/events/v2"
+	tenant_model "code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/routers/api/v2/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDispatch проверяет выполнение команды обработчиком ее типа и определение команд, которые невозможно выполнить
+func TestDispatch(t *testing.T) {
+	d := &dispatcher{handlers: map[events.CommandType]commandHandler{
+		events.CREATETENANT: func(ctx context.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+			form := new(models.CreateTenantOptions)
+			if result, err := decodeForm(payload, form); result != nil || err != nil {
+				return result, err
+			}
+			return &commandResult{code: http.StatusCreated, body: map[string]string{"name": form.Name, "doer": doer.Name}}, nil
+		},
+		events.ARCHIVETENANT: func(ctx context.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+			return failure(tenant_model.ErrTenantIsDefault{TenantID: "default"})
+		},
+		events.CREATEPROJECT: func(ctx context.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+			return failure(errors.New("database is not available"))
+		},
+		events.APPLYPRIVILEGES: func(ctx context.Context, doer *user_model.User, payload []byte) (*commandResult, error) {
+			panic("unexpected")
+		},
+	}}
+	doer := &user_model.User{ID: 1, Name: "service"}
+
+	result, err := d.dispatch(context.Background(), doer, &events.Command{Type: events.CREATETENANT, Payload: []byte(`{"tenant_key":"key","name":"tenant"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, &commandResult{code: http.StatusCreated, body: map[string]string{"name": "tenant", "doer": "service"}}, result)
+
+	result, err = d.dispatch(context.Background(), doer, &events.Command{Type: events.ARCHIVETENANT, Payload: []byte(`{"tenant_key":"default"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.code)
+
+	_, err = d.dispatch(context.Background(), doer, &events.Command{Type: events.CREATEPROJECT, Payload: []byte(`{}`)})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errPoisonCommand)
+
+	_, err = d.dispatch(context.Background(), doer, &events.Command{Type: events.CREATETENANT, Payload: []byte(`{"name":"tenant"}`)})
+	assert.ErrorIs(t, err, errPoisonCommand)
+
+	_, err = d.dispatch(context.Background(), doer, &events.Command{Type: events.CREATETENANT, Payload: []byte(`[]`)})
+	assert.ErrorIs(t, err, errPoisonCommand)
+
+	_, err = d.dispatch(context.Background(), doer, &events.Command{Type: events.APPLYPRIVILEGES, Payload: []byte(`{}`)})
+	assert.ErrorIs(t, err, errPoisonCommand)
+
+	_, err = d.dispatch(context.Background(), doer, &events.Command{Type: "DROP_DATABASE", Payload: []byte(`{}`)})
+	assert.ErrorIs(t, err, errPoisonCommand)
+}
+
+// TestNewReply проверяет ответ на успешно и неуспешно выполненную команду
+func TestNewReply(t *testing.T) {
+	command := &events.Command{Type: events.CREATETENANT}
+	command.Metadata.MessageId = "command-id"
+
+	data, err := newReply(command, &commandResult{code: http.StatusCreated, body: models.TenantPostResponse{ID: "id", Name: "tenant", TenantKey: "key"}})
+	assert.NoError(t, err)
+	reply := events.CommandReply{}
+	assert.NoError(t, json.Unmarshal(data, &reply))
+	assert.Equal(t, events.SUCCESS, reply.Status)
+	assert.Equal(t, http.StatusCreated, reply.Code)
+	assert.Equal(t, "command-id", *reply.Metadata.CorrelationMessageId)
+	assert.JSONEq(t, `{"id":"id","name":"tenant","tenant_key":"key"}`, string(*reply.Result))
+	assert.Nil(t, reply.Error)
+
+	data, err = newReply(command, &commandResult{code: http.StatusConflict, message: "tenant already exists"})
+	assert.NoError(t, err)
+	reply = events.CommandReply{}
+	assert.NoError(t, json.Unmarshal(data, &reply))
+	assert.Equal(t, events.FAILURE, reply.Status)
+	assert.Equal(t, "tenant already exists", *reply.Error)
+	assert.Nil(t, reply.Result)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"time"
 
+	"code.gitea.io/gitea/models/code_hub_usage_stat"
 	"code.gitea.io/gitea/models/internal_metric_counter"
 	"code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/tenant"
 	api_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/api/v2/models"
 	"code.gitea.io/gitea/routers/api/v2/models/metrics"
 )
@@ -18,25 +21,34 @@ type counterDB interface {
 	GetInternalMetricCounter(_ context.Context, repoID int64, metricKey string) (*internal_metric_counter.InternalMetricCounter, error)
 }
 
+type usageStatsDB interface {
+	GetDailyStats(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, from, to timeutil.TimeStamp) ([]*code_hub_usage_stat.CodeHubUsageStat, error)
+	CountUniqueUsers(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, from, to timeutil.TimeStamp) (int64, error)
+}
+
 type repoKeyDB interface {
 	GetRepoByKey(ctx context.Context, key string) (*repo.ScRepoKey, error)
 }
 
 type Server struct {
 	counterDB
+	usageStatsDB
 	repoKeyDB
 	metricsList    []string
 	counterEnabled bool
 }
 
-func New(internalMetricCounterDB counterDB, repoKeyDB repoKeyDB, metricsList []string, counterEnabled bool) Server {
-	return Server{counterDB: internalMetricCounterDB, repoKeyDB: repoKeyDB, metricsList: metricsList, counterEnabled: counterEnabled}
+func New(internalMetricCounterDB counterDB, usageStatsDB usageStatsDB, repoKeyDB repoKeyDB, metricsList []string, counterEnabled bool) Server {
+	return Server{counterDB: internalMetricCounterDB, usageStatsDB: usageStatsDB, repoKeyDB: repoKeyDB, metricsList: metricsList, counterEnabled: counterEnabled}
 }
 
 func (s Server) GetInternalMetricCounter(ctx *api_context.APIContext) {
 	// swagger:operation GET /projects/repos/metrics metrics getInternalMetric
 	// ---
 	// summary: Returns internal metric for repo
+	// description: Returns the current value of the metric counter. If from, to or interval is set,
+	//   returns internalMetricSeriesResponse with daily or weekly values of the usage metric
+	//   (unique_cloners, forks, stars, archive_downloads, package_downloads) instead
 	// produces:
 	// - application/json
 	// parameters:
@@ -52,6 +64,21 @@ func (s Server) GetInternalMetricCounter(ctx *api_context.APIContext) {
 	// - name: metric
 	//   in: query
 	//   type: string
+	// - name: from
+	//   in: query
+	//   description: First day of the period in format YYYY-MM-DD, by default 30 days or 12 weeks before to
+	//   type: string
+	//   format: date
+	// - name: to
+	//   in: query
+	//   description: Last day of the period in format YYYY-MM-DD, by default today
+	//   type: string
+	//   format: date
+	// - name: interval
+	//   in: query
+	//   description: Bucket size, by default day. Weeks start on Monday, days and weeks are in UTC
+	//   type: string
+	//   enum: [day, week]
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/internalMetricGetResponse"
@@ -127,6 +154,11 @@ func (s Server) getInternalMetricCounter(ctx *api_context.APIContext) {
 		return
 	}
 
+	if getOpts.IsSeries() {
+		s.getInternalMetricSeries(ctx, repoId, getOpts)
+		return
+	}
+
 	metricsMap := make(map[string]struct{})
 	for _, metric := range s.metricsList {
 		metricsMap[metric] = struct{}{}
@@ -147,3 +179,32 @@ func (s Server) getInternalMetricCounter(ctx *api_context.APIContext) {
 
 	ctx.JSON(http.StatusOK, metrics.InternalMetricGetResponse{Value: metric.MetricValue})
 }
+
+func (s Server) getInternalMetricSeries(ctx *api_context.APIContext, repoID int64, getOpts *metrics.InternalMetricGetOptions) {
+	metric := code_hub_usage_stat.Metric(getOpts.Metric)
+	known := false
+	for _, usageMetric := range code_hub_usage_stat.Metrics {
+		known = known || usageMetric == metric
+	}
+	if !known {
+		log.Debug("Usage metric with such key %s not declared", getOpts.Metric)
+		ctx.Error(http.StatusBadRequest, "", "Fail to find metric")
+		return
+	}
+
+	seriesRange, err := parseSeriesRange(getOpts, time.Now())
+	if err != nil {
+		log.Debug("Error has occurred while parsing period of metric %s: %v", getOpts.Metric, err)
+		ctx.Error(http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	series, err := s.getMetricSeries(ctx, repoID, metric, seriesRange)
+	if err != nil {
+		log.Error("Error has occurred while getting values of metric %s for repo %d: %v", getOpts.Metric, repoID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to get metric")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,124 @@
+package internal_counter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/code_hub_usage_stat"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v2/models/metrics"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// maxSeriesDays максимальная длина интервала запроса метрики по периодам
+	maxSeriesDays = 366
+	// defaultSeriesDays и defaultSeriesWeeks длина интервала, если не указано его начало
+	defaultSeriesDays  = 30
+	defaultSeriesWeeks = 12
+)
+
+// seriesRange интервал запроса метрики по периодам [start, end), выровненный по границам периодов
+type seriesRange struct {
+	interval code_hub_usage_stat.Interval
+	start    timeutil.TimeStamp
+	end      timeutil.TimeStamp
+}
+
+// parseSeriesRange проверяет параметры запроса метрики по периодам. Без from запрашиваются последние
+// defaultSeriesDays дней или defaultSeriesWeeks недель, без to - интервал до текущего дня включительно
+func parseSeriesRange(opts *metrics.InternalMetricGetOptions, now time.Time) (seriesRange, error) {
+	interval := code_hub_usage_stat.Interval(opts.Interval)
+	if interval == "" {
+		interval = code_hub_usage_stat.IntervalDay
+	}
+	if interval != code_hub_usage_stat.IntervalDay && interval != code_hub_usage_stat.IntervalWeek {
+		return seriesRange{}, fmt.Errorf("interval must be one of: %s, %s", code_hub_usage_stat.IntervalDay, code_hub_usage_stat.IntervalWeek)
+	}
+
+	to := code_hub_usage_stat.DayStart(timeutil.TimeStamp(now.Unix()))
+	if opts.To != "" {
+		parsed, err := time.Parse(dateLayout, opts.To)
+		if err != nil {
+			return seriesRange{}, fmt.Errorf("to must be a date in format YYYY-MM-DD")
+		}
+		to = timeutil.TimeStamp(parsed.Unix())
+	}
+
+	var from timeutil.TimeStamp
+	switch {
+	case opts.From != "":
+		parsed, err := time.Parse(dateLayout, opts.From)
+		if err != nil {
+			return seriesRange{}, fmt.Errorf("from must be a date in format YYYY-MM-DD")
+		}
+		from = timeutil.TimeStamp(parsed.Unix())
+	case interval == code_hub_usage_stat.IntervalWeek:
+		from = code_hub_usage_stat.WeekStart(to) - timeutil.TimeStamp((defaultSeriesWeeks-1)*7*24*time.Hour/time.Second)
+	default:
+		from = to - timeutil.TimeStamp((defaultSeriesDays-1)*24*time.Hour/time.Second)
+	}
+
+	if from > to {
+		return seriesRange{}, fmt.Errorf("from must not be after to")
+	}
+	if days := int64(to-from)/int64(24*time.Hour/time.Second) + 1; days > maxSeriesDays {
+		return seriesRange{}, fmt.Errorf("interval must not be longer than %d days", maxSeriesDays)
+	}
+
+	return seriesRange{
+		interval: interval,
+		start:    code_hub_usage_stat.BucketStart(from, interval),
+		end:      code_hub_usage_stat.NextBucket(code_hub_usage_stat.BucketStart(to, interval), interval),
+	}, nil
+}
+
+// getMetricSeries получить значения метрики репозитория по периодам. Значения событийных метрик суммируются
+// по дням, уникальные пользователи за неделю и за весь интервал считаются без повторов
+func (s Server) getMetricSeries(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, r seriesRange) (*metrics.InternalMetricSeriesResponse, error) {
+	stats, err := s.usageStatsDB.GetDailyStats(ctx, repoID, metric, r.start, r.end)
+	if err != nil {
+		return nil, fmt.Errorf("get daily stats: %w", err)
+	}
+	dayValues := make(map[timeutil.TimeStamp]int64, len(stats))
+	for _, stat := range stats {
+		dayValues[stat.Day] = stat.Value
+	}
+
+	series := &metrics.InternalMetricSeriesResponse{
+		Metric:   string(metric),
+		Interval: string(r.interval),
+		From:     r.start.FormatInLocation(dateLayout, time.UTC),
+		To:       (code_hub_usage_stat.DayStart(r.end - 1)).FormatInLocation(dateLayout, time.UTC),
+		Buckets:  make([]metrics.InternalMetricBucket, 0),
+	}
+
+	for bucket := r.start; bucket < r.end; bucket = code_hub_usage_stat.NextBucket(bucket, r.interval) {
+		next := code_hub_usage_stat.NextBucket(bucket, r.interval)
+
+		var value int64
+		if metric.IsUnique() && r.interval != code_hub_usage_stat.IntervalDay {
+			if value, err = s.usageStatsDB.CountUniqueUsers(ctx, repoID, metric, bucket, next); err != nil {
+				return nil, fmt.Errorf("count unique users: %w", err)
+			}
+		} else {
+			for day := bucket; day < next; day = code_hub_usage_stat.NextBucket(day, code_hub_usage_stat.IntervalDay) {
+				value += dayValues[day]
+			}
+		}
+
+		series.Buckets = append(series.Buckets, metrics.InternalMetricBucket{
+			Start: bucket.FormatInLocation(dateLayout, time.UTC),
+			Value: value,
+		})
+		series.Total += value
+	}
+
+	if metric.IsUnique() {
+		if series.Total, err = s.usageStatsDB.CountUniqueUsers(ctx, repoID, metric, r.start, r.end); err != nil {
+			return nil, fmt.Errorf("count unique users: %w", err)
+		}
+	}
+	return series, nil
+}
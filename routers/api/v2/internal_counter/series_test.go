@@ -0,0 +1,142 @@
+//go:build !correct
+
+package internal_counter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/code_hub_usage_stat"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v2/models/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type usageStatsStub struct {
+	stats []*code_hub_usage_stat.CodeHubUsageStat
+	users map[timeutil.TimeStamp][]int64
+}
+
+func (u usageStatsStub) GetDailyStats(_ context.Context, _ int64, _ code_hub_usage_stat.Metric, from, to timeutil.TimeStamp) ([]*code_hub_usage_stat.CodeHubUsageStat, error) {
+	stats := make([]*code_hub_usage_stat.CodeHubUsageStat, 0)
+	for _, stat := range u.stats {
+		if stat.Day >= from && stat.Day < to {
+			stats = append(stats, stat)
+		}
+	}
+	return stats, nil
+}
+
+func (u usageStatsStub) CountUniqueUsers(_ context.Context, _ int64, _ code_hub_usage_stat.Metric, from, to timeutil.TimeStamp) (int64, error) {
+	unique := make(map[int64]struct{})
+	for day, users := range u.users {
+		if day >= from && day < to {
+			for _, user := range users {
+				unique[user] = struct{}{}
+			}
+		}
+	}
+	return int64(len(unique)), nil
+}
+
+func date(t *testing.T, value string) timeutil.TimeStamp {
+	parsed, err := time.Parse(dateLayout, value)
+	require.NoError(t, err)
+	return timeutil.TimeStamp(parsed.Unix())
+}
+
+func TestParseSeriesRange(t *testing.T) {
+	now := time.Date(2024, 5, 15, 13, 30, 0, 0, time.UTC)
+
+	t.Run("default days", func(t *testing.T) {
+		r, err := parseSeriesRange(&metrics.InternalMetricGetOptions{Interval: "day"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, date(t, "2024-04-16"), r.start)
+		assert.Equal(t, date(t, "2024-05-16"), r.end)
+	})
+
+	t.Run("weeks are aligned to monday", func(t *testing.T) {
+		r, err := parseSeriesRange(&metrics.InternalMetricGetOptions{From: "2024-05-01", To: "2024-05-15", Interval: "week"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, code_hub_usage_stat.IntervalWeek, r.interval)
+		assert.Equal(t, date(t, "2024-04-29"), r.start)
+		assert.Equal(t, date(t, "2024-05-20"), r.end)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, opts := range []*metrics.InternalMetricGetOptions{
+			{Interval: "month"},
+			{From: "15.05.2024"},
+			{From: "2024-05-16", To: "2024-05-15"},
+			{From: "2023-01-01", To: "2024-05-15"},
+		} {
+			_, err := parseSeriesRange(opts, now)
+			assert.Error(t, err, "%+v", opts)
+		}
+	})
+}
+
+func TestGetMetricSeries(t *testing.T) {
+	ctx := context.Background()
+	stub := usageStatsStub{
+		stats: []*code_hub_usage_stat.CodeHubUsageStat{
+			{Day: date(t, "2024-05-06"), Value: 2},
+			{Day: date(t, "2024-05-08"), Value: 1},
+			{Day: date(t, "2024-05-13"), Value: 3},
+		},
+		users: map[timeutil.TimeStamp][]int64{
+			date(t, "2024-05-06"): {1, 2},
+			date(t, "2024-05-08"): {1},
+			date(t, "2024-05-13"): {1, 3, 4},
+		},
+	}
+	s := Server{usageStatsDB: stub}
+
+	t.Run("daily", func(t *testing.T) {
+		series, err := s.getMetricSeries(ctx, 1, code_hub_usage_stat.ForksMetric, seriesRange{
+			interval: code_hub_usage_stat.IntervalDay,
+			start:    date(t, "2024-05-06"),
+			end:      date(t, "2024-05-09"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-05-06", series.From)
+		assert.Equal(t, "2024-05-08", series.To)
+		assert.Equal(t, int64(3), series.Total)
+		assert.Equal(t, []metrics.InternalMetricBucket{
+			{Start: "2024-05-06", Value: 2},
+			{Start: "2024-05-07", Value: 0},
+			{Start: "2024-05-08", Value: 1},
+		}, series.Buckets)
+	})
+
+	t.Run("weekly events are summed", func(t *testing.T) {
+		series, err := s.getMetricSeries(ctx, 1, code_hub_usage_stat.StarsMetric, seriesRange{
+			interval: code_hub_usage_stat.IntervalWeek,
+			start:    date(t, "2024-05-06"),
+			end:      date(t, "2024-05-20"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), series.Total)
+		assert.Equal(t, []metrics.InternalMetricBucket{
+			{Start: "2024-05-06", Value: 3},
+			{Start: "2024-05-13", Value: 3},
+		}, series.Buckets)
+	})
+
+	t.Run("weekly unique users are not repeated", func(t *testing.T) {
+		series, err := s.getMetricSeries(ctx, 1, code_hub_usage_stat.UniqueClonersMetric, seriesRange{
+			interval: code_hub_usage_stat.IntervalWeek,
+			start:    date(t, "2024-05-06"),
+			end:      date(t, "2024-05-20"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), series.Total)
+		assert.Equal(t, []metrics.InternalMetricBucket{
+			{Start: "2024-05-06", Value: 2},
+			{Start: "2024-05-13", Value: 3},
+		}, series.Buckets)
+	})
+}
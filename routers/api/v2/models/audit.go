@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// AuditEventResponse событие аудита в ответе API v2
+// swagger:response auditEventResponse
+type AuditEventResponse struct {
+	ID         string            `json:"id"`
+	Event      string            `json:"event"`
+	EventDate  time.Time         `json:"eventdate"`
+	Status     string            `json:"status"`
+	UserName   string            `json:"username"`
+	UserID     string            `json:"internal_id"`
+	UserIP     string            `json:"user_ip"`
+	Tenant     string            `json:"tenant,omitempty"`
+	Repository string            `json:"repository,omitempty"`
+	HostName   string            `json:"host_name"`
+	HostIP     string            `json:"host_ip"`
+	Params     map[string]string `json:"params,omitempty"`
+}
+
+// AuditEventListResponse страница событий аудита в ответе API v2
+// swagger:response auditEventListResponse
+type AuditEventListResponse struct {
+	Total  int64                `json:"total"`
+	Events []AuditEventResponse `json:"events"`
+}
+
+// ToAuditEventResponse конвертирует событие аудита в ответ API
+func ToAuditEventResponse(event *audit_model.Event) AuditEventResponse {
+	return AuditEventResponse{
+		ID:         event.EventID,
+		Event:      event.Type,
+		EventDate:  event.EventUnix.AsTime().UTC(),
+		Status:     event.Status,
+		UserName:   event.UserName,
+		UserID:     event.UserID,
+		UserIP:     event.UserIP,
+		Tenant:     event.Tenant,
+		Repository: event.Repository,
+		HostName:   event.HostName,
+		HostIP:     event.HostIP,
+		Params:     event.Params,
+	}
+}
+
+// ParseAuditSearchOpts парсит из запроса параметры поиска событий аудита, время передается в формате RFC3339
+func ParseAuditSearchOpts(ctx *context.APIContext) (*audit_model.SearchEventsOptions, error) {
+	opts := &audit_model.SearchEventsOptions{
+		ListOptions: db.ListOptions{
+			Page:     ctx.FormInt("page"),
+			PageSize: convert.ToCorrectPageSize(ctx.FormInt("limit")),
+		},
+		Type:       ctx.FormString("event"),
+		UserName:   ctx.FormString("username"),
+		UserIP:     ctx.FormString("user_ip"),
+		Tenant:     ctx.FormString("tenant"),
+		Repository: ctx.FormString("repository"),
+		Status:     ctx.FormString("status"),
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	for name, target := range map[string]*timeutil.TimeStamp{"from": &opts.From, "to": &opts.To} {
+		value := ctx.FormString(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be in RFC3339 format", name)
+		}
+		*target = timeutil.TimeStamp(t.Unix())
+	}
+	return opts, nil
+}
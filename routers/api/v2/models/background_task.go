@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	background_task_model "code.gitea.io/gitea/models/background_task"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/background_task"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// BackgroundTaskResponse фоновая задача в ответе API v2
+// swagger:response backgroundTaskResponse
+type BackgroundTaskResponse struct {
+	ID            int64      `json:"id"`
+	Queue         string     `json:"queue"`
+	Status        string     `json:"status"`
+	Priority      int        `json:"priority"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LeaseOwner    string     `json:"lease_owner,omitempty"`
+	LeaseUntil    *time.Time `json:"lease_until,omitempty"`
+	Payload       string     `json:"payload"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// BackgroundTaskListResponse страница фоновых задач в ответе API v2
+// swagger:response backgroundTaskListResponse
+type BackgroundTaskListResponse struct {
+	Total int64                    `json:"total"`
+	Tasks []BackgroundTaskResponse `json:"tasks"`
+}
+
+// ToBackgroundTaskResponse конвертирует фоновую задачу в ответ API
+func ToBackgroundTaskResponse(task *background_task.Task) BackgroundTaskResponse {
+	response := BackgroundTaskResponse{
+		ID:         task.ID,
+		Queue:      task.Queue,
+		Status:     string(task.Status),
+		Priority:   task.Priority,
+		Attempts:   task.Attempts,
+		LastError:  task.LastError,
+		LeaseOwner: task.LeaseOwner,
+		Payload:    task.Payload,
+		CreatedAt:  task.CreatedAt.AsTime().UTC(),
+		UpdatedAt:  task.UpdatedAt.AsTime().UTC(),
+	}
+	if task.Status == background_task_model.StatusPending && task.RunAt > 0 {
+		nextAttemptAt := task.RunAt.AsTime().UTC()
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	if task.Status == background_task_model.StatusRunning {
+		leaseUntil := task.LeaseUntil.AsTime().UTC()
+		response.LeaseUntil = &leaseUntil
+	}
+	return response
+}
+
+// ParseBackgroundTaskFindOpts парсит из запроса параметры поиска фоновых задач
+func ParseBackgroundTaskFindOpts(ctx *context.APIContext) (*background_task.FindOptions, error) {
+	queue, err := background_task.ParseQueue(ctx.FormString("queue"))
+	if err != nil {
+		return nil, err
+	}
+	status, err := background_task.ParseStatus(ctx.FormString("status"))
+	if err != nil {
+		return nil, err
+	}
+	opts := &background_task.FindOptions{
+		ListOptions: db.ListOptions{
+			Page:     ctx.FormInt("page"),
+			PageSize: convert.ToCorrectPageSize(ctx.FormInt("limit")),
+		},
+		Queue:  queue,
+		Status: status,
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	return opts, nil
+}
@@ -6,9 +6,39 @@ type InternalMetricGetOptions struct {
 	TenantKey  string `json:"tenant_key"`
 	ProjectKey string `json:"project_key"`
 	Metric     string `json:"metric"`
+	// From, To и Interval задают запрос значений метрики по периодам
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Interval string `json:"interval"`
+}
+
+// IsSeries проверяет, что запрошены значения метрики по периодам, а не текущее значение счетчика
+func (o *InternalMetricGetOptions) IsSeries() bool {
+	return o.From != "" || o.To != "" || o.Interval != ""
 }
 
 // swagger:response internalMetricGetResponse
 type InternalMetricGetResponse struct {
 	Value int `json:"value"`
 }
+
+// InternalMetricSeriesResponse значения метрики репозитория по периодам
+// swagger:response internalMetricSeriesResponse
+type InternalMetricSeriesResponse struct {
+	Metric   string `json:"metric"`
+	Interval string `json:"interval"`
+	// From первый день первого периода
+	From string `json:"from"`
+	// To последний день последнего периода
+	To string `json:"to"`
+	// Total значение метрики за весь интервал, для уникальных метрик пользователь учитывается один раз
+	Total   int64                  `json:"total"`
+	Buckets []InternalMetricBucket `json:"buckets"`
+}
+
+// InternalMetricBucket значение метрики за период
+type InternalMetricBucket struct {
+	// Start первый день периода
+	Start string `json:"start"`
+	Value int64  `json:"value"`
+}
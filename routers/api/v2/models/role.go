@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+)
+
+// Role model for API v2 response
+// swagger:response roleResponse
+type RoleResponse struct {
+	Name         string   `json:"name"`
+	DisplayName  string   `json:"display_name"`
+	Actions      []string `json:"actions"`
+	Parent       string   `json:"parent,omitempty"`
+	IsSystem     bool     `json:"is_system"`
+	IsAssignable bool     `json:"is_assignable"`
+}
+
+// Roles model for API v2 response
+// swagger:response roleListResponse
+type RoleListResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+
+// CreateRoleOptions options to create role
+type CreateRoleOptions struct {
+	Name         string   `json:"name" binding:"Required;MaxSize(100)"`
+	DisplayName  string   `json:"display_name" binding:"MaxSize(255)"`
+	Actions      []string `json:"actions" binding:"Required"`
+	IsAssignable *bool    `json:"is_assignable"`
+}
+
+func (o *CreateRoleOptions) Validate() error {
+	if len(o.Actions) == 0 {
+		return fmt.Errorf("actions are required")
+	}
+	return nil
+}
+
+// UpdateRoleOptions options to update role
+type UpdateRoleOptions struct {
+	DisplayName  *string  `json:"display_name" binding:"MaxSize(255)"`
+	Actions      []string `json:"actions"`
+	IsAssignable *bool    `json:"is_assignable"`
+}
+
+func (o *UpdateRoleOptions) Validate() error {
+	if o.DisplayName == nil && o.Actions == nil && o.IsAssignable == nil {
+		return fmt.Errorf("nothing to update")
+	}
+	if o.Actions != nil && len(o.Actions) == 0 {
+		return fmt.Errorf("actions must not be empty")
+	}
+	return nil
+}
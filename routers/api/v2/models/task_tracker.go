@@ -0,0 +1,65 @@
+package models
+
+// TaskTrackerBinding model for API v2 response, binding without repo_key belongs to tenant
+type TaskTrackerBinding struct {
+	RepoKey  string `json:"repo_key,omitempty"`
+	Provider string `json:"provider"`
+}
+
+// Task tracker bindings model for API v2 response
+// swagger:response taskTrackerBindingsResponse
+type TaskTrackerBindingsResponse struct {
+	TenantKey       string               `json:"tenant_key"`
+	DefaultProvider string               `json:"default_provider"`
+	Providers       []string             `json:"providers"`
+	Bindings        []TaskTrackerBinding `json:"bindings"`
+}
+
+// SetTaskTrackerBindingOptions options to choose task tracker of tenant or repository
+type SetTaskTrackerBindingOptions struct {
+	Provider string `json:"provider" binding:"Required;MaxSize(100)"`
+}
+
+// UnitCodePatterns model for API v2 response, patterns without repo_key belong to tenant
+type UnitCodePatterns struct {
+	RepoKey  string   `json:"repo_key,omitempty"`
+	Patterns []string `json:"patterns"`
+}
+
+// Unit code patterns model for API v2 response
+// swagger:response unitCodePatternsResponse
+type UnitCodePatternsResponse struct {
+	TenantKey string             `json:"tenant_key"`
+	Settings  []UnitCodePatterns `json:"settings"`
+}
+
+// SetUnitCodePatternsOptions options to set unit code patterns of tenant or repository
+type SetUnitCodePatternsOptions struct {
+	Patterns []string `json:"patterns" binding:"Required"`
+}
+
+// PreviewUnitCodesOptions options to preview unit codes of branch, pull request and commits
+type PreviewUnitCodesOptions struct {
+	BranchName       string   `json:"branch_name" binding:"MaxSize(255)"`
+	PullRequestTitle string   `json:"pull_request_title" binding:"MaxSize(255)"`
+	CommitMessages   []string `json:"commit_messages"`
+	// Patterns to check before saving, effective patterns of tenant or repository are used if empty
+	Patterns []string `json:"patterns"`
+}
+
+// CommitUnitCodes unit codes found in commit message
+type CommitUnitCodes struct {
+	Message string   `json:"message"`
+	Codes   []string `json:"codes"`
+}
+
+// Unit codes preview model for API v2 response
+// swagger:response unitCodesPreviewResponse
+type UnitCodesPreviewResponse struct {
+	Provider         string            `json:"provider"`
+	Patterns         []string          `json:"patterns"`
+	Branch           []string          `json:"branch"`
+	PullRequestTitle []string          `json:"pull_request_title"`
+	Commits          []CommitUnitCodes `json:"commits"`
+	Codes            []string          `json:"codes"`
+}
@@ -11,10 +11,11 @@ var tenantNameRegex = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
 // Tenant model for API v2 response
 // swagger:response tenantGetResponse
 type TenantGetResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	IsActive  bool   `json:"is_active"`
-	TenantKey string `json:"tenant_key"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsActive   bool   `json:"is_active"`
+	IsArchived bool   `json:"is_archived"`
+	TenantKey  string `json:"tenant_key"`
 }
 
 // Tenant model for API v2 response
@@ -40,3 +41,55 @@ func (o *CreateTenantOptions) Validate() error {
 	}
 	return nil
 }
+
+// UpdateTenantOptions options to update tenant
+type UpdateTenantOptions struct {
+	Name     *string `json:"name" binding:"MaxSize(50)"`
+	IsActive *bool   `json:"is_active"`
+}
+
+func (o *UpdateTenantOptions) Validate() error {
+	if o.Name == nil && o.IsActive == nil {
+		return fmt.Errorf("nothing to update")
+	}
+	if o.Name != nil && !tenantNameRegex.MatchString(*o.Name) {
+		return fmt.Errorf("tenant name is not valid")
+	}
+	return nil
+}
+
+// TenantQuota лимит и текущее потребление ресурса тенанта, лимит 0 означает отсутствие ограничения
+type TenantQuota struct {
+	Limit int64 `json:"limit"`
+	Usage int64 `json:"usage"`
+}
+
+// Tenant quotas model for API v2 response
+// swagger:response tenantQuotasResponse
+type TenantQuotasResponse struct {
+	TenantKey   string      `json:"tenant_key"`
+	RepoCount   TenantQuota `json:"repo_count"`
+	GitSize     TenantQuota `json:"git_size"`
+	LFSSize     TenantQuota `json:"lfs_size"`
+	PackageSize TenantQuota `json:"package_size"`
+}
+
+// UpdateTenantQuotasOptions options to update tenant quotas
+type UpdateTenantQuotasOptions struct {
+	MaxRepoCount   *int64 `json:"max_repo_count"`
+	MaxGitSize     *int64 `json:"max_git_size"`
+	MaxLFSSize     *int64 `json:"max_lfs_size"`
+	MaxPackageSize *int64 `json:"max_package_size"`
+}
+
+func (o *UpdateTenantQuotasOptions) Validate() error {
+	if o.MaxRepoCount == nil && o.MaxGitSize == nil && o.MaxLFSSize == nil && o.MaxPackageSize == nil {
+		return fmt.Errorf("nothing to update")
+	}
+	for _, limit := range []*int64{o.MaxRepoCount, o.MaxGitSize, o.MaxLFSSize, o.MaxPackageSize} {
+		if limit != nil && *limit < 0 {
+			return fmt.Errorf("quota must not be negative")
+		}
+	}
+	return nil
+}
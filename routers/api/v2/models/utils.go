@@ -22,6 +22,9 @@ func ParseInternalMetricGetOpts(ctx *context.APIContext) *metrics.InternalMetric
 		TenantKey:  ctx.FormString("tenant_key"),
 		ProjectKey: ctx.FormString("project_key"),
 		Metric:     ctx.FormString("metric"),
+		From:       ctx.FormString("from"),
+		To:         ctx.FormString("to"),
+		Interval:   ctx.FormString("interval"),
 	}
 }
 
@@ -113,7 +113,7 @@ func CreateProjectRequest(ctx *context.APIContext) {
 		ctx.Error(http.StatusBadRequest, "validation error", err)
 		return
 	}
-	response, err := project.CreateProject(*ctx, *form)
+	response, err := project.CreateProject(ctx, ctx.Doer, *form)
 	if err != nil {
 		if tenant.IsProjectKeyAlreadyUsed(err) ||
 			project.IsProjectNameAlreadyUsed(err) {
@@ -227,7 +227,7 @@ func GetProjectRequest(ctx *context.APIContext) {
 		return
 	}
 
-	response, err := project.GetProject(*ctx, form.TenantKey, form.ProjectKey)
+	response, err := project.GetProject(ctx, form.TenantKey, form.ProjectKey)
 	if err != nil {
 		if tenant.IsTenantOrganizationsNotExists(err) {
 			ctx.Error(http.StatusBadRequest, "Get Project", err)
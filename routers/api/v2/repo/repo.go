@@ -18,13 +18,13 @@ import (
 	"code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v2/models"
 	apirepo "code.gitea.io/gitea/routers/api/v2/models/repo"
 	"code.gitea.io/gitea/routers/private/repo_mark"
-	"code.gitea.io/gitea/services/project"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // Server структура для DI при работе с репозиторием
@@ -33,6 +33,7 @@ type Server struct {
 	repoKeyDB             repoKeyDB
 	repoMarksEditor       repo_mark.RepoMarksEditor
 	codeHubMark           repo_marks.RepoMark
+	tenantRepos           repo_service.TenantRepoService
 }
 
 // NewRepoServer получить Server
@@ -42,6 +43,7 @@ func NewRepoServer(checkPermFn role_model.CheckUserPermissionFnType, db repoKeyD
 		repoKeyDB:             db,
 		repoMarksEditor:       repoMark,
 		codeHubMark:           mark,
+		tenantRepos:           repo_service.NewTenantRepoService(checkPermFn, db, repoMark, mark),
 	}
 }
 
@@ -158,150 +160,46 @@ func (s Server) createTenantOrgRepo(ctx *context.APIContext) {
 		return
 	}
 
-	var (
-		tenantOrg  *tenant.ScTenantOrganizations
-		org        *organization.Organization
-		repoKey    *repo.ScRepoKey
-		repoTenant *tenant.ScTenant
-		err        error
-	)
-
-	auditParams := map[string]string{
-		"repository": opt.Name,
-		"owner":      ctx.Doer.Name,
-	}
-
-	// Check if tenant exists
-	if tenantOrg, err = tenant.GetTenantOrganizationsByKeys(ctx, opt.TenantKey, opt.ProjectKey); err != nil {
-		if tenant.IsTenantOrganizationsNotExists(err) {
-			log.Error("Error has occurred while getting tenant by tenant key: %s, project key %s. Error: %v", opt.TenantKey, opt.ProjectKey, err)
-			auditParams["error"] = "Error has occurred while creating repository - options aren't valid"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			ctx.Error(http.StatusNotFound, "", "Tenant not found by given tenant key and project key")
-			return
-		} else {
-			log.Error("Error has occurred while getting tenant by org key %s and project key %s: %v", opt.TenantKey, opt.ProjectKey, err)
-			auditParams["error"] = "Error has occurred while creating repository"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			ctx.Error(http.StatusInternalServerError, "", "Fail to get tenant by tenant key and project key")
-			return
-		}
-	}
-
-	// Check if repo key already used
-	if _, err = s.repoKeyDB.GetRepoByKey(ctx, opt.RepositoryKey); err == nil {
-		log.Error("Error has occurred while getting repository by key %s. Repo with key already exists.", opt.RepositoryKey)
-		auditParams["error"] = "Error has occurred while creating repository - repository name been taken"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusConflict, "", "Fail to create repo by key, key already exists")
-		return
-	} else if !repo.IsErrorRepoKeyDoesntExists(err) {
-		log.Error("Error has occurred while getting repository by key %s: %v", opt.RepositoryKey, err)
-		auditParams["error"] = "Error has occurred while creating repository"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to get repository by key")
-		return
-	}
-
-	if repoTenant, err = tenant.GetTenantByID(ctx, tenantOrg.TenantID); err != nil {
-		log.Error("Error has occurred while getting tenant by id %s: %v", tenantOrg.TenantID, err)
-		auditParams["error"] = "Error has occurred while creating repository"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to get tenant")
-		return
-	}
-	if !repoTenant.IsActive {
-		log.Debug("Tenant with id %s is not active", repoTenant.ID)
-		auditParams["error"] = "Error has occurred while creating repository"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusNotFound, "", "Tenant is not active")
-		return
-	}
-
-	if org, err = organization.GetOrgByID(ctx, tenantOrg.OrganizationID); err != nil {
-		if user.IsErrUserNotExist(err) {
-			log.Error("Error has occurred while getting project by id %d: %v", tenantOrg.OrganizationID, err)
-			auditParams["error"] = "Error has occurred while creating repository - options aren't valid"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			ctx.Error(http.StatusNotFound, "", "Project not found")
-			return
-		} else {
-			log.Error("Error has occurred while getting project by id %d: %v", tenantOrg.OrganizationID, err)
-			auditParams["error"] = "Error has occurred while creating repository"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			ctx.Error(http.StatusInternalServerError, "", "Fail to get Project")
-			return
-		}
-	}
-
 	ctxTrace := gocontext.WithValue(ctx, trace_model.Key, "v2")
 	ctxTrace = gocontext.WithValue(ctxTrace, trace_model.EndpointKey, ctx.Req.RequestURI)
 	ctxTrace = gocontext.WithValue(ctxTrace, trace_model.FrontedKey, false)
 
-	allow, err := s.checkUserPermissionFn(ctxTrace, ctx.Doer, tenantOrg.TenantID, &organization.Organization{ID: tenantOrg.OrganizationID}, role_model.CREATE)
-	if err != nil {
-		log.Error("Error has occurred while checking user permission to organization: %v", err)
-		auditParams["error"] = "Error has occurred while creating repository"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to check user permission to organization")
-		return
-	}
-
-	if !allow {
-		log.Debug("User does not have permission to organization %d", tenantOrg.OrganizationID)
-		auditParams["error"] = "Error has occurred while creating repository - options aren't valid"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusNotFound, "", "User does not have permission to organization")
-		return
-	}
-
-	createOptions := repository.CreateRepoOptions{
-		DefaultBranch: opt.DefaultBranch,
-		Description:   opt.Description,
+	createOptions := repo_service.CreateTenantOrgRepoOptions{
+		TenantKey:     opt.TenantKey,
+		ProjectKey:    opt.ProjectKey,
+		RepositoryKey: opt.RepositoryKey,
 		Name:          opt.Name,
+		Description:   opt.Description,
+		DefaultBranch: opt.DefaultBranch,
 		IsPrivate:     *opt.Private,
-		Readme:        "Default",
 	}
-
-	createRepository, err := repository.CreateRepository(ctx.Doer, org.AsUser(), createOptions)
+	createRepository, org, err := s.tenantRepos.CreateTenantOrgRepo(ctxTrace, ctx.Doer, createOptions, auditutils.NewRequiredAuditParamsFromApiContext(ctx))
 	if err != nil {
-		if repo.IsErrRepoAlreadyExist(err) {
-			log.Error("The repository with the same name already exists")
-			auditParams["error"] = "Error has occurred while creating repository - repository name been taken"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+		log.Error("Error has occurred while creating repository %s in project %s of tenant %s: %v", opt.Name, opt.ProjectKey, opt.TenantKey, err)
+		switch {
+		case tenant.IsTenantOrganizationsNotExists(err):
+			ctx.Error(http.StatusNotFound, "", "Tenant not found by given tenant key and project key")
+		case repo.IsErrorRepoKeyAlreadyUsed(err):
+			ctx.Error(http.StatusConflict, "", "Fail to create repo by key, key already exists")
+		case tenant.IsTenantNotActive(err):
+			ctx.Error(http.StatusNotFound, "", "Tenant is not active")
+		case user.IsErrUserNotExist(err):
+			ctx.Error(http.StatusNotFound, "", "Project not found")
+		case repo_service.IsErrNoProjectPermission(err):
+			ctx.Error(http.StatusNotFound, "", "User does not have permission to organization")
+		case repo.IsErrRepoAlreadyExist(err):
 			ctx.Error(http.StatusConflict, "", "The repository with the same name already exists.")
-		} else if repo.IsErrCreateUserRepo(err) {
-			log.Error("Creating a repository outside the project is prohibited")
-			auditParams["error"] = "Error has occurred while creating repository - creating a repository outside the project is prohibited"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+		case tenant.IsErrTenantQuotaExceeded(err):
+			ctx.Error(http.StatusForbidden, "", "Tenant repository quota exceeded")
+		case repo.IsErrCreateUserRepo(err):
 			ctx.Error(http.StatusBadRequest, "", "Creating a repository outside the project is prohibited")
-		} else {
-			log.Error("Fail to create repository %v", err)
-			auditParams["error"] = "Error has occurred while creating repository"
-			audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+		default:
 			ctx.Error(http.StatusInternalServerError, "", "Fail to create repository")
 		}
 		return
 	}
 
-	// Update repokey key
-	if repoKey, err = s.repoKeyDB.GetRepoByRepoID(ctx, strconv.FormatInt(createRepository.ID, 10)); err != nil {
-		log.Error("Error has occurred while getting repository by key %s: %v", opt.RepositoryKey, err)
-		auditParams["error"] = "Error has occurred while creating repository"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to get repository by key")
-		return
-	}
-	repoKey.RepoKey = opt.RepositoryKey
-	if err = s.repoKeyDB.UpdateRepoKey(ctx, repoKey); err != nil {
-		log.Error("Error has occurred while inserting repository key %d: %v", repoKey.ID, err)
-		auditParams["error"] = "Error has occurred while creating repository"
-		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to insert repository key")
-		return
-	}
-
-	repositoryResp := apirepo.RepositoryPostResponse{
+	ctx.JSON(http.StatusCreated, apirepo.RepositoryPostResponse{
 		ID:            strconv.FormatInt(createRepository.ID, 10),
 		TenantKey:     opt.TenantKey,
 		ProjectKey:    opt.ProjectKey,
@@ -310,10 +208,7 @@ func (s Server) createTenantOrgRepo(ctx *context.APIContext) {
 		Name:          createRepository.Name,
 		Private:       createRepository.IsPrivate,
 		URI:           fmt.Sprintf("/%s/%s", org.LowerName, createRepository.LowerName),
-	}
-
-	audit.CreateAndSendEvent(audit.RepositoryCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusSuccess, ctx.Req.RemoteAddr, auditParams)
-	ctx.JSON(http.StatusCreated, repositoryResp)
+	})
 }
 
 // GetOrgRepo returns repo according to tenant and project
@@ -395,6 +290,8 @@ func (s Server) CreateTenantOrgRepo(ctx *context.APIContext) {
 	//     "$ref": "#/responses/repositoryPostResponse"
 	//   "400":
 	//     description: Bad request
+	//   "403":
+	//     description: Tenant repository quota exceeded
 	//   "404":
 	//     description: Not found
 	//   "409":
@@ -461,136 +358,30 @@ func (s Server) setMark(ctx *context.APIContext) {
 		return
 	}
 
-	var (
-		tenantOrg  *tenant.ScTenantOrganizations
-		scRepoKey  *repo.ScRepoKey
-		repoTenant *tenant.ScTenant
-		repos      *repo.Repository
-		repoId     int64
-		err        error
-	)
-
-	auditParams = map[string]string{
-		"repository_key": opt.RepoKey,
-		"project_key":    opt.ProjectKey,
-		"tenant_key":     opt.TenantKey,
-	}
-
-	// Check if tenant exists
-	if tenantOrg, err = tenant.GetTenantOrganizationsByKeys(ctx, opt.TenantKey, opt.ProjectKey); err != nil {
-		if tenant.IsTenantOrganizationsNotExists(err) {
-			log.Error("Error has occurred while getting tenant by tenant key: %s, project key %s. Error: %v", opt.TenantKey, opt.ProjectKey, err)
-			auditParams["error"] = "Error has occurred while getting tenant by tenant_key and project_key"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+	if err := s.tenantRepos.SetCodeHubMark(ctx, ctx.Doer, opt.TenantKey, opt.ProjectKey, opt.RepoKey, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while setting mark for repository %s: %v", opt.RepoKey, err)
+		switch {
+		case tenant.IsTenantOrganizationsNotExists(err):
 			ctx.Error(http.StatusNotFound, "", repo.ErrorOrgDoestExist{TenantKey: opt.TenantKey, ProjectKey: opt.ProjectKey}.Error())
-			return
-		} else {
-			log.Error("Error has occurred while getting tenant by org key %s and project key %s: %v", opt.TenantKey, opt.ProjectKey, err)
-			auditParams["error"] = "Error has occurred while getting tenant by tenant_key and project_key"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			ctx.Error(http.StatusInternalServerError, "", "Err: fail to get tenant by tenant_key and project_key")
-			return
-		}
-	}
-
-	if repoTenant, err = tenant.GetTenantByID(ctx, tenantOrg.TenantID); err != nil {
-		log.Error("Error has occurred while getting tenant by id %s: %v", tenantOrg.TenantID, err)
-		auditParams["error"] = "Error has occurred while getting tenant by id"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Err: fail to get tenant")
-		return
-	}
-
-	if !repoTenant.IsActive {
-		log.Debug("Tenant with id %s is not active", repoTenant.ID)
-		auditParams["error"] = "Error has occurred while checking if tenant is active"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusNotFound, "", "Err: tenant is not active")
-		return
-	}
-
-	isPublic, err := project.GetProject(*ctx, opt.TenantKey, opt.ProjectKey)
-	if err != nil {
-		log.Error("Error has occurred while getting tenant by id %s: %v", tenantOrg.TenantID, err)
-		auditParams["error"] = "Error has occurred while getting tenant by id"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusBadRequest, "", "Err: fail to get project")
-		return
-	}
-	if isPublic.Visibility.IsPrivate() {
-		log.Debug("Project is not public: %s", opt.ProjectKey)
-		auditParams["error"] = "Error has occurred while checking if project is public"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusBadRequest, "", "Err: project is not public")
-		return
-	}
-	// Get repo by external key
-	if scRepoKey, err = s.repoKeyDB.GetRepoByKey(ctx, opt.RepoKey); err != nil {
-		if repo.IsErrorRepoKeyDoesntExists(err) {
-			auditParams["error"] = "Error has occurred while getting repository by repo_key"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			log.Error("Error has occurred while get repository by key %s: %v", opt.RepoKey, err)
+		case tenant.IsTenantNotActive(err):
+			ctx.Error(http.StatusNotFound, "", "Err: tenant is not active")
+		case user.IsErrUserNotExist(err):
+			ctx.Error(http.StatusBadRequest, "", "Err: fail to get project")
+		case repo_service.IsErrProjectNotPublic(err):
+			ctx.Error(http.StatusBadRequest, "", "Err: project is not public")
+		case repo.IsErrorRepoKeyDoesntExists(err):
 			ctx.Error(http.StatusNotFound, "", "Err: repo_key not exists")
-			return
-		} else {
-			auditParams["error"] = "Error has occuredd while getting repository by repo_key"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			log.Error("Error has occurred while getting repository by key %s: %v", opt.RepoKey, err)
-			ctx.Error(http.StatusInternalServerError, "", "Err: fail to get repository")
-			return
-		}
-	}
-
-	repoId, err = strconv.ParseInt(scRepoKey.RepoID, 10, 64)
-	if err != nil {
-		auditParams["error"] = "Error has occurred while parsing repository by id"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		log.Error("Error has occurred while parsing repository by id %s: %v", scRepoKey.RepoID, err)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to parse repository id")
-		return
-	}
-
-	// Check if repository exists
-	if repos, err = repo.GetRepositoryByID(ctx, repoId); err != nil {
-		if repo.IsErrRepoNotExist(err) {
-			auditParams["error"] = "Error has occurred while getting repository by id"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			log.Error("Error has occurred while getting repository id %d: %v", repoId, err)
+		case repo.IsErrRepoNotExist(err):
 			ctx.Error(http.StatusNotFound, "", "Err: repo not exists")
-			return
-		} else {
-			auditParams["error"] = "Error has occurred while getting repository by id"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			log.Error("Error has occurred while getting repository by id %d: %v", repoId, err)
-			ctx.Error(http.StatusInternalServerError, "", "Err: fail to get repository")
-			return
-		}
-	}
-
-	if repos.IsPrivate {
-		log.Debug("Repo is not public: %s", opt.ProjectKey)
-		auditParams["error"] = "Error has occurred while set marks - repository is private"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusBadRequest, "", "Err: repo is not public")
-		return
-	}
-
-	// Set repository labels
-	if err = s.repoMarksEditor.InsertRepoMark(ctx, scRepoKey.RepoKey, ctx.Doer.ID, s.codeHubMark); err != nil {
-		if errors.As(err, &repo_marks_db.ErrMarkAlreadyExists{}) {
-			auditParams["error"] = "Error has occurred while inserting repository mark"
-			audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-			log.Error("Error has occurred while setting repository mark: %v", err)
+		case repo_service.IsErrRepoNotPublic(err):
+			ctx.Error(http.StatusBadRequest, "", "Err: repo is not public")
+		case errors.As(err, &repo_marks_db.ErrMarkAlreadyExists{}):
 			ctx.Error(http.StatusConflict, "", "Err: repository mark already exists")
-			return
+		default:
+			ctx.Error(http.StatusInternalServerError, "", "Err: fail to set repository mark")
 		}
-		auditParams["error"] = "Error has occurred while inserting repository mark"
-		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		log.Error("Error has occurred while setting repository mark: %v", err)
-		ctx.Error(http.StatusInternalServerError, "", "Err: fail to set repository mark")
 		return
 	}
-	audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusSuccess, ctx.Req.RemoteAddr, auditParams)
 
 	ctx.Status(http.StatusCreated)
 }
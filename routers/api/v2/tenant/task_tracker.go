@@ -0,0 +1,258 @@
+package tenant
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/task_tracker_provider"
+	tenant_model "code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v2/models"
+	task_tracker_service "code.gitea.io/gitea/services/task_tracker"
+)
+
+// findTenantRepoID возвращает идентификатор репозитория тенанта по repo_key, 0 если repo_key не указан
+func (s Server) findTenantRepoID(ctx *context.APIContext, tenant *tenant_model.ScTenant) (int64, bool) {
+	repoKey := ctx.FormString("repo_key")
+	if repoKey == "" {
+		return 0, true
+	}
+
+	scRepoKey, err := repo_model.NewRepoKeyDB(db.GetEngine(ctx)).GetRepoByKey(ctx, repoKey)
+	if err != nil {
+		if repo_model.IsErrorRepoKeyDoesntExists(err) {
+			log.Debug("Repository not exists by repo key '%s'", repoKey)
+			ctx.Error(http.StatusNotFound, "", fmt.Sprintf("Err: repository not found, repo_key: %s", repoKey))
+		} else {
+			log.Error("Error has occurred while getting repository by key '%s'. Error: %v", repoKey, err)
+			ctx.Error(http.StatusInternalServerError, "", "Failed to get repository")
+		}
+		return 0, false
+	}
+	repoID, err := strconv.ParseInt(scRepoKey.RepoID, 10, 64)
+	if err != nil {
+		log.Error("Error has occurred while parsing repository ID %s. Error: %v", scRepoKey.RepoID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Invalid repository ID")
+		return 0, false
+	}
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		log.Error("Error has occurred while getting repository by ID %d. Error: %v", repoID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Failed to get repository")
+		return 0, false
+	}
+	tenantID, err := tenant_model.GetTenantByOrgIdOrDefault(ctx, repo.OwnerID)
+	if err != nil {
+		log.Error("Error has occurred while getting tenant of repository %d. Error: %v", repoID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Failed to get tenant of repository")
+		return 0, false
+	}
+	if tenantID != tenant.ID {
+		log.Debug("Repository '%s' does not belong to tenant '%s'", repoKey, tenant.ID)
+		ctx.Error(http.StatusNotFound, "", fmt.Sprintf("Err: repository not found, repo_key: %s", repoKey))
+		return 0, false
+	}
+	return repoID, true
+}
+
+// getTaskTrackerBindings returns task tracker bindings of tenant and its repositories
+func (s Server) getTaskTrackerBindings(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+
+	bindings, err := task_tracker_provider.FindBindings(ctx, tenant.ID)
+	if err != nil {
+		log.Error("Error has occurred while getting task tracker bindings of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to get task tracker bindings")
+		return
+	}
+
+	response := models.TaskTrackerBindingsResponse{
+		TenantKey:       tenant.OrgKey,
+		DefaultProvider: setting.TaskTracker.DefaultProvider,
+		Providers:       make([]string, 0, len(setting.TaskTracker.Providers)),
+		Bindings:        make([]models.TaskTrackerBinding, 0, len(bindings)),
+	}
+	for name := range setting.TaskTracker.Providers {
+		response.Providers = append(response.Providers, name)
+	}
+	sort.Strings(response.Providers)
+
+	repoKeyDB := repo_model.NewRepoKeyDB(db.GetEngine(ctx))
+	for _, binding := range bindings {
+		item := models.TaskTrackerBinding{Provider: binding.Provider}
+		if binding.RepoID > 0 {
+			repoKey, err := repoKeyDB.GetRepoByRepoID(ctx, strconv.FormatInt(binding.RepoID, 10))
+			if err != nil {
+				log.Warn("Repository key of task tracker binding %d is not found: %v", binding.ID, err)
+				continue
+			}
+			item.RepoKey = repoKey.RepoKey
+		}
+		response.Bindings = append(response.Bindings, item)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// setTaskTrackerBinding chooses task tracker of tenant or repository
+func (s Server) setTaskTrackerBinding(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*models.SetTaskTrackerBindingOptions)
+
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+	repoID, ok := s.findTenantRepoID(ctx, tenant)
+	if !ok {
+		return
+	}
+
+	binding := &task_tracker_provider.Binding{
+		TenantID: tenant.ID,
+		RepoID:   repoID,
+		Provider: form.Provider,
+	}
+	if err := task_tracker_service.SetBinding(ctx, binding, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		if task_tracker_service.IsErrUnknownProvider(err) {
+			log.Debug("Task tracker binding of tenant '%s' is not valid: %v", tenant.ID, err)
+			ctx.Error(http.StatusBadRequest, "", err.Error())
+			return
+		}
+		log.Error("Error has occurred while setting task tracker binding of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to set task tracker binding")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TaskTrackerBinding{
+		RepoKey:  ctx.FormString("repo_key"),
+		Provider: binding.Provider,
+	})
+}
+
+// deleteTaskTrackerBinding removes task tracker binding of tenant or repository
+func (s Server) deleteTaskTrackerBinding(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+	repoID, ok := s.findTenantRepoID(ctx, tenant)
+	if !ok {
+		return
+	}
+
+	deleted, err := task_tracker_service.DeleteBinding(ctx, tenant.ID, repoID, auditutils.NewRequiredAuditParamsFromApiContext(ctx))
+	if err != nil {
+		log.Error("Error has occurred while deleting task tracker binding of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to delete task tracker binding")
+		return
+	}
+	if !deleted {
+		ctx.Error(http.StatusNotFound, "", "Task tracker binding does not exist")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetTaskTrackerBindings returns task tracker bindings of tenant
+func (s Server) GetTaskTrackerBindings(ctx *context.APIContext) {
+	// swagger:operation GET /tenants/task_tracker tenant getTaskTrackerBindings
+	// ---
+	// summary: Returns configured task trackers and task trackers chosen by the tenant and its repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/taskTrackerBindingsResponse"
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.getTaskTrackerBindings(ctx)
+}
+
+// SetTaskTrackerBinding chooses task tracker of tenant or repository
+func (s Server) SetTaskTrackerBinding(ctx *context.APIContext) {
+	// swagger:operation PUT /tenants/task_tracker tenant setTaskTrackerBinding
+	// ---
+	// summary: Chooses task tracker of the tenant or, if repo_key is set, of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: repo_key
+	//   in: query
+	//   description: key of repository
+	//   type: string
+	// - name: body
+	//   in: body
+	//   description: Task tracker of the tenant or repository
+	//   required: true
+	//   schema:
+	//     type: object
+	//     required:
+	//       - provider
+	//     properties:
+	//       provider:
+	//         type: string
+	//         description: Name of task tracker from [sourcecontrol.tasktracker.provider.*] settings
+	// responses:
+	//   "200":
+	//     description: Task tracker binding
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.setTaskTrackerBinding(ctx)
+}
+
+// DeleteTaskTrackerBinding removes task tracker binding of tenant or repository
+func (s Server) DeleteTaskTrackerBinding(ctx *context.APIContext) {
+	// swagger:operation DELETE /tenants/task_tracker tenant deleteTaskTrackerBinding
+	// ---
+	// summary: Removes task tracker binding of the tenant or, if repo_key is set, of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: repo_key
+	//   in: query
+	//   description: key of repository
+	//   type: string
+	// responses:
+	//   "204":
+	//     description: No content
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.deleteTaskTrackerBinding(ctx)
+}
@@ -2,17 +2,15 @@ package tenant
 
 import (
 	"net/http"
-	"strconv"
-
-	"code.gitea.io/gitea/modules/sbt/audit"
-	"github.com/google/uuid"
 
 	tenant_model "code.gitea.io/gitea/models/tenant"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v2/models"
+	tenant_service "code.gitea.io/gitea/services/tenant"
 )
 
 type Server struct{}
@@ -23,34 +21,17 @@ func NewTenantServer() Server {
 
 // getTenantByKey returns tenant by id
 func (s Server) getTenantByKey(ctx *context.APIContext) {
-	tenantKey := ctx.FormString("tenant_key")
-
-	var tenant *tenant_model.ScTenant
-	var has bool
-	var err error
-
-	// Check if tenant exists
-	tenant, has, err = tenant_model.GetTenantByOrgKey(ctx, tenantKey)
-	if err != nil {
-		log.Error("Error has occurred while getting tenant by tenant key '%s'. Error: %v", tenantKey, err)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to get tenant by tenant key")
-		return
-	}
-
-	if !has {
-		log.Debug("Tenant not exists by tenant key '%s'. Error: %v", tenantKey, err)
-		ctx.JSON(http.StatusNotFound, context.APIError{
-			Message: "Tenant does not exist",
-			URL:     setting.API.SwaggerURL,
-		})
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, models.TenantGetResponse{
-		ID:        tenant.ID,
-		Name:      tenant.Name,
-		IsActive:  tenant.IsActive,
-		TenantKey: tenantKey,
+		ID:         tenant.ID,
+		Name:       tenant.Name,
+		IsActive:   tenant.IsActive,
+		IsArchived: tenant.IsArchived,
+		TenantKey:  tenant.OrgKey,
 	})
 }
 
@@ -69,55 +50,198 @@ func (s Server) createTenant(ctx *context.APIContext) {
 		return
 	}
 
-	auditParams := map[string]string{
-		"tenant_key": form.Name,
+	tenant, err := tenant_service.CreateTenant(ctx, form.Name, form.TenantKey, auditutils.NewRequiredAuditParamsFromApiContext(ctx))
+	if err != nil {
+		if tenant_model.IsErrTenantAlreadyExists(err) {
+			log.Debug("Tenant with name '%s' or tenant key '%s' exists", form.Name, form.TenantKey)
+			ctx.JSON(http.StatusConflict, context.APIError{
+				Message: "Name or organization key already used",
+				URL:     setting.API.SwaggerURL,
+			})
+			return
+		}
+		log.Error("Error has occurred while creating tenant with name '%s' and tenant key '%s'. Error: %v", form.Name, form.TenantKey, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to create tenant")
+		return
 	}
 
-	// Check if tenant with same name or orgKey already exists
-	tenants, err := tenant_model.GetTenantsByNameOrOrgKey(ctx, form.Name, form.TenantKey)
+	ctx.JSON(http.StatusCreated, models.TenantPostResponse{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		TenantKey: tenant.OrgKey,
+	})
+}
+
+// findTenantByKey returns tenant by tenant key from query or writes error response
+func (s Server) findTenantByKey(ctx *context.APIContext) (*tenant_model.ScTenant, bool) {
+	tenantKey := ctx.FormString("tenant_key")
+
+	tenant, has, err := tenant_model.GetTenantByOrgKey(ctx, tenantKey)
 	if err != nil {
-		log.Error("Error has occurred while getting tenants by name '%s' or tenant key '%s'. Error: %v", form.Name, form.TenantKey, err)
-		auditParams["error"] = "Error has occurred while getting tenants"
-		audit.CreateAndSendEvent(audit.TenantCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to get tenants by name or tenant key")
-		return
+		log.Error("Error has occurred while getting tenant by tenant key '%s'. Error: %v", tenantKey, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to get tenant by tenant key")
+		return nil, false
 	}
-	if len(tenants) > 0 {
-		log.Debug("Tenant with name '%s' or tenant key '%s' exists", form.Name, form.TenantKey)
-		auditParams["error"] = "Error has occurred the tenant already exists"
-		audit.CreateAndSendEvent(audit.TenantCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+	if !has {
+		log.Debug("Tenant not exists by tenant key '%s'", tenantKey)
+		ctx.JSON(http.StatusNotFound, context.APIError{
+			Message: "Tenant does not exist",
+			URL:     setting.API.SwaggerURL,
+		})
+		return nil, false
+	}
+	return tenant, true
+}
+
+// writeLifecycleError writes response for errors of tenant lifecycle service
+func writeLifecycleError(ctx *context.APIContext, err error, message string) {
+	switch {
+	case tenant_model.IsErrTenantIsDefault(err):
+		ctx.Error(http.StatusBadRequest, "", "Operation is not allowed for default tenant")
+	case tenant_model.IsErrTenantIsDeleting(err):
+		ctx.JSON(http.StatusConflict, context.APIError{
+			Message: "Tenant is being deleted",
+			URL:     setting.API.SwaggerURL,
+		})
+	case tenant_model.IsErrTenantNameAlreadyUsed(err):
 		ctx.JSON(http.StatusConflict, context.APIError{
-			Message: "Name or organization key already used",
+			Message: "Name already used",
 			URL:     setting.API.SwaggerURL,
 		})
+	default:
+		ctx.Error(http.StatusInternalServerError, "", message)
+	}
+}
+
+// updateTenant changes name or state of tenant
+func (s Server) updateTenant(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*models.UpdateTenantOptions)
+	if err := form.Validate(); err != nil {
+		log.Debug("Input params for updating tenant are not valid: %v", err)
+		ctx.Error(http.StatusBadRequest, "", "Incorrect params")
+		return
+	}
+
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
 		return
 	}
 
-	tenant := &tenant_model.ScTenant{
-		ID:       uuid.NewString(),
-		Default:  false,
-		IsActive: true,
+	opts := tenant_service.UpdateTenantOptions{
 		Name:     form.Name,
-		OrgKey:   form.TenantKey,
+		IsActive: form.IsActive,
 	}
+	if err := tenant_service.EditTenant(ctx, tenant, opts, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while updating tenant '%s'. Error: %v", tenant.ID, err)
+		writeLifecycleError(ctx, err, "Fail to update tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TenantGetResponse{
+		ID:         tenant.ID,
+		Name:       tenant.Name,
+		IsActive:   tenant.IsActive,
+		IsArchived: tenant.IsArchived,
+		TenantKey:  tenant.OrgKey,
+	})
+}
+
+// archiveTenant deactivates tenant and archives all its repositories
+func (s Server) archiveTenant(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+
+	if err := tenant_service.ArchiveTenant(ctx, tenant, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while archiving tenant '%s'. Error: %v", tenant.ID, err)
+		writeLifecycleError(ctx, err, "Fail to archive tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TenantGetResponse{
+		ID:         tenant.ID,
+		Name:       tenant.Name,
+		IsActive:   tenant.IsActive,
+		IsArchived: tenant.IsArchived,
+		TenantKey:  tenant.OrgKey,
+	})
+}
 
-	tenantDB, err := tenant_model.InsertTenant(ctx, tenant)
+// deleteTenant removes tenant with all projects, repositories and privileges
+func (s Server) deleteTenant(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+
+	if err := tenant_service.DeleteTenantWithResources(ctx, ctx.Doer, tenant, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while deleting tenant '%s'. Error: %v", tenant.ID, err)
+		writeLifecycleError(ctx, err, "Fail to delete tenant")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// writeTenantQuotas writes limits and current usage of tenant resources
+func writeTenantQuotas(ctx *context.APIContext, tenant *tenant_model.ScTenant) {
+	usage, err := tenant_model.GetTenantUsage(ctx, tenant.ID)
 	if err != nil {
-		log.Error("Error has occurred while creating tenant with name '%s' and tenant key '%s'. Error: %v", form.Name, form.TenantKey, err)
-		auditParams["error"] = "Error has occurred while inserting tenant"
-		audit.CreateAndSendEvent(audit.TenantCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
-		ctx.Error(http.StatusInternalServerError, "", "Fail to create tenant")
+		log.Error("Error has occurred while getting usage of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to get tenant usage")
 		return
 	}
 
-	audit.CreateAndSendEvent(audit.TenantCreateEvent, ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10), audit.StatusSuccess, ctx.Req.RemoteAddr, auditParams)
-	ctx.JSON(http.StatusCreated, models.TenantPostResponse{
-		ID:        tenantDB.ID,
-		Name:      tenant.Name,
-		TenantKey: tenantDB.OrgKey,
+	quota := func(resource tenant_model.QuotaResource) models.TenantQuota {
+		return models.TenantQuota{Limit: tenant.Limit(resource), Usage: usage.Value(resource)}
+	}
+	ctx.JSON(http.StatusOK, models.TenantQuotasResponse{
+		TenantKey:   tenant.OrgKey,
+		RepoCount:   quota(tenant_model.QuotaRepoCount),
+		GitSize:     quota(tenant_model.QuotaGitSize),
+		LFSSize:     quota(tenant_model.QuotaLFSSize),
+		PackageSize: quota(tenant_model.QuotaPackageSize),
 	})
 }
 
+// getTenantQuotas returns quotas and usage of tenant
+func (s Server) getTenantQuotas(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+	writeTenantQuotas(ctx, tenant)
+}
+
+// updateTenantQuotas changes quotas of tenant
+func (s Server) updateTenantQuotas(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*models.UpdateTenantQuotasOptions)
+	if err := form.Validate(); err != nil {
+		log.Debug("Input params for updating tenant quotas are not valid: %v", err)
+		ctx.Error(http.StatusBadRequest, "", "Incorrect params")
+		return
+	}
+
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+
+	opts := tenant_service.UpdateTenantQuotasOptions{
+		MaxRepoCount:   form.MaxRepoCount,
+		MaxGitSize:     form.MaxGitSize,
+		MaxLFSSize:     form.MaxLFSSize,
+		MaxPackageSize: form.MaxPackageSize,
+	}
+	if err := tenant_service.SetTenantQuotas(ctx, tenant, opts, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		log.Error("Error has occurred while updating quotas of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to update tenant quotas")
+		return
+	}
+	writeTenantQuotas(ctx, tenant)
+}
+
 // GetTenantByKey returns tenant by id
 func (s Server) GetTenantByKey(ctx *context.APIContext) {
 	// swagger:operation GET /tenants tenant getTenantByKey
@@ -176,3 +300,169 @@ func (s Server) CreateTenant(ctx *context.APIContext) {
 
 	s.createTenant(ctx)
 }
+
+// UpdateTenant changes name or state of tenant
+func (s Server) UpdateTenant(ctx *context.APIContext) {
+	// swagger:operation PATCH /tenants tenant updateTenant
+	// ---
+	// summary: Changes name or activity of the tenant. Deactivated tenant blocks git, API and web access to its repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   description: Fields of the tenant to be changed
+	//   required: true
+	//   schema:
+	//     type: object
+	//     properties:
+	//       name:
+	//         type: string
+	//         description: New name of tenant
+	//       is_active:
+	//         type: boolean
+	//         description: Activity of tenant
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/tenantGetResponse"
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "409":
+	//     description: Conflict
+	//   "500":
+	//     description: Internal server error
+
+	s.updateTenant(ctx)
+}
+
+// ArchiveTenant deactivates tenant and archives all its repositories
+func (s Server) ArchiveTenant(ctx *context.APIContext) {
+	// swagger:operation POST /tenants/archive tenant archiveTenant
+	// ---
+	// summary: Deactivates the tenant and archives all its repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/tenantGetResponse"
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.archiveTenant(ctx)
+}
+
+// DeleteTenant removes tenant with all projects, repositories and privileges
+func (s Server) DeleteTenant(ctx *context.APIContext) {
+	// swagger:operation DELETE /tenants tenant deleteTenant
+	// ---
+	// summary: Removes the tenant with all its projects, repositories and privileges
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: No content
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.deleteTenant(ctx)
+}
+
+// GetTenantQuotas returns quotas and usage of tenant
+func (s Server) GetTenantQuotas(ctx *context.APIContext) {
+	// swagger:operation GET /tenants/quotas tenant getTenantQuotas
+	// ---
+	// summary: Returns quotas and current resource usage of the tenant
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/tenantQuotasResponse"
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.getTenantQuotas(ctx)
+}
+
+// UpdateTenantQuotas changes quotas of tenant
+func (s Server) UpdateTenantQuotas(ctx *context.APIContext) {
+	// swagger:operation PUT /tenants/quotas tenant updateTenantQuotas
+	// ---
+	// summary: Changes quotas of the tenant. Quota with value 0 does not limit the resource
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   description: Quotas of the tenant to be changed
+	//   required: true
+	//   schema:
+	//     type: object
+	//     properties:
+	//       max_repo_count:
+	//         type: integer
+	//         format: int64
+	//         description: Maximum count of repositories
+	//       max_git_size:
+	//         type: integer
+	//         format: int64
+	//         description: Maximum total size of git repositories in bytes
+	//       max_lfs_size:
+	//         type: integer
+	//         format: int64
+	//         description: Maximum total size of LFS objects in bytes
+	//       max_package_size:
+	//         type: integer
+	//         format: int64
+	//         description: Maximum total size of packages in bytes
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/tenantQuotasResponse"
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.updateTenantQuotas(ctx)
+}
@@ -144,3 +144,92 @@ func TestCreateTenant_TenantExists(t *testing.T) {
 	tenantServer.CreateTenant(ctx)
 	assert.Equal(t, http.StatusConflict, ctx.Resp.Status())
 }
+
+func TestUpdateTenant_Deactivate(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	tenantKey := "tenantKey"
+	tenant := &tenat_model.ScTenant{
+		ID:        "99246748-c934-4034-9fa1-b6ef9014e674",
+		OrgKey:    tenantKey,
+		Name:      "test",
+		IsActive:  true,
+		Default:   false,
+		CreatedAt: timeutil.TimeStampNow(),
+		UpdatedAt: timeutil.TimeStampNow(),
+	}
+	isActive := false
+	updateOptions := &models.UpdateTenantOptions{
+		IsActive: &isActive,
+	}
+
+	ctx := test.MockAPIContext(t, fmt.Sprintf("api/v2/tenants?tenant_key=%s", tenantKey))
+	ctx.SetFormString("tenant_key", tenantKey)
+	web.SetForm(ctx, updateOptions)
+
+	_, err := tenat_model.InsertTenant(ctx, tenant)
+	assert.NoError(t, err)
+
+	test.LoadUser(t, ctx, 1)
+	ctx.Doer = unittest.AssertExistsAndLoadBean(t, &user_model.User{
+		IsAdmin: true,
+		ID:      1,
+	})
+
+	tenantServer.UpdateTenant(ctx)
+	assert.Equal(t, http.StatusOK, ctx.Resp.Status())
+
+	tenantDB, err := tenat_model.GetTenantByID(ctx, tenant.ID)
+	assert.NoError(t, err)
+	assert.False(t, tenantDB.IsActive)
+}
+
+func TestUpdateTenant_DefaultTenant(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	tenantKey := "tenantKey"
+	tenant := &tenat_model.ScTenant{
+		ID:        "99246748-c934-4034-9fa1-b6ef9014e675",
+		OrgKey:    tenantKey,
+		Name:      "test",
+		IsActive:  true,
+		Default:   true,
+		CreatedAt: timeutil.TimeStampNow(),
+		UpdatedAt: timeutil.TimeStampNow(),
+	}
+	isActive := false
+	updateOptions := &models.UpdateTenantOptions{
+		IsActive: &isActive,
+	}
+
+	ctx := test.MockAPIContext(t, fmt.Sprintf("api/v2/tenants?tenant_key=%s", tenantKey))
+	ctx.SetFormString("tenant_key", tenantKey)
+	web.SetForm(ctx, updateOptions)
+
+	_, err := tenat_model.InsertTenant(ctx, tenant)
+	assert.NoError(t, err)
+
+	test.LoadUser(t, ctx, 1)
+	ctx.Doer = unittest.AssertExistsAndLoadBean(t, &user_model.User{
+		IsAdmin: true,
+		ID:      1,
+	})
+
+	tenantServer.UpdateTenant(ctx)
+	assert.Equal(t, http.StatusBadRequest, ctx.Resp.Status())
+}
+
+func TestDeleteTenant_TenantNotExist(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	tenantKey := "tenantKey"
+
+	ctx := test.MockAPIContext(t, fmt.Sprintf("api/v2/tenants?tenant_key=%s", tenantKey))
+	ctx.SetFormString("tenant_key", tenantKey)
+
+	test.LoadUser(t, ctx, 1)
+	ctx.Doer = unittest.AssertExistsAndLoadBean(t, &user_model.User{
+		IsAdmin: true,
+		ID:      1,
+	})
+
+	tenantServer.DeleteTenant(ctx)
+	assert.Equal(t, http.StatusNotFound, ctx.Resp.Status())
+}
@@ -0,0 +1,325 @@
+package tenant
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/gitnames"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit_code_pattern"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/gitnamesparser/pullrequest"
+	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v2/models"
+	"code.gitea.io/gitea/routers/private/pull_request_reader"
+	task_tracker_service "code.gitea.io/gitea/services/task_tracker"
+)
+
+// getUnitCodePatterns returns unit code patterns of tenant and its repositories
+func (s Server) getUnitCodePatterns(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+
+	settings, err := unit_code_pattern.FindSettings(ctx, tenant.ID)
+	if err != nil {
+		log.Error("Error has occurred while getting unit code patterns of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to get unit code patterns")
+		return
+	}
+
+	response := models.UnitCodePatternsResponse{
+		TenantKey: tenant.OrgKey,
+		Settings:  make([]models.UnitCodePatterns, 0, len(settings)),
+	}
+	repoKeyDB := repo_model.NewRepoKeyDB(db.GetEngine(ctx))
+	for _, patternSetting := range settings {
+		item := models.UnitCodePatterns{Patterns: patternSetting.Patterns}
+		if patternSetting.RepoID > 0 {
+			repoKey, err := repoKeyDB.GetRepoByRepoID(ctx, strconv.FormatInt(patternSetting.RepoID, 10))
+			if err != nil {
+				log.Warn("Repository key of unit code patterns %d is not found: %v", patternSetting.ID, err)
+				continue
+			}
+			item.RepoKey = repoKey.RepoKey
+		}
+		response.Settings = append(response.Settings, item)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// setUnitCodePatterns sets unit code patterns of tenant or repository
+func (s Server) setUnitCodePatterns(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*models.SetUnitCodePatternsOptions)
+
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+	repoID, ok := s.findTenantRepoID(ctx, tenant)
+	if !ok {
+		return
+	}
+
+	patternSetting := &unit_code_pattern.Setting{
+		TenantID: tenant.ID,
+		RepoID:   repoID,
+		Patterns: form.Patterns,
+	}
+	if err := task_tracker_service.SetUnitCodePatterns(ctx, patternSetting, auditutils.NewRequiredAuditParamsFromApiContext(ctx)); err != nil {
+		if task_tracker_service.IsErrTooManyUnitCodePatterns(err) || task_tracker_service.IsErrInvalidUnitCodePattern(err) {
+			log.Debug("Unit code patterns of tenant '%s' are not valid: %v", tenant.ID, err)
+			ctx.Error(http.StatusBadRequest, "", err.Error())
+			return
+		}
+		log.Error("Error has occurred while setting unit code patterns of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to set unit code patterns")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UnitCodePatterns{
+		RepoKey:  ctx.FormString("repo_key"),
+		Patterns: patternSetting.Patterns,
+	})
+}
+
+// deleteUnitCodePatterns removes unit code patterns of tenant or repository
+func (s Server) deleteUnitCodePatterns(ctx *context.APIContext) {
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+	repoID, ok := s.findTenantRepoID(ctx, tenant)
+	if !ok {
+		return
+	}
+
+	deleted, err := task_tracker_service.DeleteUnitCodePatterns(ctx, tenant.ID, repoID, auditutils.NewRequiredAuditParamsFromApiContext(ctx))
+	if err != nil {
+		log.Error("Error has occurred while deleting unit code patterns of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to delete unit code patterns")
+		return
+	}
+	if !deleted {
+		ctx.Error(http.StatusNotFound, "", "Unit code patterns do not exist")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// previewUnitCodes returns unit codes which would be linked to pull request with given branch name, title and commits
+func (s Server) previewUnitCodes(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*models.PreviewUnitCodesOptions)
+
+	tenant, ok := s.findTenantByKey(ctx)
+	if !ok {
+		return
+	}
+	repoID, ok := s.findTenantRepoID(ctx, tenant)
+	if !ok {
+		return
+	}
+
+	provider, patterns, err := task_tracker_service.ResolveProvider(ctx, tenant.ID, repoID)
+	if err != nil {
+		log.Error("Error has occurred while resolving unit code patterns of tenant '%s'. Error: %v", tenant.ID, err)
+		ctx.Error(http.StatusInternalServerError, "", "Fail to resolve unit code patterns")
+		return
+	}
+	if len(form.Patterns) > 0 {
+		if patterns, err = task_tracker_service.ValidateUnitCodePatterns(form.Patterns); err != nil {
+			log.Debug("Unit code patterns to preview are not valid: %v", err)
+			ctx.Error(http.StatusBadRequest, "", err.Error())
+			return
+		}
+	}
+
+	links, err := pullrequest.NewParser().ParseWithPatterns(pull_request_reader.PullRequestHeader{
+		PullRequestName: form.PullRequestTitle,
+		BranchName:      form.BranchName,
+		CommitNames:     form.CommitMessages,
+	}, patterns)
+	if err != nil {
+		log.Debug("Unit codes are not found: %v", err)
+	}
+
+	response := models.UnitCodesPreviewResponse{
+		Provider:         provider,
+		Patterns:         make([]string, 0, len(patterns)),
+		Branch:           unitCodesToStrings(links.BranchLinks.LinkedUnits),
+		PullRequestTitle: unitCodesToStrings(links.Base.LinkedUnits),
+		Commits:          make([]models.CommitUnitCodes, 0, len(links.CommitsLinks)),
+		Codes:            []string{},
+	}
+	for _, pattern := range patterns {
+		response.Patterns = append(response.Patterns, pattern.String())
+	}
+	for _, commit := range links.CommitsLinks {
+		response.Commits = append(response.Commits, models.CommitUnitCodes{
+			Message: commit.Description,
+			Codes:   unitCodesToStrings(commit.LinkedUnits),
+		})
+	}
+	if codes, err := links.GetUniqCodes(); err == nil {
+		response.Codes = unitCodesToStrings(codes)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func unitCodesToStrings(codes gitnames.LinkedUnits) []string {
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		result = append(result, code.Code)
+	}
+	return result
+}
+
+// GetUnitCodePatterns returns unit code patterns of tenant
+func (s Server) GetUnitCodePatterns(ctx *context.APIContext) {
+	// swagger:operation GET /tenants/task_tracker/unit_code_patterns tenant getUnitCodePatterns
+	// ---
+	// summary: Returns unit code patterns of the tenant and its repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/unitCodePatternsResponse"
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.getUnitCodePatterns(ctx)
+}
+
+// SetUnitCodePatterns sets unit code patterns of tenant or repository
+func (s Server) SetUnitCodePatterns(ctx *context.APIContext) {
+	// swagger:operation PUT /tenants/task_tracker/unit_code_patterns tenant setUnitCodePatterns
+	// ---
+	// summary: Sets unit code patterns of the tenant or, if repo_key is set, of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: repo_key
+	//   in: query
+	//   description: key of repository
+	//   type: string
+	// - name: body
+	//   in: body
+	//   description: Unit code patterns of the tenant or repository
+	//   required: true
+	//   schema:
+	//     type: object
+	//     required:
+	//       - patterns
+	//     properties:
+	//       patterns:
+	//         type: array
+	//         description: Regular expressions of unit codes, group named code selects unit code from the match
+	//         items:
+	//           type: string
+	// responses:
+	//   "200":
+	//     description: Unit code patterns
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.setUnitCodePatterns(ctx)
+}
+
+// DeleteUnitCodePatterns removes unit code patterns of tenant or repository
+func (s Server) DeleteUnitCodePatterns(ctx *context.APIContext) {
+	// swagger:operation DELETE /tenants/task_tracker/unit_code_patterns tenant deleteUnitCodePatterns
+	// ---
+	// summary: Removes unit code patterns of the tenant or, if repo_key is set, of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: repo_key
+	//   in: query
+	//   description: key of repository
+	//   type: string
+	// responses:
+	//   "204":
+	//     description: No content
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.deleteUnitCodePatterns(ctx)
+}
+
+// PreviewUnitCodes returns unit codes which would be linked to pull request
+func (s Server) PreviewUnitCodes(ctx *context.APIContext) {
+	// swagger:operation POST /tenants/task_tracker/unit_code_patterns/preview tenant previewUnitCodes
+	// ---
+	// summary: Returns unit codes which would be found in branch name, pull request title and commit messages
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant_key
+	//   in: query
+	//   description: key of tenant
+	//   type: string
+	//   required: true
+	// - name: repo_key
+	//   in: query
+	//   description: key of repository
+	//   type: string
+	// - name: body
+	//   in: body
+	//   description: Branch name, pull request title and commit messages to preview
+	//   required: true
+	//   schema:
+	//     type: object
+	//     properties:
+	//       branch_name:
+	//         type: string
+	//       pull_request_title:
+	//         type: string
+	//       commit_messages:
+	//         type: array
+	//         items:
+	//           type: string
+	//       patterns:
+	//         type: array
+	//         description: Patterns to check before saving, effective patterns of the tenant or repository are used if empty
+	//         items:
+	//           type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/unitCodesPreviewResponse"
+	//   "400":
+	//     description: Bad request
+	//   "404":
+	//     description: Not found
+	//   "500":
+	//     description: Internal server error
+
+	s.previewUnitCodes(ctx)
+}
@@ -98,6 +98,8 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	protected_branch "code.gitea.io/gitea/routers/api/v3/branch_protection"
 	"code.gitea.io/gitea/routers/api/v3/models"
+	"code.gitea.io/gitea/routers/api/v3/pulls"
+	"code.gitea.io/gitea/routers/api/v3/quality_gates"
 	"code.gitea.io/gitea/routers/api/v3/review_settings"
 	"code.gitea.io/gitea/routers/api/v3/sonar"
 	"code.gitea.io/gitea/services/auth"
@@ -127,6 +129,8 @@ func Routes(ctx gocontext.Context) *web.Route {
 	defaultReviewersDB := default_reviewers_db.New(engine)
 	reviewSettingsDB := review_settings_db.New(engine)
 	reviewSettingsServer := review_settings.NewServer(defaultReviewersDB, reviewSettingsDB)
+	pullsServer := pulls.NewServer()
+	qualityGatesServer := quality_gates.NewServer()
 
 	// -----------DI-----------
 
@@ -201,6 +205,13 @@ func Routes(ctx gocontext.Context) *web.Route {
 			m.Put("", bind(models.CreateOrUpdateSonarProjectRequest{}), api.UpdateSonarSettings)
 			m.Get("", api.SonarSettings)
 			m.Delete("", api.DeleteSonarSettings)
+			m.Get("/metrics/trend", bind(models.SonarMetricsTrendRequest{}), api.SonarMetricsTrend)
+		})
+		m.Group("/quality_gates", func() {
+			m.Get("", qualityGatesServer.GetQualityGates)
+			m.Get("/{provider}", qualityGatesServer.GetQualityGate)
+			m.Put("/{provider}", bind(models.QualityGateSettingsRequest{}), qualityGatesServer.UpdateQualityGate)
+			m.Delete("/{provider}", qualityGatesServer.DeleteQualityGate)
 		})
 	}, repoAssignment(), context.RequireRepoPermissionApi(role_model.EDIT), tenantAssigment())
 
@@ -213,6 +224,11 @@ func Routes(ctx gocontext.Context) *web.Route {
 		m.Delete("/{branch_name}", reviewSettingsServer.DeleteReviewSettings, context.RequireRepoPermissionApi(role_model.EDIT))
 	}, repoAssignment(), tenantAssigment())
 
+	// pull requests
+	m.Group("/repos/{tenant}/{project}/{repo}/pulls", func() {
+		m.Get("/{index}/merge_check", pullsServer.GetMergeCheck, context.RequireRepoPermissionApi(role_model.READ))
+	}, repoAssignment(), tenantAssigment())
+
 	return m
 }
 
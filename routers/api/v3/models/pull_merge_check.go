@@ -0,0 +1,51 @@
+package models
+
+// PullMergeCheck результат проверки возможности слияния пулл реквеста
+// swagger:model
+type PullMergeCheck struct {
+	// Можно ли выполнить слияние
+	// required: true
+	Mergeable bool `json:"mergeable"`
+
+	// Причина запрета слияния
+	Reason string `json:"reason,omitempty"`
+
+	// Статусы связанных юнитов трекера задач, заполняются, если слияние требует разрешенного статуса юнита
+	Units []PullMergeCheckUnit `json:"units,omitempty"`
+
+	// Результаты quality gate, заполняются, если настройки ревью требуют успешных quality gate
+	QualityGates []PullMergeCheckQualityGate `json:"quality_gates,omitempty"`
+}
+
+// PullMergeCheckUnit статус юнита трекера задач, связанного с пулл реквестом
+// swagger:model
+type PullMergeCheckUnit struct {
+	// Код юнита
+	// required: true
+	Code string `json:"code"`
+
+	// Статус юнита в трекере задач, пустой если юнит не найден
+	Status string `json:"status"`
+
+	// Входит ли статус в разрешенные статусы настроек ревью
+	// required: true
+	Allowed bool `json:"allowed"`
+}
+
+// PullMergeCheckQualityGate результат quality gate провайдера для пулл реквеста
+// swagger:model
+type PullMergeCheckQualityGate struct {
+	// Провайдер quality gate
+	// required: true
+	Provider string `json:"provider"`
+
+	// Статус последнего отчета провайдера (OK или ERROR), пустой если отчета нет
+	Status string `json:"status"`
+
+	// Коммит, по которому построен отчет
+	CommitSHA string `json:"commit_sha,omitempty"`
+
+	// Пройден ли quality gate
+	// required: true
+	Passed bool `json:"passed"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models/quality_gate"
+)
+
+// QualityGateCondition условие quality gate на значение метрики отчета
+// swagger:model
+type QualityGateCondition struct {
+	// Ключ метрики
+	// required: true
+	// example: vulnerabilities_critical
+	Metric string `json:"metric"`
+
+	// Оператор: GT - условие не выполнено, если значение больше порога, LT - если меньше
+	// required: true
+	// enum: GT,LT
+	Operator string `json:"operator"`
+
+	// Порог
+	// required: true
+	Threshold float64 `json:"threshold"`
+}
+
+// QualityGateSettingsRequest запрос на создание или обновление настроек провайдера quality gate
+// swagger:model
+type QualityGateSettingsRequest struct {
+	// Условия quality gate. Пустой список означает условия провайдера по умолчанию
+	Conditions []QualityGateCondition `json:"conditions"`
+
+	// Создать новый токен приема отчетов вместо текущего
+	RegenerateToken bool `json:"regenerate_token"`
+}
+
+// Validate проверяет условия запроса
+func (r QualityGateSettingsRequest) Validate() error {
+	for _, condition := range r.Conditions {
+		if condition.Metric == "" {
+			return fmt.Errorf("condition metric is required")
+		}
+		if condition.Operator != string(quality_gate.OperatorGreaterThan) && condition.Operator != string(quality_gate.OperatorLessThan) {
+			return fmt.Errorf("unknown condition operator: %s", condition.Operator)
+		}
+	}
+	return nil
+}
+
+// QualityGateSettings настройки провайдера quality gate репозитория
+// swagger:model
+type QualityGateSettings struct {
+	// Провайдер quality gate
+	// required: true
+	Provider string `json:"provider"`
+
+	// Условия, по которым проверяются отчеты провайдера
+	// required: true
+	Conditions []QualityGateCondition `json:"conditions"`
+
+	// Используются ли условия провайдера по умолчанию
+	// required: true
+	DefaultConditions bool `json:"default_conditions"`
+
+	// Токен приема отчетов, возвращается только при создании настроек или генерации нового токена
+	Token string `json:"token,omitempty"`
+
+	// Последние восемь символов токена приема отчетов
+	// required: true
+	TokenLastEight string `json:"token_last_eight"`
+
+	// Путь webhook для отправки отчетов, токен передается в заголовке X-Quality-Gate-Token
+	// required: true
+	WebhookPath string `json:"webhook_path"`
+}
+
+// ConvertQualityGateConditionsToDBModel конвертирует условия quality gate из API в модель БД
+func ConvertQualityGateConditionsToDBModel(conditions []QualityGateCondition) []quality_gate.Condition {
+	result := make([]quality_gate.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		result = append(result, quality_gate.Condition{
+			Metric:    condition.Metric,
+			Operator:  quality_gate.Operator(condition.Operator),
+			Threshold: condition.Threshold,
+		})
+	}
+	return result
+}
+
+// ConvertQualityGateSettingsToAPIModel конвертирует настройки провайдера quality gate в модель API
+func ConvertQualityGateSettingsToAPIModel(settings *quality_gate.Settings, conditions []quality_gate.Condition) QualityGateSettings {
+	result := QualityGateSettings{
+		Provider:          string(settings.Provider),
+		Conditions:        make([]QualityGateCondition, 0, len(conditions)),
+		DefaultConditions: len(settings.Conditions) == 0,
+		Token:             settings.Token,
+		TokenLastEight:    settings.TokenLastEight,
+		WebhookPath:       "/webhooks/quality_gates/" + string(settings.Provider),
+	}
+	for _, condition := range conditions {
+		result.Conditions = append(result.Conditions, QualityGateCondition{
+			Metric:    condition.Metric,
+			Operator:  string(condition.Operator),
+			Threshold: condition.Threshold,
+		})
+	}
+	return result
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"code.gitea.io/gitea/models/default_reviewers"
+	"code.gitea.io/gitea/models/quality_gate"
 	"code.gitea.io/gitea/models/review_settings"
 	"code.gitea.io/gitea/models/user"
 )
@@ -107,6 +108,15 @@ type MergeRestrictions struct {
 
 	// Требовать прохождения SonarQube Quality Gate
 	RequireSonarqubeQualityGate bool `json:"require_sonarqube_quality_gate"`
+
+	// Блокировать слияние, пока пулл реквест не связан с юнитом трекера задач в разрешенном статусе
+	RequireUnitStatus bool `json:"require_unit_status"`
+
+	// Разрешенные статусы юнитов трекера задач, регистр не учитывается
+	AllowedUnitStatuses []string `json:"allowed_unit_statuses"`
+
+	// Провайдеры quality gate, результат которых должен быть успешным для слияния: sonarqube, semgrep, trivy, dependency_scanner
+	RequiredQualityGates []string `json:"required_quality_gates"`
 }
 
 // MergeSettings определяет, кто может выполнять слияние
@@ -130,6 +140,14 @@ type StatusChecks struct {
 }
 
 func (r ReviewSettingsRequest) Validate() error {
+	if r.MergeRestrictions.RequireUnitStatus && len(r.MergeRestrictions.AllowedUnitStatuses) == 0 {
+		return fmt.Errorf("allowed unit statuses are required")
+	}
+	for _, provider := range r.MergeRestrictions.RequiredQualityGates {
+		if !quality_gate.Provider(provider).IsValid() {
+			return fmt.Errorf("unknown quality gate provider: %s", provider)
+		}
+	}
 	for _, dr := range r.ApprovalSettings.DefaultReviewers {
 		if dr.RequiredApprovalsCount < 0 {
 			return fmt.Errorf("negative required approvals count")
@@ -174,6 +192,9 @@ func ConvertReviewSettingsToAPIModel(
 			BlockOnRejectedReviews:        dbModel.BlockOnRejectedReviews,
 			DismissStaleApprovals:         dbModel.DismissStaleApprovals,
 			RequireSonarqubeQualityGate:   dbModel.EnableSonarQube,
+			RequireUnitStatus:             dbModel.RequireUnitStatus,
+			AllowedUnitStatuses:           dbModel.AllowedUnitStatuses,
+			RequiredQualityGates:          dbModel.RequiredQualityGates,
 		},
 		MergeSettings: MergeSettings{
 			RequireMergeWhitelist:   dbModel.EnableMergeWhitelist,
@@ -228,6 +249,9 @@ func ConvertAPIToReviewSettingsModel(
 			BlockOnRejectedReviews:        apiModel.MergeRestrictions.BlockOnRejectedReviews,
 			DismissStaleApprovals:         apiModel.MergeRestrictions.DismissStaleApprovals,
 			EnableSonarQube:               apiModel.MergeRestrictions.RequireSonarqubeQualityGate,
+			RequireUnitStatus:             apiModel.MergeRestrictions.RequireUnitStatus,
+			AllowedUnitStatuses:           apiModel.MergeRestrictions.AllowedUnitStatuses,
+			RequiredQualityGates:          apiModel.MergeRestrictions.RequiredQualityGates,
 			EnableMergeWhitelist:          apiModel.MergeSettings.RequireMergeWhitelist,
 			MergeWhitelistUserIDs:         whiteListUserIDs,
 			EnableStatusCheck:             apiModel.StatusChecks.EnableStatusCheck,
@@ -5,8 +5,11 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
+	"code.gitea.io/gitea/models/sonar/domain"
 	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/api"
 )
 
@@ -39,10 +42,42 @@ type CreateOrUpdateSonarProjectRequest struct {
 	// example: my-project-key
 	SonarProjectKey TrimmedString `json:"sonar_project_key" validate:"required,max=50"`
 
-	// Token used for authentication with SonarQube
-	// required: true
+	// Token used for authentication with SonarQube. The token is stored encrypted and is never returned.
+	// Required on create unless sonar_token_vault is set, on update the current token is kept if omitted
 	// example: your-secret-token
-	SonarToken TrimmedString `json:"sonar_token" validate:"required,min=40,max=255"`
+	SonarToken TrimmedString `json:"sonar_token" validate:"omitempty,min=40,max=255"`
+
+	// Path to the SonarQube token in Vault, used instead of sonar_token
+	SonarTokenVault *SonarTokenVault `json:"sonar_token_vault"`
+
+	// Secret used by SonarQube to sign webhooks (X-Sonar-Webhook-HMAC-SHA256). The secret is stored encrypted and is never returned.
+	// Webhooks are rejected until the secret is set, on update the current secret is kept if omitted
+	// example: your-webhook-secret
+	SonarWebhookSecret TrimmedString `json:"sonar_webhook_secret" validate:"omitempty,min=16,max=255"`
+}
+
+// SonarTokenVault путь к токену Sonar в Vault (sec man)
+// swagger:model
+type SonarTokenVault struct {
+	// Storage path of the key-value secrets engine, must match the storage allowed by the instance configuration
+	// required: true
+	StoragePath TrimmedString `json:"storage_path" validate:"required,max=255"`
+
+	// Path of the secret, must be inside the secret path prefix allowed for the repository by the instance configuration
+	// required: true
+	SecretPath TrimmedString `json:"secret_path" validate:"required,max=255"`
+
+	// Key of the token in the secret
+	// required: true
+	TokenKey TrimmedString `json:"token_key" validate:"required,max=255"`
+
+	// Version of the key-value secrets engine (1 or 2)
+	VersionKey int `json:"version_key" validate:"omitempty,oneof=1 2"`
+}
+
+// HasToken проверяет, что в запросе передан токен или путь к токену в Vault
+func (c CreateOrUpdateSonarProjectRequest) HasToken() bool {
+	return c.SonarToken != "" || c.SonarTokenVault != nil
 }
 
 func (c CreateOrUpdateSonarProjectRequest) Validate() error {
@@ -68,12 +103,77 @@ func (c CreateOrUpdateSonarProjectRequest) Validate() error {
 		errors = append(errors, "поле 'sonar_project_key' содержит недопустимые символы")
 	}
 
-	if !regexp.MustCompile(`^[a-zA-Z0-9\-._:]+$`).MatchString(c.SonarToken.String()) {
+	if c.SonarToken != "" && !regexp.MustCompile(`^[a-zA-Z0-9\-._:]+$`).MatchString(c.SonarToken.String()) {
 		errors = append(errors, "поле 'sonar_token' содержит недопустимые символы")
 	}
 
+	if c.SonarToken != "" && c.SonarTokenVault != nil {
+		errors = append(errors, "поля 'sonar_token' и 'sonar_token_vault' не могут быть заданы одновременно")
+	}
+
 	if len(errors) > 0 {
 		return api.ValidationErrors{Errors: errors}
 	}
 	return nil
 }
+
+const (
+	// defaultSonarMetricsTrendLimit количество последних анализов ветки в истории метрик по умолчанию
+	defaultSonarMetricsTrendLimit = 100
+	// maxSonarMetricsTrendLimit максимальное количество последних анализов ветки в истории метрик
+	maxSonarMetricsTrendLimit = 1000
+)
+
+// SonarMetricsTrendRequest — параметры запроса истории метрик Sonar по веткам
+type SonarMetricsTrendRequest struct {
+	// Branch name or pull request number in SonarQube, all branches are returned if omitted
+	Branch string `form:"branch"`
+	// Start of the analysis time range in RFC 3339 format
+	From string `form:"from"`
+	// End of the analysis time range in RFC 3339 format
+	To string `form:"to"`
+	// Number of the latest analyses returned for each branch
+	Limit int `form:"limit"`
+}
+
+// ToOptions проверяет параметры запроса и конвертирует их в параметры выборки истории метрик репозитория
+func (r SonarMetricsTrendRequest) ToOptions(repoID int64) (domain.SonarMetricsTrendOptions, error) {
+	var errors []string
+	opts := domain.SonarMetricsTrendOptions{
+		RepoID: repoID,
+		Branch: strings.TrimSpace(r.Branch),
+		Limit:  r.Limit,
+	}
+
+	if r.From != "" {
+		from, err := time.Parse(time.RFC3339, r.From)
+		if err != nil {
+			errors = append(errors, "поле 'from' должно быть временем в формате RFC 3339")
+		} else {
+			opts.From = timeutil.TimeStamp(from.Unix())
+		}
+	}
+	if r.To != "" {
+		to, err := time.Parse(time.RFC3339, r.To)
+		if err != nil {
+			errors = append(errors, "поле 'to' должно быть временем в формате RFC 3339")
+		} else {
+			opts.To = timeutil.TimeStamp(to.Unix())
+		}
+	}
+	if opts.From > 0 && opts.To > 0 && opts.From > opts.To {
+		errors = append(errors, "поле 'from' не может быть позже поля 'to'")
+	}
+
+	switch {
+	case r.Limit == 0:
+		opts.Limit = defaultSonarMetricsTrendLimit
+	case r.Limit < 0 || r.Limit > maxSonarMetricsTrendLimit:
+		errors = append(errors, fmt.Sprintf("поле 'limit' должно быть от 1 до %d", maxSonarMetricsTrendLimit))
+	}
+
+	if len(errors) > 0 {
+		return opts, api.ValidationErrors{Errors: errors}
+	}
+	return opts, nil
+}
@@ -0,0 +1,196 @@
+package pulls
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/default_reviewers/default_reviewers_db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/models/review_settings"
+	"code.gitea.io/gitea/models/review_settings/review_settings_db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	apimodels "code.gitea.io/gitea/routers/api/v3/models"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+type Server struct{}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+// knownMergeErrors ошибки проверки слияния, которые являются причиной запрета, а не ошибкой сервера
+var knownMergeErrors = []error{
+	pull_service.ErrIsClosed,
+	pull_service.ErrHasMerged,
+	pull_service.ErrIsWorkInProgress,
+	pull_service.ErrIsChecking,
+	pull_service.ErrNotMergableState,
+	pull_service.ErrDependenciesLeft,
+}
+
+func (s Server) GetMergeCheck(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{tenant}/{project}/{repo}/pulls/{index}/merge_check GetPullMergeCheck
+	// ---
+	// summary: Returns whether the pull request can be merged and the reason if it cannot
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Tenant identifier
+	// - name: project
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Project identifier
+	// - name: repo
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Repository identifier
+	// - name: index
+	//   in: path
+	//   required: true
+	//   type: integer
+	//   format: int64
+	//   description: Index of the pull request
+	// responses:
+	//   200:
+	//     description: Merge check result
+	//     schema:
+	//       "$ref": "#/definitions/PullMergeCheck"
+	//   404:
+	//     description: Not found
+	//   500:
+	//     description: Internal server error
+
+	s.getMergeCheck(ctx)
+}
+
+func (s Server) getMergeCheck(ctx *context.APIContext) {
+	pr, err := issues_model.GetPullRequestByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64("index"))
+	if err != nil {
+		if issues_model.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		log.Error("Error has occurred while getting pull request %d of repo %d. Error: %v", ctx.ParamsInt64("index"), ctx.Repo.Repository.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to get pull request", err)
+		return
+	}
+
+	result := apimodels.PullMergeCheck{Mergeable: true}
+	err = pull_service.CheckPullMergable(ctx, ctx.Doer, &ctx.Repo.Permission, pr, pull_service.MergeCheckTypeGeneral, false)
+	switch {
+	case err == nil:
+	case models.IsErrDisallowedToMerge(err):
+		result.Mergeable = false
+		result.Reason = err.(models.ErrDisallowedToMerge).Reason
+		if result.Reason == "" {
+			result.Reason = "User is not allowed to merge"
+		}
+	case isKnownMergeError(err):
+		result.Mergeable = false
+		result.Reason = err.Error()
+	default:
+		log.Error("Error has occurred while checking pull request %d is mergeable. Error: %v", pr.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to check pull request", err)
+		return
+	}
+
+	engine := db.GetEngine(ctx)
+	manager := pull_service.NewReviewSettings(default_reviewers_db.New(engine), review_settings_db.New(engine))
+	reviewSettings, err := manager.GetMatchedReviewSetting(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		log.Error("Error has occurred while getting review settings of pull request %d. Error: %v", pr.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to get review settings", err)
+		return
+	}
+
+	units, err := s.getUnitStatuses(ctx, reviewSettings, pr)
+	if err != nil {
+		log.Error("Error has occurred while getting unit statuses of pull request %d. Error: %v", pr.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to get unit statuses", err)
+		return
+	}
+	result.Units = units
+	result.QualityGates = s.getQualityGates(ctx, reviewSettings, pr)
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// getUnitStatuses возвращает статусы связанных юнитов, если хотя бы одна настройка ревью требует разрешенного статуса юнита
+func (s Server) getUnitStatuses(ctx *context.APIContext, reviewSettings []*review_settings.ReviewSettings, pr *issues_model.PullRequest) ([]apimodels.PullMergeCheckUnit, error) {
+	required := make([]*review_settings.ReviewSettings, 0, len(reviewSettings))
+	for _, rs := range reviewSettings {
+		if rs.RequireUnitStatus {
+			required = append(required, rs)
+		}
+	}
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	statuses, err := pull_service.GetPullRequestUnitStatuses(ctx, pr)
+	if err != nil {
+		// причина запрета уже указана в результате проверки слияния
+		log.Warn("Unit statuses of pull request %d are not available: %v", pr.ID, err)
+		return nil, nil
+	}
+
+	units := make([]apimodels.PullMergeCheckUnit, 0, len(statuses))
+	for _, status := range statuses {
+		allowed := true
+		for _, rs := range required {
+			allowed = allowed && pull_service.IsUnitStatusAllowed(rs, status.Status)
+		}
+		units = append(units, apimodels.PullMergeCheckUnit{Code: status.Code, Status: status.Status, Allowed: allowed})
+	}
+	return units, nil
+}
+
+// getQualityGates возвращает результаты quality gate, обязательных хотя бы по одной настройке ревью
+func (s Server) getQualityGates(ctx *context.APIContext, reviewSettings []*review_settings.ReviewSettings, pr *issues_model.PullRequest) []apimodels.PullMergeCheckQualityGate {
+	gates := make([]apimodels.PullMergeCheckQualityGate, 0)
+	seen := make(map[quality_gate.Provider]bool)
+	for _, rs := range reviewSettings {
+		statuses, err := pull_service.GetPullRequestQualityGates(ctx, rs, pr)
+		if err != nil {
+			// причина запрета уже указана в результате проверки слияния
+			log.Warn("Quality gates of pull request %d are not available: %v", pr.ID, err)
+			return nil
+		}
+		for _, status := range statuses {
+			if seen[status.Provider] {
+				continue
+			}
+			seen[status.Provider] = true
+			gate := apimodels.PullMergeCheckQualityGate{
+				Provider: string(status.Provider),
+				Status:   string(status.Status),
+				Passed:   status.Status == quality_gate.StatusOK,
+			}
+			if status.Report != nil {
+				gate.CommitSHA = status.Report.CommitSHA
+			}
+			gates = append(gates, gate)
+		}
+	}
+	return gates
+}
+
+func isKnownMergeError(err error) bool {
+	for _, known := range knownMergeErrors {
+		if errors.Is(err, known) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package quality_gates
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v3/models"
+	quality_gate_service "code.gitea.io/gitea/services/quality_gate"
+)
+
+type Server struct{}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+func (s Server) GetQualityGates(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{tenant}/{project}/{repo}/quality_gates GetQualityGates
+	// ---
+	// summary: Returns quality gate providers configured for the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Tenant identifier
+	// - name: project
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Project identifier
+	// - name: repo
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Repository identifier
+	// responses:
+	//   200:
+	//     description: Quality gate settings
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/QualityGateSettings"
+	//   500:
+	//     description: Internal server error
+
+	settings, err := quality_gate.FindSettings(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		log.Error("Error has occurred while getting quality gate settings of repo %d. Error: %v", ctx.Repo.Repository.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to get quality gate settings", err)
+		return
+	}
+
+	result := make([]models.QualityGateSettings, 0, len(settings))
+	for _, providerSettings := range settings {
+		provider, err := quality_gate_service.GetWebhookProvider(string(providerSettings.Provider))
+		if err != nil {
+			log.Warn("Quality gate settings of repo %d have unknown provider: %v", ctx.Repo.Repository.ID, err)
+			continue
+		}
+		result = append(result, models.ConvertQualityGateSettingsToAPIModel(providerSettings, quality_gate_service.EffectiveConditions(provider, providerSettings)))
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s Server) GetQualityGate(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{tenant}/{project}/{repo}/quality_gates/{provider} GetQualityGate
+	// ---
+	// summary: Returns settings of the quality gate provider
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Tenant identifier
+	// - name: project
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Project identifier
+	// - name: repo
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Repository identifier
+	// - name: provider
+	//   in: path
+	//   required: true
+	//   type: string
+	//   enum: [semgrep, trivy, dependency_scanner]
+	//   description: Quality gate provider
+	// responses:
+	//   200:
+	//     description: Quality gate settings
+	//     schema:
+	//       "$ref": "#/definitions/QualityGateSettings"
+	//   404:
+	//     description: Not found
+	//   500:
+	//     description: Internal server error
+
+	provider, err := quality_gate_service.GetWebhookProvider(ctx.Params("provider"))
+	if err != nil {
+		ctx.Error(http.StatusNotFound, "Unknown quality gate provider", err)
+		return
+	}
+
+	settings, err := quality_gate.GetSettings(ctx, ctx.Repo.Repository.ID, provider.Name())
+	if err != nil {
+		if quality_gate.IsErrSettingsNotExist(err) {
+			ctx.Error(http.StatusNotFound, "Quality gate settings not found", err)
+			return
+		}
+		log.Error("Error has occurred while getting %s settings of repo %d. Error: %v", provider.Name(), ctx.Repo.Repository.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to get quality gate settings", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.ConvertQualityGateSettingsToAPIModel(settings, quality_gate_service.EffectiveConditions(provider, settings)))
+}
+
+func (s Server) UpdateQualityGate(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{tenant}/{project}/{repo}/quality_gates/{provider} UpdateQualityGate
+	// ---
+	// summary: Creates or updates settings of the quality gate provider
+	// description: The report token is returned only when the settings are created or the token is regenerated.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Tenant identifier
+	// - name: project
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Project identifier
+	// - name: repo
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Repository identifier
+	// - name: provider
+	//   in: path
+	//   required: true
+	//   type: string
+	//   enum: [semgrep, trivy, dependency_scanner]
+	//   description: Quality gate provider
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/QualityGateSettingsRequest"
+	// responses:
+	//   200:
+	//     description: Quality gate settings updated
+	//     schema:
+	//       "$ref": "#/definitions/QualityGateSettings"
+	//   201:
+	//     description: Quality gate settings created
+	//     schema:
+	//       "$ref": "#/definitions/QualityGateSettings"
+	//   400:
+	//     description: Bad request
+	//   404:
+	//     description: Not found
+	//   500:
+	//     description: Internal server error
+
+	opt := web.GetForm(ctx).(*models.QualityGateSettingsRequest)
+	auditParams := map[string]string{
+		"repository":    ctx.Repo.Repository.Name,
+		"repository_id": strconv.FormatInt(ctx.Repo.Repository.ID, 10),
+		"project":       ctx.Repo.Repository.OwnerName,
+		"tenant_id":     ctx.Tenant.TenantID,
+		"provider":      ctx.Params("provider"),
+	}
+	auditInfo := auditutils.NewRequiredAuditParamsFromApiContext(ctx)
+
+	provider, err := quality_gate_service.GetWebhookProvider(ctx.Params("provider"))
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting quality gate provider"
+		audit.CreateAndSendEvent(audit.QualityGateSettingsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		ctx.Error(http.StatusNotFound, "Unknown quality gate provider", err)
+		return
+	}
+	if err = opt.Validate(); err != nil {
+		log.Warn("Incorrect request params: %v", err)
+		auditParams["error"] = "Error has occurred while validating form"
+		audit.CreateAndSendEvent(audit.QualityGateSettingsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		ctx.Error(http.StatusBadRequest, "Update quality gate settings", err)
+		return
+	}
+
+	settings, created, err := quality_gate_service.SaveSettings(ctx, ctx.Repo.Repository.ID, provider, models.ConvertQualityGateConditionsToDBModel(opt.Conditions), opt.RegenerateToken)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while saving quality gate settings"
+		audit.CreateAndSendEvent(audit.QualityGateSettingsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		if quality_gate_service.IsErrInvalidConditions(err) {
+			ctx.Error(http.StatusBadRequest, "Update quality gate settings", err)
+			return
+		}
+		log.Error("Error has occurred while saving %s settings of repo %d. Error: %v", provider.Name(), ctx.Repo.Repository.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to save quality gate settings", err)
+		return
+	}
+	audit.CreateAndSendEvent(audit.QualityGateSettingsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	ctx.JSON(status, models.ConvertQualityGateSettingsToAPIModel(settings, quality_gate_service.EffectiveConditions(provider, settings)))
+}
+
+func (s Server) DeleteQualityGate(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{tenant}/{project}/{repo}/quality_gates/{provider} DeleteQualityGate
+	// ---
+	// summary: Deletes settings of the quality gate provider
+	// parameters:
+	// - name: tenant
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Tenant identifier
+	// - name: project
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Project identifier
+	// - name: repo
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Repository identifier
+	// - name: provider
+	//   in: path
+	//   required: true
+	//   type: string
+	//   enum: [semgrep, trivy, dependency_scanner]
+	//   description: Quality gate provider
+	// responses:
+	//   204:
+	//     description: Quality gate settings deleted
+	//   404:
+	//     description: Not found
+	//   500:
+	//     description: Internal server error
+
+	auditParams := map[string]string{
+		"repository":    ctx.Repo.Repository.Name,
+		"repository_id": strconv.FormatInt(ctx.Repo.Repository.ID, 10),
+		"project":       ctx.Repo.Repository.OwnerName,
+		"tenant_id":     ctx.Tenant.TenantID,
+		"provider":      ctx.Params("provider"),
+	}
+	auditInfo := auditutils.NewRequiredAuditParamsFromApiContext(ctx)
+
+	provider, err := quality_gate_service.GetWebhookProvider(ctx.Params("provider"))
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting quality gate provider"
+		audit.CreateAndSendEvent(audit.QualityGateSettingsDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		ctx.Error(http.StatusNotFound, "Unknown quality gate provider", err)
+		return
+	}
+
+	if err = quality_gate.DeleteSettings(ctx, ctx.Repo.Repository.ID, provider.Name()); err != nil {
+		auditParams["error"] = "Error has occurred while deleting quality gate settings"
+		audit.CreateAndSendEvent(audit.QualityGateSettingsDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		if quality_gate.IsErrSettingsNotExist(err) {
+			ctx.Error(http.StatusNotFound, "Quality gate settings not found", err)
+			return
+		}
+		log.Error("Error has occurred while deleting %s settings of repo %d. Error: %v", provider.Name(), ctx.Repo.Repository.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Fail to delete quality gate settings", err)
+		return
+	}
+	audit.CreateAndSendEvent(audit.QualityGateSettingsDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+
+	ctx.Status(http.StatusNoContent)
+}
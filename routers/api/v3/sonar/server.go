@@ -60,7 +60,6 @@ func (s Server) CreateSonarSettings(ctx *context.APIContext) {
 	//     required:
 	//       - sonar_server_url
 	//       - sonar_project_key
-	//       - sonar_token
 	//     properties:
 	//       sonar_server_url:
 	//         type: string
@@ -72,8 +71,14 @@ func (s Server) CreateSonarSettings(ctx *context.APIContext) {
 	//         example: "my-project-key"
 	//       sonar_token:
 	//         type: string
-	//         description: Token used for authentication with SonarQube
+	//         description: Token used for authentication with SonarQube, stored encrypted and never returned. Required unless sonar_token_vault is set
 	//         example: "your-secret-token"
+	//       sonar_token_vault:
+	//         "$ref": "#/definitions/SonarTokenVault"
+	//       sonar_webhook_secret:
+	//         type: string
+	//         description: Secret used by SonarQube to sign webhooks, stored encrypted and never returned. Webhooks are rejected until it is set
+	//         example: "your-webhook-secret"
 	// responses:
 	//   200:
 	//     description: Sonar settings successfully created or updated
@@ -137,15 +142,24 @@ func (s Server) CreateSonarSettings(ctx *context.APIContext) {
 		ctx.Error(http.StatusBadRequest, "Create sonar settings", err)
 		return
 	}
+	if !opt.HasToken() {
+		log.Warn("Incorrect request params: sonar token is required")
+		auditParams["error"] = "Error has occurred while validating form"
+		audit.CreateAndSendEvent(audit.SonarSettingsCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		ctx.Error(http.StatusBadRequest, "Create sonar settings", "sonar_token or sonar_token_vault is required")
+		return
+	}
 
 	if err := s.uc.CreateSonarSettings(ctx,
 		domain.CreateOrUpdateSonarProjectRequest{
-			SonarServerURL:  opt.SonarServerURL.String(),
-			SonarProjectKey: opt.SonarProjectKey.String(),
-			SonarToken:      opt.SonarToken.String(),
-			RepoId:          ctx.Repo.Repository.ID,
-			TenantKey:       ctx.Tenant.OrgKey,
-			Project:         ctx.ContextUser,
+			SonarServerURL:     opt.SonarServerURL.String(),
+			SonarProjectKey:    opt.SonarProjectKey.String(),
+			SonarToken:         opt.SonarToken.String(),
+			SonarTokenVault:    convertSonarTokenVault(opt.SonarTokenVault),
+			SonarWebhookSecret: opt.SonarWebhookSecret.String(),
+			RepoId:             ctx.Repo.Repository.ID,
+			TenantKey:          ctx.Tenant.OrgKey,
+			Project:            ctx.ContextUser,
 		}); err != nil {
 		switch {
 		case sonar.IsSonarSettingsAlreadyExists(err):
@@ -153,6 +167,12 @@ func (s Server) CreateSonarSettings(ctx *context.APIContext) {
 			auditParams["error"] = "Error has occurred while creating sonar settings"
 			audit.CreateAndSendEvent(audit.SonarSettingsCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
 			ctx.Error(http.StatusConflict, "Sonar settings already exist", err)
+		case repo.IsErrSonarTokenVaultNotAllowed(err):
+			log.Warn("Sonar token vault path is not allowed: %v", err)
+			auditParams["error"] = "Error has occurred while creating sonar settings"
+			audit.CreateAndSendEvent(audit.SonarSettingsCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			ctx.Error(http.StatusBadRequest, "Sonar token vault path is not allowed", err)
+
 		case repo.IsErrRepoNotExist(err):
 			log.Warn("Repo not exist: %v", err)
 			auditParams["error"] = "Error has occurred while creating sonar settings"
@@ -196,6 +216,83 @@ func (s Server) SonarSettings(ctx *context.APIContext) {
 	return
 }
 
+func (s Server) SonarMetricsTrend(ctx *context.APIContext) {
+	// swagger:operation GET /{tenant}/{project}/{repo}/sonar/metrics/trend SonarMetricsTrend
+	//
+	// ---
+	// summary: Get Sonar metrics history of a repository
+	// description: Returns coverage, bugs, vulnerabilities and duplications of every kept SonarQube analysis, grouped by branch and ordered by analysis time.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tenant
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Tenant identifier
+	// - name: project
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Project identifier
+	// - name: repo
+	//   in: path
+	//   required: true
+	//   type: string
+	//   description: Repository identifier
+	// - name: branch
+	//   in: query
+	//   type: string
+	//   description: Branch name or pull request number in SonarQube, all branches are returned if omitted
+	// - name: from
+	//   in: query
+	//   type: string
+	//   format: date-time
+	//   description: Start of the analysis time range in RFC 3339 format
+	// - name: to
+	//   in: query
+	//   type: string
+	//   format: date-time
+	//   description: End of the analysis time range in RFC 3339 format
+	// - name: limit
+	//   in: query
+	//   type: integer
+	//   description: Number of the latest analyses returned for each branch (1-1000, default 100)
+	// responses:
+	//   200:
+	//     description: Sonar metrics history grouped by branch
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/SonarBranchMetricsTrend"
+	//   400:
+	//     "$ref": "#/responses/validationError"
+	//   404:
+	//     "$ref": "#/responses/notFound"
+	//   500:
+	//     "$ref": "#/responses/error"
+	opt := web.GetForm(ctx).(*models.SonarMetricsTrendRequest)
+	opts, err := opt.ToOptions(ctx.Repo.Repository.ID)
+	if err != nil {
+		log.Warn("Incorrect request params: %v", err)
+		ctx.Error(http.StatusBadRequest, "Get sonar metrics trend", err)
+		return
+	}
+
+	trends, err := s.uc.SonarMetricsTrend(ctx, opts)
+	if err != nil {
+		if sonar.IsSonarSettingsNotFound(err) {
+			log.Warn("Sonar settings not found: %v", err)
+			ctx.Error(http.StatusNotFound, "Sonar settings not found", err)
+			return
+		}
+		log.Error("Error has occurred while getting sonar metrics trend: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, trends)
+}
+
 func (s Server) UpdateSonarSettings(ctx *context.APIContext) {
 	opt := web.GetForm(ctx).(*models.CreateOrUpdateSonarProjectRequest)
 	auditParams := map[string]string{
@@ -215,11 +312,13 @@ func (s Server) UpdateSonarSettings(ctx *context.APIContext) {
 	}
 	if err := s.uc.UpdateSonarSettings(ctx,
 		domain.CreateOrUpdateSonarProjectRequest{
-			SonarServerURL:  opt.SonarServerURL.String(),
-			SonarProjectKey: opt.SonarProjectKey.String(),
-			SonarToken:      opt.SonarToken.String(),
-			RepoId:          ctx.Repo.Repository.ID,
-			TenantKey:       ctx.Tenant.OrgKey,
+			SonarServerURL:     opt.SonarServerURL.String(),
+			SonarProjectKey:    opt.SonarProjectKey.String(),
+			SonarToken:         opt.SonarToken.String(),
+			SonarTokenVault:    convertSonarTokenVault(opt.SonarTokenVault),
+			SonarWebhookSecret: opt.SonarWebhookSecret.String(),
+			RepoId:             ctx.Repo.Repository.ID,
+			TenantKey:          ctx.Tenant.OrgKey,
 		}); err != nil {
 		switch {
 		case sonar.IsSonarSettingsNotFound(err):
@@ -228,6 +327,12 @@ func (s Server) UpdateSonarSettings(ctx *context.APIContext) {
 			audit.CreateAndSendEvent(audit.SonarSettingsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
 			ctx.Error(http.StatusNotFound, "Sonar settings already exist", err)
 
+		case repo.IsErrSonarTokenVaultNotAllowed(err):
+			log.Warn("Sonar token vault path is not allowed: %v", err)
+			auditParams["error"] = "Error has occurred while updating sonar settings"
+			audit.CreateAndSendEvent(audit.SonarSettingsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			ctx.Error(http.StatusBadRequest, "Sonar token vault path is not allowed", err)
+
 		case repo.IsErrRepoNotExist(err):
 			log.Warn("Repo not exist: %v", err)
 			auditParams["error"] = "Error has occurred while creating sonar settings"
@@ -283,3 +388,16 @@ func (s Server) DeleteSonarSettings(ctx *context.APIContext) {
 	ctx.Status(http.StatusNoContent)
 	return
 }
+
+// convertSonarTokenVault конвертирует путь к токену Sonar в Vault из запроса в доменную модель
+func convertSonarTokenVault(vault *models.SonarTokenVault) *domain.SonarTokenVault {
+	if vault == nil {
+		return nil
+	}
+	return &domain.SonarTokenVault{
+		StoragePath: vault.StoragePath.String(),
+		SecretPath:  vault.SecretPath.String(),
+		TokenKey:    vault.TokenKey.String(),
+		VersionKey:  vault.VersionKey,
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/external"
 	"code.gitea.io/gitea/modules/notification"
+	audit_sinks "code.gitea.io/gitea/modules/sbt/audit/sinks"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/ssh"
 	"code.gitea.io/gitea/modules/storage"
@@ -42,6 +43,7 @@ import (
 	packages_router "code.gitea.io/gitea/routers/api/packages"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	apiv2 "code.gitea.io/gitea/routers/api/v2"
+	kafka_commands "code.gitea.io/gitea/routers/api/v2/commands"
 	apiv3 "code.gitea.io/gitea/routers/api/v3"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/internal"
@@ -50,6 +52,7 @@ import (
 	"code.gitea.io/gitea/routers/sc"
 	web_routers "code.gitea.io/gitea/routers/web"
 	actions_service "code.gitea.io/gitea/services/actions"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/automerge"
@@ -156,10 +159,13 @@ func GlobalInitInstalled(ctx context.Context) {
 	log.Info("ORM engine initialization successful!")
 	mustInitCtx(ctx, kafka.InitClient)
 	log.Info("Kafka client initialization successful!")
+	mustInitCtx(ctx, audit_service.InitChain)
+	mustInitCtx(ctx, audit_sinks.Init)
 	mustInit(system.Init)
 	mustInit(oauth2.Init)
 
 	mustInitCtx(ctx, models.Init)
+	mustInitCtx(ctx, audit_service.Init)
 	mustInit(repo_service.Init)
 
 	// Booting long running goroutines.
@@ -198,6 +204,8 @@ func GlobalInitInstalled(ctx context.Context) {
 	gitaly.InitializeSidechannelRegistry()
 	// заполняем таблицу с информацией о лицензиях из папки licenses
 	mustInitCtx(ctx, create_default.CreateLicensesInfo)
+	// Чтение команд из Kafka
+	mustInitCtx(ctx, kafka_commands.Init)
 	// Finally start up the cron
 	cron.NewContext(ctx)
 }
@@ -2,26 +2,19 @@ package code_hub_counter
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strconv"
 
+	"code.gitea.io/gitea/models/background_task"
 	"code.gitea.io/gitea/models/code_hub_counter_task"
-	"code.gitea.io/gitea/models/code_hub_counter_task/code_hub_counter_task_db"
+	"code.gitea.io/gitea/models/code_hub_usage_stat"
 	"code.gitea.io/gitea/models/internal_metric_counter"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 const UniqueClonesMetricKey = "unique_clones"
 
-// //go:generate mockery --name=taskDB --exported
-type taskDB interface {
-	LockTask(ctx context.Context, taskID int64) error
-	UnlockTask(ctx context.Context, taskID int64) error
-	UnlockTaskWithSuccess(ctx context.Context, taskID int64) error
-	GetCodeHubCounterTasks(_ context.Context) ([]code_hub_counter_task.CodeHubCounterTasks, error)
-	DeleteTask(ctx context.Context, taskID int64) error
-}
-
 // //go:generate mockery --name=uniqueUsagesDB --exported
 type uniqueUsagesDB interface {
 	CountUniqueUsagesByRepoID(_ context.Context, repoID int64) (int, error)
@@ -35,66 +28,85 @@ type counterDB interface {
 	GetInternalMetricCounters(_ context.Context) ([]internal_metric_counter.InternalMetricCounter, error)
 }
 
+// //go:generate mockery --name=usageStatsDB --exported
+type usageStatsDB interface {
+	AddUsage(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, at timeutil.TimeStamp) error
+	AddUniqueUsage(ctx context.Context, repoID int64, metric code_hub_usage_stat.Metric, at timeutil.TimeStamp, userID int64) error
+}
+
+// actionMetrics метрики дневной статистики, которые увеличивает каждое действие
+var actionMetrics = map[code_hub_counter_task.CodeHubAction]code_hub_usage_stat.Metric{
+	code_hub_counter_task.ForkRepositoryAction:  code_hub_usage_stat.ForksMetric,
+	code_hub_counter_task.StarRepositoryAction:  code_hub_usage_stat.StarsMetric,
+	code_hub_counter_task.DownloadArchiveAction: code_hub_usage_stat.ArchiveDownloadsMetric,
+	code_hub_counter_task.DownloadPackageAction: code_hub_usage_stat.PackageDownloadsMetric,
+}
+
 type codeHubCounter struct {
-	taskDB
 	uniqueUsagesDB
 	counterDB
+	usageStatsDB
 }
 
-func NewCodeHubCounter(taskWorker taskDB, uniqueUsages uniqueUsagesDB, counter counterDB) codeHubCounter {
-	return codeHubCounter{taskDB: taskWorker, uniqueUsagesDB: uniqueUsages, counterDB: counter}
+func NewCodeHubCounter(uniqueUsages uniqueUsagesDB, counter counterDB, usageStats usageStatsDB) codeHubCounter {
+	return codeHubCounter{uniqueUsagesDB: uniqueUsages, counterDB: counter, usageStatsDB: usageStats}
 }
 
-// ProcessNewUsageTasks метод для обработки необработанных логов об использовании репозитория в таблице code_hub_counter_tasks
-func (c codeHubCounter) ProcessNewUsageTasks(ctx context.Context) error {
-	tasks, err := c.taskDB.GetCodeHubCounterTasks(ctx)
-
-	if err != nil {
-		return fmt.Errorf("getting unprocessed tasks: %w", err)
+// ProcessUsageTask обработать запись об использовании репозитория из очереди code_hub_counter_task.Queue
+func (c codeHubCounter) ProcessUsageTask(ctx context.Context, task *background_task.Task, payload code_hub_counter_task.Payload) error {
+	at := payload.At
+	if at == 0 {
+		at = task.CreatedAt
 	}
 
-	if tasks == nil || len(tasks) == 0 {
-		return nil
+	// клонирование и скачивание архива считаются использованием репозитория пользователем
+	if payload.Action == code_hub_counter_task.CloneRepositoryAction || payload.Action == code_hub_counter_task.DownloadArchiveAction {
+		if err := c.processUniqueUsage(ctx, payload, at); err != nil {
+			return err
+		}
 	}
 
-	for _, task := range tasks {
-		if err = c.taskDB.LockTask(ctx, task.ID); err != nil {
-			if handledErr := new(code_hub_counter_task_db.TaskAlreadyLockedError); errors.As(err, &handledErr) {
-				log.Debug("error has occurred while locking task id: '%d' err: %s", task.ID, handledErr.Error())
-			}
-			continue
-		}
-		var uniqueUsagesCount int
-		if uniqueUsagesCount, err = c.uniqueUsagesDB.CountUniqueUsages(ctx, task.RepoID, task.UserID); err != nil {
-			log.Error("error has occurred while counting uniqueUsagesCount usages for repo %d and user %d: %v", task.RepoID, task.UserID, err)
-			c.handleErr(ctx, task.ID)
-			continue
+	if metric, ok := actionMetrics[payload.Action]; ok {
+		if err := c.usageStatsDB.AddUsage(ctx, payload.RepoID, metric, at); err != nil {
+			log.Error("error has occurred while adding %s usage for repo %d: %v", metric, payload.RepoID, err)
+			return fmt.Errorf("add usage: %w", err)
 		}
+	}
 
-		// если использование уникально, заносим в таблицу уникальных использований новую запись
-		if uniqueUsagesCount == 0 {
-			if err = c.uniqueUsagesDB.UpdateUniqueUsage(ctx, task.RepoID, task.UserID); err != nil {
-				log.Error("error has occurred while inserting uniqueUsagesCount usage for repo %d and user %d: %v", task.RepoID, task.UserID, err)
-				c.handleErr(ctx, task.ID)
-				continue
-			}
-		}
+	log.Debug("process task %d: %d, %d %v: success", task.ID, payload.RepoID, payload.UserID, payload.Action)
+	return nil
+}
 
-		if unlockErr := c.taskDB.UnlockTaskWithSuccess(ctx, task.ID); unlockErr != nil {
-			c.handleErr(ctx, task.ID)
-			continue
-		}
+func (c codeHubCounter) processUniqueUsage(ctx context.Context, payload code_hub_counter_task.Payload, at timeutil.TimeStamp) error {
+	uniqueUsagesCount, err := c.uniqueUsagesDB.CountUniqueUsages(ctx, payload.RepoID, payload.UserID)
+	if err != nil {
+		log.Error("error has occurred while counting uniqueUsagesCount usages for repo %d and user %d: %v", payload.RepoID, payload.UserID, err)
+		return fmt.Errorf("count unique usages: %w", err)
+	}
 
-		if deleteErr := c.taskDB.DeleteTask(ctx, task.ID); deleteErr != nil {
-			log.Error("error has occurred while deleting task for repo %d and user %d: %v", task.RepoID, task.UserID, err)
-			continue
+	// если использование уникально, заносим в таблицу уникальных использований новую запись
+	if uniqueUsagesCount == 0 {
+		if err = c.uniqueUsagesDB.UpdateUniqueUsage(ctx, payload.RepoID, payload.UserID); err != nil {
+			log.Error("error has occurred while inserting uniqueUsagesCount usage for repo %d and user %d: %v", payload.RepoID, payload.UserID, err)
+			return fmt.Errorf("update unique usage: %w", err)
 		}
+	}
 
-		log.Debug("process task: %d, %d %v: success", task.RepoID, task.UserID, task.Action)
+	if err = c.usageStatsDB.AddUniqueUsage(ctx, payload.RepoID, code_hub_usage_stat.UniqueClonersMetric, at, payload.UserID); err != nil {
+		log.Error("error has occurred while adding daily unique usage for repo %d and user %d: %v", payload.RepoID, payload.UserID, err)
+		return fmt.Errorf("add daily unique usage: %w", err)
 	}
 	return nil
 }
 
+// AuditParams параметры задачи для события аудита о переводе задачи в состояние dead
+func AuditParams(payload code_hub_counter_task.Payload) map[string]string {
+	return map[string]string{
+		"task_action": string(payload.Action),
+		"repo_id":     strconv.FormatInt(payload.RepoID, 10),
+	}
+}
+
 // CalculateRepoCounters пересчитать статистику уникальных использований для всех репозиториев
 func (c codeHubCounter) CalculateRepoCounters(ctx context.Context) error {
 	counters, err := c.counterDB.GetInternalMetricCounters(ctx)
@@ -125,11 +137,3 @@ func (c codeHubCounter) CalculateRepoCounters(ctx context.Context) error {
 	}
 	return nil
 }
-
-func (c codeHubCounter) handleErr(ctx context.Context, taskID int64) {
-	if err := c.taskDB.UnlockTask(ctx, taskID); err != nil {
-		if handledErr := new(code_hub_counter_task_db.TaskAlreadyLockedError); errors.As(err, &handledErr) {
-			log.Debug("error has occurred while unlocking task id: '%d' err: %s", taskID, handledErr.Error())
-		}
-	}
-}
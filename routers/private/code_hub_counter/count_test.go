@@ -8,8 +8,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"code.gitea.io/gitea/models/background_task"
 	"code.gitea.io/gitea/models/code_hub_counter_task"
+	"code.gitea.io/gitea/models/code_hub_usage_stat"
 	"code.gitea.io/gitea/models/internal_metric_counter"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/private/code_hub_counter/mocks"
 )
 
@@ -17,114 +20,139 @@ func TestProcessTasksSuccess(t *testing.T) {
 	ctx := context.Background()
 	userID := int64(1)
 	repoID := int64(2)
-	mockTaskDB := mocks.NewTaskDB(t)
+	at := timeutil.TimeStamp(1700000000)
 	mockUniqueUsagesDB := mocks.NewUniqueUsagesDB(t)
-	counter := NewCodeHubCounter(mockTaskDB, mockUniqueUsagesDB, nil)
-	counterTasksReturn := make([]code_hub_counter_task.CodeHubCounterTasks, 1)
-	counterTasksReturn[0] = code_hub_counter_task.CodeHubCounterTasks{
-		ID:     int64(1),
+	mockUsageStatsDB := mocks.NewUsageStatsDB(t)
+	counter := NewCodeHubCounter(mockUniqueUsagesDB, nil, mockUsageStatsDB)
+	task := &background_task.Task{ID: int64(1), Queue: code_hub_counter_task.Queue.Name}
+	payload := code_hub_counter_task.Payload{
 		UserID: userID,
 		RepoID: repoID,
 		Action: code_hub_counter_task.CloneRepositoryAction,
-		Status: code_hub_counter_task.StatusUnlocked,
+		At:     at,
 	}
-	mockTaskDB.
-		On("GetCodeHubCounterTasks", testCtx).
-		Return(counterTasksReturn, nil)
-	mockTaskDB.
-		On("LockTask", testCtx, int64(1)).
-		Return(nil)
 	mockUniqueUsagesDB.
 		On("CountUniqueUsages", testCtx, repoID, userID).
 		Return(0, nil)
 	mockUniqueUsagesDB.
 		On("UpdateUniqueUsage", testCtx, repoID, userID).
 		Return(nil)
-	mockTaskDB.
-		On("UnlockTaskWithSuccess", testCtx, int64(1)).
-		Return(nil)
-	mockTaskDB.
-		On("DeleteTask", testCtx, int64(1)).
+	mockUsageStatsDB.
+		On("AddUniqueUsage", testCtx, repoID, code_hub_usage_stat.UniqueClonersMetric, at, userID).
 		Return(nil)
 
-	err := counter.ProcessNewUsageTasks(ctx)
+	err := counter.ProcessUsageTask(ctx, task, payload)
 	assert.NoError(t, err)
 
 	mockUniqueUsagesDB.AssertCalled(t, "UpdateUniqueUsage", testCtx, repoID, userID)
-	mockTaskDB.AssertCalled(t, "DeleteTask", testCtx, int64(1))
 }
 
 func TestProcessTasksSuccessDuplicate(t *testing.T) {
 	ctx := context.Background()
 	userID := int64(1)
 	repoID := int64(2)
-	mockTaskDB := mocks.NewTaskDB(t)
+	createdAt := timeutil.TimeStamp(1700000000)
 	mockUniqueUsagesDB := mocks.NewUniqueUsagesDB(t)
-	counter := NewCodeHubCounter(mockTaskDB, mockUniqueUsagesDB, nil)
-	counterTasksReturn := make([]code_hub_counter_task.CodeHubCounterTasks, 1)
-	counterTasksReturn[0] = code_hub_counter_task.CodeHubCounterTasks{
-		ID:     int64(1),
+	mockUsageStatsDB := mocks.NewUsageStatsDB(t)
+	counter := NewCodeHubCounter(mockUniqueUsagesDB, nil, mockUsageStatsDB)
+	task := &background_task.Task{ID: int64(1), Queue: code_hub_counter_task.Queue.Name, CreatedAt: createdAt}
+	payload := code_hub_counter_task.Payload{
 		UserID: userID,
 		RepoID: repoID,
 		Action: code_hub_counter_task.CloneRepositoryAction,
-		Status: code_hub_counter_task.StatusUnlocked,
 	}
-	mockTaskDB.
-		On("GetCodeHubCounterTasks", testCtx).
-		Return(counterTasksReturn, nil)
-	mockTaskDB.
-		On("LockTask", testCtx, int64(1)).
-		Return(nil)
-	// Не уникальное использование
+	// Не уникальное использование, время действия берется из задачи
 	mockUniqueUsagesDB.
 		On("CountUniqueUsages", testCtx, repoID, userID).
 		Return(1, nil)
-	mockTaskDB.
-		On("UnlockTaskWithSuccess", testCtx, int64(1)).
-		Return(nil)
-	mockTaskDB.
-		On("DeleteTask", testCtx, int64(1)).
+	mockUsageStatsDB.
+		On("AddUniqueUsage", testCtx, repoID, code_hub_usage_stat.UniqueClonersMetric, createdAt, userID).
 		Return(nil)
 
-	err := counter.ProcessNewUsageTasks(ctx)
+	err := counter.ProcessUsageTask(ctx, task, payload)
 	assert.NoError(t, err)
 
 	mockUniqueUsagesDB.AssertNotCalled(t, "UpdateUniqueUsage", testCtx, mock.Anything, mock.Anything)
-	mockTaskDB.AssertCalled(t, "DeleteTask", testCtx, int64(1))
 }
 
 func TestProcessTasksHandleErr(t *testing.T) {
 	ctx := context.Background()
 	userID := int64(1)
 	repoID := int64(2)
-	mockTaskDB := mocks.NewTaskDB(t)
 	mockUniqueUsagesDB := mocks.NewUniqueUsagesDB(t)
-	counter := NewCodeHubCounter(mockTaskDB, mockUniqueUsagesDB, nil)
-	counterTasksReturn := make([]code_hub_counter_task.CodeHubCounterTasks, 1)
-	counterTasksReturn[0] = code_hub_counter_task.CodeHubCounterTasks{
-		ID:     int64(1),
+	counter := NewCodeHubCounter(mockUniqueUsagesDB, nil, nil)
+	task := &background_task.Task{ID: int64(1), Queue: code_hub_counter_task.Queue.Name}
+	payload := code_hub_counter_task.Payload{
 		UserID: userID,
 		RepoID: repoID,
 		Action: code_hub_counter_task.CloneRepositoryAction,
-		Status: code_hub_counter_task.StatusUnlocked,
 	}
-	mockTaskDB.
-		On("GetCodeHubCounterTasks", testCtx).
-		Return(counterTasksReturn, nil)
-	mockTaskDB.
-		On("LockTask", testCtx, int64(1)).
-		Return(nil)
 	mockUniqueUsagesDB.
 		On("CountUniqueUsages", testCtx, repoID, userID).
-		Return(0, errors.New(""))
-	mockTaskDB.
-		On("UnlockTask", testCtx, int64(1)).
-		Return(nil)
+		Return(0, errors.New("db is unavailable"))
 
-	err := counter.ProcessNewUsageTasks(ctx)
-	assert.NoError(t, err)
+	err := counter.ProcessUsageTask(ctx, task, payload)
+	assert.Error(t, err)
+
+	mockUniqueUsagesDB.AssertNotCalled(t, "UpdateUniqueUsage", testCtx, mock.Anything, mock.Anything)
+}
+
+func TestProcessTasksActionMetrics(t *testing.T) {
+	ctx := context.Background()
+	repoID := int64(2)
+	at := timeutil.TimeStamp(1700000000)
+	task := &background_task.Task{ID: int64(1), Queue: code_hub_counter_task.Queue.Name}
+	cases := map[code_hub_counter_task.CodeHubAction]code_hub_usage_stat.Metric{
+		code_hub_counter_task.ForkRepositoryAction:  code_hub_usage_stat.ForksMetric,
+		code_hub_counter_task.StarRepositoryAction:  code_hub_usage_stat.StarsMetric,
+		code_hub_counter_task.DownloadPackageAction: code_hub_usage_stat.PackageDownloadsMetric,
+	}
+
+	for action, metric := range cases {
+		t.Run(string(action), func(t *testing.T) {
+			mockUniqueUsagesDB := mocks.NewUniqueUsagesDB(t)
+			mockUsageStatsDB := mocks.NewUsageStatsDB(t)
+			counter := NewCodeHubCounter(mockUniqueUsagesDB, nil, mockUsageStatsDB)
+			mockUsageStatsDB.
+				On("AddUsage", testCtx, repoID, metric, at).
+				Return(nil)
+
+			err := counter.ProcessUsageTask(ctx, task, code_hub_counter_task.Payload{RepoID: repoID, Action: action, At: at})
+			assert.NoError(t, err)
+
+			mockUniqueUsagesDB.AssertNotCalled(t, "CountUniqueUsages", testCtx, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestProcessTasksDownloadArchive(t *testing.T) {
+	ctx := context.Background()
+	userID := int64(1)
+	repoID := int64(2)
+	at := timeutil.TimeStamp(1700000000)
+	mockUniqueUsagesDB := mocks.NewUniqueUsagesDB(t)
+	mockUsageStatsDB := mocks.NewUsageStatsDB(t)
+	counter := NewCodeHubCounter(mockUniqueUsagesDB, nil, mockUsageStatsDB)
+	task := &background_task.Task{ID: int64(1), Queue: code_hub_counter_task.Queue.Name}
+	payload := code_hub_counter_task.Payload{
+		UserID: userID,
+		RepoID: repoID,
+		Action: code_hub_counter_task.DownloadArchiveAction,
+		At:     at,
+	}
+	// скачивание архива учитывается и как использование, и как скачивание
+	mockUniqueUsagesDB.
+		On("CountUniqueUsages", testCtx, repoID, userID).
+		Return(1, nil)
+	mockUsageStatsDB.
+		On("AddUniqueUsage", testCtx, repoID, code_hub_usage_stat.UniqueClonersMetric, at, userID).
+		Return(nil)
+	mockUsageStatsDB.
+		On("AddUsage", testCtx, repoID, code_hub_usage_stat.ArchiveDownloadsMetric, at).
+		Return(errors.New("db is unavailable"))
 
-	mockTaskDB.AssertCalled(t, "UnlockTask", testCtx, int64(1))
+	err := counter.ProcessUsageTask(ctx, task, payload)
+	assert.Error(t, err)
 }
 
 func TestCalculateRepoCounters(t *testing.T) {
@@ -132,7 +160,7 @@ func TestCalculateRepoCounters(t *testing.T) {
 	repoID := int64(1)
 	mockCounterDB := mocks.NewCounterDB(t)
 	mockUniqueUsagesDB := mocks.NewUniqueUsagesDB(t)
-	counter := NewCodeHubCounter(nil, mockUniqueUsagesDB, mockCounterDB)
+	counter := NewCodeHubCounter(mockUniqueUsagesDB, mockCounterDB, nil)
 	countersReturn := make([]internal_metric_counter.InternalMetricCounter, 1)
 	countersReturn[0] = internal_metric_counter.InternalMetricCounter{
 		ID:     int64(1),
@@ -328,6 +328,10 @@ func (s Server) HookPostReceive(ctx *gitea_context.PrivateContext) {
 		if err := s.linkUnits(ctx, opts); err != nil {
 			log.Debug("unit_linker: run: %v", err)
 		}
+
+		if err := s.linkRefs(ctx, ownerName, repoName, opts); err != nil {
+			log.Debug("unit_linker: link refs: %v", err)
+		}
 	}
 
 	dbEngine := db.GetEngine(ctx)
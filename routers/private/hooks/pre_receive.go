@@ -11,12 +11,15 @@ import (
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	pull_model "code.gitea.io/gitea/models/pull"
+	tenant_model "code.gitea.io/gitea/models/tenant"
 	user_model "code.gitea.io/gitea/models/user"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -31,6 +34,10 @@ func (s Server) HookPreReceive(ctx *gitea_context.PrivateContext) {
 		opts:           opts,
 	}
 
+	if setting.SourceControl.Enabled && !ourCtx.assertTenantGitQuota() {
+		return
+	}
+
 	// Iterate across the provided old commit IDs
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
@@ -65,6 +72,110 @@ func (s Server) HookPreReceive(ctx *gitea_context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
+// assertTenantGitQuota проверяет, что размер репозиториев тенанта с учетом прироста от push не превысит квоту.
+// Удаление ссылок и push, не увеличивающие размер репозитория (в том числе force-push с сокращением истории), не блокируются
+func (ctx *preReceiveContext) assertTenantGitQuota() bool {
+	repo := ctx.Repo.Repository
+
+	growth, err := ctx.pushStorageGrowth()
+	if err != nil {
+		log.Error("Error has occurred while calculating push size for %-v. Error: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: "Unable to check tenant quota",
+		})
+		return false
+	}
+	if growth <= 0 {
+		return true
+	}
+
+	err = tenant_model.CheckTenantQuotaByOrgID(ctx, repo.OwnerID, tenant_model.QuotaGitSize, growth)
+	if err == nil {
+		return true
+	}
+
+	auditParams := map[string]string{
+		"repository":    repo.Name,
+		"repository_id": strconv.FormatInt(repo.ID, 10),
+		"owner":         repo.OwnerName,
+	}
+	if tenant_model.IsErrTenantQuotaExceeded(err) {
+		log.Warn("Forbidden: push to %-v exceeds tenant quota: %v", repo, err)
+		auditParams["error"] = "Tenant git size quota exceeded"
+		audit.CreateAndSendEvent(audit.ChangesPushEvent, ctx.opts.UserName, strconv.FormatInt(ctx.opts.UserID, 10), audit.StatusFailure, ctx.PrivateContext.Req.RemoteAddr, auditParams)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: "Tenant git size quota exceeded",
+		})
+		return false
+	}
+
+	log.Error("Error has occurred while checking tenant quota for %-v. Error: %v", repo, err)
+	auditParams["error"] = "Error has occurred while checking tenant quota"
+	audit.CreateAndSendEvent(audit.ChangesPushEvent, ctx.opts.UserName, strconv.FormatInt(ctx.opts.UserID, 10), audit.StatusFailure, ctx.PrivateContext.Req.RemoteAddr, auditParams)
+	ctx.JSON(http.StatusInternalServerError, private.Response{
+		Err: "Unable to check tenant quota",
+	})
+	return false
+}
+
+// pushStorageGrowth оценивает изменение размера репозитория после push как разницу между размером новых объектов,
+// недостижимых из существующих ссылок, и размером объектов, которые перестанут быть достижимы после обновления ссылок.
+// Для версий git без поддержки --disk-usage используется размер каталога карантина
+func (ctx *preReceiveContext) pushStorageGrowth() (int64, error) {
+	var newCommitIDs, oldCommitIDs, updatedRefs []string
+	for i := range ctx.opts.OldCommitIDs {
+		if ctx.opts.NewCommitIDs[i] != git.EmptySHA {
+			newCommitIDs = append(newCommitIDs, ctx.opts.NewCommitIDs[i])
+		}
+		if ctx.opts.OldCommitIDs[i] != git.EmptySHA {
+			oldCommitIDs = append(oldCommitIDs, ctx.opts.OldCommitIDs[i])
+			updatedRefs = append(updatedRefs, ctx.opts.RefFullNames[i])
+		}
+	}
+	if len(newCommitIDs) == 0 {
+		return 0, nil
+	}
+
+	if err := git.CheckGitVersionAtLeast("2.31"); err != nil {
+		if ctx.opts.GitQuarantinePath == "" {
+			return 0, nil
+		}
+		return repo_module.GetDirectorySize(ctx.opts.GitQuarantinePath)
+	}
+
+	added, err := ctx.revListDiskUsage(git.NewCommand(ctx, "rev-list", "--objects", "--disk-usage").
+		AddDynamicArguments(newCommitIDs...).
+		AddArguments("--not", "--glob=refs/*"))
+	if err != nil {
+		return 0, err
+	}
+	if len(oldCommitIDs) == 0 {
+		return added, nil
+	}
+
+	cmd := git.NewCommand(ctx, "rev-list", "--objects", "--disk-usage").
+		AddDynamicArguments(oldCommitIDs...).
+		AddArguments("--not").
+		AddDynamicArguments(newCommitIDs...)
+	for _, ref := range updatedRefs {
+		cmd.AddOptionFormat("--exclude=%s", ref)
+	}
+	removed, err := ctx.revListDiskUsage(cmd.AddArguments("--glob=refs/*"))
+	if err != nil {
+		return 0, err
+	}
+	return added - removed, nil
+}
+
+// revListDiskUsage выполняет git rev-list --disk-usage в окружении карантина push и возвращает размер объектов
+func (ctx *preReceiveContext) revListDiskUsage(cmd *git.Command) (int64, error) {
+	stdout, _, err := cmd.RunStdString(&git.RunOpts{Dir: ctx.Repo.Repository.RepoPath(), Env: ctx.env})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+}
+
 func (s Server) preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullName string) {
 	branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 	ctx.branchName = branchName
@@ -60,7 +60,7 @@ func (ctx *preReceiveContext) CanWriteCode() bool {
 				if err != nil {
 					ctx.canWriteCode = false
 				} else {
-					allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.user, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, role_model.WRITE)
+					allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.user, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, ctx.Repo.Repository, role_model.WRITE)
 					if err != nil {
 						ctx.canCreatePullRequest = false
 					}
@@ -117,7 +117,7 @@ func (ctx *preReceiveContext) CanCreatePullRequest() bool {
 				if err != nil {
 					ctx.canCreatePullRequest = false
 				} else {
-					allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.user, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, role_model.WRITE)
+					allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.user, tenantId, &organization.Organization{ID: ctx.Repo.Repository.OwnerID}, ctx.Repo.Repository, role_model.WRITE)
 					if err != nil {
 						ctx.canCreatePullRequest = false
 					}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"strings"
 
+	"code.gitea.io/gitea/models/background_task"
 	"code.gitea.io/gitea/models/pull/pullrequestidresolver"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit_links_push_task"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
@@ -58,6 +61,39 @@ func (s Server) linkUnits(ctx *gitea_context.PrivateContext, opts *private.HookO
 	return nil
 }
 
+// linkRefs ставит в очередь привязку к юнитам созданных веток и отправленных коммитов и отвязку удаленных веток.
+// Коммиты читаются из репозитория при обработке задачи, чтобы не задерживать ответ post-receive хука
+func (s Server) linkRefs(ctx *gitea_context.PrivateContext, ownerName, repoName string, opts *private.HookOptions) error {
+	repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+	if err != nil {
+		return fmt.Errorf("get repository: %w", err)
+	}
+
+	payload := unit_links_push_task.Payload{
+		RepoID:       repo.ID,
+		UserName:     opts.UserName,
+		OldCommitIDs: make([]string, 0, len(opts.RefFullNames)),
+		NewCommitIDs: make([]string, 0, len(opts.RefFullNames)),
+		RefFullNames: make([]string, 0, len(opts.RefFullNames)),
+	}
+	for i := range opts.OldCommitIDs {
+		if !strings.HasPrefix(opts.RefFullNames[i], git.BranchPrefix) {
+			continue
+		}
+		payload.OldCommitIDs = append(payload.OldCommitIDs, opts.OldCommitIDs[i])
+		payload.NewCommitIDs = append(payload.NewCommitIDs, opts.NewCommitIDs[i])
+		payload.RefFullNames = append(payload.RefFullNames, opts.RefFullNames[i])
+	}
+	if len(payload.RefFullNames) == 0 {
+		return nil
+	}
+
+	if _, err = unit_links_push_task.Queue.Enqueue(ctx, payload, background_task.EnqueueOptions{}); err != nil {
+		return fmt.Errorf("enqueue push links: %w", err)
+	}
+	return nil
+}
+
 func getBranchName(refs []string) (string, error) {
 	for _, ref := range refs {
 		branchName, err := git.RefEndNameBranchOnly(ref)
@@ -6,7 +6,6 @@ package private
 
 import (
 	goctx "context"
-	"crypto/tls"
 	"net/http"
 	"strings"
 
@@ -20,12 +19,11 @@ import (
 	"code.gitea.io/gitea/models/unit_links/unit_links_db"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/gitnamesparser/branch"
+	"code.gitea.io/gitea/modules/gitnamesparser/commit"
 	"code.gitea.io/gitea/modules/gitnamesparser/pullrequest"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/mtls"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
-	setting_mtls "code.gitea.io/gitea/modules/setting/mtls"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/private/code_hub_counter"
 	"code.gitea.io/gitea/routers/private/hooks"
@@ -81,28 +79,25 @@ func Routes() *web.Route {
 	ctx := goctx.Background()
 	dbEngine := db.GetEngine(ctx)
 	branchParser := branch.NewParser()
+	commitParser := commit.NewParser()
 	pullRequestParser := pullrequest.NewParser()
 	pullRequestIDResolver := pullrequestidresolver.NewResolver(dbEngine)
 	unitLinkDB := unit_links_db.NewUnitLinkDB(dbEngine)
 	pullRequestHeaderDB := pull_request_reader.NewReader(dbEngine)
 	protectedBranchDB := protected_branch_db.NewProtectedBranchDB(dbEngine)
 
-	mtlsConfig := &tls.Config{} // initialize tls config with default values
-	if setting_mtls.CheckMTLSConfigSecManEnabled(task_tracker_client.TaskTrackerClientName) {
-		mtlsCerts := setting_mtls.GetMTLSCertsFromSecMan(task_tracker_client.TaskTrackerClientName, setting.NewGetterForSecMan())
-		mtlsConfig = mtls.GenerateTlsConfigForMTLS(task_tracker_client.TaskTrackerClientName, mtlsCerts.Cert, mtlsCerts.CertKey, mtlsCerts.CaCerts)
+	taskTrackerResolver, err := task_tracker_client.NewResolverFromSettings()
+	if err != nil {
+		log.Fatal("Error has occurred while creating task tracker providers. Error: %v", err)
 	}
-	taskTrackerClient := task_tracker_client.New(
-		setting.TaskTracker.APIBaseURL,
-		setting.TaskTracker.APIToken,
-		&http.Client{Transport: &http.Transport{TLSClientConfig: mtlsConfig}})
 
 	unitLinker := unit_linker.NewUnitLinker(
 		branchParser,
+		commitParser,
 		pullRequestParser,
 		unitLinkDB,
 		pullRequestHeaderDB,
-		taskTrackerClient,
+		taskTrackerResolver,
 		setting.TaskTracker.UnitsValidationEnabled,
 	)
 
@@ -118,7 +113,7 @@ func Routes() *web.Route {
 	casbinCustomManager := custom_casbin_role_manager.NewManager(role_model.GetSecurityEnforcer())
 	requestAccessor := repo_accesser.NewRepoAccessor(casbinCustomManager)
 	server := hooks.NewServer(unitLinker, pullRequestIDResolver, setting.TaskTracker.Enabled, requestAccessor, orgAccesser, protectedBranchManager)
-	usagesDB := code_hub_counter_task_db.New(dbEngine)
+	usagesDB := code_hub_counter_task_db.New()
 	taskCreator := code_hub_counter.NewTaskCreator(usagesDB, setting.CodeHub.CodeHubMetricEnabled)
 	commandServer := NewServer(taskCreator)
 
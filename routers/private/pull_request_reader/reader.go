@@ -18,6 +18,7 @@ const (
 )
 
 type PullRequestHeader struct {
+	RepoID          int64
 	PullRequestURL  string
 	PullRequestName string
 	BranchName      string
@@ -72,6 +73,7 @@ func (p PullRequestReader) ReadByID(
 	)
 
 	prHeader := PullRequestHeader{
+		RepoID:          repoID,
 		CommitNames:     commits,
 		PullRequestURL:  url,
 		PullRequestName: pullRequest.Issue.Title,
@@ -363,7 +363,7 @@ func (s Server) ServCommand(ctx *context.PrivateContext) {
 				return
 			}
 
-			allowed, err := role_model.CheckUserPermissionToOrganization(ctx, user, tenantId, &organization.Organization{ID: owner.ID}, action)
+			allowed, err := role_model.CheckUserPermissionToRepository(ctx, user, tenantId, &organization.Organization{ID: owner.ID}, repo, action)
 			if err != nil {
 				ctx.JSON(http.StatusForbidden, private.Response{
 					UserMsg: "The repository you are trying to reach either does not exist or you are not authorized to view it.",
@@ -386,6 +386,25 @@ func (s Server) ServCommand(ctx *context.PrivateContext) {
 		}
 	}
 
+	// Don't allow any git access to repositories of deactivated tenant
+	if repoExist && setting.SourceControl.Enabled && owner.IsOrganization() {
+		if err := tenant.CheckTenantIsActiveByOrgID(ctx, owner.ID); err != nil {
+			if tenant.IsTenantNotActive(err) {
+				ctx.JSON(http.StatusForbidden, private.Response{
+					UserMsg: fmt.Sprintf("Repo: %s/%s belongs to deactivated tenant.", results.OwnerName, results.RepoName),
+				})
+				auditParams["error"] = "Tenant is not active"
+				audit.CreateAndSendEvent(audit.TenantAccessDeniedEvent, results.UserName, strconv.FormatInt(results.UserID, 10), audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+				return
+			}
+			log.Error("Unable to check tenant of repository %s/%s. Error: %v", results.OwnerName, results.RepoName, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to check tenant of repository %s/%s.", results.OwnerName, results.RepoName),
+			})
+			return
+		}
+	}
+
 	// Don't allow pushing if the repo is archived
 	if repoExist && mode > perm.AccessModeRead && repo.IsArchived {
 		ctx.JSON(http.StatusUnauthorized, private.Response{
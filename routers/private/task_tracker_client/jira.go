@@ -0,0 +1,326 @@
+package task_tracker_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/models/pull_request_sender"
+	"code.gitea.io/gitea/models/unit_links"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// jiraApplicationType тип приложения удаленных ссылок, созданных SourceControl
+const jiraApplicationType = "sourcecontrol"
+
+// JiraClient клиент для работы с Jira. Юниты - задачи Jira, привязка пулл реквеста - удаленная ссылка задачи
+type JiraClient struct {
+	httpClient *http.Client
+	baseURL    string
+	user       string
+	token      string
+}
+
+// NewJiraClient создает клиент Jira. Если user не пустой, используется basic авторизация (Jira Cloud),
+// иначе токен передается как Bearer (персональный токен Jira Server/Data Center)
+func NewJiraClient(baseURL, user, token string, httpClient *http.Client) JiraClient {
+	return JiraClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		user:       user,
+		token:      token,
+	}
+}
+
+type jiraSearchRequest struct {
+	JQL           string   `json:"jql"`
+	Fields        []string `json:"fields"`
+	MaxResults    int      `json:"maxResults"`
+	ValidateQuery string   `json:"validateQuery"`
+}
+
+type jiraNamedField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string          `json:"summary"`
+		Description interface{}     `json:"description"`
+		Status      *jiraNamedField `json:"status"`
+		Priority    *jiraNamedField `json:"priority"`
+	} `json:"fields"`
+}
+
+type jiraSearchResponse struct {
+	Issues []jiraIssue `json:"issues"`
+}
+
+type jiraRemoteLinkStatus struct {
+	Resolved bool `json:"resolved"`
+}
+
+type jiraRemoteLinkObject struct {
+	URL    string               `json:"url"`
+	Title  string               `json:"title"`
+	Status jiraRemoteLinkStatus `json:"status"`
+}
+
+type jiraRemoteLinkApplication struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type jiraRemoteLink struct {
+	GlobalID     string                    `json:"globalId"`
+	Application  jiraRemoteLinkApplication `json:"application"`
+	Relationship string                    `json:"relationship"`
+	Object       jiraRemoteLinkObject      `json:"object"`
+}
+
+func (c JiraClient) authorize(request *http.Request) {
+	if c.user != "" {
+		request.SetBasicAuth(c.user, c.token)
+		return
+	}
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.token))
+}
+
+// search ищет задачи по ключам, несуществующие ключи игнорируются
+func (c JiraClient) search(ctx context.Context, codes []gitnames.UnitCode) ([]jiraIssue, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(codes))
+	for _, code := range codes {
+		keys = append(keys, fmt.Sprintf("%q", code.Code))
+	}
+
+	request := jiraSearchRequest{
+		JQL:           fmt.Sprintf("key in (%s)", strings.Join(keys, ",")),
+		Fields:        []string{"summary", "description", "status", "priority"},
+		MaxResults:    len(keys),
+		ValidateQuery: "warn",
+	}
+	var response jiraSearchResponse
+	if err := doJSONRequest(ctx, c.httpClient, c.authorize, http.MethodPost, c.baseURL+"/rest/api/2/search", request, &response); err != nil {
+		return nil, fmt.Errorf("search issues: %w", err)
+	}
+	return response.Issues, nil
+}
+
+// CheckCodes проверяет наличие задач в Jira
+func (c JiraClient) CheckCodes(ctx context.Context, codes []gitnames.UnitCode) (CheckCodesResponse, error) {
+	issues, err := c.search(ctx, codes)
+	if err != nil {
+		return CheckCodesResponse{}, err
+	}
+
+	found := make(map[string]struct{}, len(issues))
+	for _, issue := range issues {
+		found[issue.Key] = struct{}{}
+	}
+
+	var response CheckCodesResponse
+	for _, code := range codes {
+		_, exists := found[code.Code]
+		response.Units = append(response.Units, Unit{Code: code.Code, IsExists: exists})
+	}
+	return response, nil
+}
+
+// GetDescriptions получает название, статус и приоритет задач Jira
+func (c JiraClient) GetDescriptions(ctx context.Context, codes []gitnames.UnitCode) (GetDescriptionsResponse, error) {
+	issues, err := c.search(ctx, codes)
+	if err != nil {
+		return GetDescriptionsResponse{}, err
+	}
+
+	var response GetDescriptionsResponse
+	for _, issue := range issues {
+		content := GetDescriptionsContent{
+			Unit: GetDescriptionsUnit{
+				Code:        issue.Key,
+				Summary:     issue.Fields.Summary,
+				Description: issue.Fields.Description,
+			},
+		}
+		if issue.Fields.Priority != nil {
+			content.AttributesAndValues = append(content.AttributesAndValues, newAttributeAndValue(PriorityAttributeName, issue.Fields.Priority.ID, issue.Fields.Priority.Name))
+		}
+		if issue.Fields.Status != nil {
+			content.AttributesAndValues = append(content.AttributesAndValues, newAttributeAndValue(StatusAttributeName, issue.Fields.Status.ID, issue.Fields.Status.Name))
+		}
+		response.Content = append(response.Content, content)
+	}
+	return response, nil
+}
+
+// SendAddPullRequestLinks добавляет ссылку на пулл реквест в задачи Jira
+func (c JiraClient) SendAddPullRequestLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	pullRequestID int64,
+	pullRequestURL string,
+) error {
+	if len(unitLinks) == 0 {
+		return nil
+	}
+
+	link := newJiraRemoteLink(pullRequestID, pullRequestURL, unitLinks[0].FromUnitStatus)
+	for _, code := range payloadUnitCodes(unitLinks) {
+		if err := c.saveRemoteLink(ctx, code, link); err != nil {
+			return fmt.Errorf("add remote link to %s: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// SendDeletePullRequestLinks удаляет ссылку на пулл реквест из задач Jira
+func (c JiraClient) SendDeletePullRequestLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	pullRequestID int64,
+) error {
+	return c.deleteRemoteLinks(ctx, payloadUnitCodes(unitLinks), jiraRemoteLinkGlobalID(pullRequestID))
+}
+
+// SendUpdatePullRequestStatus изменяет статус ссылки на пулл реквест в задачах Jira
+func (c JiraClient) SendUpdatePullRequestStatus(
+	ctx context.Context,
+	payloads unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	pullRequestID int64,
+) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	globalID := jiraRemoteLinkGlobalID(pullRequestID)
+	for _, code := range payloadUnitCodes(payloads) {
+		var link jiraRemoteLink
+		methodPath := fmt.Sprintf("%s/rest/api/2/issue/%s/remotelink?globalId=%s", c.baseURL, url.PathEscape(code), url.QueryEscape(globalID))
+		if err := doJSONRequest(ctx, c.httpClient, c.authorize, http.MethodGet, methodPath, nil, &link); err != nil {
+			return fmt.Errorf("get remote link of %s: %w", code, err)
+		}
+
+		link.Object.Status.Resolved = isPullRequestResolved(payloads[0].FromUnitStatus)
+		if err := c.saveRemoteLink(ctx, code, link); err != nil {
+			return fmt.Errorf("update remote link of %s: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// SupportsRefLinks Jira поддерживает привязку задач к коммитам и веткам через удаленные ссылки
+func (c JiraClient) SupportsRefLinks() bool {
+	return true
+}
+
+// SendAddRefLinks добавляет ссылку на коммит или ветку в задачи Jira
+func (c JiraClient) SendAddRefLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	ref *unit_links.UnitLinksRef,
+) error {
+	link := newJiraRefRemoteLink(ref)
+	for _, code := range payloadUnitCodes(unitLinks) {
+		if err := c.saveRemoteLink(ctx, code, link); err != nil {
+			return fmt.Errorf("add remote link to %s: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// SendDeleteRefLinks удаляет ссылку на коммит или ветку из задач Jira
+func (c JiraClient) SendDeleteRefLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	ref *unit_links.UnitLinksRef,
+) error {
+	return c.deleteRemoteLinks(ctx, payloadUnitCodes(unitLinks), jiraRefRemoteLinkGlobalID(ref))
+}
+
+// saveRemoteLink создает удаленную ссылку задачи, ссылка с тем же globalId заменяется
+func (c JiraClient) saveRemoteLink(ctx context.Context, code string, link jiraRemoteLink) error {
+	methodPath := fmt.Sprintf("%s/rest/api/2/issue/%s/remotelink", c.baseURL, url.PathEscape(code))
+	return doJSONRequest(ctx, c.httpClient, c.authorize, http.MethodPost, methodPath, link, nil)
+}
+
+// deleteRemoteLinks удаляет удаленную ссылку из задач, уже удаленные ссылки пропускаются
+func (c JiraClient) deleteRemoteLinks(ctx context.Context, codes []string, globalID string) error {
+	for _, code := range codes {
+		methodPath := fmt.Sprintf("%s/rest/api/2/issue/%s/remotelink?globalId=%s", c.baseURL, url.PathEscape(code), url.QueryEscape(globalID))
+		err := doJSONRequest(ctx, c.httpClient, c.authorize, http.MethodDelete, methodPath, nil, nil)
+		if statusErr := new(unexpectedStatusError); errors.As(err, statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			log.Debug("task_tracker_client: remote link %s of %s is already deleted", globalID, code)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("delete remote link from %s: %w", code, err)
+		}
+	}
+	return nil
+}
+
+func newJiraRemoteLink(pullRequestID int64, pullRequestURL string, status pull_request_sender.FromUnitStatusPr) jiraRemoteLink {
+	return jiraRemoteLink{
+		GlobalID:     jiraRemoteLinkGlobalID(pullRequestID),
+		Application:  jiraRemoteLinkApplication{Type: jiraApplicationType, Name: setting.AppName},
+		Relationship: "pull request",
+		Object: jiraRemoteLinkObject{
+			URL:    setting.AppURL + strings.TrimPrefix(pullRequestURL, "/"),
+			Title:  fmt.Sprintf("Pull request %s", pullRequestURL),
+			Status: jiraRemoteLinkStatus{Resolved: isPullRequestResolved(status)},
+		},
+	}
+}
+
+// jiraRemoteLinkGlobalID идентификатор удаленной ссылки пулл реквеста, по нему ссылка изменяется и удаляется
+func jiraRemoteLinkGlobalID(pullRequestID int64) string {
+	return fmt.Sprintf("%s:pull_request:%d", jiraApplicationType, pullRequestID)
+}
+
+func newJiraRefRemoteLink(ref *unit_links.UnitLinksRef) jiraRemoteLink {
+	title := fmt.Sprintf("Branch %s", ref.Ref)
+	if ref.RefType == unit_links.CommitFromUnitType {
+		title = fmt.Sprintf("Commit %s: %s", base.ShortSha(ref.Ref), ref.Title)
+	}
+	return jiraRemoteLink{
+		GlobalID:     jiraRefRemoteLinkGlobalID(ref),
+		Application:  jiraRemoteLinkApplication{Type: jiraApplicationType, Name: setting.AppName},
+		Relationship: ref.RefType.String(),
+		Object: jiraRemoteLinkObject{
+			URL:   setting.AppURL + strings.TrimPrefix(ref.URL, "/"),
+			Title: title,
+		},
+	}
+}
+
+// jiraRefRemoteLinkGlobalID идентификатор удаленной ссылки коммита или ветки
+func jiraRefRemoteLinkGlobalID(ref *unit_links.UnitLinksRef) string {
+	return fmt.Sprintf("%s:%s:%d", jiraApplicationType, ref.RefType, ref.ID)
+}
+
+func isPullRequestResolved(status pull_request_sender.FromUnitStatusPr) bool {
+	return status == pull_request_sender.PRStatusMerged || status == pull_request_sender.PRStatusClosed
+}
+
+func newAttributeAndValue(attribute, code, name string) GetDescriptionsAttributeAndValue {
+	return GetDescriptionsAttributeAndValue{
+		Attribute: GetDescriptionsAttribute{Code: attribute, Name: attribute},
+		Value:     GetDescriptionsValue{Code: code, Name: name},
+	}
+}
@@ -0,0 +1,50 @@
+package task_tracker_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/models/unit_links"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Provider трекер задач, юниты которого привязываются к пулл реквестам
+type Provider interface {
+	// CheckCodes проверяет наличие юнитов в трекере
+	CheckCodes(ctx context.Context, codes []gitnames.UnitCode) (CheckCodesResponse, error)
+	// GetDescriptions получает название, статус и приоритет юнитов
+	GetDescriptions(ctx context.Context, codes []gitnames.UnitCode) (GetDescriptionsResponse, error)
+	// SendAddPullRequestLinks отправляет в трекер привязку юнитов к пулл реквесту
+	SendAddPullRequestLinks(ctx context.Context, unitLinks unit_links.AllPayloadToAddOrDeletePr, userName string, pullRequestID int64, pullRequestURL string) error
+	// SendDeletePullRequestLinks отправляет в трекер отвязку юнитов от пулл реквеста
+	SendDeletePullRequestLinks(ctx context.Context, unitLinks unit_links.AllPayloadToAddOrDeletePr, userName string, pullRequestID int64) error
+	// SendUpdatePullRequestStatus отправляет в трекер статус пулл реквеста
+	SendUpdatePullRequestStatus(ctx context.Context, payloads unit_links.AllPayloadToAddOrDeletePr, userName string, pullRequestID int64) error
+	// SupportsRefLinks сообщает, поддерживает ли трекер привязку юнитов к коммитам и веткам.
+	// Если не поддерживает, коммиты и ветки к юнитам трекера не привязываются
+	SupportsRefLinks() bool
+	// SendAddRefLinks отправляет в трекер привязку юнитов к коммиту или ветке
+	SendAddRefLinks(ctx context.Context, unitLinks unit_links.AllPayloadToAddOrDeletePr, userName string, ref *unit_links.UnitLinksRef) error
+	// SendDeleteRefLinks отправляет в трекер отвязку юнитов от коммита или ветки
+	SendDeleteRefLinks(ctx context.Context, unitLinks unit_links.AllPayloadToAddOrDeletePr, userName string, ref *unit_links.UnitLinksRef) error
+}
+
+// ErrRefLinksNotSupported трекер задач не поддерживает привязку юнитов к коммитам и веткам
+var ErrRefLinksNotSupported = errors.New("task tracker does not support commit and branch links")
+
+// NewProvider создает клиент трекера задач по его настройкам
+func NewProvider(cfg setting.TaskTrackerProvider, httpClient *http.Client) (Provider, error) {
+	switch cfg.Type {
+	case setting.TaskTrackerProviderTypeTaskTracker:
+		return New(cfg.APIBaseURL, cfg.APIToken, httpClient), nil
+	case setting.TaskTrackerProviderTypeJira:
+		return NewJiraClient(cfg.APIBaseURL, cfg.User, cfg.APIToken, httpClient), nil
+	case setting.TaskTrackerProviderTypeREST:
+		return NewRESTClient(cfg.APIBaseURL, cfg.APIToken, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unknown task tracker provider type: %s", cfg.Type)
+	}
+}
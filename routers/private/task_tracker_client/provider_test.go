@@ -0,0 +1,136 @@
+//go:build !correct
+
+package task_tracker_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/models/pull_request_sender"
+	"code.gitea.io/gitea/models/unit_links"
+)
+
+func TestJiraClient_CheckCodes(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "bot" || password != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "/rest/api/2/search", r.URL.Path)
+
+		var request jiraSearchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, `key in ("PROJ-1","PROJ-2")`, request.JQL)
+
+		w.Write([]byte(`{"issues":[{"key":"PROJ-1","fields":{"summary":"First","status":{"id":"1","name":"Open"}}}]}`))
+	}))
+	defer testServer.Close()
+
+	client := NewJiraClient(testServer.URL, "bot", testToken, testServer.Client())
+
+	got, err := client.CheckCodes(context.Background(), []gitnames.UnitCode{{Code: "PROJ-1"}, {Code: "PROJ-2"}})
+	require.NoError(t, err)
+	require.Equal(t, CheckCodesResponse{Units: []Unit{{Code: "PROJ-1", IsExists: true}, {Code: "PROJ-2", IsExists: false}}}, got)
+}
+
+func TestJiraClient_SendDeletePullRequestLinks_notFound(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "sourcecontrol:pull_request:7", r.URL.Query().Get("globalId"))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	client := NewJiraClient(testServer.URL, "", testToken, testServer.Client())
+
+	err := client.SendDeletePullRequestLinks(context.Background(), unit_links.AllPayloadToAddOrDeletePr{{ToUnitID: "PROJ-1"}}, "user", 7)
+	require.NoError(t, err)
+}
+
+func TestRESTClient_SendAddPullRequestLinks(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "/pull_requests/7/links", r.URL.Path)
+
+		var request RESTPullRequestLinksRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, RESTPullRequestLinksRequest{
+			UnitCodes: []string{"TASK-1"},
+			URL:       "/org/repo/pulls/1",
+			Status:    pull_request_sender.PRStatusOpen,
+			UserLogin: "user",
+		}, request)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := NewRESTClient(testServer.URL, testToken, testServer.Client())
+	payloads := unit_links.AllPayloadToAddOrDeletePr{{ToUnitID: "TASK-1", FromUnitStatus: pull_request_sender.PRStatusOpen}}
+
+	err := client.SendAddPullRequestLinks(context.Background(), payloads, "user", 7, "/org/repo/pulls/1")
+	require.NoError(t, err)
+}
+
+func TestJiraClient_SendAddRefLinks(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/rest/api/2/issue/PROJ-1/remotelink", r.URL.Path)
+
+		var link jiraRemoteLink
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&link))
+		assert.Equal(t, "sourcecontrol:commit:3", link.GlobalID)
+		assert.Equal(t, "commit", link.Relationship)
+		assert.Equal(t, "Commit 0123456789: PROJ-1 Fix", link.Object.Title)
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer testServer.Close()
+
+	client := NewJiraClient(testServer.URL, "", testToken, testServer.Client())
+	ref := &unit_links.UnitLinksRef{
+		ID:      3,
+		RefType: unit_links.CommitFromUnitType,
+		Ref:     "0123456789abcdef",
+		Title:   "PROJ-1 Fix",
+		URL:     "/org/repo/commit/0123456789abcdef",
+	}
+
+	err := client.SendAddRefLinks(context.Background(), unit_links.AllPayloadToAddOrDeletePr{{ToUnitID: "PROJ-1"}}, "user", ref)
+	require.NoError(t, err)
+}
+
+func TestRESTClient_SendDeleteRefLinks(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/refs/5/links/delete", r.URL.Path)
+
+		var request RESTRefLinksRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, RESTRefLinksRequest{
+			UnitCodes: []string{"TASK-1"},
+			Type:      "branch",
+			Ref:       "feature/TASK-1",
+			UserLogin: "user",
+		}, request)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := NewRESTClient(testServer.URL, testToken, testServer.Client())
+	ref := &unit_links.UnitLinksRef{ID: 5, RefType: unit_links.BranchFromUnitType, Ref: "feature/TASK-1"}
+
+	err := client.SendDeleteRefLinks(context.Background(), unit_links.AllPayloadToAddOrDeletePr{{ToUnitID: "TASK-1"}}, "user", ref)
+	require.NoError(t, err)
+}
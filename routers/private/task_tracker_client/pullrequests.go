@@ -118,6 +118,32 @@ func (c TaskTrackerClient) SendUpdatePullRequestStatus(
 	return nil
 }
 
+// SupportsRefLinks TaskTracker не поддерживает привязку юнитов к коммитам и веткам, в его API нет такого метода.
+// Коммиты и ветки репозиториев с этим трекером к юнитам не привязываются
+func (c TaskTrackerClient) SupportsRefLinks() bool {
+	return false
+}
+
+// SendAddRefLinks TaskTracker не поддерживает привязку юнитов к коммитам и веткам
+func (c TaskTrackerClient) SendAddRefLinks(
+	_ context.Context,
+	_ unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	_ *unit_links.UnitLinksRef,
+) error {
+	return ErrRefLinksNotSupported
+}
+
+// SendDeleteRefLinks TaskTracker не поддерживает привязку юнитов к коммитам и веткам
+func (c TaskTrackerClient) SendDeleteRefLinks(
+	_ context.Context,
+	_ unit_links.AllPayloadToAddOrDeletePr,
+	_ string,
+	_ *unit_links.UnitLinksRef,
+) error {
+	return ErrRefLinksNotSupported
+}
+
 func (c TaskTrackerClient) doNewRequest(ctx context.Context, requestBody []byte, method, methodPath string) error {
 	request, err := http.NewRequestWithContext(ctx, method, methodPath, bytes.NewReader(requestBody))
 	if err != nil {
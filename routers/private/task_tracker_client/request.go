@@ -0,0 +1,96 @@
+package task_tracker_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"code.gitea.io/gitea/models/unit_links"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// unexpectedStatusError ответ трекера задач с неожиданным статусом
+type unexpectedStatusError struct {
+	StatusCode int
+}
+
+func (e unexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// doJSONRequest выполняет запрос к трекеру задач с телом requestModel в json и разбирает ответ в responseModel.
+// Запрос без тела выполняется, если requestModel равен nil, ответ не разбирается, если responseModel равен nil
+func doJSONRequest(
+	ctx context.Context,
+	httpClient *http.Client,
+	authorize func(*http.Request),
+	method, methodPath string,
+	requestModel, responseModel any,
+) error {
+	var body io.Reader
+	if requestModel != nil {
+		requestBody, err := json.Marshal(requestModel)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		log.Debug("task_tracker_client: %s %s request body: %s", method, methodPath, string(requestBody))
+		body = bytes.NewReader(requestBody)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, methodPath, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	authorize(request)
+	request.Header.Add("Accept", "application/json")
+	if body != nil {
+		request.Header.Add("Content-Type", "application/json")
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			log.Error("close response body: %v", err)
+		}
+	}()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return unexpectedStatusError{StatusCode: response.StatusCode}
+	}
+	if responseModel == nil {
+		return nil
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if err = json.Unmarshal(responseBody, responseModel); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	log.Debug("task_tracker_client: %s %s response body: %s", method, methodPath, string(responseBody))
+
+	return nil
+}
+
+// bearerAuthorization добавляет в запрос токен
+func bearerAuthorization(token string) func(*http.Request) {
+	return func(request *http.Request) {
+		request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+}
+
+// payloadUnitCodes возвращает коды юнитов из привязок
+func payloadUnitCodes(unitLinks unit_links.AllPayloadToAddOrDeletePr) []string {
+	codes := make([]string, 0, len(unitLinks))
+	for _, unitLink := range unitLinks {
+		codes = append(codes, unitLink.ToUnitID)
+	}
+	return codes
+}
@@ -0,0 +1,109 @@
+package task_tracker_client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/gitnamesparser"
+	"code.gitea.io/gitea/modules/mtls"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	setting_mtls "code.gitea.io/gitea/modules/setting/mtls"
+	task_tracker_service "code.gitea.io/gitea/services/task_tracker"
+)
+
+// Tracker трекер задач, выбранный для репозитория
+type Tracker struct {
+	Name        string
+	Provider    Provider
+	UnitBaseURL string
+	// UnitCodePatterns шаблоны кодов юнитов, пустые если используются шаблоны по умолчанию
+	UnitCodePatterns gitnamesparser.Patterns
+}
+
+// Resolver выбирает трекер задач по настройке репозитория или его тенанта
+type Resolver struct {
+	configs   map[string]setting.TaskTrackerProvider
+	providers map[string]Provider
+}
+
+// NewResolver создает выбор трекера задач. httpClient используется для внутреннего TaskTracker,
+// для остальных трекеров создается клиент с настройками прокси
+func NewResolver(configs map[string]setting.TaskTrackerProvider, httpClient *http.Client) (Resolver, error) {
+	resolver := Resolver{
+		configs:   configs,
+		providers: make(map[string]Provider, len(configs)),
+	}
+	externalClient := &http.Client{Transport: &http.Transport{Proxy: proxy.Proxy()}}
+	for name, cfg := range configs {
+		client := externalClient
+		if cfg.Type == setting.TaskTrackerProviderTypeTaskTracker {
+			client = httpClient
+		}
+		provider, err := NewProvider(cfg, client)
+		if err != nil {
+			return Resolver{}, fmt.Errorf("create task tracker provider %s: %w", name, err)
+		}
+		resolver.providers[name] = provider
+	}
+	return resolver, nil
+}
+
+// NewResolverFromSettings создает выбор трекера задач по настройкам [sourcecontrol.tasktracker],
+// внутренний TaskTracker вызывается с mtls, если он настроен
+func NewResolverFromSettings() (Resolver, error) {
+	mtlsConfig := &tls.Config{} // initialize tls config with default values
+	if setting_mtls.CheckMTLSConfigSecManEnabled(TaskTrackerClientName) {
+		mtlsCerts := setting_mtls.GetMTLSCertsFromSecMan(TaskTrackerClientName, setting.NewGetterForSecMan())
+		mtlsConfig = mtls.GenerateTlsConfigForMTLS(TaskTrackerClientName, mtlsCerts.Cert, mtlsCerts.CertKey, mtlsCerts.CaCerts)
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: mtlsConfig}}
+
+	return NewResolver(setting.TaskTracker.Providers, httpClient)
+}
+
+// Get возвращает трекер задач по имени
+func (r Resolver) Get(name string) (Tracker, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return Tracker{}, fmt.Errorf("task tracker provider %s is not configured", name)
+	}
+	return Tracker{Name: name, Provider: provider, UnitBaseURL: r.configs[name].UnitBaseURL}, nil
+}
+
+// ResolveByRepoID возвращает трекер задач и шаблоны кодов юнитов репозитория по настройкам репозитория и тенанта
+func (r Resolver) ResolveByRepoID(ctx context.Context, repoID int64) (Tracker, error) {
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		return Tracker{}, fmt.Errorf("get repo by id: %w", err)
+	}
+	tenantID, err := tenant.GetTenantByOrgIdOrDefault(ctx, repo.OwnerID)
+	if err != nil {
+		return Tracker{}, fmt.Errorf("get tenant of repo %d: %w", repoID, err)
+	}
+
+	providerName, patterns, err := task_tracker_service.ResolveProvider(ctx, tenantID, repoID)
+	if err != nil {
+		return Tracker{}, err
+	}
+	tracker, err := r.Get(providerName)
+	if err != nil {
+		return Tracker{}, err
+	}
+	tracker.UnitCodePatterns = patterns
+	return tracker, nil
+}
+
+// ResolveByPullRequestID возвращает трекер задач репозитория, в который создан пулл реквест
+func (r Resolver) ResolveByPullRequestID(ctx context.Context, pullRequestID int64) (Tracker, error) {
+	pr, err := issues_model.GetPullRequestByID(ctx, pullRequestID)
+	if err != nil {
+		return Tracker{}, fmt.Errorf("get pull request by id: %w", err)
+	}
+	return r.ResolveByRepoID(ctx, pr.BaseRepoID)
+}
@@ -0,0 +1,249 @@
+package task_tracker_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/models/pull_request_sender"
+	"code.gitea.io/gitea/models/unit_links"
+)
+
+// RESTClient клиент трекера задач с REST API по контракту SourceControl. Контракт позволяет подключить трекер,
+// для которого нет отдельного клиента, через адаптер:
+//
+//	POST  /units/search                    - поиск юнитов по кодам
+//	POST  /pull_requests/{id}/links        - привязка юнитов к пулл реквесту
+//	POST  /pull_requests/{id}/links/delete - отвязка юнитов от пулл реквеста
+//	PATCH /pull_requests/{id}              - изменение статуса пулл реквеста
+//	POST  /refs/{id}/links                 - привязка юнитов к коммиту или ветке
+//	POST  /refs/{id}/links/delete          - отвязка юнитов от коммита или ветки
+type RESTClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// NewRESTClient создает клиент трекера задач с REST API по контракту SourceControl
+func NewRESTClient(baseURL, token string, httpClient *http.Client) RESTClient {
+	return RESTClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		token:      token,
+	}
+}
+
+// RESTSearchUnitsRequest модель запроса поиска юнитов
+type RESTSearchUnitsRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// RESTUnit юнит трекера задач
+type RESTUnit struct {
+	Code        string `json:"code"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Priority    string `json:"priority"`
+}
+
+// RESTSearchUnitsResponse модель ответа поиска юнитов, несуществующие юниты в ответ не включаются
+type RESTSearchUnitsResponse struct {
+	Units []RESTUnit `json:"units"`
+}
+
+// RESTPullRequestLinksRequest модель запроса привязки и отвязки юнитов
+type RESTPullRequestLinksRequest struct {
+	UnitCodes []string                             `json:"unit_codes"`
+	URL       string                               `json:"url,omitempty"`
+	Status    pull_request_sender.FromUnitStatusPr `json:"status,omitempty"`
+	UserLogin string                               `json:"user_login"`
+}
+
+// RESTPullRequestStatusRequest модель запроса изменения статуса пулл реквеста
+type RESTPullRequestStatusRequest struct {
+	UnitCodes []string                             `json:"unit_codes"`
+	Status    pull_request_sender.FromUnitStatusPr `json:"status"`
+	UserLogin string                               `json:"user_login"`
+}
+
+// RESTRefLinksRequest модель запроса привязки и отвязки юнитов коммита или ветки
+type RESTRefLinksRequest struct {
+	UnitCodes []string `json:"unit_codes"`
+	Type      string   `json:"type"`
+	Ref       string   `json:"ref"`
+	Title     string   `json:"title,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	UserLogin string   `json:"user_login"`
+}
+
+func (c RESTClient) search(ctx context.Context, codes []gitnames.UnitCode) ([]RESTUnit, error) {
+	request := RESTSearchUnitsRequest{Codes: make([]string, 0, len(codes))}
+	for _, code := range codes {
+		request.Codes = append(request.Codes, code.Code)
+	}
+
+	var response RESTSearchUnitsResponse
+	if err := doJSONRequest(ctx, c.httpClient, bearerAuthorization(c.token), http.MethodPost, c.baseURL+"/units/search", request, &response); err != nil {
+		return nil, fmt.Errorf("search units: %w", err)
+	}
+	return response.Units, nil
+}
+
+// CheckCodes проверяет наличие юнитов в трекере
+func (c RESTClient) CheckCodes(ctx context.Context, codes []gitnames.UnitCode) (CheckCodesResponse, error) {
+	units, err := c.search(ctx, codes)
+	if err != nil {
+		return CheckCodesResponse{}, err
+	}
+
+	found := make(map[string]struct{}, len(units))
+	for _, unit := range units {
+		found[unit.Code] = struct{}{}
+	}
+
+	var response CheckCodesResponse
+	for _, code := range codes {
+		_, exists := found[code.Code]
+		response.Units = append(response.Units, Unit{Code: code.Code, IsExists: exists})
+	}
+	return response, nil
+}
+
+// GetDescriptions получает название, статус и приоритет юнитов
+func (c RESTClient) GetDescriptions(ctx context.Context, codes []gitnames.UnitCode) (GetDescriptionsResponse, error) {
+	units, err := c.search(ctx, codes)
+	if err != nil {
+		return GetDescriptionsResponse{}, err
+	}
+
+	var response GetDescriptionsResponse
+	for _, unit := range units {
+		response.Content = append(response.Content, GetDescriptionsContent{
+			Unit: GetDescriptionsUnit{Code: unit.Code, Summary: unit.Summary, Description: unit.Description},
+			AttributesAndValues: []GetDescriptionsAttributeAndValue{
+				newAttributeAndValue(PriorityAttributeName, unit.Priority, unit.Priority),
+				newAttributeAndValue(StatusAttributeName, unit.Status, unit.Status),
+			},
+		})
+	}
+	return response, nil
+}
+
+// SendAddPullRequestLinks отправляет в трекер привязку юнитов к пулл реквесту
+func (c RESTClient) SendAddPullRequestLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	userName string,
+	pullRequestID int64,
+	pullRequestURL string,
+) error {
+	if len(unitLinks) == 0 {
+		return nil
+	}
+
+	request := RESTPullRequestLinksRequest{
+		UnitCodes: payloadUnitCodes(unitLinks),
+		URL:       pullRequestURL,
+		Status:    unitLinks[0].FromUnitStatus,
+		UserLogin: userName,
+	}
+	methodPath := fmt.Sprintf("%s/pull_requests/%d/links", c.baseURL, pullRequestID)
+	if err := doJSONRequest(ctx, c.httpClient, bearerAuthorization(c.token), http.MethodPost, methodPath, request, nil); err != nil {
+		return fmt.Errorf("run add request: %w", err)
+	}
+	return nil
+}
+
+// SendDeletePullRequestLinks отправляет в трекер отвязку юнитов от пулл реквеста
+func (c RESTClient) SendDeletePullRequestLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	userName string,
+	pullRequestID int64,
+) error {
+	request := RESTPullRequestLinksRequest{
+		UnitCodes: payloadUnitCodes(unitLinks),
+		UserLogin: userName,
+	}
+	methodPath := fmt.Sprintf("%s/pull_requests/%d/links/delete", c.baseURL, pullRequestID)
+	if err := doJSONRequest(ctx, c.httpClient, bearerAuthorization(c.token), http.MethodPost, methodPath, request, nil); err != nil {
+		return fmt.Errorf("run delete request: %w", err)
+	}
+	return nil
+}
+
+// SendUpdatePullRequestStatus отправляет в трекер статус пулл реквеста
+func (c RESTClient) SendUpdatePullRequestStatus(
+	ctx context.Context,
+	payloads unit_links.AllPayloadToAddOrDeletePr,
+	userName string,
+	pullRequestID int64,
+) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	request := RESTPullRequestStatusRequest{
+		UnitCodes: payloadUnitCodes(payloads),
+		Status:    payloads[0].FromUnitStatus,
+		UserLogin: userName,
+	}
+	methodPath := fmt.Sprintf("%s/pull_requests/%d", c.baseURL, pullRequestID)
+	if err := doJSONRequest(ctx, c.httpClient, bearerAuthorization(c.token), http.MethodPatch, methodPath, request, nil); err != nil {
+		return fmt.Errorf("run update request: %w", err)
+	}
+	return nil
+}
+
+// SupportsRefLinks контракт SourceControl включает привязку юнитов к коммитам и веткам
+func (c RESTClient) SupportsRefLinks() bool {
+	return true
+}
+
+// SendAddRefLinks отправляет в трекер привязку юнитов к коммиту или ветке
+func (c RESTClient) SendAddRefLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	userName string,
+	ref *unit_links.UnitLinksRef,
+) error {
+	if len(unitLinks) == 0 {
+		return nil
+	}
+
+	request := RESTRefLinksRequest{
+		UnitCodes: payloadUnitCodes(unitLinks),
+		Type:      ref.RefType.String(),
+		Ref:       ref.Ref,
+		Title:     ref.Title,
+		URL:       ref.URL,
+		UserLogin: userName,
+	}
+	methodPath := fmt.Sprintf("%s/refs/%d/links", c.baseURL, ref.ID)
+	if err := doJSONRequest(ctx, c.httpClient, bearerAuthorization(c.token), http.MethodPost, methodPath, request, nil); err != nil {
+		return fmt.Errorf("run add ref request: %w", err)
+	}
+	return nil
+}
+
+// SendDeleteRefLinks отправляет в трекер отвязку юнитов от коммита или ветки
+func (c RESTClient) SendDeleteRefLinks(
+	ctx context.Context,
+	unitLinks unit_links.AllPayloadToAddOrDeletePr,
+	userName string,
+	ref *unit_links.UnitLinksRef,
+) error {
+	request := RESTRefLinksRequest{
+		UnitCodes: payloadUnitCodes(unitLinks),
+		Type:      ref.RefType.String(),
+		Ref:       ref.Ref,
+		UserLogin: userName,
+	}
+	methodPath := fmt.Sprintf("%s/refs/%d/links/delete", c.baseURL, ref.ID)
+	if err := doJSONRequest(ctx, c.httpClient, bearerAuthorization(c.token), http.MethodPost, methodPath, request, nil); err != nil {
+		return fmt.Errorf("run delete ref request: %w", err)
+	}
+	return nil
+}
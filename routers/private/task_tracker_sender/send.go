@@ -1,164 +1,162 @@
 package task_tracker_sender
 
 import (
-	"code.gitea.io/gitea/models/unit_links"
 	"context"
 	"errors"
 	"fmt"
 	"strconv"
 
+	"code.gitea.io/gitea/models/background_task"
+	"code.gitea.io/gitea/models/unit_links"
 	"code.gitea.io/gitea/models/unit_links_sender"
-	"code.gitea.io/gitea/models/unit_links_sender/unit_links_sender_db"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/routers/private/task_tracker_client"
+	background_task_service "code.gitea.io/gitea/services/background_task"
 )
 
-type taskSender interface {
-	SendAddPullRequestLinks(
-		ctx context.Context,
-		unitLinks unit_links.AllPayloadToAddOrDeletePr,
-		userName string,
-		pullRequestID int64,
-		pullRequestURL string,
-	) error
-	SendDeletePullRequestLinks(
-		ctx context.Context,
-		unitLinks unit_links.AllPayloadToAddOrDeletePr,
-		userName string,
-		pullRequestID int64,
-	) error
-	SendUpdatePullRequestStatus(
-		ctx context.Context,
-		payloads unit_links.AllPayloadToAddOrDeletePr,
-		userName string,
-		pullRequestID int64,
-	) error
-}
-
-type taskDB interface {
-	LockTask(ctx context.Context, taskID int64) error
-	UnlockTask(ctx context.Context, taskID int64) error
-	UnlockTaskWithSuccess(ctx context.Context, taskID int64) error
-	GetPullRequestLinksTask(ctx context.Context) ([]unit_links_sender.UnitLinksSenderTasks, error)
+type taskTrackerResolver interface {
+	ResolveByPullRequestID(ctx context.Context, pullRequestID int64) (task_tracker_client.Tracker, error)
+	ResolveByRepoID(ctx context.Context, repoID int64) (task_tracker_client.Tracker, error)
 }
 
 type taskTrackerSender struct {
-	taskSender
-	taskDB
+	taskTrackerResolver
 }
 
-func NewTaskTrackerSender(sender taskSender, taskPuller taskDB) taskTrackerSender {
-	return taskTrackerSender{taskSender: sender, taskDB: taskPuller}
+func NewTaskTrackerSender(resolver taskTrackerResolver) taskTrackerSender {
+	return taskTrackerSender{taskTrackerResolver: resolver}
 }
 
-func (s taskTrackerSender) SendNewPrTasksToTaskTracker(ctx context.Context) error {
-	auditParams := make(map[string]string)
-
-	tasks, err := s.taskDB.GetPullRequestLinksTask(ctx)
-	if err != nil {
-		auditParams["error"] = "Error has occurred while getting pull request links task"
-		audit.CreateAndSendEvent(audit.UnitLinksRequestCreateEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
-		return fmt.Errorf("get tasks: %w", err)
+// Send отправляет в трекер задач изменение связей юнитов из задачи очереди unit_links_sender.Queue
+func (s taskTrackerSender) Send(ctx context.Context, task *background_task.Task, payload unit_links_sender.Payload) error {
+	auditParams := map[string]string{
+		"task_id":               strconv.FormatInt(task.ID, 10),
+		"task_pull_request_id":  strconv.FormatInt(payload.PullRequestID, 10),
+		"task_pull_request_url": payload.PullRequestURL,
 	}
-
-	if tasks == nil || len(tasks) == 0 {
-		return nil
+	if payload.RefID != 0 {
+		auditParams["task_ref_id"] = strconv.FormatInt(payload.RefID, 10)
 	}
 
-	for _, task := range tasks {
-		auditParams["task_id"] = strconv.FormatInt(task.ID, 10)
-		auditParams["task_pull_request_id"] = strconv.FormatInt(task.PullRequestID, 10)
-		auditParams["task_pull_request_url"] = task.PullRequestURL
-
-		if err = s.taskDB.LockTask(ctx, task.ID); err != nil {
-			if handledErr := new(unit_links_sender_db.TaskAlreadyLockedError); errors.As(err, &handledErr) {
-				auditParams["error"] = "Error has occurred while locking task"
-				audit.CreateAndSendEvent(audit.UnitTaskLockEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
-				log.Debug("lock task id: '%d' err: %s", handledErr.Error())
-			}
+	var links unit_links.AllPayloadToAddOrDeletePr
+	if err := json.Unmarshal(payload.Links, &links); err != nil {
+		log.Error("task_tracker_sender: unmarshal task payload: %s, %v", payload.Links, err)
+		auditParams["error"] = "Error has occurred while unmarshalling"
+		audit.CreateAndSendEvent(audit.PullRequestLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+		return background_task_service.Permanent(fmt.Errorf("unmarshal payload: %w", err))
+	}
 
-			continue
+	tracker, ref, err := s.resolveTracker(ctx, payload)
+	if err != nil {
+		log.Error("task_tracker_sender: resolve task tracker of task %d: %v", task.ID, err)
+		auditParams["error"] = "Error has occurred while resolving task tracker"
+		audit.CreateAndSendEvent(audit.PullRequestLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+		return err
+	}
+	auditParams["task_tracker"] = tracker.Name
+
+	switch payload.Action {
+	case unit_links_sender.SendDeletePullRequestLinksAction:
+		if senderErr := tracker.Provider.SendDeletePullRequestLinks(
+			ctx,
+			links,
+			payload.UserName,
+			payload.PullRequestID,
+		); senderErr != nil {
+			auditParams["error"] = "Error has occurred while deleting the link"
+			audit.CreateAndSendEvent(audit.PullRequestLinksDeleteEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+			log.Error("send the delete event: %s, %v", payload.Links, senderErr)
+			return senderErr
 		}
 
-		var links unit_links.AllPayloadToAddOrDeletePr
-
-		if err = json.Unmarshal([]byte(task.Payload), &links); err != nil {
-			log.Error("task_tracker_sender: unmarshal task payload: %s, %v", task.Payload, err)
-			s.handleErr(ctx, task.ID)
-			auditParams["error"] = "Error has occurred while unmarshalling"
+	case unit_links_sender.SendAddPullRequestLinksAction:
+		if senderErr := tracker.Provider.SendAddPullRequestLinks(
+			ctx,
+			links,
+			payload.UserName,
+			payload.PullRequestID,
+			payload.PullRequestURL,
+		); senderErr != nil {
+			auditParams["error"] = "Error has occurred while sending the link"
 			audit.CreateAndSendEvent(audit.PullRequestLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
-
-			continue
+			log.Error("send the add event to task tracker: %s, %v", payload.Links, senderErr)
+			return senderErr
+		}
+	case unit_links_sender.SendUpdatePullRequestStatusAction:
+		if senderErr := tracker.Provider.SendUpdatePullRequestStatus(ctx, links, payload.UserName, payload.PullRequestID); senderErr != nil {
+			auditParams["error"] = "Error has occurred while sending the status of an updating pull request"
+			audit.CreateAndSendEvent(audit.PullRequestsUpdateEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+			log.Error("send the update event to task tracker: %s, %v", payload.Links, senderErr)
+			return senderErr
 		}
 
-		switch task.Action {
-		case unit_links_sender.SendDeletePullRequestLinksAction:
-			if senderErr := s.SendDeletePullRequestLinks(
-				ctx,
-				links,
-				task.UserName,
-				task.PullRequestID,
-			); senderErr != nil {
-				auditParams["error"] = "Error has occurred while deleting the link"
-				audit.CreateAndSendEvent(audit.PullRequestLinksDeleteEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
-				log.Error("send the delete event: %s, %v", task.Payload, senderErr)
-				s.handleErr(ctx, task.ID)
-
-				continue
+	case unit_links_sender.SendAddRefLinksAction:
+		if senderErr := tracker.Provider.SendAddRefLinks(ctx, links, payload.UserName, ref); senderErr != nil {
+			if errors.Is(senderErr, task_tracker_client.ErrRefLinksNotSupported) {
+				log.Debug("task_tracker_sender: task tracker %s does not support commit and branch links, task %d is skipped", tracker.Name, task.ID)
+				break
 			}
+			auditParams["error"] = "Error has occurred while sending the commit or branch link"
+			audit.CreateAndSendEvent(audit.RefLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+			log.Error("send the add ref event to task tracker: %s, %v", payload.Links, senderErr)
+			return senderErr
+		}
 
-		case unit_links_sender.SendAddPullRequestLinksAction:
-			if senderErr := s.SendAddPullRequestLinks(
-				ctx,
-				links,
-				task.UserName,
-				task.PullRequestID,
-				task.PullRequestURL,
-			); senderErr != nil {
-				auditParams["error"] = "Error has occurred while sending the link"
-				audit.CreateAndSendEvent(audit.PullRequestLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
-				log.Error("send the add event to task tracker: %s, %v", task.Payload, senderErr)
-				s.handleErr(ctx, task.ID)
-
-				continue
+	case unit_links_sender.SendDeleteRefLinksAction:
+		if senderErr := tracker.Provider.SendDeleteRefLinks(ctx, links, payload.UserName, ref); senderErr != nil {
+			if errors.Is(senderErr, task_tracker_client.ErrRefLinksNotSupported) {
+				log.Debug("task_tracker_sender: task tracker %s does not support commit and branch links, task %d is skipped", tracker.Name, task.ID)
+				break
 			}
-		case unit_links_sender.SendUpdatePullRequestStatusAction:
-			if senderErr := s.SendUpdatePullRequestStatus(ctx, links, task.UserName, task.PullRequestID); senderErr != nil {
-				auditParams["error"] = "Error has occurred while sending the status of an updating pull request"
-				audit.CreateAndSendEvent(audit.PullRequestsUpdateEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
-				log.Error("send the update event to task tracker: %s, %v", task.Payload, senderErr)
-				s.handleErr(ctx, task.ID)
-
-				continue
-			}
-
-		default:
-			log.Error("send the event to task tracker: %s: unknown action: %s", task.Payload, task.Action)
-			s.handleErr(ctx, task.ID)
-
-			continue
+			auditParams["error"] = "Error has occurred while deleting the commit or branch link"
+			audit.CreateAndSendEvent(audit.RefLinksDeleteEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+			log.Error("send the delete ref event to task tracker: %s, %v", payload.Links, senderErr)
+			return senderErr
 		}
 
-		if unlockErr := s.taskDB.UnlockTaskWithSuccess(ctx, task.ID); unlockErr != nil {
-			auditParams["error"] = "Error has occurred while unlocking and changing status task"
-			audit.CreateAndSendEvent(audit.UnitTaskUnlockEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+	default:
+		log.Error("send the event to task tracker: %s: unknown action: %s", payload.Links, payload.Action)
+		return background_task_service.Permanent(fmt.Errorf("unknown action: %s", payload.Action))
+	}
 
-			s.handleErr(ctx, task.ID)
-		}
+	log.Debug("send the event to task tracker: %s, %v: success", payload.Links, payload.Action)
+	audit.CreateAndSendEvent(audit.PullRequestLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusSuccess, audit.EmptyRequiredField, auditParams)
+	return nil
+}
 
-		log.Debug("send the event to task tracker: %s, %v: success", task.Payload, task.Action)
-		audit.CreateAndSendEvent(audit.PullRequestLinksAddEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusSuccess, audit.EmptyRequiredField, auditParams)
+// AuditParams параметры задачи для события аудита о переводе задачи в состояние dead
+func AuditParams(payload unit_links_sender.Payload) map[string]string {
+	params := map[string]string{
+		"task_action":     string(payload.Action),
+		"pull_request_id": strconv.FormatInt(payload.PullRequestID, 10),
+	}
+	if payload.RefID != 0 {
+		params["ref_id"] = strconv.FormatInt(payload.RefID, 10)
+	}
+	return params
+}
 
+// resolveTracker возвращает трекер задач задачи. Для задач коммитов и веток трекер выбирается
+// по репозиторию коммита или ветки RefID
+func (s taskTrackerSender) resolveTracker(
+	ctx context.Context,
+	payload unit_links_sender.Payload,
+) (task_tracker_client.Tracker, *unit_links.UnitLinksRef, error) {
+	if payload.Action != unit_links_sender.SendAddRefLinksAction && payload.Action != unit_links_sender.SendDeleteRefLinksAction {
+		tracker, err := s.taskTrackerResolver.ResolveByPullRequestID(ctx, payload.PullRequestID)
+		return tracker, nil, err
 	}
 
-	return nil
-}
+	ref, err := unit_links.GetRefByID(ctx, payload.RefID)
+	if err != nil {
+		return task_tracker_client.Tracker{}, nil, fmt.Errorf("get ref: %w", err)
+	}
 
-func (s taskTrackerSender) handleErr(ctx context.Context, taskID int64) {
-	if err := s.taskDB.UnlockTask(ctx, taskID); err != nil {
-		if handledErr := new(unit_links_sender_db.TaskAlreadyLockedError); errors.As(err, &handledErr) {
-			log.Debug("unlock task id: '%d' err: %s", handledErr.Error())
-		}
+	tracker, err := s.taskTrackerResolver.ResolveByRepoID(ctx, ref.RepoID)
+	if err != nil {
+		return task_tracker_client.Tracker{}, nil, fmt.Errorf("resolve by repo id %d: %w", ref.RepoID, err)
 	}
+	return tracker, ref, nil
 }
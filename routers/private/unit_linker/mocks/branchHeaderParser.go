@@ -4,6 +4,8 @@ package mocks
 
 import (
 	gitnames "code.gitea.io/gitea/models/gitnames"
+	gitnamesparser "code.gitea.io/gitea/modules/gitnamesparser"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,27 +14,27 @@ type BranchHeaderParser struct {
 	mock.Mock
 }
 
-// Parse provides a mock function with given fields: branchName
-func (_m *BranchHeaderParser) Parse(branchName string) (gitnames.BranchLinks, error) {
-	ret := _m.Called(branchName)
+// ParseWithPatterns provides a mock function with given fields: branchName, patterns
+func (_m *BranchHeaderParser) ParseWithPatterns(branchName string, patterns gitnamesparser.Patterns) (gitnames.BranchLinks, error) {
+	ret := _m.Called(branchName, patterns)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Parse")
+		panic("no return value specified for ParseWithPatterns")
 	}
 
 	var r0 gitnames.BranchLinks
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (gitnames.BranchLinks, error)); ok {
-		return rf(branchName)
+	if rf, ok := ret.Get(0).(func(string, gitnamesparser.Patterns) (gitnames.BranchLinks, error)); ok {
+		return rf(branchName, patterns)
 	}
-	if rf, ok := ret.Get(0).(func(string) gitnames.BranchLinks); ok {
-		r0 = rf(branchName)
+	if rf, ok := ret.Get(0).(func(string, gitnamesparser.Patterns) gitnames.BranchLinks); ok {
+		r0 = rf(branchName, patterns)
 	} else {
 		r0 = ret.Get(0).(gitnames.BranchLinks)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(branchName)
+	if rf, ok := ret.Get(1).(func(string, gitnamesparser.Patterns) error); ok {
+		r1 = rf(branchName, patterns)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -4,6 +4,8 @@ package mocks
 
 import (
 	gitnames "code.gitea.io/gitea/models/gitnames"
+	gitnamesparser "code.gitea.io/gitea/modules/gitnamesparser"
+
 	mock "github.com/stretchr/testify/mock"
 
 	pull_request_reader "code.gitea.io/gitea/routers/private/pull_request_reader"
@@ -14,27 +16,27 @@ type PullRequestHeaderParser struct {
 	mock.Mock
 }
 
-// Parse provides a mock function with given fields: _a0
-func (_m *PullRequestHeaderParser) Parse(_a0 pull_request_reader.PullRequestHeader) (gitnames.PullRequestLinks, error) {
-	ret := _m.Called(_a0)
+// ParseWithPatterns provides a mock function with given fields: header, patterns
+func (_m *PullRequestHeaderParser) ParseWithPatterns(header pull_request_reader.PullRequestHeader, patterns gitnamesparser.Patterns) (gitnames.PullRequestLinks, error) {
+	ret := _m.Called(header, patterns)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Parse")
+		panic("no return value specified for ParseWithPatterns")
 	}
 
 	var r0 gitnames.PullRequestLinks
 	var r1 error
-	if rf, ok := ret.Get(0).(func(pull_request_reader.PullRequestHeader) (gitnames.PullRequestLinks, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(pull_request_reader.PullRequestHeader, gitnamesparser.Patterns) (gitnames.PullRequestLinks, error)); ok {
+		return rf(header, patterns)
 	}
-	if rf, ok := ret.Get(0).(func(pull_request_reader.PullRequestHeader) gitnames.PullRequestLinks); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(pull_request_reader.PullRequestHeader, gitnamesparser.Patterns) gitnames.PullRequestLinks); ok {
+		r0 = rf(header, patterns)
 	} else {
 		r0 = ret.Get(0).(gitnames.PullRequestLinks)
 	}
 
-	if rf, ok := ret.Get(1).(func(pull_request_reader.PullRequestHeader) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(pull_request_reader.PullRequestHeader, gitnamesparser.Patterns) error); ok {
+		r1 = rf(header, patterns)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -33,6 +33,24 @@ func (_m *UnitLinkDB) RemoveLinks(ctx context.Context, fromUnitID int64, links u
 	return r0
 }
 
+// RemoveRefLinks provides a mock function with given fields: ctx, repoID, refType, ref, userName
+func (_m *UnitLinkDB) RemoveRefLinks(ctx context.Context, repoID int64, refType unit_links.FromUnitType, ref string, userName string) error {
+	ret := _m.Called(ctx, repoID, refType, ref, userName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveRefLinks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, unit_links.FromUnitType, string, string) error); ok {
+		r0 = rf(ctx, repoID, refType, ref, userName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateLinks provides a mock function with given fields: ctx, fromUnitID, links, userName, pullRequestURL
 func (_m *UnitLinkDB) UpdateLinks(ctx context.Context, fromUnitID int64, links unit_links.AllUnitLinks, userName string, pullRequestURL string) error {
 	ret := _m.Called(ctx, fromUnitID, links, userName, pullRequestURL)
@@ -51,6 +69,24 @@ func (_m *UnitLinkDB) UpdateLinks(ctx context.Context, fromUnitID int64, links u
 	return r0
 }
 
+// UpdateRefLinks provides a mock function with given fields: ctx, ref, links, userName
+func (_m *UnitLinkDB) UpdateRefLinks(ctx context.Context, ref *unit_links.UnitLinksRef, links unit_links.AllUnitLinks, userName string) error {
+	ret := _m.Called(ctx, ref, links, userName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRefLinks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *unit_links.UnitLinksRef, unit_links.AllUnitLinks, string) error); ok {
+		r0 = rf(ctx, ref, links, userName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUnitLinkDB creates a new instance of UnitLinkDB. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUnitLinkDB(t interface {
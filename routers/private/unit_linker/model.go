@@ -29,6 +29,52 @@ func (p PullRequestLinkRequest) Validate() error {
 	return nil
 }
 
+// BranchLinkRequest запрос привязки ветки к юнитам
 type BranchLinkRequest struct {
+	RepoID     int64
 	BranchName string
+	BranchURL  string
+	UserName   string
+}
+
+func (b BranchLinkRequest) Validate() error {
+	if b.RepoID < 1 {
+		return fmt.Errorf("repository ID should be a positive number")
+	}
+
+	if len(b.BranchName) == 0 {
+		return fmt.Errorf("branch name is required")
+	}
+
+	if len(b.UserName) == 0 {
+		return fmt.Errorf("user name is required")
+	}
+
+	return nil
+}
+
+// CommitHeader коммит, отправленный в репозиторий
+type CommitHeader struct {
+	SHA     string
+	Message string
+	URL     string
+}
+
+// CommitsLinkRequest запрос привязки коммитов к юнитам
+type CommitsLinkRequest struct {
+	RepoID   int64
+	Commits  []CommitHeader
+	UserName string
+}
+
+func (c CommitsLinkRequest) Validate() error {
+	if c.RepoID < 1 {
+		return fmt.Errorf("repository ID should be a positive number")
+	}
+
+	if len(c.UserName) == 0 {
+		return fmt.Errorf("user name is required")
+	}
+
+	return nil
 }
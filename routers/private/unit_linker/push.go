@@ -0,0 +1,133 @@
+package unit_linker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/background_task"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit_links_push_task"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	background_task_service "code.gitea.io/gitea/services/background_task"
+)
+
+// maxLinkedCommitsPerRef максимальное количество коммитов одной ветки, привязываемых к юнитам за один push
+const maxLinkedCommitsPerRef = 100
+
+// LinkPush привязывает к юнитам созданные ветки и отправленные коммиты, а удаленные ветки отвязывает.
+// Обрабатывает задачи очереди unit_links_push_task.Queue, которые ставит post-receive хук. Повторная обработка
+// задачи не создает лишних связей, так как сохраняется только разница со связями коммита или ветки
+func (u UnitLinker) LinkPush(ctx context.Context, _ *background_task.Task, payload unit_links_push_task.Payload) error {
+	repo, err := repo_model.GetRepositoryByID(ctx, payload.RepoID)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return background_task_service.Permanent(fmt.Errorf("get repository: %w", err))
+		}
+		return fmt.Errorf("get repository: %w", err)
+	}
+
+	var gitRepo *git.Repository
+	defer func() {
+		if gitRepo != nil {
+			gitRepo.Close()
+		}
+	}()
+
+	for i := range payload.RefFullNames {
+		refFullName := payload.RefFullNames[i]
+		if !strings.HasPrefix(refFullName, git.BranchPrefix) || i >= len(payload.OldCommitIDs) || i >= len(payload.NewCommitIDs) {
+			continue
+		}
+
+		oldCommitID := payload.OldCommitIDs[i]
+		newCommitID := payload.NewCommitIDs[i]
+		branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
+		branchRequest := BranchLinkRequest{
+			RepoID:     repo.ID,
+			BranchName: branchName,
+			BranchURL:  fmt.Sprintf("/%s/%s/src/branch/%s", repo.OwnerName, repo.LowerName, util.PathEscapeSegments(branchName)),
+			UserName:   payload.UserName,
+		}
+
+		if newCommitID == git.EmptySHA {
+			if err = u.UnlinkBranch(ctx, branchRequest); err != nil {
+				log.Debug("unit_linker: unlink branch %s: %v", branchName, err)
+			}
+			continue
+		}
+
+		if oldCommitID == git.EmptySHA {
+			if err = u.LinkBranch(ctx, branchRequest); err != nil {
+				log.Debug("unit_linker: link branch %s: %v", branchName, err)
+			}
+		}
+
+		if gitRepo == nil {
+			if gitRepo, err = git.OpenRepository(ctx, repo.OwnerName, repo.Name, repo.RepoPath()); err != nil {
+				return fmt.Errorf("open repository: %w", err)
+			}
+		}
+
+		commits, err := getPushedCommits(gitRepo, oldCommitID, newCommitID)
+		if err != nil {
+			log.Debug("unit_linker: get pushed commits of branch %s: %v", branchName, err)
+			continue
+		}
+
+		commitsRequest := CommitsLinkRequest{
+			RepoID:   repo.ID,
+			UserName: payload.UserName,
+			Commits:  make([]CommitHeader, 0, len(commits)),
+		}
+		for _, commit := range commits {
+			sha := commit.ID.String()
+			commitsRequest.Commits = append(commitsRequest.Commits, CommitHeader{
+				SHA:     sha,
+				Message: commit.CommitMessage,
+				URL:     fmt.Sprintf("/%s/%s/commit/%s", repo.OwnerName, repo.LowerName, sha),
+			})
+		}
+
+		if err = u.LinkCommits(ctx, commitsRequest); err != nil {
+			log.Debug("unit_linker: link commits of branch %s: %v", branchName, err)
+		}
+	}
+
+	return nil
+}
+
+// PushAuditParams параметры задачи для события аудита о переводе задачи в состояние dead
+func PushAuditParams(payload unit_links_push_task.Payload) map[string]string {
+	return map[string]string{
+		"repo_id":   strconv.FormatInt(payload.RepoID, 10),
+		"user_name": payload.UserName,
+	}
+}
+
+// getPushedCommits возвращает коммиты, отправленные в ветку, но не более maxLinkedCommitsPerRef.
+// Для новой ветки берутся последние коммиты
+func getPushedCommits(gitRepo *git.Repository, oldCommitID, newCommitID string) ([]*git.Commit, error) {
+	newCommit, err := gitRepo.GetCommit(newCommitID)
+	if err != nil {
+		return nil, fmt.Errorf("get commit %s: %w", newCommitID, err)
+	}
+
+	var commits []*git.Commit
+	if oldCommitID == git.EmptySHA {
+		commits, err = newCommit.CommitsBeforeLimit(maxLinkedCommitsPerRef)
+	} else {
+		commits, err = newCommit.CommitsBeforeUntil(oldCommitID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get commits: %w", err)
+	}
+
+	if len(commits) > maxLinkedCommitsPerRef {
+		commits = commits[:maxLinkedCommitsPerRef]
+	}
+	return commits, nil
+}
@@ -4,21 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"code.gitea.io/gitea/models/gitnames"
 	"code.gitea.io/gitea/models/unit_links"
+	"code.gitea.io/gitea/modules/gitnamesparser"
 	"code.gitea.io/gitea/routers/private/pull_request_reader"
 	"code.gitea.io/gitea/routers/private/task_tracker_client"
 )
 
 // //go:generate mockery --name=branchHeaderParser --exported
 type branchHeaderParser interface {
-	Parse(branchName string) (gitnames.BranchLinks, error)
+	ParseWithPatterns(branchName string, patterns gitnamesparser.Patterns) (gitnames.BranchLinks, error)
+}
+
+// //go:generate mockery --name=commitHeaderParser --exported
+type commitHeaderParser interface {
+	ParseWithPatterns(message string, patterns gitnamesparser.Patterns) (gitnames.CommitLinks, error)
 }
 
 // //go:generate mockery --name=pullRequestHeaderParser --exported
 type pullRequestHeaderParser interface {
-	Parse(pull_request_reader.PullRequestHeader) (gitnames.PullRequestLinks, error)
+	ParseWithPatterns(header pull_request_reader.PullRequestHeader, patterns gitnamesparser.Patterns) (gitnames.PullRequestLinks, error)
 }
 
 // //go:generate mockery --name=unitLinkDB --exported
@@ -35,11 +42,23 @@ type unitLinkDB interface {
 		links unit_links.AllUnitLinks,
 		userName, pullRequestURL string,
 	) error
+	UpdateRefLinks(
+		ctx context.Context,
+		ref *unit_links.UnitLinksRef,
+		links unit_links.AllUnitLinks,
+		userName string,
+	) error
+	RemoveRefLinks(
+		ctx context.Context,
+		repoID int64,
+		refType unit_links.FromUnitType,
+		ref, userName string,
+	) error
 }
 
-// //go:generate mockery --name=taskTrackerClient --exported
-type taskTrackerClient interface {
-	CheckCodes(ctx context.Context, codes []gitnames.UnitCode) (task_tracker_client.CheckCodesResponse, error)
+// //go:generate mockery --name=taskTrackerResolver --exported
+type taskTrackerResolver interface {
+	ResolveByRepoID(ctx context.Context, repoID int64) (task_tracker_client.Tracker, error)
 }
 
 // //go:generate mockery --name=pullRequestReader --exported
@@ -54,28 +73,32 @@ type pullRequestReader interface {
 // UnitLinker объект для создания связей
 type UnitLinker struct {
 	branchHeaderParser
+	commitHeaderParser
 	pullRequestHeaderParser
 	unitLinkDB
 	pullRequestReader
-	taskTrackerClient
+	taskTrackerResolver
 	withUnitValidation bool
 }
 
 // NewUnitLinker создает unit_linker
 func NewUnitLinker(
 	branchHeaderParser branchHeaderParser,
+	commitHeaderParser commitHeaderParser,
 	pullRequestHeaderParser pullRequestHeaderParser,
 	unitLinkDB unitLinkDB, pullRequestReader pullRequestReader,
-	taskTrackerClient taskTrackerClient,
+	taskTrackerResolver taskTrackerResolver,
 	WithUnitValidation bool,
 ) UnitLinker {
 	return UnitLinker{
 		unitLinkDB:         unitLinkDB,
 		branchHeaderParser: branchHeaderParser,
+		commitHeaderParser: commitHeaderParser,
 		pullRequestReader:  pullRequestReader,
-		taskTrackerClient:  taskTrackerClient,
 		withUnitValidation: WithUnitValidation,
 
+		taskTrackerResolver: taskTrackerResolver,
+
 		pullRequestHeaderParser: pullRequestHeaderParser,
 	}
 }
@@ -97,12 +120,17 @@ func (u UnitLinker) LinkPullRequest(ctx context.Context, request PullRequestLink
 
 	prHeader.BranchName = request.BranchName
 
-	rawPRLinks, err := u.pullRequestHeaderParser.Parse(prHeader)
+	tracker, err := u.taskTrackerResolver.ResolveByRepoID(ctx, prHeader.RepoID)
+	if err != nil {
+		return fmt.Errorf("resolve task tracker, id: '%d': %w", prID, err)
+	}
+
+	rawPRLinks, err := u.pullRequestHeaderParser.ParseWithPatterns(prHeader, tracker.UnitCodePatterns)
 	if err != nil {
 		return fmt.Errorf("parse pull request header, id: '%d': %w", prID, err)
 	}
 
-	links, err := u.getPullRequestLinks(ctx, prID, rawPRLinks)
+	links, err := u.getPullRequestLinks(ctx, tracker.Provider, prID, rawPRLinks)
 	if err != nil {
 		if handledErr := handleGetLinksErrors(err); handledErr != nil {
 			return fmt.Errorf("get codes, id: '%d': %w", prID, err)
@@ -133,12 +161,17 @@ func (u UnitLinker) UnlinkPullRequest(ctx context.Context, request PullRequestLi
 
 	prHeader.BranchName = request.BranchName
 
-	rawLinks, err := u.pullRequestHeaderParser.Parse(prHeader)
+	tracker, err := u.taskTrackerResolver.ResolveByRepoID(ctx, prHeader.RepoID)
+	if err != nil {
+		return fmt.Errorf("resolve task tracker, id: '%d': %w", prID, err)
+	}
+
+	rawLinks, err := u.pullRequestHeaderParser.ParseWithPatterns(prHeader, tracker.UnitCodePatterns)
 	if err != nil {
 		return fmt.Errorf("parse pull request header, id: '%d': %w", prID, err)
 	}
 
-	links, err := u.getPullRequestLinks(ctx, prID, rawLinks)
+	links, err := u.getPullRequestLinks(ctx, tracker.Provider, prID, rawLinks)
 	if err != nil {
 		if handledErr := handleGetLinksErrors(err); handledErr != nil {
 			return fmt.Errorf("get codes, id: '%d': %w", prID, err)
@@ -152,7 +185,156 @@ func (u UnitLinker) UnlinkPullRequest(ctx context.Context, request PullRequestLi
 	return nil
 }
 
-func (u UnitLinker) getPullRequestLinks(ctx context.Context, prID int64, rawLinks gitnames.PullRequestLinks) (unit_links.AllUnitLinks, error) {
+// LinkBranch позволяет привязать ветку к юнитам TaskTracker, коды которых есть в названии ветки
+func (u UnitLinker) LinkBranch(ctx context.Context, request BranchLinkRequest) error {
+	if err := request.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	tracker, err := u.taskTrackerResolver.ResolveByRepoID(ctx, request.RepoID)
+	if err != nil {
+		return fmt.Errorf("resolve task tracker, repo id: '%d': %w", request.RepoID, err)
+	}
+	if !tracker.Provider.SupportsRefLinks() {
+		return fmt.Errorf("task tracker %s: %w", tracker.Name, task_tracker_client.ErrRefLinksNotSupported)
+	}
+
+	rawLinks, err := u.branchHeaderParser.ParseWithPatterns(request.BranchName, tracker.UnitCodePatterns)
+	if err != nil {
+		return fmt.Errorf("parse branch name, branch: '%s': %w", request.BranchName, err)
+	}
+
+	codes, err := rawLinks.GetUniqCodes()
+	if err != nil {
+		return fmt.Errorf("get codes, branch: '%s': %w", request.BranchName, err)
+	}
+
+	links, err := u.getRefLinks(ctx, tracker.Provider, unit_links.BranchFromUnitType, codes)
+	if err != nil {
+		return fmt.Errorf("get links, branch: '%s': %w", request.BranchName, err)
+	}
+
+	ref := &unit_links.UnitLinksRef{
+		RepoID:  request.RepoID,
+		RefType: unit_links.BranchFromUnitType,
+		Ref:     request.BranchName,
+		Title:   request.BranchName,
+		URL:     request.BranchURL,
+	}
+	if err = u.unitLinkDB.UpdateRefLinks(ctx, ref, links, request.UserName); err != nil {
+		return fmt.Errorf("create link, branch: '%s': %w", request.BranchName, err)
+	}
+
+	return nil
+}
+
+// UnlinkBranch позволяет отвязать удаленную ветку от юнитов TaskTracker
+func (u UnitLinker) UnlinkBranch(ctx context.Context, request BranchLinkRequest) error {
+	if err := request.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if err := u.unitLinkDB.RemoveRefLinks(ctx, request.RepoID, unit_links.BranchFromUnitType, request.BranchName, request.UserName); err != nil {
+		return fmt.Errorf("remove link, branch: '%s': %w", request.BranchName, err)
+	}
+
+	return nil
+}
+
+// LinkCommits позволяет привязать коммиты к юнитам TaskTracker, коды которых есть в сообщениях коммитов.
+// Коммиты без кодов юнитов пропускаются
+func (u UnitLinker) LinkCommits(ctx context.Context, request CommitsLinkRequest) error {
+	if err := request.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if len(request.Commits) == 0 {
+		return nil
+	}
+
+	tracker, err := u.taskTrackerResolver.ResolveByRepoID(ctx, request.RepoID)
+	if err != nil {
+		return fmt.Errorf("resolve task tracker, repo id: '%d': %w", request.RepoID, err)
+	}
+	if !tracker.Provider.SupportsRefLinks() {
+		return fmt.Errorf("task tracker %s: %w", tracker.Name, task_tracker_client.ErrRefLinksNotSupported)
+	}
+
+	for _, commit := range request.Commits {
+		rawLinks, err := u.commitHeaderParser.ParseWithPatterns(commit.Message, tracker.UnitCodePatterns)
+		if err != nil {
+			continue
+		}
+
+		codes, err := rawLinks.GetUniqCodes()
+		if err != nil {
+			continue
+		}
+
+		links, err := u.getRefLinks(ctx, tracker.Provider, unit_links.CommitFromUnitType, codes)
+		if err != nil {
+			return fmt.Errorf("get links, commit: '%s': %w", commit.SHA, err)
+		}
+
+		if links.IsEmpty() {
+			continue
+		}
+
+		ref := &unit_links.UnitLinksRef{
+			RepoID:  request.RepoID,
+			RefType: unit_links.CommitFromUnitType,
+			Ref:     commit.SHA,
+			Title:   strings.Split(strings.TrimSpace(commit.Message), "\n")[0],
+			URL:     commit.URL,
+		}
+		if err = u.unitLinkDB.UpdateRefLinks(ctx, ref, links, request.UserName); err != nil {
+			return fmt.Errorf("create link, commit: '%s': %w", commit.SHA, err)
+		}
+	}
+
+	return nil
+}
+
+// getRefLinks формирует связи коммита или ветки с юнитами, FromUnitID заполняется при сохранении
+func (u UnitLinker) getRefLinks(
+	ctx context.Context,
+	provider task_tracker_client.Provider,
+	refType unit_links.FromUnitType,
+	codes []gitnames.UnitCode,
+) (unit_links.AllUnitLinks, error) {
+	if u.withUnitValidation {
+		checkResponse, err := provider.CheckCodes(ctx, codes)
+		if err != nil {
+			return nil, fmt.Errorf("check codes: %w", err)
+		}
+
+		var checkedCodes []gitnames.UnitCode
+		for _, unit := range checkResponse.Units {
+			if unit.IsExists {
+				checkedCodes = append(checkedCodes, gitnames.UnitCode{Code: unit.Code})
+			}
+		}
+		codes = checkedCodes
+	}
+
+	links := make(unit_links.AllUnitLinks, 0, len(codes))
+	for _, code := range codes {
+		links = append(links, unit_links.UnitLinks{
+			IsActive:     true,
+			ToUnitID:     code.Code,
+			FromUnitType: refType,
+		})
+	}
+
+	return links, nil
+}
+
+func (u UnitLinker) getPullRequestLinks(
+	ctx context.Context,
+	provider task_tracker_client.Provider,
+	prID int64,
+	rawLinks gitnames.PullRequestLinks,
+) (unit_links.AllUnitLinks, error) {
 	codes, err := rawLinks.GetUniqCodes()
 	if err != nil {
 		return nil, fmt.Errorf("get codes: %w", err)
@@ -174,7 +356,7 @@ func (u UnitLinker) getPullRequestLinks(ctx context.Context, prID int64, rawLink
 		return links, nil
 	}
 
-	checkResponse, err := u.taskTrackerClient.CheckCodes(ctx, codes)
+	checkResponse, err := provider.CheckCodes(ctx, codes)
 	if err != nil {
 		return nil, fmt.Errorf("check pull request codes: %w", err)
 	}
@@ -10,8 +10,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/models/unit_links"
+	"code.gitea.io/gitea/modules/gitnamesparser"
 	"code.gitea.io/gitea/routers/private/pull_request_reader"
 	"code.gitea.io/gitea/routers/private/task_tracker_client"
+	task_tracker_mocks "code.gitea.io/gitea/routers/private/task_tracker_client/mocks"
 	"code.gitea.io/gitea/routers/private/unit_linker/mocks"
 )
 
@@ -23,8 +26,10 @@ var (
 func TestUnitLinkerUseCase_LinkPullRequest(t *testing.T) {
 	ctx := context.Background()
 	branchParserMock := mocks.NewBranchHeaderParser(t)
+	commitParserMock := mocks.NewCommitHeaderParser(t)
 	pullRequestParserMock := mocks.NewPullRequestHeaderParser(t)
-	taskTrackerClientMock := mocks.NewTaskTrackerClient(t)
+	taskTrackerResolverMock := mocks.NewTaskTrackerResolver(t)
+	taskTrackerProviderMock := task_tracker_mocks.NewProvider(t)
 	unitLinkerDBMock := mocks.NewUnitLinkDB(t)
 	pullRequestReaderDBMock := mocks.NewPullRequestReader(t)
 
@@ -32,14 +37,16 @@ func TestUnitLinkerUseCase_LinkPullRequest(t *testing.T) {
 
 	mockedUnitLinker := NewUnitLinker(
 		branchParserMock,
+		commitParserMock,
 		pullRequestParserMock,
 		unitLinkerDBMock,
 		pullRequestReaderDBMock,
-		taskTrackerClientMock,
+		taskTrackerResolverMock,
 		withValidation,
 	)
 
 	readerReturnValue := pull_request_reader.PullRequestHeader{
+		RepoID:          1,
 		PullRequestName: "[GITRU-1] The first pull request ever",
 		BranchName:      "feature/GITRU-13",
 		CommitNames:     []string{"Init commit", "GITRU-2 Another day in paradise"},
@@ -50,18 +57,22 @@ func TestUnitLinkerUseCase_LinkPullRequest(t *testing.T) {
 		CommitsLinks: []gitnames.CommitLinks{{gitnames.Base{Description: "", LinkedUnits: []gitnames.UnitCode{{Code: "GITRU-2"}}}}},
 	}
 
-	request := PullRequestLinkRequest{BranchName: "feature/GITRU-13", PullRequestID: 1}
+	request := PullRequestLinkRequest{BranchName: "feature/GITRU-13", PullRequestID: 1, UserName: "user"}
 
 	pullRequestReaderDBMock.
 		On("ReadByID", testCtx, request.PullRequestID, pull_request_reader.MergedPullRequestStatus).
 		Return(readerReturnValue, nil)
 
+	taskTrackerResolverMock.
+		On("ResolveByRepoID", testCtx, readerReturnValue.RepoID).
+		Return(task_tracker_client.Tracker{Provider: taskTrackerProviderMock}, nil)
+
 	pullRequestParserMock.
-		On("Parse", readerReturnValue).
+		On("ParseWithPatterns", readerReturnValue, gitnamesparser.Patterns(nil)).
 		Return(parserReturnValue, nil)
 
 	unitLinkerDBMock.
-		On("UpdateLinks", testCtx, request.PullRequestID, testUnitLink).
+		On("UpdateLinks", testCtx, request.PullRequestID, testUnitLink, request.UserName, readerReturnValue.PullRequestURL).
 		Return(nil)
 
 	err := mockedUnitLinker.LinkPullRequest(ctx, request)
@@ -71,8 +82,10 @@ func TestUnitLinkerUseCase_LinkPullRequest(t *testing.T) {
 func TestUnitLinkerUseCase_LinkPullRequest_with_validation(t *testing.T) {
 	ctx := context.Background()
 	branchParserMock := mocks.NewBranchHeaderParser(t)
+	commitParserMock := mocks.NewCommitHeaderParser(t)
 	pullRequestParserMock := mocks.NewPullRequestHeaderParser(t)
-	taskTrackerClientMock := mocks.NewTaskTrackerClient(t)
+	taskTrackerResolverMock := mocks.NewTaskTrackerResolver(t)
+	taskTrackerProviderMock := task_tracker_mocks.NewProvider(t)
 	unitLinkerDBMock := mocks.NewUnitLinkDB(t)
 	pullRequestReaderDBMock := mocks.NewPullRequestReader(t)
 
@@ -80,14 +93,16 @@ func TestUnitLinkerUseCase_LinkPullRequest_with_validation(t *testing.T) {
 
 	mockedUnitLinker := NewUnitLinker(
 		branchParserMock,
+		commitParserMock,
 		pullRequestParserMock,
 		unitLinkerDBMock,
 		pullRequestReaderDBMock,
-		taskTrackerClientMock,
+		taskTrackerResolverMock,
 		withValidation,
 	)
 
 	readerReturnValue := pull_request_reader.PullRequestHeader{
+		RepoID:          1,
 		PullRequestName: "[GITRU-1] The first pull request ever",
 		BranchName:      "feature/GITRU-13",
 		CommitNames:     []string{"Init commit", "GITRU-2 Another day in paradise"},
@@ -98,7 +113,7 @@ func TestUnitLinkerUseCase_LinkPullRequest_with_validation(t *testing.T) {
 		CommitsLinks: []gitnames.CommitLinks{{gitnames.Base{Description: "", LinkedUnits: []gitnames.UnitCode{{Code: "GITRU-2"}}}}},
 	}
 
-	request := PullRequestLinkRequest{BranchName: "feature/GITRU-13", PullRequestID: 1}
+	request := PullRequestLinkRequest{BranchName: "feature/GITRU-13", PullRequestID: 1, UserName: "user"}
 	checkCodesRequest := []gitnames.UnitCode{{Code: "GITRU-1"}, {Code: "GITRU-2"}}
 	checkCodesResponse := task_tracker_client.CheckCodesResponse{Units: []task_tracker_client.Unit{{Code: "GITRU-1", IsExists: true}, {Code: "GITRU-2", IsExists: true}}}
 
@@ -106,18 +121,166 @@ func TestUnitLinkerUseCase_LinkPullRequest_with_validation(t *testing.T) {
 		On("ReadByID", testCtx, request.PullRequestID, pull_request_reader.MergedPullRequestStatus).
 		Return(readerReturnValue, nil)
 
+	taskTrackerResolverMock.
+		On("ResolveByRepoID", testCtx, readerReturnValue.RepoID).
+		Return(task_tracker_client.Tracker{Provider: taskTrackerProviderMock}, nil)
+
 	pullRequestParserMock.
-		On("Parse", readerReturnValue).
+		On("ParseWithPatterns", readerReturnValue, gitnamesparser.Patterns(nil)).
 		Return(parserReturnValue, nil)
 
-	taskTrackerClientMock.
+	taskTrackerProviderMock.
 		On("CheckCodes", testCtx, checkCodesRequest).
 		Return(checkCodesResponse, nil)
 
 	unitLinkerDBMock.
-		On("UpdateLinks", testCtx, request.PullRequestID, testUnitLink).
+		On("UpdateLinks", testCtx, request.PullRequestID, testUnitLink, request.UserName, readerReturnValue.PullRequestURL).
 		Return(nil)
 
 	err := mockedUnitLinker.LinkPullRequest(ctx, request)
 	require.NoError(t, err)
 }
+
+func TestUnitLinkerUseCase_LinkBranch(t *testing.T) {
+	ctx := context.Background()
+
+	branchParserMock := mocks.NewBranchHeaderParser(t)
+	commitParserMock := mocks.NewCommitHeaderParser(t)
+	pullRequestParserMock := mocks.NewPullRequestHeaderParser(t)
+	taskTrackerResolverMock := mocks.NewTaskTrackerResolver(t)
+	taskTrackerProviderMock := task_tracker_mocks.NewProvider(t)
+	unitLinkerDBMock := mocks.NewUnitLinkDB(t)
+	pullRequestReaderDBMock := mocks.NewPullRequestReader(t)
+
+	const withValidation = false
+	mockedUnitLinker := NewUnitLinker(
+		branchParserMock,
+		commitParserMock,
+		pullRequestParserMock,
+		unitLinkerDBMock,
+		pullRequestReaderDBMock,
+		taskTrackerResolverMock,
+		withValidation,
+	)
+
+	request := BranchLinkRequest{RepoID: 1, BranchName: "feature/GITRU-13", BranchURL: "/org/repo/src/branch/feature/GITRU-13", UserName: "user"}
+	parserReturnValue := gitnames.BranchLinks{Base: gitnames.Base{LinkedUnits: []gitnames.UnitCode{{Code: "GITRU-13"}}}}
+	expectedRef := &unit_links.UnitLinksRef{
+		RepoID:  request.RepoID,
+		RefType: unit_links.BranchFromUnitType,
+		Ref:     request.BranchName,
+		Title:   request.BranchName,
+		URL:     request.BranchURL,
+	}
+	expectedLinks := unit_links.AllUnitLinks{{IsActive: true, ToUnitID: "GITRU-13", FromUnitType: unit_links.BranchFromUnitType}}
+
+	taskTrackerResolverMock.
+		On("ResolveByRepoID", testCtx, request.RepoID).
+		Return(task_tracker_client.Tracker{Provider: taskTrackerProviderMock}, nil)
+	taskTrackerProviderMock.
+		On("SupportsRefLinks").
+		Return(true)
+	branchParserMock.
+		On("ParseWithPatterns", request.BranchName, gitnamesparser.Patterns(nil)).
+		Return(parserReturnValue, nil)
+	unitLinkerDBMock.
+		On("UpdateRefLinks", testCtx, expectedRef, expectedLinks, request.UserName).
+		Return(nil)
+
+	err := mockedUnitLinker.LinkBranch(ctx, request)
+	require.NoError(t, err)
+}
+
+func TestUnitLinkerUseCase_LinkBranch_refLinksNotSupported(t *testing.T) {
+	ctx := context.Background()
+
+	taskTrackerResolverMock := mocks.NewTaskTrackerResolver(t)
+	taskTrackerProviderMock := task_tracker_mocks.NewProvider(t)
+
+	const withValidation = false
+	mockedUnitLinker := NewUnitLinker(
+		mocks.NewBranchHeaderParser(t),
+		mocks.NewCommitHeaderParser(t),
+		mocks.NewPullRequestHeaderParser(t),
+		mocks.NewUnitLinkDB(t),
+		mocks.NewPullRequestReader(t),
+		taskTrackerResolverMock,
+		withValidation,
+	)
+
+	request := BranchLinkRequest{RepoID: 1, BranchName: "feature/GITRU-13", BranchURL: "/org/repo/src/branch/feature/GITRU-13", UserName: "user"}
+
+	taskTrackerResolverMock.
+		On("ResolveByRepoID", testCtx, request.RepoID).
+		Return(task_tracker_client.Tracker{Name: "default", Provider: taskTrackerProviderMock}, nil)
+	taskTrackerProviderMock.
+		On("SupportsRefLinks").
+		Return(false)
+
+	err := mockedUnitLinker.LinkBranch(ctx, request)
+	require.ErrorIs(t, err, task_tracker_client.ErrRefLinksNotSupported)
+}
+
+func TestUnitLinkerUseCase_LinkCommits_with_validation(t *testing.T) {
+	ctx := context.Background()
+
+	branchParserMock := mocks.NewBranchHeaderParser(t)
+	commitParserMock := mocks.NewCommitHeaderParser(t)
+	pullRequestParserMock := mocks.NewPullRequestHeaderParser(t)
+	taskTrackerResolverMock := mocks.NewTaskTrackerResolver(t)
+	taskTrackerProviderMock := task_tracker_mocks.NewProvider(t)
+	unitLinkerDBMock := mocks.NewUnitLinkDB(t)
+	pullRequestReaderDBMock := mocks.NewPullRequestReader(t)
+
+	const withValidation = true
+	mockedUnitLinker := NewUnitLinker(
+		branchParserMock,
+		commitParserMock,
+		pullRequestParserMock,
+		unitLinkerDBMock,
+		pullRequestReaderDBMock,
+		taskTrackerResolverMock,
+		withValidation,
+	)
+
+	request := CommitsLinkRequest{
+		RepoID:   1,
+		UserName: "user",
+		Commits: []CommitHeader{
+			{SHA: "a1", Message: "Init commit", URL: "/org/repo/commit/a1"},
+			{SHA: "b2", Message: "GITRU-1 GITRU-2 Fix tests\n\nDetails", URL: "/org/repo/commit/b2"},
+		},
+	}
+	checkCodesRequest := []gitnames.UnitCode{{Code: "GITRU-1"}, {Code: "GITRU-2"}}
+	checkCodesResponse := task_tracker_client.CheckCodesResponse{Units: []task_tracker_client.Unit{{Code: "GITRU-1", IsExists: true}, {Code: "GITRU-2", IsExists: false}}}
+	expectedRef := &unit_links.UnitLinksRef{
+		RepoID:  request.RepoID,
+		RefType: unit_links.CommitFromUnitType,
+		Ref:     "b2",
+		Title:   "GITRU-1 GITRU-2 Fix tests",
+		URL:     "/org/repo/commit/b2",
+	}
+	expectedLinks := unit_links.AllUnitLinks{{IsActive: true, ToUnitID: "GITRU-1", FromUnitType: unit_links.CommitFromUnitType}}
+
+	taskTrackerResolverMock.
+		On("ResolveByRepoID", testCtx, request.RepoID).
+		Return(task_tracker_client.Tracker{Provider: taskTrackerProviderMock}, nil)
+	taskTrackerProviderMock.
+		On("SupportsRefLinks").
+		Return(true)
+	commitParserMock.
+		On("ParseWithPatterns", "Init commit", gitnamesparser.Patterns(nil)).
+		Return(gitnames.CommitLinks{}, gitnames.NewEmptyUnitCodesListError())
+	commitParserMock.
+		On("ParseWithPatterns", request.Commits[1].Message, gitnamesparser.Patterns(nil)).
+		Return(gitnames.CommitLinks{Base: gitnames.Base{LinkedUnits: checkCodesRequest}}, nil)
+	taskTrackerProviderMock.
+		On("CheckCodes", testCtx, checkCodesRequest).
+		Return(checkCodesResponse, nil)
+	unitLinkerDBMock.
+		On("UpdateRefLinks", testCtx, expectedRef, expectedLinks, request.UserName).
+		Return(nil)
+
+	err := mockedUnitLinker.LinkCommits(ctx, request)
+	require.NoError(t, err)
+}
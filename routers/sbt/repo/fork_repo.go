@@ -3,6 +3,7 @@ package repo
 import (
 	"code.gitea.io/gitea/models/organization"
 	repoModel "code.gitea.io/gitea/models/repo"
+	tenantModel "code.gitea.io/gitea/models/tenant"
 	userModel "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
@@ -63,6 +64,9 @@ func ForkRepo(ctx *context.Context) {
 		case repoModel.IsErrReachLimitOfRepo(err):
 			log.Debug("Can not fork repository: %s for user: %s. Repository count limit is reached.", ctx.Repo.Repository.FullName(), forker.Name)
 			ctx.JSON(http.StatusBadRequest, apiError.RepoCountLimitIsReached())
+		case tenantModel.IsErrTenantQuotaExceeded(err):
+			log.Debug("Can not fork repository: %s for user: %s. Tenant repository quota is reached.", ctx.Repo.Repository.FullName(), forker.Name)
+			ctx.JSON(http.StatusBadRequest, apiError.RepoCountLimitIsReached())
 		case repoModel.IsErrRepoAlreadyExist(err):
 			log.Debug("Can not fork repository: %s for user: %s. Repository already exists.", ctx.Repo.Repository.FullName(), forker.Name)
 			ctx.JSON(http.StatusBadRequest, apiError.RepoAlreadyExists())
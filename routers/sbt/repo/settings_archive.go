@@ -19,7 +19,7 @@ func Archive(ctx *context.Context) {
 		return
 	}
 
-	if err := repoModel.SetArchiveRepoState(ctx.Repo.Repository, true); err != nil {
+	if err := repoModel.SetArchiveRepoState(ctx, ctx.Repo.Repository, true); err != nil {
 		log.Error("An error has occurred while trying to archive repository with repoId: %d, err: %v", ctx.Repo.Repository.ID, err)
 		ctx.JSON(http.StatusInternalServerError, apiError.InternalServerError())
 		return
@@ -33,7 +33,7 @@ func Unarchive(ctx *context.Context) {
 	log := logger.Logger{}
 	log.SetTraceId(ctx)
 
-	if err := repoModel.SetArchiveRepoState(ctx.Repo.Repository, false); err != nil {
+	if err := repoModel.SetArchiveRepoState(ctx, ctx.Repo.Repository, false); err != nil {
 		log.Error("An error has occurred while trying to unarchive repository with repoId: %d, err: %v", ctx.Repo.Repository.ID, err)
 		ctx.JSON(http.StatusInternalServerError, apiError.InternalServerError())
 		return
@@ -166,7 +166,7 @@ func DeleteCollaboration(ctx *context.Context) {
 		return
 	}
 
-	if err := models.DeleteCollaboration(ctx.Repo.Repository, u.ID); err != nil {
+	if err := models.DeleteCollaboration(ctx, ctx.Repo.Repository, u.ID); err != nil {
 		log.Error("An error occurred while deleting collaboration, err: %v", err)
 		ctx.JSON(http.StatusInternalServerError, apiError.InternalServerError())
 		return
@@ -26,9 +26,9 @@ func UpdateAction(ctx *context.Context) {
 	case "unwatch":
 		err = repoModel.WatchRepo(ctx, ctx.Doer.ID, ctx.Repo.Repository.ID, false)
 	case "star":
-		err = repoModel.StarRepo(ctx.Doer.ID, ctx.Repo.Repository.ID, true)
+		err = repoService.StarRepo(ctx, ctx.Doer.ID, ctx.Repo.Repository.ID, true)
 	case "unstar":
-		err = repoModel.StarRepo(ctx.Doer.ID, ctx.Repo.Repository.ID, false)
+		err = repoService.StarRepo(ctx, ctx.Doer.ID, ctx.Repo.Repository.ID, false)
 	case "acceptTransfer":
 		err = acceptOrRejectRepoTransfer(ctx, true)
 	case "rejectTransfer":
@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+const (
+	tplAuditEvents base.TplName = "admin/audit/list"
+
+	// auditDateFormat формат дат фильтра журнала аудита
+	auditDateFormat = "2006-01-02"
+)
+
+// auditFilterFields поля фильтра журнала аудита
+var auditFilterFields = []string{"event", "username", "user_ip", "tenant", "repository", "status", "from", "to"}
+
+// parseAuditSearchOpts заполняет параметры поиска событий аудита из формы фильтра. Даты включаются в диапазон целиком
+func parseAuditSearchOpts(ctx *context.Context) *audit_model.SearchEventsOptions {
+	opts := &audit_model.SearchEventsOptions{
+		Type:       ctx.FormTrim("event"),
+		UserName:   ctx.FormTrim("username"),
+		UserIP:     ctx.FormTrim("user_ip"),
+		Tenant:     ctx.FormTrim("tenant"),
+		Repository: ctx.FormTrim("repository"),
+		Status:     ctx.FormTrim("status"),
+	}
+	if from, err := time.ParseInLocation(auditDateFormat, ctx.FormTrim("from"), setting.DefaultUILocation); err == nil {
+		opts.From = timeutil.TimeStamp(from.Unix())
+	}
+	if to, err := time.ParseInLocation(auditDateFormat, ctx.FormTrim("to"), setting.DefaultUILocation); err == nil {
+		opts.To = timeutil.TimeStamp(to.AddDate(0, 0, 1).Unix() - 1)
+	}
+	return opts
+}
+
+// auditEventParams параметры события о просмотре журнала аудита
+func auditEventParams(ctx *context.Context) map[string]string {
+	params := make(map[string]string, len(auditFilterFields))
+	for _, field := range auditFilterFields {
+		if value := ctx.FormTrim(field); value != "" {
+			params[field] = value
+		}
+	}
+	return params
+}
+
+// AuditEvents отрисовывает журнал аудита с фильтром и пагинацией
+func AuditEvents(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdminAudit"] = true
+	auditInfo := auditutils.NewRequiredAuditParams(ctx)
+	auditParams := auditEventParams(ctx)
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	opts := parseAuditSearchOpts(ctx)
+	opts.ListOptions = db.ListOptions{Page: page, PageSize: setting.UI.Admin.NoticePagingNum}
+
+	events, total, err := audit_model.SearchEvents(ctx, opts)
+	if err != nil {
+		log.Error("Error has occurred while searching audit events. Error: %v", err)
+		auditParams["error"] = "Error has occurred while searching audit events"
+		audit.CreateAndSendEvent(audit.AuditLogSearchEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		ctx.ServerError("SearchEvents", err)
+		return
+	}
+	ctx.Data["Events"] = events
+	ctx.Data["Total"] = total
+
+	query := make(url.Values)
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	for _, field := range auditFilterFields {
+		value := ctx.FormTrim(field)
+		ctx.Data["Filter_"+field] = value
+		if value != "" {
+			pager.AddParamString(field, value)
+			query.Set(field, value)
+		}
+	}
+	ctx.Data["Page"] = pager
+	ctx.Data["ExportQuery"] = query.Encode()
+
+	audit.CreateAndSendEvent(audit.AuditLogSearchEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	ctx.HTML(http.StatusOK, tplAuditEvents)
+}
+
+// ExportAuditEvents выгружает найденные события аудита в CSV
+func ExportAuditEvents(ctx *context.Context) {
+	auditInfo := auditutils.NewRequiredAuditParams(ctx)
+	auditParams := auditEventParams(ctx)
+
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if err := audit_service.WriteEventsCSV(ctx, ctx.Resp, *parseAuditSearchOpts(ctx)); err != nil {
+		log.Error("Error has occurred while exporting audit events. Error: %v", err)
+		auditParams["error"] = "Error has occurred while exporting audit events"
+		audit.CreateAndSendEvent(audit.AuditLogExportEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return
+	}
+	audit.CreateAndSendEvent(audit.AuditLogExportEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+}
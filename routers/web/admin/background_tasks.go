@@ -0,0 +1,117 @@
+package admin
+
+import (
+	gocontext "context"
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/background_task"
+)
+
+const tplBackgroundTasks base.TplName = "admin/background_tasks/list"
+
+// BackgroundTasks отрисовывает задачи очереди с фильтром по состоянию и пагинацией
+func BackgroundTasks(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.background_tasks")
+	ctx.Data["PageIsAdminBackgroundTasks"] = true
+	queues := background_task.Queues()
+	ctx.Data["Queues"] = queues
+	ctx.Data["Statuses"] = background_task.Statuses
+
+	queueName := ctx.FormTrim("queue")
+	if queueName == "" && len(queues) > 0 {
+		queueName = string(queues[0])
+	}
+	queue, err := background_task.ParseQueue(queueName)
+	if err != nil {
+		ctx.NotFound("ParseQueue", err)
+		return
+	}
+	status, err := background_task.ParseStatus(ctx.FormTrim("status"))
+	if err != nil {
+		ctx.NotFound("ParseStatus", err)
+		return
+	}
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	tasks, total, err := background_task.FindTasks(ctx, background_task.FindOptions{
+		ListOptions: db.ListOptions{Page: page, PageSize: setting.UI.Admin.NoticePagingNum},
+		Queue:       queue,
+		Status:      status,
+	})
+	if err != nil {
+		log.Error("Error has occurred while finding background tasks of queue %s. Error: %v", queue, err)
+		ctx.ServerError("FindTasks", err)
+		return
+	}
+	ctx.Data["Tasks"] = tasks
+	ctx.Data["Total"] = total
+	ctx.Data["Filter_queue"] = string(queue)
+	ctx.Data["Filter_status"] = status
+	ctx.Data["ReturnQuery"] = backgroundTasksQuery(ctx)
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	pager.AddParamString("queue", string(queue))
+	if status != "" {
+		pager.AddParamString("status", status)
+	}
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplBackgroundTasks)
+}
+
+// RetryBackgroundTask возвращает задачу в очередь
+func RetryBackgroundTask(ctx *context.Context) {
+	changeBackgroundTask(ctx, background_task.RetryTask, "admin.background_tasks.retry_success")
+}
+
+// DiscardBackgroundTask удаляет задачу из очереди
+func DiscardBackgroundTask(ctx *context.Context) {
+	changeBackgroundTask(ctx, background_task.DiscardTask, "admin.background_tasks.discard_success")
+}
+
+func changeBackgroundTask(
+	ctx *context.Context,
+	change func(ctx gocontext.Context, queue background_task.Queue, taskID int64, auditInfo auditutils.AuditRequiredParams) error,
+	successKey string,
+) {
+	queue, err := background_task.ParseQueue(ctx.Params(":queue"))
+	if err != nil {
+		ctx.NotFound("ParseQueue", err)
+		return
+	}
+	taskID := ctx.ParamsInt64(":id")
+
+	if err = change(ctx, queue, taskID, auditutils.NewRequiredAuditParams(ctx)); err != nil {
+		if !background_task.IsErrTaskNotFound(err) {
+			log.Error("Error has occurred while changing background task %d of queue %s. Error: %v", taskID, queue, err)
+		}
+		ctx.Flash.Error(ctx.Tr("admin.background_tasks.change_failed", taskID))
+	} else {
+		ctx.Flash.Success(ctx.Tr(successKey, taskID))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/background_tasks?" + backgroundTasksQuery(ctx))
+}
+
+// backgroundTasksQuery параметры фильтра для возврата к списку задач
+func backgroundTasksQuery(ctx *context.Context) string {
+	query := make(url.Values)
+	for _, field := range []string{"queue", "status", "page"} {
+		if value := ctx.FormTrim(field); value != "" {
+			query.Set(field, value)
+		}
+	}
+	if query.Get("queue") == "" {
+		query.Set("queue", ctx.Params(":queue"))
+	}
+	return query.Encode()
+}
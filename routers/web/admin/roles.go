@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/role_model"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+	role_service "code.gitea.io/gitea/services/role"
+)
+
+const (
+	tplRoles    base.TplName = "admin/role/list"
+	tplRoleEdit base.TplName = "admin/role/edit"
+)
+
+// Roles отрисовывает список ролей ролевой модели и форму создания роли
+func Roles(ctx *context.Context) {
+	ctx.Data["PageIsAdminRoles"] = true
+	ctx.Data["Title"] = ctx.Tr("admin.roles")
+
+	roles, err := role_model.GetAllRoleDefinitions(ctx)
+	if err != nil {
+		log.Error("Roles role_model.GetAllRoleDefinitions failed: %v", err)
+		ctx.ServerError("GetAllRoleDefinitions", err)
+		return
+	}
+	ctx.Data["Roles"] = roles
+	ctx.Data["Actions"] = role_model.GetAllActions()
+	ctx.HTML(http.StatusOK, tplRoles)
+}
+
+// NewRolePost создает роль
+func NewRolePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AdminRoleForm)
+	opts := role_service.CreateRoleOptions{
+		Name:         form.Name,
+		DisplayName:  form.DisplayName,
+		Actions:      form.Actions,
+		IsAssignable: form.IsAssignable,
+	}
+	if _, err := role_service.CreateRole(ctx, opts, auditutils.NewRequiredAuditParams(ctx)); err != nil {
+		log.Error("Error has occurred while creating role '%s'. Error: %v", form.Name, err)
+		flashRoleError(ctx, err)
+	} else {
+		ctx.Flash.Success(ctx.Tr("admin.roles.create_success", form.Name))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/roles")
+}
+
+// EditRole отрисовывает форму изменения роли
+func EditRole(ctx *context.Context) {
+	ctx.Data["PageIsAdminRoles"] = true
+	ctx.Data["Title"] = ctx.Tr("admin.roles.edit")
+
+	role, ok := findRole(ctx)
+	if !ok {
+		return
+	}
+	selected := make(map[string]bool)
+	for _, action := range role.GetActions() {
+		selected[action] = true
+	}
+	ctx.Data["Role"] = role
+	ctx.Data["SelectedActions"] = selected
+	ctx.Data["Actions"] = role_model.GetAllActions()
+	ctx.HTML(http.StatusOK, tplRoleEdit)
+}
+
+// EditRolePost изменяет роль
+func EditRolePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AdminRoleForm)
+	role, ok := findRole(ctx)
+	if !ok {
+		return
+	}
+
+	actions := form.Actions
+	if actions == nil {
+		actions = []string{}
+	}
+	opts := role_service.UpdateRoleOptions{
+		DisplayName:  &form.DisplayName,
+		Actions:      actions,
+		IsAssignable: &form.IsAssignable,
+	}
+	if err := role_service.UpdateRole(ctx, role, opts, auditutils.NewRequiredAuditParams(ctx)); err != nil {
+		log.Error("Error has occurred while updating role '%s'. Error: %v", role.Name, err)
+		flashRoleError(ctx, err)
+		ctx.Redirect(setting.AppSubURL + "/admin/roles/" + role.Name)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.roles.update_success", role.Name))
+	ctx.Redirect(setting.AppSubURL + "/admin/roles")
+}
+
+// DeleteRole удаляет роль
+func DeleteRole(ctx *context.Context) {
+	role, ok := findRole(ctx)
+	if !ok {
+		return
+	}
+	if err := role_service.DeleteRole(ctx, role, auditutils.NewRequiredAuditParams(ctx)); err != nil {
+		log.Error("Error has occurred while deleting role '%s'. Error: %v", role.Name, err)
+		flashRoleError(ctx, err)
+	} else {
+		ctx.Flash.Success(ctx.Tr("admin.roles.deletion_success", role.Name))
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/roles",
+	})
+}
+
+// findRole ищет роль по имени из пути запроса
+func findRole(ctx *context.Context) (*role_model.ScRole, bool) {
+	role, err := role_model.GetRoleDefinitionByName(ctx, ctx.Params(":name"))
+	if err != nil {
+		if role_model.IsErrRoleDefinitionNotExist(err) {
+			ctx.NotFound("GetRoleDefinitionByName", err)
+		} else {
+			ctx.ServerError("GetRoleDefinitionByName", err)
+		}
+		return nil, false
+	}
+	return role, true
+}
+
+// flashRoleError показывает пользователю ошибку изменения роли
+func flashRoleError(ctx *context.Context, err error) {
+	switch {
+	case role_model.IsErrRoleDefinitionAlreadyExist(err):
+		ctx.Flash.Error(ctx.Tr("admin.roles.already_exists"))
+	case role_model.IsErrRoleDefinitionInUse(err):
+		ctx.Flash.Error(ctx.Tr("admin.roles.in_use"))
+	case role_model.IsErrRoleDefinitionInvalid(err):
+		ctx.Flash.Error(ctx.Tr("admin.roles.invalid", err.Error()))
+	default:
+		ctx.Flash.Error(ctx.Tr("admin.roles.change_failed"))
+	}
+}
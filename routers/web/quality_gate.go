@@ -0,0 +1,120 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	quality_gate_service "code.gitea.io/gitea/services/quality_gate"
+)
+
+const (
+	// qualityGateTokenHeader заголовок с токеном провайдера quality gate
+	qualityGateTokenHeader = "X-Quality-Gate-Token"
+	// maxQualityGateReportSize максимальный размер тела отчета провайдера quality gate
+	maxQualityGateReportSize = 32 << 20
+)
+
+type qualityGateWebhookResponse struct {
+	Status  quality_gate.Status   `json:"status,omitempty"`
+	Metrics []qualityGateResponse `json:"metrics,omitempty"`
+	Message string                `json:"message,omitempty"`
+}
+
+type qualityGateResponse struct {
+	Key       string                `json:"key"`
+	Value     string                `json:"value"`
+	Operator  quality_gate.Operator `json:"operator,omitempty"`
+	Threshold string                `json:"threshold,omitempty"`
+	Status    quality_gate.Status   `json:"status,omitempty"`
+}
+
+// WebhookQualityGate обработчик webhook с отчетом провайдера quality gate. Провайдер передается в пути,
+// токен из настроек репозитория в заголовке X-Quality-Gate-Token, ветка, коммит и номер пулл реквеста
+// в параметрах branch, commit и pull_request
+func WebhookQualityGate(w http.ResponseWriter, r *http.Request) {
+	provider, err := quality_gate_service.GetWebhookProvider(chi.URLParam(r, "provider"))
+	if err != nil {
+		writeQualityGateResponse(w, http.StatusNotFound, qualityGateWebhookResponse{Message: err.Error()})
+		return
+	}
+
+	token := r.Header.Get(qualityGateTokenHeader)
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		writeQualityGateResponse(w, http.StatusUnauthorized, qualityGateWebhookResponse{Message: "token is required"})
+		return
+	}
+	settings, err := quality_gate.GetSettingsByToken(r.Context(), provider.Name(), token)
+	if err != nil {
+		if quality_gate.IsErrSettingsNotExist(err) {
+			writeQualityGateResponse(w, http.StatusUnauthorized, qualityGateWebhookResponse{Message: "invalid token"})
+			return
+		}
+		log.Error("WebhookQualityGate quality_gate.GetSettingsByToken while getting %s settings failed: %v", provider.Name(), err)
+		writeQualityGateResponse(w, http.StatusInternalServerError, qualityGateWebhookResponse{})
+		return
+	}
+
+	query := r.URL.Query()
+	opts := quality_gate_service.ReportOptions{
+		Branch:    query.Get("branch"),
+		CommitSHA: query.Get("commit"),
+	}
+	if pullRequest := query.Get("pull_request"); pullRequest != "" {
+		if opts.PullRequestIndex, err = strconv.ParseInt(pullRequest, 10, 64); err != nil || opts.PullRequestIndex <= 0 {
+			writeQualityGateResponse(w, http.StatusBadRequest, qualityGateWebhookResponse{Message: "pull_request must be a positive number"})
+			return
+		}
+	}
+	if opts.Branch == "" && opts.PullRequestIndex == 0 {
+		writeQualityGateResponse(w, http.StatusBadRequest, qualityGateWebhookResponse{Message: "branch or pull_request is required"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxQualityGateReportSize))
+	if err != nil {
+		log.Error("WebhookQualityGate io.ReadAll failed while read Body: %v", err)
+		writeQualityGateResponse(w, http.StatusBadRequest, qualityGateWebhookResponse{Message: "failed to read report"})
+		return
+	}
+
+	report, metrics, err := quality_gate_service.ReceiveReport(r.Context(), provider, settings, opts, body)
+	if err != nil {
+		if quality_gate_service.IsErrInvalidReport(err) {
+			writeQualityGateResponse(w, http.StatusBadRequest, qualityGateWebhookResponse{Message: err.Error()})
+			return
+		}
+		log.Error("WebhookQualityGate quality_gate_service.ReceiveReport while saving %s report for repository %d failed: %v", provider.Name(), settings.RepoID, err)
+		writeQualityGateResponse(w, http.StatusInternalServerError, qualityGateWebhookResponse{})
+		return
+	}
+
+	resp := qualityGateWebhookResponse{Status: report.Status, Metrics: make([]qualityGateResponse, 0, len(metrics))}
+	for _, metric := range metrics {
+		resp.Metrics = append(resp.Metrics, qualityGateResponse{
+			Key:       metric.Key,
+			Value:     metric.Value,
+			Operator:  metric.Operator,
+			Threshold: metric.Threshold,
+			Status:    metric.Status,
+		})
+	}
+	writeQualityGateResponse(w, http.StatusOK, resp)
+}
+
+func writeQualityGateResponse(w http.ResponseWriter, status int, resp qualityGateWebhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("WebhookQualityGate json.Encode while writing response failed: %v", err)
+	}
+}
@@ -27,7 +27,6 @@ import (
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/code_hub_counter_task"
 	"code.gitea.io/gitea/models/code_hub_counter_task/code_hub_counter_task_db"
-	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -176,7 +175,7 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 				return
 			}
 
-			allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantId, &organization.Organization{ID: owner.ID}, action)
+			allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.Doer, tenantId, &organization.Organization{ID: owner.ID}, repo, action)
 			if err != nil {
 				log.Error("Error has occurred while checking user's permissions: %v", err)
 				ctx.PlainText(http.StatusNotFound, "The repository you are trying to reach either does not exist or you are not authorized to view it.")
@@ -209,7 +208,7 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 				return
 			}
 
-			allowed, err := role_model.CheckUserPermissionToOrganization(ctx, ctx.Doer, tenantId, &organization.Organization{ID: owner.ID}, role_model.READ_PRIVATE)
+			allowed, err := role_model.CheckUserPermissionToRepository(ctx, ctx.Doer, tenantId, &organization.Organization{ID: owner.ID}, repo, role_model.READ_PRIVATE)
 			if err != nil {
 				log.Error("Error has occurred while checking user's permissions: %v", err)
 				ctx.PlainText(http.StatusNotFound, "The repository you are trying to reach either does not exist or you are not authorized to view it.")
@@ -227,6 +226,24 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		}
 	}
 
+	// Don't allow any git access to repositories of deactivated tenant
+	if repoExist && setting.SourceControl.Enabled && owner.IsOrganization() {
+		if err := tenant.CheckTenantIsActiveByOrgID(ctx, owner.ID); err != nil {
+			if tenant.IsTenantNotActive(err) {
+				ctx.PlainText(http.StatusForbidden, "This repo belongs to deactivated tenant.")
+				doerName, doerID := audit.EmptyRequiredField, audit.EmptyRequiredField
+				if ctx.Doer != nil {
+					doerName, doerID = ctx.Doer.Name, strconv.FormatInt(ctx.Doer.ID, 10)
+				}
+				auditParams["error"] = "Tenant is not active"
+				audit.CreateAndSendEvent(audit.TenantAccessDeniedEvent, doerName, doerID, audit.StatusFailure, ctx.Req.RemoteAddr, auditParams)
+				return
+			}
+			ctx.ServerError("CheckTenantIsActiveByOrgID", err)
+			return
+		}
+	}
+
 	// Don't allow pushing if the repo is archived
 	if repoExist && repo.IsArchived && !isPull {
 		ctx.PlainText(http.StatusForbidden, "This repo is archived. You can view files and clone it, but cannot push or open Issues/pull-requests.")
@@ -815,8 +832,7 @@ func GetInfoRefs(ctx *context.Context) {
 	}
 
 	if ctx.Doer != nil {
-		dbEngine := db.GetEngine(ctx)
-		taskDB := code_hub_counter_task_db.New(dbEngine)
+		taskDB := code_hub_counter_task_db.New()
 		taskCreator := code_hub_counter.NewTaskCreator(taskDB, setting.CodeHub.CodeHubMetricEnabled)
 		if err = taskCreator.CreateByRepoNameOwner(ctx, repo, owner, ctx.Doer.ID, code_hub_counter_task.CloneRepositoryAction); err != nil {
 			log.Error("error has occurred while while inserting repository task: %v", err)
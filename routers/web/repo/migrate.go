@@ -128,6 +128,9 @@ func handleMigrateError(ctx *context.Context, owner *user_model.User, err error,
 		msg := ctx.TrN(maxCreationLimit, "repo.form.reach_limit_of_creation_1", "repo.form.reach_limit_of_creation_n", maxCreationLimit)
 		auditParams["error"] = "Reached limit of repositories"
 		ctx.RenderWithErr(msg, tpl, form)
+	case tenant.IsErrTenantQuotaExceeded(err):
+		auditParams["error"] = "Tenant repository quota exceeded"
+		ctx.RenderWithErr(ctx.Tr("repo.form.tenant_repo_quota_exceeded"), tpl, form)
 	case repo_model.IsErrCreateUserRepo(err):
 		auditParams["error"] = "Creating a repository outside the project is prohibited"
 		ctx.RenderWithErr(ctx.Tr("repo.create_user_repo_not_allowed"), tpl, form)
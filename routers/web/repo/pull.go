@@ -363,6 +363,9 @@ func ForkPost(ctx *context.Context) {
 			msg := ctx.TrN(maxCreationLimit, "repo.form.reach_limit_of_creation_1", "repo.form.reach_limit_of_creation_n", maxCreationLimit)
 			auditParams["error"] = "Reached limit of repositories"
 			ctx.RenderWithErr(msg, tplFork, &form)
+		case tenant.IsErrTenantQuotaExceeded(err):
+			auditParams["error"] = "Tenant repository quota exceeded"
+			ctx.RenderWithErr(ctx.Tr("repo.form.tenant_repo_quota_exceeded"), tplFork, &form)
 		case repo_model.IsErrCreateUserRepo(err):
 			ctx.RenderWithErr(ctx.Tr("repo.create_user_repo_not_allowed"), tplFork, &form)
 		case repo_model.IsErrRepoAlreadyExist(err):
@@ -255,6 +255,9 @@ func handleCreateError(ctx *context.Context, owner *user_model.User, err error,
 		msg := ctx.TrN(maxCreationLimit, "repo.form.reach_limit_of_creation_1", "repo.form.reach_limit_of_creation_n", maxCreationLimit)
 		ctx.RenderWithErr(msg, tpl, form)
 		auditParams["error"] = "Reached limit of repositories"
+	case tenant.IsErrTenantQuotaExceeded(err):
+		ctx.RenderWithErr(ctx.Tr("repo.form.tenant_repo_quota_exceeded"), tpl, form)
+		auditParams["error"] = "Tenant repository quota exceeded"
 	case repo_model.IsErrRepoAlreadyExist(err):
 		ctx.Data["Err_RepoName"] = true
 		ctx.RenderWithErr(ctx.Tr("form.repo_name_been_taken"), tpl, form)
@@ -515,9 +518,9 @@ func Action(ctx *context.Context) {
 	case "unwatch":
 		err = repo_model.WatchRepo(ctx, ctx.Doer.ID, ctx.Repo.Repository.ID, false)
 	case "star":
-		err = repo_model.StarRepo(ctx.Doer.ID, ctx.Repo.Repository.ID, true)
+		err = repo_service.StarRepo(ctx, ctx.Doer.ID, ctx.Repo.Repository.ID, true)
 	case "unstar":
-		err = repo_model.StarRepo(ctx.Doer.ID, ctx.Repo.Repository.ID, false)
+		err = repo_service.StarRepo(ctx, ctx.Doer.ID, ctx.Repo.Repository.ID, false)
 	case "accept_transfer":
 		err = acceptOrRejectRepoTransfer(ctx, true)
 	case "reject_transfer":
@@ -654,7 +657,7 @@ func (r RepoServer) download(ctx *context.Context, archiveName string, archiver
 	defer fr.Close()
 
 	if ctx.Doer != nil {
-		if err = r.taskCreator.Create(*ctx, ctx.Repo.Repository.ID, ctx.Doer.ID, code_hub_counter_task.CloneRepositoryAction); err != nil {
+		if err = r.taskCreator.Create(*ctx, ctx.Repo.Repository.ID, ctx.Doer.ID, code_hub_counter_task.DownloadArchiveAction); err != nil {
 			log.Error("error has occurred while while inserting repository task: %v", err)
 		}
 	}
@@ -1229,7 +1229,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		if err := repo_model.SetArchiveRepoState(repo, true); err != nil {
+		if err := repo_model.SetArchiveRepoState(ctx, repo, true); err != nil {
 			log.Error("Tried to archive a repo: %s", err)
 			ctx.Flash.Error(ctx.Tr("repo.settings.archive.error"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
@@ -1247,7 +1247,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		if err := repo_model.SetArchiveRepoState(repo, false); err != nil {
+		if err := repo_model.SetArchiveRepoState(ctx, repo, false); err != nil {
 			log.Error("Tried to unarchive a repo: %s", err)
 			ctx.Flash.Error(ctx.Tr("repo.settings.unarchive.error"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
@@ -1453,7 +1453,7 @@ func DeleteCollaboration(ctx *context.Context) {
 		"repository_id":    strconv.FormatInt(ctx.Repo.Repository.ID, 10),
 		"affected_user_id": strconv.FormatInt(ctx.FormInt64("id"), 10),
 	}
-	if err := models.DeleteCollaboration(ctx.Repo.Repository, ctx.FormInt64("id")); err != nil {
+	if err := models.DeleteCollaboration(ctx, ctx.Repo.Repository, ctx.FormInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 
 		auditParams["error"] = "Error has occurred while deleting collaboration"
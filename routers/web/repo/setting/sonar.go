@@ -31,7 +31,7 @@ func GetSonarSettings(ctx *context.Context) {
 	if settings != nil {
 		ctx.Data["Sonar"] = settings
 	} else { //кидать в этом случае ошибку не нужно, просто отдадим пустые значения
-		ctx.Data["Sonar"] = repo.ScSonarSettings{Token: "", ProjectKey: ""}
+		ctx.Data["Sonar"] = &repo.ScSonarSettings{ProjectKey: ""}
 	}
 
 	ctx.HTML(http.StatusOK, tplSonarSettings)
@@ -45,7 +45,7 @@ func PostSonarSettings(ctx *context.Context) {
 		return
 	}
 
-	err := repo.InsertOrUpdateSonarSettings(ctx.Repo.Repository.ID, settings.URL, settings.Token, settings.ProjectKey)
+	err := repo.InsertOrUpdateSonarSettings(ctx.Repo.Repository.ID, settings.URL, settings.Token, settings.ProjectKey, settings.WebhookSecret)
 	if err != nil {
 		log.Error("Error has occurred while try insert or update sonar settings of repo id: %d, error: %v", ctx.Repo.Repository.ID, err)
 		ctx.Error(http.StatusInternalServerError)
@@ -88,7 +88,7 @@ func PostSonarSettings(ctx *context.Context) {
 				projectStatusIDsWithSonarMetrics[sonarMetric.SonarProjectStatusID] = append(projectStatusIDsWithSonarMetrics[sonarMetric.SonarProjectStatusID], conditionMetricsSonar)
 			}
 		}
-		errControlWebHookSonar := webhook.ControllerWebHook(projectStatusIDsWithSonarMetrics[sonarProject.ID], sonarSettings, &sonarProject)
+		errControlWebHookSonar := webhook.ControllerWebHook(ctx, projectStatusIDsWithSonarMetrics[sonarProject.ID], sonarSettings, &sonarProject, nil)
 		if errControlWebHookSonar != nil {
 			log.Error("Error has occurred while adding or updating sonar metrics for project_key %s: %v", sonarSettings.ProjectKey, err)
 			ctx.Error(http.StatusInternalServerError)
@@ -7,70 +7,70 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/google/uuid"
+	"time"
 
-	"code.gitea.io/gitea/models/db"
-	repo_model "code.gitea.io/gitea/models/repo"
-	sonar_model "code.gitea.io/gitea/models/sonar/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/webhook_sonar"
 	"code.gitea.io/gitea/services/forms"
+	quality_gate_service "code.gitea.io/gitea/services/quality_gate"
 	"code.gitea.io/gitea/services/webhook"
 )
 
-// WebhookSonarQube обработчик для webhook из sonarQube
+// WebhookSonarQube обработчик для webhook из sonarQube. Webhook принимается только от сервера из настроек Sonar
+// репозитория с подписью тела секретом этих настроек в заголовке X-Sonar-Webhook-HMAC-SHA256
 func WebhookSonarQube(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	res := &webhook_sonar.WebHook{}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.Error("WebhookSonarQube io.ReadAll failed while read Body: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 	err = json.Unmarshal(body, res)
 	if err != nil {
 		log.Error("WebhookSonarQube json.Unmarshal while unmarshal body failed: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	// добавляем проект из sonarQube в бд
-	sonarProject, err := webhook.AddSonarProjectStatus(res)
+	// проверяем сервер, подпись и время анализа до сохранения результатов
+	sonarSettings, err := webhook.VerifySonarWebhook(r.Context(), body, r.Header.Get(webhook.SonarWebhookSignatureHeader), res, time.Now())
 	if err != nil {
-		log.Error("WebhookSonarQube webhook.AddSonarProjectStatus while adding sonar_project_status failed: %v", err)
+		if webhook.IsErrSonarWebhookRejected(err) {
+			log.Warn("WebhookSonarQube webhook.VerifySonarWebhook rejected webhook: %v", err)
+			auditParams := map[string]string{
+				"project_key": res.Project.Key,
+				"server_url":  res.ServerUrl,
+				"reason":      err.(webhook.ErrSonarWebhookRejected).Reason,
+			}
+			audit.CreateAndSendEvent(audit.SonarWebhookRejectEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, r.RemoteAddr, auditParams)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		log.Error("WebhookSonarQube webhook.VerifySonarWebhook while getting sonar settings for project %s failed: %v", res.Project.Key, err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	// получаем настройки для sonarQube
-	sonarSettings, err := repo_model.GetSonarSettingsByProjectKeyAndServerUrl(sonarProject.ProjectKey, sonarProject.SonarServer)
+	// добавляем проект из sonarQube в бд
+	sonarProject, err := webhook.AddSonarProjectStatus(r.Context(), res)
 	if err != nil {
-		log.Error("WebhookSonarQube webhook.AddSonarProjectStatus while getting sonar settings for repository failed: %v", err)
+		log.Error("WebhookSonarQube webhook.AddSonarProjectStatus while adding sonar_project_status failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	if sonarSettings == nil {
-		sonarMetrics := make([]sonar_model.ScSonarProjectMetrics, len(res.QualityGate.Conditions))
-		for idx, condition := range res.QualityGate.Conditions {
-			sonarMetricsEntity := sonar_model.ScSonarProjectMetrics{
-				ID:                   uuid.NewString(),
-				SonarProjectStatusID: sonarProject.ID,
-				Key:                  condition.Metric,
-				Value:                condition.Value,
-				IsQualityGate:        true,
-			}
-			sonarMetrics[idx] = sonarMetricsEntity
-		}
-		errUpsertSonarMetrics := sonar_model.UpsertSonarMetrics(db.DefaultContext, sonarMetrics)
-		if errUpsertSonarMetrics != nil {
-			log.Error("WebhookSonarQube failed sonar_model.UpsertSonarMetrics while updating or adding sonar metrics failed: %v", errUpsertSonarMetrics)
-			return
-		}
-		return
+	// сохраняем результат quality gate для проверки слияния пулл реквестов
+	if err = quality_gate_service.SaveSonarReport(r.Context(), sonarSettings.RepoID, res); err != nil {
+		log.Error("WebhookSonarQube quality_gate_service.SaveSonarReport while saving quality gate report failed: %v", err)
 	}
 	conditions := make([]webhook_sonar.SonarConditions, len(res.QualityGate.Conditions))
 	for idx, condition := range res.QualityGate.Conditions {
 		conditions[idx] = condition
 	}
 	// обрабатываем метрики из sonarQube
-	err = webhook.ControllerWebHook(conditions, sonarSettings, sonarProject)
+	err = webhook.ControllerWebHook(r.Context(), conditions, sonarSettings, sonarProject, res)
 	if err != nil {
 		log.Error("WebhookSonarQube webhook.ControllerWebHook while working with metrics for sonarqube failed: %v", err)
 		return
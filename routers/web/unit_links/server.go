@@ -21,23 +21,19 @@ type unitLinksDB interface {
 	GetUnitLinks(ctx goCtx.Context, pullRequestID int64) (unit_links.AllUnitLinks, error)
 }
 
-type taskTrackerClient interface {
-	GetDescriptions(
-		ctx goCtx.Context,
-		codes []gitnames.UnitCode,
-	) (task_tracker_client.GetDescriptionsResponse, error)
+type taskTrackerResolver interface {
+	ResolveByPullRequestID(ctx goCtx.Context, pullRequestID int64) (task_tracker_client.Tracker, error)
 }
 
 type Server struct {
 	unitLinksDB
-	taskTrackerClient
+	taskTrackerResolver
 
-	unitBaseURL    string
 	iamUnitBaseURL string
 }
 
-func NewServer(unitLinksDB unitLinksDB, client taskTrackerClient, unitBaseURL, iamUnitBaseURL string) Server {
-	return Server{unitLinksDB: unitLinksDB, taskTrackerClient: client, unitBaseURL: unitBaseURL, iamUnitBaseURL: iamUnitBaseURL}
+func NewServer(unitLinksDB unitLinksDB, resolver taskTrackerResolver, iamUnitBaseURL string) Server {
+	return Server{unitLinksDB: unitLinksDB, taskTrackerResolver: resolver, iamUnitBaseURL: iamUnitBaseURL}
 }
 
 func (s Server) GetUnitLinksWithDescription(ctx *context.Context) {
@@ -106,7 +102,18 @@ func (s Server) GetUnitLinksWithDescription(ctx *context.Context) {
 		codes = append(codes, gitnames.UnitCode{Code: unitLink.ToUnitID})
 	}
 
-	getDescriptionResponse, err := s.taskTrackerClient.GetDescriptions(ctx, codes)
+	tracker, err := s.taskTrackerResolver.ResolveByPullRequestID(ctx, pullRequestID)
+	if err != nil {
+		errDescription := fmt.Sprintf("resolve task tracker: %d, error: %s", pullRequestID, err.Error())
+		log.Error("resolve task tracker: %s", errDescription)
+		ctx.JSON(http.StatusInternalServerError, errDescription)
+		auditParams["error"] = "Error has occurred while resolving task tracker"
+		audit.CreateAndSendEvent(audit.UnitLinksRequestCreateEvent, doerName, doerID, audit.StatusFailure, remoteAddress, auditParams)
+
+		return
+	}
+
+	getDescriptionResponse, err := tracker.Provider.GetDescriptions(ctx, codes)
 	if err != nil {
 		errDescription := fmt.Sprintf("get description http request: %d, error: %s", pullRequestID, err.Error())
 		log.Error("http call: %s", errDescription)
@@ -116,7 +123,7 @@ func (s Server) GetUnitLinksWithDescription(ctx *context.Context) {
 
 		return
 	}
-	response, err = s.enrichUnitLinksWithDescription(codes, getDescriptionResponse)
+	response, err = s.enrichUnitLinksWithDescription(tracker, codes, getDescriptionResponse)
 	if err != nil {
 		errDescription := fmt.Sprintf("enrich unit links: %s", err.Error())
 		log.Error(errDescription)
@@ -137,6 +144,7 @@ const (
 )
 
 func (s Server) enrichUnitLinksWithDescription(
+	tracker task_tracker_client.Tracker,
 	codes []gitnames.UnitCode,
 	model task_tracker_client.GetDescriptionsResponse,
 ) (structs.GetUnitLinksWithDescriptionResponse, error) {
@@ -156,7 +164,7 @@ func (s Server) enrichUnitLinksWithDescription(
 			continue
 		}
 
-		converted, err := s.convertTaskTrackerModel(resp)
+		converted, err := s.convertTaskTrackerModel(tracker, resp)
 		if err != nil {
 			errDescription := fmt.Sprintf("%s: %s", descriptionInconsistentStatus, err.Error())
 			unitErr := structs.GetDescriptionError{Code: code.Code, Description: errDescription}
@@ -172,10 +180,11 @@ func (s Server) enrichUnitLinksWithDescription(
 }
 
 func (s Server) convertTaskTrackerModel(
+	tracker task_tracker_client.Tracker,
 	unitContent task_tracker_client.GetDescriptionsContent,
 ) (structs.GetDescriptionUnit, error) {
-	url := s.unitBaseURL
-	if setting.IAM.Enabled && setting.OneWork.Enabled {
+	url := tracker.UnitBaseURL
+	if tracker.Name == setting.DefaultTaskTrackerProviderName && setting.IAM.Enabled && setting.OneWork.Enabled {
 		url = s.iamUnitBaseURL
 	}
 
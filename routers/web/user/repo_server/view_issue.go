@@ -658,6 +658,22 @@ func (s *Server) ViewIssue(ctx *context.Context) {
 			ctx.Data["ChangedProtectedFilesNum"] = len(pull.ChangedProtectedFiles)
 			ctx.Data["ShowMergeInstructions"] = showMergeInstructions
 		}
+		var unitStatusBlockReason string
+		for _, rs := range reviewSettings {
+			if unitStatusBlockReason = pull_service.MergeBlockedByUnitStatusCached(ctx, rs, pull); unitStatusBlockReason != "" {
+				break
+			}
+		}
+		ctx.Data["IsBlockedByUnitStatus"] = unitStatusBlockReason != ""
+		ctx.Data["UnitStatusBlockReason"] = unitStatusBlockReason
+		var qualityGateBlockReason string
+		for _, rs := range reviewSettings {
+			if qualityGateBlockReason = pull_service.MergeBlockedByQualityGates(ctx, rs, pull); qualityGateBlockReason != "" {
+				break
+			}
+		}
+		ctx.Data["IsBlockedByQualityGate"] = qualityGateBlockReason != ""
+		ctx.Data["QualityGateBlockReason"] = qualityGateBlockReason
 		conditions, _ := reviewSetting.GetRequiredReviewConditions(ctx, pull.BaseRepoID, pull)
 		ctx.Data["DefaultReviewersRulesCheck"] = conditions
 		ctx.Data["WillSign"] = false
@@ -5,7 +5,6 @@ package web
 
 import (
 	gocontext "context"
-	"crypto/tls"
 	"net/http"
 
 	"code.gitea.io/gitea/models/default_reviewers/default_reviewers_db"
@@ -35,14 +34,13 @@ import (
 	"code.gitea.io/gitea/models/user/user_db"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/gitnamesparser/branch"
+	"code.gitea.io/gitea/modules/gitnamesparser/commit"
 	"code.gitea.io/gitea/modules/gitnamesparser/pullrequest"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/metrics"
-	"code.gitea.io/gitea/modules/mtls"
 	"code.gitea.io/gitea/modules/public"
 	_ "code.gitea.io/gitea/modules/session" // to registers all internal adapters
 	"code.gitea.io/gitea/modules/setting"
-	setting_mtls "code.gitea.io/gitea/modules/setting/mtls"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/templates"
@@ -168,7 +166,8 @@ func Routes(ctx gocontext.Context) *web.Route {
 	routes := web.NewRoute()
 
 	routes.Head("/", misc.DummyOK) // for health check - doesn't need to be passed through gzip handler
-	routes.RouteMethods("/webhooks/sonarqube", "GET, POST", WebhookSonarQube)
+	routes.Post("/webhooks/sonarqube", WebhookSonarQube)
+	routes.Post("/webhooks/quality_gates/{provider}", WebhookQualityGate)
 	routes.RouteMethods("/assets/*", "GET, HEAD", CorsHandler(), public.AssetsHandlerFunc("/assets/"))
 	routes.RouteMethods("/avatars/*", "GET, HEAD", storageHandler(setting.Avatar.Storage, "avatars", storage.Avatars))
 	routes.RouteMethods("/repo-avatars/*", "GET, HEAD", storageHandler(setting.RepoAvatar.Storage, "repo-avatars", storage.RepoAvatars))
@@ -468,7 +467,7 @@ func registerRoutes(m *web.Route) {
 	customDB := custom.NewCustomDB(dbEngine)
 
 	branchesServer := repo.New(casbinManager, customDB, teamServer)
-	taskDB := code_hub_counter_task_db.New(dbEngine)
+	taskDB := code_hub_counter_task_db.New()
 	taskCreator := code_hub_counter.NewTaskCreator(taskDB, setting.CodeHub.CodeHubMetricEnabled)
 	processedMarks := []repo_marks.RepoMark{codeHubMark}
 	repoServerCodeHub := repo.NewRepoServer(taskCreator, repoMarksDB, processedMarks, setting.CodeHub.CodeHubMarkEnabled)
@@ -794,6 +793,33 @@ func registerRoutes(m *web.Route) {
 				})
 			})
 		}, reqOneWork, disableFunctionalByMultiTenantsDisabled())
+		m.Group("/roles", func() {
+			m.Get("", admin.Roles)
+			m.Post("/new", web.Bind(forms.AdminRoleForm{}), admin.NewRolePost)
+			m.Group("/{name}", func() {
+				m.Combo("").Get(admin.EditRole).Post(web.Bind(forms.AdminRoleForm{}), admin.EditRolePost)
+				m.Post("/delete", admin.DeleteRole)
+			})
+		}, func(ctx *context.Context) {
+			if !setting.SourceControl.TenantWithRoleModeEnabled {
+				ctx.NotFound("", nil)
+				return
+			}
+		})
+		m.Group("/background_tasks", func() {
+			m.Get("", admin.BackgroundTasks)
+			m.Post("/{queue}/{id}/retry", admin.RetryBackgroundTask)
+			m.Post("/{queue}/{id}/discard", admin.DiscardBackgroundTask)
+		})
+		m.Group("/audit", func() {
+			m.Get("", admin.AuditEvents)
+			m.Get("/export", admin.ExportAuditEvents)
+		}, func(ctx *context.Context) {
+			if !setting.AuditSinks.Database.Enabled {
+				ctx.NotFound("", nil)
+				return
+			}
+		})
 		m.Group("/git_hooks/", func() {
 			m.Combo("pre-receive").
 				Get(admin.PreReceiveHook).
@@ -806,35 +832,31 @@ func registerRoutes(m *web.Route) {
 	//TODO: dependency initialization must be moved to upper level https://sberworks.ru/jira/browse/VCS-1300
 
 	branchParser := branch.NewParser()
+	commitParser := commit.NewParser()
 	pullRequestParser := pullrequest.NewParser()
 	unitLinkDB := unit_links_db.NewUnitLinkDB(dbEngine)
 	taskTrackerDb := task_tracker_db.NewTaskTrackerDB(dbEngine)
 	pullRequestHeaderDB := pull_request_reader.NewReader(dbEngine)
 
-	mtlsConfig := &tls.Config{} // initialize tls config with default values
-	if setting_mtls.CheckMTLSConfigSecManEnabled(task_tracker_client.TaskTrackerClientName) {
-		mtlsCerts := setting_mtls.GetMTLSCertsFromSecMan(task_tracker_client.TaskTrackerClientName, setting.NewGetterForSecMan())
-		mtlsConfig = mtls.GenerateTlsConfigForMTLS(task_tracker_client.TaskTrackerClientName, mtlsCerts.Cert, mtlsCerts.CertKey, mtlsCerts.CaCerts)
+	taskTrackerResolver, err := task_tracker_client.NewResolverFromSettings()
+	if err != nil {
+		log.Fatal("Error has occurred while creating task tracker providers. Error: %v", err)
 	}
-	taskTrackerClient := task_tracker_client.New(
-		setting.TaskTracker.APIBaseURL,
-		setting.TaskTracker.APIToken,
-		&http.Client{Transport: &http.Transport{TLSClientConfig: mtlsConfig}},
-	)
 
 	pullRequestTaskCreator := pull_request_task_creator.NewPullRequestTaskCreator(taskTrackerDb)
 	unitLinker := unit_linker.NewUnitLinker(
 		branchParser,
+		commitParser,
 		pullRequestParser,
 		unitLinkDB,
 		pullRequestHeaderDB,
-		taskTrackerClient,
+		taskTrackerResolver,
 		setting.TaskTracker.UnitsValidationEnabled,
 	)
 
 	pullRequestsServer := pulls.NewServer(unitLinker, pullRequestTaskCreator, setting.TaskTracker.Enabled)
 	issuesServer := issues.NewServer(unitLinker, pullRequestTaskCreator, setting.TaskTracker.Enabled)
-	unitLinksServer := unit_links.NewServer(unitLinkDB, taskTrackerClient, setting.TaskTracker.UnitBaseURL, setting.TaskTracker.IAMUnitBaseURL)
+	unitLinksServer := unit_links.NewServer(unitLinkDB, taskTrackerResolver, setting.TaskTracker.IAMUnitBaseURL)
 
 	defaultReviewersDB := default_reviewers_db.New(dbEngine)
 	reviewSettingsDB := review_settings_db.New(dbEngine)
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	audit_sinks "code.gitea.io/gitea/modules/sbt/audit/sinks"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// exportPageSize количество событий, читаемых из БД за один запрос при выгрузке в CSV
+const exportPageSize = 500
+
+// dbTransport сохранение событий аудита в БД
+type dbTransport struct{}
+
+// Send сохраняет пачку событий аудита. События, которые не удалось разобрать, пропускаются
+func (dbTransport) Send(ctx context.Context, records [][]byte) error {
+	events := make([]*audit_model.Event, 0, len(records))
+	for _, record := range records {
+		event, err := audit_model.ParseEvent(record)
+		if err != nil {
+			log.Error("Error has occurred while parsing audit event for database. Event: %s. Error: %v", string(record), err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return audit_model.InsertEvents(ctx, events)
+}
+
+// Close ничего не делает, соединение с БД управляется движком
+func (dbTransport) Close() error {
+	return nil
+}
+
+// Init запускает сохранение событий аудита в БД, если оно включено в конфигурации
+func Init(ctx context.Context) error {
+	if !setting.AuditSinks.Database.Enabled {
+		return nil
+	}
+	return audit_sinks.Register("database", setting.AuditSinks.Database.BatchSize, dbTransport{})
+}
+
+// DeleteExpiredEvents удаляет события аудита старше срока хранения
+func DeleteExpiredEvents(ctx context.Context) error {
+	if setting.AuditSinks.Database.Retention <= 0 {
+		return nil
+	}
+	before := timeutil.TimeStamp(time.Now().Add(-setting.AuditSinks.Database.Retention).Unix())
+	deleted, err := audit_model.DeleteEventsBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+	log.Info("Deleted %d audit events older than %s", deleted, before.FormatLong())
+	return nil
+}
+
+// WriteEventsCSV выгружает все найденные события аудита в CSV, параметры пагинации opts не учитываются
+func WriteEventsCSV(ctx context.Context, w io.Writer, opts audit_model.SearchEventsOptions) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "event", "eventdate", "status", "username", "internal_id", "user_ip", "tenant", "repository", "host.name", "host.ip", "params"}); err != nil {
+		return err
+	}
+
+	// новые события не должны сдвигать страницы во время выгрузки
+	if opts.To == 0 {
+		opts.To = timeutil.TimeStampNow()
+	}
+	opts.ListOptions = db.ListOptions{Page: 1, PageSize: exportPageSize}
+	for {
+		events, _, err := audit_model.SearchEvents(ctx, &opts)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			params, err := marshalParams(event.Params)
+			if err != nil {
+				return err
+			}
+			if err = writeCSVRecord(writer, []string{
+				event.EventID,
+				event.Type,
+				event.EventUnix.AsTime().UTC().Format(time.RFC3339),
+				event.Status,
+				event.UserName,
+				event.UserID,
+				event.UserIP,
+				event.Tenant,
+				event.Repository,
+				event.HostName,
+				event.HostIP,
+				params,
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		if err = writer.Error(); err != nil {
+			return err
+		}
+		if len(events) < exportPageSize {
+			return nil
+		}
+		opts.Page++
+	}
+}
+
+// writeCSVRecord записывает строку CSV, экранируя ячейки, которые табличные редакторы выполнят как формулу
+func writeCSVRecord(writer *csv.Writer, record []string) error {
+	for idx, cell := range record {
+		record[idx] = escapeCSVCell(cell)
+	}
+	return writer.Write(record)
+}
+
+// escapeCSVCell добавляет апостроф перед значением, начинающимся с символа формулы (=, +, -, @, табуляция, возврат каретки)
+func escapeCSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// marshalParams сериализует параметры события, ключи сортируются, поэтому выгрузка стабильна
+func marshalParams(params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("marshal audit event params: %w", err)
+	}
+	return string(data), nil
+}
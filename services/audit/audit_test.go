@@ -0,0 +1,18 @@
+//go:build !correct
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeCSVCell(t *testing.T) {
+	assert.Equal(t, "'=HYPERLINK(\"http://evil\")", escapeCSVCell(`=HYPERLINK("http://evil")`))
+	assert.Equal(t, "'+1", escapeCSVCell("+1"))
+	assert.Equal(t, "'-1", escapeCSVCell("-1"))
+	assert.Equal(t, "'@SUM(A1)", escapeCSVCell("@SUM(A1)"))
+	assert.Equal(t, "org/repo", escapeCSVCell("org/repo"))
+	assert.Equal(t, "", escapeCSVCell(""))
+}
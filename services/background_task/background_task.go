@@ -0,0 +1,169 @@
+package background_task
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	background_task_model "code.gitea.io/gitea/models/background_task"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+)
+
+// Queue имя очереди фоновых задач
+type Queue string
+
+// Queues возвращает очереди фоновых задач, доступные для администрирования, то есть все очереди,
+// созданные background_task.NewQueue
+func Queues() []Queue {
+	names := background_task_model.QueueNames()
+	queues := make([]Queue, 0, len(names))
+	for _, name := range names {
+		queues = append(queues, Queue(name))
+	}
+	return queues
+}
+
+// Statuses состояния фоновых задач
+var Statuses = func() []string {
+	statuses := make([]string, 0, len(background_task_model.Statuses))
+	for _, status := range background_task_model.Statuses {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}()
+
+// ErrUnknownQueue очередь фоновых задач не существует
+type ErrUnknownQueue struct {
+	Queue string
+}
+
+func (e ErrUnknownQueue) Error() string {
+	return fmt.Sprintf("unknown background task queue: %s", e.Queue)
+}
+
+// IsErrUnknownQueue проверяет, что ошибка ErrUnknownQueue
+func IsErrUnknownQueue(err error) bool {
+	_, ok := err.(ErrUnknownQueue)
+	return ok
+}
+
+// ErrUnknownStatus состояние фоновой задачи не существует
+type ErrUnknownStatus struct {
+	Status string
+}
+
+func (e ErrUnknownStatus) Error() string {
+	return fmt.Sprintf("unknown background task status: %s", e.Status)
+}
+
+// IsErrUnknownStatus проверяет, что ошибка ErrUnknownStatus
+func IsErrUnknownStatus(err error) bool {
+	_, ok := err.(ErrUnknownStatus)
+	return ok
+}
+
+// ErrTaskNotFound задача не найдена или сейчас обрабатывается
+type ErrTaskNotFound struct {
+	Queue Queue
+	ID    int64
+}
+
+func (e ErrTaskNotFound) Error() string {
+	return fmt.Sprintf("background task %d of queue %s is not found or is being processed", e.ID, e.Queue)
+}
+
+// IsErrTaskNotFound проверяет, что ошибка ErrTaskNotFound
+func IsErrTaskNotFound(err error) bool {
+	_, ok := err.(ErrTaskNotFound)
+	return ok
+}
+
+// Task фоновая задача очереди для администрирования
+type Task = background_task_model.Task
+
+// FindOptions параметры поиска задач очереди, пустой Status означает задачи в любом состоянии
+type FindOptions struct {
+	db.ListOptions
+	Queue  Queue
+	Status string
+}
+
+// ParseQueue проверяет имя очереди фоновых задач
+func ParseQueue(name string) (Queue, error) {
+	for _, queue := range Queues() {
+		if string(queue) == name {
+			return queue, nil
+		}
+	}
+	return "", ErrUnknownQueue{Queue: name}
+}
+
+// ParseStatus проверяет состояние фоновой задачи, пустое состояние допустимо
+func ParseStatus(status string) (string, error) {
+	if status == "" {
+		return "", nil
+	}
+	for _, known := range Statuses {
+		if known == status {
+			return status, nil
+		}
+	}
+	return "", ErrUnknownStatus{Status: status}
+}
+
+// FindTasks возвращает страницу задач очереди, начиная с последних, и общее количество найденных задач
+func FindTasks(ctx context.Context, opts FindOptions) ([]*Task, int64, error) {
+	if _, err := ParseQueue(string(opts.Queue)); err != nil {
+		return nil, 0, err
+	}
+	return background_task_model.Find(ctx, background_task_model.FindOptions{
+		ListOptions: opts.ListOptions,
+		Queue:       string(opts.Queue),
+		Status:      background_task_model.Status(opts.Status),
+	})
+}
+
+// RetryTask возвращает ожидающую или dead задачу в очередь для немедленной обработки со сброшенным количеством попыток
+func RetryTask(ctx context.Context, queue Queue, taskID int64, auditInfo auditutils.AuditRequiredParams) error {
+	return changeTask(ctx, queue, taskID, audit.BackgroundTaskRetryEvent, auditInfo, func(ctx context.Context) (bool, error) {
+		return background_task_model.Retry(ctx, string(queue), taskID)
+	})
+}
+
+// DiscardTask удаляет задачу из очереди, если она не обрабатывается
+func DiscardTask(ctx context.Context, queue Queue, taskID int64, auditInfo auditutils.AuditRequiredParams) error {
+	return changeTask(ctx, queue, taskID, audit.BackgroundTaskDiscardEvent, auditInfo, func(ctx context.Context) (bool, error) {
+		return background_task_model.Discard(ctx, string(queue), taskID)
+	})
+}
+
+func changeTask(
+	ctx context.Context,
+	queue Queue,
+	taskID int64,
+	event audit.Event,
+	auditInfo auditutils.AuditRequiredParams,
+	change func(ctx context.Context) (bool, error),
+) error {
+	auditParams := map[string]string{
+		"queue":   string(queue),
+		"task_id": strconv.FormatInt(taskID, 10),
+	}
+
+	changed, err := change(ctx)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while changing background task"
+		audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return err
+	}
+	if !changed {
+		auditParams["error"] = "Background task is not found or is being processed"
+		audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return ErrTaskNotFound{Queue: queue, ID: taskID}
+	}
+
+	audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
@@ -0,0 +1,39 @@
+package background_task
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Результаты обработки задач в метрике обработки
+const (
+	resultSucceeded = "succeeded"
+	resultFailed    = "failed"    // попытка не удалась, задача будет обработана повторно
+	resultDead      = "dead"      // задача переведена в состояние dead
+	resultRecovered = "recovered" // аренда задачи истекла, задача возвращена в очередь
+)
+
+var (
+	tasksProcessed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sourcecontrol_background_tasks_processed_total",
+			Help: "Number of processed background tasks by queue and result: succeeded, failed (will be retried), dead, recovered (lease expired)",
+		},
+		[]string{"queue", "result"},
+	)
+	taskDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sourcecontrol_background_task_duration_seconds",
+			Help:    "Duration of background task processing attempts by queue",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"queue"},
+	)
+	tasksByStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sourcecontrol_background_tasks",
+			Help: "Number of background tasks by queue and status, updated after each processing run",
+		},
+		[]string{"queue", "status"},
+	)
+)
@@ -0,0 +1,230 @@
+package background_task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	background_task_model "code.gitea.io/gitea/models/background_task"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// maxBatchesPerRun ограничивает количество арендуемых за один запуск пачек задач, чтобы задачи без задержки
+// повтора не обрабатывались в одном запуске бесконечно
+const maxBatchesPerRun = 10
+
+// owner идентификатор экземпляра приложения, арендующего задачи
+var owner = func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return hostname + ":" + strconv.Itoa(os.Getpid())
+}()
+
+// Handler обработчик задачи очереди с нагрузкой типа T
+type Handler[T any] func(ctx context.Context, task *background_task_model.Task, payload T) error
+
+// WorkerOptions параметры обработки задач очереди
+type WorkerOptions[T any] struct {
+	Retry setting.TaskRetry
+	// KeepDone сохранять обработанные задачи в состоянии done, иначе они удаляются
+	KeepDone bool
+	// AuditParams параметры нагрузки для события аудита о переводе задачи в состояние dead
+	AuditParams func(payload T) map[string]string
+}
+
+// Worker обрабатывает задачи очереди. Задачи арендуются, поэтому несколько экземпляров приложения
+// обрабатывают очередь одновременно. Пока пачка задач обрабатывается, аренда ее задач продлевается.
+// Задачи доставляются не менее одного раза: если экземпляр остановлен во время обработки или не смог продлить
+// аренду, задача обрабатывается повторно, поэтому обработчики должны быть идемпотентными
+type Worker[T any] struct {
+	queue   background_task_model.Queue[T]
+	handler Handler[T]
+	opts    WorkerOptions[T]
+}
+
+func NewWorker[T any](queue background_task_model.Queue[T], handler Handler[T], opts WorkerOptions[T]) *Worker[T] {
+	return &Worker[T]{queue: queue, handler: handler, opts: opts}
+}
+
+// permanentError ошибка, после которой задача не обрабатывается повторно
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку обработчика как неустранимую повтором, задача сразу переводится в состояние dead
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent проверяет, что ошибка помечена Permanent
+func IsPermanent(err error) bool {
+	return errors.As(err, new(permanentError))
+}
+
+// Process возвращает в очередь задачи с истекшей арендой и обрабатывает готовые задачи очереди
+func (w *Worker[T]) Process(ctx context.Context) error {
+	name := w.queue.Name
+
+	recovered, dead, err := background_task_model.RecoverExpired(ctx, name, w.opts.Retry.MaxAttempts)
+	if err != nil {
+		return fmt.Errorf("recover expired tasks: %w", err)
+	}
+	if recovered > 0 || dead > 0 {
+		log.Warn("Lease of %d tasks of queue %s has expired, %d tasks are moved to dead", recovered+dead, name, dead)
+		tasksProcessed.WithLabelValues(name, resultRecovered).Add(float64(recovered))
+		tasksProcessed.WithLabelValues(name, resultDead).Add(float64(dead))
+	}
+
+	batchSize := setting.BackgroundTasks.BatchSize
+	for i := 0; i < maxBatchesPerRun; i++ {
+		tasks, err := background_task_model.Claim(ctx, name, owner, batchSize, setting.BackgroundTasks.LeaseTimeout)
+		if err != nil {
+			return fmt.Errorf("claim tasks: %w", err)
+		}
+		stopHeartbeat := w.heartbeat(ctx, tasks)
+		for _, task := range tasks {
+			if err = ctx.Err(); err != nil {
+				stopHeartbeat()
+				// арендованные задачи вернутся в очередь после истечения аренды
+				return err
+			}
+			w.processTask(ctx, task)
+		}
+		stopHeartbeat()
+		if len(tasks) < batchSize {
+			break
+		}
+	}
+
+	w.updateStatusMetrics(ctx)
+	return nil
+}
+
+// heartbeat продлевает аренду задач пачки каждую треть LeaseTimeout, пока задачи ожидают обработки
+// или обрабатываются. Завершенные задачи уже не арендованы и не продлеваются. Возвращает функцию остановки
+func (w *Worker[T]) heartbeat(ctx context.Context, tasks []*background_task_model.Task) (stop func()) {
+	if len(tasks) == 0 {
+		return func() {}
+	}
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	lease := setting.BackgroundTasks.LeaseTimeout
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := background_task_model.ExtendLeases(ctx, w.queue.Name, owner, ids, lease); err != nil {
+					log.Error("Error has occurred while extending leases of tasks of queue %s. Error: %v", w.queue.Name, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (w *Worker[T]) processTask(ctx context.Context, task *background_task_model.Task) {
+	name := w.queue.Name
+	start := time.Now()
+
+	payload, err := w.queue.Decode(task)
+	if err != nil {
+		err = Permanent(err)
+	} else {
+		err = w.handler(ctx, task, payload)
+	}
+	taskDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		w.fail(ctx, task, payload, err)
+		return
+	}
+
+	completed, err := background_task_model.Complete(ctx, task, w.opts.KeepDone)
+	if err != nil {
+		log.Error("Error has occurred while completing task %d of queue %s. Error: %v", task.ID, name, err)
+		return
+	}
+	if !completed {
+		log.Warn("Lease of task %d of queue %s has expired before completion, the task may be processed again", task.ID, name)
+	}
+	tasksProcessed.WithLabelValues(name, resultSucceeded).Inc()
+}
+
+// fail сохраняет неудачную попытку обработки задачи, следующая попытка выполняется с экспоненциальной задержкой.
+// После Retry.MaxAttempts попыток или после ошибки Permanent задача переводится в состояние dead
+func (w *Worker[T]) fail(ctx context.Context, task *background_task_model.Task, payload T, taskErr error) {
+	name := w.queue.Name
+	retry := w.opts.Retry
+	dead := IsPermanent(taskErr) || retry.IsLastAttempt(task.Attempts)
+	runAt := timeutil.TimeStamp(time.Now().Add(retry.NextAttemptDelay(task.Attempts)).Unix())
+
+	saved, err := background_task_model.Fail(ctx, task, taskErr, runAt, dead)
+	if err != nil {
+		// задача вернется в очередь после истечения аренды
+		log.Error("Error has occurred while saving failed attempt of task %d of queue %s. Error: %v", task.ID, name, err)
+		return
+	}
+	if !saved {
+		log.Warn("Lease of task %d of queue %s has expired before failure was saved", task.ID, name)
+		return
+	}
+	if !dead {
+		tasksProcessed.WithLabelValues(name, resultFailed).Inc()
+		return
+	}
+
+	tasksProcessed.WithLabelValues(name, resultDead).Inc()
+	log.Error("Error has occurred while processing task %d of queue %s after %d attempts. Error: %v", task.ID, name, task.Attempts, taskErr)
+	auditParams := map[string]string{
+		"queue":    name,
+		"task_id":  strconv.FormatInt(task.ID, 10),
+		"attempts": strconv.Itoa(task.Attempts),
+		"error":    fmt.Sprintf("Error has occurred while processing task: %v", taskErr),
+	}
+	if w.opts.AuditParams != nil {
+		for key, value := range w.opts.AuditParams(payload) {
+			auditParams[key] = value
+		}
+	}
+	audit.CreateAndSendEvent(audit.BackgroundTaskDeadEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+}
+
+func (w *Worker[T]) updateStatusMetrics(ctx context.Context) {
+	counts, err := background_task_model.CountByStatus(ctx, w.queue.Name)
+	if err != nil {
+		log.Error("Error has occurred while counting tasks of queue %s. Error: %v", w.queue.Name, err)
+		return
+	}
+	for status, count := range counts {
+		tasksByStatus.WithLabelValues(w.queue.Name, string(status)).Set(float64(count))
+	}
+}
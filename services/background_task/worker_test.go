@@ -0,0 +1,20 @@
+//go:build !correct
+
+package background_task
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPermanent(t *testing.T) {
+	cause := errors.New("unknown action")
+
+	assert.True(t, IsPermanent(Permanent(cause)))
+	assert.True(t, IsPermanent(fmt.Errorf("handle task: %w", Permanent(cause))))
+	assert.ErrorIs(t, Permanent(cause), cause)
+	assert.False(t, IsPermanent(cause))
+}
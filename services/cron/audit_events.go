@@ -0,0 +1,36 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+func registerDeleteOldAuditEvents() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: false, Schedule: "@every 24h"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := audit_service.DeleteExpiredEvents(ctx); err != nil {
+			return fmt.Errorf("error has occurred while deleting old audit events: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("delete_old_audit_events", cfg, actionFunc)
+}
+
+func registerAuditChainCheckpoint() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: true, Schedule: "@every 1h"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := audit.WriteChainCheckpoint(); err != nil {
+			return fmt.Errorf("error has occurred while writing audit chain checkpoint: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("audit_chain_checkpoint", cfg, actionFunc)
+}
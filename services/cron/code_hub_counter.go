@@ -4,13 +4,15 @@ import (
 	"context"
 	"fmt"
 
-	"code.gitea.io/gitea/models/code_hub_counter_task/code_hub_counter_task_db"
+	"code.gitea.io/gitea/models/code_hub_counter_task"
 	"code.gitea.io/gitea/models/code_hub_unique_usages/code_hub_unique_usages_db"
+	"code.gitea.io/gitea/models/code_hub_usage_stat/code_hub_usage_stat_db"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/internal_metric_counter/internal_metric_counter_db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/private/code_hub_counter"
+	"code.gitea.io/gitea/services/background_task"
 )
 
 func registerCodeHubCounterTasksProcessor() {
@@ -23,13 +25,18 @@ func registerCodeHubCounterTasksProcessor() {
 	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
 		dbEngine := db.GetEngine(ctx)
 
-		codeHubTasksDB := code_hub_counter_task_db.New(dbEngine)
 		codeHubUniqueUsagesDB := code_hub_unique_usages_db.New(dbEngine)
 		codeHubCounterDB := internal_metric_counter_db.New(dbEngine)
 
-		counter := code_hub_counter.NewCodeHubCounter(codeHubTasksDB, codeHubUniqueUsagesDB, codeHubCounterDB)
+		codeHubUsageStatDB := code_hub_usage_stat_db.New()
 
-		if err := counter.ProcessNewUsageTasks(ctx); err != nil {
+		counter := code_hub_counter.NewCodeHubCounter(codeHubUniqueUsagesDB, codeHubCounterDB, codeHubUsageStatDB)
+		worker := background_task.NewWorker(code_hub_counter_task.Queue, counter.ProcessUsageTask, background_task.WorkerOptions[code_hub_counter_task.Payload]{
+			Retry:       setting.CodeHub.TasksRetry,
+			AuditParams: code_hub_counter.AuditParams,
+		})
+
+		if err := worker.Process(ctx); err != nil {
 			return fmt.Errorf("error has occurred while proccessing new tasks: %w", err)
 		}
 
@@ -48,11 +55,10 @@ func registerCodeHubCounterStatsProcessor() {
 	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
 		dbEngine := db.GetEngine(ctx)
 
-		codeHubTasksDB := code_hub_counter_task_db.New(dbEngine)
 		codeHubUniqueUsagesDB := code_hub_unique_usages_db.New(dbEngine)
 		codeHubCounterDB := internal_metric_counter_db.New(dbEngine)
 
-		counter := code_hub_counter.NewCodeHubCounter(codeHubTasksDB, codeHubUniqueUsagesDB, codeHubCounterDB)
+		counter := code_hub_counter.NewCodeHubCounter(codeHubUniqueUsagesDB, codeHubCounterDB, nil)
 
 		if err := counter.CalculateRepoCounters(ctx); err != nil {
 			return fmt.Errorf("error has occurred while calculating repo counters: %w", err)
@@ -0,0 +1,53 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kafka_command_model "code.gitea.io/gitea/models/kafka_command"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	kafka_outbox_service "code.gitea.io/gitea/services/kafka_outbox"
+)
+
+func registerKafkaOutboxRelay() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: true, Schedule: setting.Kafka.Outbox.Schedule}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := kafka_outbox_service.RelayMessages(ctx); err != nil {
+			return fmt.Errorf("error has occurred while relaying Kafka outbox messages: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("kafka_outbox_relay", cfg, actionFunc)
+}
+
+func registerDeleteSentKafkaOutboxMessages() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: false, Schedule: "@every 24h"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := kafka_outbox_service.DeleteSentMessages(ctx); err != nil {
+			return fmt.Errorf("error has occurred while deleting sent Kafka outbox messages: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("delete_sent_kafka_outbox_messages", cfg, actionFunc)
+}
+
+func registerDeleteFinishedKafkaCommands() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: false, Schedule: "@every 24h"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		before := timeutil.TimeStamp(time.Now().Add(-setting.Kafka.Commands.Retention).Unix())
+		if _, err := kafka_command_model.DeleteFinishedCommandsBefore(ctx, before); err != nil {
+			return fmt.Errorf("error has occurred while deleting finished Kafka commands: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("delete_finished_kafka_commands", cfg, actionFunc)
+}
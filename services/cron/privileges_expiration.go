@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/services/privileges"
+)
+
+func registerRevokeExpiredPrivileges() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: true, Schedule: "@every 10m"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := privileges.RevokeExpiredPrivileges(ctx); err != nil {
+			return fmt.Errorf("error has occurred while revoking expired privileges: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("revoke_expired_privileges", cfg, actionFunc)
+}
@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/role_model"
+	user_model "code.gitea.io/gitea/models/user"
+)
+
+func registerReloadRoleDefinitions() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: false, Schedule: "@every 1m"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := role_model.ReloadRoleDefinitions(ctx); err != nil {
+			return fmt.Errorf("error has occurred while reloading role definitions: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("reload_role_definitions", cfg, actionFunc)
+}
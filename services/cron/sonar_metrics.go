@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	webhook_service "code.gitea.io/gitea/services/webhook"
+)
+
+func registerDeleteOldSonarMetrics() {
+	cfg := &BaseConfig{Enabled: true, RunAtStart: false, Schedule: "@every 24h"}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		if err := webhook_service.DeleteExpiredSonarMetricsSnapshots(ctx); err != nil {
+			return fmt.Errorf("error has occurred while deleting old sonar metrics snapshots: %w", err)
+		}
+		return nil
+	}
+
+	RegisterTaskFatal("delete_old_sonar_metrics", cfg, actionFunc)
+}
@@ -230,9 +230,30 @@ func initExtendedTasks() {
 
 	if setting.TaskTracker.Enabled {
 		registerUnitLinksSender()
+		registerUnitLinksPushProcessor()
 	}
 	if setting.CodeHub.CodeHubMetricEnabled {
 		registerCodeHubCounterTasksProcessor()
 		registerCodeHubCounterStatsProcessor()
 	}
+	if setting.SourceControl.TenantWithRoleModeEnabled {
+		registerRevokeExpiredPrivileges()
+		registerReloadRoleDefinitions()
+	}
+	if setting.AuditSinks.Database.Enabled {
+		registerDeleteOldAuditEvents()
+	}
+	if setting.AuditChain.Enabled && setting.AuditChain.Secret != "" {
+		registerAuditChainCheckpoint()
+	}
+	if setting.Sonar.MetricsRetention > 0 {
+		registerDeleteOldSonarMetrics()
+	}
+	if setting.Kafka.Enabled && setting.Kafka.Outbox.Enabled {
+		registerKafkaOutboxRelay()
+		registerDeleteSentKafkaOutboxMessages()
+	}
+	if topic := setting.Kafka.Topics[setting.KafkaCommandTopic]; setting.Kafka.Enabled && topic.Enabled {
+		registerDeleteFinishedKafkaCommands()
+	}
 }
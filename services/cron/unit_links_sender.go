@@ -2,18 +2,22 @@ package cron
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"net/http"
 
 	"code.gitea.io/gitea/models/db"
-	"code.gitea.io/gitea/models/unit_links_sender/unit_links_sender_db"
+	"code.gitea.io/gitea/models/unit_links/unit_links_db"
+	"code.gitea.io/gitea/models/unit_links_push_task"
+	"code.gitea.io/gitea/models/unit_links_sender"
 	user_model "code.gitea.io/gitea/models/user"
-	"code.gitea.io/gitea/modules/mtls"
+	"code.gitea.io/gitea/modules/gitnamesparser/branch"
+	"code.gitea.io/gitea/modules/gitnamesparser/commit"
+	"code.gitea.io/gitea/modules/gitnamesparser/pullrequest"
 	"code.gitea.io/gitea/modules/setting"
-	setting_mtls "code.gitea.io/gitea/modules/setting/mtls"
+	"code.gitea.io/gitea/routers/private/pull_request_reader"
 	"code.gitea.io/gitea/routers/private/task_tracker_client"
 	"code.gitea.io/gitea/routers/private/task_tracker_sender"
+	"code.gitea.io/gitea/routers/private/unit_linker"
+	"code.gitea.io/gitea/services/background_task"
 )
 
 func registerUnitLinksSender() {
@@ -23,24 +27,18 @@ func registerUnitLinksSender() {
 	cfg := &BaseConfig{Enabled: true, RunAtStart: true, Schedule: schedule}
 
 	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
-		dbEngine := db.GetEngine(ctx)
-		unitLinkSenderDB := unit_links_sender_db.New(dbEngine)
-
-		mtlsConfig := &tls.Config{} // initialize tls config with default values
-		if setting_mtls.CheckMTLSConfigSecManEnabled(task_tracker_client.TaskTrackerClientName) {
-			mtlsCerts := setting_mtls.GetMTLSCertsFromSecMan(task_tracker_client.TaskTrackerClientName, setting.NewGetterForSecMan())
-			mtlsConfig = mtls.GenerateTlsConfigForMTLS(task_tracker_client.TaskTrackerClientName, mtlsCerts.Cert, mtlsCerts.CertKey, mtlsCerts.CaCerts)
+		taskTrackerResolver, err := task_tracker_client.NewResolverFromSettings()
+		if err != nil {
+			return fmt.Errorf("create task tracker providers: %w", err)
 		}
-		taskTrackerClient := task_tracker_client.New(
-			setting.TaskTracker.APIBaseURL,
-			setting.TaskTracker.APIToken,
-			&http.Client{Transport: &http.Transport{TLSClientConfig: mtlsConfig}},
-		)
-		sender := task_tracker_sender.NewTaskTrackerSender(
-			taskTrackerClient, unitLinkSenderDB,
-		)
+		sender := task_tracker_sender.NewTaskTrackerSender(taskTrackerResolver)
+		worker := background_task.NewWorker(unit_links_sender.Queue, sender.Send, background_task.WorkerOptions[unit_links_sender.Payload]{
+			Retry:       setting.TaskTracker.SenderRetry,
+			KeepDone:    true,
+			AuditParams: task_tracker_sender.AuditParams,
+		})
 
-		if err := sender.SendNewPrTasksToTaskTracker(ctx); err != nil {
+		if err := worker.Process(ctx); err != nil {
 			return err
 		}
 
@@ -49,3 +47,34 @@ func registerUnitLinksSender() {
 
 	RegisterTaskFatal("task_tracker", cfg, actionFunc)
 }
+
+func registerUnitLinksPushProcessor() {
+	interval := setting.TaskTracker.UnitLinksSenderIntervalSeconds
+	schedule := fmt.Sprintf("*/%d * * * * *", interval)
+
+	cfg := &BaseConfig{Enabled: true, RunAtStart: true, Schedule: schedule}
+
+	actionFunc := func(ctx context.Context, _ *user_model.User, config Config) error {
+		taskTrackerResolver, err := task_tracker_client.NewResolverFromSettings()
+		if err != nil {
+			return fmt.Errorf("create task tracker providers: %w", err)
+		}
+		unitLinker := unit_linker.NewUnitLinker(
+			branch.NewParser(),
+			commit.NewParser(),
+			pullrequest.NewParser(),
+			unit_links_db.NewUnitLinkDB(db.GetEngine(ctx)),
+			pull_request_reader.NewReader(db.GetEngine(ctx)),
+			taskTrackerResolver,
+			setting.TaskTracker.UnitsValidationEnabled,
+		)
+		worker := background_task.NewWorker(unit_links_push_task.Queue, unitLinker.LinkPush, background_task.WorkerOptions[unit_links_push_task.Payload]{
+			Retry:       setting.TaskTracker.SenderRetry,
+			AuditParams: unit_linker.PushAuditParams,
+		})
+
+		return worker.Process(ctx)
+	}
+
+	RegisterTaskFatal("task_tracker_push_links", cfg, actionFunc)
+}
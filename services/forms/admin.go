@@ -72,3 +72,17 @@ func (f *AdminDashboardForm) Validate(req *http.Request, errs binding.Errors) bi
 	ctx := context.GetValidateContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AdminRoleForm form for admin to create or edit role of the role model
+type AdminRoleForm struct {
+	Name         string   `binding:"MaxSize(100)"`
+	DisplayName  string   `binding:"MaxSize(255)"`
+	Actions      []string `form:"actions"`
+	IsAssignable bool
+}
+
+// Validate validates form fields
+func (f *AdminRoleForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
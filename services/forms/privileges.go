@@ -2,6 +2,9 @@ package forms
 
 import (
 	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/role_model"
 )
 
 // count - максимальное количество user key в request
@@ -28,10 +31,21 @@ func (a ApplyPrivilegeRequest) Validate() error {
 		return fmt.Errorf("request incorrect: maximum %d users allowed per request", count)
 	}
 	// Проверка ограничения на количество привилегий для каждого пользователя
+	now := time.Now()
 	for _, grant := range a.Action.Grant {
 		if len(grant.PrivilegeGroups) > count {
 			return fmt.Errorf("request incorrect: maximum %d privileges allowed per user in grant", count)
 		}
+		// Проверка, что срок действия привилегии еще не истек
+		for _, group := range grant.PrivilegeGroups {
+			if group.ExpiresAt != nil && !group.ExpiresAt.After(now) {
+				return fmt.Errorf("request incorrect: expires_at must be in the future")
+			}
+			// Срок действия поддерживается только для ролей в проекте
+			if group.ExpiresAt != nil && group.RepositoryKey != "" {
+				return fmt.Errorf("request incorrect: expires_at is not supported for repository privileges")
+			}
+		}
 	}
 	for _, revoke := range a.Action.Revoke {
 		if len(revoke.PrivilegeGroups) > count {
@@ -58,9 +72,10 @@ func (a ApplyPrivilegeRequest) HasOnlyGrant() bool {
 
 // PrivilegeRequest структура запроса на получение привилегий
 type PrivilegeRequest struct {
-	TenantKey  string `json:"tenant_key" binding:"Required"`
-	ProjectKey string `json:"project_key" binding:"Required"`
-	UserKey    string `json:"user_key" binding:"Required"`
+	TenantKey     string `json:"tenant_key" binding:"Required"`
+	ProjectKey    string `json:"project_key" binding:"Required"`
+	UserKey       string `json:"user_key" binding:"Required"`
+	RepositoryKey string `json:"repository_key,omitempty"` // ключ репозитория проекта, при указании возвращаются роли на репозиторий
 }
 
 // GetPrivilegesRequest — массив запросов
@@ -85,6 +100,7 @@ type PrivilegeGroupGrant struct {
 	TenantKey      string   `json:"tenant_key" binding:"Required"`
 	ProjectKey     string   `json:"project_key" binding:"Required"`
 	PrivilegeGroup []string `json:"privilege_group" binding:"Required"` // enum значение
+	RepositoryKey  string   `json:"repository_key,omitempty"`
 }
 
 // ResponsePrivilegesGet структура ответа для запроса на получение привилегий
@@ -106,9 +122,11 @@ type PrivilegeGroupAssignment struct {
 
 // PrivilegeGroup структура назначения привилегий
 type PrivilegeGroup struct {
-	TenantKey       string `json:"tenant_key" binding:"Required"`
-	ProjectKey      string `json:"project_key" binding:"Required"`
-	PrivilegesGroup string `json:"privilege_group" binding:"Required"`
+	TenantKey       string     `json:"tenant_key" binding:"Required"`
+	ProjectKey      string     `json:"project_key" binding:"Required"`
+	PrivilegesGroup string     `json:"privilege_group" binding:"Required"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`     // время окончания действия привилегии, пустое значение - бессрочно
+	RepositoryKey   string     `json:"repository_key,omitempty"` // ключ репозитория проекта, пустое значение - роль на весь проект
 }
 
 // ApplyPrivilegesResponse структура ответа для запроса на назначение и удаление привилегий с ошибкой
@@ -134,5 +152,61 @@ type PrivilegeGroupErr struct {
 	TenantID       string `json:"tenant_key"`
 	ProjectKey     string `json:"project_key"`
 	PrivilegeGroup string `json:"privilege_group"`
+	RepositoryKey  string `json:"repository_key,omitempty"`
 	ErrMsg         string `json:"error"`
 }
+
+// Источники привилегий пользователя
+const (
+	PrivilegeSourceManual = "manual" // привилегии назначены через API или интерфейс администратора
+	PrivilegeSourceIAM    = "iam"    // привилегии синхронизируются из заголовка Ws-Privileges IAM
+)
+
+// ExplainPrivilegeRequest структура запроса на объяснение решения о доступе пользователя
+// swagger:model ExplainPrivilegeRequest
+type ExplainPrivilegeRequest struct {
+	UserKey         string `json:"user_key" binding:"Required"`
+	TenantKey       string `json:"tenant_key" binding:"Required"`
+	ProjectKey      string `json:"project_key" binding:"Required"`
+	RepositoryKey   string `json:"repository_key,omitempty"`
+	Action          string `json:"action,omitempty"`           // действие ролевой модели (role_model.Action)
+	CustomPrivilege string `json:"custom_privilege,omitempty"` // кастомная привилегия (role_model.CustomPrivilege), требует repository_key
+}
+
+// Validate проверяет корректность запроса. Для кастомной привилегии action необязателен:
+// по умолчанию проверяется доступ на чтение репозитория, как в middleware проверки кастомных привилегий
+func (r ExplainPrivilegeRequest) Validate() error {
+	if r.Action == "" && r.CustomPrivilege == "" {
+		return fmt.Errorf("request incorrect: action or custom_privilege must be specified")
+	}
+	if r.Action != "" {
+		if _, ok := role_model.GetActionByString(r.Action); !ok {
+			return fmt.Errorf("request incorrect: action %s does not exist", r.Action)
+		}
+	}
+	if r.CustomPrivilege == "" {
+		return nil
+	}
+	if _, ok := role_model.GetCustomPrivilegesByString(r.CustomPrivilege); !ok {
+		return fmt.Errorf("request incorrect: custom privilege %s does not exist", r.CustomPrivilege)
+	}
+	if r.RepositoryKey == "" {
+		return fmt.Errorf("request incorrect: repository_key is required for custom privilege")
+	}
+	return nil
+}
+
+// PrivilegeSource источник привилегий пользователя
+// swagger:model PrivilegeSource
+type PrivilegeSource struct {
+	Type     string     `json:"type"`
+	SyncedAt *time.Time `json:"synced_at,omitempty"` // время последней синхронизации привилегий из IAM
+}
+
+// ExplainPrivilegeResponse структура ответа с решением о доступе и проверками, которые к нему привели
+// swagger:model ExplainPrivilegeResponse
+type ExplainPrivilegeResponse struct {
+	Allowed bool                       `json:"allowed"`
+	Source  PrivilegeSource            `json:"source"`
+	Rules   []role_model.ExplainedRule `json:"rules"`
+}
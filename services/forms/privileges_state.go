@@ -0,0 +1,206 @@
+package forms
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PrivilegesState декларативное описание привилегий пользователей и команд.
+// Область описания задается явно: списком тенантов или признаком all_tenants
+// swagger:model PrivilegesState
+type PrivilegesState struct {
+	Tenants              []string                   `json:"tenants,omitempty" yaml:"tenants,omitempty"`         // ключи тенантов, которыми управляет описание
+	AllTenants           bool                       `json:"all_tenants,omitempty" yaml:"all_tenants,omitempty"` // описание управляет всеми тенантами
+	Privileges           []ProjectPrivilegeState    `json:"privileges" yaml:"privileges"`
+	RepositoryPrivileges []RepositoryPrivilegeState `json:"repository_privileges" yaml:"repository_privileges"`
+	TeamMembers          []TeamMemberState          `json:"team_members" yaml:"team_members"`
+	TeamPrivileges       []TeamPrivilegeState       `json:"team_privileges" yaml:"team_privileges"`
+	CustomGroups         []CustomGroupState         `json:"custom_groups,omitempty" yaml:"custom_groups,omitempty"` // только для чтения, группы задаются конфигурацией
+}
+
+// ProjectPrivilegeState роль или кастомная группа пользователя в проекте
+// swagger:model ProjectPrivilegeState
+type ProjectPrivilegeState struct {
+	UserKey        string `json:"user_key" yaml:"user_key"`
+	TenantKey      string `json:"tenant_key" yaml:"tenant_key"`
+	ProjectKey     string `json:"project_key" yaml:"project_key"`
+	PrivilegeGroup string `json:"privilege_group" yaml:"privilege_group"`
+}
+
+// RepositoryPrivilegeState роль пользователя в репозитории проекта
+// swagger:model RepositoryPrivilegeState
+type RepositoryPrivilegeState struct {
+	UserKey        string `json:"user_key" yaml:"user_key"`
+	TenantKey      string `json:"tenant_key" yaml:"tenant_key"`
+	ProjectKey     string `json:"project_key" yaml:"project_key"`
+	RepositoryKey  string `json:"repository_key" yaml:"repository_key"`
+	PrivilegeGroup string `json:"privilege_group" yaml:"privilege_group"`
+}
+
+// TeamMemberState участие пользователя в команде проекта с кастомными привилегиями
+// swagger:model TeamMemberState
+type TeamMemberState struct {
+	UserKey    string `json:"user_key" yaml:"user_key"`
+	TenantKey  string `json:"tenant_key" yaml:"tenant_key"`
+	ProjectKey string `json:"project_key" yaml:"project_key"`
+	Team       string `json:"team" yaml:"team"`
+}
+
+// TeamPrivilegeState кастомные привилегии команды в репозитории проекта
+// swagger:model TeamPrivilegeState
+type TeamPrivilegeState struct {
+	Team             string   `json:"team" yaml:"team"`
+	TenantKey        string   `json:"tenant_key" yaml:"tenant_key"`
+	ProjectKey       string   `json:"project_key" yaml:"project_key"`
+	RepositoryKey    string   `json:"repository_key" yaml:"repository_key"`
+	CustomPrivileges []string `json:"custom_privileges" yaml:"custom_privileges"`
+}
+
+// CustomGroupState кастомная группа привилегий
+// swagger:model CustomGroupState
+type CustomGroupState struct {
+	Code       string   `json:"code" yaml:"code"`
+	Name       string   `json:"name" yaml:"name"`
+	Privileges []string `json:"privileges" yaml:"privileges"`
+}
+
+// PrivilegesPlan разница между описанием привилегий и текущим состоянием ролевой модели
+// swagger:model PrivilegesPlan
+type PrivilegesPlan struct {
+	Add      PrivilegesState `json:"add" yaml:"add"`
+	Remove   PrivilegesState `json:"remove" yaml:"remove"`
+	Warnings []string        `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// PrivilegesStateExportResponse структура ответа на выгрузку описания привилегий
+// swagger:model PrivilegesStateExportResponse
+type PrivilegesStateExportResponse struct {
+	State    PrivilegesState `json:"state"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+// PrivilegesStateApplyResponse структура ответа на применение описания привилегий
+// swagger:model PrivilegesStateApplyResponse
+type PrivilegesStateApplyResponse struct {
+	Applied bool           `json:"applied"`
+	Plan    PrivilegesPlan `json:"plan"`
+}
+
+// IsEmpty проверяет, что описание не содержит привилегий
+func (s PrivilegesState) IsEmpty() bool {
+	return len(s.Privileges) == 0 && len(s.RepositoryPrivileges) == 0 && len(s.TeamMembers) == 0 && len(s.TeamPrivileges) == 0
+}
+
+// IsEmpty проверяет, что изменений нет
+func (p PrivilegesPlan) IsEmpty() bool {
+	return p.Add.IsEmpty() && p.Remove.IsEmpty()
+}
+
+// Count возвращает количество записей описания
+func (s PrivilegesState) Count() int {
+	return len(s.Privileges) + len(s.RepositoryPrivileges) + len(s.TeamMembers) + len(s.TeamPrivileges)
+}
+
+// InScope проверяет, что тенант управляется описанием
+func (s PrivilegesState) InScope(tenantKey string) bool {
+	if s.AllTenants {
+		return true
+	}
+	for _, tenant := range s.Tenants {
+		if tenant == tenantKey {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateScope проверяет, что область описания задана явно. Пустой список тенантов не означает все тенанты,
+// чтобы пустое или обрезанное описание не снимало привилегии всех тенантов
+func (s PrivilegesState) ValidateScope() error {
+	if len(s.Tenants) == 0 && !s.AllTenants {
+		return fmt.Errorf("tenants or all_tenants must be specified")
+	}
+	if len(s.Tenants) > 0 && s.AllTenants {
+		return fmt.Errorf("tenants and all_tenants can not be specified together")
+	}
+	return nil
+}
+
+// Validate проверяет корректность описания привилегий
+func (s PrivilegesState) Validate() error {
+	if err := s.ValidateScope(); err != nil {
+		return err
+	}
+	check := func(section string, idx int, tenantKey string, fields ...string) error {
+		for _, field := range fields {
+			if field == "" {
+				return fmt.Errorf("%s[%d]: all fields are required", section, idx)
+			}
+		}
+		if !s.InScope(tenantKey) {
+			return fmt.Errorf("%s[%d]: tenant %s is not listed in tenants", section, idx, tenantKey)
+		}
+		return nil
+	}
+
+	projectRoles := make(map[string]struct{}, len(s.Privileges))
+	for idx, privilege := range s.Privileges {
+		if err := check("privileges", idx, privilege.TenantKey, privilege.UserKey, privilege.TenantKey, privilege.ProjectKey, privilege.PrivilegeGroup); err != nil {
+			return err
+		}
+		// у пользователя может быть только одна роль в проекте
+		key := privilege.UserKey + "/" + privilege.TenantKey + "/" + privilege.ProjectKey
+		if _, ok := projectRoles[key]; ok {
+			return fmt.Errorf("privileges[%d]: user %s has several privilege groups in project %s", idx, privilege.UserKey, privilege.ProjectKey)
+		}
+		projectRoles[key] = struct{}{}
+	}
+	repoRoles := make(map[string]struct{}, len(s.RepositoryPrivileges))
+	for idx, privilege := range s.RepositoryPrivileges {
+		if err := check("repository_privileges", idx, privilege.TenantKey, privilege.UserKey, privilege.TenantKey, privilege.ProjectKey, privilege.RepositoryKey, privilege.PrivilegeGroup); err != nil {
+			return err
+		}
+		key := privilege.UserKey + "/" + privilege.TenantKey + "/" + privilege.ProjectKey + "/" + privilege.RepositoryKey
+		if _, ok := repoRoles[key]; ok {
+			return fmt.Errorf("repository_privileges[%d]: user %s has several privilege groups in repository %s", idx, privilege.UserKey, privilege.RepositoryKey)
+		}
+		repoRoles[key] = struct{}{}
+	}
+	for idx, member := range s.TeamMembers {
+		if err := check("team_members", idx, member.TenantKey, member.UserKey, member.TenantKey, member.ProjectKey, member.Team); err != nil {
+			return err
+		}
+	}
+	for idx, privilege := range s.TeamPrivileges {
+		if err := check("team_privileges", idx, privilege.TenantKey, privilege.Team, privilege.TenantKey, privilege.ProjectKey, privilege.RepositoryKey); err != nil {
+			return err
+		}
+		if len(privilege.CustomPrivileges) == 0 {
+			return fmt.Errorf("team_privileges[%d]: custom_privileges are required", idx)
+		}
+	}
+	return nil
+}
+
+// Key возвращает ключ для сравнения записей описания
+func (p ProjectPrivilegeState) Key() string {
+	return strings.Join([]string{p.UserKey, p.TenantKey, p.ProjectKey, p.PrivilegeGroup}, "/")
+}
+
+// Key возвращает ключ для сравнения записей описания
+func (p RepositoryPrivilegeState) Key() string {
+	return strings.Join([]string{p.UserKey, p.TenantKey, p.ProjectKey, p.RepositoryKey, p.PrivilegeGroup}, "/")
+}
+
+// Key возвращает ключ для сравнения записей описания
+func (m TeamMemberState) Key() string {
+	return strings.Join([]string{m.UserKey, m.TenantKey, m.ProjectKey, m.Team}, "/")
+}
+
+// Key возвращает ключ для сравнения записей описания, порядок привилегий не учитывается
+func (p TeamPrivilegeState) Key() string {
+	privileges := append([]string(nil), p.CustomPrivileges...)
+	sort.Strings(privileges)
+	return strings.Join([]string{p.Team, p.TenantKey, p.ProjectKey, p.RepositoryKey, strings.Join(privileges, ",")}, "/")
+}
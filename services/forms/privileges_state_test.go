@@ -0,0 +1,55 @@
+//go:build !correct
+
+package forms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivilegesState_Validate(t *testing.T) {
+	privilege := ProjectPrivilegeState{UserKey: "user", TenantKey: "tenant", ProjectKey: "project", PrivilegeGroup: "reader"}
+
+	state := PrivilegesState{Tenants: []string{"tenant"}, Privileges: []ProjectPrivilegeState{privilege}}
+	assert.NoError(t, state.Validate())
+
+	// тенант не входит в область описания
+	state = PrivilegesState{Tenants: []string{"other"}, Privileges: []ProjectPrivilegeState{privilege}}
+	assert.Error(t, state.Validate())
+
+	// несколько ролей пользователя в одном проекте
+	owner := privilege
+	owner.PrivilegeGroup = "owner"
+	state = PrivilegesState{AllTenants: true, Privileges: []ProjectPrivilegeState{privilege, owner}}
+	assert.Error(t, state.Validate())
+
+	state = PrivilegesState{AllTenants: true, Privileges: []ProjectPrivilegeState{{UserKey: "user", TenantKey: "tenant", ProjectKey: "project"}}}
+	assert.Error(t, state.Validate())
+
+	state = PrivilegesState{AllTenants: true, TeamPrivileges: []TeamPrivilegeState{{Team: "team", TenantKey: "tenant", ProjectKey: "project", RepositoryKey: "repo"}}}
+	assert.Error(t, state.Validate())
+}
+
+func TestPrivilegesState_ValidateScope(t *testing.T) {
+	privilege := ProjectPrivilegeState{UserKey: "user", TenantKey: "tenant", ProjectKey: "project", PrivilegeGroup: "reader"}
+
+	// пустое описание без области не должно снимать привилегии всех тенантов
+	assert.Error(t, PrivilegesState{}.Validate())
+	assert.Error(t, PrivilegesState{Privileges: []ProjectPrivilegeState{privilege}}.Validate())
+	assert.Error(t, PrivilegesState{Tenants: []string{"tenant"}, AllTenants: true}.Validate())
+
+	state := PrivilegesState{AllTenants: true, Privileges: []ProjectPrivilegeState{privilege}}
+	assert.NoError(t, state.Validate())
+	assert.True(t, state.InScope("other"))
+	assert.False(t, PrivilegesState{Tenants: []string{"tenant"}}.InScope("other"))
+}
+
+func TestTeamPrivilegeState_Key(t *testing.T) {
+	first := TeamPrivilegeState{Team: "team", TenantKey: "tenant", ProjectKey: "project", RepositoryKey: "repo", CustomPrivileges: []string{"viewBranch", "mergePR"}}
+	second := first
+	second.CustomPrivileges = []string{"mergePR", "viewBranch"}
+
+	assert.Equal(t, first.Key(), second.Key())
+	assert.Equal(t, []string{"viewBranch", "mergePR"}, first.CustomPrivileges)
+}
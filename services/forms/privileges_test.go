@@ -0,0 +1,78 @@
+//go:build !correct
+
+package forms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPrivilegeRequest_ValidateExpiresAt(t *testing.T) {
+	newRequest := func(expiresAt *time.Time) ApplyPrivilegeRequest {
+		return ApplyPrivilegeRequest{
+			Action: ApplyPrivilegeGroups{
+				Grant: []PrivilegeGroupAssignment{{
+					UserExternalID: "user",
+					PrivilegeGroups: []PrivilegeGroup{{
+						TenantKey:       "tenant",
+						ProjectKey:      "project",
+						PrivilegesGroup: "reader",
+						ExpiresAt:       expiresAt,
+					}},
+				}},
+			},
+		}
+	}
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	assert.NoError(t, newRequest(nil).Validate())
+	assert.NoError(t, newRequest(&future).Validate())
+	assert.Error(t, newRequest(&past).Validate())
+}
+
+func TestApplyPrivilegeRequest_ValidateRepositoryKey(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	request := ApplyPrivilegeRequest{
+		Action: ApplyPrivilegeGroups{
+			Grant: []PrivilegeGroupAssignment{{
+				UserExternalID: "user",
+				PrivilegeGroups: []PrivilegeGroup{{
+					TenantKey:       "tenant",
+					ProjectKey:      "project",
+					PrivilegesGroup: "writer",
+					RepositoryKey:   "repo",
+				}},
+			}},
+		},
+	}
+	assert.NoError(t, request.Validate())
+
+	request.Action.Grant[0].PrivilegeGroups[0].ExpiresAt = &future
+	assert.Error(t, request.Validate())
+}
+
+func TestExplainPrivilegeRequest_Validate(t *testing.T) {
+	request := ExplainPrivilegeRequest{UserKey: "user", TenantKey: "tenant", ProjectKey: "project"}
+	assert.Error(t, request.Validate())
+
+	request.Action = "write"
+	assert.NoError(t, request.Validate())
+
+	request.CustomPrivilege = "mergePR"
+	assert.Error(t, request.Validate())
+
+	request.RepositoryKey = "repo"
+	assert.NoError(t, request.Validate())
+
+	request.Action = ""
+	assert.NoError(t, request.Validate())
+
+	request.CustomPrivilege = "unknown"
+	assert.Error(t, request.Validate())
+
+	request = ExplainPrivilegeRequest{UserKey: "user", TenantKey: "tenant", ProjectKey: "project", Action: "unknown"}
+	assert.Error(t, request.Validate())
+}
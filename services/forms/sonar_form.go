@@ -12,6 +12,8 @@ type AddSonarSettings struct {
 	URL        string `binding:"Required;ValidUrl"`
 	Token      string `binding:"Required"`
 	ProjectKey string `binding:"Required"`
+	// Секрет для проверки подписи webhook SonarQube, пустой - сохраненный секрет не изменяется
+	WebhookSecret string `binding:"OmitEmpty;MinSize(16);MaxSize(255)"`
 }
 
 /*
@@ -7,10 +7,12 @@ import (
 	"context"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/events/v2/generic"
 	issues_model "code.gitea.io/gitea/models/issues"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
+	kafka_notification "code.gitea.io/gitea/modules/notification/kafka"
 )
 
 // ChangeStatus changes issue status to open or closed.
@@ -21,7 +23,14 @@ func ChangeStatus(issue *issues_model.Issue, doer *user_model.User, commitID str
 // changeStatusCtx changes issue status to open or closed.
 // TODO: if context is not db.DefaultContext we get a deadlock!!!
 func changeStatusCtx(ctx context.Context, issue *issues_model.Issue, doer *user_model.User, commitID string, closed bool) error {
-	comment, err := issues_model.ChangeIssueStatus(ctx, issue, doer, closed)
+	var comment *issues_model.Comment
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if comment, err = issues_model.ChangeIssueStatus(ctx, issue, doer, closed); err != nil {
+			return err
+		}
+		return enqueuePullRequestCloseEvent(ctx, doer, issue, closed)
+	})
 	if err != nil {
 		if issues_model.IsErrDependenciesLeft(err) && closed {
 			if err := issues_model.FinishIssueStopwatchIfPossible(ctx, doer, issue); err != nil {
@@ -41,3 +50,19 @@ func changeStatusCtx(ctx context.Context, issue *issues_model.Issue, doer *user_
 
 	return nil
 }
+
+// enqueuePullRequestCloseEvent сохраняет в outbox событие Kafka о закрытии запроса на слияние без слияния
+// в транзакции изменения статуса. Закрытие при слиянии отправляется как событие о слиянии
+func enqueuePullRequestCloseEvent(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, closed bool) error {
+	if !issue.IsPull || !closed {
+		return nil
+	}
+	if err := issue.LoadPullRequest(ctx); err != nil {
+		return err
+	}
+	if issue.PullRequest.HasMerged {
+		return nil
+	}
+	issue.PullRequest.Issue = issue
+	return kafka_notification.EnqueuePullRequestEvent(ctx, doer, issue.PullRequest, generic.CLOSE)
+}
@@ -0,0 +1,176 @@
+package kafka_outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/clients/kafka"
+	kafka_outbox_model "code.gitea.io/gitea/models/kafka_outbox"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// errTopicDisabled топик события выключен после сохранения события
+var errTopicDisabled = errors.New("topic is disabled")
+
+// producer отправляет сообщение в топик
+type producer interface {
+	Produce(ctx context.Context, topicKey, key, idempotencyKey string, value []byte) error
+}
+
+// topicProducer отправляет сообщения общими писателями топиков Kafka
+type topicProducer struct{}
+
+func (topicProducer) Produce(ctx context.Context, topicKey, key, idempotencyKey string, value []byte) error {
+	topicInfo, ok := setting.Kafka.Topics[topicKey]
+	if !ok || !topicInfo.Enabled || topicInfo.Type != string(kafka.Produce) {
+		return errTopicDisabled
+	}
+	return kafka.ProduceMessage(ctx, topicKey, key, idempotencyKey, value)
+}
+
+// store хранилище событий outbox
+type store interface {
+	GetDeliverableMessages(ctx context.Context, limit int, now timeutil.TimeStamp) ([]*kafka_outbox_model.Message, error)
+	LockMessage(ctx context.Context, id int64, now timeutil.TimeStamp) error
+	MarkMessageSent(ctx context.Context, id int64) error
+	MarkMessageAttemptFailed(ctx context.Context, msg *kafka_outbox_model.Message, sendErr error, nextAttempt timeutil.TimeStamp, final bool) error
+}
+
+// dbStore хранит события outbox в таблице kafka_outbox_message
+type dbStore struct{}
+
+func (dbStore) GetDeliverableMessages(ctx context.Context, limit int, now timeutil.TimeStamp) ([]*kafka_outbox_model.Message, error) {
+	return kafka_outbox_model.GetDeliverableMessages(ctx, limit, now)
+}
+
+func (dbStore) LockMessage(ctx context.Context, id int64, now timeutil.TimeStamp) error {
+	return kafka_outbox_model.LockMessage(ctx, id, now)
+}
+
+func (dbStore) MarkMessageSent(ctx context.Context, id int64) error {
+	return kafka_outbox_model.MarkMessageSent(ctx, id)
+}
+
+func (dbStore) MarkMessageAttemptFailed(ctx context.Context, msg *kafka_outbox_model.Message, sendErr error, nextAttempt timeutil.TimeStamp, final bool) error {
+	return kafka_outbox_model.MarkMessageAttemptFailed(ctx, msg, sendErr, nextAttempt, final)
+}
+
+// RelayMessages отправляет сохраненные в outbox события. События с одним ключом отправляются в порядке сохранения:
+// за один проход выбирается только первое неотправленное событие ключа, поэтому следующее событие ждет,
+// пока предыдущее не отправлено, отправляется другим экземпляром приложения или остановлено с ошибкой
+func RelayMessages(ctx context.Context) error {
+	if !setting.Kafka.Enabled || !setting.Kafka.Outbox.Enabled {
+		return nil
+	}
+
+	staleBefore := timeutil.TimeStamp(time.Now().Add(-setting.Kafka.Outbox.LockTimeout).Unix())
+	if unlocked, err := kafka_outbox_model.UnlockStaleMessages(ctx, staleBefore); err != nil {
+		return err
+	} else if unlocked > 0 {
+		log.Warn("Unlocked %d stale Kafka outbox messages", unlocked)
+	}
+
+	return relayMessages(ctx, dbStore{}, topicProducer{})
+}
+
+// relayMessages отправляет события проходами, пока очередной проход отправляет хотя бы одно событие
+func relayMessages(ctx context.Context, s store, p producer) error {
+	for {
+		sent, err := relayBatch(ctx, s, p)
+		if err != nil || sent == 0 {
+			return err
+		}
+	}
+}
+
+// relayBatch отправляет первые события ключей и возвращает количество отправленных событий
+func relayBatch(ctx context.Context, s store, p producer) (int, error) {
+	now := timeutil.TimeStampNow()
+	messages, err := s.GetDeliverableMessages(ctx, setting.Kafka.Outbox.BatchSize, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		if err = ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		if err = s.LockMessage(ctx, msg.ID, now); err != nil {
+			if lockedErr := new(kafka_outbox_model.MessageAlreadyLockedError); errors.As(err, &lockedErr) {
+				log.Debug("lock outbox message err: %s", lockedErr.Error())
+				continue
+			}
+			return sent, err
+		}
+
+		if sendErr := p.Produce(ctx, msg.TopicKey, msg.MessageKey, msg.IdempotencyKey, []byte(msg.Payload)); sendErr != nil {
+			markAttemptFailed(ctx, s, msg, sendErr)
+			continue
+		}
+		if err = s.MarkMessageSent(ctx, msg.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// markAttemptFailed сохраняет неудачную попытку отправки. После последней попытки событие помечается ошибочным
+// и останавливает очередь своего ключа, пока администратор не отправит его повторно или не пропустит
+func markAttemptFailed(ctx context.Context, s store, msg *kafka_outbox_model.Message, sendErr error) {
+	outbox := setting.Kafka.Outbox
+	final := errors.Is(sendErr, errTopicDisabled) || (outbox.MaxAttempts > 0 && msg.Attempts+1 >= outbox.MaxAttempts)
+	nextAttempt := timeutil.TimeStamp(time.Now().Add(retryBackoff(msg.Attempts)).Unix())
+
+	if err := s.MarkMessageAttemptFailed(ctx, msg, sendErr, nextAttempt, final); err != nil {
+		log.Error("Error has occurred while saving Kafka outbox message %d attempt. Error: %v", msg.ID, err)
+		return
+	}
+	if !final {
+		log.Warn("Kafka outbox message %d to %s was not sent, attempt %d. Error: %v", msg.ID, msg.TopicKey, msg.Attempts, sendErr)
+		return
+	}
+
+	log.Error("Error has occurred while sending Kafka outbox message %d to %s after %d attempts, messages with key %q are stopped. Error: %v", msg.ID, msg.TopicKey, msg.Attempts, msg.MessageKey, sendErr)
+	auditParams := map[string]string{
+		"message_id":      strconv.FormatInt(msg.ID, 10),
+		"topic":           msg.TopicKey,
+		"message_key":     msg.MessageKey,
+		"idempotency_key": msg.IdempotencyKey,
+		"attempts":        strconv.Itoa(msg.Attempts),
+		"error":           fmt.Sprintf("Error has occurred while sending Kafka outbox message: %v", sendErr),
+	}
+	audit.CreateAndSendEvent(audit.KafkaOutboxMessageFailEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+}
+
+// retryBackoff возвращает задержку перед следующей попыткой, задержка удваивается с каждой попыткой
+func retryBackoff(attempts int) time.Duration {
+	outbox := setting.Kafka.Outbox
+	backoff := outbox.RetryBackoff
+	for i := 0; i < attempts && backoff < outbox.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outbox.MaxRetryBackoff {
+		backoff = outbox.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// DeleteSentMessages удаляет отправленные события старше срока хранения
+func DeleteSentMessages(ctx context.Context) error {
+	before := timeutil.TimeStamp(time.Now().Add(-setting.Kafka.Outbox.Retention).Unix())
+	deleted, err := kafka_outbox_model.DeleteSentMessagesBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+	log.Debug("Deleted %d sent Kafka outbox messages", deleted)
+	return nil
+}
@@ -0,0 +1,179 @@
+//go:build !correct
+
+package kafka_outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	kafka_outbox_model "code.gitea.io/gitea/models/kafka_outbox"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetryBackoff проверяет удвоение задержки перед повторной отправкой и ее ограничение
+func TestRetryBackoff(t *testing.T) {
+	defer func(outbox setting.KafkaOutboxConfig) { setting.Kafka.Outbox = outbox }(setting.Kafka.Outbox)
+	setting.Kafka.Outbox.RetryBackoff = 10 * time.Second
+	setting.Kafka.Outbox.MaxRetryBackoff = time.Minute
+
+	assert.Equal(t, 10*time.Second, retryBackoff(0))
+	assert.Equal(t, 20*time.Second, retryBackoff(1))
+	assert.Equal(t, 40*time.Second, retryBackoff(2))
+	assert.Equal(t, time.Minute, retryBackoff(3))
+	assert.Equal(t, time.Minute, retryBackoff(100))
+}
+
+// memoryStore хранит события outbox в памяти и выбирает их так же, как таблица kafka_outbox_message
+type memoryStore struct {
+	messages []*kafka_outbox_model.Message
+	// lockedElsewhere события, заблокированные другим экземпляром приложения между выборкой и блокировкой
+	lockedElsewhere map[int64]bool
+}
+
+func newMemoryStore(keys ...string) *memoryStore {
+	s := &memoryStore{lockedElsewhere: make(map[int64]bool)}
+	for i, key := range keys {
+		s.messages = append(s.messages, &kafka_outbox_model.Message{
+			ID:             int64(i + 1),
+			TopicKey:       setting.KafkaPushTopic,
+			MessageKey:     key,
+			IdempotencyKey: fmt.Sprintf("%s-%d", key, i+1),
+			Status:         kafka_outbox_model.StatusUnlocked,
+		})
+	}
+	return s
+}
+
+func (s *memoryStore) GetDeliverableMessages(_ context.Context, limit int, now timeutil.TimeStamp) ([]*kafka_outbox_model.Message, error) {
+	heads := make(map[string]bool)
+	result := make([]*kafka_outbox_model.Message, 0, limit)
+	for _, msg := range s.messages {
+		switch msg.Status {
+		case kafka_outbox_model.StatusUnlocked, kafka_outbox_model.StatusLocked, kafka_outbox_model.StatusFailed:
+		default:
+			continue
+		}
+		if heads[msg.MessageKey] {
+			continue
+		}
+		heads[msg.MessageKey] = true
+		if msg.Status == kafka_outbox_model.StatusUnlocked && msg.NextAttemptUnix <= now && len(result) < limit {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) LockMessage(_ context.Context, id int64, now timeutil.TimeStamp) error {
+	msg := s.get(id)
+	if s.lockedElsewhere[id] || msg.Status != kafka_outbox_model.StatusUnlocked || msg.NextAttemptUnix > now {
+		return kafka_outbox_model.NewMessageAlreadyLockedError(id)
+	}
+	msg.Status = kafka_outbox_model.StatusLocked
+	return nil
+}
+
+func (s *memoryStore) MarkMessageSent(_ context.Context, id int64) error {
+	s.get(id).Status = kafka_outbox_model.StatusSent
+	return nil
+}
+
+func (s *memoryStore) MarkMessageAttemptFailed(_ context.Context, msg *kafka_outbox_model.Message, sendErr error, nextAttempt timeutil.TimeStamp, final bool) error {
+	msg.Attempts++
+	msg.LastError = sendErr.Error()
+	msg.NextAttemptUnix = nextAttempt
+	msg.Status = kafka_outbox_model.StatusUnlocked
+	if final {
+		msg.Status = kafka_outbox_model.StatusFailed
+	}
+	return nil
+}
+
+func (s *memoryStore) get(id int64) *kafka_outbox_model.Message {
+	return s.messages[id-1]
+}
+
+// recordingProducer запоминает отправленные события и возвращает ошибку для событий из failing
+type recordingProducer struct {
+	sent    []string
+	failing map[string]bool
+}
+
+func (p *recordingProducer) Produce(_ context.Context, _, _, idempotencyKey string, _ []byte) error {
+	if p.failing[idempotencyKey] {
+		return errors.New("broker is not available")
+	}
+	p.sent = append(p.sent, idempotencyKey)
+	return nil
+}
+
+func setOutboxSettings(t *testing.T, maxAttempts int) {
+	outbox := setting.Kafka.Outbox
+	t.Cleanup(func() { setting.Kafka.Outbox = outbox })
+	setting.Kafka.Outbox.BatchSize = 2
+	setting.Kafka.Outbox.MaxAttempts = maxAttempts
+	setting.Kafka.Outbox.RetryBackoff = time.Minute
+	setting.Kafka.Outbox.MaxRetryBackoff = time.Hour
+}
+
+// TestRelayMessages_Order проверяет, что события одного ключа отправляются в порядке сохранения,
+// а все события отправляются за один запуск, даже если их больше размера выборки
+func TestRelayMessages_Order(t *testing.T) {
+	setOutboxSettings(t, 20)
+	s := newMemoryStore("repo1", "repo1", "repo2", "repo1", "repo2")
+	p := &recordingProducer{}
+
+	assert.NoError(t, relayMessages(context.Background(), s, p))
+	assert.Equal(t, []string{"repo1-1", "repo2-3", "repo1-2", "repo2-5", "repo1-4"}, p.sent)
+}
+
+// TestRelayMessages_RetryStopsKey проверяет, что следующие события ключа ждут повторной отправки предыдущего
+func TestRelayMessages_RetryStopsKey(t *testing.T) {
+	setOutboxSettings(t, 20)
+	s := newMemoryStore("repo1", "repo1", "repo2")
+	p := &recordingProducer{failing: map[string]bool{"repo1-1": true}}
+
+	assert.NoError(t, relayMessages(context.Background(), s, p))
+	assert.Equal(t, []string{"repo2-3"}, p.sent)
+	assert.Equal(t, kafka_outbox_model.StatusUnlocked, s.get(1).Status)
+	assert.Equal(t, 1, s.get(1).Attempts)
+	assert.Equal(t, kafka_outbox_model.StatusUnlocked, s.get(2).Status)
+}
+
+// TestRelayMessages_FailedStopsKey проверяет, что ошибочное событие останавливает очередь своего ключа
+func TestRelayMessages_FailedStopsKey(t *testing.T) {
+	setOutboxSettings(t, 1)
+	s := newMemoryStore("repo1", "repo1", "repo2")
+	p := &recordingProducer{failing: map[string]bool{"repo1-1": true}}
+
+	assert.NoError(t, relayMessages(context.Background(), s, p))
+	assert.Equal(t, []string{"repo2-3"}, p.sent)
+	assert.Equal(t, kafka_outbox_model.StatusFailed, s.get(1).Status)
+	assert.Equal(t, kafka_outbox_model.StatusUnlocked, s.get(2).Status)
+
+	// после повторной отправки ошибочного события очередь ключа продолжается
+	s.get(1).Status = kafka_outbox_model.StatusUnlocked
+	s.get(1).NextAttemptUnix = 0
+	p.failing = nil
+	assert.NoError(t, relayMessages(context.Background(), s, p))
+	assert.Equal(t, []string{"repo2-3", "repo1-1", "repo1-2"}, p.sent)
+}
+
+// TestRelayMessages_LockedElsewhere проверяет, что событие, заблокированное другим экземпляром приложения,
+// не отправляется повторно, а следующие события его ключа ждут
+func TestRelayMessages_LockedElsewhere(t *testing.T) {
+	setOutboxSettings(t, 20)
+	s := newMemoryStore("repo1", "repo1", "repo2")
+	s.lockedElsewhere[1] = true
+	p := &recordingProducer{}
+
+	assert.NoError(t, relayMessages(context.Background(), s, p))
+	assert.Equal(t, []string{"repo2-3"}, p.sent)
+	assert.Equal(t, kafka_outbox_model.StatusUnlocked, s.get(2).Status)
+}
@@ -23,6 +23,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
+	tenant_model "code.gitea.io/gitea/models/tenant"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
@@ -267,6 +268,33 @@ func BatchHandler(ctx *context.Context) {
 	}
 }
 
+// checkTenantLFSQuota проверяет, что загрузка нового LFS объекта в репозиторий не превысит квоту тенанта
+func checkTenantLFSQuota(ctx *context.Context, repository *repo_model.Repository, p lfs_module.Pointer) bool {
+	if !setting.SourceControl.Enabled {
+		return true
+	}
+
+	if _, err := git_model.GetLFSMetaObjectByOid(ctx, repository.ID, p.Oid); err == nil {
+		return true
+	} else if !errors.Is(err, git_model.ErrLFSObjectNotExist) {
+		log.Error("Unable to get LFS MetaObject [%s] for %-v. Error: %v", p.Oid, repository, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return false
+	}
+
+	if err := tenant_model.CheckTenantQuotaByOrgID(ctx, repository.OwnerID, tenant_model.QuotaLFSSize, p.Size); err != nil {
+		if tenant_model.IsErrTenantQuotaExceeded(err) {
+			log.Warn("Upload of LFS OID[%s] to %-v exceeds tenant quota: %v", p.Oid, repository, err)
+			writeStatusMessage(ctx, http.StatusRequestEntityTooLarge, "Tenant LFS size quota exceeded")
+			return false
+		}
+		log.Error("Unable to check tenant LFS quota for %-v. Error: %v", repository, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
 // UploadHandler receives data from the client and puts it into the content store
 func UploadHandler(ctx *context.Context) {
 	rc := getRequestContext(ctx)
@@ -288,6 +316,10 @@ func UploadHandler(ctx *context.Context) {
 		return
 	}
 
+	if !checkTenantLFSQuota(ctx, repository, p) {
+		return
+	}
+
 	contentStore := lfs_module.NewContentStore()
 	exists, err := contentStore.Exists(p)
 	if err != nil {
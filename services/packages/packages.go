@@ -11,9 +11,12 @@ import (
 	"io"
 	"strings"
 
+	"code.gitea.io/gitea/models/code_hub_counter_task"
+	"code.gitea.io/gitea/models/code_hub_counter_task/code_hub_counter_task_db"
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
+	tenant_model "code.gitea.io/gitea/models/tenant"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -411,6 +414,17 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 		}
 	}
 
+	if setting.SourceControl.Enabled {
+		if err := tenant_model.CheckTenantQuotaByOrgID(ctx, owner.ID, tenant_model.QuotaPackageSize, uploadSize); err != nil {
+			if tenant_model.IsErrTenantQuotaExceeded(err) {
+				log.Warn("Tenant package quota exceeded for owner %d: %v", owner.ID, err)
+				return ErrQuotaTotalSize
+			}
+			log.Error("CheckTenantQuotaByOrgID failed: %v", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -628,14 +642,43 @@ func GetPackageFileStream(ctx context.Context, pf *packages_model.PackageFile) (
 	s, err := packages_module.NewContentStore().Get(packages_module.BlobHash256Key(pb.HashSHA256))
 	if err == nil {
 		if pf.IsLead {
-			if err := packages_model.IncrementDownloadCounter(ctx, pf.VersionID); err != nil {
-				log.Error("Error incrementing download counter: %v", err)
-			}
+			IncrementDownloadCounter(ctx, pf.VersionID)
 		}
 	}
 	return s, pf, err
 }
 
+// IncrementDownloadCounter increments the download counter of the package version
+// and counts the download in Code Hub statistics of the repository linked to the package
+func IncrementDownloadCounter(ctx context.Context, versionID int64) {
+	if err := packages_model.IncrementDownloadCounter(ctx, versionID); err != nil {
+		log.Error("Error incrementing download counter: %v", err)
+	}
+
+	if !setting.CodeHub.CodeHubMetricEnabled {
+		return
+	}
+
+	pv, err := packages_model.GetVersionByID(ctx, versionID)
+	if err != nil {
+		log.Error("Error has occurred while getting package version %d. Error: %v", versionID, err)
+		return
+	}
+	p, err := packages_model.GetPackageByID(ctx, pv.PackageID)
+	if err != nil {
+		log.Error("Error has occurred while getting package %d. Error: %v", pv.PackageID, err)
+		return
+	}
+	if p.RepoID == 0 {
+		return
+	}
+
+	// скачивания пакетов считаются без учета пользователя
+	if err = code_hub_counter_task_db.New().InsertTask(ctx, p.RepoID, 0, code_hub_counter_task.DownloadPackageAction); err != nil {
+		log.Error("Error has occurred while inserting package download task for repo %d. Error: %v", p.RepoID, err)
+	}
+}
+
 // RemoveAllPackages for User
 func RemoveAllPackages(ctx context.Context, userID int64) (int, error) {
 	count := 0
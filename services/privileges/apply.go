@@ -3,6 +3,7 @@ package privileges
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 
@@ -13,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/sbt/audit"
 	audit2 "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/timeutil"
 	v2 "code.gitea.io/gitea/routers/api/v2/cache"
 	"code.gitea.io/gitea/services/casbingormadapter"
 	"code.gitea.io/gitea/services/forms"
@@ -24,6 +26,10 @@ type PrivilegesProcessor interface {
 	GrantAndRevokeTx(ctx context.Context, request forms.ApplyPrivilegeGroups, cache v2.RequestCache, auditInfo audit2.AuditRequiredParams) (forms.ApplyPrivilegesResponse, error)
 	ApplyPrivilegesRequest(ctx context.Context, request forms.ApplyPrivilegeRequest, auditInfo audit2.AuditRequiredParams) (forms.ApplyPrivilegesResponse, error)
 	GetPrivilegesRequest(ctx context.Context, privileges forms.GetPrivilegesRequest) (forms.ResponsePrivilegesGet, error)
+	ExplainPrivilegeRequest(ctx context.Context, request forms.ExplainPrivilegeRequest) (forms.ExplainPrivilegeResponse, error)
+	ExportPrivilegesState(ctx context.Context, tenants []string, allTenants bool) (forms.PrivilegesState, []string, error)
+	PlanPrivilegesState(ctx context.Context, desired forms.PrivilegesState) (forms.PrivilegesPlan, error)
+	ApplyPrivilegesState(ctx context.Context, desired forms.PrivilegesState, force bool, auditInfo audit2.AuditRequiredParams) (forms.PrivilegesStateApplyResponse, error)
 }
 
 type privilegeService struct {
@@ -129,21 +135,69 @@ func (p *privilegeService) GrantPrivileges(ctx context.Context, request forms.Ap
 			if !ok {
 				auditParams["error"] = "Error has occurred while searching for a role"
 				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, ErrWrongPrivelegeGroup{Name: role.String()}.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, ErrWrongPrivelegeGroup{Name: role.String()}.Error())
 				continue
 			}
 			tenant, err := cache.GetTenant(ctx, group.TenantKey, group.ProjectKey)
 			if err != nil {
 				auditParams["error"] = "Error has occurred while getting tenant"
 				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, err.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
 				continue
 			}
 			user, err := cache.GetUser(ctx, grant.UserExternalID)
 			if err != nil {
 				auditParams["error"] = "Error has occurred while getting user"
 				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, err.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+				continue
+			}
+			if group.RepositoryKey != "" {
+				auditParams["repository_key"] = group.RepositoryKey
+				// срок действия хранится для роли в проекте, для ролей на репозиторий он не поддерживается
+				if group.ExpiresAt != nil {
+					auditParams["error"] = "Error has occurred while granting privileges - expiration is not supported for repository privileges"
+					audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+					errorGroups = appendPrivilegeGroupError(errorGroups, group, ErrRepoPrivilegeExpiration{RepositoryKey: group.RepositoryKey}.Error())
+					continue
+				}
+				repo, err := cache.GetRepository(ctx, group.RepositoryKey, tenant.OrganizationID)
+				if err != nil {
+					auditParams["error"] = "Error has occurred while getting repository"
+					audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+					errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+					continue
+				}
+				err = role_model.GrantUserPermissionToRepositoryTx(
+					p.enforcer,
+					&user_model.User{ID: user.ID},
+					tenant.TenantID,
+					&org_model.Organization{ID: tenant.OrganizationID},
+					repo,
+					role,
+				)
+				if err != nil {
+					auditParams["error"] = "Error has occurred while granting privileges"
+					audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+					errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+					continue
+				}
+				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+				response.AppliedStatus.Grant = append(response.AppliedStatus.Grant, grant)
+				continue
+			}
+			// срок действия записывается до назначения роли, чтобы привилегия не осталась бессрочной при сбое записи срока
+			previousExpiration, err := role_model.GetPrivilegeExpiration(ctx, user.ID, tenant.OrganizationID)
+			if err != nil {
+				auditParams["error"] = "Error has occurred while getting privilege expiration"
+				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+				continue
+			}
+			if err = applyPrivilegeExpiration(ctx, user.ID, tenant.TenantID, tenant.OrganizationID, role, group.ExpiresAt); err != nil {
+				auditParams["error"] = "Error has occurred while setting privilege expiration"
+				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
 				continue
 			}
 			err = role_model.GrantUserPermissionToOrganizationTx(
@@ -154,11 +208,17 @@ func (p *privilegeService) GrantPrivileges(ctx context.Context, request forms.Ap
 				role,
 			)
 			if err != nil {
+				if restoreErr := restorePrivilegeExpiration(ctx, user.ID, tenant.OrganizationID, previousExpiration); restoreErr != nil {
+					log.Error("Error has occurred while restoring privilege expiration for userId: %d in orgId: %d. Error: %v", user.ID, tenant.OrganizationID, restoreErr)
+				}
 				auditParams["error"] = "Error has occurred while granting privileges"
 				audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, err.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
 				continue
 			}
+			if group.ExpiresAt != nil {
+				auditParams["expires_at"] = group.ExpiresAt.Format(time.RFC3339)
+			}
 			audit.CreateAndSendEvent(audit.PrivilegesGrantEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
 			response.AppliedStatus.Grant = append(response.AppliedStatus.Grant, grant)
 		}
@@ -192,21 +252,48 @@ func (p *privilegeService) RevokePrivileges(ctx context.Context, request forms.A
 			if !ok {
 				auditParams["error"] = "Error has occurred while searching for a role"
 				audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, ErrWrongPrivelegeGroup{Name: role.String()}.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, ErrWrongPrivelegeGroup{Name: role.String()}.Error())
 				continue
 			}
 			tenant, err := cache.GetTenant(ctx, group.TenantKey, group.ProjectKey)
 			if err != nil {
 				auditParams["error"] = "Error has occurred while getting tenant"
 				audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, err.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
 				continue
 			}
 			user, err := cache.GetUser(ctx, revoke.UserExternalID)
 			if err != nil {
 				auditParams["error"] = "Error has occurred while getting user"
 				audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, err.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+				continue
+			}
+			if group.RepositoryKey != "" {
+				auditParams["repository_key"] = group.RepositoryKey
+				repo, err := cache.GetRepository(ctx, group.RepositoryKey, tenant.OrganizationID)
+				if err != nil {
+					auditParams["error"] = "Error has occurred while getting repository"
+					audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+					errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+					continue
+				}
+				err = role_model.RevokeUserPermissionToRepositoryTx(
+					p.enforcer,
+					&user_model.User{ID: user.ID},
+					tenant.TenantID,
+					&org_model.Organization{ID: tenant.OrganizationID},
+					repo,
+					role,
+				)
+				if err != nil {
+					auditParams["error"] = "Error has occurred while revoking privileges"
+					audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+					errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
+					continue
+				}
+				audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+				response.AppliedStatus.Revoke = append(response.AppliedStatus.Revoke, revoke)
 				continue
 			}
 			err = role_model.RevokeUserPermissionToOrganizationTx(
@@ -219,9 +306,12 @@ func (p *privilegeService) RevokePrivileges(ctx context.Context, request forms.A
 			if err != nil {
 				auditParams["error"] = "Error has occurred while revoking privileges"
 				audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
-				errorGroups = appendPrivilegeGroupError(errorGroups, group.TenantKey, group.ProjectKey, group.PrivilegesGroup, err.Error())
+				errorGroups = appendPrivilegeGroupError(errorGroups, group, err.Error())
 				continue
 			}
+			if err = role_model.RemovePrivilegeExpiration(ctx, user.ID, tenant.OrganizationID); err != nil {
+				log.Error("Error has occurred while removing privilege expiration for userId: %d in orgId: %d. Error: %v", user.ID, tenant.OrganizationID, err)
+			}
 			audit.CreateAndSendEvent(audit.PrivilegesRevokeEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
 			response.AppliedStatus.Revoke = append(response.AppliedStatus.Revoke, revoke)
 		}
@@ -235,11 +325,35 @@ func (p *privilegeService) RevokePrivileges(ctx context.Context, request forms.A
 	return mergeResponseByUser(&response), nil
 }
 
-func appendPrivilegeGroupError(errorGroups []forms.PrivilegeGroupErr, tenantID string, projectKey string, privilegeGroup string, errMsg string) []forms.PrivilegeGroupErr {
+// applyPrivilegeExpiration сохраняет срок действия назначенной привилегии, без срока привилегия становится бессрочной
+func applyPrivilegeExpiration(ctx context.Context, userID int64, tenantID string, orgID int64, role role_model.Role, expiresAt *time.Time) error {
+	if expiresAt == nil {
+		return role_model.RemovePrivilegeExpiration(ctx, userID, orgID)
+	}
+	return role_model.SetPrivilegeExpiration(ctx, &role_model.ScPrivilegeExpiration{
+		UserID:    userID,
+		TenantID:  tenantID,
+		OrgID:     orgID,
+		Role:      role.String(),
+		ExpiresAt: timeutil.TimeStamp(expiresAt.Unix()),
+	})
+}
+
+// restorePrivilegeExpiration возвращает срок действия привилегии, действовавший до неудачного назначения роли
+func restorePrivilegeExpiration(ctx context.Context, userID, orgID int64, previous *role_model.ScPrivilegeExpiration) error {
+	if previous == nil {
+		return role_model.RemovePrivilegeExpiration(ctx, userID, orgID)
+	}
+	previous.ID = 0
+	return role_model.SetPrivilegeExpiration(ctx, previous)
+}
+
+func appendPrivilegeGroupError(errorGroups []forms.PrivilegeGroupErr, group forms.PrivilegeGroup, errMsg string) []forms.PrivilegeGroupErr {
 	return append(errorGroups, forms.PrivilegeGroupErr{
-		TenantID:       tenantID,
-		ProjectKey:     projectKey,
-		PrivilegeGroup: privilegeGroup,
+		TenantID:       group.TenantKey,
+		ProjectKey:     group.ProjectKey,
+		PrivilegeGroup: group.PrivilegesGroup,
+		RepositoryKey:  group.RepositoryKey,
 		ErrMsg:         errMsg,
 	})
 }
@@ -261,7 +375,7 @@ func mergeResponseByUser(response *forms.ApplyPrivilegesResponse) forms.ApplyPri
 		}
 		// формируем группу привилегий по конкретному user_key
 		for _, group := range grant.PrivilegeGroups {
-			groupKey := fmt.Sprintf("%s|%s|%s", group.TenantKey, group.ProjectKey, group.PrivilegesGroup)
+			groupKey := fmt.Sprintf("%s|%s|%s|%s", group.TenantKey, group.ProjectKey, group.RepositoryKey, group.PrivilegesGroup)
 			aggregatedGrants[grant.UserExternalID][groupKey] = group
 		}
 	}
@@ -272,7 +386,7 @@ func mergeResponseByUser(response *forms.ApplyPrivilegesResponse) forms.ApplyPri
 		}
 		// формируем группу привилегий по конкретному user_key
 		for _, group := range revoke.PrivilegeGroups {
-			groupKey := fmt.Sprintf("%s|%s|%s", group.TenantKey, group.ProjectKey, group.PrivilegesGroup)
+			groupKey := fmt.Sprintf("%s|%s|%s|%s", group.TenantKey, group.ProjectKey, group.RepositoryKey, group.PrivilegesGroup)
 			aggregatedRevokes[revoke.UserExternalID][groupKey] = group
 		}
 	}
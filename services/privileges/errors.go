@@ -24,3 +24,49 @@ func (err ErrWrongPrivelegeGroup) Unwrap() error {
 func IsProjectNameAlreadyUsed(err error) bool {
 	return errors.As(err, &ErrWrongPrivelegeGroup{})
 }
+
+// ErrInvalidPrivilegesState ошибка проверки декларативного описания привилегий
+type ErrInvalidPrivilegesState struct {
+	Err error
+}
+
+// Реализация интерфейса error
+func (err ErrInvalidPrivilegesState) Error() string {
+	return fmt.Sprintf("Err: invalid privileges state: %v", err.Err)
+}
+
+// Unwrap возвращает исходную ошибку проверки
+func (err ErrInvalidPrivilegesState) Unwrap() error {
+	return err.Err
+}
+
+// IsErrInvalidPrivilegesState проверяет, является ли ошибка ErrInvalidPrivilegesState
+func IsErrInvalidPrivilegesState(err error) bool {
+	return errors.As(err, &ErrInvalidPrivilegesState{})
+}
+
+// ErrPrivilegesStateRemovalsExceeded ошибка применения описания привилегий, снимающего слишком много привилегий без принудительного режима
+type ErrPrivilegesStateRemovalsExceeded struct {
+	Removals int
+	Limit    int
+}
+
+// Реализация интерфейса error
+func (err ErrPrivilegesStateRemovalsExceeded) Error() string {
+	return fmt.Sprintf("Err: privileges state removes %d privileges, more than %d allowed without force", err.Removals, err.Limit)
+}
+
+// IsErrPrivilegesStateRemovalsExceeded проверяет, является ли ошибка ErrPrivilegesStateRemovalsExceeded
+func IsErrPrivilegesStateRemovalsExceeded(err error) bool {
+	return errors.As(err, &ErrPrivilegesStateRemovalsExceeded{})
+}
+
+// ErrRepoPrivilegeExpiration ошибка назначения срока действия роли на репозиторий
+type ErrRepoPrivilegeExpiration struct {
+	RepositoryKey string
+}
+
+// Реализация интерфейса error
+func (err ErrRepoPrivilegeExpiration) Error() string {
+	return fmt.Sprintf("Err: expires_at is not supported for repository privileges [repository_key: %s]", err.RepositoryKey)
+}
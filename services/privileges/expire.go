@@ -0,0 +1,78 @@
+package privileges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/role_model"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RevokeExpiredPrivileges отзывает привилегии, срок действия которых истек.
+// Если роль пользователя в проекте изменилась после назначения срока, удаляется только устаревшая запись о сроке.
+// Ошибки отзыва отдельных привилегий объединяются и возвращаются после обработки всех записей
+func RevokeExpiredPrivileges(ctx context.Context) error {
+	expired, err := role_model.GetExpiredPrivileges(ctx, timeutil.TimeStampNow())
+	if err != nil {
+		log.Error("Error has occurred while getting expired privileges. Error: %v", err)
+		return fmt.Errorf("get expired privileges: %w", err)
+	}
+
+	// сбой отзыва одной привилегии не должен блокировать отзыв остальных
+	var errs []error
+	for _, expiration := range expired {
+		if err = revokeExpiredPrivilege(ctx, expiration); err != nil {
+			log.Error("Error has occurred while revoking expired privilege of userId: %d in orgId: %d. Error: %v", expiration.UserID, expiration.OrgID, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// revokeExpiredPrivilege отзывает одну привилегию с истекшим сроком действия
+func revokeExpiredPrivilege(ctx context.Context, expiration *role_model.ScPrivilegeExpiration) error {
+	auditParams := map[string]string{
+		"user_id":    strconv.FormatInt(expiration.UserID, 10),
+		"tenant_id":  expiration.TenantID,
+		"project_id": strconv.FormatInt(expiration.OrgID, 10),
+		"role":       expiration.Role,
+		"expires_at": expiration.ExpiresAt.FormatLong(),
+	}
+
+	currentRole, err := role_model.GetRoleForUser(expiration.UserID, expiration.OrgID, expiration.TenantID)
+	if err != nil {
+		log.Error("Error has occurred while getting role of userId: %d in orgId: %d. Error: %v", expiration.UserID, expiration.OrgID, err)
+		return fmt.Errorf("get role for user: %w", err)
+	}
+
+	if currentRole.String() == expiration.Role {
+		err = role_model.RevokeUserPermissionToOrganization(
+			&user_model.User{ID: expiration.UserID},
+			expiration.TenantID,
+			&organization.Organization{ID: expiration.OrgID},
+			currentRole,
+			true,
+		)
+		if err != nil {
+			log.Error("Error has occurred while revoking expired privilege of userId: %d in orgId: %d. Error: %v", expiration.UserID, expiration.OrgID, err)
+			auditParams["error"] = "Error has occurred while revoking expired privilege"
+			audit.CreateAndSendEvent(audit.ProjectTeamRightsRemoveEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusFailure, audit.EmptyRequiredField, auditParams)
+			return fmt.Errorf("revoke user permission to organization: %w", err)
+		}
+		audit.CreateAndSendEvent(audit.ProjectTeamRightsRemoveEvent, audit.EmptyRequiredField, audit.EmptyRequiredField, audit.StatusSuccess, audit.EmptyRequiredField, auditParams)
+	} else {
+		log.Debug("Role of userId: %d in orgId: %d has changed since expiration was set, removing stale expiration", expiration.UserID, expiration.OrgID)
+	}
+
+	if err = role_model.RemovePrivilegeExpiration(ctx, expiration.UserID, expiration.OrgID); err != nil {
+		log.Error("Error has occurred while removing privilege expiration of userId: %d in orgId: %d. Error: %v", expiration.UserID, expiration.OrgID, err)
+		return fmt.Errorf("remove privilege expiration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package privileges
+
+import (
+	"context"
+	"fmt"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/role_model"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v2/cache"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// ExplainPrivilegeRequest метод usecase для объяснения решения о доступе пользователя без его изменения
+func (p *privilegeService) ExplainPrivilegeRequest(ctx context.Context, request forms.ExplainPrivilegeRequest) (forms.ExplainPrivilegeResponse, error) {
+	var response forms.ExplainPrivilegeResponse
+	requestCache := cache.NewRequestCache(p.engine)
+
+	user, err := requestCache.GetUser(ctx, request.UserKey)
+	if err != nil {
+		log.Error("Error has occurred while getting user. Error: %v", err)
+		return response, err
+	}
+	tenantOrg, err := requestCache.GetTenantByKeys(ctx, request.TenantKey, request.ProjectKey)
+	if err != nil {
+		log.Error("Error has occurred while getting tenant. Error: %v", err)
+		return response, err
+	}
+	org := &organization.Organization{ID: tenantOrg.OrganizationID}
+
+	var explanation *role_model.PermissionExplanation
+	switch {
+	case request.CustomPrivilege != "":
+		privilege, _ := role_model.GetCustomPrivilegesByString(request.CustomPrivilege)
+		repo, err := requestCache.GetRepository(ctx, request.RepositoryKey, tenantOrg.OrganizationID)
+		if err != nil {
+			log.Error("Error has occurred while getting repository. Error: %v", err)
+			return response, err
+		}
+		action, ok := role_model.GetActionByString(request.Action)
+		if !ok {
+			action = role_model.READ
+			if repo.IsPrivate {
+				action = role_model.READ_PRIVATE
+			}
+		}
+		explanation, err = role_model.ExplainUserPermissionToRepositoryOrTeam(ctx, user, tenantOrg.TenantID, org, repo, action, privilege)
+		if err != nil {
+			log.Error("Error has occurred while explaining custom privilege %s for user %d. Error: %v", request.CustomPrivilege, user.ID, err)
+			return response, fmt.Errorf("explain custom privilege: %w", err)
+		}
+	case request.RepositoryKey != "":
+		action, _ := role_model.GetActionByString(request.Action)
+		repo, err := requestCache.GetRepository(ctx, request.RepositoryKey, tenantOrg.OrganizationID)
+		if err != nil {
+			log.Error("Error has occurred while getting repository. Error: %v", err)
+			return response, err
+		}
+		explanation, err = role_model.ExplainUserPermissionToRepository(ctx, user, tenantOrg.TenantID, org, repo, action)
+		if err != nil {
+			log.Error("Error has occurred while explaining action %s for user %d. Error: %v", request.Action, user.ID, err)
+			return response, fmt.Errorf("explain repository permission: %w", err)
+		}
+	default:
+		action, _ := role_model.GetActionByString(request.Action)
+		explanation, err = role_model.ExplainUserPermissionToOrganization(ctx, user, tenantOrg.TenantID, org, action)
+		if err != nil {
+			log.Error("Error has occurred while explaining action %s for user %d. Error: %v", request.Action, user.ID, err)
+			return response, fmt.Errorf("explain project permission: %w", err)
+		}
+	}
+
+	response.Allowed = explanation.Allowed
+	response.Rules = explanation.Rules
+	response.Source = privilegeSource(user)
+	return response, nil
+}
+
+// privilegeSource определяет источник привилегий пользователя. Привилегии IAM пользователей
+// перезаписываются из заголовка Ws-Privileges при входе, если синхронизация включена
+func privilegeSource(user *user_model.User) forms.PrivilegeSource {
+	if !setting.IAM.Enabled || !setting.IAM.WsPrivilegesEnabled || user.LoginType != auth_model.IAM {
+		return forms.PrivilegeSource{Type: forms.PrivilegeSourceManual}
+	}
+	source := forms.PrivilegeSource{Type: forms.PrivilegeSourceIAM}
+	if user.LastLoginUnix > 0 {
+		syncedAt := user.LastLoginUnix.AsTime()
+		source.SyncedAt = &syncedAt
+	}
+	return source
+}
@@ -3,6 +3,7 @@ package privileges
 import (
 	"context"
 
+	"code.gitea.io/gitea/models/role_model"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/routers/api/v2/cache"
 	"code.gitea.io/gitea/services/forms"
@@ -24,6 +25,22 @@ func (p *privilegeService) GetPrivilegesRequest(ctx context.Context, privileges
 			log.Error("Error has occurred while getting tenant. Error: %v", err)
 			return response, err
 		}
+		if request.RepositoryKey != "" {
+			repo, err := cache.GetRepository(ctx, request.RepositoryKey, tenant.OrganizationID)
+			if err != nil {
+				log.Error("Error has occurred while getting repository. Error: %v", err)
+				return response, err
+			}
+			repoRole, has, err := role_model.GetRepoRoleForUser(user.ID, tenant.TenantID, tenant.OrganizationID, repo.ID)
+			if err != nil {
+				log.Error("Error has occurred while getting repository privileges. Error: %v", err)
+				return response, err
+			}
+			if has {
+				addGrantToResponse(&response, request.UserKey, request.TenantKey, request.ProjectKey, request.RepositoryKey, []string{repoRole.String()})
+				continue
+			}
+		}
 		role, err := cache.GetPrivileges(ctx, tenant.OrganizationID)
 		if err != nil {
 			log.Error("Error has occurred while getting privileges. Error: %v", err)
@@ -35,12 +52,14 @@ func (p *privilegeService) GetPrivilegesRequest(ctx context.Context, privileges
 				privilegeGroups = append(privilegeGroups, v.Role.String())
 			}
 		}
-		addGrantToResponse(&response, request.UserKey, request.TenantKey, request.ProjectKey, privilegeGroups)
+		addGrantToResponse(&response, request.UserKey, request.TenantKey, request.ProjectKey, "", privilegeGroups)
 	}
 
 	return response, nil
 }
-func addGrantToResponse(response *forms.ResponsePrivilegesGet, userKey, tenantKey, projectKey string, privilegeGroups []string) {
+
+// addGrantToResponse добавляет в ответ роли пользователя. Для роли на весь проект repositoryKey пустой
+func addGrantToResponse(response *forms.ResponsePrivilegesGet, userKey, tenantKey, projectKey, repositoryKey string, privilegeGroups []string) {
 	if len(privilegeGroups) > 0 {
 		response.Grant = append(response.Grant, forms.PrivilegeGroupAssignmentGrant{
 			UserExternalID: userKey,
@@ -49,6 +68,7 @@ func addGrantToResponse(response *forms.ResponsePrivilegesGet, userKey, tenantKe
 					TenantKey:      tenantKey,
 					ProjectKey:     projectKey,
 					PrivilegeGroup: privilegeGroups,
+					RepositoryKey:  repositoryKey,
 				},
 			},
 		})
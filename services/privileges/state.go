@@ -0,0 +1,687 @@
+package privileges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/role_model"
+	"code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	audit2 "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// Типы политик casbin, которыми управляет декларативное описание привилегий
+const (
+	projectPolicyType    = "p"
+	repositoryPolicyType = "p6"
+	teamMemberPolicyType = "p4"
+	teamPolicyType       = "p5"
+)
+
+// statePolicyTypes типы политик, сохраняемые в снимок перед применением описания
+var statePolicyTypes = []string{projectPolicyType, repositoryPolicyType, teamMemberPolicyType, teamPolicyType}
+
+// stateIndex справочники для перевода идентификаторов политик casbin в ключи описания и обратно
+type stateIndex struct {
+	projectsByID  map[int64]*tenant.ScTenantOrganizations
+	projectsByKey map[string]*tenant.ScTenantOrganizations
+	repoKeys      map[int64]string
+	repoIDs       map[string]int64
+}
+
+// ExportPrivilegesState выгружает привилегии ролевой модели в декларативное описание. Область выгрузки задается явно: тенанты или все тенанты.
+// Политики, которые нельзя описать ключами (нет ключа проекта или репозитория), пропускаются с предупреждением
+func (p *privilegeService) ExportPrivilegesState(ctx context.Context, tenants []string, allTenants bool) (forms.PrivilegesState, []string, error) {
+	state := forms.PrivilegesState{Tenants: tenants, AllTenants: allTenants}
+	if err := state.ValidateScope(); err != nil {
+		return state, nil, ErrInvalidPrivilegesState{Err: err}
+	}
+	index, err := p.loadStateIndex(ctx)
+	if err != nil {
+		return state, nil, err
+	}
+
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	// project возвращает проект политики, если он входит в область описания
+	project := func(ptype string, rule []string, orgID string) (*tenant.ScTenantOrganizations, bool) {
+		id, err := strconv.ParseInt(orgID, 10, 64)
+		if err != nil {
+			warn("%s %v: incorrect project id", ptype, rule)
+			return nil, false
+		}
+		tenantOrg, ok := index.projectsByID[id]
+		if !ok {
+			warn("%s %v: project has no tenant and project keys", ptype, rule)
+			return nil, false
+		}
+		return tenantOrg, state.InScope(tenantOrg.OrgKey)
+	}
+	repository := func(ptype string, rule []string, repoID string) (string, bool) {
+		id, err := strconv.ParseInt(repoID, 10, 64)
+		if err != nil {
+			warn("%s %v: incorrect repository id", ptype, rule)
+			return "", false
+		}
+		key, ok := index.repoKeys[id]
+		if !ok {
+			warn("%s %v: repository has no repository key", ptype, rule)
+		}
+		return key, ok
+	}
+
+	userIDs := make(map[string]struct{})
+	projectRules, err := p.enforcer.GetNamedPolicy(projectPolicyType)
+	if err != nil {
+		return state, nil, fmt.Errorf("get %s policies: %w", projectPolicyType, err)
+	}
+	for _, rule := range projectRules {
+		if len(rule) != 4 {
+			continue
+		}
+		tenantOrg, ok := project(projectPolicyType, rule, rule[2])
+		if !ok || tenantOrg.TenantID != rule[1] {
+			continue
+		}
+		userIDs[rule[0]] = struct{}{}
+		state.Privileges = append(state.Privileges, forms.ProjectPrivilegeState{
+			UserKey: rule[0], TenantKey: tenantOrg.OrgKey, ProjectKey: tenantOrg.ProjectKey, PrivilegeGroup: rule[3],
+		})
+	}
+
+	repositoryRules, err := p.enforcer.GetNamedPolicy(repositoryPolicyType)
+	if err != nil {
+		return state, nil, fmt.Errorf("get %s policies: %w", repositoryPolicyType, err)
+	}
+	for _, rule := range repositoryRules {
+		if len(rule) != 5 {
+			continue
+		}
+		tenantOrg, ok := project(repositoryPolicyType, rule, rule[2])
+		if !ok || tenantOrg.TenantID != rule[1] {
+			continue
+		}
+		repoKey, ok := repository(repositoryPolicyType, rule, rule[3])
+		if !ok {
+			continue
+		}
+		userIDs[rule[0]] = struct{}{}
+		state.RepositoryPrivileges = append(state.RepositoryPrivileges, forms.RepositoryPrivilegeState{
+			UserKey: rule[0], TenantKey: tenantOrg.OrgKey, ProjectKey: tenantOrg.ProjectKey, RepositoryKey: repoKey, PrivilegeGroup: rule[4],
+		})
+	}
+
+	memberRules, err := p.enforcer.GetNamedPolicy(teamMemberPolicyType)
+	if err != nil {
+		return state, nil, fmt.Errorf("get %s policies: %w", teamMemberPolicyType, err)
+	}
+	for _, rule := range memberRules {
+		if len(rule) != 4 {
+			continue
+		}
+		tenantOrg, ok := project(teamMemberPolicyType, rule, rule[2])
+		if !ok || tenantOrg.TenantID != rule[1] {
+			continue
+		}
+		userIDs[rule[0]] = struct{}{}
+		state.TeamMembers = append(state.TeamMembers, forms.TeamMemberState{
+			UserKey: rule[0], TenantKey: tenantOrg.OrgKey, ProjectKey: tenantOrg.ProjectKey, Team: rule[3],
+		})
+	}
+
+	teamRules, err := p.enforcer.GetNamedPolicy(teamPolicyType)
+	if err != nil {
+		return state, nil, fmt.Errorf("get %s policies: %w", teamPolicyType, err)
+	}
+	for _, rule := range teamRules {
+		if len(rule) != 4 {
+			continue
+		}
+		tenantOrg, ok := project(teamPolicyType, rule, rule[1])
+		if !ok {
+			continue
+		}
+		repoKey, ok := repository(teamPolicyType, rule, rule[2])
+		if !ok {
+			continue
+		}
+		state.TeamPrivileges = append(state.TeamPrivileges, forms.TeamPrivilegeState{
+			Team: rule[0], TenantKey: tenantOrg.OrgKey, ProjectKey: tenantOrg.ProjectKey, RepositoryKey: repoKey, CustomPrivileges: customPrivilegesFromPolicy(rule[3]),
+		})
+	}
+
+	// в политиках пользователи хранятся идентификаторами, в описании - ключами
+	userKeys, err := getUserKeys(userIDs)
+	if err != nil {
+		return state, nil, err
+	}
+	state.Privileges = filterByUserKey(state.Privileges, userKeys, func(s *forms.ProjectPrivilegeState) *string { return &s.UserKey }, warn)
+	state.RepositoryPrivileges = filterByUserKey(state.RepositoryPrivileges, userKeys, func(s *forms.RepositoryPrivilegeState) *string { return &s.UserKey }, warn)
+	state.TeamMembers = filterByUserKey(state.TeamMembers, userKeys, func(s *forms.TeamMemberState) *string { return &s.UserKey }, warn)
+
+	groups, err := role_model.GetAllCustomPrivilegesGroup(ctx)
+	if err != nil {
+		return state, nil, fmt.Errorf("get custom groups: %w", err)
+	}
+	for _, group := range groups {
+		state.CustomGroups = append(state.CustomGroups, forms.CustomGroupState{
+			Code: group.Code, Name: group.Name, Privileges: strings.Split(group.Privileges, ","),
+		})
+	}
+
+	sortPrivilegesState(&state)
+	return state, warnings, nil
+}
+
+// PlanPrivilegesState сравнивает описание привилегий с текущим состоянием ролевой модели
+func (p *privilegeService) PlanPrivilegesState(ctx context.Context, desired forms.PrivilegesState) (forms.PrivilegesPlan, error) {
+	var plan forms.PrivilegesPlan
+	if err := desired.Validate(); err != nil {
+		return plan, ErrInvalidPrivilegesState{Err: err}
+	}
+	current, warnings, err := p.ExportPrivilegesState(ctx, desired.Tenants, desired.AllTenants)
+	if err != nil {
+		return plan, err
+	}
+	plan.Warnings = warnings
+
+	plan.Add.Privileges, plan.Remove.Privileges = diffStateEntries(desired.Privileges, current.Privileges, forms.ProjectPrivilegeState.Key)
+	plan.Add.RepositoryPrivileges, plan.Remove.RepositoryPrivileges = diffStateEntries(desired.RepositoryPrivileges, current.RepositoryPrivileges, forms.RepositoryPrivilegeState.Key)
+	plan.Add.TeamMembers, plan.Remove.TeamMembers = diffStateEntries(desired.TeamMembers, current.TeamMembers, forms.TeamMemberState.Key)
+	plan.Add.TeamPrivileges, plan.Remove.TeamPrivileges = diffStateEntries(desired.TeamPrivileges, current.TeamPrivileges, forms.TeamPrivilegeState.Key)
+
+	if len(desired.CustomGroups) > 0 {
+		plan.Warnings = append(plan.Warnings, "custom_groups are managed by configuration and are not applied")
+	}
+	if removals := plan.Remove.Count(); removals > setting.SourceControl.PrivilegesStateMaxRemovals {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("plan removes %d privileges, more than %d allowed without force", removals, setting.SourceControl.PrivilegesStateMaxRemovals))
+	}
+	return plan, nil
+}
+
+// ApplyPrivilegesState приводит ролевую модель в соответствие с описанием привилегий.
+// Все записи плана проверяются до изменений. План, снимающий больше PrivilegesStateMaxRemovals привилегий, применяется только при force.
+// Записи БД изменяются в одной транзакции, политики casbin - после ее фиксации; при ошибке политики восстанавливаются из снимка,
+// а повторное применение описания доводит изменения до конца
+func (p *privilegeService) ApplyPrivilegesState(ctx context.Context, desired forms.PrivilegesState, force bool, auditInfo audit2.AuditRequiredParams) (forms.PrivilegesStateApplyResponse, error) {
+	response := forms.PrivilegesStateApplyResponse{}
+	auditParams := map[string]string{
+		"tenants": strings.Join(desired.Tenants, ","),
+	}
+	if desired.AllTenants {
+		auditParams["tenants"] = "all"
+	}
+
+	plan, err := p.PlanPrivilegesState(ctx, desired)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while planning privileges state"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, err
+	}
+	response.Plan = plan
+	if plan.IsEmpty() {
+		return response, nil
+	}
+	auditParams["added"] = strconv.Itoa(plan.Add.Count())
+	auditParams["removed"] = strconv.Itoa(plan.Remove.Count())
+	if removals := plan.Remove.Count(); removals > setting.SourceControl.PrivilegesStateMaxRemovals && !force {
+		auditParams["error"] = "Privileges state removes too many privileges"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, ErrPrivilegesStateRemovalsExceeded{Removals: removals, Limit: setting.SourceControl.PrivilegesStateMaxRemovals}
+	}
+
+	index, err := p.loadStateIndex(ctx)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while loading privileges state index"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, err
+	}
+	changes, err := p.resolvePlan(ctx, index, plan)
+	if err != nil {
+		if isStateEntryNotFound(err) {
+			err = ErrInvalidPrivilegesState{Err: err}
+		}
+		auditParams["error"] = "Error has occurred while resolving privileges state"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, err
+	}
+
+	if err = db.WithTx(ctx, func(ctx context.Context) error {
+		return p.applyStateRows(ctx, changes)
+	}); err != nil {
+		log.Error("Error has occurred while applying privileges state rows. Error: %v", err)
+		auditParams["error"] = "Error has occurred while applying privileges state"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, err
+	}
+
+	snapshot, err := p.snapshotPolicies()
+	if err != nil {
+		auditParams["error"] = "Error has occurred while saving policies snapshot"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, err
+	}
+	if err = p.applyPolicies(changes); err != nil {
+		log.Error("Error has occurred while applying privileges state policies. Error: %v", err)
+		if restoreErr := p.restorePolicies(snapshot); restoreErr != nil {
+			log.Error("Error has occurred while restoring policies from snapshot. Error: %v", restoreErr)
+			err = errors.Join(err, fmt.Errorf("restore policies: %w", restoreErr))
+		}
+		auditParams["error"] = "Error has occurred while applying privileges state"
+		audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return response, err
+	}
+
+	audit.CreateAndSendEvent(audit.PrivilegesStateApplyEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	response.Applied = true
+	return response, nil
+}
+
+// stateChange изменение ролевой модели, полученное из записи плана
+type stateChange struct {
+	policyType string
+	rule       []string
+	user       *user_model.User
+	tenantID   string
+	org        *organization.Organization
+	repo       *repo_model.Repository
+	role       role_model.Role
+}
+
+// resolvePlan переводит записи плана в политики casbin. Сначала удаления, затем назначения
+func (p *privilegeService) resolvePlan(ctx context.Context, index *stateIndex, plan forms.PrivilegesPlan) (stateChanges, error) {
+	var result stateChanges
+	users := make(map[string]*user_model.User)
+	resolveUser := func(key string) (*user_model.User, error) {
+		if user, ok := users[key]; ok {
+			return user, nil
+		}
+		user, err := user_model.GetIAMUserByLoginName(ctx, p.engine, key)
+		if user_model.IsErrUserNotExist(err) {
+			user, err = user_model.GetUserByName(ctx, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get user %s: %w", key, err)
+		}
+		users[key] = user
+		return user, nil
+	}
+	resolveProject := func(tenantKey, projectKey string) (*tenant.ScTenantOrganizations, error) {
+		tenantOrg, ok := index.projectsByKey[tenantKey+"/"+projectKey]
+		if !ok {
+			return nil, tenant.ErrTenantOrganizationsNotExists{OrgKey: tenantKey, ProjectKey: projectKey}
+		}
+		return tenantOrg, nil
+	}
+	resolveRepo := func(repoKey string, orgID int64) (*repo_model.Repository, error) {
+		repoID, ok := index.repoIDs[repoKey]
+		if !ok {
+			return nil, fmt.Errorf("repository %s: %w", repoKey, repo_model.ErrorRepoKeyDoesntExists{RepoKey: repoKey})
+		}
+		repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+		if err != nil {
+			return nil, fmt.Errorf("get repository %s: %w", repoKey, err)
+		}
+		if repo.OwnerID != orgID {
+			return nil, ErrInvalidPrivilegesState{Err: fmt.Errorf("repository %s does not belong to project", repoKey)}
+		}
+		return repo, nil
+	}
+	resolveRole := func(name string) (role_model.Role, error) {
+		role, ok := role_model.GetRoleByString(name)
+		if !ok {
+			return 0, ErrWrongPrivelegeGroup{Name: name}
+		}
+		return role, nil
+	}
+	resolveTeam := func(name string, orgID int64) error {
+		if _, err := organization.GetTeam(ctx, orgID, name); err != nil {
+			return fmt.Errorf("get team %s: %w", name, err)
+		}
+		return nil
+	}
+
+	for _, entries := range []struct {
+		state forms.PrivilegesState
+		list  *[]stateChange
+	}{{plan.Remove, &result.remove}, {plan.Add, &result.add}} {
+		isAdd := entries.list == &result.add
+		for _, privilege := range entries.state.Privileges {
+			user, err := resolveUser(privilege.UserKey)
+			if err != nil {
+				return result, err
+			}
+			tenantOrg, err := resolveProject(privilege.TenantKey, privilege.ProjectKey)
+			if err != nil {
+				return result, err
+			}
+			role, err := resolveRole(privilege.PrivilegeGroup)
+			if err != nil {
+				return result, err
+			}
+			*entries.list = append(*entries.list, stateChange{
+				policyType: projectPolicyType,
+				user:       user,
+				tenantID:   tenantOrg.TenantID,
+				org:        &organization.Organization{ID: tenantOrg.OrganizationID},
+				role:       role,
+			})
+		}
+		for _, privilege := range entries.state.RepositoryPrivileges {
+			user, err := resolveUser(privilege.UserKey)
+			if err != nil {
+				return result, err
+			}
+			tenantOrg, err := resolveProject(privilege.TenantKey, privilege.ProjectKey)
+			if err != nil {
+				return result, err
+			}
+			repo, err := resolveRepo(privilege.RepositoryKey, tenantOrg.OrganizationID)
+			if err != nil {
+				return result, err
+			}
+			role, err := resolveRole(privilege.PrivilegeGroup)
+			if err != nil {
+				return result, err
+			}
+			*entries.list = append(*entries.list, stateChange{
+				policyType: repositoryPolicyType,
+				user:       user,
+				tenantID:   tenantOrg.TenantID,
+				org:        &organization.Organization{ID: tenantOrg.OrganizationID},
+				repo:       repo,
+				role:       role,
+			})
+		}
+		for _, member := range entries.state.TeamMembers {
+			user, err := resolveUser(member.UserKey)
+			if err != nil {
+				return result, err
+			}
+			tenantOrg, err := resolveProject(member.TenantKey, member.ProjectKey)
+			if err != nil {
+				return result, err
+			}
+			if isAdd {
+				if err = resolveTeam(member.Team, tenantOrg.OrganizationID); err != nil {
+					return result, err
+				}
+			}
+			*entries.list = append(*entries.list, stateChange{
+				policyType: teamMemberPolicyType,
+				rule:       []string{strconv.FormatInt(user.ID, 10), tenantOrg.TenantID, strconv.FormatInt(tenantOrg.OrganizationID, 10), member.Team},
+			})
+		}
+		for _, privilege := range entries.state.TeamPrivileges {
+			tenantOrg, err := resolveProject(privilege.TenantKey, privilege.ProjectKey)
+			if err != nil {
+				return result, err
+			}
+			repoID, ok := index.repoIDs[privilege.RepositoryKey]
+			if !ok {
+				return result, fmt.Errorf("repository %s: %w", privilege.RepositoryKey, repo_model.ErrorRepoKeyDoesntExists{RepoKey: privilege.RepositoryKey})
+			}
+			customPrivileges := make([]role_model.CustomPrivilege, 0, len(privilege.CustomPrivileges))
+			for _, name := range privilege.CustomPrivileges {
+				customPrivilege, ok := role_model.GetCustomPrivilegesByString(name)
+				if !ok {
+					return result, ErrInvalidPrivilegesState{Err: fmt.Errorf("custom privilege %s does not exist", name)}
+				}
+				customPrivileges = append(customPrivileges, customPrivilege)
+			}
+			if isAdd {
+				if err = resolveTeam(privilege.Team, tenantOrg.OrganizationID); err != nil {
+					return result, err
+				}
+			}
+			*entries.list = append(*entries.list, stateChange{
+				policyType: teamPolicyType,
+				rule:       []string{privilege.Team, strconv.FormatInt(tenantOrg.OrganizationID, 10), strconv.FormatInt(repoID, 10), role_model.ConvertCustomPrivilegeToNameOfPolicy(customPrivileges)},
+			})
+		}
+	}
+	return result, nil
+}
+
+// stateChanges изменения ролевой модели в порядке применения
+type stateChanges struct {
+	remove []stateChange
+	add    []stateChange
+}
+
+// applyStateRows изменяет записи БД плана: участие пользователей в репозиториях для ролей на репозиторий и сроки действия ролей в проекте.
+// Роли, назначенные описанием, бессрочные, поэтому срок действия удаляется как при снятии, так и при назначении роли в проекте
+func (p *privilegeService) applyStateRows(ctx context.Context, changes stateChanges) error {
+	for _, change := range changes.remove {
+		var err error
+		switch change.policyType {
+		case projectPolicyType:
+			err = role_model.RemovePrivilegeExpiration(ctx, change.user.ID, change.org.ID)
+		case repositoryPolicyType:
+			err = role_model.RemoveRepoPrivilegeCollaborator(ctx, change.user, change.repo)
+		}
+		if err != nil {
+			return fmt.Errorf("remove %s rows: %w", change.policyType, err)
+		}
+	}
+	for _, change := range changes.add {
+		var err error
+		switch change.policyType {
+		case projectPolicyType:
+			err = role_model.RemovePrivilegeExpiration(ctx, change.user.ID, change.org.ID)
+		case repositoryPolicyType:
+			err = role_model.AddRepoPrivilegeCollaborator(ctx, p.enforcer, change.user, change.org, change.repo, change.role)
+		}
+		if err != nil {
+			return fmt.Errorf("add %s rows: %w", change.policyType, err)
+		}
+	}
+	return nil
+}
+
+// applyPolicies применяет изменения политик casbin и сохраняет политики. Записи БД плана уже изменены applyStateRows
+func (p *privilegeService) applyPolicies(changes stateChanges) error {
+	for _, change := range changes.remove {
+		var err error
+		switch change.policyType {
+		case projectPolicyType:
+			err = role_model.RevokeUserPermissionToOrganizationTx(p.enforcer, change.user, change.tenantID, change.org, change.role)
+		case repositoryPolicyType:
+			err = role_model.RevokeUserRepositoryPolicyTx(p.enforcer, change.user, change.tenantID, change.org, change.repo, change.role)
+		default:
+			_, err = p.enforcer.RemoveNamedPolicy(change.policyType, change.rule)
+		}
+		if err != nil {
+			return fmt.Errorf("remove %s policy: %w", change.policyType, err)
+		}
+	}
+	for _, change := range changes.add {
+		var err error
+		switch change.policyType {
+		case projectPolicyType:
+			err = role_model.GrantUserPermissionToOrganizationTx(p.enforcer, change.user, change.tenantID, change.org, change.role)
+		case repositoryPolicyType:
+			err = role_model.GrantUserRepositoryPolicyTx(p.enforcer, change.user, change.tenantID, change.org, change.repo, change.role)
+		default:
+			_, err = p.enforcer.AddNamedPolicy(change.policyType, change.rule)
+		}
+		if err != nil {
+			return fmt.Errorf("add %s policy: %w", change.policyType, err)
+		}
+	}
+	if err := p.enforcer.SavePolicy(); err != nil {
+		return fmt.Errorf("save policy: %w", err)
+	}
+	return nil
+}
+
+// snapshotPolicies сохраняет политики, которыми управляет описание привилегий
+func (p *privilegeService) snapshotPolicies() (map[string][][]string, error) {
+	snapshot := make(map[string][][]string, len(statePolicyTypes))
+	for _, ptype := range statePolicyTypes {
+		rules, err := p.enforcer.GetNamedPolicy(ptype)
+		if err != nil {
+			return nil, fmt.Errorf("get %s policies: %w", ptype, err)
+		}
+		snapshot[ptype] = rules
+	}
+	return snapshot, nil
+}
+
+// restorePolicies восстанавливает политики из снимка
+func (p *privilegeService) restorePolicies(snapshot map[string][][]string) error {
+	for ptype, rules := range snapshot {
+		current, err := p.enforcer.GetNamedPolicy(ptype)
+		if err != nil {
+			return fmt.Errorf("get %s policies: %w", ptype, err)
+		}
+		if len(current) > 0 {
+			if _, err = p.enforcer.RemoveNamedPolicies(ptype, current); err != nil {
+				return fmt.Errorf("remove %s policies: %w", ptype, err)
+			}
+		}
+		if len(rules) > 0 {
+			if _, err = p.enforcer.AddNamedPolicies(ptype, rules); err != nil {
+				return fmt.Errorf("add %s policies: %w", ptype, err)
+			}
+		}
+	}
+	return p.enforcer.SavePolicy()
+}
+
+// loadStateIndex загружает ключи проектов и репозиториев
+func (p *privilegeService) loadStateIndex(ctx context.Context) (*stateIndex, error) {
+	tenantOrgs, err := tenant.GetAllTenantOrganizations(ctx)
+	if err != nil {
+		log.Error("Error has occurred while getting tenant organizations. Error: %v", err)
+		return nil, fmt.Errorf("get tenant organizations: %w", err)
+	}
+	repoKeys, err := repo_model.NewRepoKeyDB(p.engine).GetAllRepoKeys(ctx)
+	if err != nil {
+		log.Error("Error has occurred while getting repository keys. Error: %v", err)
+		return nil, fmt.Errorf("get repository keys: %w", err)
+	}
+
+	index := &stateIndex{
+		projectsByID:  make(map[int64]*tenant.ScTenantOrganizations, len(tenantOrgs)),
+		projectsByKey: make(map[string]*tenant.ScTenantOrganizations, len(tenantOrgs)),
+		repoKeys:      make(map[int64]string, len(repoKeys)),
+		repoIDs:       make(map[string]int64, len(repoKeys)),
+	}
+	for _, tenantOrg := range tenantOrgs {
+		index.projectsByID[tenantOrg.OrganizationID] = tenantOrg
+		index.projectsByKey[tenantOrg.OrgKey+"/"+tenantOrg.ProjectKey] = tenantOrg
+	}
+	for _, repoKey := range repoKeys {
+		repoID, err := strconv.ParseInt(repoKey.RepoID, 10, 64)
+		if err != nil {
+			continue
+		}
+		index.repoKeys[repoID] = repoKey.RepoKey
+		index.repoIDs[repoKey.RepoKey] = repoID
+	}
+	return index, nil
+}
+
+// getUserKeys возвращает ключи пользователей по идентификаторам: login_name пользователя IAM или имя пользователя
+func getUserKeys(userIDs map[string]struct{}) (map[string]string, error) {
+	ids := make([]int64, 0, len(userIDs))
+	for userID := range userIDs {
+		if id, err := strconv.ParseInt(userID, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	users, err := user_model.GetUsersByIDs(ids)
+	if err != nil {
+		log.Error("Error has occurred while getting users by ids. Error: %v", err)
+		return nil, fmt.Errorf("get users: %w", err)
+	}
+	keys := make(map[string]string, len(users))
+	for _, user := range users {
+		key := user.LoginName
+		if key == "" {
+			key = user.LowerName
+		}
+		keys[strconv.FormatInt(user.ID, 10)] = key
+	}
+	return keys, nil
+}
+
+// filterByUserKey заменяет идентификаторы пользователей на ключи, записи удаленных пользователей пропускаются
+func filterByUserKey[T any](entries []T, userKeys map[string]string, userKey func(*T) *string, warn func(string, ...interface{})) []T {
+	result := entries[:0]
+	for idx := range entries {
+		field := userKey(&entries[idx])
+		key, ok := userKeys[*field]
+		if !ok {
+			warn("user %s does not exist", *field)
+			continue
+		}
+		*field = key
+		result = append(result, entries[idx])
+	}
+	return result
+}
+
+// customPrivilegesFromPolicy переводит имя политики p5 (например, vB_cPR) в названия кастомных привилегий
+func customPrivilegesFromPolicy(name string) []string {
+	privileges := make([]string, 0, 4)
+	for _, short := range strings.Split(name, "_") {
+		if privilege, ok := role_model.PolicyOfNames[short]; ok {
+			privileges = append(privileges, privilege.String())
+		}
+	}
+	return privileges
+}
+
+// diffStateEntries возвращает записи, которые нужно добавить и удалить, чтобы получить желаемое состояние
+func diffStateEntries[T any](desired, current []T, key func(T) string) (add, remove []T) {
+	currentKeys := make(map[string]struct{}, len(current))
+	for _, entry := range current {
+		currentKeys[key(entry)] = struct{}{}
+	}
+	desiredKeys := make(map[string]struct{}, len(desired))
+	for _, entry := range desired {
+		desiredKeys[key(entry)] = struct{}{}
+		if _, ok := currentKeys[key(entry)]; !ok {
+			add = append(add, entry)
+		}
+	}
+	for _, entry := range current {
+		if _, ok := desiredKeys[key(entry)]; !ok {
+			remove = append(remove, entry)
+		}
+	}
+	return add, remove
+}
+
+// isStateEntryNotFound проверяет, что запись описания ссылается на несуществующий объект
+func isStateEntryNotFound(err error) bool {
+	return user_model.IsErrUserNotExist(err) || tenant.IsTenantOrganizationsNotExists(err) ||
+		repo_model.IsErrorRepoKeyDoesntExists(err) || errors.As(err, &repo_model.ErrRepoNotExist{}) ||
+		errors.As(err, &organization.ErrTeamNotExist{}) || IsProjectNameAlreadyUsed(err)
+}
+
+// sortPrivilegesState упорядочивает записи описания для стабильной выгрузки
+func sortPrivilegesState(state *forms.PrivilegesState) {
+	sort.Slice(state.Privileges, func(i, j int) bool { return state.Privileges[i].Key() < state.Privileges[j].Key() })
+	sort.Slice(state.RepositoryPrivileges, func(i, j int) bool {
+		return state.RepositoryPrivileges[i].Key() < state.RepositoryPrivileges[j].Key()
+	})
+	sort.Slice(state.TeamMembers, func(i, j int) bool { return state.TeamMembers[i].Key() < state.TeamMembers[j].Key() })
+	sort.Slice(state.TeamPrivileges, func(i, j int) bool { return state.TeamPrivileges[i].Key() < state.TeamPrivileges[j].Key() })
+	sort.Slice(state.CustomGroups, func(i, j int) bool { return state.CustomGroups[i].Code < state.CustomGroups[j].Code })
+}
@@ -12,7 +12,6 @@ import (
 	"code.gitea.io/gitea/models/role_model"
 	tenant_model "code.gitea.io/gitea/models/tenant"
 	"code.gitea.io/gitea/models/user"
-	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/sbt/audit"
 	"code.gitea.io/gitea/modules/setting"
@@ -20,20 +19,19 @@ import (
 	"code.gitea.io/gitea/services/forms"
 )
 
-// CreateProject cоздание проекта под тенантом
-func CreateProject(ctx context.APIContext, projectRequest forms.CreateProjectRequest) (*forms.CreateProjectResponse, error) {
+// CreateProject cоздание проекта под тенантом от имени пользователя doer
+func CreateProject(ctx cctx.Context, doer *user.User, projectRequest forms.CreateProjectRequest) (*forms.CreateProjectResponse, error) {
 	auditParams := map[string]string{
 		"tenant_key":   projectRequest.TenantKey,
 		"project_key":  projectRequest.ProjectKey,
 		"project_name": projectRequest.Name,
 	}
 
-	u := ctx.Doer
 	var userName string
 	var userID string
-	if u != nil {
-		userName = u.Name
-		userID = strconv.FormatInt(u.ID, 10)
+	if doer != nil {
+		userName = doer.Name
+		userID = strconv.FormatInt(doer.ID, 10)
 	} else {
 		userName = audit.EmptyRequiredField
 		userID = audit.EmptyRequiredField
@@ -109,7 +107,7 @@ func CreateProject(ctx context.APIContext, projectRequest forms.CreateProjectReq
 			}
 		}
 
-		if err = role_model.GrantUserPermissionToOrganization(ctx.Doer, tenant.ID, org, role_model.OWNER); err != nil {
+		if err = role_model.GrantUserPermissionToOrganization(doer, tenant.ID, org, role_model.OWNER); err != nil {
 			log.Error("Error has occurred while graining user permission to organization: %v", err)
 			return nil, fmt.Errorf("create project: %w", err)
 		}
@@ -125,7 +123,7 @@ func CreateProject(ctx context.APIContext, projectRequest forms.CreateProjectReq
 }
 
 // GetProject получение проекта по tenantKey и projectKey
-func GetProject(ctx context.APIContext, tenantKey, projectKey string) (forms.ProjectInfoResponse, error) {
+func GetProject(ctx cctx.Context, tenantKey, projectKey string) (forms.ProjectInfoResponse, error) {
 	tenantOrganization, err := tenant_model.GetTenantOrganizationsByKeys(ctx, tenantKey, projectKey)
 	if err != nil {
 		if errors.Is(err, tenant_model.ErrTenantOrganizationsNotExists{}) {
@@ -158,7 +156,7 @@ func GetProject(ctx context.APIContext, tenantKey, projectKey string) (forms.Pro
 }
 
 // createOrg создание проекта и tenantOrganization
-func createOrg(ctx context.APIContext, org *organization.Organization, orgKey, projectKey string) (*organization.Organization, error) {
+func createOrg(ctx cctx.Context, org *organization.Organization, orgKey, projectKey string) (*organization.Organization, error) {
 	tenant, has, err := tenant_model.GetTenantByOrgKey(ctx, orgKey)
 	if err != nil {
 		return nil, err
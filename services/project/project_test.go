@@ -42,7 +42,7 @@ func TestCreateProject_Success(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	response, err := CreateProject(ctx, projectRequest)
+	response, err := CreateProject(ctx, nil, projectRequest)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, response)
@@ -17,6 +17,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/events/v2/generic"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -29,6 +30,7 @@ import (
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
+	kafka_notification "code.gitea.io/gitea/modules/notification/kafka"
 	"code.gitea.io/gitea/modules/references"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -190,7 +192,7 @@ func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.U
 	pr.Merger = doer
 	pr.MergerID = doer.ID
 
-	if _, err := pr.SetMerged(hammerCtx); err != nil {
+	if _, err := setMerged(hammerCtx, pr); err != nil {
 		log.Error("SetMerged %-v: %v", pr, err)
 	}
 
@@ -556,7 +558,7 @@ func MergedManually(pr *issues_model.PullRequest, doer *user_model.User, baseGit
 		pr.MergerID = doer.ID
 
 		var merged bool
-		if merged, err = pr.SetMerged(ctx); err != nil {
+		if merged, err = setMerged(ctx, pr); err != nil {
 			return err
 		} else if !merged {
 			return fmt.Errorf("SetMerged failed")
@@ -570,3 +572,18 @@ func MergedManually(pr *issues_model.PullRequest, doer *user_model.User, baseGit
 	log.Info("manuallyMerged[%d]: Marked as manually merged into %s/%s by commit id: %s", pr.ID, pr.BaseRepo.Name, pr.BaseBranch, commitID)
 	return nil
 }
+
+// setMerged помечает запрос на слияние слитым и в той же транзакции сохраняет в outbox событие Kafka о слиянии
+func setMerged(ctx context.Context, pr *issues_model.PullRequest) (merged bool, err error) {
+	err = db.WithTx(ctx, func(ctx context.Context) error {
+		merged, err = pr.SetMerged(ctx)
+		if err != nil || !merged {
+			return err
+		}
+		return kafka_notification.EnqueuePullRequestEvent(ctx, pr.Merger, pr, generic.MERGE)
+	})
+	if err != nil {
+		return false, err
+	}
+	return merged, nil
+}
@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/default_reviewers/default_reviewers_db"
+	"code.gitea.io/gitea/models/events/v2/generic"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -26,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
+	kafka_notification "code.gitea.io/gitea/modules/notification/kafka"
 	"code.gitea.io/gitea/modules/process"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/sbt/audit"
@@ -70,7 +72,12 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, pull *issu
 	pr.CommitsAhead = divergence.Ahead
 	pr.CommitsBehind = divergence.Behind
 
-	if err := issues_model.NewPullRequest(ctx, repo, pull, labelIDs, uuids, pr); err != nil {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := issues_model.NewPullRequest(ctx, repo, pull, labelIDs, uuids, pr); err != nil {
+			return err
+		}
+		return kafka_notification.EnqueuePullRequestEvent(ctx, pull.Poster, pr, generic.CREATE)
+	}); err != nil {
 		log.Error("Error has occurred while creating pull request. Error: %v", err)
 		auditParams["error"] = "Error has occurred while creating pull request"
 		audit.CreateAndSendEvent(audit.PRCreateEvent, pull.Poster.Name, strconv.FormatInt(pull.PosterID, 10), audit.StatusFailure, audit.EmptyRequiredField, auditParams)
@@ -0,0 +1,71 @@
+package pull
+
+import (
+	gocontext "context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/models/review_settings"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// QualityGateStatus результат quality gate провайдера для пулл реквеста. Пустой статус означает, что провайдер
+// еще не прислал отчет по последнему коммиту ни для пулл реквеста, ни для его исходной ветки
+type QualityGateStatus struct {
+	Provider quality_gate.Provider
+	Status   quality_gate.Status
+	Report   *quality_gate.Report
+}
+
+// GetPullRequestQualityGate возвращает отчет провайдера quality gate по последнему коммиту пулл реквеста
+var GetPullRequestQualityGate = func(ctx gocontext.Context, pr *issues.PullRequest, provider quality_gate.Provider) (*quality_gate.Report, error) {
+	return quality_gate.GetPullRequestReport(ctx, provider, pr.BaseRepoID, pr.Index, pr.HeadRepoID, pr.HeadBranch, pr.HeadCommitID)
+}
+
+// GetPullRequestQualityGates возвращает результаты провайдеров quality gate, обязательных по настройке ревью
+func GetPullRequestQualityGates(ctx gocontext.Context, rs *review_settings.ReviewSettings, pr *issues.PullRequest) ([]QualityGateStatus, error) {
+	statuses := make([]QualityGateStatus, 0, len(rs.RequiredQualityGates))
+	for _, provider := range rs.RequiredQualityGates {
+		report, err := GetPullRequestQualityGate(ctx, pr, quality_gate.Provider(provider))
+		if err != nil {
+			return nil, fmt.Errorf("get %s report: %w", provider, err)
+		}
+		status := QualityGateStatus{Provider: quality_gate.Provider(provider), Report: report}
+		// Отчет по другому коммиту не описывает текущее состояние пулл реквеста
+		if report != nil && report.CommitSHA != pr.HeadCommitID {
+			status.Report = nil
+		}
+		if status.Report != nil {
+			status.Status = report.Status
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// MergeBlockedByQualityGates возвращает причину запрета слияния по обязательным quality gate или пустую строку,
+// если слияние разрешено. При ошибке получения отчетов слияние запрещается
+func MergeBlockedByQualityGates(ctx gocontext.Context, rs *review_settings.ReviewSettings, pr *issues.PullRequest) string {
+	if len(rs.RequiredQualityGates) == 0 {
+		return ""
+	}
+
+	statuses, err := GetPullRequestQualityGates(ctx, rs, pr)
+	if err != nil {
+		log.Error("Error has occurred while getting quality gates of pull request %d. Error: %v", pr.ID, err)
+		return "Failed to get quality gate results"
+	}
+
+	for _, status := range statuses {
+		switch status.Status {
+		case quality_gate.StatusOK:
+			continue
+		case "":
+			return fmt.Sprintf("Quality gate %s has not reported a result for the head commit", status.Provider)
+		default:
+			return fmt.Sprintf("Quality gate %s has failed", status.Provider)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+//go:build !correct
+
+package pull
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/models/review_settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubGetPullRequestQualityGate(reports map[quality_gate.Provider]*quality_gate.Report, err error) func() {
+	original := GetPullRequestQualityGate
+	GetPullRequestQualityGate = func(_ context.Context, _ *issues.PullRequest, provider quality_gate.Provider) (*quality_gate.Report, error) {
+		return reports[provider], err
+	}
+	return func() { GetPullRequestQualityGate = original }
+}
+
+func TestMergeBlockedByQualityGates(t *testing.T) {
+	ctx := context.Background()
+	pr := &issues.PullRequest{ID: 1, HeadCommitID: "head"}
+	rs := &review_settings.ReviewSettings{RequiredQualityGates: []string{"sonarqube", "trivy"}}
+
+	t.Run("no required gates", func(t *testing.T) {
+		defer stubGetPullRequestQualityGate(nil, errors.New("must not be called"))()
+
+		assert.Empty(t, MergeBlockedByQualityGates(ctx, &review_settings.ReviewSettings{}, pr))
+	})
+
+	t.Run("all gates passed", func(t *testing.T) {
+		defer stubGetPullRequestQualityGate(map[quality_gate.Provider]*quality_gate.Report{
+			quality_gate.ProviderSonarQube: {CommitSHA: "head", Status: quality_gate.StatusOK},
+			quality_gate.ProviderTrivy:     {CommitSHA: "head", Status: quality_gate.StatusOK},
+		}, nil)()
+
+		assert.Empty(t, MergeBlockedByQualityGates(ctx, rs, pr))
+	})
+
+	t.Run("gate has not reported", func(t *testing.T) {
+		defer stubGetPullRequestQualityGate(map[quality_gate.Provider]*quality_gate.Report{
+			quality_gate.ProviderSonarQube: {CommitSHA: "head", Status: quality_gate.StatusOK},
+		}, nil)()
+
+		assert.Equal(t, "Quality gate trivy has not reported a result for the head commit", MergeBlockedByQualityGates(ctx, rs, pr))
+	})
+
+	t.Run("gate reported another commit", func(t *testing.T) {
+		defer stubGetPullRequestQualityGate(map[quality_gate.Provider]*quality_gate.Report{
+			quality_gate.ProviderSonarQube: {CommitSHA: "head", Status: quality_gate.StatusOK},
+			quality_gate.ProviderTrivy:     {CommitSHA: "previous", Status: quality_gate.StatusOK},
+		}, nil)()
+
+		assert.Equal(t, "Quality gate trivy has not reported a result for the head commit", MergeBlockedByQualityGates(ctx, rs, pr))
+	})
+
+	t.Run("gate failed", func(t *testing.T) {
+		defer stubGetPullRequestQualityGate(map[quality_gate.Provider]*quality_gate.Report{
+			quality_gate.ProviderSonarQube: {CommitSHA: "head", Status: quality_gate.StatusError},
+			quality_gate.ProviderTrivy:     {CommitSHA: "head", Status: quality_gate.StatusOK},
+		}, nil)()
+
+		assert.Equal(t, "Quality gate sonarqube has failed", MergeBlockedByQualityGates(ctx, rs, pr))
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		defer stubGetPullRequestQualityGate(nil, errors.New("database is unavailable"))()
+
+		assert.Equal(t, "Failed to get quality gate results", MergeBlockedByQualityGates(ctx, rs, pr))
+	})
+}
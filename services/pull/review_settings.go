@@ -168,6 +168,18 @@ func (r reviewSettingsManager) CheckReviewSettingsProtections(ctx gocontext.Cont
 				Reason: "The head branch is behind the base branch",
 			}
 		}
+
+		if reason := MergeBlockedByUnitStatus(ctx, rs, pr); reason != "" {
+			return models.ErrDisallowedToMerge{
+				Reason: reason,
+			}
+		}
+
+		if reason := MergeBlockedByQualityGates(ctx, rs, pr); reason != "" {
+			return models.ErrDisallowedToMerge{
+				Reason: reason,
+			}
+		}
 	}
 
 	if skipProtectedFilesCheck {
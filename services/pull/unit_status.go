@@ -0,0 +1,165 @@
+package pull
+
+import (
+	gocontext "context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/gitnames"
+	"code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/review_settings"
+	"code.gitea.io/gitea/models/unit_links/unit_links_db"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/private/task_tracker_client"
+)
+
+// UnitStatus статус юнита трекера задач, связанного с пулл реквестом. Пустой статус означает, что юнит не найден в трекере
+type UnitStatus struct {
+	Code   string
+	Status string
+}
+
+var (
+	unitStatusResolver     task_tracker_client.Resolver
+	unitStatusResolverErr  error
+	unitStatusResolverOnce sync.Once
+)
+
+func getUnitStatusResolver() (task_tracker_client.Resolver, error) {
+	unitStatusResolverOnce.Do(func() {
+		unitStatusResolver, unitStatusResolverErr = task_tracker_client.NewResolverFromSettings()
+	})
+	return unitStatusResolver, unitStatusResolverErr
+}
+
+// GetPullRequestUnitStatuses возвращает статусы юнитов трекера задач, связанных с пулл реквестом
+var GetPullRequestUnitStatuses = func(ctx gocontext.Context, pr *issues.PullRequest) ([]UnitStatus, error) {
+	links, err := unit_links_db.NewUnitLinkDB(db.GetEngine(ctx)).GetUnitLinks(ctx, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get unit links: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	resolver, err := getUnitStatusResolver()
+	if err != nil {
+		return nil, fmt.Errorf("create task tracker resolver: %w", err)
+	}
+	tracker, err := resolver.ResolveByRepoID(ctx, pr.BaseRepoID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve task tracker: %w", err)
+	}
+
+	codes := make([]gitnames.UnitCode, 0, len(links))
+	for _, link := range links {
+		codes = append(codes, gitnames.UnitCode{Code: link.ToUnitID})
+	}
+	descriptions, err := tracker.Provider.GetDescriptions(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("get unit descriptions: %w", err)
+	}
+
+	statuses := make(map[string]string, len(descriptions.Content))
+	for _, content := range descriptions.Content {
+		for _, attribute := range content.AttributesAndValues {
+			if attribute.Attribute.Code == task_tracker_client.StatusAttributeName {
+				statuses[content.Unit.Code] = attribute.Value.Name
+			}
+		}
+	}
+
+	result := make([]UnitStatus, 0, len(codes))
+	for _, code := range codes {
+		result = append(result, UnitStatus{Code: code.Code, Status: statuses[code.Code]})
+	}
+	return result, nil
+}
+
+// IsUnitStatusAllowed проверяет, что статус юнита входит в разрешенные статусы настройки ревью. Регистр не учитывается
+func IsUnitStatusAllowed(rs *review_settings.ReviewSettings, status string) bool {
+	if status == "" {
+		return false
+	}
+	for _, allowed := range rs.AllowedUnitStatuses {
+		if strings.EqualFold(strings.TrimSpace(allowed), status) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCachedPullRequestUnitStatuses возвращает статусы юнитов пулл реквеста из кэша, а если их там нет, получает их
+// из трекера задач и сохраняет в кэш на TaskTracker.UnitStatusCacheTTL. Ключ включает head коммит пулл реквеста,
+// поэтому после push статусы запрашиваются заново. Ошибки не кэшируются
+func GetCachedPullRequestUnitStatuses(ctx gocontext.Context, pr *issues.PullRequest) ([]UnitStatus, error) {
+	ttl := int64(setting.TaskTracker.UnitStatusCacheTTL.Seconds())
+	c := cache.GetCache()
+	if c == nil || ttl <= 0 {
+		return GetPullRequestUnitStatuses(ctx, pr)
+	}
+
+	key := fmt.Sprintf("pull_unit_statuses:%d:%s", pr.ID, pr.HeadCommitID)
+	if cached, ok := c.Get(key).(string); ok {
+		var units []UnitStatus
+		if err := json.Unmarshal([]byte(cached), &units); err == nil {
+			return units, nil
+		}
+	}
+
+	units, err := GetPullRequestUnitStatuses(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(units)
+	if err != nil {
+		log.Error("Error has occurred while marshaling unit statuses of pull request %d. Error: %v", pr.ID, err)
+		return units, nil
+	}
+	if err = c.Put(key, string(data), ttl); err != nil {
+		log.Error("Error has occurred while caching unit statuses of pull request %d. Error: %v", pr.ID, err)
+	}
+	return units, nil
+}
+
+// MergeBlockedByUnitStatus возвращает причину запрета слияния по статусам юнитов трекера задач или пустую строку,
+// если слияние разрешено. Статусы всегда запрашиваются в трекере. При ошибке получения статусов слияние запрещается
+func MergeBlockedByUnitStatus(ctx gocontext.Context, rs *review_settings.ReviewSettings, pr *issues.PullRequest) string {
+	if !rs.RequireUnitStatus {
+		return ""
+	}
+	units, err := GetPullRequestUnitStatuses(ctx, pr)
+	return unitStatusBlockReason(rs, pr, units, err)
+}
+
+// MergeBlockedByUnitStatusCached работает как MergeBlockedByUnitStatus, но берет статусы из кэша.
+// Используется для отображения страницы пулл реквеста, чтобы не запрашивать трекер при каждом просмотре
+func MergeBlockedByUnitStatusCached(ctx gocontext.Context, rs *review_settings.ReviewSettings, pr *issues.PullRequest) string {
+	if !rs.RequireUnitStatus {
+		return ""
+	}
+	units, err := GetCachedPullRequestUnitStatuses(ctx, pr)
+	return unitStatusBlockReason(rs, pr, units, err)
+}
+
+func unitStatusBlockReason(rs *review_settings.ReviewSettings, pr *issues.PullRequest, units []UnitStatus, err error) string {
+	if err != nil {
+		log.Error("Error has occurred while getting unit statuses of pull request %d. Error: %v", pr.ID, err)
+		return "Failed to get statuses of linked task tracker units"
+	}
+	if len(units) == 0 {
+		return "Pull request is not linked to any task tracker unit"
+	}
+
+	for _, unit := range units {
+		if IsUnitStatusAllowed(rs, unit.Status) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("None of the linked task tracker units is in an allowed status (%s)", strings.Join(rs.AllowedUnitStatuses, ", "))
+}
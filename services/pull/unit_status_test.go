@@ -0,0 +1,92 @@
+//go:build !correct
+
+package pull
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/review_settings"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubGetPullRequestUnitStatuses(units []UnitStatus, err error) func() {
+	original := GetPullRequestUnitStatuses
+	GetPullRequestUnitStatuses = func(_ context.Context, _ *issues.PullRequest) ([]UnitStatus, error) {
+		return units, err
+	}
+	return func() { GetPullRequestUnitStatuses = original }
+}
+
+func TestMergeBlockedByUnitStatus(t *testing.T) {
+	ctx := context.Background()
+	pr := &issues.PullRequest{ID: 1}
+	rs := &review_settings.ReviewSettings{RequireUnitStatus: true, AllowedUnitStatuses: []string{"Done", "Ready for merge"}}
+
+	t.Run("disabled", func(t *testing.T) {
+		defer stubGetPullRequestUnitStatuses(nil, errors.New("must not be called"))()
+
+		assert.Empty(t, MergeBlockedByUnitStatus(ctx, &review_settings.ReviewSettings{}, pr))
+	})
+
+	t.Run("no linked units", func(t *testing.T) {
+		defer stubGetPullRequestUnitStatuses(nil, nil)()
+
+		assert.Equal(t, "Pull request is not linked to any task tracker unit", MergeBlockedByUnitStatus(ctx, rs, pr))
+	})
+
+	t.Run("allowed status ignores case", func(t *testing.T) {
+		defer stubGetPullRequestUnitStatuses([]UnitStatus{{Code: "PROJ-1", Status: "In progress"}, {Code: "PROJ-2", Status: "done"}}, nil)()
+
+		assert.Empty(t, MergeBlockedByUnitStatus(ctx, rs, pr))
+	})
+
+	t.Run("no allowed status", func(t *testing.T) {
+		defer stubGetPullRequestUnitStatuses([]UnitStatus{{Code: "PROJ-1", Status: "In progress"}, {Code: "PROJ-2"}}, nil)()
+
+		assert.Equal(t, "None of the linked task tracker units is in an allowed status (Done, Ready for merge)", MergeBlockedByUnitStatus(ctx, rs, pr))
+	})
+
+	t.Run("tracker error", func(t *testing.T) {
+		defer stubGetPullRequestUnitStatuses(nil, errors.New("tracker is unavailable"))()
+
+		assert.Equal(t, "Failed to get statuses of linked task tracker units", MergeBlockedByUnitStatus(ctx, rs, pr))
+	})
+}
+
+func TestGetCachedPullRequestUnitStatuses(t *testing.T) {
+	oldCacheService, oldTTL := setting.CacheService, setting.TaskTracker.UnitStatusCacheTTL
+	defer func() { setting.CacheService, setting.TaskTracker.UnitStatusCacheTTL = oldCacheService, oldTTL }()
+	setting.CacheService.Enabled = true
+	setting.CacheService.Adapter = "memory"
+	setting.CacheService.Interval = 60
+	setting.TaskTracker.UnitStatusCacheTTL = time.Minute
+	assert.NoError(t, cache.NewContext())
+
+	ctx := context.Background()
+	calls := 0
+	original := GetPullRequestUnitStatuses
+	GetPullRequestUnitStatuses = func(_ context.Context, _ *issues.PullRequest) ([]UnitStatus, error) {
+		calls++
+		return []UnitStatus{{Code: "PROJ-1", Status: "Done"}}, nil
+	}
+	defer func() { GetPullRequestUnitStatuses = original }()
+
+	pr := &issues.PullRequest{ID: 42, HeadCommitID: "a1"}
+	for i := 0; i < 2; i++ {
+		units, err := GetCachedPullRequestUnitStatuses(ctx, pr)
+		assert.NoError(t, err)
+		assert.Equal(t, []UnitStatus{{Code: "PROJ-1", Status: "Done"}}, units)
+	}
+	assert.Equal(t, 1, calls)
+
+	pr.HeadCommitID = "b2"
+	_, err := GetCachedPullRequestUnitStatuses(ctx, pr)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,76 @@
+package quality_gate
+
+import (
+	"sort"
+	"strconv"
+
+	"code.gitea.io/gitea/models/quality_gate"
+)
+
+// Evaluate проверяет условия quality gate на метриках отчета. Условие на метрику, которой нет в отчете, не выполнено.
+// Возвращает итоговый статус и метрики отчета с результатами проверки условий
+func Evaluate(values map[string]float64, conditions []quality_gate.Condition) (quality_gate.Status, []*quality_gate.Metric) {
+	status := quality_gate.StatusOK
+	metrics := make([]*quality_gate.Metric, 0, len(values)+len(conditions))
+	byKey := make(map[string]*quality_gate.Metric, len(values))
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		metric := &quality_gate.Metric{Key: key, Value: formatFloat(values[key])}
+		metrics = append(metrics, metric)
+		byKey[key] = metric
+	}
+
+	for _, condition := range conditions {
+		metric, ok := byKey[condition.Metric]
+		if !ok || metric.IsCondition() {
+			metric = &quality_gate.Metric{Key: condition.Metric}
+			if ok {
+				metric.Value = byKey[condition.Metric].Value
+			}
+			metrics = append(metrics, metric)
+		}
+		metric.Operator = condition.Operator
+		metric.Threshold = formatFloat(condition.Threshold)
+		metric.Status = quality_gate.StatusOK
+
+		value, reported := values[condition.Metric]
+		if !reported || isConditionFailed(value, condition) {
+			metric.Status = quality_gate.StatusError
+			status = quality_gate.StatusError
+		}
+	}
+	return status, metrics
+}
+
+// ValidateConditions проверяет операторы и метрики условий quality gate
+func ValidateConditions(conditions []quality_gate.Condition) bool {
+	for _, condition := range conditions {
+		if condition.Metric == "" {
+			return false
+		}
+		if condition.Operator != quality_gate.OperatorGreaterThan && condition.Operator != quality_gate.OperatorLessThan {
+			return false
+		}
+	}
+	return true
+}
+
+func isConditionFailed(value float64, condition quality_gate.Condition) bool {
+	switch condition.Operator {
+	case quality_gate.OperatorGreaterThan:
+		return value > condition.Threshold
+	case quality_gate.OperatorLessThan:
+		return value < condition.Threshold
+	default:
+		return true
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,145 @@
+package quality_gate
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/modules/json"
+)
+
+// semgrepProvider принимает JSON отчет semgrep --json. Метрики: findings_total и findings_<severity>
+type semgrepProvider struct{}
+
+type semgrepReport struct {
+	Results *[]struct {
+		CheckID string `json:"check_id"`
+		Extra   struct {
+			Severity string `json:"severity"`
+		} `json:"extra"`
+	} `json:"results"`
+}
+
+func (semgrepProvider) Name() quality_gate.Provider {
+	return quality_gate.ProviderSemgrep
+}
+
+func (semgrepProvider) ParseMetrics(body []byte) (map[string]float64, error) {
+	var report semgrepReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, fmt.Errorf("unmarshal semgrep report: %w", err)
+	}
+	if report.Results == nil {
+		return nil, fmt.Errorf("semgrep report has no results")
+	}
+
+	metrics := map[string]float64{
+		"findings_total":   0,
+		"findings_error":   0,
+		"findings_warning": 0,
+		"findings_info":    0,
+	}
+	for _, result := range *report.Results {
+		metrics["findings_total"]++
+		metrics["findings_"+severityKey(result.Extra.Severity)]++
+	}
+	return metrics, nil
+}
+
+func (semgrepProvider) DefaultConditions() []quality_gate.Condition {
+	return []quality_gate.Condition{
+		{Metric: "findings_error", Operator: quality_gate.OperatorGreaterThan, Threshold: 0},
+	}
+}
+
+// trivyProvider принимает JSON отчет trivy --format json. Метрики: vulnerabilities_total, vulnerabilities_<severity>,
+// misconfigurations_total и secrets_total
+type trivyProvider struct{}
+
+type trivyReport struct {
+	Results *[]struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+		Misconfigurations []json.RawMessage `json:"Misconfigurations"`
+		Secrets           []json.RawMessage `json:"Secrets"`
+	} `json:"Results"`
+}
+
+func (trivyProvider) Name() quality_gate.Provider {
+	return quality_gate.ProviderTrivy
+}
+
+func (trivyProvider) ParseMetrics(body []byte) (map[string]float64, error) {
+	var report trivyReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, fmt.Errorf("unmarshal trivy report: %w", err)
+	}
+	if report.Results == nil {
+		return nil, fmt.Errorf("trivy report has no results")
+	}
+
+	metrics := map[string]float64{
+		"vulnerabilities_total":    0,
+		"vulnerabilities_critical": 0,
+		"vulnerabilities_high":     0,
+		"vulnerabilities_medium":   0,
+		"vulnerabilities_low":      0,
+		"vulnerabilities_unknown":  0,
+		"misconfigurations_total":  0,
+		"secrets_total":            0,
+	}
+	for _, result := range *report.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			metrics["vulnerabilities_total"]++
+			metrics["vulnerabilities_"+severityKey(vulnerability.Severity)]++
+		}
+		metrics["misconfigurations_total"] += float64(len(result.Misconfigurations))
+		metrics["secrets_total"] += float64(len(result.Secrets))
+	}
+	return metrics, nil
+}
+
+func (trivyProvider) DefaultConditions() []quality_gate.Condition {
+	return []quality_gate.Condition{
+		{Metric: "vulnerabilities_critical", Operator: quality_gate.OperatorGreaterThan, Threshold: 0},
+		{Metric: "vulnerabilities_high", Operator: quality_gate.OperatorGreaterThan, Threshold: 0},
+	}
+}
+
+// dependencyScannerProvider принимает отчет сканера зависимостей в нормализованном виде {"metrics": {"<key>": <number>}}.
+// Условий по умолчанию нет, их нужно задать в настройках репозитория
+type dependencyScannerProvider struct{}
+
+type normalizedReport struct {
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+func (dependencyScannerProvider) Name() quality_gate.Provider {
+	return quality_gate.ProviderDependencyScanner
+}
+
+func (dependencyScannerProvider) ParseMetrics(body []byte) (map[string]float64, error) {
+	var report normalizedReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, fmt.Errorf("unmarshal dependency scanner report: %w", err)
+	}
+	if report.Metrics == nil {
+		return nil, fmt.Errorf("dependency scanner report has no metrics")
+	}
+	return report.Metrics, nil
+}
+
+func (dependencyScannerProvider) DefaultConditions() []quality_gate.Condition {
+	return nil
+}
+
+func severityKey(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	if severity == "" {
+		return "unknown"
+	}
+	return severity
+}
@@ -0,0 +1,53 @@
+package quality_gate
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models/quality_gate"
+)
+
+// Provider провайдер quality gate, отчеты которого принимаются входящим webhook /webhooks/quality_gates/{provider}
+type Provider interface {
+	// Name имя провайдера в настройках и адресе webhook
+	Name() quality_gate.Provider
+	// ParseMetrics разбирает тело отчета в нормализованные метрики
+	ParseMetrics(body []byte) (map[string]float64, error)
+	// DefaultConditions условия, проверяемые, если условия не заданы в настройках репозитория
+	DefaultConditions() []quality_gate.Condition
+}
+
+var webhookProviders = map[quality_gate.Provider]Provider{}
+
+func registerProvider(provider Provider) {
+	webhookProviders[provider.Name()] = provider
+}
+
+func init() {
+	registerProvider(semgrepProvider{})
+	registerProvider(trivyProvider{})
+	registerProvider(dependencyScannerProvider{})
+}
+
+// ErrUnknownProvider провайдер quality gate не существует или не принимает отчеты через webhook
+type ErrUnknownProvider struct {
+	Provider string
+}
+
+func (e ErrUnknownProvider) Error() string {
+	return fmt.Sprintf("unknown quality gate provider: %s", e.Provider)
+}
+
+// IsErrUnknownProvider проверяет, что ошибка ErrUnknownProvider
+func IsErrUnknownProvider(err error) bool {
+	_, ok := err.(ErrUnknownProvider)
+	return ok
+}
+
+// GetWebhookProvider возвращает провайдер, принимающий отчеты через webhook
+func GetWebhookProvider(name string) (Provider, error) {
+	provider, ok := webhookProviders[quality_gate.Provider(name)]
+	if !ok {
+		return nil, ErrUnknownProvider{Provider: name}
+	}
+	return provider, nil
+}
@@ -0,0 +1,96 @@
+//go:build !correct
+
+package quality_gate
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/quality_gate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemgrepParseMetrics(t *testing.T) {
+	body := []byte(`{"results":[
+		{"check_id":"go.sql-injection","extra":{"severity":"ERROR"}},
+		{"check_id":"go.weak-hash","extra":{"severity":"WARNING"}},
+		{"check_id":"go.unused","extra":{"severity":"WARNING"}}
+	],"errors":[]}`)
+
+	metrics, err := semgrepProvider{}.ParseMetrics(body)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"findings_total":   3,
+		"findings_error":   1,
+		"findings_warning": 2,
+		"findings_info":    0,
+	}, metrics)
+
+	_, err = semgrepProvider{}.ParseMetrics([]byte(`{"version":"1.0"}`))
+	assert.Error(t, err)
+}
+
+func TestTrivyParseMetrics(t *testing.T) {
+	body := []byte(`{"Results":[
+		{"Target":"go.mod","Vulnerabilities":[{"VulnerabilityID":"CVE-1","Severity":"CRITICAL"},{"VulnerabilityID":"CVE-2","Severity":"LOW"}]},
+		{"Target":"Dockerfile","Misconfigurations":[{"ID":"DS002"}],"Secrets":[{"RuleID":"aws-access-key-id"}]}
+	]}`)
+
+	metrics, err := trivyProvider{}.ParseMetrics(body)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, metrics["vulnerabilities_total"])
+	assert.EqualValues(t, 1, metrics["vulnerabilities_critical"])
+	assert.EqualValues(t, 0, metrics["vulnerabilities_high"])
+	assert.EqualValues(t, 1, metrics["vulnerabilities_low"])
+	assert.EqualValues(t, 1, metrics["misconfigurations_total"])
+	assert.EqualValues(t, 1, metrics["secrets_total"])
+}
+
+func TestDependencyScannerParseMetrics(t *testing.T) {
+	metrics, err := dependencyScannerProvider{}.ParseMetrics([]byte(`{"metrics":{"outdated":4,"vulnerable":1}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"outdated": 4, "vulnerable": 1}, metrics)
+
+	_, err = dependencyScannerProvider{}.ParseMetrics([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	values := map[string]float64{"coverage": 75.5, "findings_error": 0}
+
+	t.Run("conditions passed", func(t *testing.T) {
+		status, metrics := Evaluate(values, []quality_gate.Condition{
+			{Metric: "coverage", Operator: quality_gate.OperatorLessThan, Threshold: 70},
+			{Metric: "findings_error", Operator: quality_gate.OperatorGreaterThan, Threshold: 0},
+		})
+
+		assert.Equal(t, quality_gate.StatusOK, status)
+		require.Len(t, metrics, 2)
+		assert.Equal(t, &quality_gate.Metric{Key: "coverage", Value: "75.5", Operator: quality_gate.OperatorLessThan, Threshold: "70", Status: quality_gate.StatusOK}, metrics[0])
+	})
+
+	t.Run("condition failed", func(t *testing.T) {
+		status, _ := Evaluate(values, []quality_gate.Condition{
+			{Metric: "coverage", Operator: quality_gate.OperatorLessThan, Threshold: 80},
+		})
+
+		assert.Equal(t, quality_gate.StatusError, status)
+	})
+
+	t.Run("metric is not reported", func(t *testing.T) {
+		status, metrics := Evaluate(values, []quality_gate.Condition{
+			{Metric: "duplicated_lines", Operator: quality_gate.OperatorGreaterThan, Threshold: 3},
+		})
+
+		assert.Equal(t, quality_gate.StatusError, status)
+		require.Len(t, metrics, 3)
+		assert.Equal(t, &quality_gate.Metric{Key: "duplicated_lines", Operator: quality_gate.OperatorGreaterThan, Threshold: "3", Status: quality_gate.StatusError}, metrics[2])
+	})
+
+	t.Run("no conditions", func(t *testing.T) {
+		status, metrics := Evaluate(values, nil)
+
+		assert.Equal(t, quality_gate.StatusOK, status)
+		assert.Len(t, metrics, 2)
+	})
+}
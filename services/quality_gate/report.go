@@ -0,0 +1,141 @@
+package quality_gate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/models/quality_gate"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/webhook_sonar"
+)
+
+// sonarAnalysedAtLayout формат поля analysedAt webhook SonarQube
+const sonarAnalysedAtLayout = "2006-01-02T15:04:05-0700"
+
+// ErrInvalidReport тело отчета провайдера не удалось разобрать
+type ErrInvalidReport struct {
+	Provider quality_gate.Provider
+	Err      error
+}
+
+func (e ErrInvalidReport) Error() string {
+	return fmt.Sprintf("invalid %s report: %v", e.Provider, e.Err)
+}
+
+// IsErrInvalidReport проверяет, что ошибка ErrInvalidReport
+func IsErrInvalidReport(err error) bool {
+	_, ok := err.(ErrInvalidReport)
+	return ok
+}
+
+// ReportOptions ветка, коммит и пулл реквест, по которым построен отчет
+type ReportOptions struct {
+	Branch           string
+	CommitSHA        string
+	PullRequestIndex int64
+}
+
+// ReceiveReport разбирает отчет провайдера, проверяет условия из настроек репозитория (или условия провайдера по умолчанию)
+// и сохраняет результат вместо предыдущего отчета по той же ветке или пулл реквесту
+func ReceiveReport(ctx context.Context, provider Provider, settings *quality_gate.Settings, opts ReportOptions, body []byte) (*quality_gate.Report, []*quality_gate.Metric, error) {
+	values, err := provider.ParseMetrics(body)
+	if err != nil {
+		return nil, nil, ErrInvalidReport{Provider: provider.Name(), Err: err}
+	}
+
+	status, metrics := Evaluate(values, EffectiveConditions(provider, settings))
+
+	report := &quality_gate.Report{
+		RepoID:           settings.RepoID,
+		Provider:         provider.Name(),
+		Branch:           opts.Branch,
+		PullRequestIndex: opts.PullRequestIndex,
+		CommitSHA:        opts.CommitSHA,
+		Status:           status,
+		AnalysedUnix:     timeutil.TimeStampNow(),
+	}
+	if err = quality_gate.SaveReport(ctx, report, metrics); err != nil {
+		return nil, nil, err
+	}
+	return report, metrics, nil
+}
+
+// SaveSonarReport сохраняет результат quality gate из webhook SonarQube, чтобы проверка слияния учитывала его наравне
+// с другими провайдерами. Webhook без quality gate не сохраняется
+func SaveSonarReport(ctx context.Context, repoID int64, hook *webhook_sonar.WebHook) error {
+	if hook.QualityGate.Status == "" {
+		return nil
+	}
+
+	pullRequestIndex, err := sonarPullRequestIndex(hook)
+	if err != nil {
+		return err
+	}
+	report := &quality_gate.Report{
+		RepoID:           repoID,
+		Provider:         quality_gate.ProviderSonarQube,
+		Branch:           hook.Branch.Name,
+		PullRequestIndex: pullRequestIndex,
+		CommitSHA:        hook.Revision,
+		Status:           sonarStatus(hook.QualityGate.Status),
+	}
+	if analysedAt, err := time.Parse(sonarAnalysedAtLayout, hook.AnalysedAt); err == nil {
+		report.AnalysedUnix = timeutil.TimeStamp(analysedAt.Unix())
+	} else {
+		report.AnalysedUnix = timeutil.TimeStampNow()
+	}
+
+	metrics := make([]*quality_gate.Metric, 0, len(hook.QualityGate.Conditions))
+	for _, condition := range hook.QualityGate.Conditions {
+		metrics = append(metrics, &quality_gate.Metric{
+			Key:       condition.Metric,
+			Value:     condition.Value,
+			Operator:  sonarOperator(condition.Operator),
+			Threshold: condition.ErrorThreshold,
+			Status:    sonarStatus(condition.Status),
+		})
+	}
+	return quality_gate.SaveReport(ctx, report, metrics)
+}
+
+// GetSonarReportAnalysedUnix возвращает время анализа сохраненного отчета SonarQube по ветке или пулл реквесту
+// из webhook, 0 если отчета нет
+func GetSonarReportAnalysedUnix(ctx context.Context, repoID int64, hook *webhook_sonar.WebHook) (timeutil.TimeStamp, error) {
+	pullRequestIndex, err := sonarPullRequestIndex(hook)
+	if err != nil {
+		return 0, err
+	}
+	report, err := quality_gate.GetReport(ctx, repoID, quality_gate.ProviderSonarQube, hook.Branch.Name, pullRequestIndex)
+	if err != nil || report == nil {
+		return 0, err
+	}
+	return report.AnalysedUnix, nil
+}
+
+// sonarPullRequestIndex номер пулл реквеста анализа SonarQube, 0 если анализ построен по ветке
+func sonarPullRequestIndex(hook *webhook_sonar.WebHook) (int64, error) {
+	if hook.Branch.Type != "PULL_REQUEST" {
+		return 0, nil
+	}
+	index, err := strconv.ParseInt(hook.Branch.Name, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse sonarqube pull request key %q: %w", hook.Branch.Name, err)
+	}
+	return index, nil
+}
+
+func sonarStatus(status string) quality_gate.Status {
+	if status == "OK" {
+		return quality_gate.StatusOK
+	}
+	return quality_gate.StatusError
+}
+
+func sonarOperator(operator string) quality_gate.Operator {
+	if operator == "LESS_THAN" {
+		return quality_gate.OperatorLessThan
+	}
+	return quality_gate.OperatorGreaterThan
+}
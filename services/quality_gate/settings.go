@@ -0,0 +1,72 @@
+package quality_gate
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/quality_gate"
+)
+
+// ErrInvalidConditions условия quality gate содержат пустую метрику или неизвестный оператор
+type ErrInvalidConditions struct{}
+
+func (e ErrInvalidConditions) Error() string {
+	return "quality gate conditions must have a metric and GT or LT operator"
+}
+
+// IsErrInvalidConditions проверяет, что ошибка ErrInvalidConditions
+func IsErrInvalidConditions(err error) bool {
+	_, ok := err.(ErrInvalidConditions)
+	return ok
+}
+
+// SaveSettings создает или обновляет настройки провайдера quality gate репозитория. Токен приема отчетов создается
+// вместе с настройками или по запросу regenerateToken и возвращается в поле Token только в этом случае.
+// Возвращает true, если настройки созданы
+func SaveSettings(ctx context.Context, repoID int64, provider Provider, conditions []quality_gate.Condition, regenerateToken bool) (*quality_gate.Settings, bool, error) {
+	if !ValidateConditions(conditions) {
+		return nil, false, ErrInvalidConditions{}
+	}
+
+	var (
+		settings *quality_gate.Settings
+		created  bool
+	)
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		settings, err = quality_gate.GetSettings(ctx, repoID, provider.Name())
+		if err != nil && !quality_gate.IsErrSettingsNotExist(err) {
+			return err
+		}
+
+		if settings == nil {
+			created = true
+			settings = &quality_gate.Settings{RepoID: repoID, Provider: provider.Name(), Conditions: conditions}
+			if err = settings.GenerateToken(); err != nil {
+				return err
+			}
+			return quality_gate.InsertSettings(ctx, settings)
+		}
+
+		settings.Conditions = conditions
+		if regenerateToken {
+			if err = settings.GenerateToken(); err != nil {
+				return err
+			}
+		}
+		return quality_gate.UpdateSettings(ctx, settings)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("save %s settings: %w", provider.Name(), err)
+	}
+	return settings, created, nil
+}
+
+// EffectiveConditions возвращает условия, по которым проверяются отчеты провайдера: из настроек или условия по умолчанию
+func EffectiveConditions(provider Provider, settings *quality_gate.Settings) []quality_gate.Condition {
+	if len(settings.Conditions) == 0 {
+		return provider.DefaultConditions()
+	}
+	return settings.Conditions
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/code_hub_counter_task"
+	"code.gitea.io/gitea/models/code_hub_counter_task/code_hub_counter_task_db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// StarRepo ставит или снимает звезду пользователя на репозитории. Новая звезда учитывается в статистике Code Hub
+func StarRepo(ctx context.Context, userID, repoID int64, star bool) error {
+	staring := repo_model.IsStaring(ctx, userID, repoID)
+	if err := repo_model.StarRepo(userID, repoID, star); err != nil {
+		return err
+	}
+
+	if star && !staring {
+		addCodeHubUsage(ctx, repoID, userID, code_hub_counter_task.StarRepositoryAction)
+	}
+	return nil
+}
+
+// addCodeHubUsage создает задачу подсчета статистики использования репозитория, если подсчет включен.
+// Ошибка не прерывает действие пользователя и только логируется
+func addCodeHubUsage(ctx context.Context, repoID, userID int64, action code_hub_counter_task.CodeHubAction) {
+	if !setting.CodeHub.CodeHubMetricEnabled {
+		return
+	}
+	if err := code_hub_counter_task_db.New().InsertTask(ctx, repoID, userID, action); err != nil {
+		log.Error("Error has occurred while inserting %s task for repo %d. Error: %v", action, repoID, err)
+	}
+}
@@ -4,6 +4,7 @@
 package repository
 
 import (
+	"code.gitea.io/gitea/models/code_hub_counter_task"
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -150,6 +151,7 @@ func ForkRepository(ctx context.Context, doer, owner *user_model.User, opts Fork
 	}
 
 	notification.NotifyForkRepository(ctx, doer, opts.BaseRepo, repo)
+	addCodeHubUsage(ctx, opts.BaseRepo.ID, doer.ID, code_hub_counter_task.ForkRepositoryAction)
 
 	return repo, nil
 }
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/role_model"
 	system_model "code.gitea.io/gitea/models/system"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -65,6 +66,12 @@ func DeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_mod
 		return err
 	}
 
+	if setting.SourceControl.TenantWithRoleModeEnabled {
+		if err := role_model.RemoveRepoPrivilegesByRepoID(ctx, repo.ID); err != nil {
+			log.Error("Error has occurred while removing privileges of repoId: %d. Error: %v", repo.ID, err)
+		}
+	}
+
 	return packages_model.UnlinkRepositoryFromAllPackages(ctx, repo.ID)
 }
 
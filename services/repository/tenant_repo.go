@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/repo_marks"
+	"code.gitea.io/gitea/models/role_model"
+	tenant_model "code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+)
+
+// ErrNoProjectPermission пользователь не имеет права создавать репозитории в проекте
+type ErrNoProjectPermission struct {
+	UserName       string
+	OrganizationID int64
+}
+
+// IsErrNoProjectPermission проверяет, является ли ошибка ErrNoProjectPermission
+func IsErrNoProjectPermission(err error) bool {
+	return errors.As(err, &ErrNoProjectPermission{})
+}
+
+func (err ErrNoProjectPermission) Error() string {
+	return fmt.Sprintf("user does not have permission to project [user: %s, organization_id: %d]", err.UserName, err.OrganizationID)
+}
+
+// ErrProjectNotPublic метка устанавливается только для репозиториев публичного проекта
+type ErrProjectNotPublic struct {
+	ProjectKey string
+}
+
+// IsErrProjectNotPublic проверяет, является ли ошибка ErrProjectNotPublic
+func IsErrProjectNotPublic(err error) bool {
+	return errors.As(err, &ErrProjectNotPublic{})
+}
+
+func (err ErrProjectNotPublic) Error() string {
+	return fmt.Sprintf("project is not public [project_key: %s]", err.ProjectKey)
+}
+
+// ErrRepoNotPublic метка устанавливается только для публичного репозитория
+type ErrRepoNotPublic struct {
+	RepoKey string
+}
+
+// IsErrRepoNotPublic проверяет, является ли ошибка ErrRepoNotPublic
+func IsErrRepoNotPublic(err error) bool {
+	return errors.As(err, &ErrRepoNotPublic{})
+}
+
+func (err ErrRepoNotPublic) Error() string {
+	return fmt.Sprintf("repository is not public [repo_key: %s]", err.RepoKey)
+}
+
+// TenantRepoKeyDB хранилище внешних ключей репозиториев
+type TenantRepoKeyDB interface {
+	GetRepoByKey(ctx context.Context, key string) (*repo_model.ScRepoKey, error)
+	GetRepoByRepoID(ctx context.Context, repoID string) (*repo_model.ScRepoKey, error)
+	UpdateRepoKey(ctx context.Context, repoKey *repo_model.ScRepoKey) error
+}
+
+// RepoMarkInserter установка меток репозитория
+type RepoMarkInserter interface {
+	InsertRepoMark(ctx context.Context, repoKey string, expertID int64, repoMark repo_marks.RepoMark) error
+}
+
+// TenantRepoService создание репозиториев и установка метки CodeHub в проектах tenant по внешним ключам
+type TenantRepoService struct {
+	checkUserPermissionFn role_model.CheckUserPermissionFnType
+	repoKeyDB             TenantRepoKeyDB
+	repoMarks             RepoMarkInserter
+	codeHubMark           repo_marks.RepoMark
+}
+
+// NewTenantRepoService получить TenantRepoService
+func NewTenantRepoService(checkPermFn role_model.CheckUserPermissionFnType, repoKeyDB TenantRepoKeyDB, repoMarks RepoMarkInserter, codeHubMark repo_marks.RepoMark) TenantRepoService {
+	return TenantRepoService{
+		checkUserPermissionFn: checkPermFn,
+		repoKeyDB:             repoKeyDB,
+		repoMarks:             repoMarks,
+		codeHubMark:           codeHubMark,
+	}
+}
+
+// CreateTenantOrgRepoOptions параметры создания репозитория в проекте tenant
+type CreateTenantOrgRepoOptions struct {
+	TenantKey     string
+	ProjectKey    string
+	RepositoryKey string
+	Name          string
+	Description   string
+	DefaultBranch string
+	IsPrivate     bool
+}
+
+// CreateTenantOrgRepo создание репозитория с внешним ключом opts.RepositoryKey в проекте tenant от имени пользователя doer.
+// Возвращает созданный репозиторий и проект, в котором он создан
+func (s TenantRepoService) CreateTenantOrgRepo(ctx context.Context, doer *user_model.User, opts CreateTenantOrgRepoOptions, auditInfo auditutils.AuditRequiredParams) (*repo_model.Repository, *organization.Organization, error) {
+	auditParams := map[string]string{
+		"repository": opts.Name,
+		"owner":      doer.Name,
+	}
+	sendFailure := func(message string) {
+		auditParams["error"] = message
+		audit.CreateAndSendEvent(audit.RepositoryCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+	}
+
+	tenantOrg, err := tenant_model.GetTenantOrganizationsByKeys(ctx, opts.TenantKey, opts.ProjectKey)
+	if err != nil {
+		if tenant_model.IsTenantOrganizationsNotExists(err) {
+			sendFailure("Error has occurred while creating repository - options aren't valid")
+			return nil, nil, err
+		}
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("get tenant organization by tenant key %s and project key %s: %w", opts.TenantKey, opts.ProjectKey, err)
+	}
+
+	if _, err = s.repoKeyDB.GetRepoByKey(ctx, opts.RepositoryKey); err == nil {
+		sendFailure("Error has occurred while creating repository - repository name been taken")
+		return nil, nil, repo_model.ErrorRepoKeyAlreadyUsed{RepoKey: opts.RepositoryKey}
+	} else if !repo_model.IsErrorRepoKeyDoesntExists(err) {
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("get repository by key %s: %w", opts.RepositoryKey, err)
+	}
+
+	repoTenant, err := tenant_model.GetTenantByID(ctx, tenantOrg.TenantID)
+	if err != nil {
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("get tenant by id %s: %w", tenantOrg.TenantID, err)
+	}
+	if !repoTenant.IsActive {
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, tenant_model.ErrTenantNotActive{TenantKey: opts.TenantKey}
+	}
+
+	org, err := organization.GetOrgByID(ctx, tenantOrg.OrganizationID)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			sendFailure("Error has occurred while creating repository - options aren't valid")
+			return nil, nil, err
+		}
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("get project by id %d: %w", tenantOrg.OrganizationID, err)
+	}
+
+	allow, err := s.checkUserPermissionFn(ctx, doer, tenantOrg.TenantID, &organization.Organization{ID: tenantOrg.OrganizationID}, role_model.CREATE)
+	if err != nil {
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("check user permission to organization: %w", err)
+	}
+	if !allow {
+		sendFailure("Error has occurred while creating repository - options aren't valid")
+		return nil, nil, ErrNoProjectPermission{UserName: doer.Name, OrganizationID: tenantOrg.OrganizationID}
+	}
+
+	repo, err := repo_module.CreateRepository(doer, org.AsUser(), repo_module.CreateRepoOptions{
+		DefaultBranch: opts.DefaultBranch,
+		Description:   opts.Description,
+		Name:          opts.Name,
+		IsPrivate:     opts.IsPrivate,
+		Readme:        "Default",
+	})
+	if err != nil {
+		switch {
+		case repo_model.IsErrRepoAlreadyExist(err):
+			sendFailure("Error has occurred while creating repository - repository name been taken")
+		case tenant_model.IsErrTenantQuotaExceeded(err):
+			sendFailure("Error has occurred while creating repository - tenant repository quota exceeded")
+		case repo_model.IsErrCreateUserRepo(err):
+			sendFailure("Error has occurred while creating repository - creating a repository outside the project is prohibited")
+		default:
+			sendFailure("Error has occurred while creating repository")
+		}
+		return nil, nil, err
+	}
+
+	repoKey, err := s.repoKeyDB.GetRepoByRepoID(ctx, strconv.FormatInt(repo.ID, 10))
+	if err != nil {
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("get repository key by repository id %d: %w", repo.ID, err)
+	}
+	repoKey.RepoKey = opts.RepositoryKey
+	if err = s.repoKeyDB.UpdateRepoKey(ctx, repoKey); err != nil {
+		sendFailure("Error has occurred while creating repository")
+		return nil, nil, fmt.Errorf("update repository key %d: %w", repoKey.ID, err)
+	}
+
+	audit.CreateAndSendEvent(audit.RepositoryCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return repo, org, nil
+}
+
+// SetCodeHubMark установка метки CodeHub публичному репозиторию с внешним ключом repoKey публичного проекта tenant
+func (s TenantRepoService) SetCodeHubMark(ctx context.Context, doer *user_model.User, tenantKey, projectKey, repoKey string, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"repository_key": repoKey,
+		"project_key":    projectKey,
+		"tenant_key":     tenantKey,
+	}
+	sendFailure := func(message string) {
+		auditParams["error"] = message
+		audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+	}
+
+	tenantOrg, err := tenant_model.GetTenantOrganizationsByKeys(ctx, tenantKey, projectKey)
+	if err != nil {
+		sendFailure("Error has occurred while getting tenant by tenant_key and project_key")
+		if tenant_model.IsTenantOrganizationsNotExists(err) {
+			return err
+		}
+		return fmt.Errorf("get tenant organization by tenant key %s and project key %s: %w", tenantKey, projectKey, err)
+	}
+
+	repoTenant, err := tenant_model.GetTenantByID(ctx, tenantOrg.TenantID)
+	if err != nil {
+		sendFailure("Error has occurred while getting tenant by id")
+		return fmt.Errorf("get tenant by id %s: %w", tenantOrg.TenantID, err)
+	}
+	if !repoTenant.IsActive {
+		sendFailure("Error has occurred while checking if tenant is active")
+		return tenant_model.ErrTenantNotActive{TenantKey: tenantKey}
+	}
+
+	org, err := organization.GetOrgByID(ctx, tenantOrg.OrganizationID)
+	if err != nil {
+		sendFailure("Error has occurred while getting project by id")
+		if user_model.IsErrUserNotExist(err) {
+			return err
+		}
+		return fmt.Errorf("get project by id %d: %w", tenantOrg.OrganizationID, err)
+	}
+	if org.Visibility.IsPrivate() {
+		sendFailure("Error has occurred while checking if project is public")
+		return ErrProjectNotPublic{ProjectKey: projectKey}
+	}
+
+	scRepoKey, err := s.repoKeyDB.GetRepoByKey(ctx, repoKey)
+	if err != nil {
+		sendFailure("Error has occurred while getting repository by repo_key")
+		if repo_model.IsErrorRepoKeyDoesntExists(err) {
+			return err
+		}
+		return fmt.Errorf("get repository by key %s: %w", repoKey, err)
+	}
+	repoID, err := strconv.ParseInt(scRepoKey.RepoID, 10, 64)
+	if err != nil {
+		sendFailure("Error has occurred while parsing repository by id")
+		return fmt.Errorf("parse repository id %s: %w", scRepoKey.RepoID, err)
+	}
+
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		sendFailure("Error has occurred while getting repository by id")
+		if repo_model.IsErrRepoNotExist(err) {
+			return err
+		}
+		return fmt.Errorf("get repository by id %d: %w", repoID, err)
+	}
+	if repo.IsPrivate {
+		sendFailure("Error has occurred while set marks - repository is private")
+		return ErrRepoNotPublic{RepoKey: repoKey}
+	}
+
+	if err = s.repoMarks.InsertRepoMark(ctx, scRepoKey.RepoKey, doer.ID, s.codeHubMark); err != nil {
+		sendFailure("Error has occurred while inserting repository mark")
+		return err
+	}
+
+	audit.CreateAndSendEvent(audit.CodeHubMarkSetEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
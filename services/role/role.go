@@ -0,0 +1,93 @@
+package role
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/role_model"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+)
+
+// CreateRoleOptions параметры для создания роли
+type CreateRoleOptions struct {
+	Name         string
+	DisplayName  string
+	Actions      []string
+	IsAssignable bool
+}
+
+// UpdateRoleOptions параметры для изменения роли, пустые поля не изменяются
+type UpdateRoleOptions struct {
+	DisplayName  *string
+	Actions      []string
+	IsAssignable *bool
+}
+
+// CreateRole создание роли с набором действий
+func CreateRole(ctx context.Context, opts CreateRoleOptions, auditInfo auditutils.AuditRequiredParams) (*role_model.ScRole, error) {
+	role := &role_model.ScRole{
+		Name:         opts.Name,
+		DisplayName:  opts.DisplayName,
+		IsAssignable: opts.IsAssignable,
+	}
+	role.SetActions(opts.Actions)
+	auditParams := map[string]string{
+		"role":      role.Name,
+		"new_value": role.Actions,
+	}
+
+	if err := role_model.CreateRoleDefinition(ctx, role); err != nil {
+		auditParams["error"] = "Error has occurred while creating role"
+		audit.CreateAndSendEvent(audit.RoleCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return nil, fmt.Errorf("create role definition: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.RoleCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return role, nil
+}
+
+// UpdateRole изменение отображаемого имени, набора действий и доступности роли для назначения
+func UpdateRole(ctx context.Context, role *role_model.ScRole, opts UpdateRoleOptions, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"role":      role.Name,
+		"old_value": role.Actions,
+	}
+
+	if opts.DisplayName != nil {
+		role.DisplayName = *opts.DisplayName
+	}
+	if opts.Actions != nil {
+		role.SetActions(opts.Actions)
+	}
+	if opts.IsAssignable != nil {
+		role.IsAssignable = *opts.IsAssignable
+	}
+	auditParams["new_value"] = role.Actions
+
+	if err := role_model.UpdateRoleDefinition(ctx, role); err != nil {
+		auditParams["error"] = "Error has occurred while updating role"
+		audit.CreateAndSendEvent(audit.RoleUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("update role definition: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.RoleUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// DeleteRole удаление роли, не назначенной пользователям
+func DeleteRole(ctx context.Context, role *role_model.ScRole, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"role":      role.Name,
+		"old_value": role.Actions,
+	}
+
+	if err := role_model.DeleteRoleDefinition(ctx, role); err != nil {
+		auditParams["error"] = "Error has occurred while deleting role"
+		audit.CreateAndSendEvent(audit.RoleDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("delete role definition: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.RoleDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
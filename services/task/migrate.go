@@ -12,6 +12,7 @@ import (
 	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
+	tenant_model "code.gitea.io/gitea/models/tenant"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/json"
@@ -29,6 +30,8 @@ func handleCreateError(owner *user_model.User, err error) error {
 	switch {
 	case repo_model.IsErrReachLimitOfRepo(err):
 		return fmt.Errorf("you have already reached your limit of %d repositories", owner.MaxCreationLimit())
+	case tenant_model.IsErrTenantQuotaExceeded(err):
+		return errors.New("the tenant has already reached its repository quota")
 	case repo_model.IsErrRepoAlreadyExist(err):
 		return errors.New("the repository name is already used")
 	case repo_model.IsErrCreateUserRepo(err):
@@ -0,0 +1,99 @@
+package task_tracker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models/task_tracker_provider"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrUnknownProvider трекер задач не настроен
+type ErrUnknownProvider struct {
+	Name string
+}
+
+func (e ErrUnknownProvider) Error() string {
+	return fmt.Sprintf("task tracker provider %s is not configured", e.Name)
+}
+
+// IsErrUnknownProvider проверяет, что ошибка ErrUnknownProvider
+func IsErrUnknownProvider(err error) bool {
+	_, ok := err.(ErrUnknownProvider)
+	return ok
+}
+
+// ErrInvalidUnitCodePattern шаблон кода юнита не является регулярным выражением
+type ErrInvalidUnitCodePattern struct {
+	Pattern string
+	Err     error
+}
+
+func (e ErrInvalidUnitCodePattern) Error() string {
+	return fmt.Sprintf("unit code pattern %q is invalid: %v", e.Pattern, e.Err)
+}
+
+// IsErrInvalidUnitCodePattern проверяет, что ошибка ErrInvalidUnitCodePattern
+func IsErrInvalidUnitCodePattern(err error) bool {
+	_, ok := err.(ErrInvalidUnitCodePattern)
+	return ok
+}
+
+// ValidateBinding проверяет, что трекер задач настроен
+func ValidateBinding(binding *task_tracker_provider.Binding) error {
+	if _, ok := setting.TaskTracker.Providers[binding.Provider]; !ok {
+		return ErrUnknownProvider{Name: binding.Provider}
+	}
+	return nil
+}
+
+// SetBinding выбирает трекер задач для тенанта или репозитория
+func SetBinding(ctx context.Context, binding *task_tracker_provider.Binding, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := bindingAuditParams(binding.TenantID, binding.RepoID)
+	auditParams["new_value"] = binding.Provider
+
+	if err := ValidateBinding(binding); err != nil {
+		auditParams["error"] = "Task tracker binding is invalid"
+		audit.CreateAndSendEvent(audit.TaskTrackerBindingUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return err
+	}
+
+	if err := task_tracker_provider.SaveBinding(ctx, binding); err != nil {
+		auditParams["error"] = "Error has occurred while saving task tracker binding"
+		audit.CreateAndSendEvent(audit.TaskTrackerBindingUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("save task tracker binding: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.TaskTrackerBindingUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// DeleteBinding удаляет настройку трекера задач тенанта или репозитория, после чего используется
+// настройка тенанта или трекер задач по умолчанию
+func DeleteBinding(ctx context.Context, tenantID string, repoID int64, auditInfo auditutils.AuditRequiredParams) (bool, error) {
+	auditParams := bindingAuditParams(tenantID, repoID)
+
+	deleted, err := task_tracker_provider.DeleteBinding(ctx, tenantID, repoID)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while deleting task tracker binding"
+		audit.CreateAndSendEvent(audit.TaskTrackerBindingDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return false, err
+	}
+	if deleted {
+		audit.CreateAndSendEvent(audit.TaskTrackerBindingDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	}
+	return deleted, nil
+}
+
+func bindingAuditParams(tenantID string, repoID int64) map[string]string {
+	auditParams := map[string]string{
+		"tenant_id": tenantID,
+	}
+	if repoID > 0 {
+		auditParams["repository_id"] = strconv.FormatInt(repoID, 10)
+	}
+	return auditParams
+}
@@ -0,0 +1,116 @@
+package task_tracker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/task_tracker_provider"
+	"code.gitea.io/gitea/models/unit_code_pattern"
+	"code.gitea.io/gitea/modules/gitnamesparser"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// MaxUnitCodePatterns максимальное количество шаблонов кодов юнитов тенанта или репозитория
+const MaxUnitCodePatterns = 10
+
+// ErrTooManyUnitCodePatterns превышено количество шаблонов кодов юнитов
+type ErrTooManyUnitCodePatterns struct {
+	Count int
+}
+
+func (e ErrTooManyUnitCodePatterns) Error() string {
+	return fmt.Sprintf("too many unit code patterns: %d, maximum is %d", e.Count, MaxUnitCodePatterns)
+}
+
+// IsErrTooManyUnitCodePatterns проверяет, что ошибка ErrTooManyUnitCodePatterns
+func IsErrTooManyUnitCodePatterns(err error) bool {
+	_, ok := err.(ErrTooManyUnitCodePatterns)
+	return ok
+}
+
+// ResolveProvider возвращает имя трекера задач и шаблоны кодов юнитов репозитория. Трекер выбирается по настройке
+// репозитория, затем тенанта, а если их нет, используется трекер по умолчанию. Шаблоны берутся из таблицы
+// unit_code_pattern для репозитория или тенанта, а если их нет, из настроек выбранного трекера. Пустые шаблоны
+// означают, что используются шаблоны по умолчанию
+func ResolveProvider(ctx context.Context, tenantID string, repoID int64) (string, gitnamesparser.Patterns, error) {
+	providerName := setting.TaskTracker.DefaultProvider
+	binding, has, err := task_tracker_provider.GetEffectiveBinding(ctx, tenantID, repoID)
+	if err != nil {
+		return "", nil, err
+	}
+	if has {
+		providerName = binding.Provider
+	}
+	rawPatterns := []string{setting.TaskTracker.Providers[providerName].UnitCodePattern}
+
+	patternSetting, has, err := unit_code_pattern.GetEffectiveSetting(ctx, tenantID, repoID)
+	if err != nil {
+		return "", nil, err
+	}
+	if has && len(patternSetting.Patterns) > 0 {
+		rawPatterns = patternSetting.Patterns
+	}
+
+	patterns, err := gitnamesparser.CompilePatterns(rawPatterns)
+	if err != nil {
+		return "", nil, fmt.Errorf("unit code patterns of tenant %s repo %d: %w", tenantID, repoID, err)
+	}
+	return providerName, patterns, nil
+}
+
+// ValidateUnitCodePatterns проверяет количество шаблонов кодов юнитов и компилирует их
+func ValidateUnitCodePatterns(patterns []string) (gitnamesparser.Patterns, error) {
+	if len(patterns) > MaxUnitCodePatterns {
+		return nil, ErrTooManyUnitCodePatterns{Count: len(patterns)}
+	}
+	compiled := make(gitnamesparser.Patterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := gitnamesparser.CompilePatterns([]string{pattern})
+		if err != nil {
+			return nil, ErrInvalidUnitCodePattern{Pattern: pattern, Err: err}
+		}
+		compiled = append(compiled, re...)
+	}
+	return compiled, nil
+}
+
+// SetUnitCodePatterns сохраняет шаблоны кодов юнитов тенанта или репозитория
+func SetUnitCodePatterns(ctx context.Context, patternSetting *unit_code_pattern.Setting, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := bindingAuditParams(patternSetting.TenantID, patternSetting.RepoID)
+	auditParams["new_value"] = strings.Join(patternSetting.Patterns, ",")
+
+	if _, err := ValidateUnitCodePatterns(patternSetting.Patterns); err != nil {
+		auditParams["error"] = "Unit code patterns are invalid"
+		audit.CreateAndSendEvent(audit.UnitCodePatternsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return err
+	}
+
+	if err := unit_code_pattern.SaveSetting(ctx, patternSetting); err != nil {
+		auditParams["error"] = "Error has occurred while saving unit code patterns"
+		audit.CreateAndSendEvent(audit.UnitCodePatternsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("save unit code patterns: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.UnitCodePatternsUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// DeleteUnitCodePatterns удаляет шаблоны кодов юнитов тенанта или репозитория, после чего используются
+// шаблоны тенанта или трекера задач
+func DeleteUnitCodePatterns(ctx context.Context, tenantID string, repoID int64, auditInfo auditutils.AuditRequiredParams) (bool, error) {
+	auditParams := bindingAuditParams(tenantID, repoID)
+
+	deleted, err := unit_code_pattern.DeleteSetting(ctx, tenantID, repoID)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while deleting unit code patterns"
+		audit.CreateAndSendEvent(audit.UnitCodePatternsDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return false, err
+	}
+	if deleted {
+		audit.CreateAndSendEvent(audit.UnitCodePatternsDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	}
+	return deleted, nil
+}
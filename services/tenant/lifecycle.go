@@ -0,0 +1,316 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	tenant_model "code.gitea.io/gitea/models/tenant"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+	org_service "code.gitea.io/gitea/services/org"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// UpdateTenantOptions параметры для изменения tenant, пустые поля не изменяются
+type UpdateTenantOptions struct {
+	Name     *string
+	IsActive *bool
+}
+
+// CreateTenant создание активного tenant с именем name и ключом tenantKey.
+// Если tenant с таким именем или ключом уже существует, возвращается ErrTenantAlreadyExists
+func CreateTenant(ctx context.Context, name, tenantKey string, auditInfo auditutils.AuditRequiredParams) (*tenant_model.ScTenant, error) {
+	auditParams := map[string]string{
+		"tenant_key": name,
+	}
+
+	tenants, err := tenant_model.GetTenantsByNameOrOrgKey(ctx, name, tenantKey)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting tenants"
+		audit.CreateAndSendEvent(audit.TenantCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return nil, fmt.Errorf("get tenants by name or tenant key: %w", err)
+	}
+	if len(tenants) > 0 {
+		auditParams["error"] = "Error has occurred the tenant already exists"
+		audit.CreateAndSendEvent(audit.TenantCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return nil, tenant_model.ErrTenantAlreadyExists{Name: name, TenantKey: tenantKey}
+	}
+
+	tenant, err := tenant_model.InsertTenant(ctx, &tenant_model.ScTenant{
+		ID:       uuid.NewString(),
+		Default:  false,
+		IsActive: true,
+		Name:     name,
+		OrgKey:   tenantKey,
+	})
+	if err != nil {
+		auditParams["error"] = "Error has occurred while inserting tenant"
+		audit.CreateAndSendEvent(audit.TenantCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return nil, fmt.Errorf("insert tenant: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.TenantCreateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return tenant, nil
+}
+
+// EditTenant изменение имени и состояния tenant.
+// При смене состояния отправляется событие активации или деактивации tenant
+func EditTenant(ctx context.Context, tenant *tenant_model.ScTenant, opts UpdateTenantOptions, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"tenant_id":  tenant.ID,
+		"tenant_key": tenant.OrgKey,
+	}
+
+	if opts.Name != nil && *opts.Name != tenant.Name {
+		existing, has, err := tenant_model.GetTenantByNameWithFlag(ctx, *opts.Name)
+		if err != nil {
+			auditParams["error"] = "Error has occurred while getting tenant by name"
+			audit.CreateAndSendEvent(audit.TenantEditEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return fmt.Errorf("get tenant by name: %w", err)
+		}
+		if has && existing.ID != tenant.ID {
+			auditParams["error"] = "Error has occurred while editing tenant - name already used"
+			audit.CreateAndSendEvent(audit.TenantEditEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return tenant_model.ErrTenantNameAlreadyUsed{Name: *opts.Name}
+		}
+
+		auditParams["old_value"] = tenant.Name
+		auditParams["new_value"] = *opts.Name
+		tenant.Name = *opts.Name
+		if err = tenant_model.UpdateTenantCols(ctx, tenant, "name"); err != nil {
+			auditParams["error"] = "Error has occurred while updating tenant"
+			audit.CreateAndSendEvent(audit.TenantEditEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return fmt.Errorf("update tenant: %w", err)
+		}
+		audit.CreateAndSendEvent(audit.TenantEditEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	}
+
+	if opts.IsActive != nil && *opts.IsActive != tenant.IsActive {
+		return SetTenantActive(ctx, tenant, *opts.IsActive, auditInfo)
+	}
+	return nil
+}
+
+// SetTenantActive активация или деактивация tenant.
+// Деактивация распространяется на все проекты tenant: доступ к их репозиториям через git, API и web интерфейс блокируется
+func SetTenantActive(ctx context.Context, tenant *tenant_model.ScTenant, isActive bool, auditInfo auditutils.AuditRequiredParams) error {
+	event := audit.TenantActivateEvent
+	if !isActive {
+		event = audit.TenantDeactivateEvent
+	}
+	auditParams := map[string]string{
+		"tenant_id":  tenant.ID,
+		"tenant_key": tenant.OrgKey,
+		"old_value":  strconv.FormatBool(tenant.IsActive),
+		"new_value":  strconv.FormatBool(isActive),
+	}
+
+	if tenant.Default && !isActive {
+		auditParams["error"] = "Error has occurred while deactivating default tenant"
+		audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return tenant_model.ErrTenantIsDefault{TenantID: tenant.ID}
+	}
+	if tenant.IsDeleting && isActive {
+		auditParams["error"] = "Error has occurred while activating tenant that is being deleted"
+		audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return tenant_model.ErrTenantIsDeleting{TenantID: tenant.ID}
+	}
+
+	tenant.IsActive = isActive
+	if isActive {
+		tenant.IsArchived = false
+	}
+	if err := tenant_model.UpdateTenantCols(ctx, tenant, "is_active", "is_archived"); err != nil {
+		auditParams["error"] = "Error has occurred while updating tenant"
+		audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("update tenant: %w", err)
+	}
+
+	audit.CreateAndSendEvent(event, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// ArchiveTenant деактивирует tenant и переводит все его репозитории в архив
+func ArchiveTenant(ctx context.Context, tenant *tenant_model.ScTenant, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"tenant_id":  tenant.ID,
+		"tenant_key": tenant.OrgKey,
+	}
+
+	if tenant.Default {
+		auditParams["error"] = "Error has occurred while archiving default tenant"
+		audit.CreateAndSendEvent(audit.TenantArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return tenant_model.ErrTenantIsDefault{TenantID: tenant.ID}
+	}
+
+	if tenant.IsActive {
+		if err := SetTenantActive(ctx, tenant, false, auditInfo); err != nil {
+			auditParams["error"] = "Error has occurred while deactivating tenant"
+			audit.CreateAndSendEvent(audit.TenantArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return err
+		}
+	}
+
+	repos, err := getTenantRepositories(ctx, tenant.ID)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting tenant repositories"
+		audit.CreateAndSendEvent(audit.TenantArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return err
+	}
+
+	for _, repo := range repos {
+		if repo.IsArchived {
+			continue
+		}
+		repoAuditParams := map[string]string{
+			"tenant_id":     tenant.ID,
+			"repository":    repo.Name,
+			"repository_id": strconv.FormatInt(repo.ID, 10),
+			"owner":         repo.OwnerName,
+		}
+		if err = repo_model.SetArchiveRepoState(ctx, repo, true); err != nil {
+			log.Error("Error has occurred while archiving repository %d of tenant %s: %v", repo.ID, tenant.ID, err)
+			repoAuditParams["error"] = "Error has occurred while archiving repository"
+			audit.CreateAndSendEvent(audit.TenantRepositoryArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, repoAuditParams)
+			auditParams["error"] = "Error has occurred while archiving tenant repositories"
+			audit.CreateAndSendEvent(audit.TenantArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return fmt.Errorf("archive repository %d: %w", repo.ID, err)
+		}
+		audit.CreateAndSendEvent(audit.TenantRepositoryArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, repoAuditParams)
+	}
+
+	tenant.IsArchived = true
+	if err = tenant_model.UpdateTenantCols(ctx, tenant, "is_archived"); err != nil {
+		auditParams["error"] = "Error has occurred while updating tenant"
+		audit.CreateAndSendEvent(audit.TenantArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("update tenant: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.TenantArchiveEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// DeleteTenantWithResources безвозвратно удаляет tenant вместе с репозиториями, проектами,
+// связями sc_tenant_organizations и правилами casbin.
+// Перед удалением ресурсов tenant деактивируется и помечается признаком IsDeleting, все шаги идемпотентны:
+// при повторном вызове после сбоя удаление продолжается с оставшихся репозиториев и проектов
+func DeleteTenantWithResources(ctx context.Context, doer *user_model.User, tenant *tenant_model.ScTenant, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"tenant_id":  tenant.ID,
+		"tenant_key": tenant.OrgKey,
+	}
+
+	if tenant.Default {
+		auditParams["error"] = "Error has occurred while deleting default tenant"
+		audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return tenant_model.ErrTenantIsDefault{TenantID: tenant.ID}
+	}
+
+	if !tenant.IsDeleting {
+		tenant.IsActive = false
+		tenant.IsDeleting = true
+		if err := tenant_model.UpdateTenantCols(ctx, tenant, "is_active", "is_deleting"); err != nil {
+			auditParams["error"] = "Error has occurred while marking tenant as deleting"
+			audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return fmt.Errorf("update tenant: %w", err)
+		}
+	}
+
+	orgIDs, err := getTenantOrganizationIDs(ctx, tenant.ID)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting tenant organizations"
+		audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return err
+	}
+
+	// после прерванного удаления часть репозиториев и проектов уже удалена, поэтому они запрашиваются заново
+	repos, err := repo_model.GetRepositoriesByOwnerIDs(ctx, orgIDs)
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting tenant repositories"
+		audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("get tenant repositories: %w", err)
+	}
+	for _, repo := range repos {
+		repoAuditParams := map[string]string{
+			"tenant_id":     tenant.ID,
+			"repository":    repo.Name,
+			"repository_id": strconv.FormatInt(repo.ID, 10),
+			"owner":         repo.OwnerName,
+		}
+		if err = repo_service.DeleteRepository(ctx, doer, repo, true); err != nil {
+			repoAuditParams["error"] = "Error has occurred while deleting repository"
+			audit.CreateAndSendEvent(audit.RepositoryDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, repoAuditParams)
+			auditParams["error"] = "Error has occurred while deleting tenant repositories"
+			audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return fmt.Errorf("delete repository %d: %w", repo.ID, err)
+		}
+		audit.CreateAndSendEvent(audit.RepositoryDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, repoAuditParams)
+	}
+
+	organizations := make([]*organization.Organization, 0, len(orgIDs))
+	if len(orgIDs) > 0 {
+		organizations, err = organization.GetOrganizationByIDs(ctx, orgIDs)
+	}
+	if err != nil {
+		auditParams["error"] = "Error has occurred while getting tenant organizations"
+		audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("get organizations: %w", err)
+	}
+	for _, org := range organizations {
+		orgAuditParams := map[string]string{
+			"tenant_id":  tenant.ID,
+			"project":    org.Name,
+			"project_id": strconv.FormatInt(org.ID, 10),
+		}
+		if err = org_service.DeleteOrganization(org); err != nil {
+			orgAuditParams["error"] = "Error has occurred while deleting project"
+			audit.CreateAndSendEvent(audit.ProjectDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, orgAuditParams)
+			auditParams["error"] = "Error has occurred while deleting tenant organizations"
+			audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+			return fmt.Errorf("delete organization %d: %w", org.ID, err)
+		}
+		audit.CreateAndSendEvent(audit.ProjectDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, orgAuditParams)
+	}
+
+	if err = RemoveTenantByID(ctx, tenant.ID, orgIDs); err != nil {
+		auditParams["error"] = "Error has occurred while deleting tenant"
+		audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("remove tenant: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.TenantDeleteEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// getTenantOrganizationIDs получение идентификаторов всех проектов tenant
+func getTenantOrganizationIDs(ctx context.Context, tenantID string) ([]int64, error) {
+	tenantOrganizations, err := tenant_model.GetTenantOrganizations(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant organizations: %w", err)
+	}
+	orgIDs := make([]int64, len(tenantOrganizations))
+	for idx, tenantOrganization := range tenantOrganizations {
+		orgIDs[idx] = tenantOrganization.OrganizationID
+	}
+	return orgIDs, nil
+}
+
+// getTenantRepositories получение всех репозиториев проектов tenant
+func getTenantRepositories(ctx context.Context, tenantID string) (repo_model.RepositoryList, error) {
+	orgIDs, err := getTenantOrganizationIDs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := repo_model.GetRepositoriesByOwnerIDs(ctx, orgIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get repositories: %w", err)
+	}
+	return repos, nil
+}
@@ -0,0 +1,61 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tenant_model "code.gitea.io/gitea/models/tenant"
+	"code.gitea.io/gitea/modules/sbt/audit"
+	auditutils "code.gitea.io/gitea/modules/sbt/audit/utils"
+)
+
+// UpdateTenantQuotasOptions параметры для изменения квот tenant, пустые поля не изменяются
+type UpdateTenantQuotasOptions struct {
+	MaxRepoCount   *int64
+	MaxGitSize     *int64
+	MaxLFSSize     *int64
+	MaxPackageSize *int64
+}
+
+// SetTenantQuotas изменение квот tenant. Значение 0 снимает ограничение на ресурс
+func SetTenantQuotas(ctx context.Context, tenant *tenant_model.ScTenant, opts UpdateTenantQuotasOptions, auditInfo auditutils.AuditRequiredParams) error {
+	auditParams := map[string]string{
+		"tenant_id":  tenant.ID,
+		"tenant_key": tenant.OrgKey,
+		"old_value":  formatTenantQuotas(tenant),
+	}
+
+	if opts.MaxRepoCount != nil {
+		tenant.MaxRepoCount = *opts.MaxRepoCount
+	}
+	if opts.MaxGitSize != nil {
+		tenant.MaxGitSize = *opts.MaxGitSize
+	}
+	if opts.MaxLFSSize != nil {
+		tenant.MaxLFSSize = *opts.MaxLFSSize
+	}
+	if opts.MaxPackageSize != nil {
+		tenant.MaxPackageSize = *opts.MaxPackageSize
+	}
+	auditParams["new_value"] = formatTenantQuotas(tenant)
+
+	if err := tenant_model.UpdateTenantQuotas(ctx, tenant); err != nil {
+		auditParams["error"] = "Error has occurred while updating tenant quotas"
+		audit.CreateAndSendEvent(audit.TenantQuotaUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusFailure, auditInfo.RemoteAddress, auditParams)
+		return fmt.Errorf("update tenant quotas: %w", err)
+	}
+
+	audit.CreateAndSendEvent(audit.TenantQuotaUpdateEvent, auditInfo.DoerName, auditInfo.DoerID, audit.StatusSuccess, auditInfo.RemoteAddress, auditParams)
+	return nil
+}
+
+// formatTenantQuotas строковое представление квот tenant для аудита
+func formatTenantQuotas(tenant *tenant_model.ScTenant) string {
+	return fmt.Sprintf("%s=%s,%s=%s,%s=%s,%s=%s",
+		tenant_model.QuotaRepoCount, strconv.FormatInt(tenant.MaxRepoCount, 10),
+		tenant_model.QuotaGitSize, strconv.FormatInt(tenant.MaxGitSize, 10),
+		tenant_model.QuotaLFSSize, strconv.FormatInt(tenant.MaxLFSSize, 10),
+		tenant_model.QuotaPackageSize, strconv.FormatInt(tenant.MaxPackageSize, 10),
+	)
+}
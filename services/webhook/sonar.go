@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,8 +11,8 @@ import (
 
 	"github.com/google/uuid"
 
-	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/sonar/domain"
 	"code.gitea.io/gitea/models/sonar/repo"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -25,52 +26,116 @@ const (
 	urlApiListPullRequest = "/api/project_pull_requests/list"
 	// для получения metrics из sonarqube
 	urlApiMetricsSearch = "/api/metrics/search"
+	// для получения measures ветки или pull request из sonarqube
+	urlApiMeasuresComponent = "/api/measures/component"
 )
 
 // AddSonarProjectStatus добавление или обновление информации о проекте из сонара
-func AddSonarProjectStatus(webhookInfo *webhook_sonar.WebHook) (*repo.ScSonarProjectStatus, error) {
-	timeAnalyzedAt, err := time.Parse("2006-01-02T15:04:05+0300", webhookInfo.AnalysedAt)
+func AddSonarProjectStatus(ctx context.Context, webhookInfo *webhook_sonar.WebHook) (*repo.ScSonarProjectStatus, error) {
+	analysedAt, err := time.Parse(sonarAnalysedAtLayout, webhookInfo.AnalysedAt)
 	if err != nil {
 		return nil, fmt.Errorf("time parse: %w", err)
 	}
-	var analyzedAt timeutil.TimeStamp
 	sonarProjectStatusEntity := &repo.ScSonarProjectStatus{
 		ID:          uuid.NewString(),
 		SonarServer: webhookInfo.ServerUrl,
 		ProjectKey:  webhookInfo.Project.Key,
 		Branch:      webhookInfo.Branch.Name,
-		AnalysedAt:  analyzedAt.Add(int64(timeAnalyzedAt.Second())),
+		AnalysedAt:  timeutil.TimeStamp(analysedAt.Unix()),
 		Status:      webhookInfo.QualityGate.Status,
 	}
-	sonarProjectStatus, err := repo.UpsertSonarProjectStatus(db.DefaultContext, sonarProjectStatusEntity)
+	sonarProjectStatus, err := repo.UpsertSonarProjectStatus(ctx, sonarProjectStatusEntity)
 	if err != nil {
 		return nil, fmt.Errorf("upsert sonar project status: %w", err)
 	}
 	return sonarProjectStatus, nil
 }
 
-// ControllerWebHook обработчик информации из webhook
-func ControllerWebHook(conditions []webhook_sonar.SonarConditions, sonarSettings *repo_model.ScSonarSettings, sonarProject *repo.ScSonarProjectStatus) error {
+// ControllerWebHook обработчик информации из webhook. Если передан анализ из webhook, его результат публикуется
+// в статус коммита и комментарий пулл реквеста
+func ControllerWebHook(ctx context.Context, conditions []webhook_sonar.SonarConditions, sonarSettings *repo_model.ScSonarSettings, sonarProject *repo.ScSonarProjectStatus, analysis *webhook_sonar.WebHook) error {
+	if analysis != nil {
+		// ошибка публикации не должна мешать сохранению метрик
+		if err := DecorateSonarAnalysis(ctx, sonarSettings, analysis); err != nil {
+			log.Error("Error has occurred while decorating sonar analysis of project %s. Error: %v", analysis.Project.Key, err)
+		}
+	}
+
 	mapUniqueCondition := make(map[string]webhook_sonar.SonarConditions)
 	for _, cond := range conditions {
 		mapUniqueCondition[cond.Metric] = cond
 	}
-	_, err := GetAllMetrics(mapUniqueCondition, sonarSettings, sonarProject)
+	snapshot, err := sonarMetricsSnapshot(ctx, sonarProject, analysis)
+	if err != nil {
+		return err
+	}
+	// значения метрик истории по ветке анализа, ошибка получения не должна мешать сохранению метрик quality gate
+	var branchMeasures []webhook_sonar.SonarMeasures
+	if analysis != nil {
+		branchMeasures, err = SonarBranchMeasuresGet(sonarSettings, analysis.Branch, sonarTrendMetricKeys())
+		if err != nil {
+			log.Error("Error has occurred while getting sonar measures of branch %s of project %s. Error: %v", analysis.Branch.Name, sonarSettings.ProjectKey, err)
+		}
+	}
+	_, err = GetAllMetrics(ctx, mapUniqueCondition, sonarSettings, sonarProject, snapshot, branchMeasures)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetAllMetrics получаем все информацию о метриках из webhook и добавляем информацию о них в бд
-func GetAllMetrics(mapUniqueCondition map[string]webhook_sonar.SonarConditions, sonarSettingsForRepository *repo_model.ScSonarSettings, sonarProject *repo.ScSonarProjectStatus) ([]webhook_sonar.SonarMetrics, error) {
+// sonarTrendMetricKeys ключи метрик Sonar, по которым строится история анализов ветки
+func sonarTrendMetricKeys() []string {
+	keys := make([]string, 0, 8)
+	keys = append(keys, domain.SonarTrendMetrics.Coverage...)
+	keys = append(keys, domain.SonarTrendMetrics.Bugs...)
+	keys = append(keys, domain.SonarTrendMetrics.Vulnerabilities...)
+	keys = append(keys, domain.SonarTrendMetrics.Duplications...)
+	return keys
+}
+
+// sonarMetricsSnapshot снимок, в который сохраняются метрики. Каждый анализ из webhook сохраняется в новый снимок,
+// без анализа обновляются метрики последнего снимка ветки
+func sonarMetricsSnapshot(ctx context.Context, sonarProject *repo.ScSonarProjectStatus, analysis *webhook_sonar.WebHook) (*repo.ScSonarMetricsSnapshot, error) {
+	if analysis == nil {
+		latest, err := repo.GetLatestSonarMetricsSnapshot(ctx, sonarProject.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get latest sonar metrics snapshot: %w", err)
+		}
+		if latest != nil {
+			return latest, nil
+		}
+	}
+
+	snapshot := &repo.ScSonarMetricsSnapshot{
+		SonarProjectStatusID: sonarProject.ID,
+		Branch:               sonarProject.Branch,
+		QualityGateStatus:    sonarProject.Status,
+		AnalysedAt:           timeutil.TimeStampNow(),
+	}
+	if analysis != nil {
+		snapshot.Revision = analysis.Revision
+		snapshot.QualityGateStatus = analysis.QualityGate.Status
+		if analysedAt, err := time.Parse(sonarAnalysedAtLayout, analysis.AnalysedAt); err == nil {
+			snapshot.AnalysedAt = timeutil.TimeStamp(analysedAt.Unix())
+		}
+	}
+	return snapshot, nil
+}
+
+// GetAllMetrics получаем все информацию о метриках из webhook и сохраняем их в снимок анализа ветки
+func GetAllMetrics(ctx context.Context, mapUniqueCondition map[string]webhook_sonar.SonarConditions, sonarSettingsForRepository *repo_model.ScSonarSettings, sonarProject *repo.ScSonarProjectStatus, snapshot *repo.ScSonarMetricsSnapshot, branchMeasures []webhook_sonar.SonarMeasures) ([]webhook_sonar.SonarMetrics, error) {
 	// получаем все возможные метрики
+	token, err := sonarSettingsForRepository.GetToken()
+	if err != nil {
+		return nil, err
+	}
 	reqUrl := sonarSettingsForRepository.URL + urlApiMetricsSearch
 	req, err := http.NewRequest("GET", reqUrl, nil)
-	req.SetBasicAuth(sonarSettingsForRepository.Token, "")
 	if err != nil {
 		return nil, err
 	}
+	req.SetBasicAuth(token, "")
 	response, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -134,8 +199,40 @@ func GetAllMetrics(mapUniqueCondition map[string]webhook_sonar.SonarConditions,
 			upsertSonarSetting = append(upsertSonarSetting, entitySonarMeasure)
 		}
 	}
-	// добавляем или обновляем информацию о метриках
-	err = repo.UpsertSonarMetrics(db.DefaultContext, upsertSonarSetting)
+	// дополняем метриками ветки для истории анализов, если их нет среди уже полученных
+	savedMetrics := make(map[string]struct{}, len(upsertSonarSetting))
+	for _, metric := range upsertSonarSetting {
+		savedMetrics[metric.Key] = struct{}{}
+	}
+	uniqueBranchMeasure := make(map[string]webhook_sonar.SonarMeasures, len(branchMeasures))
+	for _, branchMeasure := range branchMeasures {
+		uniqueBranchMeasure[branchMeasure.Metric] = branchMeasure
+	}
+	for _, sonarMeasure := range res.Metrics {
+		val, ok := uniqueBranchMeasure[sonarMeasure.Key]
+		if !ok {
+			continue
+		}
+		if _, saved := savedMetrics[sonarMeasure.Key]; saved {
+			continue
+		}
+		valueMeasure := val.Value
+		if valueMeasure == "" {
+			valueMeasure = val.Period.Value
+		}
+		upsertSonarSetting = append(upsertSonarSetting, repo.ScSonarProjectMetrics{
+			ID:                   uuid.NewString(),
+			SonarProjectStatusID: sonarProject.ID,
+			Key:                  sonarMeasure.Key,
+			Name:                 sonarMeasure.Name,
+			Type:                 sonarMeasure.Type,
+			Domain:               sonarMeasure.Domain,
+			Value:                valueMeasure,
+			IsQualityGate:        false,
+		})
+	}
+	// сохраняем метрики в снимок анализа, предыдущие анализы ветки остаются в истории
+	err = repo.SaveSonarMetricsSnapshot(ctx, snapshot, upsertSonarSetting)
 	if err != nil {
 		return nil, err
 	}
@@ -165,17 +262,22 @@ func SonarMeasuresGet(sonarMetrics []webhook_sonar.SonarMetrics, sonarSettingsFo
 	for metricKey := range metricKeysUnique {
 		metricKeys = append(metricKeys, metricKey)
 	}
+	token, err := sonarSettingsForRepository.GetToken()
+	if err != nil {
+		log.Error("SonarMeasuresGet failed while getting sonar token: %v", err)
+		return nil, err
+	}
 	reqUrl := sonarSettingsForRepository.URL + urlApiMeasureSearch
 	req, err := http.NewRequest("GET", reqUrl, nil)
-	queryReqValue := req.URL.Query()
-	queryReqValue.Add("metricKeys", strings.Join(metricKeys, ","))
-	queryReqValue.Add("projectKeys", projectKey)
-	req.URL.RawQuery = queryReqValue.Encode()
-	req.SetBasicAuth(sonarSettingsForRepository.Token, "")
 	if err != nil {
 		log.Error("SonarMeasuresGet failed while create request: %v", err)
 		return nil, err
 	}
+	queryReqValue := req.URL.Query()
+	queryReqValue.Add("metricKeys", strings.Join(metricKeys, ","))
+	queryReqValue.Add("projectKeys", projectKey)
+	req.URL.RawQuery = queryReqValue.Encode()
+	req.SetBasicAuth(token, "")
 	response, err := http.DefaultClient.Do(req)
 	if err != nil {
 		log.Error("SonarMeasuresGet failed while send request ulr:%s : %v", reqUrl, err)
@@ -196,6 +298,45 @@ func SonarMeasuresGet(sonarMetrics []webhook_sonar.SonarMetrics, sonarSettingsFo
 	return &listSonarMeasures, nil
 }
 
+// SonarBranchMeasuresGet получение значений метрик metricKeys по ветке или pull request анализа
+func SonarBranchMeasuresGet(sonarSettingsForRepository *repo_model.ScSonarSettings, branch webhook_sonar.Branch, metricKeys []string) ([]webhook_sonar.SonarMeasures, error) {
+	token, err := sonarSettingsForRepository.GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("get sonar token: %w", err)
+	}
+	reqUrl := sonarSettingsForRepository.URL + urlApiMeasuresComponent
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	queryReqValue := req.URL.Query()
+	queryReqValue.Add("component", sonarSettingsForRepository.ProjectKey)
+	queryReqValue.Add("metricKeys", strings.Join(metricKeys, ","))
+	// основная ветка запрашивается без параметра, так как ветки недоступны в бесплатной версии sonarQube
+	switch {
+	case branch.Type == sonarPullRequestBranchType:
+		queryReqValue.Add("pullRequest", branch.Name)
+	case !branch.IsMain && branch.Name != "":
+		queryReqValue.Add("branch", branch.Name)
+	}
+	req.URL.RawQuery = queryReqValue.Encode()
+	req.SetBasicAuth(token, "")
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request %s: %w", reqUrl, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %d from %s", response.StatusCode, reqUrl)
+	}
+
+	var componentMeasures webhook_sonar.ComponentMeasures
+	if err = json.NewDecoder(response.Body).Decode(&componentMeasures); err != nil {
+		return nil, fmt.Errorf("decode response body: %w", err)
+	}
+	return componentMeasures.Component.Measures, nil
+}
+
 // GetQualityGatesForProjectByPullRequest получаем информацию о quality gates, которые пришли из webhook
 func GetQualityGatesForProjectByPullRequest(repositoryID int64, baseBranch, branch, pullRequestID string) (*webhook_sonar.ResponseForPagePullRequest, error) {
 	// получаем натсройки для sonarQube по repository_id
@@ -208,16 +349,21 @@ func GetQualityGatesForProjectByPullRequest(repositoryID int64, baseBranch, bran
 		log.Error("GetQualityGatesForProjectByPullRequest sonarSettings is nil for repositoryID: %v", repositoryID)
 		return &webhook_sonar.ResponseForPagePullRequest{}, fmt.Errorf("GetQualityGatesForProjectByPullRequest sonarSettings is empty")
 	}
+	token, err := sonarSettings.GetToken()
+	if err != nil {
+		log.Error("GetQualityGatesForProjectByPullRequest failed while getting sonar token: %v", err)
+		return nil, err
+	}
 	reqUrl := sonarSettings.URL + urlApiListPullRequest
 	req, err := http.NewRequest("GET", reqUrl, nil)
-	queryReqValue := req.URL.Query()
-	queryReqValue.Add("project", sonarSettings.ProjectKey)
-	req.URL.RawQuery = queryReqValue.Encode()
-	req.SetBasicAuth(sonarSettings.Token, "")
 	if err != nil {
 		log.Error("GetQualityGatesForProjectByPullRequest http.NewRequest failed while create request: %v", err)
 		return nil, err
 	}
+	queryReqValue := req.URL.Query()
+	queryReqValue.Add("project", sonarSettings.ProjectKey)
+	req.URL.RawQuery = queryReqValue.Encode()
+	req.SetBasicAuth(token, "")
 
 	response, err := http.DefaultClient.Do(req)
 	if err != nil {
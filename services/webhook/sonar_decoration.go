@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/webhook_sonar"
+	issue_service "code.gitea.io/gitea/services/issue"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+const (
+	// sonarStatusContextPrefix префикс контекста статуса коммита с результатом quality gate
+	sonarStatusContextPrefix = "sonarqube/"
+	// sonarPullRequestBranchType тип ветки анализа пулл реквеста в webhook
+	sonarPullRequestBranchType = "PULL_REQUEST"
+)
+
+// SonarStatusContext возвращает контекст статуса коммита с результатом quality gate проекта Sonar
+func SonarStatusContext(projectKey string) string {
+	return sonarStatusContextPrefix + projectKey
+}
+
+// DecorateSonarAnalysis публикует результат quality gate анализа: статус коммита sonarqube/<project> на проанализированной
+// ревизии и, для анализа пулл реквеста, один сводный комментарий с невыполненными условиями, который обновляется
+// при следующих анализах
+func DecorateSonarAnalysis(ctx context.Context, sonarSettings *repo_model.ScSonarSettings, analysis *webhook_sonar.WebHook) error {
+	if analysis.QualityGate.Status == "" {
+		return nil
+	}
+
+	repo, err := repo_model.GetRepositoryByID(ctx, sonarSettings.RepoID)
+	if err != nil {
+		return fmt.Errorf("get repository %d: %w", sonarSettings.RepoID, err)
+	}
+	doer := user_model.NewActionsUser()
+
+	if analysis.Revision != "" {
+		if err = files_service.CreateCommitStatus(ctx, repo, doer, analysis.Revision, &git_model.CommitStatus{
+			State:       sonarCommitStatusState(analysis.QualityGate.Status),
+			TargetURL:   sonarAnalysisURL(analysis),
+			Description: sonarStatusDescription(analysis),
+			Context:     SonarStatusContext(analysis.Project.Key),
+		}); err != nil {
+			return fmt.Errorf("create commit status: %w", err)
+		}
+	}
+
+	if analysis.Branch.Type != sonarPullRequestBranchType {
+		return nil
+	}
+	index, err := strconv.ParseInt(analysis.Branch.Name, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse pull request key %q: %w", analysis.Branch.Name, err)
+	}
+	pr, err := issues_model.GetPullRequestByIndex(ctx, repo.ID, index)
+	if err != nil {
+		return fmt.Errorf("get pull request %d: %w", index, err)
+	}
+	return upsertSonarSummaryComment(ctx, doer, repo, pr.Issue, analysis)
+}
+
+// upsertSonarSummaryComment создает сводный комментарий анализа или обновляет комментарий предыдущего анализа проекта
+func upsertSonarSummaryComment(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, issue *issues_model.Issue, analysis *webhook_sonar.WebHook) error {
+	content := SonarSummaryComment(analysis)
+	marker := sonarSummaryMarker(analysis.Project.Key)
+
+	comments, err := issues_model.FindComments(ctx, &issues_model.FindCommentsOptions{
+		IssueID: issue.ID,
+		Type:    issues_model.CommentTypeComment,
+	})
+	if err != nil {
+		return fmt.Errorf("find comments: %w", err)
+	}
+	for _, comment := range comments {
+		if comment.PosterID != doer.ID || !strings.HasPrefix(comment.Content, marker) {
+			continue
+		}
+		if comment.Content == content {
+			return nil
+		}
+		oldContent := comment.Content
+		comment.Content = content
+		if err = issue_service.UpdateComment(ctx, comment, doer, oldContent); err != nil {
+			return fmt.Errorf("update comment %d: %w", comment.ID, err)
+		}
+		return nil
+	}
+
+	if _, err = issue_service.CreateIssueComment(ctx, doer, repo, issue, content, nil); err != nil {
+		return fmt.Errorf("create comment: %w", err)
+	}
+	return nil
+}
+
+// SonarSummaryComment формирует сводный комментарий анализа пулл реквеста
+func SonarSummaryComment(analysis *webhook_sonar.WebHook) string {
+	var sb strings.Builder
+	sb.WriteString(sonarSummaryMarker(analysis.Project.Key))
+	sb.WriteString("\n")
+
+	projectName := analysis.Project.Name
+	if projectName == "" {
+		projectName = analysis.Project.Key
+	}
+	if analysis.QualityGate.Status == "OK" {
+		fmt.Fprintf(&sb, "### SonarQube quality gate passed: %s\n\n", projectName)
+	} else {
+		fmt.Fprintf(&sb, "### SonarQube quality gate failed: %s\n\n", projectName)
+	}
+	if analysis.Revision != "" {
+		fmt.Fprintf(&sb, "Analysed commit `%s`", analysis.Revision)
+		if analysis.AnalysedAt != "" {
+			fmt.Fprintf(&sb, " at %s", analysis.AnalysedAt)
+		}
+		sb.WriteString(".\n\n")
+	}
+
+	failed := sonarFailedConditions(analysis)
+	if len(failed) == 0 {
+		sb.WriteString("All quality gate conditions are met.\n")
+	} else {
+		sb.WriteString("| Metric | Value | Fails when |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, condition := range failed {
+			fmt.Fprintf(&sb, "| [%s](%s) | %s | %s %s |\n",
+				condition.Metric, sonarMetricURL(analysis, condition.Metric), condition.Value,
+				sonarOperatorSign(condition.Operator), condition.ErrorThreshold)
+		}
+	}
+
+	if link := sonarAnalysisURL(analysis); link != "" {
+		fmt.Fprintf(&sb, "\n[Open analysis in SonarQube](%s)\n", link)
+	}
+	return sb.String()
+}
+
+func sonarSummaryMarker(projectKey string) string {
+	return fmt.Sprintf("<!-- sonarqube:%s -->", projectKey)
+}
+
+func sonarFailedConditions(analysis *webhook_sonar.WebHook) []webhook_sonar.SonarConditions {
+	failed := make([]webhook_sonar.SonarConditions, 0, len(analysis.QualityGate.Conditions))
+	for _, condition := range analysis.QualityGate.Conditions {
+		if condition.Status == "ERROR" {
+			failed = append(failed, condition)
+		}
+	}
+	return failed
+}
+
+func sonarCommitStatusState(status string) api.CommitStatusState {
+	if status == "OK" {
+		return api.CommitStatusSuccess
+	}
+	return api.CommitStatusFailure
+}
+
+func sonarStatusDescription(analysis *webhook_sonar.WebHook) string {
+	if analysis.QualityGate.Status == "OK" {
+		return "Quality gate passed"
+	}
+	failed := len(sonarFailedConditions(analysis))
+	if failed == 0 {
+		return "Quality gate failed"
+	}
+	return fmt.Sprintf("Quality gate failed: %d conditions not met", failed)
+}
+
+// sonarAnalysisURL ссылка на анализ в Sonar: ссылка из webhook или дашборд проекта по ветке или пулл реквесту
+func sonarAnalysisURL(analysis *webhook_sonar.WebHook) string {
+	if analysis.Branch.Url != "" {
+		return analysis.Branch.Url
+	}
+	if analysis.ServerUrl == "" {
+		return ""
+	}
+	query := url.Values{"id": {analysis.Project.Key}}
+	if analysis.Branch.Type == sonarPullRequestBranchType {
+		query.Set("pullRequest", analysis.Branch.Name)
+	} else if analysis.Branch.Name != "" && !analysis.Branch.IsMain {
+		query.Set("branch", analysis.Branch.Name)
+	}
+	return strings.TrimSuffix(analysis.ServerUrl, "/") + "/dashboard?" + query.Encode()
+}
+
+// sonarMetricURL ссылка на значение метрики анализа в Sonar
+func sonarMetricURL(analysis *webhook_sonar.WebHook, metric string) string {
+	query := url.Values{"id": {analysis.Project.Key}, "metric": {metric}}
+	if analysis.Branch.Type == sonarPullRequestBranchType {
+		query.Set("pullRequest", analysis.Branch.Name)
+	} else if analysis.Branch.Name != "" && !analysis.Branch.IsMain {
+		query.Set("branch", analysis.Branch.Name)
+	}
+	return strings.TrimSuffix(analysis.ServerUrl, "/") + "/component_measures?" + query.Encode()
+}
+
+func sonarOperatorSign(operator string) string {
+	switch operator {
+	case "GREATER_THAN":
+		return ">"
+	case "LESS_THAN":
+		return "<"
+	default:
+		return operator
+	}
+}
@@ -0,0 +1,63 @@
+//go:build !correct
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/webhook_sonar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSonarSummaryComment(t *testing.T) {
+	analysis := &webhook_sonar.WebHook{
+		ServerUrl:  "https://sonar.example.com",
+		Revision:   "c5f2a1b",
+		AnalysedAt: "2026-10-17T10:00:00+0300",
+		Branch:     webhook_sonar.Branch{Name: "42", Type: "PULL_REQUEST"},
+		Project:    webhook_sonar.Project{Key: "app", Name: "Application"},
+		QualityGate: webhook_sonar.SonarQualityGate{
+			Status: "ERROR",
+			Conditions: []webhook_sonar.SonarConditions{
+				{Metric: "new_coverage", Operator: "LESS_THAN", ErrorThreshold: "80", Value: "61.5", Status: "ERROR"},
+				{Metric: "new_bugs", Operator: "GREATER_THAN", ErrorThreshold: "0", Value: "0", Status: "OK"},
+			},
+		},
+	}
+
+	t.Run("failed quality gate", func(t *testing.T) {
+		assert.Equal(t, "<!-- sonarqube:app -->\n"+
+			"### SonarQube quality gate failed: Application\n\n"+
+			"Analysed commit `c5f2a1b` at 2026-10-17T10:00:00+0300.\n\n"+
+			"| Metric | Value | Fails when |\n"+
+			"|---|---|---|\n"+
+			"| [new_coverage](https://sonar.example.com/component_measures?id=app&metric=new_coverage&pullRequest=42) | 61.5 | < 80 |\n"+
+			"\n[Open analysis in SonarQube](https://sonar.example.com/dashboard?id=app&pullRequest=42)\n",
+			SonarSummaryComment(analysis))
+		assert.Equal(t, "Quality gate failed: 1 conditions not met", sonarStatusDescription(analysis))
+	})
+
+	t.Run("passed quality gate", func(t *testing.T) {
+		passed := *analysis
+		passed.QualityGate = webhook_sonar.SonarQualityGate{Status: "OK"}
+		passed.Branch.Url = "https://sonar.example.com/dashboard?id=app&pullRequest=42"
+
+		comment := SonarSummaryComment(&passed)
+		assert.Contains(t, comment, "### SonarQube quality gate passed: Application")
+		assert.Contains(t, comment, "All quality gate conditions are met.")
+		assert.Equal(t, "Quality gate passed", sonarStatusDescription(&passed))
+	})
+}
+
+func TestSonarAnalysisURL(t *testing.T) {
+	analysis := &webhook_sonar.WebHook{
+		ServerUrl: "https://sonar.example.com/",
+		Project:   webhook_sonar.Project{Key: "app"},
+		Branch:    webhook_sonar.Branch{Name: "feature/login", Type: "BRANCH"},
+	}
+	assert.Equal(t, "https://sonar.example.com/dashboard?branch=feature%2Flogin&id=app", sonarAnalysisURL(analysis))
+
+	analysis.Branch.IsMain = true
+	assert.Equal(t, "https://sonar.example.com/dashboard?id=app", sonarAnalysisURL(analysis))
+	assert.Equal(t, "sonarqube/app", SonarStatusContext("app"))
+}
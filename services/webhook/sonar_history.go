@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/sonar/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// DeleteExpiredSonarMetricsSnapshots удаляет снимки метрик анализов старше срока хранения. Последний снимок
+// каждой ветки сохраняется
+func DeleteExpiredSonarMetricsSnapshots(ctx context.Context) error {
+	if setting.Sonar.MetricsRetention <= 0 {
+		return nil
+	}
+	before := timeutil.TimeStamp(time.Now().Add(-setting.Sonar.MetricsRetention).Unix())
+	deleted, err := repo.DeleteSonarMetricsSnapshotsBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+	log.Info("Deleted %d sonar metrics snapshots older than %s", deleted, before.FormatLong())
+	return nil
+}
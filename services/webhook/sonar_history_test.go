@@ -0,0 +1,44 @@
+//go:build !correct
+
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/sonar/repo"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/webhook_sonar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSonarMetricsSnapshot(t *testing.T) {
+	sonarProject := &repo.ScSonarProjectStatus{ID: "status-id", Branch: "42", Status: "OK"}
+	analysis := &webhook_sonar.WebHook{
+		Revision:    "c5f2a1b",
+		AnalysedAt:  "2026-10-17T13:00:00+0300",
+		Branch:      webhook_sonar.Branch{Name: "42", Type: "PULL_REQUEST"},
+		QualityGate: webhook_sonar.SonarQualityGate{Status: "ERROR"},
+	}
+
+	snapshot, err := sonarMetricsSnapshot(db.DefaultContext, sonarProject, analysis)
+	require.NoError(t, err)
+	// каждый анализ из webhook сохраняется новым снимком
+	assert.Empty(t, snapshot.ID)
+	assert.Equal(t, "status-id", snapshot.SonarProjectStatusID)
+	assert.Equal(t, "42", snapshot.Branch)
+	assert.Equal(t, "c5f2a1b", snapshot.Revision)
+	assert.Equal(t, "ERROR", snapshot.QualityGateStatus)
+	assert.Equal(t, timeutil.TimeStamp(time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC).Unix()), snapshot.AnalysedAt)
+}
+
+func TestSonarTrendMetricKeys(t *testing.T) {
+	assert.Equal(t, []string{
+		"coverage", "new_coverage",
+		"bugs", "new_bugs",
+		"vulnerabilities", "new_vulnerabilities",
+		"duplicated_lines_density", "new_duplicated_lines_density",
+	}, sonarTrendMetricKeys())
+}
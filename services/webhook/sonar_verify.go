@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	sonar_model "code.gitea.io/gitea/models/sonar/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/webhook_sonar"
+	quality_gate_service "code.gitea.io/gitea/services/quality_gate"
+)
+
+const (
+	// SonarWebhookSignatureHeader заголовок с подписью тела webhook SonarQube
+	SonarWebhookSignatureHeader = "X-Sonar-Webhook-HMAC-SHA256"
+	// sonarAnalysedAtLayout формат времени анализа в webhook SonarQube
+	sonarAnalysedAtLayout = "2006-01-02T15:04:05-0700"
+)
+
+// ErrSonarWebhookRejected webhook SonarQube не прошел проверку
+type ErrSonarWebhookRejected struct {
+	ProjectKey string
+	ServerURL  string
+	Reason     string
+}
+
+func (e ErrSonarWebhookRejected) Error() string {
+	return fmt.Sprintf("sonar webhook for project %s from %s rejected: %s", e.ProjectKey, e.ServerURL, e.Reason)
+}
+
+// IsErrSonarWebhookRejected проверяет, что ошибка ErrSonarWebhookRejected
+func IsErrSonarWebhookRejected(err error) bool {
+	_, ok := err.(ErrSonarWebhookRejected)
+	return ok
+}
+
+// VerifySonarWebhook проверяет webhook SonarQube и возвращает настройки Sonar репозитория, к которому он относится.
+// Сервер из webhook должен совпадать с адресом в настройках, подпись тела в заголовке X-Sonar-Webhook-HMAC-SHA256
+// должна быть сделана секретом из этих настроек, время анализа не должно выходить за окно повторной отправки,
+// а сам анализ должен быть новее сохраненного отчета и снимка метрик той же ветки или пулл реквеста
+func VerifySonarWebhook(ctx context.Context, body []byte, signature string, analysis *webhook_sonar.WebHook, now time.Time) (*repo_model.ScSonarSettings, error) {
+	settings, err := repo_model.GetSonarSettingsByProjectKey(ctx, analysis.Project.Key)
+	if err != nil {
+		return nil, fmt.Errorf("get sonar settings by project key: %w", err)
+	}
+	verified, err := verifySonarWebhook(settings, body, signature, analysis, now)
+	if err != nil {
+		return nil, err
+	}
+
+	reportAnalysedAt, err := quality_gate_service.GetSonarReportAnalysedUnix(ctx, verified.RepoID, analysis)
+	if err != nil {
+		return nil, fmt.Errorf("get sonar report analysis time: %w", err)
+	}
+	snapshotAnalysedAt, err := sonar_model.GetLatestSonarAnalysedAt(ctx, analysis.ServerUrl, analysis.Project.Key, analysis.Branch.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get sonar snapshot analysis time: %w", err)
+	}
+	if err = checkSonarAnalysisIsNewer(analysis, reportAnalysedAt, snapshotAnalysedAt); err != nil {
+		return nil, err
+	}
+	return verified, nil
+}
+
+// verifySonarWebhook выбирает из настроек Sonar с ключом проекта из webhook те, подписью которых подписано тело.
+// Если секреты не обязательны, webhook без подписи относится к настройкам без секрета
+func verifySonarWebhook(settings []*repo_model.ScSonarSettings, body []byte, signature string, analysis *webhook_sonar.WebHook, now time.Time) (*repo_model.ScSonarSettings, error) {
+	reject := func(reason string) error {
+		return ErrSonarWebhookRejected{ProjectKey: analysis.Project.Key, ServerURL: analysis.ServerUrl, Reason: reason}
+	}
+
+	var signatureBytes []byte
+	if signature != "" {
+		var err error
+		if signatureBytes, err = hex.DecodeString(strings.TrimSpace(signature)); err != nil {
+			return nil, reject("signature is not a hex string")
+		}
+	}
+
+	var (
+		verified      *repo_model.ScSonarSettings
+		unsigned      *repo_model.ScSonarSettings
+		knownServer   bool
+		secretDefined bool
+	)
+	for _, s := range settings {
+		if !sameSonarServer(s.URL, analysis.ServerUrl) {
+			continue
+		}
+		knownServer = true
+		if !s.HasWebhookSecret() {
+			if unsigned == nil {
+				unsigned = s
+			}
+			continue
+		}
+		secretDefined = true
+		if signatureBytes == nil {
+			continue
+		}
+		webhookSecret, err := s.GetWebhookSecret()
+		if err != nil {
+			log.Error("Error has occurred while decrypting sonar webhook secret of repository %d. Error: %v", s.RepoID, err)
+			continue
+		}
+		if hmac.Equal(signatureBytes, SonarWebhookSignature(webhookSecret, body)) {
+			verified = s
+			break
+		}
+	}
+	if verified == nil && unsigned != nil && !setting.Sonar.RequireWebhookSecret {
+		log.Warn("Sonar webhook for project %s is accepted without signature, webhook secret of repository %d is not configured", analysis.Project.Key, unsigned.RepoID)
+		verified = unsigned
+	}
+	switch {
+	case !knownServer:
+		return nil, reject("server url does not match sonar settings")
+	case verified == nil && !secretDefined:
+		return nil, reject("webhook secret is not configured")
+	case verified == nil && signatureBytes == nil:
+		return nil, reject("signature header is missing")
+	case verified == nil:
+		return nil, reject("signature does not match")
+	}
+
+	analysedAt, err := time.Parse(sonarAnalysedAtLayout, analysis.AnalysedAt)
+	if err != nil {
+		return nil, reject("analysedAt is not a valid time")
+	}
+	if diff := now.Sub(analysedAt); diff > setting.Sonar.WebhookReplayWindow || diff < -setting.Sonar.WebhookReplayWindow {
+		return nil, reject("analysis is outside of the replay window")
+	}
+	return verified, nil
+}
+
+// checkSonarAnalysisIsNewer проверяет, что анализ из webhook новее сохраненных ранее анализов той же ветки
+// или пулл реквеста. Повторно отправленный или более старый анализ не должен заменять текущий результат
+func checkSonarAnalysisIsNewer(analysis *webhook_sonar.WebHook, storedAnalysedAt ...timeutil.TimeStamp) error {
+	analysedAt, err := time.Parse(sonarAnalysedAtLayout, analysis.AnalysedAt)
+	if err != nil {
+		return ErrSonarWebhookRejected{ProjectKey: analysis.Project.Key, ServerURL: analysis.ServerUrl, Reason: "analysedAt is not a valid time"}
+	}
+	for _, stored := range storedAnalysedAt {
+		if stored != 0 && analysedAt.Unix() <= int64(stored) {
+			return ErrSonarWebhookRejected{ProjectKey: analysis.Project.Key, ServerURL: analysis.ServerUrl, Reason: "analysis is not newer than the stored analysis"}
+		}
+	}
+	return nil
+}
+
+// SonarWebhookSignature возвращает HMAC-SHA256 тела webhook SonarQube, подписанного секретом
+func SonarWebhookSignature(webhookSecret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(webhookSecret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// sameSonarServer сравнивает адреса сервера Sonar без учета регистра и завершающего слэша
+func sameSonarServer(settingsURL, serverURL string) bool {
+	return strings.EqualFold(strings.TrimRight(strings.TrimSpace(settingsURL), "/"), strings.TrimRight(strings.TrimSpace(serverURL), "/"))
+}
@@ -0,0 +1,109 @@
+//go:build !correct
+
+package webhook
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/webhook_sonar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySonarWebhook(t *testing.T) {
+	oldSonar := setting.Sonar
+	setting.Sonar.RequireWebhookSecret = true
+	setting.Sonar.WebhookReplayWindow = time.Hour
+	defer func() { setting.Sonar = oldSonar }()
+
+	const webhookSecret = "0123456789abcdef-secret"
+	withSecret := &repo_model.ScSonarSettings{RepoID: 1, URL: "https://sonar.example.com/", ProjectKey: "app"}
+	require.NoError(t, withSecret.SetWebhookSecret(webhookSecret))
+	withoutSecret := &repo_model.ScSonarSettings{RepoID: 2, URL: "https://sonar.example.com", ProjectKey: "app"}
+	settings := []*repo_model.ScSonarSettings{withoutSecret, withSecret}
+
+	now := time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC)
+	body := []byte(`{"project":{"key":"app"}}`)
+	analysis := func(projectKey, serverURL, analysedAt string) *webhook_sonar.WebHook {
+		return &webhook_sonar.WebHook{
+			ServerUrl:  serverURL,
+			AnalysedAt: analysedAt,
+			Project:    webhook_sonar.Project{Key: projectKey},
+		}
+	}
+	signature := hex.EncodeToString(SonarWebhookSignature(webhookSecret, body))
+
+	t.Run("valid signature", func(t *testing.T) {
+		verified, err := verifySonarWebhook(settings, body, signature, analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, verified.RepoID)
+	})
+
+	rejected := func(t *testing.T, reason string, err error) {
+		require.Error(t, err)
+		require.True(t, IsErrSonarWebhookRejected(err), err.Error())
+		assert.Equal(t, reason, err.(ErrSonarWebhookRejected).Reason)
+	}
+
+	t.Run("missing signature", func(t *testing.T) {
+		_, err := verifySonarWebhook(settings, body, "", analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		rejected(t, "signature header is missing", err)
+	})
+
+	t.Run("wrong signature", func(t *testing.T) {
+		wrong := hex.EncodeToString(SonarWebhookSignature("another-secret-value", body))
+		_, err := verifySonarWebhook(settings, body, wrong, analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		rejected(t, "signature does not match", err)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		_, err := verifySonarWebhook(settings, []byte(`{"project":{"key":"app"},"qualityGate":{"status":"OK"}}`), signature, analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		rejected(t, "signature does not match", err)
+	})
+
+	t.Run("unknown server", func(t *testing.T) {
+		_, err := verifySonarWebhook(settings, body, signature, analysis("app", "https://evil.example.com", "2026-10-17T13:00:00+0300"), now)
+		rejected(t, "server url does not match sonar settings", err)
+	})
+
+	t.Run("secret is not configured", func(t *testing.T) {
+		_, err := verifySonarWebhook([]*repo_model.ScSonarSettings{withoutSecret}, body, signature, analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		rejected(t, "webhook secret is not configured", err)
+	})
+
+	t.Run("outside of replay window", func(t *testing.T) {
+		_, err := verifySonarWebhook(settings, body, signature, analysis("app", "https://sonar.example.com", "2026-10-17T11:00:00+0300"), now)
+		rejected(t, "analysis is outside of the replay window", err)
+	})
+
+	t.Run("unsigned webhook during rollout", func(t *testing.T) {
+		setting.Sonar.RequireWebhookSecret = false
+		defer func() { setting.Sonar.RequireWebhookSecret = true }()
+
+		verified, err := verifySonarWebhook(settings, body, "", analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, verified.RepoID)
+
+		_, err = verifySonarWebhook([]*repo_model.ScSonarSettings{withSecret}, body, "", analysis("app", "https://sonar.example.com", "2026-10-17T13:00:00+0300"), now)
+		rejected(t, "signature header is missing", err)
+	})
+}
+
+func TestCheckSonarAnalysisIsNewer(t *testing.T) {
+	analysis := &webhook_sonar.WebHook{ServerUrl: "https://sonar.example.com", AnalysedAt: "2026-10-17T13:00:00+0300", Project: webhook_sonar.Project{Key: "app"}}
+	analysedAt := timeutil.TimeStamp(time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC).Unix())
+
+	assert.NoError(t, checkSonarAnalysisIsNewer(analysis, 0, 0))
+	assert.NoError(t, checkSonarAnalysisIsNewer(analysis, analysedAt-60, analysedAt-1))
+
+	for _, stored := range [][]timeutil.TimeStamp{{analysedAt, 0}, {0, analysedAt + 60}} {
+		err := checkSonarAnalysisIsNewer(analysis, stored...)
+		require.True(t, IsErrSonarWebhookRejected(err))
+		assert.Equal(t, "analysis is not newer than the stored analysis", err.(ErrSonarWebhookRejected).Reason)
+	}
+}